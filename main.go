@@ -20,7 +20,6 @@ import (
 	"flag"
 	"net/url"
 	"os"
-	"path/filepath"
 	"strings"
 
 	utilsmetadata "github.com/armosec/utils-k8s-go/armometadata"
@@ -67,7 +66,11 @@ func main() {
 	if err != nil {
 		logger.L().Ctx(ctx).Fatal("load config error", helpers.Error(err))
 	}
-	cfg.DefaultNamespace = clusterData.Namespace
+	setNamespace := func(cfg *config.Config) { cfg.DefaultNamespace = clusterData.Namespace }
+	setNamespace(&cfg)
+	// reload the live fields of the configuration when its ConfigMap changes
+	configWatcher := config.NewWatcher(configDir, cfg, setNamespace)
+	go configWatcher.Run(ctx)
 	// to enable otel, set OTEL_COLLECTOR_SVC=otel-collector:4317
 	if otelHost, present := os.LookupEnv("OTEL_COLLECTOR_SVC"); present {
 		ctx = logger.InitOtel("storage",
@@ -99,7 +102,7 @@ func main() {
 
 	// setup storage components
 	osFs := afero.NewOsFs()
-	pool := file.NewPool(filepath.Join(file.DefaultStorageRoot, "metadata.sq3"), file.DefaultPoolSize)
+	pool := file.NewPool(file.DefaultMetadataPath, file.DefaultPoolSize)
 
 	// setup watcher
 	watchDispatcher := file.NewWatchDispatcher()
@@ -114,10 +117,15 @@ func main() {
 	relevancyEnabled := clusterData.RelevantImageVulnerabilitiesEnabled != nil && *clusterData.RelevantImageVulnerabilitiesEnabled
 
 	cleanupHandler := file.NewResourcesCleanupHandler(osFs, file.DefaultStorageRoot, pool, watchDispatcher, cfg.CleanupInterval, cfg.DefaultNamespace, kubernetesAPI, relevancyEnabled)
+	configWatcher.Subscribe(cleanupHandler.Reload)
 	go cleanupHandler.RunCleanupTask(ctx)
 
 	// start the server
 	options := server.NewWardleServerOptions(os.Stdout, os.Stderr, osFs, pool, cfg, watchDispatcher, cleanupHandler)
+	options.NamespaceLabels = kubernetesAPI.NamespaceLabels
+	options.ClusterLister = kubernetesAPI
+	options.QuarantineEvent = kubernetesAPI.QuarantineEvent
+	options.ConfigWatcher = configWatcher
 	cmd := server.NewCommandStartWardleServer(ctx, options, false)
 	logger.L().Info("APIServer starting")
 	code := cli.Run(cmd)
@@ -0,0 +1,68 @@
+/*
+Copyright 2024 The Kubescape Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package softwarecomposition
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CallStackQuery is created on the callstacks subresource of an
+// ApplicationProfile or a ContainerProfile to read its call stacks. It is not
+// persisted: the response carries the unified call stack trees of the profile
+// and the frames indexed across the profiles of its namespace.
+type CallStackQuery struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec   CallStackQuerySpec
+	Status CallStackQueryStatus
+}
+
+// CallStackQuerySpec selects the call stacks to return, at least one of
+// CallID and Frame is set.
+type CallStackQuerySpec struct {
+	// ContainerName selects the container of an ApplicationProfile, all
+	// containers are read when it is empty. It is ignored for a
+	// ContainerProfile.
+	ContainerName string
+	// CallID selects the call stacks leading to this CallID.
+	CallID CallID
+	// Frame selects the call stacks going through this frame, FrameType is
+	// ignored.
+	Frame *StackFrame
+}
+
+type CallStackQueryStatus struct {
+	// CallStacks are the unified call stacks of the profile matching the
+	// spec, sorted by CallID.
+	CallStacks []IdentifiedCallStack
+	// References are the frames of the profiles of the namespace matching the
+	// spec, one per profile, container, CallID and frame.
+	References []CallStackReference
+}
+
+// CallStackReference is a frame of the call stacks of a profile leading to a
+// CallID.
+type CallStackReference struct {
+	Kind          string
+	Name          string
+	ContainerName string
+	CallID        CallID
+	Frame         StackFrame
+}
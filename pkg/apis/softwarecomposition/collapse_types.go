@@ -18,6 +18,11 @@ package softwarecomposition
 
 import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+// DefaultCollapseConfigurationName is the name of the cluster-wide
+// CollapseConfiguration, which applies to the profiles no scoped
+// configuration selects.
+const DefaultCollapseConfigurationName = "default"
+
 // +genclient
 // +genclient:nonNamespaced
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -37,6 +42,12 @@ import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 // Tooling (e.g. bobctl autotune) can write the singleton to push tuned
 // thresholds back into a running cluster without restarting the storage
 // server.
+//
+// Other CollapseConfigurations are scoped by their NamespaceSelector and
+// WorkloadSelector: a profile they select is collapsed with the most
+// specific of them instead of "default", and the name of the configuration
+// used is recorded in the kubescape.io/collapse-configuration annotation of
+// the profile.
 type CollapseConfiguration struct {
 	metav1.TypeMeta
 	metav1.ObjectMeta
@@ -44,7 +55,8 @@ type CollapseConfiguration struct {
 	Spec CollapseConfigurationSpec
 }
 
-// CollapseConfigurationSpec carries the cluster-wide collapse thresholds.
+// CollapseConfigurationSpec carries the collapse thresholds, cluster-wide for
+// "default" and scoped by the selectors otherwise.
 type CollapseConfigurationSpec struct {
 	// OpenDynamicThreshold is the fallback threshold for AnalyzeOpens when
 	// no per-prefix entry matches the walked path. Omitted or 0 means "use
@@ -57,6 +69,34 @@ type CollapseConfigurationSpec struct {
 	// longest-prefix-wins. It REPLACES the compiled-in defaults wholesale
 	// (no merge); include any default prefix you want to keep.
 	CollapseConfigs []CollapseConfigEntry
+	// IPCollapseThreshold is the number of addresses of a NetworkNeighborhood
+	// which may fall in one /24 (IPv4) or /64 (IPv6) before they fold into
+	// that CIDR, with the same zero-means-default semantics.
+	IPCollapseThreshold int32
+	// DNSCollapseThreshold is the number of sibling labels which may appear
+	// under one DNS suffix before they fold into a "*.<suffix>" wildcard,
+	// with the same zero-means-default semantics.
+	DNSCollapseThreshold int32
+	// ExecArgsCollapseThreshold is the number of distinct values one argv
+	// position of an exec path may take before it collapses to "⋯", with the
+	// same zero-means-default semantics.
+	ExecArgsCollapseThreshold int32
+	// EnvCollapseThreshold is the number of distinct values one environment
+	// variable of an exec path may take before only its name is kept, with
+	// the same zero-means-default semantics.
+	EnvCollapseThreshold int32
+	// NamespaceSelector scopes a CollapseConfiguration other than "default"
+	// to the profiles of the namespaces it selects, an empty selector
+	// selects all namespaces. It must not be set on "default", which applies
+	// to the profiles no scoped configuration selects.
+	NamespaceSelector *metav1.LabelSelector
+	// WorkloadSelector further scopes a configuration to the profiles whose
+	// labels it selects, e.g. kubescape.io/workload-name.
+	WorkloadSelector *metav1.LabelSelector
+	// HeaderAllowlist is the names of the headers kept in the profile of an
+	// HTTP endpoint, the compiled-in allowlist when empty. The values of
+	// credential headers are never kept.
+	HeaderAllowlist []string
 }
 
 // CollapseConfigEntry is one per-prefix threshold override.
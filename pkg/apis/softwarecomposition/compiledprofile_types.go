@@ -0,0 +1,45 @@
+/*
+Copyright 2024 The Kubescape Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package softwarecomposition
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CompiledContainerProfile is served on the compiled subresource of a
+// ContainerProfile: the effective profile, with its overlay applied, compiled
+// into the pre-indexed form of the compiledprofile package. It is produced
+// during consolidation and rebuilt on read when stale.
+type CompiledContainerProfile struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec CompiledContainerProfileSpec
+}
+
+type CompiledContainerProfileSpec struct {
+	// FormatVersion is the compiledprofile.FormatVersion Data is encoded with.
+	FormatVersion int32
+	// SourceResourceVersion is the resourceVersion of the profile Data was
+	// compiled from.
+	SourceResourceVersion string
+	// Data is the compiledprofile.Encode encoding of the profile, decode it
+	// with compiledprofile.Decode.
+	Data []byte
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2024 The Kubescape Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package softwarecomposition
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Guardrail is a cluster-scoped resource carrying organization rules that
+// ApplicationProfile, NetworkNeighborhood and ContainerProfile objects are
+// evaluated against, e.g. "no container may exec a shell".
+//
+// Every rule is a CEL expression which must evaluate to true for a profile to
+// comply. Profiles are evaluated when they are written, and all stored
+// profiles are re-evaluated whenever a Guardrail changes. Violations are
+// recorded in an annotation on the profile and listed in the Guardrail status.
+type Guardrail struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec   GuardrailSpec
+	Status GuardrailStatus
+}
+
+// GuardrailSpec carries the rules of a Guardrail.
+type GuardrailSpec struct {
+	// MatchKinds restricts the guardrail to the listed profile kinds
+	// (ApplicationProfile, NetworkNeighborhood, ContainerProfile). Empty
+	// matches all of them.
+	MatchKinds []string
+	// Rules are evaluated independently, each failing rule is one violation.
+	Rules []GuardrailRule
+}
+
+// GuardrailRule is one CEL rule of a Guardrail.
+type GuardrailRule struct {
+	// Name identifies the rule in violations.
+	Name string
+	// Expression is a CEL expression which must evaluate to true for the
+	// profile to comply. It can reference `object` (the profile, as served by
+	// the v1beta1 API) and `kind`.
+	Expression string
+	// Message is reported along with violations of the rule.
+	Message string
+}
+
+// GuardrailStatus lists the profiles currently violating a Guardrail.
+type GuardrailStatus struct {
+	Violations []GuardrailViolation
+}
+
+// GuardrailViolation is a profile violating a rule of a Guardrail.
+type GuardrailViolation struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Rule      string
+	Message   string
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// GuardrailList is a list of Guardrail objects.
+type GuardrailList struct {
+	metav1.TypeMeta
+	metav1.ListMeta
+
+	Items []Guardrail
+}
@@ -0,0 +1,205 @@
+package networkpolicy
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"sort"
+
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Server of the policy refs of the peers resolved from cluster objects.
+const (
+	NodePolicyRefServer         = "node"
+	LoadBalancerPolicyRefServer = "loadbalancer"
+)
+
+// ClusterLister lists the cluster objects learned ingress IPs are resolved
+// against, see NewClusterSnapshot.
+type ClusterLister interface {
+	ListServices(ctx context.Context) ([]v1.Service, error)
+	ListEndpointSlices(ctx context.Context) ([]discoveryv1.EndpointSlice, error)
+	ListNodes(ctx context.Context) ([]v1.Node, error)
+}
+
+// IngressResolver resolves the source IP of learned ingress traffic into the
+// peers allowing it.
+type IngressResolver interface {
+	ResolveIngress(ip string) ([]softwarecomposition.NetworkPolicyPeer, []softwarecomposition.PolicyRef, bool)
+}
+
+// ClusterSnapshot is an IngressResolver indexing the Services, EndpointSlices
+// and Nodes of the cluster at a point in time. An IP is resolved:
+//   - when it is an endpoint of a Service with a selector, typically an
+//     ingress controller, to the pod and namespace selectors of that Service,
+//   - when it is an InternalIP of a Node, where NodePort and LoadBalancer
+//     traffic is SNATed by kube-proxy, to the InternalIPs of every Node,
+//   - when it is an ExternalIP of a Node, which NodePort traffic may come
+//     from outside the cluster network, to the ExternalIPs of every Node,
+//   - when it is a LoadBalancer ingress IP of a Service, to every ingress IP
+//     of that LoadBalancer.
+type ClusterSnapshot struct {
+	endpoints map[string]*v1.Service
+	// nodes and externalNodes map the InternalIPs and ExternalIPs of the
+	// Nodes to their names
+	nodes         map[string]string
+	externalNodes map[string]string
+	loadBalancers map[string]*v1.Service
+}
+
+var _ IngressResolver = (*ClusterSnapshot)(nil)
+
+// NewClusterSnapshot lists the cluster objects of lister and indexes them.
+func NewClusterSnapshot(ctx context.Context, lister ClusterLister) (*ClusterSnapshot, error) {
+	services, err := lister.ListServices(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list services: %w", err)
+	}
+	endpointSlices, err := lister.ListEndpointSlices(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list endpoint slices: %w", err)
+	}
+	nodes, err := lister.ListNodes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list nodes: %w", err)
+	}
+
+	snapshot := &ClusterSnapshot{
+		endpoints:     map[string]*v1.Service{},
+		nodes:         map[string]string{},
+		externalNodes: map[string]string{},
+		loadBalancers: map[string]*v1.Service{},
+	}
+	// services sorted by namespace and name, so that an IP behind several of
+	// them always resolves to the same one
+	sort.Slice(services, func(i, j int) bool { return serviceLess(&services[i], &services[j]) })
+	byName := make(map[string]*v1.Service, len(services))
+	for i := range services {
+		svc := &services[i]
+		byName[svc.Namespace+"/"+svc.Name] = svc
+		if svc.Spec.Type != v1.ServiceTypeLoadBalancer {
+			continue
+		}
+		for _, ingress := range svc.Status.LoadBalancer.Ingress {
+			if _, found := snapshot.loadBalancers[ingress.IP]; ingress.IP != "" && !found {
+				snapshot.loadBalancers[ingress.IP] = svc
+			}
+		}
+	}
+	for _, slice := range endpointSlices {
+		svc, ok := byName[slice.Namespace+"/"+slice.Labels[discoveryv1.LabelServiceName]]
+		if !ok || len(svc.Spec.Selector) == 0 {
+			// without a selector, the endpoints are not pods we can select
+			continue
+		}
+		for _, endpoint := range slice.Endpoints {
+			for _, address := range endpoint.Addresses {
+				if current, found := snapshot.endpoints[address]; !found || serviceLess(svc, current) {
+					snapshot.endpoints[address] = svc
+				}
+			}
+		}
+	}
+	for _, node := range nodes {
+		for _, address := range node.Status.Addresses {
+			switch address.Type {
+			case v1.NodeInternalIP:
+				snapshot.nodes[address.Address] = node.Name
+			case v1.NodeExternalIP:
+				snapshot.externalNodes[address.Address] = node.Name
+			}
+		}
+	}
+	return snapshot, nil
+}
+
+func serviceLess(a, b *v1.Service) bool {
+	if a.Namespace != b.Namespace {
+		return a.Namespace < b.Namespace
+	}
+	return a.Name < b.Name
+}
+
+// ResolveIngress returns the peers allowing ingress traffic from ip and their
+// policy refs, or false when ip is not known to the snapshot.
+func (s *ClusterSnapshot) ResolveIngress(ip string) ([]softwarecomposition.NetworkPolicyPeer, []softwarecomposition.PolicyRef, bool) {
+	if svc, ok := s.endpoints[ip]; ok {
+		return []softwarecomposition.NetworkPolicyPeer{{
+			PodSelector: &metav1.LabelSelector{MatchLabels: copyLabels(svc.Spec.Selector)},
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{
+				v1.LabelMetadataName: svc.Namespace,
+			}},
+		}}, nil, true
+	}
+	if _, ok := s.nodes[ip]; ok {
+		peers, refs := resolveNodes(ip, s.nodes)
+		return peers, refs, true
+	}
+	if _, ok := s.externalNodes[ip]; ok {
+		peers, refs := resolveNodes(ip, s.externalNodes)
+		return peers, refs, true
+	}
+	if svc, ok := s.loadBalancers[ip]; ok {
+		var peers []softwarecomposition.NetworkPolicyPeer
+		var refs []softwarecomposition.PolicyRef
+		for _, ingress := range svc.Status.LoadBalancer.Ingress {
+			if ingress.IP == "" {
+				continue
+			}
+			ipBlock := hostIPBlock(ingress.IP)
+			peers = append(peers, softwarecomposition.NetworkPolicyPeer{IPBlock: ipBlock})
+			refs = append(refs, softwarecomposition.PolicyRef{
+				Name:       svc.Namespace + "/" + svc.Name,
+				OriginalIP: ip,
+				IPBlock:    ipBlock.CIDR,
+				Server:     LoadBalancerPolicyRefServer,
+			})
+		}
+		return peers, refs, true
+	}
+	return nil, nil, false
+}
+
+// resolveNodes returns the peers and policy refs of the node addresses of
+// nodes, ip being the one traffic was learned from.
+func resolveNodes(ip string, nodes map[string]string) ([]softwarecomposition.NetworkPolicyPeer, []softwarecomposition.PolicyRef) {
+	addresses := make([]string, 0, len(nodes))
+	for address := range nodes {
+		addresses = append(addresses, address)
+	}
+	slices.Sort(addresses)
+	var peers []softwarecomposition.NetworkPolicyPeer
+	var refs []softwarecomposition.PolicyRef
+	for _, address := range addresses {
+		ipBlock := hostIPBlock(address)
+		peers = append(peers, softwarecomposition.NetworkPolicyPeer{IPBlock: ipBlock})
+		refs = append(refs, softwarecomposition.PolicyRef{
+			Name:       nodes[address],
+			OriginalIP: ip,
+			IPBlock:    ipBlock.CIDR,
+			Server:     NodePolicyRefServer,
+		})
+	}
+	return peers, refs
+}
+
+// hostIPBlock returns the IPBlock of the single address ip.
+func hostIPBlock(ip string) *softwarecomposition.IPBlock {
+	if parsed := net.ParseIP(ip); parsed != nil && parsed.To4() == nil {
+		return &softwarecomposition.IPBlock{CIDR: ip + "/128"}
+	}
+	return getSingleIP(ip)
+}
+
+func copyLabels(labels map[string]string) map[string]string {
+	out := make(map[string]string, len(labels))
+	for k, v := range labels {
+		out[k] = v
+	}
+	return out
+}
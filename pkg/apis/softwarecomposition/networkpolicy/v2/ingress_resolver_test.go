@@ -0,0 +1,215 @@
+package networkpolicy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	helpersv1 "github.com/kubescape/k8s-interface/instanceidhandler/v1/helpers"
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+type fakeClusterLister struct {
+	services       []v1.Service
+	endpointSlices []discoveryv1.EndpointSlice
+	nodes          []v1.Node
+	err            error
+}
+
+func (f fakeClusterLister) ListServices(context.Context) ([]v1.Service, error) {
+	return f.services, f.err
+}
+
+func (f fakeClusterLister) ListEndpointSlices(context.Context) ([]discoveryv1.EndpointSlice, error) {
+	return f.endpointSlices, f.err
+}
+
+func (f fakeClusterLister) ListNodes(context.Context) ([]v1.Node, error) {
+	return f.nodes, f.err
+}
+
+func testClusterLister() fakeClusterLister {
+	return fakeClusterLister{
+		services: []v1.Service{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "ingress-nginx-controller", Namespace: "ingress-nginx"},
+				Spec: v1.ServiceSpec{
+					Type:     v1.ServiceTypeLoadBalancer,
+					Selector: map[string]string{"app.kubernetes.io/name": "ingress-nginx"},
+				},
+				Status: v1.ServiceStatus{LoadBalancer: v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{
+					{IP: "203.0.113.10"}, {IP: "203.0.113.11"}, {Hostname: "lb.example.com"},
+				}}},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "external", Namespace: "default"},
+			},
+		},
+		endpointSlices: []discoveryv1.EndpointSlice{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "ingress-nginx-controller-abcde", Namespace: "ingress-nginx",
+					Labels: map[string]string{discoveryv1.LabelServiceName: "ingress-nginx-controller"}},
+				Endpoints: []discoveryv1.Endpoint{{Addresses: []string{"10.244.1.7"}}},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "external-abcde", Namespace: "default",
+					Labels: map[string]string{discoveryv1.LabelServiceName: "external"}},
+				Endpoints: []discoveryv1.Endpoint{{Addresses: []string{"192.0.2.1"}}},
+			},
+		},
+		nodes: []v1.Node{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "node-b"},
+				Status: v1.NodeStatus{Addresses: []v1.NodeAddress{
+					{Type: v1.NodeInternalIP, Address: "172.18.0.3"},
+					{Type: v1.NodeExternalIP, Address: "198.51.100.3"},
+					{Type: v1.NodeHostName, Address: "node-b"},
+				}},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "node-a"},
+				Status: v1.NodeStatus{Addresses: []v1.NodeAddress{
+					{Type: v1.NodeInternalIP, Address: "172.18.0.2"},
+				}},
+			},
+		},
+	}
+}
+
+func TestClusterSnapshot_ResolveIngress(t *testing.T) {
+	snapshot, err := NewClusterSnapshot(context.Background(), testClusterLister())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		ip        string
+		wantPeers []softwarecomposition.NetworkPolicyPeer
+		wantRefs  []softwarecomposition.PolicyRef
+		wantOK    bool
+	}{
+		{
+			name: "ingress controller endpoint",
+			ip:   "10.244.1.7",
+			wantPeers: []softwarecomposition.NetworkPolicyPeer{{
+				PodSelector:       &metav1.LabelSelector{MatchLabels: map[string]string{"app.kubernetes.io/name": "ingress-nginx"}},
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"kubernetes.io/metadata.name": "ingress-nginx"}},
+			}},
+			wantOK: true,
+		},
+		{
+			name: "node address",
+			ip:   "172.18.0.3",
+			wantPeers: []softwarecomposition.NetworkPolicyPeer{
+				{IPBlock: &softwarecomposition.IPBlock{CIDR: "172.18.0.2/32"}},
+				{IPBlock: &softwarecomposition.IPBlock{CIDR: "172.18.0.3/32"}},
+			},
+			wantRefs: []softwarecomposition.PolicyRef{
+				{Name: "node-a", OriginalIP: "172.18.0.3", IPBlock: "172.18.0.2/32", Server: NodePolicyRefServer},
+				{Name: "node-b", OriginalIP: "172.18.0.3", IPBlock: "172.18.0.3/32", Server: NodePolicyRefServer},
+			},
+			wantOK: true,
+		},
+		{
+			name: "node external address",
+			ip:   "198.51.100.3",
+			wantPeers: []softwarecomposition.NetworkPolicyPeer{
+				{IPBlock: &softwarecomposition.IPBlock{CIDR: "198.51.100.3/32"}},
+			},
+			wantRefs: []softwarecomposition.PolicyRef{
+				{Name: "node-b", OriginalIP: "198.51.100.3", IPBlock: "198.51.100.3/32", Server: NodePolicyRefServer},
+			},
+			wantOK: true,
+		},
+		{
+			name: "load balancer ingress",
+			ip:   "203.0.113.11",
+			wantPeers: []softwarecomposition.NetworkPolicyPeer{
+				{IPBlock: &softwarecomposition.IPBlock{CIDR: "203.0.113.10/32"}},
+				{IPBlock: &softwarecomposition.IPBlock{CIDR: "203.0.113.11/32"}},
+			},
+			wantRefs: []softwarecomposition.PolicyRef{
+				{Name: "ingress-nginx/ingress-nginx-controller", OriginalIP: "203.0.113.11", IPBlock: "203.0.113.10/32", Server: LoadBalancerPolicyRefServer},
+				{Name: "ingress-nginx/ingress-nginx-controller", OriginalIP: "203.0.113.11", IPBlock: "203.0.113.11/32", Server: LoadBalancerPolicyRefServer},
+			},
+			wantOK: true,
+		},
+		{
+			name: "endpoint of a service without selector",
+			ip:   "192.0.2.1",
+		},
+		{
+			name: "unknown",
+			ip:   "198.51.100.1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			peers, refs, ok := snapshot.ResolveIngress(tt.ip)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantPeers, peers)
+			assert.Equal(t, tt.wantRefs, refs)
+		})
+	}
+}
+
+func TestNewClusterSnapshot_Error(t *testing.T) {
+	_, err := NewClusterSnapshot(context.Background(), fakeClusterLister{err: errors.New("forbidden")})
+	assert.Error(t, err)
+}
+
+func TestGenerateNetworkPolicyWithIngressResolver(t *testing.T) {
+	snapshot, err := NewClusterSnapshot(context.Background(), testClusterLister())
+	require.NoError(t, err)
+	nn := &softwarecomposition.NetworkNeighborhood{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "deployment-web",
+			Namespace:   "default",
+			Annotations: map[string]string{helpersv1.StatusMetadataKey: helpersv1.Completed},
+			Labels: map[string]string{
+				helpersv1.RelatedKindMetadataKey: "Deployment",
+				helpersv1.RelatedNameMetadataKey: "web",
+			},
+		},
+		Spec: softwarecomposition.NetworkNeighborhoodSpec{
+			Containers: []softwarecomposition.NetworkNeighborhoodContainer{{
+				Ingress: []softwarecomposition.NetworkNeighbor{
+					{IPAddress: "10.244.1.7", Ports: []softwarecomposition.NetworkPort{{Name: "TCP-8080", Protocol: "TCP", Port: ptr.To(int32(8080))}}},
+					{IPAddress: "172.18.0.2", Ports: []softwarecomposition.NetworkPort{{Name: "TCP-30080", Protocol: "TCP", Port: ptr.To(int32(30080))}}},
+					{IPAddress: "198.51.100.1", Ports: []softwarecomposition.NetworkPort{{Name: "TCP-8080", Protocol: "TCP", Port: ptr.To(int32(8080))}}},
+				},
+			}},
+		},
+	}
+
+	generated, err := GenerateNetworkPolicyWithIngressResolver(nn, softwarecomposition.NewKnownServersFinderImpl(nil), snapshot, metav1.Now())
+	require.NoError(t, err)
+
+	tcp := v1.ProtocolTCP
+	assert.Equal(t, []softwarecomposition.NetworkPolicyIngressRule{
+		{
+			Ports: []softwarecomposition.NetworkPolicyPort{{Protocol: &tcp, Port: ptr.To(int32(8080))}},
+			From:  []softwarecomposition.NetworkPolicyPeer{{IPBlock: &softwarecomposition.IPBlock{CIDR: "198.51.100.1/32"}}},
+		},
+		{
+			Ports: []softwarecomposition.NetworkPolicyPort{{Protocol: &tcp, Port: ptr.To(int32(30080))}},
+			From: []softwarecomposition.NetworkPolicyPeer{
+				{IPBlock: &softwarecomposition.IPBlock{CIDR: "172.18.0.2/32"}},
+				{IPBlock: &softwarecomposition.IPBlock{CIDR: "172.18.0.3/32"}},
+			},
+		},
+		{
+			Ports: []softwarecomposition.NetworkPolicyPort{{Protocol: &tcp, Port: ptr.To(int32(8080))}},
+			From: []softwarecomposition.NetworkPolicyPeer{{
+				PodSelector:       &metav1.LabelSelector{MatchLabels: map[string]string{"app.kubernetes.io/name": "ingress-nginx"}},
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"kubernetes.io/metadata.name": "ingress-nginx"}},
+			}},
+		},
+	}, generated.Spec.Spec.Ingress)
+	assert.Len(t, generated.PoliciesRef, 2)
+}
@@ -21,6 +21,15 @@ import (
 )
 
 func GenerateNetworkPolicy(nn *softwarecomposition.NetworkNeighborhood, knownServers softwarecomposition.IKnownServersFinder, timeProvider metav1.Time) (softwarecomposition.GeneratedNetworkPolicy, error) {
+	return GenerateNetworkPolicyWithIngressResolver(nn, knownServers, nil, timeProvider)
+}
+
+// GenerateNetworkPolicyWithIngressResolver is GenerateNetworkPolicy resolving
+// the IPs of learned ingress neighbors with ingressResolver first, so that
+// traffic arriving through a Service, an ingress controller or a
+// LoadBalancer is allowed from its actual source. A nil ingressResolver
+// resolves nothing.
+func GenerateNetworkPolicyWithIngressResolver(nn *softwarecomposition.NetworkNeighborhood, knownServers softwarecomposition.IKnownServersFinder, ingressResolver IngressResolver, timeProvider metav1.Time) (softwarecomposition.GeneratedNetworkPolicy, error) {
 	if !IsAvailable(nn) {
 		return softwarecomposition.GeneratedNetworkPolicy{}, fmt.Errorf("nn %s/%s status annotation is not ready nor completed", nn.Namespace, nn.Name)
 	}
@@ -83,7 +92,7 @@ func GenerateNetworkPolicy(nn *softwarecomposition.NetworkNeighborhood, knownSer
 	ingressPolicyRefsHash := make(map[string]bool)
 	for _, neighbor := range listIngressNetworkNeighbors(nn) {
 
-		rule, policyRefs := generateIngressRule(neighbor, knownServers)
+		rule, policyRefs := generateIngressRule(neighbor, knownServers, ingressResolver)
 
 		if ruleHash, err := hash(rule); err == nil {
 			if ok := ingressHash[ruleHash]; !ok {
@@ -395,7 +404,7 @@ func generateEgressRule(neighbor softwarecomposition.NetworkNeighbor, knownServe
 	return egressRule, policyRefs
 }
 
-func generateIngressRule(neighbor softwarecomposition.NetworkNeighbor, knownServers softwarecomposition.IKnownServersFinder) (softwarecomposition.NetworkPolicyIngressRule, []softwarecomposition.PolicyRef) {
+func generateIngressRule(neighbor softwarecomposition.NetworkNeighbor, knownServers softwarecomposition.IKnownServersFinder, ingressResolver IngressResolver) (softwarecomposition.NetworkPolicyIngressRule, []softwarecomposition.PolicyRef) {
 	ingressRule := softwarecomposition.NetworkPolicyIngressRule{}
 	policyRefs := []softwarecomposition.PolicyRef{}
 
@@ -416,7 +425,18 @@ func generateIngressRule(neighbor softwarecomposition.NetworkNeighbor, knownServ
 		}
 	}
 
-	if neighbor.IPAddress != "" {
+	resolved := false
+	if neighbor.IPAddress != "" && neighbor.PodSelector == nil && neighbor.NamespaceSelector == nil && ingressResolver != nil {
+		// look if this IP belongs to a Service, a Node or a LoadBalancer
+		var peers []softwarecomposition.NetworkPolicyPeer
+		var refs []softwarecomposition.PolicyRef
+		if peers, refs, resolved = ingressResolver.ResolveIngress(neighbor.IPAddress); resolved {
+			ingressRule.From = append(ingressRule.From, peers...)
+			policyRefs = append(policyRefs, refs...)
+		}
+	}
+
+	if neighbor.IPAddress != "" && !resolved {
 		// look if this IP is part of any known server
 		if entries, ok := knownServers.Contains(net.ParseIP(neighbor.IPAddress)); ok {
 			for _, entry := range entries {
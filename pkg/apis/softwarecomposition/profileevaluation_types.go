@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The Kubescape Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package softwarecomposition
+
+import (
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition/consts"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ObservationType is the kind of runtime event a ProfileObservation describes.
+type ObservationType string
+
+const (
+	ObservationTypeExec         ObservationType = "Exec"
+	ObservationTypeOpen         ObservationType = "Open"
+	ObservationTypeSyscall      ObservationType = "Syscall"
+	ObservationTypeCapability   ObservationType = "Capability"
+	ObservationTypeEgress       ObservationType = "Egress"
+	ObservationTypeIngress      ObservationType = "Ingress"
+	ObservationTypeHTTPEndpoint ObservationType = "HTTPEndpoint"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ProfileEvaluation is created on the evaluate subresource of an
+// ApplicationProfile, NetworkNeighborhood or ContainerProfile to ask whether
+// the stored profile covers a batch of observations. It is not persisted: the
+// response carries one result per observation, in the same order, computed
+// with the matchers the node-agent uses at runtime.
+type ProfileEvaluation struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec   ProfileEvaluationSpec
+	Status ProfileEvaluationStatus
+}
+
+type ProfileEvaluationSpec struct {
+	// ContainerName selects the container of an ApplicationProfile or a
+	// NetworkNeighborhood, it is ignored for a ContainerProfile.
+	ContainerName string
+	Observations  []ProfileObservation
+}
+
+// ProfileObservation is one runtime event, only the fields relevant to its
+// Type are read.
+type ProfileObservation struct {
+	Type ObservationType
+	// Path is the executable of an Exec or the file of an Open.
+	Path string
+	// Args are the arguments of an Exec, recorded like the args of a profile exec.
+	Args []string
+	// Flags are the flags of an Open.
+	Flags []string
+	// Name is the syscall of a Syscall or the capability of a Capability.
+	Name string
+	// IPAddress, DNSName, Protocol and Port describe the peer of an Egress or
+	// an Ingress, at least one of IPAddress and DNSName must be set.
+	IPAddress string
+	DNSName   string
+	Protocol  Protocol
+	Port      *int32
+	// Endpoint, Method and Direction describe an HTTPEndpoint, e.g. ":80/users/42".
+	Endpoint  string
+	Method    string
+	Direction consts.NetworkDirection
+}
+
+type ProfileEvaluationStatus struct {
+	// Results has one entry per observation, in the order of the spec.
+	Results []ProfileObservationResult
+}
+
+type ProfileObservationResult struct {
+	// Allowed is true when an entry of the profile covers the observation.
+	Allowed bool
+	// MatchedField is the field path of the covering entry, e.g. "spec.containers[0].execs[3]".
+	MatchedField string
+	// MatchedEntry is the covering entry as stored, e.g. "/bin/sh -c ⋯⋯".
+	MatchedEntry string
+	// Reason explains why an observation is not allowed.
+	Reason string
+}
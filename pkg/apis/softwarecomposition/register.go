@@ -85,6 +85,11 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&SeccompProfileList{},
 		&CollapseConfiguration{},
 		&CollapseConfigurationList{},
+		&Guardrail{},
+		&GuardrailList{},
+		&ProfileEvaluation{},
+		&CompiledContainerProfile{},
+		&CallStackQuery{},
 	)
 	return nil
 }
@@ -114,6 +114,22 @@ type WorkloadConfigurationScanSeveritiesSummary struct {
 	Unknown  int64
 }
 
+func (s *WorkloadConfigurationScanSeveritiesSummary) Add(severities *WorkloadConfigurationScanSeveritiesSummary) {
+	s.Critical += severities.Critical
+	s.High += severities.High
+	s.Medium += severities.Medium
+	s.Low += severities.Low
+	s.Unknown += severities.Unknown
+}
+
+func (s *WorkloadConfigurationScanSeveritiesSummary) Sub(severities *WorkloadConfigurationScanSeveritiesSummary) {
+	s.Critical -= severities.Critical
+	s.High -= severities.High
+	s.Medium -= severities.Medium
+	s.Low -= severities.Low
+	s.Unknown -= severities.Unknown
+}
+
 type ScannedControlSummary struct {
 	ControlID string
 	Severity  ControlSeverity
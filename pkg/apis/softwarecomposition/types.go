@@ -198,6 +198,20 @@ func (s *SeveritySummary) Add(severities *SeveritySummary) {
 	s.Unknown.Add(&severities.Unknown)
 }
 
+func (c *VulnerabilityCounters) Sub(counters *VulnerabilityCounters) {
+	c.All -= counters.All
+	c.Relevant -= counters.Relevant
+}
+
+func (s *SeveritySummary) Sub(severities *SeveritySummary) {
+	s.Critical.Sub(&severities.Critical)
+	s.High.Sub(&severities.High)
+	s.Medium.Sub(&severities.Medium)
+	s.Low.Sub(&severities.Low)
+	s.Negligible.Sub(&severities.Negligible)
+	s.Unknown.Sub(&severities.Unknown)
+}
+
 func (v *VulnerabilitySummary) Merge(vulnManifestSumm *VulnerabilityManifestSummary) {
 	v.Spec.Severities.Add(&vulnManifestSumm.Spec.Severities)
 	workloadVulnerabilitiesObj := VulnerabilitiesObjScope{
@@ -736,6 +750,14 @@ type HTTPEndpoint struct {
 	Internal  bool
 	Direction consts.NetworkDirection
 	Headers   json.RawMessage
+	// QueryParams are the names of the query parameters sent to the endpoint,
+	// their values are not kept.
+	QueryParams []string
+	// StatusClasses are the classes of the response status codes, e.g. "2xx".
+	StatusClasses []string
+	// ContentTypes are the media types of the request and response bodies,
+	// without their parameters, e.g. "application/json".
+	ContentTypes []string
 }
 
 func (e *HTTPEndpoint) GetHeaders() (map[string][]string, error) {
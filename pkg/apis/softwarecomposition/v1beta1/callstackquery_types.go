@@ -0,0 +1,76 @@
+/*
+Copyright 2024 The Kubescape Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CallStackQuery is created on the callstacks subresource of an
+// ApplicationProfile or a ContainerProfile to read its call stacks. It is not
+// persisted: the response carries the unified call stack trees of the profile
+// and the frames indexed across the profiles of its namespace.
+type CallStackQuery struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	Spec CallStackQuerySpec `json:"spec" protobuf:"bytes,2,req,name=spec"`
+	// +optional
+	Status CallStackQueryStatus `json:"status,omitempty" protobuf:"bytes,3,opt,name=status"`
+}
+
+// CallStackQuerySpec selects the call stacks to return, at least one of
+// CallID and Frame is set.
+type CallStackQuerySpec struct {
+	// ContainerName selects the container of an ApplicationProfile, all
+	// containers are read when it is empty. It is ignored for a
+	// ContainerProfile.
+	// +optional
+	ContainerName string `json:"containerName,omitempty" protobuf:"bytes,1,opt,name=containerName"`
+	// CallID selects the call stacks leading to this CallID.
+	// +optional
+	CallID CallID `json:"callID,omitempty" protobuf:"bytes,2,opt,name=callID"`
+	// Frame selects the call stacks going through this frame, FrameType is
+	// ignored.
+	// +optional
+	Frame *StackFrame `json:"frame,omitempty" protobuf:"bytes,3,opt,name=frame"`
+}
+
+type CallStackQueryStatus struct {
+	// CallStacks are the unified call stacks of the profile matching the
+	// spec, sorted by CallID.
+	// +optional
+	// +listType=atomic
+	CallStacks []IdentifiedCallStack `json:"callStacks,omitempty" protobuf:"bytes,1,rep,name=callStacks"`
+	// References are the frames of the profiles of the namespace matching the
+	// spec, one per profile, container, CallID and frame.
+	// +optional
+	// +listType=atomic
+	References []CallStackReference `json:"references,omitempty" protobuf:"bytes,2,rep,name=references"`
+}
+
+// CallStackReference is a frame of the call stacks of a profile leading to a
+// CallID.
+type CallStackReference struct {
+	Kind          string     `json:"kind" protobuf:"bytes,1,req,name=kind"`
+	Name          string     `json:"name" protobuf:"bytes,2,req,name=name"`
+	ContainerName string     `json:"containerName,omitempty" protobuf:"bytes,3,opt,name=containerName"`
+	CallID        CallID     `json:"callID" protobuf:"bytes,4,req,name=callID"`
+	Frame         StackFrame `json:"frame" protobuf:"bytes,5,req,name=frame"`
+}
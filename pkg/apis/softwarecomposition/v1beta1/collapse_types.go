@@ -32,6 +32,12 @@ import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 // built-in /etc, /opt, /var/run (etc.) entries. List every prefix you want
 // active, including any default you wish to keep. When the resource is
 // absent the deflate path uses the compiled-in DefaultCollapseSettings.
+//
+// Other CollapseConfigurations are scoped by their NamespaceSelector and
+// WorkloadSelector: a profile they select is collapsed with the most
+// specific of them instead of "default", and the name of the configuration
+// used is recorded in the kubescape.io/collapse-configuration annotation of
+// the profile.
 type CollapseConfiguration struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
@@ -39,7 +45,8 @@ type CollapseConfiguration struct {
 	Spec CollapseConfigurationSpec `json:"spec" protobuf:"bytes,2,req,name=spec"`
 }
 
-// CollapseConfigurationSpec carries the cluster-wide collapse thresholds.
+// CollapseConfigurationSpec carries the collapse thresholds, cluster-wide for
+// "default" and scoped by the selectors otherwise.
 type CollapseConfigurationSpec struct {
 	// OpenDynamicThreshold is the fallback threshold for AnalyzeOpens when
 	// no per-prefix entry matches the walked path. Optional: when omitted
@@ -65,6 +72,53 @@ type CollapseConfigurationSpec struct {
 	// +listType=map
 	// +listMapKey=prefix
 	CollapseConfigs []CollapseConfigEntry `json:"collapseConfigs,omitempty" protobuf:"bytes,3,rep,name=collapseConfigs"`
+	// IPCollapseThreshold is the number of addresses of a NetworkNeighborhood
+	// which may fall in one /24 (IPv4) or /64 (IPv6) before they fold into
+	// that CIDR. Optional with the same omitted/0-means-compiled-default
+	// semantics as OpenDynamicThreshold.
+	// +optional
+	IPCollapseThreshold int32 `json:"ipCollapseThreshold,omitempty" protobuf:"varint,4,opt,name=ipCollapseThreshold"`
+	// DNSCollapseThreshold is the number of sibling labels which may appear
+	// under one DNS suffix before they fold into a "*.<suffix>" wildcard.
+	// Optional with the same omitted/0-means-compiled-default semantics as
+	// OpenDynamicThreshold.
+	// +optional
+	DNSCollapseThreshold int32 `json:"dnsCollapseThreshold,omitempty" protobuf:"varint,5,opt,name=dnsCollapseThreshold"`
+	// ExecArgsCollapseThreshold is the number of distinct values one argv
+	// position of an exec path may take before it collapses to "⋯"; argv of
+	// varying length collapses to a common prefix followed by "⋯⋯" under the
+	// same threshold. Optional with the same omitted/0-means-compiled-default
+	// semantics as OpenDynamicThreshold.
+	// +optional
+	ExecArgsCollapseThreshold int32 `json:"execArgsCollapseThreshold,omitempty" protobuf:"varint,8,opt,name=execArgsCollapseThreshold"`
+	// EnvCollapseThreshold is the number of distinct values one environment
+	// variable of an exec path may take before only its name is kept.
+	// Optional with the same omitted/0-means-compiled-default semantics as
+	// OpenDynamicThreshold.
+	// +optional
+	EnvCollapseThreshold int32 `json:"envCollapseThreshold,omitempty" protobuf:"varint,9,opt,name=envCollapseThreshold"`
+	// NamespaceSelector scopes a CollapseConfiguration other than "default"
+	// to the profiles of the namespaces it selects; an empty selector
+	// selects all namespaces. Required on every configuration but
+	// "default", on which it must not be set: "default" applies to the
+	// profiles no scoped configuration selects.
+	// +optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty" protobuf:"bytes,6,opt,name=namespaceSelector"`
+	// WorkloadSelector further scopes a configuration to the profiles whose
+	// labels it selects, e.g. kubescape.io/workload-name. When several
+	// scoped configurations select a profile, the most specific one wins:
+	// one with a WorkloadSelector over one without, then the one with the
+	// most selector requirements, then the first by name.
+	// +optional
+	WorkloadSelector *metav1.LabelSelector `json:"workloadSelector,omitempty" protobuf:"bytes,7,opt,name=workloadSelector"`
+	// HeaderAllowlist is the names of the headers kept in the profile of an
+	// HTTP endpoint, matched case-insensitively, the others are dropped.
+	// Optional: when omitted or empty the compiled-in allowlist applies. The
+	// values of credential headers such as Authorization are never kept,
+	// only their names.
+	// +optional
+	// +listType=set
+	HeaderAllowlist []string `json:"headerAllowlist,omitempty" protobuf:"bytes,10,rep,name=headerAllowlist"`
 }
 
 // CollapseConfigEntry is one per-prefix threshold override.
@@ -0,0 +1,94 @@
+/*
+Copyright 2024 The Kubescape Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Guardrail is a cluster-scoped resource carrying organization rules that
+// ApplicationProfile, NetworkNeighborhood and ContainerProfile objects are
+// evaluated against, e.g. "no container may exec a shell".
+//
+// Every rule is a CEL expression which must evaluate to true for a profile to
+// comply. Violations are recorded in the kubescape.io/guardrail-violations
+// annotation of the profile and listed in the Guardrail status.
+type Guardrail struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	Spec GuardrailSpec `json:"spec" protobuf:"bytes,2,req,name=spec"`
+	// +optional
+	Status GuardrailStatus `json:"status,omitempty" protobuf:"bytes,3,opt,name=status"`
+}
+
+// GuardrailSpec carries the rules of a Guardrail.
+type GuardrailSpec struct {
+	// MatchKinds restricts the guardrail to the listed profile kinds
+	// (ApplicationProfile, NetworkNeighborhood, ContainerProfile). Empty
+	// matches all of them.
+	// +optional
+	// +listType=set
+	MatchKinds []string `json:"matchKinds,omitempty" protobuf:"bytes,1,rep,name=matchKinds"`
+	// Rules are evaluated independently, each failing rule is one violation.
+	// +listType=map
+	// +listMapKey=name
+	Rules []GuardrailRule `json:"rules" protobuf:"bytes,2,rep,name=rules"`
+}
+
+// GuardrailRule is one CEL rule of a Guardrail.
+type GuardrailRule struct {
+	// Name identifies the rule in violations.
+	Name string `json:"name" protobuf:"bytes,1,req,name=name"`
+	// Expression is a CEL expression which must evaluate to true for the
+	// profile to comply. It can reference `object` (the profile, as served by
+	// this API) and `kind`, e.g.
+	// `object.spec.containers.all(c, c.execs.all(e, e.path != '/bin/sh'))`.
+	Expression string `json:"expression" protobuf:"bytes,2,req,name=expression"`
+	// Message is reported along with violations of the rule.
+	// +optional
+	Message string `json:"message,omitempty" protobuf:"bytes,3,opt,name=message"`
+}
+
+// GuardrailStatus lists the profiles currently violating a Guardrail.
+type GuardrailStatus struct {
+	// +optional
+	Violations []GuardrailViolation `json:"violations,omitempty" protobuf:"bytes,1,rep,name=violations"`
+}
+
+// GuardrailViolation is a profile violating a rule of a Guardrail.
+type GuardrailViolation struct {
+	Kind      string `json:"kind" protobuf:"bytes,1,req,name=kind"`
+	Namespace string `json:"namespace" protobuf:"bytes,2,req,name=namespace"`
+	Name      string `json:"name" protobuf:"bytes,3,req,name=name"`
+	Rule      string `json:"rule" protobuf:"bytes,4,req,name=rule"`
+	// +optional
+	Message string `json:"message,omitempty" protobuf:"bytes,5,opt,name=message"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// GuardrailList is a list of Guardrail objects.
+type GuardrailList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	Items []Guardrail `json:"items" protobuf:"bytes,2,rep,name=items"`
+}
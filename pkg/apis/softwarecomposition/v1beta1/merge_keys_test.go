@@ -0,0 +1,69 @@
+package v1beta1
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+)
+
+// TestStrategicMergePatch_MergeKeys checks that strategic merge patches merge
+// the container lists by name and the neighbor lists by identifier, instead of
+// replacing the whole array.
+func TestStrategicMergePatch_MergeKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		original any
+		patch    string
+		dataType any
+		want     any
+	}{
+		{
+			name: "network neighborhood containers and neighbors",
+			original: NetworkNeighborhood{Spec: NetworkNeighborhoodSpec{Containers: []NetworkNeighborhoodContainer{
+				{Name: "nginx", Egress: []NetworkNeighbor{{Identifier: "a", DNS: "a.com."}}},
+				{Name: "sidecar", Ingress: []NetworkNeighbor{{Identifier: "b", DNS: "b.com."}}},
+			}}},
+			patch:    `{"spec":{"containers":[{"name":"nginx","egress":[{"identifier":"c","dns":"c.com."}]}]}}`,
+			dataType: NetworkNeighborhood{},
+			want: NetworkNeighborhood{Spec: NetworkNeighborhoodSpec{Containers: []NetworkNeighborhoodContainer{
+				{Name: "nginx", Egress: []NetworkNeighbor{{Identifier: "c", DNS: "c.com."}, {Identifier: "a", DNS: "a.com."}}},
+				{Name: "sidecar", Ingress: []NetworkNeighbor{{Identifier: "b", DNS: "b.com."}}},
+			}}},
+		},
+		{
+			name: "application profile containers",
+			original: ApplicationProfile{Spec: ApplicationProfileSpec{Containers: []ApplicationProfileContainer{
+				{Name: "nginx", Syscalls: []string{"open"}},
+				{Name: "sidecar", Syscalls: []string{"read"}},
+			}}},
+			patch:    `{"spec":{"containers":[{"name":"nginx","rulePolicies":{"R0001":{"processAllowed":["ls"]}}}]}}`,
+			dataType: ApplicationProfile{},
+			want: ApplicationProfile{Spec: ApplicationProfileSpec{Containers: []ApplicationProfileContainer{
+				{Name: "nginx", Syscalls: []string{"open"}, PolicyByRuleId: map[string]RulePolicy{"R0001": {AllowedProcesses: []string{"ls"}}}},
+				{Name: "sidecar", Syscalls: []string{"read"}},
+			}}},
+		},
+		{
+			name:     "container profile neighbors",
+			original: ContainerProfile{Spec: ContainerProfileSpec{Ingress: []NetworkNeighbor{{Identifier: "a"}}}},
+			patch:    `{"spec":{"ingress":[{"identifier":"a","dns":"a.com."},{"identifier":"b"}]}}`,
+			dataType: ContainerProfile{},
+			want:     ContainerProfile{Spec: ContainerProfileSpec{Ingress: []NetworkNeighbor{{Identifier: "a", DNS: "a.com."}, {Identifier: "b"}}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original, err := json.Marshal(tt.original)
+			require.NoError(t, err)
+			patched, err := strategicpatch.StrategicMergePatch(original, []byte(tt.patch), tt.dataType)
+			require.NoError(t, err)
+			got := reflect.New(reflect.TypeOf(tt.want))
+			require.NoError(t, json.Unmarshal(patched, got.Interface()))
+			assert.Equal(t, tt.want, got.Elem().Interface())
+		})
+	}
+}
@@ -27,6 +27,7 @@ type NetworkNeighborhoodList struct {
 }
 
 // +genclient
+// +genclient:method=Evaluate,verb=create,subresource=evaluate,input=ProfileEvaluation,result=ProfileEvaluation
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // NetworkNeighborhood represents a list of network communications for a specific workload.
@@ -39,15 +40,35 @@ type NetworkNeighborhood struct {
 
 type NetworkNeighborhoodSpec struct {
 	metav1.LabelSelector `json:",inline" protobuf:"bytes,3,opt,name=labelSelector"`
-	Containers           []NetworkNeighborhoodContainer `json:"containers" protobuf:"bytes,4,rep,name=containers"`
-	InitContainers       []NetworkNeighborhoodContainer `json:"initContainers" protobuf:"bytes,5,rep,name=initContainers"`
-	EphemeralContainers  []NetworkNeighborhoodContainer `json:"ephemeralContainers" protobuf:"bytes,6,rep,name=ephemeralContainers"`
+	// +patchMergeKey=name
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=name
+	Containers []NetworkNeighborhoodContainer `json:"containers" patchStrategy:"merge" patchMergeKey:"name" protobuf:"bytes,4,rep,name=containers"`
+	// +patchMergeKey=name
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=name
+	InitContainers []NetworkNeighborhoodContainer `json:"initContainers" patchStrategy:"merge" patchMergeKey:"name" protobuf:"bytes,5,rep,name=initContainers"`
+	// +patchMergeKey=name
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=name
+	EphemeralContainers []NetworkNeighborhoodContainer `json:"ephemeralContainers" patchStrategy:"merge" patchMergeKey:"name" protobuf:"bytes,6,rep,name=ephemeralContainers"`
 }
 
 type NetworkNeighborhoodContainer struct {
-	Name    string            `json:"name" protobuf:"bytes,1,req,name=name"`
-	Ingress []NetworkNeighbor `json:"ingress" protobuf:"bytes,2,rep,name=ingress"`
-	Egress  []NetworkNeighbor `json:"egress" protobuf:"bytes,3,rep,name=egress"`
+	Name string `json:"name" protobuf:"bytes,1,req,name=name"`
+	// +patchMergeKey=identifier
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=identifier
+	Ingress []NetworkNeighbor `json:"ingress" patchStrategy:"merge" patchMergeKey:"identifier" protobuf:"bytes,2,rep,name=ingress"`
+	// +patchMergeKey=identifier
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=identifier
+	Egress []NetworkNeighbor `json:"egress" patchStrategy:"merge" patchMergeKey:"identifier" protobuf:"bytes,3,rep,name=egress"`
 }
 
 // NetworkNeighbor represents a single network communication made by this resource.
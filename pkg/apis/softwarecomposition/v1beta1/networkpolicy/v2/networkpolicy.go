@@ -12,12 +12,18 @@ const (
 )
 
 func GenerateNetworkPolicy(networkNeighborhood *v1beta1.NetworkNeighborhood, knownServersFinder sc.IKnownServersFinder, timeProvider metav1.Time) (v1beta1.GeneratedNetworkPolicy, error) {
+	return GenerateNetworkPolicyWithIngressResolver(networkNeighborhood, knownServersFinder, nil, timeProvider)
+}
+
+// GenerateNetworkPolicyWithIngressResolver is GenerateNetworkPolicy resolving
+// learned ingress IPs with ingressResolver, see np.GenerateNetworkPolicyWithIngressResolver.
+func GenerateNetworkPolicyWithIngressResolver(networkNeighborhood *v1beta1.NetworkNeighborhood, knownServersFinder sc.IKnownServersFinder, ingressResolver np.IngressResolver, timeProvider metav1.Time) (v1beta1.GeneratedNetworkPolicy, error) {
 	networkNeighborhoodV1, err := convertNetworkNeighborhood(networkNeighborhood)
 	if err != nil {
 		return v1beta1.GeneratedNetworkPolicy{}, err
 	}
 
-	npv1, err := np.GenerateNetworkPolicy(networkNeighborhoodV1, knownServersFinder, timeProvider)
+	npv1, err := np.GenerateNetworkPolicyWithIngressResolver(networkNeighborhoodV1, knownServersFinder, ingressResolver, timeProvider)
 	if err != nil {
 		return v1beta1.GeneratedNetworkPolicy{}, err
 	}
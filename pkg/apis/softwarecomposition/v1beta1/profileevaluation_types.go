@@ -0,0 +1,122 @@
+/*
+Copyright 2024 The Kubescape Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition/consts"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ObservationType is the kind of runtime event a ProfileObservation describes.
+type ObservationType string
+
+const (
+	ObservationTypeExec         ObservationType = "Exec"
+	ObservationTypeOpen         ObservationType = "Open"
+	ObservationTypeSyscall      ObservationType = "Syscall"
+	ObservationTypeCapability   ObservationType = "Capability"
+	ObservationTypeEgress       ObservationType = "Egress"
+	ObservationTypeIngress      ObservationType = "Ingress"
+	ObservationTypeHTTPEndpoint ObservationType = "HTTPEndpoint"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ProfileEvaluation is created on the evaluate subresource of an
+// ApplicationProfile, NetworkNeighborhood or ContainerProfile to ask whether
+// the stored profile covers a batch of observations. It is not persisted: the
+// response carries one result per observation, in the same order, computed
+// with the matchers the node-agent uses at runtime.
+type ProfileEvaluation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	Spec ProfileEvaluationSpec `json:"spec" protobuf:"bytes,2,req,name=spec"`
+	// +optional
+	Status ProfileEvaluationStatus `json:"status,omitempty" protobuf:"bytes,3,opt,name=status"`
+}
+
+// ProfileEvaluationSpec carries the observations to evaluate.
+type ProfileEvaluationSpec struct {
+	// ContainerName selects the container of an ApplicationProfile or a
+	// NetworkNeighborhood, it is ignored for a ContainerProfile.
+	// +optional
+	ContainerName string `json:"containerName,omitempty" protobuf:"bytes,1,opt,name=containerName"`
+	// +listType=atomic
+	Observations []ProfileObservation `json:"observations" protobuf:"bytes,2,rep,name=observations"`
+}
+
+// ProfileObservation is one runtime event, only the fields relevant to its
+// Type are read.
+type ProfileObservation struct {
+	// Type is one of Exec, Open, Syscall, Capability, Egress, Ingress or HTTPEndpoint.
+	Type ObservationType `json:"type" protobuf:"bytes,1,req,name=type"`
+	// Path is the executable of an Exec or the file of an Open.
+	// +optional
+	Path string `json:"path,omitempty" protobuf:"bytes,2,opt,name=path"`
+	// Args are the arguments of an Exec, recorded like the args of a profile exec.
+	// +optional
+	// +listType=atomic
+	Args []string `json:"args,omitempty" protobuf:"bytes,3,rep,name=args"`
+	// Flags are the flags of an Open.
+	// +optional
+	// +listType=atomic
+	Flags []string `json:"flags,omitempty" protobuf:"bytes,4,rep,name=flags"`
+	// Name is the syscall of a Syscall or the capability of a Capability.
+	// +optional
+	Name string `json:"name,omitempty" protobuf:"bytes,5,opt,name=name"`
+	// IPAddress is the peer of an Egress or an Ingress.
+	// +optional
+	IPAddress string `json:"ipAddress,omitempty" protobuf:"bytes,6,opt,name=ipAddress"`
+	// DNSName is the peer of an Egress or an Ingress.
+	// +optional
+	DNSName string `json:"dnsName,omitempty" protobuf:"bytes,7,opt,name=dnsName"`
+	// +optional
+	Protocol Protocol `json:"protocol,omitempty" protobuf:"bytes,8,opt,name=protocol"`
+	// +optional
+	Port *int32 `json:"port,omitempty" protobuf:"bytes,9,opt,name=port"`
+	// Endpoint is the port and path of an HTTPEndpoint, e.g. ":80/users/42".
+	// +optional
+	Endpoint string `json:"endpoint,omitempty" protobuf:"bytes,10,opt,name=endpoint"`
+	// +optional
+	Method string `json:"method,omitempty" protobuf:"bytes,11,opt,name=method"`
+	// +optional
+	Direction consts.NetworkDirection `json:"direction,omitempty" protobuf:"bytes,12,opt,name=direction"`
+}
+
+// ProfileEvaluationStatus carries the results of a ProfileEvaluation.
+type ProfileEvaluationStatus struct {
+	// Results has one entry per observation, in the order of the spec.
+	// +optional
+	// +listType=atomic
+	Results []ProfileObservationResult `json:"results,omitempty" protobuf:"bytes,1,rep,name=results"`
+}
+
+// ProfileObservationResult tells whether the profile covers an observation.
+type ProfileObservationResult struct {
+	// Allowed is true when an entry of the profile covers the observation.
+	Allowed bool `json:"allowed" protobuf:"varint,1,req,name=allowed"`
+	// MatchedField is the field path of the covering entry, e.g. "spec.containers[0].execs[3]".
+	// +optional
+	MatchedField string `json:"matchedField,omitempty" protobuf:"bytes,2,opt,name=matchedField"`
+	// MatchedEntry is the covering entry as stored, e.g. "/bin/sh -c ⋯⋯".
+	// +optional
+	MatchedEntry string `json:"matchedEntry,omitempty" protobuf:"bytes,3,opt,name=matchedEntry"`
+	// Reason explains why an observation is not allowed.
+	// +optional
+	Reason string `json:"reason,omitempty" protobuf:"bytes,4,opt,name=reason"`
+}
@@ -81,6 +81,11 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&SeccompProfileList{},
 		&CollapseConfiguration{},
 		&CollapseConfigurationList{},
+		&Guardrail{},
+		&GuardrailList{},
+		&ProfileEvaluation{},
+		&CompiledContainerProfile{},
+		&CallStackQuery{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
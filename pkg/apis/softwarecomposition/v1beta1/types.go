@@ -180,6 +180,9 @@ type VulnerabilitySummaryList struct {
 }
 
 // +genclient
+// +genclient:method=Evaluate,verb=create,subresource=evaluate,input=ProfileEvaluation,result=ProfileEvaluation
+// +genclient:method=GetPending,verb=get,subresource=pending
+// +genclient:method=QueryCallStacks,verb=create,subresource=callstacks,input=CallStackQuery,result=CallStackQuery
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 type ApplicationProfile struct {
@@ -194,12 +197,18 @@ type ApplicationProfileSpec struct {
 	Architectures []string `json:"architectures" protobuf:"bytes,1,rep,name=architectures"`
 	// +patchMergeKey=name
 	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=name
 	Containers []ApplicationProfileContainer `json:"containers,omitempty" patchStrategy:"merge" patchMergeKey:"name" protobuf:"bytes,2,rep,name=containers"`
 	// +patchMergeKey=name
 	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=name
 	InitContainers []ApplicationProfileContainer `json:"initContainers,omitempty" patchStrategy:"merge" patchMergeKey:"name" protobuf:"bytes,3,rep,name=initContainers"`
 	// +patchMergeKey=name
 	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=name
 	EphemeralContainers []ApplicationProfileContainer `json:"ephemeralContainers,omitempty" patchStrategy:"merge" patchMergeKey:"name" protobuf:"bytes,4,rep,name=ephemeralContainers"`
 }
 
@@ -280,6 +289,10 @@ type ApplicationProfileList struct {
 }
 
 // +genclient
+// +genclient:method=Evaluate,verb=create,subresource=evaluate,input=ProfileEvaluation,result=ProfileEvaluation
+// +genclient:method=GetCompiled,verb=get,subresource=compiled,result=CompiledContainerProfile
+// +genclient:method=GetPending,verb=get,subresource=pending
+// +genclient:method=QueryCallStacks,verb=create,subresource=callstacks,input=CallStackQuery,result=CallStackQuery
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 type ContainerProfile struct {
@@ -313,8 +326,16 @@ type ContainerProfileSpec struct {
 	IdentifiedCallStacks []IdentifiedCallStack `json:"identifiedCallStacks" protobuf:"bytes,11,rep,name=identifiedCallStacks"`
 	// WARNING report fields from NetworkNeighborhoodContainer here, increment proto IDs by 100
 	metav1.LabelSelector `json:",inline" protobuf:"bytes,101,opt,name=labelSelector"`
-	Ingress              []NetworkNeighbor `json:"ingress" protobuf:"bytes,102,rep,name=ingress"`
-	Egress               []NetworkNeighbor `json:"egress" protobuf:"bytes,103,rep,name=egress"`
+	// +patchMergeKey=identifier
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=identifier
+	Ingress []NetworkNeighbor `json:"ingress" patchStrategy:"merge" patchMergeKey:"identifier" protobuf:"bytes,102,rep,name=ingress"`
+	// +patchMergeKey=identifier
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=identifier
+	Egress []NetworkNeighbor `json:"egress" patchStrategy:"merge" patchMergeKey:"identifier" protobuf:"bytes,103,rep,name=egress"`
 }
 
 type ContainerProfileStatus struct {
@@ -639,6 +660,14 @@ type HTTPEndpoint struct {
 	Internal  bool                    `json:"internal" protobuf:"bytes,3,opt,name=internal"`
 	Direction consts.NetworkDirection `json:"direction,omitempty" protobuf:"bytes,4,opt,name=direction"`
 	Headers   json.RawMessage         `json:"headers,omitempty" protobuf:"bytes,5,opt,name=headers"`
+	// QueryParams are the names of the query parameters sent to the endpoint,
+	// their values are not kept.
+	QueryParams []string `json:"queryParams,omitempty" protobuf:"bytes,6,rep,name=queryParams"`
+	// StatusClasses are the classes of the response status codes, e.g. "2xx".
+	StatusClasses []string `json:"statusClasses,omitempty" protobuf:"bytes,7,rep,name=statusClasses"`
+	// ContentTypes are the media types of the request and response bodies,
+	// without their parameters, e.g. "application/json".
+	ContentTypes []string `json:"contentTypes,omitempty" protobuf:"bytes,8,rep,name=contentTypes"`
 }
 
 func (e *HTTPEndpoint) GetHeaders() (map[string][]string, error) {
@@ -141,6 +141,46 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*CallStackQuery)(nil), (*softwarecomposition.CallStackQuery)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_CallStackQuery_To_softwarecomposition_CallStackQuery(a.(*CallStackQuery), b.(*softwarecomposition.CallStackQuery), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*softwarecomposition.CallStackQuery)(nil), (*CallStackQuery)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_softwarecomposition_CallStackQuery_To_v1beta1_CallStackQuery(a.(*softwarecomposition.CallStackQuery), b.(*CallStackQuery), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*CallStackQuerySpec)(nil), (*softwarecomposition.CallStackQuerySpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_CallStackQuerySpec_To_softwarecomposition_CallStackQuerySpec(a.(*CallStackQuerySpec), b.(*softwarecomposition.CallStackQuerySpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*softwarecomposition.CallStackQuerySpec)(nil), (*CallStackQuerySpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_softwarecomposition_CallStackQuerySpec_To_v1beta1_CallStackQuerySpec(a.(*softwarecomposition.CallStackQuerySpec), b.(*CallStackQuerySpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*CallStackQueryStatus)(nil), (*softwarecomposition.CallStackQueryStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_CallStackQueryStatus_To_softwarecomposition_CallStackQueryStatus(a.(*CallStackQueryStatus), b.(*softwarecomposition.CallStackQueryStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*softwarecomposition.CallStackQueryStatus)(nil), (*CallStackQueryStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_softwarecomposition_CallStackQueryStatus_To_v1beta1_CallStackQueryStatus(a.(*softwarecomposition.CallStackQueryStatus), b.(*CallStackQueryStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*CallStackReference)(nil), (*softwarecomposition.CallStackReference)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_CallStackReference_To_softwarecomposition_CallStackReference(a.(*CallStackReference), b.(*softwarecomposition.CallStackReference), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*softwarecomposition.CallStackReference)(nil), (*CallStackReference)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_softwarecomposition_CallStackReference_To_v1beta1_CallStackReference(a.(*softwarecomposition.CallStackReference), b.(*CallStackReference), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*CollapseConfigEntry)(nil), (*softwarecomposition.CollapseConfigEntry)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta1_CollapseConfigEntry_To_softwarecomposition_CollapseConfigEntry(a.(*CollapseConfigEntry), b.(*softwarecomposition.CollapseConfigEntry), scope)
 	}); err != nil {
@@ -181,6 +221,26 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*CompiledContainerProfile)(nil), (*softwarecomposition.CompiledContainerProfile)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_CompiledContainerProfile_To_softwarecomposition_CompiledContainerProfile(a.(*CompiledContainerProfile), b.(*softwarecomposition.CompiledContainerProfile), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*softwarecomposition.CompiledContainerProfile)(nil), (*CompiledContainerProfile)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_softwarecomposition_CompiledContainerProfile_To_v1beta1_CompiledContainerProfile(a.(*softwarecomposition.CompiledContainerProfile), b.(*CompiledContainerProfile), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*CompiledContainerProfileSpec)(nil), (*softwarecomposition.CompiledContainerProfileSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_CompiledContainerProfileSpec_To_softwarecomposition_CompiledContainerProfileSpec(a.(*CompiledContainerProfileSpec), b.(*softwarecomposition.CompiledContainerProfileSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*softwarecomposition.CompiledContainerProfileSpec)(nil), (*CompiledContainerProfileSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_softwarecomposition_CompiledContainerProfileSpec_To_v1beta1_CompiledContainerProfileSpec(a.(*softwarecomposition.CompiledContainerProfileSpec), b.(*CompiledContainerProfileSpec), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*Component)(nil), (*softwarecomposition.Component)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta1_Component_To_softwarecomposition_Component(a.(*Component), b.(*softwarecomposition.Component), scope)
 	}); err != nil {
@@ -461,6 +521,66 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*Guardrail)(nil), (*softwarecomposition.Guardrail)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_Guardrail_To_softwarecomposition_Guardrail(a.(*Guardrail), b.(*softwarecomposition.Guardrail), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*softwarecomposition.Guardrail)(nil), (*Guardrail)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_softwarecomposition_Guardrail_To_v1beta1_Guardrail(a.(*softwarecomposition.Guardrail), b.(*Guardrail), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*GuardrailList)(nil), (*softwarecomposition.GuardrailList)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_GuardrailList_To_softwarecomposition_GuardrailList(a.(*GuardrailList), b.(*softwarecomposition.GuardrailList), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*softwarecomposition.GuardrailList)(nil), (*GuardrailList)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_softwarecomposition_GuardrailList_To_v1beta1_GuardrailList(a.(*softwarecomposition.GuardrailList), b.(*GuardrailList), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*GuardrailRule)(nil), (*softwarecomposition.GuardrailRule)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_GuardrailRule_To_softwarecomposition_GuardrailRule(a.(*GuardrailRule), b.(*softwarecomposition.GuardrailRule), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*softwarecomposition.GuardrailRule)(nil), (*GuardrailRule)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_softwarecomposition_GuardrailRule_To_v1beta1_GuardrailRule(a.(*softwarecomposition.GuardrailRule), b.(*GuardrailRule), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*GuardrailSpec)(nil), (*softwarecomposition.GuardrailSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_GuardrailSpec_To_softwarecomposition_GuardrailSpec(a.(*GuardrailSpec), b.(*softwarecomposition.GuardrailSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*softwarecomposition.GuardrailSpec)(nil), (*GuardrailSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_softwarecomposition_GuardrailSpec_To_v1beta1_GuardrailSpec(a.(*softwarecomposition.GuardrailSpec), b.(*GuardrailSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*GuardrailStatus)(nil), (*softwarecomposition.GuardrailStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_GuardrailStatus_To_softwarecomposition_GuardrailStatus(a.(*GuardrailStatus), b.(*softwarecomposition.GuardrailStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*softwarecomposition.GuardrailStatus)(nil), (*GuardrailStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_softwarecomposition_GuardrailStatus_To_v1beta1_GuardrailStatus(a.(*softwarecomposition.GuardrailStatus), b.(*GuardrailStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*GuardrailViolation)(nil), (*softwarecomposition.GuardrailViolation)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_GuardrailViolation_To_softwarecomposition_GuardrailViolation(a.(*GuardrailViolation), b.(*softwarecomposition.GuardrailViolation), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*softwarecomposition.GuardrailViolation)(nil), (*GuardrailViolation)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_softwarecomposition_GuardrailViolation_To_v1beta1_GuardrailViolation(a.(*softwarecomposition.GuardrailViolation), b.(*GuardrailViolation), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*HTTPEndpoint)(nil), (*softwarecomposition.HTTPEndpoint)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta1_HTTPEndpoint_To_softwarecomposition_HTTPEndpoint(a.(*HTTPEndpoint), b.(*softwarecomposition.HTTPEndpoint), scope)
 	}); err != nil {
@@ -1031,6 +1151,56 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*ProfileEvaluation)(nil), (*softwarecomposition.ProfileEvaluation)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_ProfileEvaluation_To_softwarecomposition_ProfileEvaluation(a.(*ProfileEvaluation), b.(*softwarecomposition.ProfileEvaluation), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*softwarecomposition.ProfileEvaluation)(nil), (*ProfileEvaluation)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_softwarecomposition_ProfileEvaluation_To_v1beta1_ProfileEvaluation(a.(*softwarecomposition.ProfileEvaluation), b.(*ProfileEvaluation), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*ProfileEvaluationSpec)(nil), (*softwarecomposition.ProfileEvaluationSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_ProfileEvaluationSpec_To_softwarecomposition_ProfileEvaluationSpec(a.(*ProfileEvaluationSpec), b.(*softwarecomposition.ProfileEvaluationSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*softwarecomposition.ProfileEvaluationSpec)(nil), (*ProfileEvaluationSpec)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_softwarecomposition_ProfileEvaluationSpec_To_v1beta1_ProfileEvaluationSpec(a.(*softwarecomposition.ProfileEvaluationSpec), b.(*ProfileEvaluationSpec), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*ProfileEvaluationStatus)(nil), (*softwarecomposition.ProfileEvaluationStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_ProfileEvaluationStatus_To_softwarecomposition_ProfileEvaluationStatus(a.(*ProfileEvaluationStatus), b.(*softwarecomposition.ProfileEvaluationStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*softwarecomposition.ProfileEvaluationStatus)(nil), (*ProfileEvaluationStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_softwarecomposition_ProfileEvaluationStatus_To_v1beta1_ProfileEvaluationStatus(a.(*softwarecomposition.ProfileEvaluationStatus), b.(*ProfileEvaluationStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*ProfileObservation)(nil), (*softwarecomposition.ProfileObservation)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_ProfileObservation_To_softwarecomposition_ProfileObservation(a.(*ProfileObservation), b.(*softwarecomposition.ProfileObservation), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*softwarecomposition.ProfileObservation)(nil), (*ProfileObservation)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_softwarecomposition_ProfileObservation_To_v1beta1_ProfileObservation(a.(*softwarecomposition.ProfileObservation), b.(*ProfileObservation), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*ProfileObservationResult)(nil), (*softwarecomposition.ProfileObservationResult)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1beta1_ProfileObservationResult_To_softwarecomposition_ProfileObservationResult(a.(*ProfileObservationResult), b.(*softwarecomposition.ProfileObservationResult), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*softwarecomposition.ProfileObservationResult)(nil), (*ProfileObservationResult)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_softwarecomposition_ProfileObservationResult_To_v1beta1_ProfileObservationResult(a.(*softwarecomposition.ProfileObservationResult), b.(*ProfileObservationResult), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*ReportMeta)(nil), (*softwarecomposition.ReportMeta)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1beta1_ReportMeta_To_softwarecomposition_ReportMeta(a.(*ReportMeta), b.(*softwarecomposition.ReportMeta), scope)
 	}); err != nil {
@@ -2028,6 +2198,116 @@ func Convert_softwarecomposition_CallStackNode_To_v1beta1_CallStackNode(in *soft
 	return autoConvert_softwarecomposition_CallStackNode_To_v1beta1_CallStackNode(in, out, s)
 }
 
+func autoConvert_v1beta1_CallStackQuery_To_softwarecomposition_CallStackQuery(in *CallStackQuery, out *softwarecomposition.CallStackQuery, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_v1beta1_CallStackQuerySpec_To_softwarecomposition_CallStackQuerySpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	if err := Convert_v1beta1_CallStackQueryStatus_To_softwarecomposition_CallStackQueryStatus(&in.Status, &out.Status, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1beta1_CallStackQuery_To_softwarecomposition_CallStackQuery is an autogenerated conversion function.
+func Convert_v1beta1_CallStackQuery_To_softwarecomposition_CallStackQuery(in *CallStackQuery, out *softwarecomposition.CallStackQuery, s conversion.Scope) error {
+	return autoConvert_v1beta1_CallStackQuery_To_softwarecomposition_CallStackQuery(in, out, s)
+}
+
+func autoConvert_softwarecomposition_CallStackQuery_To_v1beta1_CallStackQuery(in *softwarecomposition.CallStackQuery, out *CallStackQuery, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_softwarecomposition_CallStackQuerySpec_To_v1beta1_CallStackQuerySpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	if err := Convert_softwarecomposition_CallStackQueryStatus_To_v1beta1_CallStackQueryStatus(&in.Status, &out.Status, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_softwarecomposition_CallStackQuery_To_v1beta1_CallStackQuery is an autogenerated conversion function.
+func Convert_softwarecomposition_CallStackQuery_To_v1beta1_CallStackQuery(in *softwarecomposition.CallStackQuery, out *CallStackQuery, s conversion.Scope) error {
+	return autoConvert_softwarecomposition_CallStackQuery_To_v1beta1_CallStackQuery(in, out, s)
+}
+
+func autoConvert_v1beta1_CallStackQuerySpec_To_softwarecomposition_CallStackQuerySpec(in *CallStackQuerySpec, out *softwarecomposition.CallStackQuerySpec, s conversion.Scope) error {
+	out.ContainerName = in.ContainerName
+	out.CallID = softwarecomposition.CallID(in.CallID)
+	out.Frame = (*softwarecomposition.StackFrame)(unsafe.Pointer(in.Frame))
+	return nil
+}
+
+// Convert_v1beta1_CallStackQuerySpec_To_softwarecomposition_CallStackQuerySpec is an autogenerated conversion function.
+func Convert_v1beta1_CallStackQuerySpec_To_softwarecomposition_CallStackQuerySpec(in *CallStackQuerySpec, out *softwarecomposition.CallStackQuerySpec, s conversion.Scope) error {
+	return autoConvert_v1beta1_CallStackQuerySpec_To_softwarecomposition_CallStackQuerySpec(in, out, s)
+}
+
+func autoConvert_softwarecomposition_CallStackQuerySpec_To_v1beta1_CallStackQuerySpec(in *softwarecomposition.CallStackQuerySpec, out *CallStackQuerySpec, s conversion.Scope) error {
+	out.ContainerName = in.ContainerName
+	out.CallID = CallID(in.CallID)
+	out.Frame = (*StackFrame)(unsafe.Pointer(in.Frame))
+	return nil
+}
+
+// Convert_softwarecomposition_CallStackQuerySpec_To_v1beta1_CallStackQuerySpec is an autogenerated conversion function.
+func Convert_softwarecomposition_CallStackQuerySpec_To_v1beta1_CallStackQuerySpec(in *softwarecomposition.CallStackQuerySpec, out *CallStackQuerySpec, s conversion.Scope) error {
+	return autoConvert_softwarecomposition_CallStackQuerySpec_To_v1beta1_CallStackQuerySpec(in, out, s)
+}
+
+func autoConvert_v1beta1_CallStackQueryStatus_To_softwarecomposition_CallStackQueryStatus(in *CallStackQueryStatus, out *softwarecomposition.CallStackQueryStatus, s conversion.Scope) error {
+	out.CallStacks = *(*[]softwarecomposition.IdentifiedCallStack)(unsafe.Pointer(&in.CallStacks))
+	out.References = *(*[]softwarecomposition.CallStackReference)(unsafe.Pointer(&in.References))
+	return nil
+}
+
+// Convert_v1beta1_CallStackQueryStatus_To_softwarecomposition_CallStackQueryStatus is an autogenerated conversion function.
+func Convert_v1beta1_CallStackQueryStatus_To_softwarecomposition_CallStackQueryStatus(in *CallStackQueryStatus, out *softwarecomposition.CallStackQueryStatus, s conversion.Scope) error {
+	return autoConvert_v1beta1_CallStackQueryStatus_To_softwarecomposition_CallStackQueryStatus(in, out, s)
+}
+
+func autoConvert_softwarecomposition_CallStackQueryStatus_To_v1beta1_CallStackQueryStatus(in *softwarecomposition.CallStackQueryStatus, out *CallStackQueryStatus, s conversion.Scope) error {
+	out.CallStacks = *(*[]IdentifiedCallStack)(unsafe.Pointer(&in.CallStacks))
+	out.References = *(*[]CallStackReference)(unsafe.Pointer(&in.References))
+	return nil
+}
+
+// Convert_softwarecomposition_CallStackQueryStatus_To_v1beta1_CallStackQueryStatus is an autogenerated conversion function.
+func Convert_softwarecomposition_CallStackQueryStatus_To_v1beta1_CallStackQueryStatus(in *softwarecomposition.CallStackQueryStatus, out *CallStackQueryStatus, s conversion.Scope) error {
+	return autoConvert_softwarecomposition_CallStackQueryStatus_To_v1beta1_CallStackQueryStatus(in, out, s)
+}
+
+func autoConvert_v1beta1_CallStackReference_To_softwarecomposition_CallStackReference(in *CallStackReference, out *softwarecomposition.CallStackReference, s conversion.Scope) error {
+	out.Kind = in.Kind
+	out.Name = in.Name
+	out.ContainerName = in.ContainerName
+	out.CallID = softwarecomposition.CallID(in.CallID)
+	if err := Convert_v1beta1_StackFrame_To_softwarecomposition_StackFrame(&in.Frame, &out.Frame, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1beta1_CallStackReference_To_softwarecomposition_CallStackReference is an autogenerated conversion function.
+func Convert_v1beta1_CallStackReference_To_softwarecomposition_CallStackReference(in *CallStackReference, out *softwarecomposition.CallStackReference, s conversion.Scope) error {
+	return autoConvert_v1beta1_CallStackReference_To_softwarecomposition_CallStackReference(in, out, s)
+}
+
+func autoConvert_softwarecomposition_CallStackReference_To_v1beta1_CallStackReference(in *softwarecomposition.CallStackReference, out *CallStackReference, s conversion.Scope) error {
+	out.Kind = in.Kind
+	out.Name = in.Name
+	out.ContainerName = in.ContainerName
+	out.CallID = CallID(in.CallID)
+	if err := Convert_softwarecomposition_StackFrame_To_v1beta1_StackFrame(&in.Frame, &out.Frame, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_softwarecomposition_CallStackReference_To_v1beta1_CallStackReference is an autogenerated conversion function.
+func Convert_softwarecomposition_CallStackReference_To_v1beta1_CallStackReference(in *softwarecomposition.CallStackReference, out *CallStackReference, s conversion.Scope) error {
+	return autoConvert_softwarecomposition_CallStackReference_To_v1beta1_CallStackReference(in, out, s)
+}
+
 func autoConvert_v1beta1_CollapseConfigEntry_To_softwarecomposition_CollapseConfigEntry(in *CollapseConfigEntry, out *softwarecomposition.CollapseConfigEntry, s conversion.Scope) error {
 	out.Prefix = in.Prefix
 	out.Threshold = in.Threshold
@@ -2102,6 +2382,13 @@ func autoConvert_v1beta1_CollapseConfigurationSpec_To_softwarecomposition_Collap
 	out.OpenDynamicThreshold = in.OpenDynamicThreshold
 	out.EndpointDynamicThreshold = in.EndpointDynamicThreshold
 	out.CollapseConfigs = *(*[]softwarecomposition.CollapseConfigEntry)(unsafe.Pointer(&in.CollapseConfigs))
+	out.IPCollapseThreshold = in.IPCollapseThreshold
+	out.DNSCollapseThreshold = in.DNSCollapseThreshold
+	out.ExecArgsCollapseThreshold = in.ExecArgsCollapseThreshold
+	out.EnvCollapseThreshold = in.EnvCollapseThreshold
+	out.NamespaceSelector = (*metav1.LabelSelector)(unsafe.Pointer(in.NamespaceSelector))
+	out.WorkloadSelector = (*metav1.LabelSelector)(unsafe.Pointer(in.WorkloadSelector))
+	out.HeaderAllowlist = *(*[]string)(unsafe.Pointer(&in.HeaderAllowlist))
 	return nil
 }
 
@@ -2114,6 +2401,13 @@ func autoConvert_softwarecomposition_CollapseConfigurationSpec_To_v1beta1_Collap
 	out.OpenDynamicThreshold = in.OpenDynamicThreshold
 	out.EndpointDynamicThreshold = in.EndpointDynamicThreshold
 	out.CollapseConfigs = *(*[]CollapseConfigEntry)(unsafe.Pointer(&in.CollapseConfigs))
+	out.IPCollapseThreshold = in.IPCollapseThreshold
+	out.DNSCollapseThreshold = in.DNSCollapseThreshold
+	out.ExecArgsCollapseThreshold = in.ExecArgsCollapseThreshold
+	out.EnvCollapseThreshold = in.EnvCollapseThreshold
+	out.NamespaceSelector = (*metav1.LabelSelector)(unsafe.Pointer(in.NamespaceSelector))
+	out.WorkloadSelector = (*metav1.LabelSelector)(unsafe.Pointer(in.WorkloadSelector))
+	out.HeaderAllowlist = *(*[]string)(unsafe.Pointer(&in.HeaderAllowlist))
 	return nil
 }
 
@@ -2122,6 +2416,56 @@ func Convert_softwarecomposition_CollapseConfigurationSpec_To_v1beta1_CollapseCo
 	return autoConvert_softwarecomposition_CollapseConfigurationSpec_To_v1beta1_CollapseConfigurationSpec(in, out, s)
 }
 
+func autoConvert_v1beta1_CompiledContainerProfile_To_softwarecomposition_CompiledContainerProfile(in *CompiledContainerProfile, out *softwarecomposition.CompiledContainerProfile, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_v1beta1_CompiledContainerProfileSpec_To_softwarecomposition_CompiledContainerProfileSpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1beta1_CompiledContainerProfile_To_softwarecomposition_CompiledContainerProfile is an autogenerated conversion function.
+func Convert_v1beta1_CompiledContainerProfile_To_softwarecomposition_CompiledContainerProfile(in *CompiledContainerProfile, out *softwarecomposition.CompiledContainerProfile, s conversion.Scope) error {
+	return autoConvert_v1beta1_CompiledContainerProfile_To_softwarecomposition_CompiledContainerProfile(in, out, s)
+}
+
+func autoConvert_softwarecomposition_CompiledContainerProfile_To_v1beta1_CompiledContainerProfile(in *softwarecomposition.CompiledContainerProfile, out *CompiledContainerProfile, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_softwarecomposition_CompiledContainerProfileSpec_To_v1beta1_CompiledContainerProfileSpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_softwarecomposition_CompiledContainerProfile_To_v1beta1_CompiledContainerProfile is an autogenerated conversion function.
+func Convert_softwarecomposition_CompiledContainerProfile_To_v1beta1_CompiledContainerProfile(in *softwarecomposition.CompiledContainerProfile, out *CompiledContainerProfile, s conversion.Scope) error {
+	return autoConvert_softwarecomposition_CompiledContainerProfile_To_v1beta1_CompiledContainerProfile(in, out, s)
+}
+
+func autoConvert_v1beta1_CompiledContainerProfileSpec_To_softwarecomposition_CompiledContainerProfileSpec(in *CompiledContainerProfileSpec, out *softwarecomposition.CompiledContainerProfileSpec, s conversion.Scope) error {
+	out.FormatVersion = in.FormatVersion
+	out.SourceResourceVersion = in.SourceResourceVersion
+	out.Data = *(*[]byte)(unsafe.Pointer(&in.Data))
+	return nil
+}
+
+// Convert_v1beta1_CompiledContainerProfileSpec_To_softwarecomposition_CompiledContainerProfileSpec is an autogenerated conversion function.
+func Convert_v1beta1_CompiledContainerProfileSpec_To_softwarecomposition_CompiledContainerProfileSpec(in *CompiledContainerProfileSpec, out *softwarecomposition.CompiledContainerProfileSpec, s conversion.Scope) error {
+	return autoConvert_v1beta1_CompiledContainerProfileSpec_To_softwarecomposition_CompiledContainerProfileSpec(in, out, s)
+}
+
+func autoConvert_softwarecomposition_CompiledContainerProfileSpec_To_v1beta1_CompiledContainerProfileSpec(in *softwarecomposition.CompiledContainerProfileSpec, out *CompiledContainerProfileSpec, s conversion.Scope) error {
+	out.FormatVersion = in.FormatVersion
+	out.SourceResourceVersion = in.SourceResourceVersion
+	out.Data = *(*[]byte)(unsafe.Pointer(&in.Data))
+	return nil
+}
+
+// Convert_softwarecomposition_CompiledContainerProfileSpec_To_v1beta1_CompiledContainerProfileSpec is an autogenerated conversion function.
+func Convert_softwarecomposition_CompiledContainerProfileSpec_To_v1beta1_CompiledContainerProfileSpec(in *softwarecomposition.CompiledContainerProfileSpec, out *CompiledContainerProfileSpec, s conversion.Scope) error {
+	return autoConvert_softwarecomposition_CompiledContainerProfileSpec_To_v1beta1_CompiledContainerProfileSpec(in, out, s)
+}
+
 func autoConvert_v1beta1_Component_To_softwarecomposition_Component(in *Component, out *softwarecomposition.Component, s conversion.Scope) error {
 	out.ID = in.ID
 	out.Hashes = *(*map[softwarecomposition.Algorithm]softwarecomposition.Hash)(unsafe.Pointer(&in.Hashes))
@@ -2882,12 +3226,163 @@ func Convert_softwarecomposition_GrypePackage_To_v1beta1_GrypePackage(in *softwa
 	return autoConvert_softwarecomposition_GrypePackage_To_v1beta1_GrypePackage(in, out, s)
 }
 
+func autoConvert_v1beta1_Guardrail_To_softwarecomposition_Guardrail(in *Guardrail, out *softwarecomposition.Guardrail, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_v1beta1_GuardrailSpec_To_softwarecomposition_GuardrailSpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	if err := Convert_v1beta1_GuardrailStatus_To_softwarecomposition_GuardrailStatus(&in.Status, &out.Status, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1beta1_Guardrail_To_softwarecomposition_Guardrail is an autogenerated conversion function.
+func Convert_v1beta1_Guardrail_To_softwarecomposition_Guardrail(in *Guardrail, out *softwarecomposition.Guardrail, s conversion.Scope) error {
+	return autoConvert_v1beta1_Guardrail_To_softwarecomposition_Guardrail(in, out, s)
+}
+
+func autoConvert_softwarecomposition_Guardrail_To_v1beta1_Guardrail(in *softwarecomposition.Guardrail, out *Guardrail, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_softwarecomposition_GuardrailSpec_To_v1beta1_GuardrailSpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	if err := Convert_softwarecomposition_GuardrailStatus_To_v1beta1_GuardrailStatus(&in.Status, &out.Status, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_softwarecomposition_Guardrail_To_v1beta1_Guardrail is an autogenerated conversion function.
+func Convert_softwarecomposition_Guardrail_To_v1beta1_Guardrail(in *softwarecomposition.Guardrail, out *Guardrail, s conversion.Scope) error {
+	return autoConvert_softwarecomposition_Guardrail_To_v1beta1_Guardrail(in, out, s)
+}
+
+func autoConvert_v1beta1_GuardrailList_To_softwarecomposition_GuardrailList(in *GuardrailList, out *softwarecomposition.GuardrailList, s conversion.Scope) error {
+	out.ListMeta = in.ListMeta
+	out.Items = *(*[]softwarecomposition.Guardrail)(unsafe.Pointer(&in.Items))
+	return nil
+}
+
+// Convert_v1beta1_GuardrailList_To_softwarecomposition_GuardrailList is an autogenerated conversion function.
+func Convert_v1beta1_GuardrailList_To_softwarecomposition_GuardrailList(in *GuardrailList, out *softwarecomposition.GuardrailList, s conversion.Scope) error {
+	return autoConvert_v1beta1_GuardrailList_To_softwarecomposition_GuardrailList(in, out, s)
+}
+
+func autoConvert_softwarecomposition_GuardrailList_To_v1beta1_GuardrailList(in *softwarecomposition.GuardrailList, out *GuardrailList, s conversion.Scope) error {
+	out.ListMeta = in.ListMeta
+	out.Items = *(*[]Guardrail)(unsafe.Pointer(&in.Items))
+	return nil
+}
+
+// Convert_softwarecomposition_GuardrailList_To_v1beta1_GuardrailList is an autogenerated conversion function.
+func Convert_softwarecomposition_GuardrailList_To_v1beta1_GuardrailList(in *softwarecomposition.GuardrailList, out *GuardrailList, s conversion.Scope) error {
+	return autoConvert_softwarecomposition_GuardrailList_To_v1beta1_GuardrailList(in, out, s)
+}
+
+func autoConvert_v1beta1_GuardrailRule_To_softwarecomposition_GuardrailRule(in *GuardrailRule, out *softwarecomposition.GuardrailRule, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Expression = in.Expression
+	out.Message = in.Message
+	return nil
+}
+
+// Convert_v1beta1_GuardrailRule_To_softwarecomposition_GuardrailRule is an autogenerated conversion function.
+func Convert_v1beta1_GuardrailRule_To_softwarecomposition_GuardrailRule(in *GuardrailRule, out *softwarecomposition.GuardrailRule, s conversion.Scope) error {
+	return autoConvert_v1beta1_GuardrailRule_To_softwarecomposition_GuardrailRule(in, out, s)
+}
+
+func autoConvert_softwarecomposition_GuardrailRule_To_v1beta1_GuardrailRule(in *softwarecomposition.GuardrailRule, out *GuardrailRule, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Expression = in.Expression
+	out.Message = in.Message
+	return nil
+}
+
+// Convert_softwarecomposition_GuardrailRule_To_v1beta1_GuardrailRule is an autogenerated conversion function.
+func Convert_softwarecomposition_GuardrailRule_To_v1beta1_GuardrailRule(in *softwarecomposition.GuardrailRule, out *GuardrailRule, s conversion.Scope) error {
+	return autoConvert_softwarecomposition_GuardrailRule_To_v1beta1_GuardrailRule(in, out, s)
+}
+
+func autoConvert_v1beta1_GuardrailSpec_To_softwarecomposition_GuardrailSpec(in *GuardrailSpec, out *softwarecomposition.GuardrailSpec, s conversion.Scope) error {
+	out.MatchKinds = *(*[]string)(unsafe.Pointer(&in.MatchKinds))
+	out.Rules = *(*[]softwarecomposition.GuardrailRule)(unsafe.Pointer(&in.Rules))
+	return nil
+}
+
+// Convert_v1beta1_GuardrailSpec_To_softwarecomposition_GuardrailSpec is an autogenerated conversion function.
+func Convert_v1beta1_GuardrailSpec_To_softwarecomposition_GuardrailSpec(in *GuardrailSpec, out *softwarecomposition.GuardrailSpec, s conversion.Scope) error {
+	return autoConvert_v1beta1_GuardrailSpec_To_softwarecomposition_GuardrailSpec(in, out, s)
+}
+
+func autoConvert_softwarecomposition_GuardrailSpec_To_v1beta1_GuardrailSpec(in *softwarecomposition.GuardrailSpec, out *GuardrailSpec, s conversion.Scope) error {
+	out.MatchKinds = *(*[]string)(unsafe.Pointer(&in.MatchKinds))
+	out.Rules = *(*[]GuardrailRule)(unsafe.Pointer(&in.Rules))
+	return nil
+}
+
+// Convert_softwarecomposition_GuardrailSpec_To_v1beta1_GuardrailSpec is an autogenerated conversion function.
+func Convert_softwarecomposition_GuardrailSpec_To_v1beta1_GuardrailSpec(in *softwarecomposition.GuardrailSpec, out *GuardrailSpec, s conversion.Scope) error {
+	return autoConvert_softwarecomposition_GuardrailSpec_To_v1beta1_GuardrailSpec(in, out, s)
+}
+
+func autoConvert_v1beta1_GuardrailStatus_To_softwarecomposition_GuardrailStatus(in *GuardrailStatus, out *softwarecomposition.GuardrailStatus, s conversion.Scope) error {
+	out.Violations = *(*[]softwarecomposition.GuardrailViolation)(unsafe.Pointer(&in.Violations))
+	return nil
+}
+
+// Convert_v1beta1_GuardrailStatus_To_softwarecomposition_GuardrailStatus is an autogenerated conversion function.
+func Convert_v1beta1_GuardrailStatus_To_softwarecomposition_GuardrailStatus(in *GuardrailStatus, out *softwarecomposition.GuardrailStatus, s conversion.Scope) error {
+	return autoConvert_v1beta1_GuardrailStatus_To_softwarecomposition_GuardrailStatus(in, out, s)
+}
+
+func autoConvert_softwarecomposition_GuardrailStatus_To_v1beta1_GuardrailStatus(in *softwarecomposition.GuardrailStatus, out *GuardrailStatus, s conversion.Scope) error {
+	out.Violations = *(*[]GuardrailViolation)(unsafe.Pointer(&in.Violations))
+	return nil
+}
+
+// Convert_softwarecomposition_GuardrailStatus_To_v1beta1_GuardrailStatus is an autogenerated conversion function.
+func Convert_softwarecomposition_GuardrailStatus_To_v1beta1_GuardrailStatus(in *softwarecomposition.GuardrailStatus, out *GuardrailStatus, s conversion.Scope) error {
+	return autoConvert_softwarecomposition_GuardrailStatus_To_v1beta1_GuardrailStatus(in, out, s)
+}
+
+func autoConvert_v1beta1_GuardrailViolation_To_softwarecomposition_GuardrailViolation(in *GuardrailViolation, out *softwarecomposition.GuardrailViolation, s conversion.Scope) error {
+	out.Kind = in.Kind
+	out.Namespace = in.Namespace
+	out.Name = in.Name
+	out.Rule = in.Rule
+	out.Message = in.Message
+	return nil
+}
+
+// Convert_v1beta1_GuardrailViolation_To_softwarecomposition_GuardrailViolation is an autogenerated conversion function.
+func Convert_v1beta1_GuardrailViolation_To_softwarecomposition_GuardrailViolation(in *GuardrailViolation, out *softwarecomposition.GuardrailViolation, s conversion.Scope) error {
+	return autoConvert_v1beta1_GuardrailViolation_To_softwarecomposition_GuardrailViolation(in, out, s)
+}
+
+func autoConvert_softwarecomposition_GuardrailViolation_To_v1beta1_GuardrailViolation(in *softwarecomposition.GuardrailViolation, out *GuardrailViolation, s conversion.Scope) error {
+	out.Kind = in.Kind
+	out.Namespace = in.Namespace
+	out.Name = in.Name
+	out.Rule = in.Rule
+	out.Message = in.Message
+	return nil
+}
+
+// Convert_softwarecomposition_GuardrailViolation_To_v1beta1_GuardrailViolation is an autogenerated conversion function.
+func Convert_softwarecomposition_GuardrailViolation_To_v1beta1_GuardrailViolation(in *softwarecomposition.GuardrailViolation, out *GuardrailViolation, s conversion.Scope) error {
+	return autoConvert_softwarecomposition_GuardrailViolation_To_v1beta1_GuardrailViolation(in, out, s)
+}
+
 func autoConvert_v1beta1_HTTPEndpoint_To_softwarecomposition_HTTPEndpoint(in *HTTPEndpoint, out *softwarecomposition.HTTPEndpoint, s conversion.Scope) error {
 	out.Endpoint = in.Endpoint
 	out.Methods = *(*[]string)(unsafe.Pointer(&in.Methods))
 	out.Internal = in.Internal
 	out.Direction = consts.NetworkDirection(in.Direction)
 	out.Headers = *(*json.RawMessage)(unsafe.Pointer(&in.Headers))
+	out.QueryParams = *(*[]string)(unsafe.Pointer(&in.QueryParams))
+	out.StatusClasses = *(*[]string)(unsafe.Pointer(&in.StatusClasses))
+	out.ContentTypes = *(*[]string)(unsafe.Pointer(&in.ContentTypes))
 	return nil
 }
 
@@ -2902,6 +3397,9 @@ func autoConvert_softwarecomposition_HTTPEndpoint_To_v1beta1_HTTPEndpoint(in *so
 	out.Internal = in.Internal
 	out.Direction = consts.NetworkDirection(in.Direction)
 	out.Headers = *(*json.RawMessage)(unsafe.Pointer(&in.Headers))
+	out.QueryParams = *(*[]string)(unsafe.Pointer(&in.QueryParams))
+	out.StatusClasses = *(*[]string)(unsafe.Pointer(&in.StatusClasses))
+	out.ContentTypes = *(*[]string)(unsafe.Pointer(&in.ContentTypes))
 	return nil
 }
 
@@ -4386,6 +4884,148 @@ func Convert_softwarecomposition_Product_To_v1beta1_Product(in *softwarecomposit
 	return autoConvert_softwarecomposition_Product_To_v1beta1_Product(in, out, s)
 }
 
+func autoConvert_v1beta1_ProfileEvaluation_To_softwarecomposition_ProfileEvaluation(in *ProfileEvaluation, out *softwarecomposition.ProfileEvaluation, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_v1beta1_ProfileEvaluationSpec_To_softwarecomposition_ProfileEvaluationSpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	if err := Convert_v1beta1_ProfileEvaluationStatus_To_softwarecomposition_ProfileEvaluationStatus(&in.Status, &out.Status, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_v1beta1_ProfileEvaluation_To_softwarecomposition_ProfileEvaluation is an autogenerated conversion function.
+func Convert_v1beta1_ProfileEvaluation_To_softwarecomposition_ProfileEvaluation(in *ProfileEvaluation, out *softwarecomposition.ProfileEvaluation, s conversion.Scope) error {
+	return autoConvert_v1beta1_ProfileEvaluation_To_softwarecomposition_ProfileEvaluation(in, out, s)
+}
+
+func autoConvert_softwarecomposition_ProfileEvaluation_To_v1beta1_ProfileEvaluation(in *softwarecomposition.ProfileEvaluation, out *ProfileEvaluation, s conversion.Scope) error {
+	out.ObjectMeta = in.ObjectMeta
+	if err := Convert_softwarecomposition_ProfileEvaluationSpec_To_v1beta1_ProfileEvaluationSpec(&in.Spec, &out.Spec, s); err != nil {
+		return err
+	}
+	if err := Convert_softwarecomposition_ProfileEvaluationStatus_To_v1beta1_ProfileEvaluationStatus(&in.Status, &out.Status, s); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Convert_softwarecomposition_ProfileEvaluation_To_v1beta1_ProfileEvaluation is an autogenerated conversion function.
+func Convert_softwarecomposition_ProfileEvaluation_To_v1beta1_ProfileEvaluation(in *softwarecomposition.ProfileEvaluation, out *ProfileEvaluation, s conversion.Scope) error {
+	return autoConvert_softwarecomposition_ProfileEvaluation_To_v1beta1_ProfileEvaluation(in, out, s)
+}
+
+func autoConvert_v1beta1_ProfileEvaluationSpec_To_softwarecomposition_ProfileEvaluationSpec(in *ProfileEvaluationSpec, out *softwarecomposition.ProfileEvaluationSpec, s conversion.Scope) error {
+	out.ContainerName = in.ContainerName
+	out.Observations = *(*[]softwarecomposition.ProfileObservation)(unsafe.Pointer(&in.Observations))
+	return nil
+}
+
+// Convert_v1beta1_ProfileEvaluationSpec_To_softwarecomposition_ProfileEvaluationSpec is an autogenerated conversion function.
+func Convert_v1beta1_ProfileEvaluationSpec_To_softwarecomposition_ProfileEvaluationSpec(in *ProfileEvaluationSpec, out *softwarecomposition.ProfileEvaluationSpec, s conversion.Scope) error {
+	return autoConvert_v1beta1_ProfileEvaluationSpec_To_softwarecomposition_ProfileEvaluationSpec(in, out, s)
+}
+
+func autoConvert_softwarecomposition_ProfileEvaluationSpec_To_v1beta1_ProfileEvaluationSpec(in *softwarecomposition.ProfileEvaluationSpec, out *ProfileEvaluationSpec, s conversion.Scope) error {
+	out.ContainerName = in.ContainerName
+	out.Observations = *(*[]ProfileObservation)(unsafe.Pointer(&in.Observations))
+	return nil
+}
+
+// Convert_softwarecomposition_ProfileEvaluationSpec_To_v1beta1_ProfileEvaluationSpec is an autogenerated conversion function.
+func Convert_softwarecomposition_ProfileEvaluationSpec_To_v1beta1_ProfileEvaluationSpec(in *softwarecomposition.ProfileEvaluationSpec, out *ProfileEvaluationSpec, s conversion.Scope) error {
+	return autoConvert_softwarecomposition_ProfileEvaluationSpec_To_v1beta1_ProfileEvaluationSpec(in, out, s)
+}
+
+func autoConvert_v1beta1_ProfileEvaluationStatus_To_softwarecomposition_ProfileEvaluationStatus(in *ProfileEvaluationStatus, out *softwarecomposition.ProfileEvaluationStatus, s conversion.Scope) error {
+	out.Results = *(*[]softwarecomposition.ProfileObservationResult)(unsafe.Pointer(&in.Results))
+	return nil
+}
+
+// Convert_v1beta1_ProfileEvaluationStatus_To_softwarecomposition_ProfileEvaluationStatus is an autogenerated conversion function.
+func Convert_v1beta1_ProfileEvaluationStatus_To_softwarecomposition_ProfileEvaluationStatus(in *ProfileEvaluationStatus, out *softwarecomposition.ProfileEvaluationStatus, s conversion.Scope) error {
+	return autoConvert_v1beta1_ProfileEvaluationStatus_To_softwarecomposition_ProfileEvaluationStatus(in, out, s)
+}
+
+func autoConvert_softwarecomposition_ProfileEvaluationStatus_To_v1beta1_ProfileEvaluationStatus(in *softwarecomposition.ProfileEvaluationStatus, out *ProfileEvaluationStatus, s conversion.Scope) error {
+	out.Results = *(*[]ProfileObservationResult)(unsafe.Pointer(&in.Results))
+	return nil
+}
+
+// Convert_softwarecomposition_ProfileEvaluationStatus_To_v1beta1_ProfileEvaluationStatus is an autogenerated conversion function.
+func Convert_softwarecomposition_ProfileEvaluationStatus_To_v1beta1_ProfileEvaluationStatus(in *softwarecomposition.ProfileEvaluationStatus, out *ProfileEvaluationStatus, s conversion.Scope) error {
+	return autoConvert_softwarecomposition_ProfileEvaluationStatus_To_v1beta1_ProfileEvaluationStatus(in, out, s)
+}
+
+func autoConvert_v1beta1_ProfileObservation_To_softwarecomposition_ProfileObservation(in *ProfileObservation, out *softwarecomposition.ProfileObservation, s conversion.Scope) error {
+	out.Type = softwarecomposition.ObservationType(in.Type)
+	out.Path = in.Path
+	out.Args = *(*[]string)(unsafe.Pointer(&in.Args))
+	out.Flags = *(*[]string)(unsafe.Pointer(&in.Flags))
+	out.Name = in.Name
+	out.IPAddress = in.IPAddress
+	out.DNSName = in.DNSName
+	out.Protocol = softwarecomposition.Protocol(in.Protocol)
+	out.Port = (*int32)(unsafe.Pointer(in.Port))
+	out.Endpoint = in.Endpoint
+	out.Method = in.Method
+	out.Direction = consts.NetworkDirection(in.Direction)
+	return nil
+}
+
+// Convert_v1beta1_ProfileObservation_To_softwarecomposition_ProfileObservation is an autogenerated conversion function.
+func Convert_v1beta1_ProfileObservation_To_softwarecomposition_ProfileObservation(in *ProfileObservation, out *softwarecomposition.ProfileObservation, s conversion.Scope) error {
+	return autoConvert_v1beta1_ProfileObservation_To_softwarecomposition_ProfileObservation(in, out, s)
+}
+
+func autoConvert_softwarecomposition_ProfileObservation_To_v1beta1_ProfileObservation(in *softwarecomposition.ProfileObservation, out *ProfileObservation, s conversion.Scope) error {
+	out.Type = ObservationType(in.Type)
+	out.Path = in.Path
+	out.Args = *(*[]string)(unsafe.Pointer(&in.Args))
+	out.Flags = *(*[]string)(unsafe.Pointer(&in.Flags))
+	out.Name = in.Name
+	out.IPAddress = in.IPAddress
+	out.DNSName = in.DNSName
+	out.Protocol = Protocol(in.Protocol)
+	out.Port = (*int32)(unsafe.Pointer(in.Port))
+	out.Endpoint = in.Endpoint
+	out.Method = in.Method
+	out.Direction = consts.NetworkDirection(in.Direction)
+	return nil
+}
+
+// Convert_softwarecomposition_ProfileObservation_To_v1beta1_ProfileObservation is an autogenerated conversion function.
+func Convert_softwarecomposition_ProfileObservation_To_v1beta1_ProfileObservation(in *softwarecomposition.ProfileObservation, out *ProfileObservation, s conversion.Scope) error {
+	return autoConvert_softwarecomposition_ProfileObservation_To_v1beta1_ProfileObservation(in, out, s)
+}
+
+func autoConvert_v1beta1_ProfileObservationResult_To_softwarecomposition_ProfileObservationResult(in *ProfileObservationResult, out *softwarecomposition.ProfileObservationResult, s conversion.Scope) error {
+	out.Allowed = in.Allowed
+	out.MatchedField = in.MatchedField
+	out.MatchedEntry = in.MatchedEntry
+	out.Reason = in.Reason
+	return nil
+}
+
+// Convert_v1beta1_ProfileObservationResult_To_softwarecomposition_ProfileObservationResult is an autogenerated conversion function.
+func Convert_v1beta1_ProfileObservationResult_To_softwarecomposition_ProfileObservationResult(in *ProfileObservationResult, out *softwarecomposition.ProfileObservationResult, s conversion.Scope) error {
+	return autoConvert_v1beta1_ProfileObservationResult_To_softwarecomposition_ProfileObservationResult(in, out, s)
+}
+
+func autoConvert_softwarecomposition_ProfileObservationResult_To_v1beta1_ProfileObservationResult(in *softwarecomposition.ProfileObservationResult, out *ProfileObservationResult, s conversion.Scope) error {
+	out.Allowed = in.Allowed
+	out.MatchedField = in.MatchedField
+	out.MatchedEntry = in.MatchedEntry
+	out.Reason = in.Reason
+	return nil
+}
+
+// Convert_softwarecomposition_ProfileObservationResult_To_v1beta1_ProfileObservationResult is an autogenerated conversion function.
+func Convert_softwarecomposition_ProfileObservationResult_To_v1beta1_ProfileObservationResult(in *softwarecomposition.ProfileObservationResult, out *ProfileObservationResult, s conversion.Scope) error {
+	return autoConvert_softwarecomposition_ProfileObservationResult_To_v1beta1_ProfileObservationResult(in, out, s)
+}
+
 func autoConvert_v1beta1_ReportMeta_To_softwarecomposition_ReportMeta(in *ReportMeta, out *softwarecomposition.ReportMeta, s conversion.Scope) error {
 	out.CreatedAt = in.CreatedAt
 	return nil
@@ -319,6 +319,100 @@ func (in *CallStackNode) DeepCopy() *CallStackNode {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CallStackQuery) DeepCopyInto(out *CallStackQuery) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CallStackQuery.
+func (in *CallStackQuery) DeepCopy() *CallStackQuery {
+	if in == nil {
+		return nil
+	}
+	out := new(CallStackQuery)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CallStackQuery) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CallStackQuerySpec) DeepCopyInto(out *CallStackQuerySpec) {
+	*out = *in
+	if in.Frame != nil {
+		in, out := &in.Frame, &out.Frame
+		*out = new(StackFrame)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CallStackQuerySpec.
+func (in *CallStackQuerySpec) DeepCopy() *CallStackQuerySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CallStackQuerySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CallStackQueryStatus) DeepCopyInto(out *CallStackQueryStatus) {
+	*out = *in
+	if in.CallStacks != nil {
+		in, out := &in.CallStacks, &out.CallStacks
+		*out = make([]IdentifiedCallStack, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.References != nil {
+		in, out := &in.References, &out.References
+		*out = make([]CallStackReference, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CallStackQueryStatus.
+func (in *CallStackQueryStatus) DeepCopy() *CallStackQueryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CallStackQueryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CallStackReference) DeepCopyInto(out *CallStackReference) {
+	*out = *in
+	out.Frame = in.Frame
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CallStackReference.
+func (in *CallStackReference) DeepCopy() *CallStackReference {
+	if in == nil {
+		return nil
+	}
+	out := new(CallStackReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CollapseConfigEntry) DeepCopyInto(out *CollapseConfigEntry) {
 	*out = *in
@@ -403,6 +497,21 @@ func (in *CollapseConfigurationSpec) DeepCopyInto(out *CollapseConfigurationSpec
 		*out = make([]CollapseConfigEntry, len(*in))
 		copy(*out, *in)
 	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WorkloadSelector != nil {
+		in, out := &in.WorkloadSelector, &out.WorkloadSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HeaderAllowlist != nil {
+		in, out := &in.HeaderAllowlist, &out.HeaderAllowlist
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -416,6 +525,54 @@ func (in *CollapseConfigurationSpec) DeepCopy() *CollapseConfigurationSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CompiledContainerProfile) DeepCopyInto(out *CompiledContainerProfile) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompiledContainerProfile.
+func (in *CompiledContainerProfile) DeepCopy() *CompiledContainerProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(CompiledContainerProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CompiledContainerProfile) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CompiledContainerProfileSpec) DeepCopyInto(out *CompiledContainerProfileSpec) {
+	*out = *in
+	if in.Data != nil {
+		in, out := &in.Data, &out.Data
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompiledContainerProfileSpec.
+func (in *CompiledContainerProfileSpec) DeepCopy() *CompiledContainerProfileSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CompiledContainerProfileSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Component) DeepCopyInto(out *Component) {
 	*out = *in
@@ -1175,6 +1332,146 @@ func (in *GrypePackage) DeepCopy() *GrypePackage {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Guardrail) DeepCopyInto(out *Guardrail) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Guardrail.
+func (in *Guardrail) DeepCopy() *Guardrail {
+	if in == nil {
+		return nil
+	}
+	out := new(Guardrail)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Guardrail) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GuardrailList) DeepCopyInto(out *GuardrailList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Guardrail, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GuardrailList.
+func (in *GuardrailList) DeepCopy() *GuardrailList {
+	if in == nil {
+		return nil
+	}
+	out := new(GuardrailList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GuardrailList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GuardrailRule) DeepCopyInto(out *GuardrailRule) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GuardrailRule.
+func (in *GuardrailRule) DeepCopy() *GuardrailRule {
+	if in == nil {
+		return nil
+	}
+	out := new(GuardrailRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GuardrailSpec) DeepCopyInto(out *GuardrailSpec) {
+	*out = *in
+	if in.MatchKinds != nil {
+		in, out := &in.MatchKinds, &out.MatchKinds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]GuardrailRule, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GuardrailSpec.
+func (in *GuardrailSpec) DeepCopy() *GuardrailSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GuardrailSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GuardrailStatus) DeepCopyInto(out *GuardrailStatus) {
+	*out = *in
+	if in.Violations != nil {
+		in, out := &in.Violations, &out.Violations
+		*out = make([]GuardrailViolation, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GuardrailStatus.
+func (in *GuardrailStatus) DeepCopy() *GuardrailStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GuardrailStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GuardrailViolation) DeepCopyInto(out *GuardrailViolation) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GuardrailViolation.
+func (in *GuardrailViolation) DeepCopy() *GuardrailViolation {
+	if in == nil {
+		return nil
+	}
+	out := new(GuardrailViolation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HTTPEndpoint) DeepCopyInto(out *HTTPEndpoint) {
 	*out = *in
@@ -1188,6 +1485,21 @@ func (in *HTTPEndpoint) DeepCopyInto(out *HTTPEndpoint) {
 		*out = make(json.RawMessage, len(*in))
 		copy(*out, *in)
 	}
+	if in.QueryParams != nil {
+		in, out := &in.QueryParams, &out.QueryParams
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.StatusClasses != nil {
+		in, out := &in.StatusClasses, &out.StatusClasses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ContentTypes != nil {
+		in, out := &in.ContentTypes, &out.ContentTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -2663,6 +2975,125 @@ func (in *Product) DeepCopy() *Product {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProfileEvaluation) DeepCopyInto(out *ProfileEvaluation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfileEvaluation.
+func (in *ProfileEvaluation) DeepCopy() *ProfileEvaluation {
+	if in == nil {
+		return nil
+	}
+	out := new(ProfileEvaluation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ProfileEvaluation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProfileEvaluationSpec) DeepCopyInto(out *ProfileEvaluationSpec) {
+	*out = *in
+	if in.Observations != nil {
+		in, out := &in.Observations, &out.Observations
+		*out = make([]ProfileObservation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfileEvaluationSpec.
+func (in *ProfileEvaluationSpec) DeepCopy() *ProfileEvaluationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProfileEvaluationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProfileEvaluationStatus) DeepCopyInto(out *ProfileEvaluationStatus) {
+	*out = *in
+	if in.Results != nil {
+		in, out := &in.Results, &out.Results
+		*out = make([]ProfileObservationResult, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfileEvaluationStatus.
+func (in *ProfileEvaluationStatus) DeepCopy() *ProfileEvaluationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ProfileEvaluationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProfileObservation) DeepCopyInto(out *ProfileObservation) {
+	*out = *in
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Flags != nil {
+		in, out := &in.Flags, &out.Flags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfileObservation.
+func (in *ProfileObservation) DeepCopy() *ProfileObservation {
+	if in == nil {
+		return nil
+	}
+	out := new(ProfileObservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProfileObservationResult) DeepCopyInto(out *ProfileObservationResult) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProfileObservationResult.
+func (in *ProfileObservationResult) DeepCopy() *ProfileObservationResult {
+	if in == nil {
+		return nil
+	}
+	out := new(ProfileObservationResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReportMeta) DeepCopyInto(out *ReportMeta) {
 	*out = *in
@@ -71,6 +71,26 @@ func (in CallStackNode) OpenAPIModelName() string {
 	return "com.github.kubescape.storage.pkg.apis.softwarecomposition.v1beta1.CallStackNode"
 }
 
+// OpenAPIModelName returns the OpenAPI model name for this type.
+func (in CallStackQuery) OpenAPIModelName() string {
+	return "com.github.kubescape.storage.pkg.apis.softwarecomposition.v1beta1.CallStackQuery"
+}
+
+// OpenAPIModelName returns the OpenAPI model name for this type.
+func (in CallStackQuerySpec) OpenAPIModelName() string {
+	return "com.github.kubescape.storage.pkg.apis.softwarecomposition.v1beta1.CallStackQuerySpec"
+}
+
+// OpenAPIModelName returns the OpenAPI model name for this type.
+func (in CallStackQueryStatus) OpenAPIModelName() string {
+	return "com.github.kubescape.storage.pkg.apis.softwarecomposition.v1beta1.CallStackQueryStatus"
+}
+
+// OpenAPIModelName returns the OpenAPI model name for this type.
+func (in CallStackReference) OpenAPIModelName() string {
+	return "com.github.kubescape.storage.pkg.apis.softwarecomposition.v1beta1.CallStackReference"
+}
+
 // OpenAPIModelName returns the OpenAPI model name for this type.
 func (in CollapseConfigEntry) OpenAPIModelName() string {
 	return "com.github.kubescape.storage.pkg.apis.softwarecomposition.v1beta1.CollapseConfigEntry"
@@ -91,6 +111,16 @@ func (in CollapseConfigurationSpec) OpenAPIModelName() string {
 	return "com.github.kubescape.storage.pkg.apis.softwarecomposition.v1beta1.CollapseConfigurationSpec"
 }
 
+// OpenAPIModelName returns the OpenAPI model name for this type.
+func (in CompiledContainerProfile) OpenAPIModelName() string {
+	return "com.github.kubescape.storage.pkg.apis.softwarecomposition.v1beta1.CompiledContainerProfile"
+}
+
+// OpenAPIModelName returns the OpenAPI model name for this type.
+func (in CompiledContainerProfileSpec) OpenAPIModelName() string {
+	return "com.github.kubescape.storage.pkg.apis.softwarecomposition.v1beta1.CompiledContainerProfileSpec"
+}
+
 // OpenAPIModelName returns the OpenAPI model name for this type.
 func (in Component) OpenAPIModelName() string {
 	return "com.github.kubescape.storage.pkg.apis.softwarecomposition.v1beta1.Component"
@@ -231,6 +261,36 @@ func (in GrypePackage) OpenAPIModelName() string {
 	return "com.github.kubescape.storage.pkg.apis.softwarecomposition.v1beta1.GrypePackage"
 }
 
+// OpenAPIModelName returns the OpenAPI model name for this type.
+func (in Guardrail) OpenAPIModelName() string {
+	return "com.github.kubescape.storage.pkg.apis.softwarecomposition.v1beta1.Guardrail"
+}
+
+// OpenAPIModelName returns the OpenAPI model name for this type.
+func (in GuardrailList) OpenAPIModelName() string {
+	return "com.github.kubescape.storage.pkg.apis.softwarecomposition.v1beta1.GuardrailList"
+}
+
+// OpenAPIModelName returns the OpenAPI model name for this type.
+func (in GuardrailRule) OpenAPIModelName() string {
+	return "com.github.kubescape.storage.pkg.apis.softwarecomposition.v1beta1.GuardrailRule"
+}
+
+// OpenAPIModelName returns the OpenAPI model name for this type.
+func (in GuardrailSpec) OpenAPIModelName() string {
+	return "com.github.kubescape.storage.pkg.apis.softwarecomposition.v1beta1.GuardrailSpec"
+}
+
+// OpenAPIModelName returns the OpenAPI model name for this type.
+func (in GuardrailStatus) OpenAPIModelName() string {
+	return "com.github.kubescape.storage.pkg.apis.softwarecomposition.v1beta1.GuardrailStatus"
+}
+
+// OpenAPIModelName returns the OpenAPI model name for this type.
+func (in GuardrailViolation) OpenAPIModelName() string {
+	return "com.github.kubescape.storage.pkg.apis.softwarecomposition.v1beta1.GuardrailViolation"
+}
+
 // OpenAPIModelName returns the OpenAPI model name for this type.
 func (in HTTPEndpoint) OpenAPIModelName() string {
 	return "com.github.kubescape.storage.pkg.apis.softwarecomposition.v1beta1.HTTPEndpoint"
@@ -516,6 +576,31 @@ func (in Product) OpenAPIModelName() string {
 	return "com.github.kubescape.storage.pkg.apis.softwarecomposition.v1beta1.Product"
 }
 
+// OpenAPIModelName returns the OpenAPI model name for this type.
+func (in ProfileEvaluation) OpenAPIModelName() string {
+	return "com.github.kubescape.storage.pkg.apis.softwarecomposition.v1beta1.ProfileEvaluation"
+}
+
+// OpenAPIModelName returns the OpenAPI model name for this type.
+func (in ProfileEvaluationSpec) OpenAPIModelName() string {
+	return "com.github.kubescape.storage.pkg.apis.softwarecomposition.v1beta1.ProfileEvaluationSpec"
+}
+
+// OpenAPIModelName returns the OpenAPI model name for this type.
+func (in ProfileEvaluationStatus) OpenAPIModelName() string {
+	return "com.github.kubescape.storage.pkg.apis.softwarecomposition.v1beta1.ProfileEvaluationStatus"
+}
+
+// OpenAPIModelName returns the OpenAPI model name for this type.
+func (in ProfileObservation) OpenAPIModelName() string {
+	return "com.github.kubescape.storage.pkg.apis.softwarecomposition.v1beta1.ProfileObservation"
+}
+
+// OpenAPIModelName returns the OpenAPI model name for this type.
+func (in ProfileObservationResult) OpenAPIModelName() string {
+	return "com.github.kubescape.storage.pkg.apis.softwarecomposition.v1beta1.ProfileObservationResult"
+}
+
 // OpenAPIModelName returns the OpenAPI model name for this type.
 func (in ReportMeta) OpenAPIModelName() string {
 	return "com.github.kubescape.storage.pkg.apis.softwarecomposition.v1beta1.ReportMeta"
@@ -17,20 +17,32 @@ limitations under the License.
 package apiserver
 
 import (
+	"context"
+	"net/http"
+	"path/filepath"
+
+	"github.com/kubescape/go-logger"
+	"github.com/kubescape/go-logger/helpers"
 	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
 	"github.com/kubescape/storage/pkg/apis/softwarecomposition/install"
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition/networkpolicy/v2"
 	"github.com/kubescape/storage/pkg/config"
 	"github.com/kubescape/storage/pkg/registry"
 	sbomregistry "github.com/kubescape/storage/pkg/registry"
 	"github.com/kubescape/storage/pkg/registry/file"
 	"github.com/kubescape/storage/pkg/registry/softwarecomposition/applicationprofile"
+	"github.com/kubescape/storage/pkg/registry/softwarecomposition/callstackquery"
 	"github.com/kubescape/storage/pkg/registry/softwarecomposition/collapseconfiguration"
+	"github.com/kubescape/storage/pkg/registry/softwarecomposition/compiledcontainerprofile"
 	"github.com/kubescape/storage/pkg/registry/softwarecomposition/configurationscansummary"
 	"github.com/kubescape/storage/pkg/registry/softwarecomposition/containerprofile"
 	"github.com/kubescape/storage/pkg/registry/softwarecomposition/generatednetworkpolicy"
+	"github.com/kubescape/storage/pkg/registry/softwarecomposition/guardrail"
 	knownserver "github.com/kubescape/storage/pkg/registry/softwarecomposition/knownservers"
 	"github.com/kubescape/storage/pkg/registry/softwarecomposition/networkneighborhood"
 	"github.com/kubescape/storage/pkg/registry/softwarecomposition/openvulnerabilityexchange"
+	"github.com/kubescape/storage/pkg/registry/softwarecomposition/pendingprofile"
+	"github.com/kubescape/storage/pkg/registry/softwarecomposition/profileevaluation"
 	"github.com/kubescape/storage/pkg/registry/softwarecomposition/sbomsyftfiltereds"
 	"github.com/kubescape/storage/pkg/registry/softwarecomposition/sbomsyfts"
 	"github.com/kubescape/storage/pkg/registry/softwarecomposition/seccompprofiles"
@@ -47,6 +59,7 @@ import (
 	"k8s.io/apiserver/pkg/registry/generic"
 	"k8s.io/apiserver/pkg/registry/rest"
 	genericapiserver "k8s.io/apiserver/pkg/server"
+	"k8s.io/apiserver/pkg/server/healthz"
 	"k8s.io/apiserver/pkg/server/options"
 	"k8s.io/apiserver/pkg/storage"
 	"zombiezen.com/go/sqlite/sqlitemigration"
@@ -81,6 +94,16 @@ func init() {
 	)
 }
 
+// QuotasPath serves the usage of the namespace quotas, see file.Quotas.
+const QuotasPath = "/quotas"
+
+// QuarantinePath serves the payloads quarantined, see file.Scrubber.
+const QuarantinePath = "/quarantine"
+
+// MigrationsPath serves the progress of the schema migration sweep, see
+// file.MigrationSweeper.
+const MigrationsPath = "/migrations"
+
 // ExtraConfig holds custom apiserver config
 type ExtraConfig struct {
 	CleanupHandler  *file.ResourcesCleanupHandler
@@ -88,6 +111,14 @@ type ExtraConfig struct {
 	Pool            *sqlitemigration.Pool
 	StorageConfig   config.Config
 	WatchDispatcher *file.WatchDispatcher
+	NamespaceLabels file.NamespaceLabelsFunc
+	ClusterLister   networkpolicy.ClusterLister
+	// ConfigWatcher publishes the reloads of StorageConfig, nil when it is
+	// not reloaded.
+	ConfigWatcher *config.Watcher
+	// QuarantineEvent reports the objects quarantined by the integrity
+	// scrubber, nil only logs them.
+	QuarantineEvent file.QuarantineEventFunc
 }
 
 // Config defines the config for the apiserver
@@ -146,17 +177,25 @@ func (c completedConfig) New() (*WardleServer, error) {
 	// read the CR, processors are baked into the storage backend.
 	applicationProfileProcessor := file.NewApplicationProfileProcessor(c.ExtraConfig.StorageConfig)
 	containerProfileProcessor := file.NewContainerProfileProcessor(c.ExtraConfig.StorageConfig, c.ExtraConfig.CleanupHandler)
+	networkNeighborhoodProcessor := file.NewNetworkNeighborhoodProcessor(c.ExtraConfig.StorageConfig)
+	if c.ExtraConfig.ConfigWatcher != nil {
+		c.ExtraConfig.ConfigWatcher.Subscribe(applicationProfileProcessor.Reload)
+		c.ExtraConfig.ConfigWatcher.Subscribe(containerProfileProcessor.Reload)
+		c.ExtraConfig.ConfigWatcher.Subscribe(networkNeighborhoodProcessor.Reload)
+	}
 
 	var (
 		storageImpl = file.NewStorageImpl(c.ExtraConfig.OsFs, file.DefaultStorageRoot, c.ExtraConfig.Pool, c.ExtraConfig.WatchDispatcher, Scheme)
 
-		applicationProfileStorageBackend = file.NewStorageImplWithCollector(c.ExtraConfig.OsFs, file.DefaultStorageRoot, c.ExtraConfig.Pool, c.ExtraConfig.WatchDispatcher, Scheme, applicationProfileProcessor)
-		applicationProfileStorageImpl    = file.NewApplicationProfileStorage(applicationProfileStorageBackend)
-		containerProfileStorageImpl      = file.NewContainerProfileRESTStorage(file.NewStorageImplWithCollector(c.ExtraConfig.OsFs, file.DefaultStorageRoot, c.ExtraConfig.Pool, c.ExtraConfig.WatchDispatcher, Scheme, containerProfileProcessor))
-		networkNeighborhoodStorageImpl   = file.NewNetworkNeighborhoodStorage(file.NewStorageImplWithCollector(c.ExtraConfig.OsFs, file.DefaultStorageRoot, c.ExtraConfig.Pool, c.ExtraConfig.WatchDispatcher, Scheme, file.NewNetworkNeighborhoodProcessor(c.ExtraConfig.StorageConfig)))
-		configScanStorageImpl            = file.NewConfigurationScanSummaryStorage(storageImpl)
-		vulnerabilitySummaryStorage      = file.NewVulnerabilitySummaryStorage(storageImpl)
-		generatedNetworkPolicyStorage    = file.NewGeneratedNetworkPolicyStorage(storageImpl, networkNeighborhoodStorageImpl)
+		applicationProfileStorageBackend  = file.NewStorageImplWithCollector(c.ExtraConfig.OsFs, file.DefaultStorageRoot, c.ExtraConfig.Pool, c.ExtraConfig.WatchDispatcher, Scheme, applicationProfileProcessor)
+		applicationProfileStorageImpl     = file.NewApplicationProfileStorage(applicationProfileStorageBackend)
+		containerProfileStorageBackend    = file.NewStorageImplWithCollector(c.ExtraConfig.OsFs, file.DefaultStorageRoot, c.ExtraConfig.Pool, c.ExtraConfig.WatchDispatcher, Scheme, containerProfileProcessor)
+		containerProfileStorageImpl       = file.NewContainerProfileRESTStorage(containerProfileStorageBackend)
+		networkNeighborhoodStorageBackend = file.NewStorageImplWithCollector(c.ExtraConfig.OsFs, file.DefaultStorageRoot, c.ExtraConfig.Pool, c.ExtraConfig.WatchDispatcher, Scheme, networkNeighborhoodProcessor)
+		networkNeighborhoodStorageImpl    = file.NewNetworkNeighborhoodStorage(networkNeighborhoodStorageBackend)
+		configScanStorageImpl             = file.NewConfigurationScanSummaryStorage(storageImpl)
+		vulnerabilitySummaryStorage       = file.NewVulnerabilitySummaryStorage(storageImpl)
+		generatedNetworkPolicyStorage     = file.NewGeneratedNetworkPolicyStorage(storageImpl, networkNeighborhoodStorageImpl, c.ExtraConfig.ClusterLister)
 
 		// REST endpoint registration, defaults to storageImpl.
 		ep = func(f func(*runtime.Scheme, storage.Interface, generic.RESTOptionsGetter) (*registry.REST, error), s ...storage.Interface) *registry.REST {
@@ -181,14 +220,105 @@ func (c completedConfig) New() (*WardleServer, error) {
 	collapseSettingsFromCRD := file.NewCRDCollapseSettingsProvider(applicationProfileStorageBackend)
 	applicationProfileProcessor.SetCollapseSettings(collapseSettingsFromCRD)
 	containerProfileProcessor.CollapseSettings = collapseSettingsFromCRD
+	networkNeighborhoodProcessor.SetCollapseSettings(collapseSettingsFromCRD)
+	// Scoped configurations take precedence: each profile is collapsed with
+	// the most specific CollapseConfiguration selecting its namespace and
+	// labels, recorded in an annotation, and with "default" otherwise.
+	collapseSettingsResolver := file.NewCRDCollapseSettingsResolver(applicationProfileStorageBackend, c.ExtraConfig.NamespaceLabels)
+	applicationProfileProcessor.SetCollapseSettingsResolver(collapseSettingsResolver)
+	containerProfileProcessor.CollapseSettingsResolver = collapseSettingsResolver
+	networkNeighborhoodProcessor.SetCollapseSettingsResolver(collapseSettingsResolver)
+
+	// Server-side apply needs the managed fields of the objects it merges into,
+	// they are only kept for the resources listed in the configuration.
+	// The creates of every backend are checked against the quotas of their
+	// namespace, whose usage is served on QuotasPath.
+	// The writes of every backend are rejected under disk pressure, the
+	// volumes of the payloads and of the metadata being watched by diskMonitor.
+	// The payloads are written with the configured codec, gob by default so
+	// that the previous releases can read them.
+	// The objects stored at an older schema version, and the legacy payloads
+	// the current types fail to decode, are migrated when read; the cleanup
+	// keeps the versions they can be migrated from.
+	quotas := file.NewQuotas(&c.ExtraConfig.StorageConfig)
+	schemaMigrations := file.DefaultSchemaMigrations()
+	containerProfileProcessor.SchemaMigrations = schemaMigrations
+	var payloadCodec file.PayloadCodec
+	if name := c.ExtraConfig.StorageConfig.PayloadCodec; name != "" {
+		if payloadCodec, err = file.NewPayloadCodec(name, Scheme); err != nil {
+			return nil, err
+		}
+	}
+	var emergencyCleanup func(ctx context.Context) error
+	if c.ExtraConfig.CleanupHandler != nil {
+		emergencyCleanup = c.ExtraConfig.CleanupHandler.EmergencyCleanup
+	}
+	diskMonitor := file.NewDiskMonitor(&c.ExtraConfig.StorageConfig, emergencyCleanup, file.DefaultStorageRoot, filepath.Dir(file.DefaultMetadataPath))
+	if c.ExtraConfig.ConfigWatcher != nil {
+		c.ExtraConfig.ConfigWatcher.Subscribe(quotas.Reload)
+		c.ExtraConfig.ConfigWatcher.Subscribe(diskMonitor.Reload)
+	}
+	for _, backend := range []file.StorageQuerier{storageImpl, applicationProfileStorageBackend, containerProfileStorageBackend, networkNeighborhoodStorageBackend} {
+		backend.TrackManagedFields(c.ExtraConfig.StorageConfig.ManagedFieldsResources...)
+		backend.SetQuotas(quotas)
+		backend.SetDiskMonitor(diskMonitor)
+		if payloadCodec != nil {
+			backend.SetPayloadCodec(payloadCodec)
+		}
+		backend.SetSchemaMigrations(schemaMigrations, c.ExtraConfig.StorageConfig.MigrationDryRun)
+	}
+	s.GenericAPIServer.Handler.NonGoRestfulMux.Handle(QuotasPath, quotas.Handler(c.ExtraConfig.Pool))
+	if err := s.GenericAPIServer.AddReadyzChecks(healthz.NamedCheck("disk-pressure", func(_ *http.Request) error {
+		return diskMonitor.ReadinessCheck()
+	})); err != nil {
+		return nil, err
+	}
+	s.GenericAPIServer.AddPostStartHookOrDie("disk-pressure-monitor", func(ctx genericapiserver.PostStartHookContext) error {
+		go diskMonitor.Run(ctx)
+		return nil
+	})
+
+	// Every profile write is evaluated against the Guardrail rules, and every
+	// Guardrail change re-evaluates the stored profiles through the storage
+	// owning them.
+	guardrailsCache := file.NewGuardrailsCache(storageImpl)
+	s.GenericAPIServer.AddPostStartHookOrDie("guardrails-cache", func(ctx genericapiserver.PostStartHookContext) error {
+		go guardrailsCache.Run(ctx)
+		return nil
+	})
+	applicationProfileProcessor.SetGuardrails(guardrailsCache.Get)
+	containerProfileProcessor.Guardrails = guardrailsCache.Get
+	networkNeighborhoodProcessor.SetGuardrails(guardrailsCache.Get)
+	guardrailStorage := file.NewGuardrailStorage(storageImpl, guardrailsCache, map[string]file.StorageQuerier{
+		"applicationprofiles":           applicationProfileStorageBackend,
+		file.ContainerProfileKindPlural: containerProfileStorageBackend,
+		"networkneighborhoods":          networkNeighborhoodStorageBackend,
+	})
+	// The profiles also serve an evaluate subresource, which answers whether
+	// they cover runtime observations, and ContainerProfiles a compiled one.
+	// ApplicationProfiles and ContainerProfiles serve a callstacks subresource
+	// reading their call stacks and the frames indexed across the namespace,
+	// and a pending one reading the revision waiting for approval.
+	applicationProfileREST := ep(applicationprofile.NewREST, applicationProfileStorageImpl)
+	containerProfileREST := ep(containerprofile.NewREST, containerProfileStorageImpl)
+	networkNeighborhoodREST := ep(networkneighborhood.NewREST, networkNeighborhoodStorageImpl)
 	apiGroupInfo.VersionedResourcesStorageMap["v1beta1"] = map[string]rest.Storage{
-		"applicationprofiles":                 ep(applicationprofile.NewREST, applicationProfileStorageImpl),
+		"applicationprofiles":                 applicationProfileREST,
+		"applicationprofiles/callstacks":      callstackquery.NewREST(applicationProfileREST, storageImpl.ListCallStackReferences),
+		"applicationprofiles/evaluate":        profileevaluation.NewREST(applicationProfileREST),
+		"applicationprofiles/pending":         pendingprofile.NewREST(applicationProfileREST, file.WithPendingRevision),
 		"collapseconfigurations":              ep(collapseconfiguration.NewREST),
 		"configurationscansummaries":          ep(configurationscansummary.NewREST, configScanStorageImpl),
-		"containerprofiles":                   ep(containerprofile.NewREST, containerProfileStorageImpl),
+		"containerprofiles":                   containerProfileREST,
+		"containerprofiles/callstacks":        callstackquery.NewREST(containerProfileREST, storageImpl.ListCallStackReferences),
+		"containerprofiles/compiled":          compiledcontainerprofile.NewREST(containerProfileREST, file.NewCompiledContainerProfileGetter(containerProfileStorageBackend, containerProfileREST.KeyFunc)),
+		"containerprofiles/evaluate":          profileevaluation.NewREST(containerProfileREST),
+		"containerprofiles/pending":           pendingprofile.NewREST(containerProfileREST, file.WithPendingRevision),
 		"generatednetworkpolicies":            ep(generatednetworkpolicy.NewREST, generatedNetworkPolicyStorage),
+		"guardrails":                          ep(guardrail.NewREST, guardrailStorage),
 		"knownservers":                        ep(knownserver.NewREST),
-		"networkneighborhoods":                ep(networkneighborhood.NewREST, networkNeighborhoodStorageImpl),
+		"networkneighborhoods":                networkNeighborhoodREST,
+		"networkneighborhoods/evaluate":       profileevaluation.NewREST(networkNeighborhoodREST),
 		"openvulnerabilityexchangecontainers": ep(openvulnerabilityexchange.NewREST),
 		"sbomsyftfiltereds":                   ep(sbomsyftfiltereds.NewREST),
 		"sbomsyfts":                           ep(sbomsyfts.NewREST),
@@ -212,5 +342,69 @@ func (c completedConfig) New() (*WardleServer, error) {
 		return nil, err
 	}
 
+	// The resources having their own backend are scrubbed, swept and
+	// reconciled by it, as it locks their objects.
+	backends := map[string]file.StorageQuerier{
+		"applicationprofiles":  applicationProfileStorageBackend,
+		"containerprofiles":    containerProfileStorageBackend,
+		"networkneighborhoods": networkNeighborhoodStorageBackend,
+	}
+
+	// The payloads are scrubbed in the background, the quarantined ones are
+	// served on QuarantinePath for their producers to send them again.
+	scrubber := file.NewScrubber(storageImpl, backends, &c.ExtraConfig.StorageConfig, c.ExtraConfig.QuarantineEvent)
+	if c.ExtraConfig.ConfigWatcher != nil {
+		c.ExtraConfig.ConfigWatcher.Subscribe(scrubber.Reload)
+	}
+	s.GenericAPIServer.Handler.NonGoRestfulMux.Handle(QuarantinePath, scrubber.Handler())
+	s.GenericAPIServer.AddPostStartHookOrDie("integrity-scrubber", func(ctx genericapiserver.PostStartHookContext) error {
+		go scrubber.Run(ctx)
+		return nil
+	})
+
+	// The legacy payloads, those in an outdated format and the objects at an
+	// older schema version are rewritten by a sweep at startup, its progress being served on MigrationsPath.
+	migrationSweeper := file.NewMigrationSweeper(storageImpl, backends, &c.ExtraConfig.StorageConfig)
+	s.GenericAPIServer.Handler.NonGoRestfulMux.Handle(MigrationsPath, migrationSweeper.Handler())
+	s.GenericAPIServer.AddPostStartHookOrDie("schema-migration-sweeper", func(ctx genericapiserver.PostStartHookContext) error {
+		go migrationSweeper.Run(ctx)
+		return nil
+	})
+
+	// A crash may leave a payload and its metadata disagreeing, reconcile them
+	// once at startup. Objects are checked under their lock, this runs in the
+	// background too.
+	s.GenericAPIServer.AddPostStartHookOrDie("reconcile-storage", func(ctx genericapiserver.PostStartHookContext) error {
+		go func() {
+			report, err := file.ReconcileBackends(ctx, storageImpl, backends)
+			if err != nil {
+				logger.L().Error("reconcile storage failed", helpers.Error(err))
+				return
+			}
+			logger.L().Info("reconcile storage done",
+				helpers.Int("temporaryRemoved", report.TemporaryRemoved),
+				helpers.Int("metadataRestored", report.MetadataRestored),
+				helpers.Int("metadataDeleted", report.MetadataDeleted),
+				helpers.Int("quarantined", report.Quarantined))
+		}()
+		return nil
+	})
+
+	// The namespace summaries are maintained incrementally on every write, rebuild them
+	// once at startup to backfill existing objects and repair any drift left by a crash.
+	// This runs in the background so it does not hold back readiness.
+	if !c.ExtraConfig.StorageConfig.DisableVirtualCRDs {
+		s.GenericAPIServer.AddPostStartHookOrDie("rebuild-summaries", func(ctx genericapiserver.PostStartHookContext) error {
+			go func() {
+				if err := storageImpl.RebuildSummaries(ctx); err != nil {
+					logger.L().Error("rebuild summaries failed", helpers.Error(err))
+					return
+				}
+				logger.L().Info("rebuild summaries done")
+			}()
+			return nil
+		})
+	}
+
 	return s, nil
 }
@@ -28,6 +28,7 @@ import (
 	"github.com/didip/tollbooth/v7"
 	"github.com/kubescape/go-logger"
 	"github.com/kubescape/go-logger/helpers"
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition/networkpolicy/v2"
 	"github.com/kubescape/storage/pkg/apis/softwarecomposition/v1beta1"
 	"github.com/kubescape/storage/pkg/apiserver"
 	"github.com/kubescape/storage/pkg/config"
@@ -77,6 +78,21 @@ type WardleServerOptions struct {
 	Pool            *sqlitemigration.Pool
 	StorageConfig   config.Config
 	WatchDispatcher *file.WatchDispatcher
+	// NamespaceLabels looks up the labels CollapseConfiguration namespace
+	// selectors are matched against, nil matches on the namespace name only.
+	NamespaceLabels file.NamespaceLabelsFunc
+	// ClusterLister lists the Services, EndpointSlices and Nodes learned
+	// ingress IPs are resolved against, nil leaves them as IP blocks.
+	ClusterLister networkpolicy.ClusterLister
+	// ConfigWatcher publishes the reloads of StorageConfig to the components
+	// following them, nil when it is not reloaded.
+	ConfigWatcher *config.Watcher
+	// QuarantineEvent reports the objects quarantined by the integrity
+	// scrubber, nil only logs them.
+	QuarantineEvent file.QuarantineEventFunc
+
+	queueManager   *queuemanager.QueueManager
+	watchAdmission *queuemanager.WatchAdmission
 }
 
 func WardleVersionToKubeVersion(ver *version.Version) *version.Version {
@@ -272,7 +288,23 @@ func (o *WardleServerOptions) Config() (*apiserver.Config, error) {
 	serverConfig.FeatureGate = o.ComponentGlobalsRegistry.FeatureGateFor(basecompatibility.DefaultKubeComponent)
 	serverConfig.EffectiveVersion = o.ComponentGlobalsRegistry.EffectiveVersionFor(apiserver.WardleComponentName)
 
+	if o.StorageConfig.QueueManagerEnabled {
+		o.queueManager = queuemanager.NewQueueManager(&o.StorageConfig, file.NewPayloadSizer(o.Pool))
+	}
+	o.watchAdmission = queuemanager.NewWatchAdmission(&o.StorageConfig)
+	if o.ConfigWatcher != nil {
+		if o.queueManager != nil {
+			o.ConfigWatcher.Subscribe(o.queueManager.Reload)
+		}
+		o.ConfigWatcher.Subscribe(o.watchAdmission.Reload)
+	}
 	serverConfig.BuildHandlerChainFunc = func(apiHandler http.Handler, c *genericapiserver.Config) http.Handler {
+		// the queue manager and the watch admission key clients on their
+		// authenticated user, so they run inside the default handler chain
+		if o.queueManager != nil {
+			apiHandler = o.queueManager.QueueHandler(apiHandler) // Attach queue manager
+		}
+		apiHandler = o.watchAdmission.Handler(apiHandler)                   // Attach watch admission
 		handler := genericapiserver.DefaultBuildHandlerChain(apiHandler, c) // Default handler chain
 		if o.StorageConfig.QueueProcessingStatsPrint {
 			handler = stats.Handler(handler) // Attach stats collector
@@ -280,10 +312,6 @@ func (o *WardleServerOptions) Config() (*apiserver.Config, error) {
 		if o.StorageConfig.QueueTimeoutPrint {
 			handler = queuemanager.TimeoutLoggerMiddleware(handler, o.StorageConfig.QueueTimeout) // Attach timeout logger
 		}
-		if o.StorageConfig.QueueManagerEnabled {
-			queueManager := queuemanager.NewQueueManager(&o.StorageConfig) // Attach queue manager
-			handler = queueManager.QueueHandler(handler)                   // Attach queue manager
-		}
 		return handler
 	}
 
@@ -299,6 +327,10 @@ func (o *WardleServerOptions) Config() (*apiserver.Config, error) {
 			Pool:            o.Pool,
 			StorageConfig:   o.StorageConfig,
 			WatchDispatcher: o.WatchDispatcher,
+			NamespaceLabels: o.NamespaceLabels,
+			ClusterLister:   o.ClusterLister,
+			ConfigWatcher:   o.ConfigWatcher,
+			QuarantineEvent: o.QuarantineEvent,
 		},
 	}
 	return c, nil
@@ -341,6 +373,17 @@ func (o WardleServerOptions) RunWardleServer(ctx context.Context) error {
 							helpers.String("avg", time.Duration(stats.Sum.Nanoseconds()/stats.Count).String()))
 					}
 				}
+				if o.queueManager != nil {
+					budget := o.queueManager.BudgetStats(true)
+					logger.L().Info("in-flight byte budget stats", helpers.Int("limit", int(budget.Limit)),
+						helpers.Int("inFlight", int(budget.InFlight)), helpers.Int("peak", int(budget.Peak)),
+						helpers.Int("waiting", budget.Waiting), helpers.Int("admitted", int(budget.Admitted)),
+						helpers.Int("rejected", int(budget.Rejected)))
+				}
+				watches := o.watchAdmission.Stats(true)
+				logger.L().Info("watch admission stats", helpers.Int("active", watches.Active),
+					helpers.Int("admitted", int(watches.Admitted)), helpers.Int("rejected", int(watches.Rejected)),
+					helpers.Int("shed", int(watches.Shed)))
 			}
 		}()
 	}
@@ -0,0 +1,366 @@
+package compiledprofile
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/netip"
+)
+
+// Magic opens every encoded profile, FormatVersion follows it. A change to
+// the encoding bumps FormatVersion, Decode rejects the versions it does not
+// know.
+const (
+	Magic         = "KSCP"
+	FormatVersion = 1
+)
+
+var (
+	ErrNotCompiledProfile = errors.New("not a compiled profile")
+	ErrUnsupportedVersion = errors.New("unsupported compiled profile version")
+	ErrTruncated          = errors.New("truncated compiled profile")
+)
+
+// Encode returns the binary encoding of p. The encoding is stable: equal
+// profiles encode to equal bytes, and the layout only changes with
+// FormatVersion. It is
+//
+//	profile   = "KSCP" uvarint(version) string(ImageID) string(ImageTag)
+//	            strings(Architectures) strings(Capabilities) strings(Syscalls)
+//	            list(exec) list(open) list(endpoint) list(policy)
+//	            list(neighbor) list(neighbor)
+//	exec      = string(Path) strings(Args) bool(ArgsRequired) strings(Envs)
+//	open      = string(Path) strings(Segments) strings(Flags)
+//	endpoint  = string(Endpoint) string(Direction) bool(Internal) strings(Methods)
+//	policy    = string(RuleID) bool(AllowedContainer) strings(AllowedProcesses)
+//	neighbor  = string(Identifier) string(Type) bool(AnyIP) list(prefix)
+//	            list(strings) list(port) selector selector
+//	prefix    = byte(4|16) address byte(bits)
+//	port      = string(Name) string(Protocol) uvarint(Port)
+//	selector  = byte(0) | byte(1) list(string string) list(requirement)
+//	requirement = string(Key) string(Operator) strings(Values)
+//	list(x)   = uvarint(count) x*
+//	strings   = list(string)
+//	string    = uvarint(length) bytes
+//	bool      = byte(0|1)
+//
+// Encode does not sort p: use the profiles produced by storage, or sort as
+// documented on Profile, for the lookup methods to work.
+func Encode(p *Profile) []byte {
+	e := encoder{buf: make([]byte, 0, 1024)}
+	e.buf = append(e.buf, Magic...)
+	e.uvarint(FormatVersion)
+	e.string(p.ImageID)
+	e.string(p.ImageTag)
+	e.strings(p.Architectures)
+	e.strings(p.Capabilities)
+	e.strings(p.Syscalls)
+	e.uvarint(uint64(len(p.Execs)))
+	for _, exec := range p.Execs {
+		e.string(exec.Path)
+		e.strings(exec.Args)
+		e.bool(exec.ArgsRequired)
+		e.strings(exec.Envs)
+	}
+	e.uvarint(uint64(len(p.Opens)))
+	for _, open := range p.Opens {
+		e.string(open.Path)
+		e.strings(open.Segments)
+		e.strings(open.Flags)
+	}
+	e.uvarint(uint64(len(p.Endpoints)))
+	for _, endpoint := range p.Endpoints {
+		e.string(endpoint.Endpoint)
+		e.string(endpoint.Direction)
+		e.bool(endpoint.Internal)
+		e.strings(endpoint.Methods)
+	}
+	e.uvarint(uint64(len(p.Policies)))
+	for _, policy := range p.Policies {
+		e.string(policy.RuleID)
+		e.bool(policy.AllowedContainer)
+		e.strings(policy.AllowedProcesses)
+	}
+	e.neighbors(p.Ingress)
+	e.neighbors(p.Egress)
+	return e.buf
+}
+
+// Decode parses the binary encoding of a profile, see Encode.
+func Decode(data []byte) (*Profile, error) {
+	if len(data) < len(Magic) || string(data[:len(Magic)]) != Magic {
+		return nil, ErrNotCompiledProfile
+	}
+	d := decoder{buf: data[len(Magic):]}
+	if version := d.uvarint(); d.err == nil && version != FormatVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, version)
+	}
+	p := &Profile{
+		ImageID:       d.string(),
+		ImageTag:      d.string(),
+		Architectures: d.strings(),
+		Capabilities:  d.strings(),
+		Syscalls:      d.strings(),
+	}
+	if n := d.count(); n > 0 {
+		p.Execs = make([]Exec, n)
+		for i := range p.Execs {
+			p.Execs[i] = Exec{Path: d.string(), Args: d.strings(), ArgsRequired: d.bool(), Envs: d.strings()}
+		}
+	}
+	if n := d.count(); n > 0 {
+		p.Opens = make([]Open, n)
+		for i := range p.Opens {
+			p.Opens[i] = Open{Path: d.string(), Segments: d.strings(), Flags: d.strings()}
+		}
+	}
+	if n := d.count(); n > 0 {
+		p.Endpoints = make([]Endpoint, n)
+		for i := range p.Endpoints {
+			p.Endpoints[i] = Endpoint{Endpoint: d.string(), Direction: d.string(), Internal: d.bool(), Methods: d.strings()}
+		}
+	}
+	if n := d.count(); n > 0 {
+		p.Policies = make([]RulePolicy, n)
+		for i := range p.Policies {
+			p.Policies[i] = RulePolicy{RuleID: d.string(), AllowedContainer: d.bool(), AllowedProcesses: d.strings()}
+		}
+	}
+	p.Ingress = d.neighbors()
+	p.Egress = d.neighbors()
+	if d.err != nil {
+		return nil, d.err
+	}
+	if len(d.buf) > 0 {
+		return nil, fmt.Errorf("%d trailing bytes after compiled profile", len(d.buf))
+	}
+	return p, nil
+}
+
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) uvarint(v uint64) {
+	e.buf = binary.AppendUvarint(e.buf, v)
+}
+
+func (e *encoder) string(s string) {
+	e.uvarint(uint64(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+func (e *encoder) strings(ss []string) {
+	e.uvarint(uint64(len(ss)))
+	for _, s := range ss {
+		e.string(s)
+	}
+}
+
+func (e *encoder) bool(b bool) {
+	if b {
+		e.buf = append(e.buf, 1)
+	} else {
+		e.buf = append(e.buf, 0)
+	}
+}
+
+func (e *encoder) neighbors(neighbors []Neighbor) {
+	e.uvarint(uint64(len(neighbors)))
+	for _, n := range neighbors {
+		e.string(n.Identifier)
+		e.string(n.Type)
+		e.bool(n.AnyIP)
+		e.uvarint(uint64(len(n.Prefixes)))
+		for _, prefix := range n.Prefixes {
+			addr := prefix.Addr().AsSlice()
+			e.buf = append(e.buf, byte(len(addr)))
+			e.buf = append(e.buf, addr...)
+			e.buf = append(e.buf, byte(prefix.Bits()))
+		}
+		e.uvarint(uint64(len(n.DNSNames)))
+		for _, labels := range n.DNSNames {
+			e.strings(labels)
+		}
+		e.uvarint(uint64(len(n.Ports)))
+		for _, port := range n.Ports {
+			e.string(port.Name)
+			e.string(port.Protocol)
+			e.uvarint(uint64(uint32(port.Port)))
+		}
+		e.selector(n.PodSelector)
+		e.selector(n.NamespaceSelector)
+	}
+}
+
+func (e *encoder) selector(s *Selector) {
+	if s == nil {
+		e.bool(false)
+		return
+	}
+	e.bool(true)
+	e.uvarint(uint64(len(s.MatchLabels)))
+	for _, label := range s.MatchLabels {
+		e.string(label.Key)
+		e.string(label.Value)
+	}
+	e.uvarint(uint64(len(s.MatchExpressions)))
+	for _, req := range s.MatchExpressions {
+		e.string(req.Key)
+		e.string(req.Operator)
+		e.strings(req.Values)
+	}
+}
+
+// decoder reads the encoding, the first error sticks and turns every
+// further read into a zero value.
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) fail(err error) {
+	if d.err == nil {
+		d.err = err
+	}
+	d.buf = nil
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.buf)
+	if n <= 0 {
+		d.fail(ErrTruncated)
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return v
+}
+
+// count reads a list length, bounded by the bytes left since every element
+// takes at least one byte.
+func (d *decoder) count() int {
+	n := d.uvarint()
+	if n > uint64(len(d.buf)) {
+		d.fail(ErrTruncated)
+		return 0
+	}
+	return int(n)
+}
+
+func (d *decoder) bytes(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n > len(d.buf) {
+		d.fail(ErrTruncated)
+		return nil
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+func (d *decoder) string() string {
+	return string(d.bytes(d.count()))
+}
+
+func (d *decoder) strings() []string {
+	n := d.count()
+	if n == 0 {
+		return nil
+	}
+	ss := make([]string, n)
+	for i := range ss {
+		ss[i] = d.string()
+	}
+	return ss
+}
+
+func (d *decoder) bool() bool {
+	b := d.bytes(1)
+	if len(b) == 0 {
+		return false
+	}
+	if b[0] > 1 {
+		d.fail(fmt.Errorf("invalid bool %d in compiled profile", b[0]))
+		return false
+	}
+	return b[0] == 1
+}
+
+func (d *decoder) neighbors() []Neighbor {
+	n := d.count()
+	if n == 0 {
+		return nil
+	}
+	neighbors := make([]Neighbor, n)
+	for i := range neighbors {
+		nb := Neighbor{Identifier: d.string(), Type: d.string(), AnyIP: d.bool()}
+		if n := d.count(); n > 0 {
+			nb.Prefixes = make([]netip.Prefix, n)
+			for j := range nb.Prefixes {
+				nb.Prefixes[j] = d.prefix()
+			}
+		}
+		if n := d.count(); n > 0 {
+			nb.DNSNames = make([][]string, n)
+			for j := range nb.DNSNames {
+				nb.DNSNames[j] = d.strings()
+			}
+		}
+		if n := d.count(); n > 0 {
+			nb.Ports = make([]Port, n)
+			for j := range nb.Ports {
+				nb.Ports[j] = Port{Name: d.string(), Protocol: d.string(), Port: int32(uint32(d.uvarint()))}
+			}
+		}
+		nb.PodSelector = d.selector()
+		nb.NamespaceSelector = d.selector()
+		neighbors[i] = nb
+	}
+	return neighbors
+}
+
+func (d *decoder) prefix() netip.Prefix {
+	size := d.bytes(1)
+	if len(size) == 0 {
+		return netip.Prefix{}
+	}
+	addr, ok := netip.AddrFromSlice(d.bytes(int(size[0])))
+	bits := d.bytes(1)
+	if d.err != nil {
+		return netip.Prefix{}
+	}
+	if !ok {
+		d.fail(fmt.Errorf("invalid address of %d bytes in compiled profile", size[0]))
+		return netip.Prefix{}
+	}
+	prefix := netip.PrefixFrom(addr, int(bits[0]))
+	if !prefix.IsValid() {
+		d.fail(fmt.Errorf("invalid prefix length %d in compiled profile", bits[0]))
+	}
+	return prefix
+}
+
+func (d *decoder) selector() *Selector {
+	if !d.bool() {
+		return nil
+	}
+	s := &Selector{}
+	if n := d.count(); n > 0 {
+		s.MatchLabels = make([]Label, n)
+		for i := range s.MatchLabels {
+			s.MatchLabels[i] = Label{Key: d.string(), Value: d.string()}
+		}
+	}
+	if n := d.count(); n > 0 {
+		s.MatchExpressions = make([]Requirement, n)
+		for i := range s.MatchExpressions {
+			s.MatchExpressions[i] = Requirement{Key: d.string(), Operator: d.string(), Values: d.strings()}
+		}
+	}
+	return s
+}
@@ -0,0 +1,125 @@
+package compiledprofile
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testProfile() *Profile {
+	return &Profile{
+		ImageID:       "sha256:abc",
+		ImageTag:      "nginx:1.27",
+		Architectures: []string{"amd64"},
+		Capabilities:  []string{"CAP_CHOWN", "CAP_NET_BIND_SERVICE"},
+		Syscalls:      []string{"accept4", "openat", "read"},
+		Execs: []Exec{
+			{Path: "/bin/sh", Args: []string{"-c", "⋯"}, Envs: []string{"HOME=/root"}},
+			{Path: "/usr/sbin/nginx", Args: []string{"⋯⋯"}, ArgsRequired: true},
+		},
+		Opens: []Open{
+			{Path: "/etc/nginx/nginx.conf", Segments: []string{"", "etc", "nginx", "nginx.conf"}, Flags: []string{"O_RDONLY"}},
+		},
+		Endpoints: []Endpoint{
+			{Endpoint: ":80/health", Direction: "inbound", Internal: true, Methods: []string{"GET"}},
+		},
+		Policies: []RulePolicy{
+			{RuleID: "R0001", AllowedProcesses: []string{"nginx"}},
+			{RuleID: "R0002", AllowedContainer: true},
+		},
+		Ingress: []Neighbor{{
+			Identifier: "ingress",
+			Type:       "internal",
+			Prefixes:   []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")},
+			Ports:      []Port{{Name: "TCP-80", Protocol: "TCP", Port: 80}},
+			PodSelector: &Selector{
+				MatchLabels:      []Label{{Key: "app", Value: "gateway"}},
+				MatchExpressions: []Requirement{{Key: "tier", Operator: "In", Values: []string{"edge", "web"}}},
+			},
+		}},
+		Egress: []Neighbor{{
+			Identifier: "egress",
+			Type:       "external",
+			AnyIP:      false,
+			Prefixes: []netip.Prefix{
+				netip.MustParsePrefix("93.184.216.34/32"),
+				netip.MustParsePrefix("2001:db8::/32"),
+			},
+			DNSNames:          [][]string{{"*", "example", "com"}},
+			Ports:             []Port{{Name: "TCP-443", Protocol: "TCP", Port: 443}},
+			NamespaceSelector: &Selector{},
+		}},
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	p := testProfile()
+	data := Encode(p)
+	got, err := Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, p, got)
+	// the encoding is stable
+	assert.Equal(t, data, Encode(got))
+
+	empty, err := Decode(Encode(&Profile{}))
+	require.NoError(t, err)
+	assert.Equal(t, &Profile{}, empty)
+}
+
+func TestEncode_Header(t *testing.T) {
+	data := Encode(&Profile{ImageID: "a"})
+	assert.Equal(t, []byte{'K', 'S', 'C', 'P', FormatVersion, 1, 'a', 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, data)
+}
+
+func TestDecode_Rejects(t *testing.T) {
+	data := Encode(testProfile())
+
+	_, err := Decode([]byte("{}"))
+	assert.ErrorIs(t, err, ErrNotCompiledProfile)
+
+	future := append([]byte(Magic), 2)
+	_, err = Decode(append(future, data[len(Magic)+1:]...))
+	assert.ErrorIs(t, err, ErrUnsupportedVersion)
+
+	for _, n := range []int{len(Magic), len(Magic) + 1, len(data) / 2, len(data) - 1} {
+		_, err = Decode(data[:n])
+		assert.ErrorIs(t, err, ErrTruncated, "truncated at %d", n)
+	}
+
+	_, err = Decode(append(data[:len(data):len(data)], 0))
+	assert.ErrorContains(t, err, "trailing bytes")
+
+	// a count larger than the data left is not allocated
+	_, err = Decode([]byte{'K', 'S', 'C', 'P', FormatVersion, 0, 0, 0, 0, 0xff, 0xff, 0xff, 0xff, 0x0f})
+	assert.ErrorIs(t, err, ErrTruncated)
+}
+
+func TestProfile_Lookups(t *testing.T) {
+	p := testProfile()
+	assert.True(t, p.HasSyscall("openat"))
+	assert.False(t, p.HasSyscall("ptrace"))
+	assert.True(t, p.HasCapability("CAP_CHOWN"))
+	assert.False(t, p.HasCapability("CAP_SYS_ADMIN"))
+
+	assert.Len(t, p.ExecsFor("/bin/sh"), 1)
+	assert.Nil(t, p.ExecsFor("/bin/bash"))
+
+	policy, ok := p.Policy("R0002")
+	assert.True(t, ok)
+	assert.True(t, policy.AllowedContainer)
+	_, ok = p.Policy("R0003")
+	assert.False(t, ok)
+
+	egress := p.Egress[0]
+	assert.True(t, egress.MatchIP(netip.MustParseAddr("93.184.216.34")))
+	assert.True(t, egress.MatchIP(netip.MustParseAddr("::ffff:93.184.216.34")))
+	assert.True(t, egress.MatchIP(netip.MustParseAddr("2001:db8::1")))
+	assert.False(t, egress.MatchIP(netip.MustParseAddr("93.184.216.35")))
+	assert.False(t, egress.MatchIP(netip.Addr{}))
+	assert.True(t, egress.HasPort("tcp", 443))
+	assert.False(t, egress.HasPort("UDP", 443))
+
+	assert.True(t, (&Neighbor{AnyIP: true}).MatchIP(netip.MustParseAddr("1.1.1.1")))
+}
@@ -0,0 +1,179 @@
+// Package compiledprofile is the compiled, pre-indexed form of a
+// ContainerProfile that storage serves on the containerprofiles/compiled
+// subresource. Consumers decode it with Decode instead of rebuilding their
+// own lookup structures from the profile on every update: every set is
+// sorted and deduplicated, open paths are split into segments, IP entries are
+// parsed into prefixes and DNS entries into lowercased labels.
+//
+// The package has no dependency on the API types so that it stays cheap to
+// import; see Encode for the binary encoding.
+package compiledprofile
+
+import (
+	"net/netip"
+	"sort"
+	"strings"
+)
+
+// Profile is a compiled ContainerProfile. All slices are sorted as
+// documented on their fields, which the lookup methods rely on.
+type Profile struct {
+	ImageID  string
+	ImageTag string
+	// Architectures, Capabilities and Syscalls are sorted and deduplicated.
+	Architectures []string
+	Capabilities  []string
+	Syscalls      []string
+	// Execs are sorted by Path, then by Args.
+	Execs []Exec
+	// Opens are sorted by Path.
+	Opens []Open
+	// Endpoints are sorted by Direction, then by Endpoint.
+	Endpoints []Endpoint
+	// Policies are sorted by RuleID.
+	Policies []RulePolicy
+	// Ingress and Egress are sorted by Identifier.
+	Ingress []Neighbor
+	Egress  []Neighbor
+}
+
+// Exec is one exec entry. Args may hold the "⋯" and "⋯⋯" wildcard tokens of
+// the profile, Envs are sorted.
+type Exec struct {
+	Path         string
+	Args         []string
+	ArgsRequired bool
+	Envs         []string
+}
+
+// Open is one open entry, Segments is Path split on "/" so that the leading
+// empty segment of an absolute path is kept. Flags are sorted.
+type Open struct {
+	Path     string
+	Segments []string
+	Flags    []string
+}
+
+// Endpoint is one HTTP endpoint, Methods are sorted.
+type Endpoint struct {
+	Endpoint  string
+	Direction string
+	Internal  bool
+	Methods   []string
+}
+
+// RulePolicy is the policy of one rule, AllowedProcesses are sorted.
+type RulePolicy struct {
+	RuleID           string
+	AllowedContainer bool
+	AllowedProcesses []string
+}
+
+// Neighbor is one network neighbor.
+type Neighbor struct {
+	Identifier string
+	Type       string
+	// AnyIP is set when the neighbor admits any address ("*").
+	AnyIP bool
+	// Prefixes are the IP entries, a literal address being a single-address
+	// prefix, sorted and deduplicated.
+	Prefixes []netip.Prefix
+	// DNSNames are the DNS entries split into lowercased labels without the
+	// trailing dot, sorted by name and deduplicated. Labels may be the "*"
+	// and "⋯" wildcards of the profile.
+	DNSNames [][]string
+	// Ports are sorted by Protocol, then Port.
+	Ports             []Port
+	PodSelector       *Selector
+	NamespaceSelector *Selector
+}
+
+// Port is one port of a neighbor, Port is 0 when the profile has none.
+type Port struct {
+	Name     string
+	Protocol string
+	Port     int32
+}
+
+// Selector is a label selector, with MatchLabels sorted by key and
+// MatchExpressions kept in profile order with sorted Values.
+type Selector struct {
+	MatchLabels      []Label
+	MatchExpressions []Requirement
+}
+
+type Label struct {
+	Key   string
+	Value string
+}
+
+type Requirement struct {
+	Key      string
+	Operator string
+	Values   []string
+}
+
+// HasSyscall reports whether the profile allows the syscall.
+func (p *Profile) HasSyscall(name string) bool {
+	return contains(p.Syscalls, name)
+}
+
+// HasCapability reports whether the profile allows the capability.
+func (p *Profile) HasCapability(name string) bool {
+	return contains(p.Capabilities, name)
+}
+
+// ExecsFor returns the exec entries of path, nil if there are none.
+func (p *Profile) ExecsFor(path string) []Exec {
+	i := sort.Search(len(p.Execs), func(i int) bool { return p.Execs[i].Path >= path })
+	j := i
+	for j < len(p.Execs) && p.Execs[j].Path == path {
+		j++
+	}
+	if i == j {
+		return nil
+	}
+	return p.Execs[i:j]
+}
+
+// Policy returns the policy of ruleID.
+func (p *Profile) Policy(ruleID string) (RulePolicy, bool) {
+	i := sort.Search(len(p.Policies), func(i int) bool { return p.Policies[i].RuleID >= ruleID })
+	if i < len(p.Policies) && p.Policies[i].RuleID == ruleID {
+		return p.Policies[i], true
+	}
+	return RulePolicy{}, false
+}
+
+// MatchIP reports whether the neighbor admits addr.
+func (n *Neighbor) MatchIP(addr netip.Addr) bool {
+	if !addr.IsValid() {
+		return false
+	}
+	if n.AnyIP {
+		return true
+	}
+	addr = addr.Unmap()
+	for _, prefix := range n.Prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPort reports whether the neighbor lists protocol and port, protocol is
+// compared case-insensitively.
+func (n *Neighbor) HasPort(protocol string, port int32) bool {
+	for _, p := range n.Ports {
+		if p.Port == port && strings.EqualFold(p.Protocol, protocol) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(sorted []string, s string) bool {
+	i := sort.SearchStrings(sorted, s)
+	return i < len(sorted) && sorted[i] == s
+}
@@ -9,18 +9,28 @@ import (
 )
 
 type KindQueueConfig struct {
-	QueueLength   int `mapstructure:"queueLength"`
-	WorkerCount   int `mapstructure:"workerCount"`
-	MaxObjectSize int `mapstructure:"maxObjectSize"`
+	QueueLength          int `mapstructure:"queueLength"`
+	WorkerCount          int `mapstructure:"workerCount"`
+	MaxObjectSize        int `mapstructure:"maxObjectSize"`
+	MaxInFlightPerClient int `mapstructure:"maxInFlightPerClient"`
+}
+
+// QuotaConfig caps the objects of a kind in a namespace, 0 for no limit.
+type QuotaConfig struct {
+	MaxObjects int64 `mapstructure:"maxObjects" json:"maxObjects"`
+	MaxBytes   int64 `mapstructure:"maxBytes" json:"maxBytes"`
 }
 
 type Config struct {
+	CallStackMaxBreadth           int                `mapstructure:"callStackMaxBreadth"`
+	CallStackMaxDepth             int                `mapstructure:"callStackMaxDepth"`
 	CleanupInterval               time.Duration      `mapstructure:"cleanupInterval"`
 	DefaultNamespace              string             `mapstructure:"defaultNamespace"`
 	HostType                      armotypes.HostType `mapstructure:"hostType"`
 	DisableVirtualCRDs            bool               `mapstructure:"disableVirtualCRDs"`
 	DisableSeccompProfileEndpoint bool               `mapstructure:"disableSeccompProfileEndpoint"`
 	ExcludeJsonPaths              []string           `mapstructure:"excludeJsonPaths"`
+	ManagedFieldsResources        []string           `mapstructure:"managedFieldsResources"`
 	MaxApplicationProfileSize     int                `mapstructure:"maxApplicationProfileSize"`
 	MaxNetworkNeighborhoodSize    int                `mapstructure:"maxNetworkNeighborhoodSize"`
 	MaxSniffingTime               time.Duration      `mapstructure:"maxSniffingTimePerContainer"`
@@ -37,6 +47,55 @@ type Config struct {
 	DefaultQueueLength   int                        `mapstructure:"defaultQueueLength"`
 	DefaultWorkerCount   int                        `mapstructure:"defaultWorkerCount"`
 	DefaultMaxObjectSize int                        `mapstructure:"defaultMaxObjectSize"`
+	// InFlightBytesBudget caps the bytes of the requests served at once across
+	// kinds, writes counting their content length and reads their payload
+	// size; a request waits InFlightBytesWait for the budget before getting a
+	// 429. 0 disables the budget.
+	InFlightBytesBudget int64         `mapstructure:"inFlightBytesBudget"`
+	InFlightBytesWait   time.Duration `mapstructure:"inFlightBytesWait"`
+	// The workers of a kind queue are handed out by weighted round-robin
+	// across clients, a client being a user and a node. ClientWeights gives
+	// the weight of the clients of a user, 1 by default, and
+	// MaxInFlightPerClient caps the workers of a client, 0 for no cap.
+	ClientWeights        map[string]int `mapstructure:"clientWeights"`
+	MaxInFlightPerClient int            `mapstructure:"maxInFlightPerClient"`
+	// Watches are admitted up to MaxWatches overall, MaxWatchesPerClient per
+	// client and WatchResourceCaps per resource, 0 for no limit. A rejected or
+	// shed watch is asked to retry after WatchRetryAfter.
+	MaxWatches          int            `mapstructure:"maxWatches"`
+	MaxWatchesPerClient int            `mapstructure:"maxWatchesPerClient"`
+	WatchResourceCaps   map[string]int `mapstructure:"watchResourceCaps"`
+	WatchRetryAfter     time.Duration  `mapstructure:"watchRetryAfter"`
+	// Quotas caps the objects created per namespace and kind, keyed by
+	// namespace then kind, "*" standing for any namespace or kind without an
+	// entry of its own.
+	Quotas map[string]map[string]QuotaConfig `mapstructure:"quotas"`
+	// The volumes of the storage root and of the metadata database are
+	// checked every DiskCheckInterval. Past DiskCleanupWatermark percent used
+	// an emergency cleanup runs, past DiskLargeKindsWatermark the writes of
+	// DiskLargeKinds are rejected and past DiskReadOnlyWatermark every write
+	// is. 0 disables a watermark.
+	DiskCheckInterval       time.Duration `mapstructure:"diskCheckInterval"`
+	DiskCleanupWatermark    int           `mapstructure:"diskCleanupWatermark"`
+	DiskLargeKindsWatermark int           `mapstructure:"diskLargeKindsWatermark"`
+	DiskReadOnlyWatermark   int           `mapstructure:"diskReadOnlyWatermark"`
+	DiskLargeKinds          []string      `mapstructure:"diskLargeKinds"`
+	// The integrity scrubber checks every payload, at most ScrubRate per
+	// second, then waits ScrubInterval before its next pass. 0 disables it.
+	ScrubInterval time.Duration `mapstructure:"scrubInterval"`
+	ScrubRate     int           `mapstructure:"scrubRate"`
+	// PayloadCodec is the encoding the payloads are written in, "gob" as the
+	// previous releases or "protobuf". The payloads in another encoding are
+	// rewritten when read, and by the sweep at startup. The previous releases
+	// cannot read protobuf: to roll back, set it to "gob" again and wait for
+	// the sweep to be done before downgrading.
+	PayloadCodec string `mapstructure:"payloadCodec"`
+	// The legacy payloads and the objects at an older schema version are
+	// migrated when read, and rewritten with those in an outdated format by a
+	// sweep at startup, at most MigrationSweepRate per second, 0 for no limit.
+	// With MigrationDryRun the migrations are only reported.
+	MigrationSweepRate int  `mapstructure:"migrationSweepRate"`
+	MigrationDryRun    bool `mapstructure:"migrationDryRun"`
 
 	// Debugging
 	QueueManagerEnabled       bool `mapstructure:"queueManagerEnabled"`
@@ -52,8 +111,11 @@ func LoadConfig(path string) (Config, error) {
 	v.SetConfigName("config")
 	v.SetConfigType("json")
 
+	v.SetDefault("callStackMaxBreadth", 32)
+	v.SetDefault("callStackMaxDepth", 64)
 	v.SetDefault("cleanupInterval", 24*time.Hour)
 	v.SetDefault("defaultNamespace", "kubescape")
+	v.SetDefault("managedFieldsResources", []string{"applicationprofiles", "containerprofiles", "networkneighborhoods"})
 	v.SetDefault("maxApplicationProfileSize", 40000)
 	v.SetDefault("maxNetworkNeighborhoodSize", 40000)
 	v.SetDefault("rateLimitTotal", 10)
@@ -62,6 +124,20 @@ func LoadConfig(path string) (Config, error) {
 	v.SetDefault("defaultQueueLength", 100)
 	v.SetDefault("defaultWorkerCount", 2)
 	v.SetDefault("defaultMaxObjectSize", 400000)
+	v.SetDefault("inFlightBytesBudget", 256*1024*1024)
+	v.SetDefault("inFlightBytesWait", 5*time.Second)
+	v.SetDefault("maxWatches", 10000)
+	v.SetDefault("maxWatchesPerClient", 32)
+	v.SetDefault("watchRetryAfter", 10*time.Second)
+	v.SetDefault("diskCheckInterval", 30*time.Second)
+	v.SetDefault("diskCleanupWatermark", 85)
+	v.SetDefault("diskLargeKindsWatermark", 90)
+	v.SetDefault("diskReadOnlyWatermark", 95)
+	v.SetDefault("diskLargeKinds", []string{"openvulnerabilityexchangecontainers", "sbomsyftfiltereds", "sbomsyfts", "vulnerabilitymanifests"})
+	v.SetDefault("scrubInterval", 24*time.Hour)
+	v.SetDefault("scrubRate", 20)
+	v.SetDefault("payloadCodec", "gob")
+	v.SetDefault("migrationSweepRate", 20)
 	v.SetDefault("queueManagerEnabled", false)
 	v.SetDefault("queueTimeoutPrint", false)
 	v.SetDefault("queueTimeout", 60)
@@ -21,10 +21,13 @@ func TestLoadConfig(t *testing.T) {
 			name: "TestLoadConfig",
 			path: "../../configuration",
 			want: Config{
+				CallStackMaxBreadth:        32,
+				CallStackMaxDepth:          64,
 				CleanupInterval:            24 * time.Hour,
 				DefaultNamespace:           "kubescape",
 				HostType:                   armotypes.HostTypeKubernetes,
 				ExcludeJsonPaths:           []string{".containers[*].env[?(@.name==\"KUBECONFIG\")]"},
+				ManagedFieldsResources:     []string{"applicationprofiles", "containerprofiles", "networkneighborhoods"},
 				MaxApplicationProfileSize:  40000,
 				MaxNetworkNeighborhoodSize: 40000,
 				RateLimitTotal:             10,
@@ -67,11 +70,25 @@ func TestLoadConfig(t *testing.T) {
 						MaxObjectSize: 10000000,
 					},
 				},
-				DefaultQueueLength:   100,
-				DefaultWorkerCount:   2,
-				DefaultMaxObjectSize: 400000,
-				QueueManagerEnabled:  true,
-				QueueTimeout:         60,
+				DefaultQueueLength:      100,
+				DefaultWorkerCount:      2,
+				DefaultMaxObjectSize:    400000,
+				InFlightBytesBudget:     256 * 1024 * 1024,
+				InFlightBytesWait:       5 * time.Second,
+				MaxWatches:              10000,
+				MaxWatchesPerClient:     32,
+				WatchRetryAfter:         10 * time.Second,
+				DiskCheckInterval:       30 * time.Second,
+				DiskCleanupWatermark:    85,
+				DiskLargeKindsWatermark: 90,
+				DiskReadOnlyWatermark:   95,
+				DiskLargeKinds:          []string{"openvulnerabilityexchangecontainers", "sbomsyftfiltereds", "sbomsyfts", "vulnerabilitymanifests"},
+				ScrubInterval:           24 * time.Hour,
+				ScrubRate:               20,
+				PayloadCodec:            "gob",
+				MigrationSweepRate:      20,
+				QueueManagerEnabled:     true,
+				QueueTimeout:            60,
 			},
 			wantErr: false,
 		},
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kubescape/go-logger"
+	"github.com/kubescape/go-logger/helpers"
+)
+
+// reloadPollInterval is how often the Watcher checks the configuration file.
+// Polling rather than watching the file copes with the symlink swap of a
+// mounted ConfigMap.
+var reloadPollInterval = 10 * time.Second
+
+// liveFields are the fields, by mapstructure name, a reload applies without
+// restarting. Any other field keeps its value until the next restart.
+var liveFields = map[string]bool{
+	"cleanupInterval":             true,
+	"clientWeights":               true,
+	"defaultMaxObjectSize":        true,
+	"defaultQueueLength":          true,
+	"defaultWorkerCount":          true,
+	"diskCheckInterval":           true,
+	"diskCleanupWatermark":        true,
+	"diskLargeKinds":              true,
+	"diskLargeKindsWatermark":     true,
+	"diskReadOnlyWatermark":       true,
+	"inFlightBytesWait":           true,
+	"kindQueues":                  true,
+	"maxApplicationProfileSize":   true,
+	"maxInFlightPerClient":        true,
+	"maxNetworkNeighborhoodSize":  true,
+	"maxSniffingTimePerContainer": true,
+	"maxWatches":                  true,
+	"maxWatchesPerClient":         true,
+	"quotas":                      true,
+	"scrubInterval":               true,
+	"scrubRate":                   true,
+	"watchResourceCaps":           true,
+	"watchRetryAfter":             true,
+}
+
+// Diff returns the names, by mapstructure tag, of the fields differing between
+// a and b.
+func Diff(a, b Config) []string {
+	var fields []string
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	t := va.Type()
+	for i := range t.NumField() {
+		if !reflect.DeepEqual(va.Field(i).Interface(), vb.Field(i).Interface()) {
+			fields = append(fields, t.Field(i).Tag.Get("mapstructure"))
+		}
+	}
+	slices.Sort(fields)
+	return fields
+}
+
+// applyLive returns cur with the live fields of next.
+func applyLive(cur, next Config) Config {
+	out := cur
+	vo, vn := reflect.ValueOf(&out).Elem(), reflect.ValueOf(next)
+	t := vo.Type()
+	for i := range t.NumField() {
+		if liveFields[t.Field(i).Tag.Get("mapstructure")] {
+			vo.Field(i).Set(vn.Field(i))
+		}
+	}
+	return out
+}
+
+// Watcher reloads the configuration when its file changes. A new file is
+// loaded and validated by LoadConfig, an invalid one is ignored. The live
+// fields of a valid one are published at once to the subscribers, the
+// changes to the other fields are logged as needing a restart.
+type Watcher struct {
+	path     string
+	override func(*Config)
+	current  atomic.Pointer[Config]
+
+	mu          sync.Mutex
+	loaded      Config // last loaded, with override applied
+	content     []byte // of the file last loaded
+	subscribers []func(*Config)
+}
+
+// NewWatcher returns a Watcher of the configuration in path, cfg being the
+// configuration in effect as loaded by LoadConfig. override, if not nil,
+// adjusts every configuration loaded, as it was done to cfg.
+func NewWatcher(path string, cfg Config, override func(*Config)) *Watcher {
+	w := &Watcher{
+		path:     path,
+		override: override,
+		loaded:   cfg,
+	}
+	w.content, _ = os.ReadFile(w.file())
+	w.current.Store(&cfg)
+	return w
+}
+
+func (w *Watcher) file() string {
+	return filepath.Join(w.path, "config.json")
+}
+
+// Config returns the configuration in effect, it must not be modified.
+func (w *Watcher) Config() *Config {
+	return w.current.Load()
+}
+
+// Subscribe calls f with the configuration in effect after every reload
+// changing a live field. f must not modify it nor block.
+func (w *Watcher) Subscribe(f func(cfg *Config)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subscribers = append(w.subscribers, f)
+}
+
+// Run checks the configuration file for changes until ctx is done.
+func (w *Watcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(reloadPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.Reload(); err != nil {
+				logger.L().Warning("config reload failed, keeping the current configuration", helpers.Error(err))
+			}
+		}
+	}
+}
+
+// Reload loads the configuration file if it changed since last loaded and
+// publishes its live fields.
+func (w *Watcher) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	content, err := os.ReadFile(w.file())
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(content, w.content) {
+		return nil
+	}
+	next, err := LoadConfig(w.path)
+	if err != nil {
+		return err
+	}
+	if w.override != nil {
+		w.override(&next)
+	}
+	var restart []string
+	for _, field := range Diff(w.loaded, next) {
+		if !liveFields[field] {
+			restart = append(restart, field)
+		}
+	}
+	if len(restart) > 0 {
+		logger.L().Warning("config changes need a restart to take effect", helpers.Interface("fields", restart))
+	}
+	w.loaded, w.content = next, content
+
+	cur := w.current.Load()
+	effective := applyLive(*cur, next)
+	changed := Diff(*cur, effective)
+	if len(changed) == 0 {
+		return nil
+	}
+	w.current.Store(&effective)
+	logger.L().Info("config reloaded", helpers.Interface("fields", changed))
+	for _, f := range w.subscribers {
+		f(&effective)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, dir, content string) {
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.json"), []byte(content), 0o644))
+}
+
+func TestDiff(t *testing.T) {
+	a := Config{CleanupInterval: time.Hour, KindQueues: map[string]KindQueueConfig{"sbomsyfts": {WorkerCount: 1}}}
+	b := a
+	assert.Empty(t, Diff(a, b))
+	b.ServerBindPort = 8443
+	b.KindQueues = map[string]KindQueueConfig{"sbomsyfts": {WorkerCount: 2}}
+	assert.Equal(t, []string{"kindQueues", "serverBindPort"}, Diff(a, b))
+}
+
+func TestWatcher_Reload(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, `{"cleanupInterval": "1h", "serverBindPort": 8443}`)
+	cfg, err := LoadConfig(dir)
+	require.NoError(t, err)
+	override := func(c *Config) { c.DefaultNamespace = "custom" }
+	override(&cfg)
+	w := NewWatcher(dir, cfg, override)
+	var published []*Config
+	w.Subscribe(func(c *Config) { published = append(published, c) })
+
+	// unchanged file
+	require.NoError(t, w.Reload())
+	assert.Empty(t, published)
+
+	// live and restart-only changes
+	writeConfig(t, dir, `{"cleanupInterval": "2h", "maxApplicationProfileSize": 1000, "serverBindPort": 9443,
+		"kindQueues": {"sbomsyfts": {"queueLength": 5, "workerCount": 3}}}`)
+	require.NoError(t, w.Reload())
+	require.Len(t, published, 1)
+	got := w.Config()
+	assert.Same(t, published[0], got)
+	assert.Equal(t, 2*time.Hour, got.CleanupInterval)
+	assert.Equal(t, 1000, got.MaxApplicationProfileSize)
+	assert.Equal(t, map[string]KindQueueConfig{"sbomsyfts": {QueueLength: 5, WorkerCount: 3}}, got.KindQueues)
+	assert.Equal(t, 8443, got.ServerBindPort, "restart-only field changed live")
+	assert.Equal(t, "custom", got.DefaultNamespace)
+
+	// invalid file is ignored
+	writeConfig(t, dir, `{"cleanupInterval": "3h", "hostType": "nope"}`)
+	assert.Error(t, w.Reload())
+	assert.Equal(t, 2*time.Hour, w.Config().CleanupInterval)
+	writeConfig(t, dir, `{"cleanupInterval": `)
+	assert.Error(t, w.Reload())
+	assert.Len(t, published, 1)
+
+	// restart-only change alone publishes nothing
+	writeConfig(t, dir, `{"cleanupInterval": "2h", "maxApplicationProfileSize": 1000, "serverBindPort": 10443,
+		"kindQueues": {"sbomsyfts": {"queueLength": 5, "workerCount": 3}}}`)
+	require.NoError(t, w.Reload())
+	assert.Len(t, published, 1)
+	assert.Equal(t, 8443, w.Config().ServerBindPort)
+}
@@ -37,6 +37,12 @@ import (
 // built-in /etc, /opt, /var/run (etc.) entries. List every prefix you want
 // active, including any default you wish to keep. When the resource is
 // absent the deflate path uses the compiled-in DefaultCollapseSettings.
+//
+// Other CollapseConfigurations are scoped by their NamespaceSelector and
+// WorkloadSelector: a profile they select is collapsed with the most
+// specific of them instead of "default", and the name of the configuration
+// used is recorded in the kubescape.io/collapse-configuration annotation of
+// the profile.
 type CollapseConfigurationApplyConfiguration struct {
 	v1.TypeMetaApplyConfiguration    `json:",inline"`
 	*v1.ObjectMetaApplyConfiguration `json:"metadata,omitempty"`
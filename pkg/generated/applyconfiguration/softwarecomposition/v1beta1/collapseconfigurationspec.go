@@ -18,10 +18,15 @@ limitations under the License.
 
 package v1beta1
 
+import (
+	v1 "k8s.io/client-go/applyconfigurations/meta/v1"
+)
+
 // CollapseConfigurationSpecApplyConfiguration represents a declarative configuration of the CollapseConfigurationSpec type for use
 // with apply.
 //
-// CollapseConfigurationSpec carries the cluster-wide collapse thresholds.
+// CollapseConfigurationSpec carries the collapse thresholds, cluster-wide for
+// "default" and scoped by the selectors otherwise.
 type CollapseConfigurationSpecApplyConfiguration struct {
 	// OpenDynamicThreshold is the fallback threshold for AnalyzeOpens when
 	// no per-prefix entry matches the walked path. Optional: when omitted
@@ -43,6 +48,45 @@ type CollapseConfigurationSpecApplyConfiguration struct {
 	// built-in /etc, /opt, /var/run (etc.) overrides — include them
 	// explicitly if you want them to remain in effect.
 	CollapseConfigs []CollapseConfigEntryApplyConfiguration `json:"collapseConfigs,omitempty"`
+	// IPCollapseThreshold is the number of addresses of a NetworkNeighborhood
+	// which may fall in one /24 (IPv4) or /64 (IPv6) before they fold into
+	// that CIDR. Optional with the same omitted/0-means-compiled-default
+	// semantics as OpenDynamicThreshold.
+	IPCollapseThreshold *int32 `json:"ipCollapseThreshold,omitempty"`
+	// DNSCollapseThreshold is the number of sibling labels which may appear
+	// under one DNS suffix before they fold into a "*.<suffix>" wildcard.
+	// Optional with the same omitted/0-means-compiled-default semantics as
+	// OpenDynamicThreshold.
+	DNSCollapseThreshold *int32 `json:"dnsCollapseThreshold,omitempty"`
+	// ExecArgsCollapseThreshold is the number of distinct values one argv
+	// position of an exec path may take before it collapses to "⋯"; argv of
+	// varying length collapses to a common prefix followed by "⋯⋯" under the
+	// same threshold. Optional with the same omitted/0-means-compiled-default
+	// semantics as OpenDynamicThreshold.
+	ExecArgsCollapseThreshold *int32 `json:"execArgsCollapseThreshold,omitempty"`
+	// EnvCollapseThreshold is the number of distinct values one environment
+	// variable of an exec path may take before only its name is kept.
+	// Optional with the same omitted/0-means-compiled-default semantics as
+	// OpenDynamicThreshold.
+	EnvCollapseThreshold *int32 `json:"envCollapseThreshold,omitempty"`
+	// NamespaceSelector scopes a CollapseConfiguration other than "default"
+	// to the profiles of the namespaces it selects; an empty selector
+	// selects all namespaces. Required on every configuration but
+	// "default", on which it must not be set: "default" applies to the
+	// profiles no scoped configuration selects.
+	NamespaceSelector *v1.LabelSelectorApplyConfiguration `json:"namespaceSelector,omitempty"`
+	// WorkloadSelector further scopes a configuration to the profiles whose
+	// labels it selects, e.g. kubescape.io/workload-name. When several
+	// scoped configurations select a profile, the most specific one wins:
+	// one with a WorkloadSelector over one without, then the one with the
+	// most selector requirements, then the first by name.
+	WorkloadSelector *v1.LabelSelectorApplyConfiguration `json:"workloadSelector,omitempty"`
+	// HeaderAllowlist is the names of the headers kept in the profile of an
+	// HTTP endpoint, matched case-insensitively, the others are dropped.
+	// Optional: when omitted or empty the compiled-in allowlist applies. The
+	// values of credential headers such as Authorization are never kept,
+	// only their names.
+	HeaderAllowlist []string `json:"headerAllowlist,omitempty"`
 }
 
 // CollapseConfigurationSpecApplyConfiguration constructs a declarative configuration of the CollapseConfigurationSpec type for use with
@@ -79,3 +123,61 @@ func (b *CollapseConfigurationSpecApplyConfiguration) WithCollapseConfigs(values
 	}
 	return b
 }
+
+// WithIPCollapseThreshold sets the IPCollapseThreshold field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the IPCollapseThreshold field is set to the value of the last call.
+func (b *CollapseConfigurationSpecApplyConfiguration) WithIPCollapseThreshold(value int32) *CollapseConfigurationSpecApplyConfiguration {
+	b.IPCollapseThreshold = &value
+	return b
+}
+
+// WithDNSCollapseThreshold sets the DNSCollapseThreshold field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the DNSCollapseThreshold field is set to the value of the last call.
+func (b *CollapseConfigurationSpecApplyConfiguration) WithDNSCollapseThreshold(value int32) *CollapseConfigurationSpecApplyConfiguration {
+	b.DNSCollapseThreshold = &value
+	return b
+}
+
+// WithExecArgsCollapseThreshold sets the ExecArgsCollapseThreshold field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the ExecArgsCollapseThreshold field is set to the value of the last call.
+func (b *CollapseConfigurationSpecApplyConfiguration) WithExecArgsCollapseThreshold(value int32) *CollapseConfigurationSpecApplyConfiguration {
+	b.ExecArgsCollapseThreshold = &value
+	return b
+}
+
+// WithEnvCollapseThreshold sets the EnvCollapseThreshold field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the EnvCollapseThreshold field is set to the value of the last call.
+func (b *CollapseConfigurationSpecApplyConfiguration) WithEnvCollapseThreshold(value int32) *CollapseConfigurationSpecApplyConfiguration {
+	b.EnvCollapseThreshold = &value
+	return b
+}
+
+// WithNamespaceSelector sets the NamespaceSelector field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the NamespaceSelector field is set to the value of the last call.
+func (b *CollapseConfigurationSpecApplyConfiguration) WithNamespaceSelector(value *v1.LabelSelectorApplyConfiguration) *CollapseConfigurationSpecApplyConfiguration {
+	b.NamespaceSelector = value
+	return b
+}
+
+// WithWorkloadSelector sets the WorkloadSelector field in the declarative configuration to the given value
+// and returns the receiver, so that objects can be built by chaining "With" function invocations.
+// If called multiple times, the WorkloadSelector field is set to the value of the last call.
+func (b *CollapseConfigurationSpecApplyConfiguration) WithWorkloadSelector(value *v1.LabelSelectorApplyConfiguration) *CollapseConfigurationSpecApplyConfiguration {
+	b.WorkloadSelector = value
+	return b
+}
+
+// WithHeaderAllowlist adds the given value to the HeaderAllowlist field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the HeaderAllowlist field.
+func (b *CollapseConfigurationSpecApplyConfiguration) WithHeaderAllowlist(values ...string) *CollapseConfigurationSpecApplyConfiguration {
+	for i := range values {
+		b.HeaderAllowlist = append(b.HeaderAllowlist, values[i])
+	}
+	return b
+}
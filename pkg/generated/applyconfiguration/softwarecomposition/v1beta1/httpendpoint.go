@@ -27,11 +27,14 @@ import (
 // HTTPEndpointApplyConfiguration represents a declarative configuration of the HTTPEndpoint type for use
 // with apply.
 type HTTPEndpointApplyConfiguration struct {
-	Endpoint  *string                  `json:"endpoint,omitempty"`
-	Methods   []string                 `json:"methods,omitempty"`
-	Internal  *bool                    `json:"internal,omitempty"`
-	Direction *consts.NetworkDirection `json:"direction,omitempty"`
-	Headers   *json.RawMessage         `json:"headers,omitempty"`
+	Endpoint      *string                  `json:"endpoint,omitempty"`
+	Methods       []string                 `json:"methods,omitempty"`
+	Internal      *bool                    `json:"internal,omitempty"`
+	Direction     *consts.NetworkDirection `json:"direction,omitempty"`
+	Headers       *json.RawMessage         `json:"headers,omitempty"`
+	QueryParams   []string                 `json:"queryParams,omitempty"`
+	StatusClasses []string                 `json:"statusClasses,omitempty"`
+	ContentTypes  []string                 `json:"contentTypes,omitempty"`
 }
 
 // HTTPEndpointApplyConfiguration constructs a declarative configuration of the HTTPEndpoint type for use with
@@ -81,3 +84,33 @@ func (b *HTTPEndpointApplyConfiguration) WithHeaders(value json.RawMessage) *HTT
 	b.Headers = &value
 	return b
 }
+
+// WithQueryParams adds the given value to the QueryParams field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the QueryParams field.
+func (b *HTTPEndpointApplyConfiguration) WithQueryParams(values ...string) *HTTPEndpointApplyConfiguration {
+	for i := range values {
+		b.QueryParams = append(b.QueryParams, values[i])
+	}
+	return b
+}
+
+// WithStatusClasses adds the given value to the StatusClasses field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the StatusClasses field.
+func (b *HTTPEndpointApplyConfiguration) WithStatusClasses(values ...string) *HTTPEndpointApplyConfiguration {
+	for i := range values {
+		b.StatusClasses = append(b.StatusClasses, values[i])
+	}
+	return b
+}
+
+// WithContentTypes adds the given value to the ContentTypes field in the declarative configuration
+// and returns the receiver, so that objects can be build by chaining "With" function invocations.
+// If called multiple times, values provided by each call will be appended to the ContentTypes field.
+func (b *HTTPEndpointApplyConfiguration) WithContentTypes(values ...string) *HTTPEndpointApplyConfiguration {
+	for i := range values {
+		b.ContentTypes = append(b.ContentTypes, values[i])
+	}
+	return b
+}
@@ -102,6 +102,16 @@ func ForKind(kind schema.GroupVersionKind) interface{} {
 		return &softwarecompositionv1beta1.GrypeDocumentApplyConfiguration{}
 	case v1beta1.SchemeGroupVersion.WithKind("GrypePackage"):
 		return &softwarecompositionv1beta1.GrypePackageApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("Guardrail"):
+		return &softwarecompositionv1beta1.GuardrailApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("GuardrailRule"):
+		return &softwarecompositionv1beta1.GuardrailRuleApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("GuardrailSpec"):
+		return &softwarecompositionv1beta1.GuardrailSpecApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("GuardrailStatus"):
+		return &softwarecompositionv1beta1.GuardrailStatusApplyConfiguration{}
+	case v1beta1.SchemeGroupVersion.WithKind("GuardrailViolation"):
+		return &softwarecompositionv1beta1.GuardrailViolationApplyConfiguration{}
 	case v1beta1.SchemeGroupVersion.WithKind("HTTPEndpoint"):
 		return &softwarecompositionv1beta1.HTTPEndpointApplyConfiguration{}
 	case v1beta1.SchemeGroupVersion.WithKind("IdentifiedCallStack"):
@@ -51,6 +51,10 @@ type ApplicationProfileInterface interface {
 	Apply(ctx context.Context, applicationProfile *applyconfigurationsoftwarecompositionv1beta1.ApplicationProfileApplyConfiguration, opts v1.ApplyOptions) (result *softwarecompositionv1beta1.ApplicationProfile, err error)
 	// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
 	ApplyStatus(ctx context.Context, applicationProfile *applyconfigurationsoftwarecompositionv1beta1.ApplicationProfileApplyConfiguration, opts v1.ApplyOptions) (result *softwarecompositionv1beta1.ApplicationProfile, err error)
+	Evaluate(ctx context.Context, applicationProfileName string, profileEvaluation *softwarecompositionv1beta1.ProfileEvaluation, opts v1.CreateOptions) (*softwarecompositionv1beta1.ProfileEvaluation, error)
+	GetPending(ctx context.Context, applicationProfileName string, options v1.GetOptions) (*softwarecompositionv1beta1.ApplicationProfile, error)
+	QueryCallStacks(ctx context.Context, applicationProfileName string, callStackQuery *softwarecompositionv1beta1.CallStackQuery, opts v1.CreateOptions) (*softwarecompositionv1beta1.CallStackQuery, error)
+
 	ApplicationProfileExpansion
 }
 
@@ -76,3 +80,47 @@ func newApplicationProfiles(c *SpdxV1beta1Client, namespace string) *application
 		),
 	}
 }
+
+// Evaluate takes the representation of a profileEvaluation and creates it.  Returns the server's representation of the profileEvaluation, and an error, if there is any.
+func (c *applicationProfiles) Evaluate(ctx context.Context, applicationProfileName string, profileEvaluation *softwarecompositionv1beta1.ProfileEvaluation, opts v1.CreateOptions) (result *softwarecompositionv1beta1.ProfileEvaluation, err error) {
+	result = &softwarecompositionv1beta1.ProfileEvaluation{}
+	err = c.GetClient().Post().
+		Namespace(c.GetNamespace()).
+		Resource("applicationprofiles").
+		Name(applicationProfileName).
+		SubResource("evaluate").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(profileEvaluation).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// GetPending takes name of the applicationProfile, and returns the corresponding softwarecompositionv1beta1.ApplicationProfile object, and an error if there is any.
+func (c *applicationProfiles) GetPending(ctx context.Context, applicationProfileName string, options v1.GetOptions) (result *softwarecompositionv1beta1.ApplicationProfile, err error) {
+	result = &softwarecompositionv1beta1.ApplicationProfile{}
+	err = c.GetClient().Get().
+		Namespace(c.GetNamespace()).
+		Resource("applicationprofiles").
+		Name(applicationProfileName).
+		SubResource("pending").
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// QueryCallStacks takes the representation of a callStackQuery and creates it.  Returns the server's representation of the callStackQuery, and an error, if there is any.
+func (c *applicationProfiles) QueryCallStacks(ctx context.Context, applicationProfileName string, callStackQuery *softwarecompositionv1beta1.CallStackQuery, opts v1.CreateOptions) (result *softwarecompositionv1beta1.CallStackQuery, err error) {
+	result = &softwarecompositionv1beta1.CallStackQuery{}
+	err = c.GetClient().Post().
+		Namespace(c.GetNamespace()).
+		Resource("applicationprofiles").
+		Name(applicationProfileName).
+		SubResource("callstacks").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(callStackQuery).
+		Do(ctx).
+		Into(result)
+	return
+}
@@ -51,6 +51,11 @@ type ContainerProfileInterface interface {
 	Apply(ctx context.Context, containerProfile *applyconfigurationsoftwarecompositionv1beta1.ContainerProfileApplyConfiguration, opts v1.ApplyOptions) (result *softwarecompositionv1beta1.ContainerProfile, err error)
 	// Add a +genclient:noStatus comment above the type to avoid generating ApplyStatus().
 	ApplyStatus(ctx context.Context, containerProfile *applyconfigurationsoftwarecompositionv1beta1.ContainerProfileApplyConfiguration, opts v1.ApplyOptions) (result *softwarecompositionv1beta1.ContainerProfile, err error)
+	Evaluate(ctx context.Context, containerProfileName string, profileEvaluation *softwarecompositionv1beta1.ProfileEvaluation, opts v1.CreateOptions) (*softwarecompositionv1beta1.ProfileEvaluation, error)
+	GetCompiled(ctx context.Context, containerProfileName string, options v1.GetOptions) (*softwarecompositionv1beta1.CompiledContainerProfile, error)
+	GetPending(ctx context.Context, containerProfileName string, options v1.GetOptions) (*softwarecompositionv1beta1.ContainerProfile, error)
+	QueryCallStacks(ctx context.Context, containerProfileName string, callStackQuery *softwarecompositionv1beta1.CallStackQuery, opts v1.CreateOptions) (*softwarecompositionv1beta1.CallStackQuery, error)
+
 	ContainerProfileExpansion
 }
 
@@ -76,3 +81,61 @@ func newContainerProfiles(c *SpdxV1beta1Client, namespace string) *containerProf
 		),
 	}
 }
+
+// Evaluate takes the representation of a profileEvaluation and creates it.  Returns the server's representation of the profileEvaluation, and an error, if there is any.
+func (c *containerProfiles) Evaluate(ctx context.Context, containerProfileName string, profileEvaluation *softwarecompositionv1beta1.ProfileEvaluation, opts v1.CreateOptions) (result *softwarecompositionv1beta1.ProfileEvaluation, err error) {
+	result = &softwarecompositionv1beta1.ProfileEvaluation{}
+	err = c.GetClient().Post().
+		Namespace(c.GetNamespace()).
+		Resource("containerprofiles").
+		Name(containerProfileName).
+		SubResource("evaluate").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(profileEvaluation).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// GetCompiled takes name of the containerProfile, and returns the corresponding softwarecompositionv1beta1.CompiledContainerProfile object, and an error if there is any.
+func (c *containerProfiles) GetCompiled(ctx context.Context, containerProfileName string, options v1.GetOptions) (result *softwarecompositionv1beta1.CompiledContainerProfile, err error) {
+	result = &softwarecompositionv1beta1.CompiledContainerProfile{}
+	err = c.GetClient().Get().
+		Namespace(c.GetNamespace()).
+		Resource("containerprofiles").
+		Name(containerProfileName).
+		SubResource("compiled").
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// GetPending takes name of the containerProfile, and returns the corresponding softwarecompositionv1beta1.ContainerProfile object, and an error if there is any.
+func (c *containerProfiles) GetPending(ctx context.Context, containerProfileName string, options v1.GetOptions) (result *softwarecompositionv1beta1.ContainerProfile, err error) {
+	result = &softwarecompositionv1beta1.ContainerProfile{}
+	err = c.GetClient().Get().
+		Namespace(c.GetNamespace()).
+		Resource("containerprofiles").
+		Name(containerProfileName).
+		SubResource("pending").
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// QueryCallStacks takes the representation of a callStackQuery and creates it.  Returns the server's representation of the callStackQuery, and an error, if there is any.
+func (c *containerProfiles) QueryCallStacks(ctx context.Context, containerProfileName string, callStackQuery *softwarecompositionv1beta1.CallStackQuery, opts v1.CreateOptions) (result *softwarecompositionv1beta1.CallStackQuery, err error) {
+	result = &softwarecompositionv1beta1.CallStackQuery{}
+	err = c.GetClient().Post().
+		Namespace(c.GetNamespace()).
+		Resource("containerprofiles").
+		Name(containerProfileName).
+		SubResource("callstacks").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(callStackQuery).
+		Do(ctx).
+		Into(result)
+	return
+}
@@ -19,10 +19,14 @@ limitations under the License.
 package fake
 
 import (
+	context "context"
+
 	v1beta1 "github.com/kubescape/storage/pkg/apis/softwarecomposition/v1beta1"
 	softwarecompositionv1beta1 "github.com/kubescape/storage/pkg/generated/applyconfiguration/softwarecomposition/v1beta1"
 	typedsoftwarecompositionv1beta1 "github.com/kubescape/storage/pkg/generated/clientset/versioned/typed/softwarecomposition/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	gentype "k8s.io/client-go/gentype"
+	testing "k8s.io/client-go/testing"
 )
 
 // fakeApplicationProfiles implements ApplicationProfileInterface
@@ -51,3 +55,39 @@ func newFakeApplicationProfiles(fake *FakeSpdxV1beta1, namespace string) typedso
 		fake,
 	}
 }
+
+// Evaluate takes the representation of a profileEvaluation and creates it.  Returns the server's representation of the profileEvaluation, and an error, if there is any.
+func (c *fakeApplicationProfiles) Evaluate(ctx context.Context, applicationProfileName string, profileEvaluation *v1beta1.ProfileEvaluation, opts v1.CreateOptions) (result *v1beta1.ProfileEvaluation, err error) {
+	emptyResult := &v1beta1.ProfileEvaluation{}
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateSubresourceActionWithOptions(c.Resource(), applicationProfileName, "evaluate", c.Namespace(), profileEvaluation, opts), emptyResult)
+
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.ProfileEvaluation), err
+}
+
+// GetPending takes name of the applicationProfile, and returns the corresponding applicationProfile object, and an error if there is any.
+func (c *fakeApplicationProfiles) GetPending(ctx context.Context, applicationProfileName string, options v1.GetOptions) (result *v1beta1.ApplicationProfile, err error) {
+	emptyResult := &v1beta1.ApplicationProfile{}
+	obj, err := c.Fake.
+		Invokes(testing.NewGetSubresourceActionWithOptions(c.Resource(), c.Namespace(), "pending", applicationProfileName, options), emptyResult)
+
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.ApplicationProfile), err
+}
+
+// QueryCallStacks takes the representation of a callStackQuery and creates it.  Returns the server's representation of the callStackQuery, and an error, if there is any.
+func (c *fakeApplicationProfiles) QueryCallStacks(ctx context.Context, applicationProfileName string, callStackQuery *v1beta1.CallStackQuery, opts v1.CreateOptions) (result *v1beta1.CallStackQuery, err error) {
+	emptyResult := &v1beta1.CallStackQuery{}
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateSubresourceActionWithOptions(c.Resource(), applicationProfileName, "callstacks", c.Namespace(), callStackQuery, opts), emptyResult)
+
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.CallStackQuery), err
+}
@@ -19,10 +19,14 @@ limitations under the License.
 package fake
 
 import (
+	context "context"
+
 	v1beta1 "github.com/kubescape/storage/pkg/apis/softwarecomposition/v1beta1"
 	softwarecompositionv1beta1 "github.com/kubescape/storage/pkg/generated/applyconfiguration/softwarecomposition/v1beta1"
 	typedsoftwarecompositionv1beta1 "github.com/kubescape/storage/pkg/generated/clientset/versioned/typed/softwarecomposition/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	gentype "k8s.io/client-go/gentype"
+	testing "k8s.io/client-go/testing"
 )
 
 // fakeContainerProfiles implements ContainerProfileInterface
@@ -51,3 +55,51 @@ func newFakeContainerProfiles(fake *FakeSpdxV1beta1, namespace string) typedsoft
 		fake,
 	}
 }
+
+// Evaluate takes the representation of a profileEvaluation and creates it.  Returns the server's representation of the profileEvaluation, and an error, if there is any.
+func (c *fakeContainerProfiles) Evaluate(ctx context.Context, containerProfileName string, profileEvaluation *v1beta1.ProfileEvaluation, opts v1.CreateOptions) (result *v1beta1.ProfileEvaluation, err error) {
+	emptyResult := &v1beta1.ProfileEvaluation{}
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateSubresourceActionWithOptions(c.Resource(), containerProfileName, "evaluate", c.Namespace(), profileEvaluation, opts), emptyResult)
+
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.ProfileEvaluation), err
+}
+
+// GetCompiled takes name of the containerProfile, and returns the corresponding compiledContainerProfile object, and an error if there is any.
+func (c *fakeContainerProfiles) GetCompiled(ctx context.Context, containerProfileName string, options v1.GetOptions) (result *v1beta1.CompiledContainerProfile, err error) {
+	emptyResult := &v1beta1.CompiledContainerProfile{}
+	obj, err := c.Fake.
+		Invokes(testing.NewGetSubresourceActionWithOptions(c.Resource(), c.Namespace(), "compiled", containerProfileName, options), emptyResult)
+
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.CompiledContainerProfile), err
+}
+
+// GetPending takes name of the containerProfile, and returns the corresponding containerProfile object, and an error if there is any.
+func (c *fakeContainerProfiles) GetPending(ctx context.Context, containerProfileName string, options v1.GetOptions) (result *v1beta1.ContainerProfile, err error) {
+	emptyResult := &v1beta1.ContainerProfile{}
+	obj, err := c.Fake.
+		Invokes(testing.NewGetSubresourceActionWithOptions(c.Resource(), c.Namespace(), "pending", containerProfileName, options), emptyResult)
+
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.ContainerProfile), err
+}
+
+// QueryCallStacks takes the representation of a callStackQuery and creates it.  Returns the server's representation of the callStackQuery, and an error, if there is any.
+func (c *fakeContainerProfiles) QueryCallStacks(ctx context.Context, containerProfileName string, callStackQuery *v1beta1.CallStackQuery, opts v1.CreateOptions) (result *v1beta1.CallStackQuery, err error) {
+	emptyResult := &v1beta1.CallStackQuery{}
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateSubresourceActionWithOptions(c.Resource(), containerProfileName, "callstacks", c.Namespace(), callStackQuery, opts), emptyResult)
+
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.CallStackQuery), err
+}
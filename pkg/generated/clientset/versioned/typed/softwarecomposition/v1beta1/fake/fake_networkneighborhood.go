@@ -19,10 +19,14 @@ limitations under the License.
 package fake
 
 import (
+	context "context"
+
 	v1beta1 "github.com/kubescape/storage/pkg/apis/softwarecomposition/v1beta1"
 	softwarecompositionv1beta1 "github.com/kubescape/storage/pkg/generated/applyconfiguration/softwarecomposition/v1beta1"
 	typedsoftwarecompositionv1beta1 "github.com/kubescape/storage/pkg/generated/clientset/versioned/typed/softwarecomposition/v1beta1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	gentype "k8s.io/client-go/gentype"
+	testing "k8s.io/client-go/testing"
 )
 
 // fakeNetworkNeighborhoods implements NetworkNeighborhoodInterface
@@ -51,3 +55,15 @@ func newFakeNetworkNeighborhoods(fake *FakeSpdxV1beta1, namespace string) typeds
 		fake,
 	}
 }
+
+// Evaluate takes the representation of a profileEvaluation and creates it.  Returns the server's representation of the profileEvaluation, and an error, if there is any.
+func (c *fakeNetworkNeighborhoods) Evaluate(ctx context.Context, networkNeighborhoodName string, profileEvaluation *v1beta1.ProfileEvaluation, opts v1.CreateOptions) (result *v1beta1.ProfileEvaluation, err error) {
+	emptyResult := &v1beta1.ProfileEvaluation{}
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateSubresourceActionWithOptions(c.Resource(), networkNeighborhoodName, "evaluate", c.Namespace(), profileEvaluation, opts), emptyResult)
+
+	if obj == nil {
+		return emptyResult, err
+	}
+	return obj.(*v1beta1.ProfileEvaluation), err
+}
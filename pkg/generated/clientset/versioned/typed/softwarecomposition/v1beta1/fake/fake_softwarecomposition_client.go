@@ -48,6 +48,10 @@ func (c *FakeSpdxV1beta1) GeneratedNetworkPolicies(namespace string) v1beta1.Gen
 	return newFakeGeneratedNetworkPolicies(c, namespace)
 }
 
+func (c *FakeSpdxV1beta1) Guardrails() v1beta1.GuardrailInterface {
+	return newFakeGuardrails(c)
+}
+
 func (c *FakeSpdxV1beta1) KnownServers(namespace string) v1beta1.KnownServerInterface {
 	return newFakeKnownServers(c, namespace)
 }
@@ -28,6 +28,8 @@ type ContainerProfileExpansion interface{}
 
 type GeneratedNetworkPolicyExpansion interface{}
 
+type GuardrailExpansion interface{}
+
 type KnownServerExpansion interface{}
 
 type NetworkNeighborhoodExpansion interface{}
@@ -47,6 +47,8 @@ type NetworkNeighborhoodInterface interface {
 	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
 	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *softwarecompositionv1beta1.NetworkNeighborhood, err error)
 	Apply(ctx context.Context, networkNeighborhood *applyconfigurationsoftwarecompositionv1beta1.NetworkNeighborhoodApplyConfiguration, opts v1.ApplyOptions) (result *softwarecompositionv1beta1.NetworkNeighborhood, err error)
+	Evaluate(ctx context.Context, networkNeighborhoodName string, profileEvaluation *softwarecompositionv1beta1.ProfileEvaluation, opts v1.CreateOptions) (*softwarecompositionv1beta1.ProfileEvaluation, error)
+
 	NetworkNeighborhoodExpansion
 }
 
@@ -72,3 +74,18 @@ func newNetworkNeighborhoods(c *SpdxV1beta1Client, namespace string) *networkNei
 		),
 	}
 }
+
+// Evaluate takes the representation of a profileEvaluation and creates it.  Returns the server's representation of the profileEvaluation, and an error, if there is any.
+func (c *networkNeighborhoods) Evaluate(ctx context.Context, networkNeighborhoodName string, profileEvaluation *softwarecompositionv1beta1.ProfileEvaluation, opts v1.CreateOptions) (result *softwarecompositionv1beta1.ProfileEvaluation, err error) {
+	result = &softwarecompositionv1beta1.ProfileEvaluation{}
+	err = c.GetClient().Post().
+		Namespace(c.GetNamespace()).
+		Resource("networkneighborhoods").
+		Name(networkNeighborhoodName).
+		SubResource("evaluate").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(profileEvaluation).
+		Do(ctx).
+		Into(result)
+	return
+}
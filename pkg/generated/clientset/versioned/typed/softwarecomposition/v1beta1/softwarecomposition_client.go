@@ -33,6 +33,7 @@ type SpdxV1beta1Interface interface {
 	ConfigurationScanSummariesGetter
 	ContainerProfilesGetter
 	GeneratedNetworkPoliciesGetter
+	GuardrailsGetter
 	KnownServersGetter
 	NetworkNeighborhoodsGetter
 	OpenVulnerabilityExchangeContainersGetter
@@ -71,6 +72,10 @@ func (c *SpdxV1beta1Client) GeneratedNetworkPolicies(namespace string) Generated
 	return newGeneratedNetworkPolicies(c, namespace)
 }
 
+func (c *SpdxV1beta1Client) Guardrails() GuardrailInterface {
+	return newGuardrails(c)
+}
+
 func (c *SpdxV1beta1Client) KnownServers(namespace string) KnownServerInterface {
 	return newKnownServers(c, namespace)
 }
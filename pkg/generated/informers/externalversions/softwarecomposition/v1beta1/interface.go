@@ -34,6 +34,8 @@ type Interface interface {
 	ContainerProfiles() ContainerProfileInformer
 	// GeneratedNetworkPolicies returns a GeneratedNetworkPolicyInformer.
 	GeneratedNetworkPolicies() GeneratedNetworkPolicyInformer
+	// Guardrails returns a GuardrailInformer.
+	Guardrails() GuardrailInformer
 	// KnownServers returns a KnownServerInformer.
 	KnownServers() KnownServerInformer
 	// NetworkNeighborhoods returns a NetworkNeighborhoodInformer.
@@ -94,6 +96,11 @@ func (v *version) GeneratedNetworkPolicies() GeneratedNetworkPolicyInformer {
 	return &generatedNetworkPolicyInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// Guardrails returns a GuardrailInformer.
+func (v *version) Guardrails() GuardrailInformer {
+	return &guardrailInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
 // KnownServers returns a KnownServerInformer.
 func (v *version) KnownServers() KnownServerInformer {
 	return &knownServerInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
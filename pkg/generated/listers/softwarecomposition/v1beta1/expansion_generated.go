@@ -54,6 +54,10 @@ type GeneratedNetworkPolicyListerExpansion interface{}
 // GeneratedNetworkPolicyNamespaceLister.
 type GeneratedNetworkPolicyNamespaceListerExpansion interface{}
 
+// GuardrailListerExpansion allows custom methods to be added to
+// GuardrailLister.
+type GuardrailListerExpansion interface{}
+
 // KnownServerListerExpansion allows custom methods to be added to
 // KnownServerLister.
 type KnownServerListerExpansion interface{}
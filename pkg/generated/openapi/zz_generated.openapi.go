@@ -43,10 +43,16 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		v1beta1.CPE{}.OpenAPIModelName():                                        schema_pkg_apis_softwarecomposition_v1beta1_CPE(ref),
 		v1beta1.CallStack{}.OpenAPIModelName():                                  schema_pkg_apis_softwarecomposition_v1beta1_CallStack(ref),
 		v1beta1.CallStackNode{}.OpenAPIModelName():                              schema_pkg_apis_softwarecomposition_v1beta1_CallStackNode(ref),
+		v1beta1.CallStackQuery{}.OpenAPIModelName():                             schema_pkg_apis_softwarecomposition_v1beta1_CallStackQuery(ref),
+		v1beta1.CallStackQuerySpec{}.OpenAPIModelName():                         schema_pkg_apis_softwarecomposition_v1beta1_CallStackQuerySpec(ref),
+		v1beta1.CallStackQueryStatus{}.OpenAPIModelName():                       schema_pkg_apis_softwarecomposition_v1beta1_CallStackQueryStatus(ref),
+		v1beta1.CallStackReference{}.OpenAPIModelName():                         schema_pkg_apis_softwarecomposition_v1beta1_CallStackReference(ref),
 		v1beta1.CollapseConfigEntry{}.OpenAPIModelName():                        schema_pkg_apis_softwarecomposition_v1beta1_CollapseConfigEntry(ref),
 		v1beta1.CollapseConfiguration{}.OpenAPIModelName():                      schema_pkg_apis_softwarecomposition_v1beta1_CollapseConfiguration(ref),
 		v1beta1.CollapseConfigurationList{}.OpenAPIModelName():                  schema_pkg_apis_softwarecomposition_v1beta1_CollapseConfigurationList(ref),
 		v1beta1.CollapseConfigurationSpec{}.OpenAPIModelName():                  schema_pkg_apis_softwarecomposition_v1beta1_CollapseConfigurationSpec(ref),
+		v1beta1.CompiledContainerProfile{}.OpenAPIModelName():                   schema_pkg_apis_softwarecomposition_v1beta1_CompiledContainerProfile(ref),
+		v1beta1.CompiledContainerProfileSpec{}.OpenAPIModelName():               schema_pkg_apis_softwarecomposition_v1beta1_CompiledContainerProfileSpec(ref),
 		v1beta1.Component{}.OpenAPIModelName():                                  schema_pkg_apis_softwarecomposition_v1beta1_Component(ref),
 		v1beta1.Condition{}.OpenAPIModelName():                                  schema_pkg_apis_softwarecomposition_v1beta1_Condition(ref),
 		v1beta1.ConditionedStatus{}.OpenAPIModelName():                          schema_pkg_apis_softwarecomposition_v1beta1_ConditionedStatus(ref),
@@ -75,6 +81,12 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		v1beta1.GeneratedNetworkPolicyList{}.OpenAPIModelName():                 schema_pkg_apis_softwarecomposition_v1beta1_GeneratedNetworkPolicyList(ref),
 		v1beta1.GrypeDocument{}.OpenAPIModelName():                              schema_pkg_apis_softwarecomposition_v1beta1_GrypeDocument(ref),
 		v1beta1.GrypePackage{}.OpenAPIModelName():                               schema_pkg_apis_softwarecomposition_v1beta1_GrypePackage(ref),
+		v1beta1.Guardrail{}.OpenAPIModelName():                                  schema_pkg_apis_softwarecomposition_v1beta1_Guardrail(ref),
+		v1beta1.GuardrailList{}.OpenAPIModelName():                              schema_pkg_apis_softwarecomposition_v1beta1_GuardrailList(ref),
+		v1beta1.GuardrailRule{}.OpenAPIModelName():                              schema_pkg_apis_softwarecomposition_v1beta1_GuardrailRule(ref),
+		v1beta1.GuardrailSpec{}.OpenAPIModelName():                              schema_pkg_apis_softwarecomposition_v1beta1_GuardrailSpec(ref),
+		v1beta1.GuardrailStatus{}.OpenAPIModelName():                            schema_pkg_apis_softwarecomposition_v1beta1_GuardrailStatus(ref),
+		v1beta1.GuardrailViolation{}.OpenAPIModelName():                         schema_pkg_apis_softwarecomposition_v1beta1_GuardrailViolation(ref),
 		v1beta1.HTTPEndpoint{}.OpenAPIModelName():                               schema_pkg_apis_softwarecomposition_v1beta1_HTTPEndpoint(ref),
 		v1beta1.HTTPIngressPath{}.OpenAPIModelName():                            schema_pkg_apis_softwarecomposition_v1beta1_HTTPIngressPath(ref),
 		v1beta1.HTTPIngressRuleValue{}.OpenAPIModelName():                       schema_pkg_apis_softwarecomposition_v1beta1_HTTPIngressRuleValue(ref),
@@ -132,6 +144,11 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		v1beta1.PackageCustomData{}.OpenAPIModelName():                          schema_pkg_apis_softwarecomposition_v1beta1_PackageCustomData(ref),
 		v1beta1.PolicyRef{}.OpenAPIModelName():                                  schema_pkg_apis_softwarecomposition_v1beta1_PolicyRef(ref),
 		v1beta1.Product{}.OpenAPIModelName():                                    schema_pkg_apis_softwarecomposition_v1beta1_Product(ref),
+		v1beta1.ProfileEvaluation{}.OpenAPIModelName():                          schema_pkg_apis_softwarecomposition_v1beta1_ProfileEvaluation(ref),
+		v1beta1.ProfileEvaluationSpec{}.OpenAPIModelName():                      schema_pkg_apis_softwarecomposition_v1beta1_ProfileEvaluationSpec(ref),
+		v1beta1.ProfileEvaluationStatus{}.OpenAPIModelName():                    schema_pkg_apis_softwarecomposition_v1beta1_ProfileEvaluationStatus(ref),
+		v1beta1.ProfileObservation{}.OpenAPIModelName():                         schema_pkg_apis_softwarecomposition_v1beta1_ProfileObservation(ref),
+		v1beta1.ProfileObservationResult{}.OpenAPIModelName():                   schema_pkg_apis_softwarecomposition_v1beta1_ProfileObservationResult(ref),
 		v1beta1.ReportMeta{}.OpenAPIModelName():                                 schema_pkg_apis_softwarecomposition_v1beta1_ReportMeta(ref),
 		v1beta1.RulePath{}.OpenAPIModelName():                                   schema_pkg_apis_softwarecomposition_v1beta1_RulePath(ref),
 		v1beta1.RulePolicy{}.OpenAPIModelName():                                 schema_pkg_apis_softwarecomposition_v1beta1_RulePolicy(ref),
@@ -563,6 +580,10 @@ func schema_pkg_apis_softwarecomposition_v1beta1_ApplicationProfileSpec(ref comm
 					"containers": {
 						VendorExtensible: spec.VendorExtensible{
 							Extensions: spec.Extensions{
+								"x-kubernetes-list-map-keys": []interface{}{
+									"name",
+								},
+								"x-kubernetes-list-type":       "map",
 								"x-kubernetes-patch-merge-key": "name",
 								"x-kubernetes-patch-strategy":  "merge",
 							},
@@ -582,6 +603,10 @@ func schema_pkg_apis_softwarecomposition_v1beta1_ApplicationProfileSpec(ref comm
 					"initContainers": {
 						VendorExtensible: spec.VendorExtensible{
 							Extensions: spec.Extensions{
+								"x-kubernetes-list-map-keys": []interface{}{
+									"name",
+								},
+								"x-kubernetes-list-type":       "map",
 								"x-kubernetes-patch-merge-key": "name",
 								"x-kubernetes-patch-strategy":  "merge",
 							},
@@ -601,6 +626,10 @@ func schema_pkg_apis_softwarecomposition_v1beta1_ApplicationProfileSpec(ref comm
 					"ephemeralContainers": {
 						VendorExtensible: spec.VendorExtensible{
 							Extensions: spec.Extensions{
+								"x-kubernetes-list-map-keys": []interface{}{
+									"name",
+								},
+								"x-kubernetes-list-type":       "map",
 								"x-kubernetes-patch-merge-key": "name",
 								"x-kubernetes-patch-strategy":  "merge",
 							},
@@ -761,41 +790,11 @@ func schema_pkg_apis_softwarecomposition_v1beta1_CallStackNode(ref common.Refere
 	}
 }
 
-func schema_pkg_apis_softwarecomposition_v1beta1_CollapseConfigEntry(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Description: "CollapseConfigEntry is one per-prefix threshold override.",
-				Type:        []string{"object"},
-				Properties: map[string]spec.Schema{
-					"prefix": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Prefix is the path prefix to match (e.g. \"/etc\", \"/opt\").",
-							Default:     "",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-					"threshold": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Threshold is the maximum number of unique children allowed at any trie node under Prefix before that node collapses to a single dynamic identifier.",
-							Default:     0,
-							Type:        []string{"integer"},
-							Format:      "int32",
-						},
-					},
-				},
-				Required: []string{"prefix", "threshold"},
-			},
-		},
-	}
-}
-
-func schema_pkg_apis_softwarecomposition_v1beta1_CollapseConfiguration(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_softwarecomposition_v1beta1_CallStackQuery(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "CollapseConfiguration is a cluster-scoped resource carrying per-prefix thresholds for the dynamic-path-detector's open/endpoint collapse step. The storage server's deflate path reads the singleton (name \"default\") and feeds its entries into NewPathAnalyzerWithConfigs at runtime.\n\nReplace, not merge: when the singleton is present it REPLACES the compiled-in defaults wholesale — CollapseConfigs does not overlay the built-in /etc, /opt, /var/run (etc.) entries. List every prefix you want active, including any default you wish to keep. When the resource is absent the deflate path uses the compiled-in DefaultCollapseSettings.",
+				Description: "CallStackQuery is created on the callstacks subresource of an ApplicationProfile or a ContainerProfile to read its call stacks. It is not persisted: the response carries the unified call stack trees of the profile and the frames indexed across the profiles of its namespace.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
 					"kind": {
@@ -821,7 +820,13 @@ func schema_pkg_apis_softwarecomposition_v1beta1_CollapseConfiguration(ref commo
 					"spec": {
 						SchemaProps: spec.SchemaProps{
 							Default: map[string]interface{}{},
-							Ref:     ref(v1beta1.CollapseConfigurationSpec{}.OpenAPIModelName()),
+							Ref:     ref(v1beta1.CallStackQuerySpec{}.OpenAPIModelName()),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref(v1beta1.CallStackQueryStatus{}.OpenAPIModelName()),
 						},
 					},
 				},
@@ -829,253 +834,181 @@ func schema_pkg_apis_softwarecomposition_v1beta1_CollapseConfiguration(ref commo
 			},
 		},
 		Dependencies: []string{
-			v1beta1.CollapseConfigurationSpec{}.OpenAPIModelName(), v1.ObjectMeta{}.OpenAPIModelName()},
+			v1beta1.CallStackQuerySpec{}.OpenAPIModelName(), v1beta1.CallStackQueryStatus{}.OpenAPIModelName(), v1.ObjectMeta{}.OpenAPIModelName()},
 	}
 }
 
-func schema_pkg_apis_softwarecomposition_v1beta1_CollapseConfigurationList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_softwarecomposition_v1beta1_CallStackQuerySpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "CollapseConfigurationList is a list of CollapseConfiguration objects.",
+				Description: "CallStackQuerySpec selects the call stacks to return, at least one of CallID and Frame is set.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"kind": {
+					"containerName": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Description: "ContainerName selects the container of an ApplicationProfile, all containers are read when it is empty. It is ignored for a ContainerProfile.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"apiVersion": {
+					"callID": {
 						SchemaProps: spec.SchemaProps{
-							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Description: "CallID selects the call stacks leading to this CallID.",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"metadata": {
-						SchemaProps: spec.SchemaProps{
-							Default: map[string]interface{}{},
-							Ref:     ref(v1.ListMeta{}.OpenAPIModelName()),
-						},
-					},
-					"items": {
+					"frame": {
 						SchemaProps: spec.SchemaProps{
-							Type: []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Default: map[string]interface{}{},
-										Ref:     ref(v1beta1.CollapseConfiguration{}.OpenAPIModelName()),
-									},
-								},
-							},
+							Description: "Frame selects the call stacks going through this frame, FrameType is ignored.",
+							Ref:         ref(v1beta1.StackFrame{}.OpenAPIModelName()),
 						},
 					},
 				},
-				Required: []string{"items"},
 			},
 		},
 		Dependencies: []string{
-			v1beta1.CollapseConfiguration{}.OpenAPIModelName(), v1.ListMeta{}.OpenAPIModelName()},
+			v1beta1.StackFrame{}.OpenAPIModelName()},
 	}
 }
 
-func schema_pkg_apis_softwarecomposition_v1beta1_CollapseConfigurationSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_softwarecomposition_v1beta1_CallStackQueryStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "CollapseConfigurationSpec carries the cluster-wide collapse thresholds.",
-				Type:        []string{"object"},
+				Type: []string{"object"},
 				Properties: map[string]spec.Schema{
-					"openDynamicThreshold": {
-						SchemaProps: spec.SchemaProps{
-							Description: "OpenDynamicThreshold is the fallback threshold for AnalyzeOpens when no per-prefix entry matches the walked path. Optional: when omitted (decodes to 0) or explicitly set to 0, the deflate path uses the compiled-in default rather than a literal 0 — a 0 threshold would collapse every open to a single dynamic identifier. See CollapseSettingsFromCRD.",
-							Type:        []string{"integer"},
-							Format:      "int32",
-						},
-					},
-					"endpointDynamicThreshold": {
-						SchemaProps: spec.SchemaProps{
-							Description: "EndpointDynamicThreshold is the counterpart for AnalyzeEndpoints. Optional with the same omitted/0-means-compiled-default semantics as OpenDynamicThreshold.",
-							Type:        []string{"integer"},
-							Format:      "int32",
-						},
-					},
-					"collapseConfigs": {
+					"callStacks": {
 						VendorExtensible: spec.VendorExtensible{
 							Extensions: spec.Extensions{
-								"x-kubernetes-list-map-keys": []interface{}{
-									"prefix",
-								},
-								"x-kubernetes-list-type": "map",
+								"x-kubernetes-list-type": "atomic",
 							},
 						},
 						SchemaProps: spec.SchemaProps{
-							Description: "CollapseConfigs is the per-prefix threshold override list, evaluated longest-prefix-wins. Each entry is keyed by Prefix so server-side apply patches one entry at a time instead of replacing the slice.\n\nThis list REPLACES the compiled-in default prefixes wholesale; it is not merged with them. A CR with a single entry therefore drops the built-in /etc, /opt, /var/run (etc.) overrides — include them explicitly if you want them to remain in effect.",
+							Description: "CallStacks are the unified call stacks of the profile matching the spec, sorted by CallID.",
 							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
 										Default: map[string]interface{}{},
-										Ref:     ref(v1beta1.CollapseConfigEntry{}.OpenAPIModelName()),
+										Ref:     ref(v1beta1.IdentifiedCallStack{}.OpenAPIModelName()),
 									},
 								},
 							},
 						},
 					},
-				},
-			},
-		},
-		Dependencies: []string{
-			v1beta1.CollapseConfigEntry{}.OpenAPIModelName()},
-	}
-}
-
-func schema_pkg_apis_softwarecomposition_v1beta1_Component(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Type: []string{"object"},
-				Properties: map[string]spec.Schema{
-					"@id": {
-						SchemaProps: spec.SchemaProps{
-							Description: "ID is an IRI identifying the component. It is optional as the component can also be identified using hashes or software identifiers.",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
-					"hashes": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Hashes is a map of hashes to identify the component using cryptographic hashes.",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Default: "",
-										Type:    []string{"string"},
-										Format:  "",
-									},
-								},
+					"references": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "atomic",
 							},
 						},
-					},
-					"identifiers": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Identifiers is a list of software identifiers that describe the component.",
-							Type:        []string{"object"},
-							AdditionalProperties: &spec.SchemaOrBool{
-								Allows: true,
+							Description: "References are the frames of the profiles of the namespace matching the spec, one per profile, container, CallID and frame.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Default: "",
-										Type:    []string{"string"},
-										Format:  "",
+										Default: map[string]interface{}{},
+										Ref:     ref(v1beta1.CallStackReference{}.OpenAPIModelName()),
 									},
 								},
 							},
 						},
 					},
-					"supplier": {
-						SchemaProps: spec.SchemaProps{
-							Description: "Supplier is an optional machine-readable identifier for the supplier of the component. Valid examples include email address or IRIs.",
-							Type:        []string{"string"},
-							Format:      "",
-						},
-					},
 				},
 			},
 		},
+		Dependencies: []string{
+			v1beta1.CallStackReference{}.OpenAPIModelName(), v1beta1.IdentifiedCallStack{}.OpenAPIModelName()},
 	}
 }
 
-func schema_pkg_apis_softwarecomposition_v1beta1_Condition(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_softwarecomposition_v1beta1_CallStackReference(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Type: []string{"object"},
+				Description: "CallStackReference is a frame of the call stacks of a profile leading to a CallID.",
+				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"type": {
+					"kind": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Type of this condition. At most one of each condition type may apply to a resource at any point in time.",
-							Default:     "",
-							Type:        []string{"string"},
-							Format:      "",
+							Default: "",
+							Type:    []string{"string"},
+							Format:  "",
 						},
 					},
-					"status": {
+					"name": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Status of this condition; is it currently True, False, or Unknown?",
-							Default:     "",
-							Type:        []string{"string"},
-							Format:      "",
+							Default: "",
+							Type:    []string{"string"},
+							Format:  "",
 						},
 					},
-					"lastTransitionTime": {
+					"containerName": {
 						SchemaProps: spec.SchemaProps{
-							Description: "LastTransitionTime is the last time this condition transitioned from one status to another.",
-							Ref:         ref(v1.Time{}.OpenAPIModelName()),
+							Type:   []string{"string"},
+							Format: "",
 						},
 					},
-					"reason": {
+					"callID": {
 						SchemaProps: spec.SchemaProps{
-							Description: "A Reason for this condition's last transition from one status to another.",
-							Default:     "",
-							Type:        []string{"string"},
-							Format:      "",
+							Default: "",
+							Type:    []string{"string"},
+							Format:  "",
 						},
 					},
-					"message": {
+					"frame": {
 						SchemaProps: spec.SchemaProps{
-							Description: "A Message containing details about this condition's last transition from one status to another, if any.",
-							Type:        []string{"string"},
-							Format:      "",
+							Default: map[string]interface{}{},
+							Ref:     ref(v1beta1.StackFrame{}.OpenAPIModelName()),
 						},
 					},
 				},
-				Required: []string{"type", "status", "lastTransitionTime", "reason"},
+				Required: []string{"kind", "name", "callID", "frame"},
 			},
 		},
 		Dependencies: []string{
-			v1.Time{}.OpenAPIModelName()},
+			v1beta1.StackFrame{}.OpenAPIModelName()},
 	}
 }
 
-func schema_pkg_apis_softwarecomposition_v1beta1_ConditionedStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_softwarecomposition_v1beta1_CollapseConfigEntry(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Type: []string{"object"},
+				Description: "CollapseConfigEntry is one per-prefix threshold override.",
+				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"conditions": {
+					"prefix": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Conditions of the resource.",
-							Type:        []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Default: map[string]interface{}{},
-										Ref:     ref(v1beta1.Condition{}.OpenAPIModelName()),
-									},
-								},
-							},
+							Description: "Prefix is the path prefix to match (e.g. \"/etc\", \"/opt\").",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"threshold": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Threshold is the maximum number of unique children allowed at any trie node under Prefix before that node collapses to a single dynamic identifier.",
+							Default:     0,
+							Type:        []string{"integer"},
+							Format:      "int32",
 						},
 					},
 				},
+				Required: []string{"prefix", "threshold"},
 			},
 		},
-		Dependencies: []string{
-			v1beta1.Condition{}.OpenAPIModelName()},
 	}
 }
 
-func schema_pkg_apis_softwarecomposition_v1beta1_ConfigurationScanSummary(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_softwarecomposition_v1beta1_CollapseConfiguration(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "ConfigurationScanSummary is a summary for a group of WorkloadConfigurationScanSummary objects for a given scope (ex. namespace).",
+				Description: "CollapseConfiguration is a cluster-scoped resource carrying per-prefix thresholds for the dynamic-path-detector's open/endpoint collapse step. The storage server's deflate path reads the singleton (name \"default\") and feeds its entries into NewPathAnalyzerWithConfigs at runtime.\n\nReplace, not merge: when the singleton is present it REPLACES the compiled-in defaults wholesale — CollapseConfigs does not overlay the built-in /etc, /opt, /var/run (etc.) entries. List every prefix you want active, including any default you wish to keep. When the resource is absent the deflate path uses the compiled-in DefaultCollapseSettings.\n\nOther CollapseConfigurations are scoped by their NamespaceSelector and WorkloadSelector: a profile they select is collapsed with the most specific of them instead of \"default\", and the name of the configuration used is recorded in the kubescape.io/collapse-configuration annotation of the profile.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
 					"kind": {
@@ -1101,7 +1034,7 @@ func schema_pkg_apis_softwarecomposition_v1beta1_ConfigurationScanSummary(ref co
 					"spec": {
 						SchemaProps: spec.SchemaProps{
 							Default: map[string]interface{}{},
-							Ref:     ref(v1beta1.ConfigurationScanSummarySpec{}.OpenAPIModelName()),
+							Ref:     ref(v1beta1.CollapseConfigurationSpec{}.OpenAPIModelName()),
 						},
 					},
 				},
@@ -1109,15 +1042,15 @@ func schema_pkg_apis_softwarecomposition_v1beta1_ConfigurationScanSummary(ref co
 			},
 		},
 		Dependencies: []string{
-			v1beta1.ConfigurationScanSummarySpec{}.OpenAPIModelName(), v1.ObjectMeta{}.OpenAPIModelName()},
+			v1beta1.CollapseConfigurationSpec{}.OpenAPIModelName(), v1.ObjectMeta{}.OpenAPIModelName()},
 	}
 }
 
-func schema_pkg_apis_softwarecomposition_v1beta1_ConfigurationScanSummaryList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_softwarecomposition_v1beta1_CollapseConfigurationList(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "ConfigurationScanSummaryList is a list of ConfigurationScanSummary summaries.",
+				Description: "CollapseConfigurationList is a list of CollapseConfiguration objects.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
 					"kind": {
@@ -1147,7 +1080,7 @@ func schema_pkg_apis_softwarecomposition_v1beta1_ConfigurationScanSummaryList(re
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
 										Default: map[string]interface{}{},
-										Ref:     ref(v1beta1.ConfigurationScanSummary{}.OpenAPIModelName()),
+										Ref:     ref(v1beta1.CollapseConfiguration{}.OpenAPIModelName()),
 									},
 								},
 							},
@@ -1158,22 +1091,439 @@ func schema_pkg_apis_softwarecomposition_v1beta1_ConfigurationScanSummaryList(re
 			},
 		},
 		Dependencies: []string{
-			v1beta1.ConfigurationScanSummary{}.OpenAPIModelName(), v1.ListMeta{}.OpenAPIModelName()},
+			v1beta1.CollapseConfiguration{}.OpenAPIModelName(), v1.ListMeta{}.OpenAPIModelName()},
 	}
 }
 
-func schema_pkg_apis_softwarecomposition_v1beta1_ConfigurationScanSummarySpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_softwarecomposition_v1beta1_CollapseConfigurationSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Type: []string{"object"},
+				Description: "CollapseConfigurationSpec carries the collapse thresholds, cluster-wide for \"default\" and scoped by the selectors otherwise.",
+				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"severities": {
+					"openDynamicThreshold": {
 						SchemaProps: spec.SchemaProps{
-							Default: map[string]interface{}{},
-							Ref:     ref(v1beta1.WorkloadConfigurationScanSeveritiesSummary{}.OpenAPIModelName()),
-						},
-					},
+							Description: "OpenDynamicThreshold is the fallback threshold for AnalyzeOpens when no per-prefix entry matches the walked path. Optional: when omitted (decodes to 0) or explicitly set to 0, the deflate path uses the compiled-in default rather than a literal 0 — a 0 threshold would collapse every open to a single dynamic identifier. See CollapseSettingsFromCRD.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"endpointDynamicThreshold": {
+						SchemaProps: spec.SchemaProps{
+							Description: "EndpointDynamicThreshold is the counterpart for AnalyzeEndpoints. Optional with the same omitted/0-means-compiled-default semantics as OpenDynamicThreshold.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"collapseConfigs": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-map-keys": []interface{}{
+									"prefix",
+								},
+								"x-kubernetes-list-type": "map",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "CollapseConfigs is the per-prefix threshold override list, evaluated longest-prefix-wins. Each entry is keyed by Prefix so server-side apply patches one entry at a time instead of replacing the slice.\n\nThis list REPLACES the compiled-in default prefixes wholesale; it is not merged with them. A CR with a single entry therefore drops the built-in /etc, /opt, /var/run (etc.) overrides — include them explicitly if you want them to remain in effect.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref(v1beta1.CollapseConfigEntry{}.OpenAPIModelName()),
+									},
+								},
+							},
+						},
+					},
+					"ipCollapseThreshold": {
+						SchemaProps: spec.SchemaProps{
+							Description: "IPCollapseThreshold is the number of addresses of a NetworkNeighborhood which may fall in one /24 (IPv4) or /64 (IPv6) before they fold into that CIDR. Optional with the same omitted/0-means-compiled-default semantics as OpenDynamicThreshold.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"dnsCollapseThreshold": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DNSCollapseThreshold is the number of sibling labels which may appear under one DNS suffix before they fold into a \"*.<suffix>\" wildcard. Optional with the same omitted/0-means-compiled-default semantics as OpenDynamicThreshold.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"execArgsCollapseThreshold": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ExecArgsCollapseThreshold is the number of distinct values one argv position of an exec path may take before it collapses to \"⋯\"; argv of varying length collapses to a common prefix followed by \"⋯⋯\" under the same threshold. Optional with the same omitted/0-means-compiled-default semantics as OpenDynamicThreshold.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"envCollapseThreshold": {
+						SchemaProps: spec.SchemaProps{
+							Description: "EnvCollapseThreshold is the number of distinct values one environment variable of an exec path may take before only its name is kept. Optional with the same omitted/0-means-compiled-default semantics as OpenDynamicThreshold.",
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"namespaceSelector": {
+						SchemaProps: spec.SchemaProps{
+							Description: "NamespaceSelector scopes a CollapseConfiguration other than \"default\" to the profiles of the namespaces it selects; an empty selector selects all namespaces. Required on every configuration but \"default\", on which it must not be set: \"default\" applies to the profiles no scoped configuration selects.",
+							Ref:         ref(v1.LabelSelector{}.OpenAPIModelName()),
+						},
+					},
+					"workloadSelector": {
+						SchemaProps: spec.SchemaProps{
+							Description: "WorkloadSelector further scopes a configuration to the profiles whose labels it selects, e.g. kubescape.io/workload-name. When several scoped configurations select a profile, the most specific one wins: one with a WorkloadSelector over one without, then the one with the most selector requirements, then the first by name.",
+							Ref:         ref(v1.LabelSelector{}.OpenAPIModelName()),
+						},
+					},
+					"headerAllowlist": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "set",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "HeaderAllowlist is the names of the headers kept in the profile of an HTTP endpoint, matched case-insensitively, the others are dropped. Optional: when omitted or empty the compiled-in allowlist applies. The values of credential headers such as Authorization are never kept, only their names.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			v1beta1.CollapseConfigEntry{}.OpenAPIModelName(), v1.LabelSelector{}.OpenAPIModelName()},
+	}
+}
+
+func schema_pkg_apis_softwarecomposition_v1beta1_CompiledContainerProfile(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "CompiledContainerProfile is served on the compiled subresource of a ContainerProfile: the effective profile, with its overlay applied, compiled into the pre-indexed form of the compiledprofile package. It is produced during consolidation and rebuilt on read when stale.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref(v1.ObjectMeta{}.OpenAPIModelName()),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref(v1beta1.CompiledContainerProfileSpec{}.OpenAPIModelName()),
+						},
+					},
+				},
+				Required: []string{"spec"},
+			},
+		},
+		Dependencies: []string{
+			v1beta1.CompiledContainerProfileSpec{}.OpenAPIModelName(), v1.ObjectMeta{}.OpenAPIModelName()},
+	}
+}
+
+func schema_pkg_apis_softwarecomposition_v1beta1_CompiledContainerProfileSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"formatVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "FormatVersion is the compiledprofile.FormatVersion Data is encoded with.",
+							Default:     0,
+							Type:        []string{"integer"},
+							Format:      "int32",
+						},
+					},
+					"sourceResourceVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "SourceResourceVersion is the resourceVersion of the profile Data was compiled from.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"data": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Data is the compiledprofile.Encode encoding of the profile, decode it with compiledprofile.Decode.",
+							Type:        []string{"string"},
+							Format:      "byte",
+						},
+					},
+				},
+				Required: []string{"formatVersion", "data"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_softwarecomposition_v1beta1_Component(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"@id": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ID is an IRI identifying the component. It is optional as the component can also be identified using hashes or software identifiers.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"hashes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Hashes is a map of hashes to identify the component using cryptographic hashes.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"identifiers": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Identifiers is a list of software identifiers that describe the component.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"supplier": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Supplier is an optional machine-readable identifier for the supplier of the component. Valid examples include email address or IRIs.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_softwarecomposition_v1beta1_Condition(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"type": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Type of this condition. At most one of each condition type may apply to a resource at any point in time.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Status of this condition; is it currently True, False, or Unknown?",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"lastTransitionTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "LastTransitionTime is the last time this condition transitioned from one status to another.",
+							Ref:         ref(v1.Time{}.OpenAPIModelName()),
+						},
+					},
+					"reason": {
+						SchemaProps: spec.SchemaProps{
+							Description: "A Reason for this condition's last transition from one status to another.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "A Message containing details about this condition's last transition from one status to another, if any.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"type", "status", "lastTransitionTime", "reason"},
+			},
+		},
+		Dependencies: []string{
+			v1.Time{}.OpenAPIModelName()},
+	}
+}
+
+func schema_pkg_apis_softwarecomposition_v1beta1_ConditionedStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"conditions": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Conditions of the resource.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref(v1beta1.Condition{}.OpenAPIModelName()),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			v1beta1.Condition{}.OpenAPIModelName()},
+	}
+}
+
+func schema_pkg_apis_softwarecomposition_v1beta1_ConfigurationScanSummary(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ConfigurationScanSummary is a summary for a group of WorkloadConfigurationScanSummary objects for a given scope (ex. namespace).",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref(v1.ObjectMeta{}.OpenAPIModelName()),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref(v1beta1.ConfigurationScanSummarySpec{}.OpenAPIModelName()),
+						},
+					},
+				},
+				Required: []string{"spec"},
+			},
+		},
+		Dependencies: []string{
+			v1beta1.ConfigurationScanSummarySpec{}.OpenAPIModelName(), v1.ObjectMeta{}.OpenAPIModelName()},
+	}
+}
+
+func schema_pkg_apis_softwarecomposition_v1beta1_ConfigurationScanSummaryList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ConfigurationScanSummaryList is a list of ConfigurationScanSummary summaries.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref(v1.ListMeta{}.OpenAPIModelName()),
+						},
+					},
+					"items": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref(v1beta1.ConfigurationScanSummary{}.OpenAPIModelName()),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"items"},
+			},
+		},
+		Dependencies: []string{
+			v1beta1.ConfigurationScanSummary{}.OpenAPIModelName(), v1.ListMeta{}.OpenAPIModelName()},
+	}
+}
+
+func schema_pkg_apis_softwarecomposition_v1beta1_ConfigurationScanSummarySpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"severities": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref(v1beta1.WorkloadConfigurationScanSeveritiesSummary{}.OpenAPIModelName()),
+						},
+					},
 					"summaryRef": {
 						SchemaProps: spec.SchemaProps{
 							Type: []string{"array"},
@@ -1485,6 +1835,16 @@ func schema_pkg_apis_softwarecomposition_v1beta1_ContainerProfileSpec(ref common
 						},
 					},
 					"ingress": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-map-keys": []interface{}{
+									"identifier",
+								},
+								"x-kubernetes-list-type":       "map",
+								"x-kubernetes-patch-merge-key": "identifier",
+								"x-kubernetes-patch-strategy":  "merge",
+							},
+						},
 						SchemaProps: spec.SchemaProps{
 							Type: []string{"array"},
 							Items: &spec.SchemaOrArray{
@@ -1498,6 +1858,16 @@ func schema_pkg_apis_softwarecomposition_v1beta1_ContainerProfileSpec(ref common
 						},
 					},
 					"egress": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-map-keys": []interface{}{
+									"identifier",
+								},
+								"x-kubernetes-list-type":       "map",
+								"x-kubernetes-patch-merge-key": "identifier",
+								"x-kubernetes-patch-strategy":  "merge",
+							},
+						},
 						SchemaProps: spec.SchemaProps{
 							Type: []string{"array"},
 							Items: &spec.SchemaOrArray{
@@ -1892,205 +2262,457 @@ func schema_pkg_apis_softwarecomposition_v1beta1_ExecCalls(ref common.ReferenceC
 	}
 }
 
-func schema_pkg_apis_softwarecomposition_v1beta1_Executable(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_softwarecomposition_v1beta1_Executable(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"format": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Format denotes either ELF, Mach-O, or PE",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"hasExports": {
+						SchemaProps: spec.SchemaProps{
+							Default: false,
+							Type:    []string{"boolean"},
+							Format:  "",
+						},
+					},
+					"hasEntrypoint": {
+						SchemaProps: spec.SchemaProps{
+							Default: false,
+							Type:    []string{"boolean"},
+							Format:  "",
+						},
+					},
+					"importedLibraries": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"elfSecurityFeatures": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref(v1beta1.ELFSecurityFeatures{}.OpenAPIModelName()),
+						},
+					},
+				},
+				Required: []string{"format", "hasExports", "hasEntrypoint", "importedLibraries"},
+			},
+		},
+		Dependencies: []string{
+			v1beta1.ELFSecurityFeatures{}.OpenAPIModelName()},
+	}
+}
+
+func schema_pkg_apis_softwarecomposition_v1beta1_FileLicense(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"value": {
+						SchemaProps: spec.SchemaProps{
+							Default: "",
+							Type:    []string{"string"},
+							Format:  "",
+						},
+					},
+					"spdxExpression": {
+						SchemaProps: spec.SchemaProps{
+							Default: "",
+							Type:    []string{"string"},
+							Format:  "",
+						},
+					},
+					"type": {
+						SchemaProps: spec.SchemaProps{
+							Default: "",
+							Type:    []string{"string"},
+							Format:  "",
+						},
+					},
+					"evidence": {
+						SchemaProps: spec.SchemaProps{
+							Ref: ref(v1beta1.FileLicenseEvidence{}.OpenAPIModelName()),
+						},
+					},
+				},
+				Required: []string{"value", "spdxExpression", "type"},
+			},
+		},
+		Dependencies: []string{
+			v1beta1.FileLicenseEvidence{}.OpenAPIModelName()},
+	}
+}
+
+func schema_pkg_apis_softwarecomposition_v1beta1_FileLicenseEvidence(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"confidence": {
+						SchemaProps: spec.SchemaProps{
+							Default: 0,
+							Type:    []string{"integer"},
+							Format:  "int64",
+						},
+					},
+					"offset": {
+						SchemaProps: spec.SchemaProps{
+							Default: 0,
+							Type:    []string{"integer"},
+							Format:  "int64",
+						},
+					},
+					"extent": {
+						SchemaProps: spec.SchemaProps{
+							Default: 0,
+							Type:    []string{"integer"},
+							Format:  "int64",
+						},
+					},
+				},
+				Required: []string{"confidence", "offset", "extent"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_softwarecomposition_v1beta1_FileMetadataEntry(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"mode": {
+						SchemaProps: spec.SchemaProps{
+							Default: 0,
+							Type:    []string{"integer"},
+							Format:  "int64",
+						},
+					},
+					"type": {
+						SchemaProps: spec.SchemaProps{
+							Default: "",
+							Type:    []string{"string"},
+							Format:  "",
+						},
+					},
+					"linkDestination": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"userID": {
+						SchemaProps: spec.SchemaProps{
+							Default: 0,
+							Type:    []string{"integer"},
+							Format:  "int64",
+						},
+					},
+					"groupID": {
+						SchemaProps: spec.SchemaProps{
+							Default: 0,
+							Type:    []string{"integer"},
+							Format:  "int64",
+						},
+					},
+					"mimeType": {
+						SchemaProps: spec.SchemaProps{
+							Default: "",
+							Type:    []string{"string"},
+							Format:  "",
+						},
+					},
+					"size": {
+						SchemaProps: spec.SchemaProps{
+							Default: 0,
+							Type:    []string{"integer"},
+							Format:  "int64",
+						},
+					},
+				},
+				Required: []string{"mode", "type", "userID", "groupID", "mimeType", "size"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_softwarecomposition_v1beta1_Fix(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Type: []string{"object"},
+				Properties: map[string]spec.Schema{
+					"versions": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"state": {
+						SchemaProps: spec.SchemaProps{
+							Default: "",
+							Type:    []string{"string"},
+							Format:  "",
+						},
+					},
+				},
+				Required: []string{"versions", "state"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_softwarecomposition_v1beta1_GeneratedNetworkPolicy(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Type: []string{"object"},
+				Description: "GeneratedNetworkPolicy represents a generated NetworkPolicy.",
+				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"format": {
+					"kind": {
 						SchemaProps: spec.SchemaProps{
-							Description: "Format denotes either ELF, Mach-O, or PE",
-							Default:     "",
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
 							Type:        []string{"string"},
 							Format:      "",
 						},
 					},
-					"hasExports": {
+					"apiVersion": {
 						SchemaProps: spec.SchemaProps{
-							Default: false,
-							Type:    []string{"boolean"},
-							Format:  "",
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"hasEntrypoint": {
+					"metadata": {
 						SchemaProps: spec.SchemaProps{
-							Default: false,
-							Type:    []string{"boolean"},
-							Format:  "",
+							Default: map[string]interface{}{},
+							Ref:     ref(v1.ObjectMeta{}.OpenAPIModelName()),
 						},
 					},
-					"importedLibraries": {
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref(v1beta1.NetworkPolicy{}.OpenAPIModelName()),
+						},
+					},
+					"policyRef": {
 						SchemaProps: spec.SchemaProps{
 							Type: []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Default: "",
-										Type:    []string{"string"},
-										Format:  "",
+										Default: map[string]interface{}{},
+										Ref:     ref(v1beta1.PolicyRef{}.OpenAPIModelName()),
 									},
 								},
 							},
 						},
 					},
-					"elfSecurityFeatures": {
-						SchemaProps: spec.SchemaProps{
-							Ref: ref(v1beta1.ELFSecurityFeatures{}.OpenAPIModelName()),
-						},
-					},
 				},
-				Required: []string{"format", "hasExports", "hasEntrypoint", "importedLibraries"},
+				Required: []string{"spec"},
 			},
 		},
 		Dependencies: []string{
-			v1beta1.ELFSecurityFeatures{}.OpenAPIModelName()},
+			v1beta1.NetworkPolicy{}.OpenAPIModelName(), v1beta1.PolicyRef{}.OpenAPIModelName(), v1.ObjectMeta{}.OpenAPIModelName()},
 	}
 }
 
-func schema_pkg_apis_softwarecomposition_v1beta1_FileLicense(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_softwarecomposition_v1beta1_GeneratedNetworkPolicyList(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Type: []string{"object"},
+				Description: "GeneratedNetworkPolicyList is a list of GeneratedNetworkPolicies.",
+				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"value": {
+					"kind": {
 						SchemaProps: spec.SchemaProps{
-							Default: "",
-							Type:    []string{"string"},
-							Format:  "",
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"spdxExpression": {
+					"apiVersion": {
 						SchemaProps: spec.SchemaProps{
-							Default: "",
-							Type:    []string{"string"},
-							Format:  "",
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"type": {
+					"metadata": {
 						SchemaProps: spec.SchemaProps{
-							Default: "",
-							Type:    []string{"string"},
-							Format:  "",
+							Default: map[string]interface{}{},
+							Ref:     ref(v1.ListMeta{}.OpenAPIModelName()),
 						},
 					},
-					"evidence": {
+					"items": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref(v1beta1.FileLicenseEvidence{}.OpenAPIModelName()),
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref(v1beta1.GeneratedNetworkPolicy{}.OpenAPIModelName()),
+									},
+								},
+							},
 						},
 					},
 				},
-				Required: []string{"value", "spdxExpression", "type"},
+				Required: []string{"items"},
 			},
 		},
 		Dependencies: []string{
-			v1beta1.FileLicenseEvidence{}.OpenAPIModelName()},
+			v1beta1.GeneratedNetworkPolicy{}.OpenAPIModelName(), v1.ListMeta{}.OpenAPIModelName()},
 	}
 }
 
-func schema_pkg_apis_softwarecomposition_v1beta1_FileLicenseEvidence(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_softwarecomposition_v1beta1_GrypeDocument(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Type: []string{"object"},
+				Description: "GrypeDocument is the document that represents the vulnerability manifest in the Grype’s JSON format",
+				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"confidence": {
+					"matches": {
 						SchemaProps: spec.SchemaProps{
-							Default: 0,
-							Type:    []string{"integer"},
-							Format:  "int64",
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref(v1beta1.Match{}.OpenAPIModelName()),
+									},
+								},
+							},
 						},
 					},
-					"offset": {
+					"ignoredMatches": {
 						SchemaProps: spec.SchemaProps{
-							Default: 0,
-							Type:    []string{"integer"},
-							Format:  "int64",
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref(v1beta1.IgnoredMatch{}.OpenAPIModelName()),
+									},
+								},
+							},
 						},
 					},
-					"extent": {
+					"source": {
 						SchemaProps: spec.SchemaProps{
-							Default: 0,
-							Type:    []string{"integer"},
-							Format:  "int64",
+							Ref: ref(v1beta1.Source{}.OpenAPIModelName()),
+						},
+					},
+					"distro": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref(v1beta1.Distribution{}.OpenAPIModelName()),
+						},
+					},
+					"descriptor": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref(v1beta1.Descriptor{}.OpenAPIModelName()),
 						},
 					},
 				},
-				Required: []string{"confidence", "offset", "extent"},
+				Required: []string{"matches", "source", "distro", "descriptor"},
 			},
 		},
+		Dependencies: []string{
+			v1beta1.Descriptor{}.OpenAPIModelName(), v1beta1.Distribution{}.OpenAPIModelName(), v1beta1.IgnoredMatch{}.OpenAPIModelName(), v1beta1.Match{}.OpenAPIModelName(), v1beta1.Source{}.OpenAPIModelName()},
 	}
 }
 
-func schema_pkg_apis_softwarecomposition_v1beta1_FileMetadataEntry(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_softwarecomposition_v1beta1_GrypePackage(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
 				Type: []string{"object"},
 				Properties: map[string]spec.Schema{
-					"mode": {
-						SchemaProps: spec.SchemaProps{
-							Default: 0,
-							Type:    []string{"integer"},
-							Format:  "int64",
-						},
-					},
-					"type": {
+					"name": {
 						SchemaProps: spec.SchemaProps{
 							Default: "",
 							Type:    []string{"string"},
 							Format:  "",
 						},
 					},
-					"linkDestination": {
+					"version": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Default: "",
+							Type:    []string{"string"},
+							Format:  "",
 						},
 					},
-					"userID": {
+					"type": {
 						SchemaProps: spec.SchemaProps{
-							Default: 0,
-							Type:    []string{"integer"},
-							Format:  "int64",
+							Default: "",
+							Type:    []string{"string"},
+							Format:  "",
 						},
 					},
-					"groupID": {
+					"locations": {
 						SchemaProps: spec.SchemaProps{
-							Default: 0,
-							Type:    []string{"integer"},
-							Format:  "int64",
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref(v1beta1.SyftCoordinates{}.OpenAPIModelName()),
+									},
+								},
+							},
 						},
 					},
-					"mimeType": {
+					"language": {
 						SchemaProps: spec.SchemaProps{
 							Default: "",
 							Type:    []string{"string"},
 							Format:  "",
 						},
 					},
-					"size": {
+					"licenses": {
 						SchemaProps: spec.SchemaProps{
-							Default: 0,
-							Type:    []string{"integer"},
-							Format:  "int64",
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
 						},
 					},
-				},
-				Required: []string{"mode", "type", "userID", "groupID", "mimeType", "size"},
-			},
-		},
-	}
-}
-
-func schema_pkg_apis_softwarecomposition_v1beta1_Fix(ref common.ReferenceCallback) common.OpenAPIDefinition {
-	return common.OpenAPIDefinition{
-		Schema: spec.Schema{
-			SchemaProps: spec.SchemaProps{
-				Type: []string{"object"},
-				Properties: map[string]spec.Schema{
-					"versions": {
+					"cpes": {
 						SchemaProps: spec.SchemaProps{
 							Type: []string{"array"},
 							Items: &spec.SchemaOrArray{
@@ -2104,25 +2726,52 @@ func schema_pkg_apis_softwarecomposition_v1beta1_Fix(ref common.ReferenceCallbac
 							},
 						},
 					},
-					"state": {
+					"purl": {
 						SchemaProps: spec.SchemaProps{
 							Default: "",
 							Type:    []string{"string"},
 							Format:  "",
 						},
 					},
+					"upstreams": {
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref(v1beta1.UpstreamPackage{}.OpenAPIModelName()),
+									},
+								},
+							},
+						},
+					},
+					"metadataType": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "byte",
+						},
+					},
 				},
-				Required: []string{"versions", "state"},
+				Required: []string{"name", "version", "type", "locations", "language", "licenses", "cpes", "purl", "upstreams"},
 			},
 		},
+		Dependencies: []string{
+			v1beta1.SyftCoordinates{}.OpenAPIModelName(), v1beta1.UpstreamPackage{}.OpenAPIModelName()},
 	}
 }
 
-func schema_pkg_apis_softwarecomposition_v1beta1_GeneratedNetworkPolicy(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_softwarecomposition_v1beta1_Guardrail(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "GeneratedNetworkPolicy represents a generated NetworkPolicy.",
+				Description: "Guardrail is a cluster-scoped resource carrying organization rules that ApplicationProfile, NetworkNeighborhood and ContainerProfile objects are evaluated against, e.g. \"no container may exec a shell\".\n\nEvery rule is a CEL expression which must evaluate to true for a profile to comply. Violations are recorded in the kubescape.io/guardrail-violations annotation of the profile and listed in the Guardrail status.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
 					"kind": {
@@ -2148,20 +2797,13 @@ func schema_pkg_apis_softwarecomposition_v1beta1_GeneratedNetworkPolicy(ref comm
 					"spec": {
 						SchemaProps: spec.SchemaProps{
 							Default: map[string]interface{}{},
-							Ref:     ref(v1beta1.NetworkPolicy{}.OpenAPIModelName()),
+							Ref:     ref(v1beta1.GuardrailSpec{}.OpenAPIModelName()),
 						},
 					},
-					"policyRef": {
+					"status": {
 						SchemaProps: spec.SchemaProps{
-							Type: []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Default: map[string]interface{}{},
-										Ref:     ref(v1beta1.PolicyRef{}.OpenAPIModelName()),
-									},
-								},
-							},
+							Default: map[string]interface{}{},
+							Ref:     ref(v1beta1.GuardrailStatus{}.OpenAPIModelName()),
 						},
 					},
 				},
@@ -2169,15 +2811,15 @@ func schema_pkg_apis_softwarecomposition_v1beta1_GeneratedNetworkPolicy(ref comm
 			},
 		},
 		Dependencies: []string{
-			v1beta1.NetworkPolicy{}.OpenAPIModelName(), v1beta1.PolicyRef{}.OpenAPIModelName(), v1.ObjectMeta{}.OpenAPIModelName()},
+			v1beta1.GuardrailSpec{}.OpenAPIModelName(), v1beta1.GuardrailStatus{}.OpenAPIModelName(), v1.ObjectMeta{}.OpenAPIModelName()},
 	}
 }
 
-func schema_pkg_apis_softwarecomposition_v1beta1_GeneratedNetworkPolicyList(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_softwarecomposition_v1beta1_GuardrailList(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "GeneratedNetworkPolicyList is a list of GeneratedNetworkPolicies.",
+				Description: "GuardrailList is a list of Guardrail objects.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
 					"kind": {
@@ -2207,7 +2849,7 @@ func schema_pkg_apis_softwarecomposition_v1beta1_GeneratedNetworkPolicyList(ref
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
 										Default: map[string]interface{}{},
-										Ref:     ref(v1beta1.GeneratedNetworkPolicy{}.OpenAPIModelName()),
+										Ref:     ref(v1beta1.Guardrail{}.OpenAPIModelName()),
 									},
 								},
 							},
@@ -2218,182 +2860,178 @@ func schema_pkg_apis_softwarecomposition_v1beta1_GeneratedNetworkPolicyList(ref
 			},
 		},
 		Dependencies: []string{
-			v1beta1.GeneratedNetworkPolicy{}.OpenAPIModelName(), v1.ListMeta{}.OpenAPIModelName()},
+			v1beta1.Guardrail{}.OpenAPIModelName(), v1.ListMeta{}.OpenAPIModelName()},
 	}
 }
 
-func schema_pkg_apis_softwarecomposition_v1beta1_GrypeDocument(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_softwarecomposition_v1beta1_GuardrailRule(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Description: "GrypeDocument is the document that represents the vulnerability manifest in the Grype’s JSON format",
+				Description: "GuardrailRule is one CEL rule of a Guardrail.",
 				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"matches": {
-						SchemaProps: spec.SchemaProps{
-							Type: []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Default: map[string]interface{}{},
-										Ref:     ref(v1beta1.Match{}.OpenAPIModelName()),
-									},
-								},
-							},
-						},
-					},
-					"ignoredMatches": {
-						SchemaProps: spec.SchemaProps{
-							Type: []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Default: map[string]interface{}{},
-										Ref:     ref(v1beta1.IgnoredMatch{}.OpenAPIModelName()),
-									},
-								},
-							},
-						},
-					},
-					"source": {
+					"name": {
 						SchemaProps: spec.SchemaProps{
-							Ref: ref(v1beta1.Source{}.OpenAPIModelName()),
+							Description: "Name identifies the rule in violations.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"distro": {
+					"expression": {
 						SchemaProps: spec.SchemaProps{
-							Default: map[string]interface{}{},
-							Ref:     ref(v1beta1.Distribution{}.OpenAPIModelName()),
+							Description: "Expression is a CEL expression which must evaluate to true for the profile to comply. It can reference `object` (the profile, as served by this API) and `kind`, e.g. `object.spec.containers.all(c, c.execs.all(e, e.path != '/bin/sh'))`.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
-					"descriptor": {
+					"message": {
 						SchemaProps: spec.SchemaProps{
-							Default: map[string]interface{}{},
-							Ref:     ref(v1beta1.Descriptor{}.OpenAPIModelName()),
+							Description: "Message is reported along with violations of the rule.",
+							Type:        []string{"string"},
+							Format:      "",
 						},
 					},
 				},
-				Required: []string{"matches", "source", "distro", "descriptor"},
+				Required: []string{"name", "expression"},
 			},
 		},
-		Dependencies: []string{
-			v1beta1.Descriptor{}.OpenAPIModelName(), v1beta1.Distribution{}.OpenAPIModelName(), v1beta1.IgnoredMatch{}.OpenAPIModelName(), v1beta1.Match{}.OpenAPIModelName(), v1beta1.Source{}.OpenAPIModelName()},
 	}
 }
 
-func schema_pkg_apis_softwarecomposition_v1beta1_GrypePackage(ref common.ReferenceCallback) common.OpenAPIDefinition {
+func schema_pkg_apis_softwarecomposition_v1beta1_GuardrailSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
 			SchemaProps: spec.SchemaProps{
-				Type: []string{"object"},
+				Description: "GuardrailSpec carries the rules of a Guardrail.",
+				Type:        []string{"object"},
 				Properties: map[string]spec.Schema{
-					"name": {
-						SchemaProps: spec.SchemaProps{
-							Default: "",
-							Type:    []string{"string"},
-							Format:  "",
-						},
-					},
-					"version": {
-						SchemaProps: spec.SchemaProps{
-							Default: "",
-							Type:    []string{"string"},
-							Format:  "",
-						},
-					},
-					"type": {
-						SchemaProps: spec.SchemaProps{
-							Default: "",
-							Type:    []string{"string"},
-							Format:  "",
+					"matchKinds": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "set",
+							},
 						},
-					},
-					"locations": {
 						SchemaProps: spec.SchemaProps{
-							Type: []string{"array"},
+							Description: "MatchKinds restricts the guardrail to the listed profile kinds (ApplicationProfile, NetworkNeighborhood, ContainerProfile). Empty matches all of them.",
+							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Default: map[string]interface{}{},
-										Ref:     ref(v1beta1.SyftCoordinates{}.OpenAPIModelName()),
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
 									},
 								},
 							},
 						},
 					},
-					"language": {
-						SchemaProps: spec.SchemaProps{
-							Default: "",
-							Type:    []string{"string"},
-							Format:  "",
+					"rules": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-map-keys": []interface{}{
+									"name",
+								},
+								"x-kubernetes-list-type": "map",
+							},
 						},
-					},
-					"licenses": {
 						SchemaProps: spec.SchemaProps{
-							Type: []string{"array"},
+							Description: "Rules are evaluated independently, each failing rule is one violation.",
+							Type:        []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Default: "",
-										Type:    []string{"string"},
-										Format:  "",
+										Default: map[string]interface{}{},
+										Ref:     ref(v1beta1.GuardrailRule{}.OpenAPIModelName()),
 									},
 								},
 							},
 						},
 					},
-					"cpes": {
+				},
+				Required: []string{"rules"},
+			},
+		},
+		Dependencies: []string{
+			v1beta1.GuardrailRule{}.OpenAPIModelName()},
+	}
+}
+
+func schema_pkg_apis_softwarecomposition_v1beta1_GuardrailStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "GuardrailStatus lists the profiles currently violating a Guardrail.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"violations": {
 						SchemaProps: spec.SchemaProps{
 							Type: []string{"array"},
 							Items: &spec.SchemaOrArray{
 								Schema: &spec.Schema{
 									SchemaProps: spec.SchemaProps{
-										Default: "",
-										Type:    []string{"string"},
-										Format:  "",
+										Default: map[string]interface{}{},
+										Ref:     ref(v1beta1.GuardrailViolation{}.OpenAPIModelName()),
 									},
 								},
 							},
 						},
 					},
-					"purl": {
+				},
+			},
+		},
+		Dependencies: []string{
+			v1beta1.GuardrailViolation{}.OpenAPIModelName()},
+	}
+}
+
+func schema_pkg_apis_softwarecomposition_v1beta1_GuardrailViolation(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "GuardrailViolation is a profile violating a rule of a Guardrail.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
 						SchemaProps: spec.SchemaProps{
 							Default: "",
 							Type:    []string{"string"},
 							Format:  "",
 						},
 					},
-					"upstreams": {
+					"namespace": {
 						SchemaProps: spec.SchemaProps{
-							Type: []string{"array"},
-							Items: &spec.SchemaOrArray{
-								Schema: &spec.Schema{
-									SchemaProps: spec.SchemaProps{
-										Default: map[string]interface{}{},
-										Ref:     ref(v1beta1.UpstreamPackage{}.OpenAPIModelName()),
-									},
-								},
-							},
+							Default: "",
+							Type:    []string{"string"},
+							Format:  "",
 						},
 					},
-					"metadataType": {
+					"name": {
 						SchemaProps: spec.SchemaProps{
-							Type:   []string{"string"},
-							Format: "",
+							Default: "",
+							Type:    []string{"string"},
+							Format:  "",
 						},
 					},
-					"metadata": {
+					"rule": {
+						SchemaProps: spec.SchemaProps{
+							Default: "",
+							Type:    []string{"string"},
+							Format:  "",
+						},
+					},
+					"message": {
 						SchemaProps: spec.SchemaProps{
 							Type:   []string{"string"},
-							Format: "byte",
+							Format: "",
 						},
 					},
 				},
-				Required: []string{"name", "version", "type", "locations", "language", "licenses", "cpes", "purl", "upstreams"},
+				Required: []string{"kind", "namespace", "name", "rule"},
 			},
 		},
-		Dependencies: []string{
-			v1beta1.SyftCoordinates{}.OpenAPIModelName(), v1beta1.UpstreamPackage{}.OpenAPIModelName()},
 	}
 }
 
@@ -2442,6 +3080,51 @@ func schema_pkg_apis_softwarecomposition_v1beta1_HTTPEndpoint(ref common.Referen
 							Format: "byte",
 						},
 					},
+					"queryParams": {
+						SchemaProps: spec.SchemaProps{
+							Description: "QueryParams are the names of the query parameters sent to the endpoint, their values are not kept.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"statusClasses": {
+						SchemaProps: spec.SchemaProps{
+							Description: "StatusClasses are the classes of the response status codes, e.g. \"2xx\".",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"contentTypes": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ContentTypes are the media types of the request and response bodies, without their parameters, e.g. \"application/json\".",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
 				},
 				Required: []string{"internal"},
 			},
@@ -4015,6 +4698,16 @@ func schema_pkg_apis_softwarecomposition_v1beta1_NetworkNeighborhoodContainer(re
 						},
 					},
 					"ingress": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-map-keys": []interface{}{
+									"identifier",
+								},
+								"x-kubernetes-list-type":       "map",
+								"x-kubernetes-patch-merge-key": "identifier",
+								"x-kubernetes-patch-strategy":  "merge",
+							},
+						},
 						SchemaProps: spec.SchemaProps{
 							Type: []string{"array"},
 							Items: &spec.SchemaOrArray{
@@ -4028,6 +4721,16 @@ func schema_pkg_apis_softwarecomposition_v1beta1_NetworkNeighborhoodContainer(re
 						},
 					},
 					"egress": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-map-keys": []interface{}{
+									"identifier",
+								},
+								"x-kubernetes-list-type":       "map",
+								"x-kubernetes-patch-merge-key": "identifier",
+								"x-kubernetes-patch-strategy":  "merge",
+							},
+						},
 						SchemaProps: spec.SchemaProps{
 							Type: []string{"array"},
 							Items: &spec.SchemaOrArray{
@@ -4140,6 +4843,16 @@ func schema_pkg_apis_softwarecomposition_v1beta1_NetworkNeighborhoodSpec(ref com
 						},
 					},
 					"containers": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-map-keys": []interface{}{
+									"name",
+								},
+								"x-kubernetes-list-type":       "map",
+								"x-kubernetes-patch-merge-key": "name",
+								"x-kubernetes-patch-strategy":  "merge",
+							},
+						},
 						SchemaProps: spec.SchemaProps{
 							Type: []string{"array"},
 							Items: &spec.SchemaOrArray{
@@ -4153,6 +4866,16 @@ func schema_pkg_apis_softwarecomposition_v1beta1_NetworkNeighborhoodSpec(ref com
 						},
 					},
 					"initContainers": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-map-keys": []interface{}{
+									"name",
+								},
+								"x-kubernetes-list-type":       "map",
+								"x-kubernetes-patch-merge-key": "name",
+								"x-kubernetes-patch-strategy":  "merge",
+							},
+						},
 						SchemaProps: spec.SchemaProps{
 							Type: []string{"array"},
 							Items: &spec.SchemaOrArray{
@@ -4166,6 +4889,16 @@ func schema_pkg_apis_softwarecomposition_v1beta1_NetworkNeighborhoodSpec(ref com
 						},
 					},
 					"ephemeralContainers": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-map-keys": []interface{}{
+									"name",
+								},
+								"x-kubernetes-list-type":       "map",
+								"x-kubernetes-patch-merge-key": "name",
+								"x-kubernetes-patch-strategy":  "merge",
+							},
+						},
 						SchemaProps: spec.SchemaProps{
 							Type: []string{"array"},
 							Items: &spec.SchemaOrArray{
@@ -4991,6 +5724,293 @@ func schema_pkg_apis_softwarecomposition_v1beta1_Product(ref common.ReferenceCal
 	}
 }
 
+func schema_pkg_apis_softwarecomposition_v1beta1_ProfileEvaluation(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ProfileEvaluation is created on the evaluate subresource of an ApplicationProfile, NetworkNeighborhood or ContainerProfile to ask whether the stored profile covers a batch of observations. It is not persisted: the response carries one result per observation, in the same order, computed with the matchers the node-agent uses at runtime.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"kind": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Kind is a string value representing the REST resource this object represents. Servers may infer this from the endpoint the client submits requests to. Cannot be updated. In CamelCase. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#types-kinds",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"apiVersion": {
+						SchemaProps: spec.SchemaProps{
+							Description: "APIVersion defines the versioned schema of this representation of an object. Servers should convert recognized schemas to the latest internal value, and may reject unrecognized values. More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#resources",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"metadata": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref(v1.ObjectMeta{}.OpenAPIModelName()),
+						},
+					},
+					"spec": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref(v1beta1.ProfileEvaluationSpec{}.OpenAPIModelName()),
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Default: map[string]interface{}{},
+							Ref:     ref(v1beta1.ProfileEvaluationStatus{}.OpenAPIModelName()),
+						},
+					},
+				},
+				Required: []string{"spec"},
+			},
+		},
+		Dependencies: []string{
+			v1beta1.ProfileEvaluationSpec{}.OpenAPIModelName(), v1beta1.ProfileEvaluationStatus{}.OpenAPIModelName(), v1.ObjectMeta{}.OpenAPIModelName()},
+	}
+}
+
+func schema_pkg_apis_softwarecomposition_v1beta1_ProfileEvaluationSpec(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ProfileEvaluationSpec carries the observations to evaluate.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"containerName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ContainerName selects the container of an ApplicationProfile or a NetworkNeighborhood, it is ignored for a ContainerProfile.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"observations": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "atomic",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Type: []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref(v1beta1.ProfileObservation{}.OpenAPIModelName()),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"observations"},
+			},
+		},
+		Dependencies: []string{
+			v1beta1.ProfileObservation{}.OpenAPIModelName()},
+	}
+}
+
+func schema_pkg_apis_softwarecomposition_v1beta1_ProfileEvaluationStatus(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ProfileEvaluationStatus carries the results of a ProfileEvaluation.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"results": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "atomic",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Results has one entry per observation, in the order of the spec.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref(v1beta1.ProfileObservationResult{}.OpenAPIModelName()),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			v1beta1.ProfileObservationResult{}.OpenAPIModelName()},
+	}
+}
+
+func schema_pkg_apis_softwarecomposition_v1beta1_ProfileObservation(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ProfileObservation is one runtime event, only the fields relevant to its Type are read.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"type": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Type is one of Exec, Open, Syscall, Capability, Egress, Ingress or HTTPEndpoint.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"path": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Path is the executable of an Exec or the file of an Open.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"args": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "atomic",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Args are the arguments of an Exec, recorded like the args of a profile exec.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"flags": {
+						VendorExtensible: spec.VendorExtensible{
+							Extensions: spec.Extensions{
+								"x-kubernetes-list-type": "atomic",
+							},
+						},
+						SchemaProps: spec.SchemaProps{
+							Description: "Flags are the flags of an Open.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"name": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Name is the syscall of a Syscall or the capability of a Capability.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"ipAddress": {
+						SchemaProps: spec.SchemaProps{
+							Description: "IPAddress is the peer of an Egress or an Ingress.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"dnsName": {
+						SchemaProps: spec.SchemaProps{
+							Description: "DNSName is the peer of an Egress or an Ingress.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"protocol": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"port": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"integer"},
+							Format: "int32",
+						},
+					},
+					"endpoint": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Endpoint is the port and path of an HTTPEndpoint, e.g. \":80/users/42\".",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"method": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+					"direction": {
+						SchemaProps: spec.SchemaProps{
+							Type:   []string{"string"},
+							Format: "",
+						},
+					},
+				},
+				Required: []string{"type"},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_softwarecomposition_v1beta1_ProfileObservationResult(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ProfileObservationResult tells whether the profile covers an observation.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"allowed": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Allowed is true when an entry of the profile covers the observation.",
+							Default:     false,
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"matchedField": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MatchedField is the field path of the covering entry, e.g. \"spec.containers[0].execs[3]\".",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"matchedEntry": {
+						SchemaProps: spec.SchemaProps{
+							Description: "MatchedEntry is the covering entry as stored, e.g. \"/bin/sh -c ⋯⋯\".",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"reason": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Reason explains why an observation is not allowed.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"allowed"},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_softwarecomposition_v1beta1_ReportMeta(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
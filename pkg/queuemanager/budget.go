@@ -0,0 +1,160 @@
+package queuemanager
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+)
+
+// PayloadSizeFunc returns the size of the payloads a read of kind serves: the
+// one of name in namespace, or the limit largest ones of namespace (every
+// namespace when empty, no limit when not positive) when name is empty. It
+// returns 0 when unknown.
+type PayloadSizeFunc func(kind, namespace, name string, limit int64) int64
+
+// BudgetStats is a snapshot of the in-flight byte budget.
+type BudgetStats struct {
+	// Limit is the budget, 0 when disabled.
+	Limit int64
+	// InFlight is the number of bytes charged by the requests being served.
+	InFlight int64
+	// Peak is the highest InFlight since the last reset.
+	Peak int64
+	// Waiting is the number of requests waiting for the budget.
+	Waiting int
+	// Admitted and Rejected count the charged requests since the last reset.
+	Admitted int64
+	Rejected int64
+}
+
+// byteBudget is the in-flight byte budget shared by every kindQueue. A
+// request is charged at most the whole budget, so that an object larger than
+// the budget is still served once nothing else is in flight.
+type byteBudget struct {
+	mu       sync.Mutex
+	limit    int64
+	inFlight int64
+	released chan struct{} // closed and replaced on every release
+	stats    BudgetStats
+}
+
+func newByteBudget(limit int64) *byteBudget {
+	return &byteBudget{
+		limit:    limit,
+		released: make(chan struct{}),
+	}
+}
+
+// acquire charges n bytes, waiting at most wait for the budget to free up.
+// It returns the bytes charged, to be given back to release, and false when
+// the budget was still exhausted or ctx done.
+func (b *byteBudget) acquire(ctx context.Context, n int64, wait time.Duration) (int64, bool) {
+	if b == nil || b.limit <= 0 || n <= 0 {
+		return 0, true
+	}
+	n = min(n, b.limit)
+	var timeout <-chan time.Time
+	waiting := false
+	b.mu.Lock()
+	for b.inFlight+n > b.limit {
+		if !waiting {
+			if wait <= 0 {
+				b.stats.Rejected++
+				b.mu.Unlock()
+				return 0, false
+			}
+			timer := time.NewTimer(wait)
+			defer timer.Stop()
+			timeout = timer.C
+			waiting = true
+			b.stats.Waiting++
+		}
+		released := b.released
+		b.mu.Unlock()
+		select {
+		case <-released:
+			b.mu.Lock()
+		case <-timeout:
+			b.mu.Lock()
+			b.stats.Waiting--
+			b.stats.Rejected++
+			b.mu.Unlock()
+			return 0, false
+		case <-ctx.Done():
+			b.mu.Lock()
+			b.stats.Waiting--
+			b.stats.Rejected++
+			b.mu.Unlock()
+			return 0, false
+		}
+	}
+	if waiting {
+		b.stats.Waiting--
+	}
+	b.inFlight += n
+	b.stats.Admitted++
+	b.stats.Peak = max(b.stats.Peak, b.inFlight)
+	b.mu.Unlock()
+	return n, true
+}
+
+// release gives back n bytes charged by acquire.
+func (b *byteBudget) release(n int64) {
+	if n <= 0 {
+		return
+	}
+	b.mu.Lock()
+	b.inFlight -= n
+	close(b.released)
+	b.released = make(chan struct{})
+	b.mu.Unlock()
+}
+
+// snapshot returns the current BudgetStats, Peak, Admitted and Rejected are
+// cleared after reading if reset is true.
+func (b *byteBudget) snapshot(reset bool) BudgetStats {
+	if b == nil {
+		return BudgetStats{}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	stats := b.stats
+	stats.Limit = b.limit
+	stats.InFlight = b.inFlight
+	if reset {
+		b.stats = BudgetStats{Waiting: b.stats.Waiting, Peak: b.inFlight}
+	}
+	return stats
+}
+
+// requestCost returns the bytes a request is charged: the content length of
+// writes and the payload size of reads. A list is only charged when it serves
+// the payloads, up to its page size, the other lists only read metadata.
+func (qm *QueueManager) requestCost(r *http.Request, kind, verb string) int64 {
+	if qm.budget == nil {
+		return 0
+	}
+	switch verb {
+	case "create", "update", "patch", http.MethodPost, http.MethodPut, http.MethodPatch:
+		return max(r.ContentLength, 0)
+	case "get", "list", http.MethodGet:
+		if qm.payloadSize == nil {
+			return 0
+		}
+		namespace, name := extractNamespaceAndName(r)
+		if name != "" {
+			return qm.payloadSize(kind, namespace, name, 0)
+		}
+		query := r.URL.Query()
+		if query.Get("resourceVersion") != softwarecomposition.ResourceVersionFullSpec {
+			return 0
+		}
+		limit, _ := strconv.ParseInt(query.Get("limit"), 10, 64)
+		return qm.payloadSize(kind, namespace, "", limit)
+	}
+	return 0
+}
@@ -0,0 +1,195 @@
+package queuemanager
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kubescape/storage/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestByteBudget(t *testing.T) {
+	b := newByteBudget(100)
+
+	charged, ok := b.acquire(context.Background(), 60, 0)
+	require.True(t, ok)
+	assert.Equal(t, int64(60), charged)
+
+	// exhausted, no wait
+	_, ok = b.acquire(context.Background(), 50, 0)
+	assert.False(t, ok)
+
+	// exhausted, waits for a release
+	done := make(chan int64)
+	go func() {
+		charged, ok := b.acquire(context.Background(), 50, time.Minute)
+		assert.True(t, ok)
+		done <- charged
+	}()
+	assert.Eventually(t, func() bool { return b.snapshot(false).Waiting == 1 }, time.Second, time.Millisecond)
+	b.release(charged)
+	charged = <-done
+	assert.Equal(t, int64(50), charged)
+
+	// larger than the budget, charged the whole budget once alone
+	_, ok = b.acquire(context.Background(), 1000, 10*time.Millisecond)
+	assert.False(t, ok)
+	b.release(charged)
+	charged, ok = b.acquire(context.Background(), 1000, 0)
+	require.True(t, ok)
+	assert.Equal(t, int64(100), charged)
+
+	// canceled while waiting
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, ok = b.acquire(ctx, 10, time.Minute)
+	assert.False(t, ok)
+	b.release(charged)
+
+	stats := b.snapshot(true)
+	assert.Equal(t, BudgetStats{Limit: 100, Peak: 100, Admitted: 3, Rejected: 3}, stats)
+	assert.Equal(t, BudgetStats{Limit: 100}, b.snapshot(false))
+}
+
+func TestByteBudget_Disabled(t *testing.T) {
+	var b *byteBudget
+	charged, ok := b.acquire(context.Background(), 1000, 0)
+	assert.True(t, ok)
+	assert.Zero(t, charged)
+	assert.Equal(t, BudgetStats{}, b.snapshot(false))
+}
+
+func TestQueueHandler_ByteBudget(t *testing.T) {
+	cfg := &config.Config{
+		DefaultQueueLength:  10,
+		DefaultWorkerCount:  10,
+		InFlightBytesBudget: 100,
+		InFlightBytesWait:   10 * time.Millisecond,
+	}
+	qm := NewQueueManager(cfg, func(kind, namespace, name string, _ int64) int64 {
+		if kind == "sbomsyfts" && namespace == "kubescape" && name == "big" {
+			return 80
+		}
+		return 0
+	})
+	release := make(chan struct{})
+	started := make(chan struct{})
+	handler := qm.QueueHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			close(started)
+			<-release
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	// a read of a large payload holds the budget
+	go handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet,
+		"/apis/spdx.softwarecomposition.kubescape.io/v1beta1/namespaces/kubescape/sbomsyfts/big?resourceVersion=fullSpec", nil))
+	<-started
+	assert.Equal(t, int64(80), qm.BudgetStats(false).InFlight)
+
+	// a large write does not fit
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost,
+		"/apis/spdx.softwarecomposition.kubescape.io/v1beta1/namespaces/kubescape/sbomsyfts", strings.NewReader(strings.Repeat("x", 50))))
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+
+	// a small one does
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost,
+		"/apis/spdx.softwarecomposition.kubescape.io/v1beta1/namespaces/kubescape/sbomsyfts", strings.NewReader(strings.Repeat("x", 20))))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	close(release)
+	assert.Eventually(t, func() bool { return qm.BudgetStats(false).InFlight == 0 }, time.Second, time.Millisecond)
+	stats := qm.BudgetStats(false)
+	assert.Equal(t, int64(2), stats.Admitted)
+	assert.Equal(t, int64(1), stats.Rejected)
+}
+
+func TestQueueHandler_ByteBudgetWaitHoldsNoWorker(t *testing.T) {
+	cfg := &config.Config{
+		DefaultQueueLength:  10,
+		DefaultWorkerCount:  1,
+		InFlightBytesBudget: 100,
+		InFlightBytesWait:   time.Minute,
+	}
+	qm := NewQueueManager(cfg, func(kind, namespace, name string, _ int64) int64 {
+		switch name {
+		case "big":
+			return 80
+		case "small":
+			return 5
+		}
+		return 0
+	})
+	release := make(chan struct{})
+	started := make(chan struct{})
+	handler := qm.QueueHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			close(started)
+			<-release
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer close(release)
+
+	// a write of another kind holds most of the budget
+	go handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost,
+		"/apis/spdx.softwarecomposition.kubescape.io/v1beta1/namespaces/kubescape/applicationprofiles", strings.NewReader(strings.Repeat("x", 90))))
+	<-started
+
+	// a large read waits for the budget
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	bigDone := make(chan int)
+	go func() {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet,
+			"/apis/spdx.softwarecomposition.kubescape.io/v1beta1/namespaces/kubescape/sbomsyfts/big", nil).WithContext(ctx))
+		bigDone <- rec.Code
+	}()
+	require.Eventually(t, func() bool { return qm.BudgetStats(false).Waiting == 1 }, time.Second, time.Millisecond)
+
+	// a small read of the same kind is served meanwhile, by its only worker
+	done := make(chan int)
+	go func() {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet,
+			"/apis/spdx.softwarecomposition.kubescape.io/v1beta1/namespaces/kubescape/sbomsyfts/small", nil))
+		done <- rec.Code
+	}()
+	select {
+	case code := <-done:
+		assert.Equal(t, http.StatusOK, code)
+	case <-time.After(time.Second):
+		assert.Fail(t, "the small read waited for the large one")
+	}
+
+	cancel()
+	assert.Equal(t, http.StatusTooManyRequests, <-bigDone)
+}
+
+func TestRequestCost_Lists(t *testing.T) {
+	qm := NewQueueManager(&config.Config{InFlightBytesBudget: 100}, func(kind, namespace, name string, limit int64) int64 {
+		if name != "" {
+			return 10
+		}
+		return 30 * max(limit, 1)
+	})
+	cost := func(target string) int64 {
+		return qm.requestCost(httptest.NewRequest(http.MethodGet, target, nil), "sbomsyfts", "list")
+	}
+	// lists of metadata read no payload
+	assert.Zero(t, cost("/apis/spdx.softwarecomposition.kubescape.io/v1beta1/namespaces/kubescape/sbomsyfts"))
+	// full lists are charged their page
+	assert.Equal(t, int64(30), cost("/apis/spdx.softwarecomposition.kubescape.io/v1beta1/namespaces/kubescape/sbomsyfts?resourceVersion=fullSpec"))
+	assert.Equal(t, int64(60), cost("/apis/spdx.softwarecomposition.kubescape.io/v1beta1/namespaces/kubescape/sbomsyfts?resourceVersion=fullSpec&limit=2"))
+	assert.Equal(t, int64(10), cost("/apis/spdx.softwarecomposition.kubescape.io/v1beta1/namespaces/kubescape/sbomsyfts/a"))
+}
@@ -0,0 +1,209 @@
+package queuemanager
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
+	"k8s.io/apiserver/pkg/endpoints/request"
+)
+
+// clientKey returns the client a request is queued for: its authenticated
+// user and the node its service account token is bound to, which tells apart
+// the node-agents sharing a service account, or the source IP of the request
+// when the token is not bound to a node. Nothing the client sends unverified
+// is used, the QueueHandler runs after authentication.
+func clientKey(r *http.Request) string {
+	var userName, node string
+	if u, ok := request.UserFrom(r.Context()); ok {
+		userName = u.GetName()
+		if nodes := u.GetExtra()[serviceaccount.NodeNameKey]; len(nodes) > 0 {
+			node = nodes[0]
+		}
+	}
+	if node == "" {
+		if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+			node = host
+		} else {
+			node = r.RemoteAddr
+		}
+	}
+	return userName + "/" + node
+}
+
+// clientUser returns the user part of a clientKey.
+func clientUser(key string) string {
+	userName, _, _ := strings.Cut(key, "/")
+	return userName
+}
+
+type fairClient struct {
+	key      string
+	inFlight int
+	waiters  []chan struct{}
+	credit   int // grants left in the current turn of the client
+}
+
+// fairQueue hands the workers of a kindQueue out to its clients by weighted
+// round-robin: each client with waiting requests gets, in turn, as many
+// workers as its weight, and never more than maxInFlight at once. Requests of
+// a client are served in arrival order.
+type fairQueue struct {
+	mu          sync.Mutex
+	workers     int
+	busy        int
+	maxInFlight int // per client, 0 for no limit
+	weight      func(key string) int
+	clients     map[string]*fairClient
+	order       []*fairClient // clients with waiters, in round-robin order
+	cursor      int
+}
+
+func newFairQueue(workers, maxInFlight int, weight func(key string) int) *fairQueue {
+	return &fairQueue{
+		workers:     workers,
+		maxInFlight: maxInFlight,
+		weight:      weight,
+		clients:     map[string]*fairClient{},
+	}
+}
+
+// setLimits changes the number of workers and the per-client cap, workers
+// already handed out beyond them are given back as usual.
+func (f *fairQueue) setLimits(workers, maxInFlight int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.workers = workers
+	f.maxInFlight = maxInFlight
+	f.dispatchLocked()
+}
+
+// acquire waits for a worker for client, it returns ctx.Err() when ctx is
+// done first. A worker acquired must be given back with release.
+func (f *fairQueue) acquire(ctx context.Context, client string) error {
+	f.mu.Lock()
+	c, ok := f.clients[client]
+	if !ok {
+		c = &fairClient{key: client}
+		f.clients[client] = c
+	}
+	granted := make(chan struct{})
+	if len(c.waiters) == 0 {
+		f.order = append(f.order, c)
+	}
+	c.waiters = append(c.waiters, granted)
+	f.dispatchLocked()
+	f.mu.Unlock()
+
+	select {
+	case <-granted:
+		if err := ctx.Err(); err != nil {
+			f.release(client)
+			return err
+		}
+		return nil
+	case <-ctx.Done():
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		select {
+		case <-granted:
+			// granted in the meantime
+			f.releaseLocked(c)
+		default:
+			f.abandonLocked(c, granted)
+		}
+		return ctx.Err()
+	}
+}
+
+// release gives back a worker acquired for client.
+func (f *fairQueue) release(client string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if c, ok := f.clients[client]; ok {
+		f.releaseLocked(c)
+	}
+}
+
+func (f *fairQueue) releaseLocked(c *fairClient) {
+	c.inFlight--
+	f.busy--
+	f.forgetLocked(c)
+	f.dispatchLocked()
+}
+
+// abandonLocked removes the waiter granted of c.
+func (f *fairQueue) abandonLocked(c *fairClient, granted chan struct{}) {
+	for i, w := range c.waiters {
+		if w == granted {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			break
+		}
+	}
+	if len(c.waiters) == 0 {
+		f.dequeueLocked(c)
+	}
+	f.forgetLocked(c)
+}
+
+// dequeueLocked removes c from the round-robin order.
+func (f *fairQueue) dequeueLocked(c *fairClient) {
+	for i, o := range f.order {
+		if o == c {
+			f.order = append(f.order[:i], f.order[i+1:]...)
+			if i < f.cursor {
+				f.cursor--
+			}
+			break
+		}
+	}
+	c.credit = 0
+}
+
+// forgetLocked drops the state of c once it has nothing in flight or waiting.
+func (f *fairQueue) forgetLocked(c *fairClient) {
+	if c.inFlight == 0 && len(c.waiters) == 0 {
+		delete(f.clients, c.key)
+	}
+}
+
+// dispatchLocked grants the free workers to the waiting clients.
+func (f *fairQueue) dispatchLocked() {
+	for f.busy < f.workers && len(f.order) > 0 {
+		granted := false
+		// scan at most one full turn for a client under its in-flight limit
+		for range len(f.order) {
+			if f.cursor >= len(f.order) {
+				f.cursor = 0
+			}
+			c := f.order[f.cursor]
+			if f.maxInFlight > 0 && c.inFlight >= f.maxInFlight {
+				c.credit = 0
+				f.cursor++
+				continue
+			}
+			if c.credit <= 0 {
+				c.credit = max(f.weight(c.key), 1)
+			}
+			close(c.waiters[0])
+			c.waiters = c.waiters[1:]
+			c.inFlight++
+			c.credit--
+			f.busy++
+			if len(c.waiters) == 0 {
+				// the cursor now points to the next client
+				f.dequeueLocked(c)
+			} else if c.credit == 0 {
+				f.cursor++
+			}
+			granted = true
+			break
+		}
+		if !granted {
+			return
+		}
+	}
+}
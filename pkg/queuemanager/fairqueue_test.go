@@ -0,0 +1,145 @@
+package queuemanager
+
+import (
+	"context"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+)
+
+func waiting(f *fairQueue) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, c := range f.clients {
+		n += len(c.waiters)
+	}
+	return n
+}
+
+// grantOrder holds the single worker of f, queues the requests of clients in
+// order, then releases the worker and returns the order they were served in.
+func grantOrder(t *testing.T, f *fairQueue, clients ...string) []string {
+	require.NoError(t, f.acquire(context.Background(), "holder"))
+	var mu sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+	for i, client := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, f.acquire(context.Background(), client))
+			mu.Lock()
+			order = append(order, client)
+			mu.Unlock()
+			f.release(client)
+		}()
+		require.Eventually(t, func() bool { return waiting(f) == i+1 }, time.Second, time.Millisecond)
+	}
+	f.release("holder")
+	wg.Wait()
+	return order
+}
+
+func TestFairQueue_RoundRobin(t *testing.T) {
+	f := newFairQueue(1, 0, func(string) int { return 1 })
+	assert.Equal(t, []string{"a", "b", "c", "a", "a"}, grantOrder(t, f, "a", "a", "a", "b", "c"))
+	assert.Empty(t, f.clients)
+}
+
+func TestFairQueue_Weighted(t *testing.T) {
+	f := newFairQueue(1, 0, func(key string) int {
+		if key == "a" {
+			return 2
+		}
+		return 1
+	})
+	assert.Equal(t, []string{"a", "a", "b", "a", "b"}, grantOrder(t, f, "a", "a", "a", "b", "b"))
+}
+
+func TestFairQueue_MaxInFlight(t *testing.T) {
+	f := newFairQueue(2, 1, func(string) int { return 1 })
+	require.NoError(t, f.acquire(context.Background(), "a"))
+
+	// a is at its cap, b gets the second worker
+	granted := make(chan string, 2)
+	for _, client := range []string{"a", "b"} {
+		go func() {
+			assert.NoError(t, f.acquire(context.Background(), client))
+			granted <- client
+		}()
+	}
+	assert.Equal(t, "b", <-granted)
+	select {
+	case <-granted:
+		t.Fatal("a exceeded its in-flight cap")
+	case <-time.After(20 * time.Millisecond):
+	}
+	f.release("a")
+	assert.Equal(t, "a", <-granted)
+	f.release("a")
+	f.release("b")
+	assert.Empty(t, f.clients)
+}
+
+func TestFairQueue_Canceled(t *testing.T) {
+	f := newFairQueue(1, 0, func(string) int { return 1 })
+	require.NoError(t, f.acquire(context.Background(), "a"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, f.acquire(ctx, "b"), context.DeadlineExceeded)
+
+	f.release("a")
+	assert.Empty(t, f.clients)
+	assert.Empty(t, f.order)
+	assert.Zero(t, f.busy)
+}
+
+func TestClientKey(t *testing.T) {
+	r := httptest.NewRequest("GET", "/apis/spdx.softwarecomposition.kubescape.io/v1beta1/applicationprofiles", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+	// unverified headers are ignored
+	r.Header.Set("X-Remote-User", "system:admin")
+	r.Header.Set("X-Forwarded-For", "10.244.0.7")
+	assert.Equal(t, "/10.0.0.1", clientKey(r))
+
+	r = r.WithContext(request.WithUser(r.Context(), &user.DefaultInfo{Name: "system:serviceaccount:kubescape:node-agent"}))
+	assert.Equal(t, "system:serviceaccount:kubescape:node-agent/10.0.0.1", clientKey(r))
+
+	r = r.WithContext(request.WithUser(r.Context(), &user.DefaultInfo{
+		Name:  "system:serviceaccount:kubescape:node-agent",
+		Extra: map[string][]string{serviceaccount.NodeNameKey: {"node-1"}},
+	}))
+	key := clientKey(r)
+	assert.Equal(t, "system:serviceaccount:kubescape:node-agent/node-1", key)
+	assert.Equal(t, "system:serviceaccount:kubescape:node-agent", clientUser(key))
+}
+
+func TestFairQueue_SetLimits(t *testing.T) {
+	f := newFairQueue(1, 0, func(string) int { return 1 })
+	require.NoError(t, f.acquire(context.Background(), "a"))
+	granted := make(chan struct{})
+	go func() {
+		assert.NoError(t, f.acquire(context.Background(), "b"))
+		close(granted)
+	}()
+	require.Eventually(t, func() bool { return waiting(f) == 1 }, time.Second, time.Millisecond)
+	// more workers are handed out at once
+	f.setLimits(2, 0)
+	<-granted
+	f.release("b")
+	// fewer workers are handed out once the busy ones are released
+	f.setLimits(0, 0)
+	f.release("a")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, f.acquire(ctx, "a"), context.DeadlineExceeded)
+}
@@ -2,6 +2,7 @@ package queuemanager
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -15,21 +16,49 @@ import (
 )
 
 type kindQueue struct {
-	maxObjectSize int
-	maxQueueLen   uint64
+	maxObjectSize atomic.Int64
+	maxQueueLen   atomic.Uint64
 	queueLen      atomic.Uint64
-	workerSem     chan struct{}
+	workers       *fairQueue
 }
 
 type QueueManager struct {
-	queues sync.Map
-	cfg    *config.Config
+	queues      sync.Map
+	cfg         atomic.Pointer[config.Config]
+	budget      *byteBudget
+	payloadSize PayloadSizeFunc
 }
 
-func NewQueueManager(cfg *config.Config) *QueueManager {
-	return &QueueManager{
-		cfg: cfg,
+// NewQueueManager returns a QueueManager configured by cfg. Reads are charged
+// against the in-flight byte budget by the payload sizes payloadSize returns,
+// a nil payloadSize leaves them uncharged.
+func NewQueueManager(cfg *config.Config, payloadSize PayloadSizeFunc) *QueueManager {
+	qm := &QueueManager{
+		payloadSize: payloadSize,
 	}
+	qm.cfg.Store(cfg)
+	if cfg.InFlightBytesBudget > 0 {
+		qm.budget = newByteBudget(cfg.InFlightBytesBudget)
+		logger.L().Info("QueueManager - in-flight byte budget", helpers.Int("budget", int(cfg.InFlightBytesBudget)),
+			helpers.String("wait", cfg.InFlightBytesWait.String()))
+	}
+	return qm
+}
+
+// Reload applies cfg to the queues, the requests already queued or being
+// served are not affected. The in-flight byte budget itself is not resized.
+func (qm *QueueManager) Reload(cfg *config.Config) {
+	qm.cfg.Store(cfg)
+	qm.queues.Range(func(kind, q any) bool {
+		qm.configureQueue(kind.(string), q.(*kindQueue), cfg)
+		return true
+	})
+}
+
+// BudgetStats returns a snapshot of the in-flight byte budget, the peak and
+// counters are cleared after reading if reset is true.
+func (qm *QueueManager) BudgetStats(reset bool) BudgetStats {
+	return qm.budget.snapshot(reset)
 }
 
 func (qm *QueueManager) getOrCreateQueue(kind string) *kindQueue {
@@ -39,10 +68,25 @@ func (qm *QueueManager) getOrCreateQueue(kind string) *kindQueue {
 	}
 
 	// Use a double-check pattern with sync.Map
-	kcfg, ok := qm.cfg.KindQueues[kind]
-	queueLen := qm.cfg.DefaultQueueLength
-	workerCount := qm.cfg.DefaultWorkerCount
-	maxObjectSize := qm.cfg.DefaultMaxObjectSize
+	newQueue := &kindQueue{workers: newFairQueue(0, 0, qm.clientWeight)}
+	qm.configureQueue(kind, newQueue, qm.cfg.Load())
+
+	// Store the new queue if it doesn't already exist
+	actual, _ := qm.queues.LoadOrStore(kind, newQueue)
+	if actual != newQueue {
+		// Another goroutine created the queue, discard the new one
+		return actual.(*kindQueue)
+	}
+	return newQueue
+}
+
+// configureQueue sets the limits of the queue of kind from cfg.
+func (qm *QueueManager) configureQueue(kind string, q *kindQueue, cfg *config.Config) {
+	kcfg, ok := cfg.KindQueues[kind]
+	queueLen := cfg.DefaultQueueLength
+	workerCount := cfg.DefaultWorkerCount
+	maxObjectSize := cfg.DefaultMaxObjectSize
+	maxInFlightPerClient := cfg.MaxInFlightPerClient
 	if ok {
 		if kcfg.QueueLength > 0 {
 			queueLen = kcfg.QueueLength
@@ -53,23 +97,24 @@ func (qm *QueueManager) getOrCreateQueue(kind string) *kindQueue {
 		if kcfg.MaxObjectSize > 0 {
 			maxObjectSize = kcfg.MaxObjectSize
 		}
+		if kcfg.MaxInFlightPerClient > 0 {
+			maxInFlightPerClient = kcfg.MaxInFlightPerClient
+		}
 	}
+	q.maxObjectSize.Store(int64(maxObjectSize))
+	q.maxQueueLen.Store(uint64(queueLen))
+	q.workers.setLimits(workerCount, maxInFlightPerClient)
 
-	newQueue := &kindQueue{
-		maxObjectSize: maxObjectSize,
-		maxQueueLen:   uint64(queueLen),
-		workerSem:     make(chan struct{}, workerCount),
-	}
+	logger.L().Info("QueueManager - queue configuration", helpers.String("kind", kind), helpers.Int("queueLength", queueLen), helpers.Int("workerCount", workerCount), helpers.Int("maxObjectSize", maxObjectSize), helpers.Int("maxInFlightPerClient", maxInFlightPerClient))
+}
 
-	// Store the new queue if it doesn't already exist
-	actual, _ := qm.queues.LoadOrStore(kind, newQueue)
-	if actual != newQueue {
-		// Another goroutine created the queue, discard the new one
-		return actual.(*kindQueue)
+// clientWeight returns the weight of a client in the fair queuing of the
+// kindQueues, configured by user name and 1 by default.
+func (qm *QueueManager) clientWeight(key string) int {
+	if weight, ok := qm.cfg.Load().ClientWeights[clientUser(key)]; ok && weight > 0 {
+		return weight
 	}
-
-	logger.L().Info("QueueManager - queue configuration", helpers.String("kind", kind), helpers.Int("queueLength", queueLen), helpers.Int("workerCount", workerCount), helpers.Int("maxObjectSize", maxObjectSize))
-	return newQueue
+	return 1
 }
 
 func extractKindAndVerb(r *http.Request) (kind, verb string) {
@@ -81,6 +126,24 @@ func extractKindAndVerb(r *http.Request) (kind, verb string) {
 	return extractKindAndVerbFromPath(r)
 }
 
+func extractNamespaceAndName(r *http.Request) (namespace, name string) {
+	if reqInfo, ok := request.RequestInfoFrom(r.Context()); ok {
+		return reqInfo.Namespace, reqInfo.Name
+	}
+	// fallback: .../namespaces/<namespace>/<kind>/<name>
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	for i, part := range parts {
+		if part == "namespaces" && i+1 < len(parts) {
+			namespace = parts[i+1]
+			if i+3 < len(parts) {
+				name = parts[i+3]
+			}
+			break
+		}
+	}
+	return namespace, name
+}
+
 func extractKindAndVerbFromPath(r *http.Request) (kind, verb string) {
 	// Example: /apis/spdx.softwarecomposition.kubescape.io/v1beta1/namespaces/foo/configurationscansummaries
 	//          /apis/spdx.softwarecomposition.kubescape.io/v1beta1/namespaces/default/applicationprofiles
@@ -103,8 +166,8 @@ func extractKindAndVerbFromPath(r *http.Request) (kind, verb string) {
 }
 
 func shouldSkipQueue(r *http.Request) bool {
-	// FIXME find a way to limit the number of watches in parallel
-	// watch requests cannot be queued, as they are long-lived and can block the queue
+	// watch requests cannot be queued, as they are long-lived and can block the queue,
+	// they are limited by the WatchAdmission instead
 	if r.URL.Query().Get("watch") == "true" {
 		return true
 	}
@@ -141,44 +204,52 @@ func (qm *QueueManager) QueueHandler(next http.Handler) http.Handler {
 		}
 		q := qm.getOrCreateQueue(kind)
 		// Enforce max object size if applicable (Content-Length header)
-		if q.maxObjectSize > 0 && r.ContentLength > int64(q.maxObjectSize) {
+		if maxObjectSize := q.maxObjectSize.Load(); maxObjectSize > 0 && r.ContentLength > maxObjectSize {
 			logger.L().Warning("QueueManager - request entity too large", helpers.String("path", r.URL.Path),
 				helpers.String("kind", kind), helpers.String("verb", verb),
-				helpers.Int("contentLength", int(r.ContentLength)), helpers.Int("maxObjectSize", q.maxObjectSize))
+				helpers.Int("contentLength", int(r.ContentLength)), helpers.Int("maxObjectSize", int(maxObjectSize)))
 			http.Error(w, "Request entity too large", http.StatusRequestEntityTooLarge)
 			return
 		}
 		// Check if the queue is full
-		if q.queueLen.Add(1) > q.maxQueueLen {
+		if q.queueLen.Add(1) > q.maxQueueLen.Load() {
 			q.queueLen.Add(^uint64(0)) // Decrement back if the queue is full
 			http.Error(w, "Too Many Requests (queue full)", http.StatusTooManyRequests)
 			return
 		}
 		defer q.queueLen.Add(^uint64(0)) // Ensure decrement after processing
-		// Limit concurrent workers (but also check for context cancellation)
-		select {
-		case <-r.Context().Done():
+		// Charge the request against the in-flight byte budget shared by every kind, before taking
+		// a worker: a request waiting for bytes must not hold a worker the small requests of its kind need
+		wait := qm.cfg.Load().InFlightBytesWait
+		charged, ok := qm.budget.acquire(r.Context(), qm.requestCost(r, kind, verb), wait)
+		if !ok {
+			logger.L().Debug("QueueManager - in-flight byte budget exhausted", helpers.String("path", r.URL.Path),
+				helpers.String("kind", kind), helpers.String("verb", verb))
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds(wait)))
+			http.Error(w, "Too Many Requests (in-flight byte budget exhausted)", http.StatusTooManyRequests)
+			return
+		}
+		defer qm.budget.release(charged)
+		// Limit concurrent workers, handed out fairly across clients (but also check for context cancellation)
+		client := clientKey(r)
+		if err := q.workers.acquire(r.Context(), client); err != nil {
 			logger.L().Debug("QueueManager - request context canceled", helpers.String("path", r.URL.Path),
-				helpers.String("kind", kind), helpers.String("verb", verb),
-				helpers.Int("queueLen", int(q.queueLen.Load())), helpers.Int("maxQueueLen", int(q.maxQueueLen)))
+				helpers.String("kind", kind), helpers.String("verb", verb), helpers.String("client", client),
+				helpers.Int("queueLen", int(q.queueLen.Load())), helpers.Int("maxQueueLen", int(q.maxQueueLen.Load())))
 			http.Error(w, "Request Timeout", http.StatusRequestTimeout)
 			return
-		case q.workerSem <- struct{}{}:
-			// check if the context is still valid
-			if r.Context().Err() != nil {
-				logger.L().Debug("QueueManager - request context canceled after semaphore", helpers.String("path", r.URL.Path),
-					helpers.String("kind", kind), helpers.String("verb", verb),
-					helpers.Int("queueLen", int(q.queueLen.Load())), helpers.Int("maxQueueLen", int(q.maxQueueLen)))
-				<-q.workerSem // Release the semaphore
-				http.Error(w, "Request Timeout", http.StatusRequestTimeout)
-				return
-			}
-			defer func() { <-q.workerSem }() // Release semaphore when done
-			next.ServeHTTP(w, r)
 		}
+		defer q.workers.release(client) // Release the worker when done
+		next.ServeHTTP(w, r)
 	})
 }
 
+// retryAfterSeconds returns the Retry-After of a request rejected after
+// waiting wait for the byte budget, at least a second.
+func retryAfterSeconds(wait time.Duration) int {
+	return max(int(wait.Round(time.Second)/time.Second), 1)
+}
+
 // TimeoutLoggerMiddleware logs a warning if a request takes longer than timeoutSeconds seconds to finish.
 func TimeoutLoggerMiddleware(next http.Handler, timeoutSeconds int) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -4,6 +4,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/kubescape/storage/pkg/config"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -68,3 +69,28 @@ func TestExtractKindAndVerb_Fallback(t *testing.T) {
 	assert.Equal(t, "unknown", kind)
 	assert.Equal(t, "GET", verb)
 }
+
+func TestQueueManager_Reload(t *testing.T) {
+	qm := NewQueueManager(&config.Config{
+		DefaultQueueLength:   10,
+		DefaultWorkerCount:   1,
+		DefaultMaxObjectSize: 100,
+	}, nil)
+	q := qm.getOrCreateQueue("sbomsyfts")
+	assert.Equal(t, int64(100), q.maxObjectSize.Load())
+	assert.Equal(t, uint64(10), q.maxQueueLen.Load())
+	assert.Equal(t, 1, q.workers.workers)
+
+	qm.Reload(&config.Config{
+		DefaultQueueLength:   10,
+		DefaultWorkerCount:   1,
+		DefaultMaxObjectSize: 100,
+		KindQueues:           map[string]config.KindQueueConfig{"sbomsyfts": {QueueLength: 5, WorkerCount: 3, MaxObjectSize: 50}},
+		MaxInFlightPerClient: 2,
+	})
+	assert.Same(t, q, qm.getOrCreateQueue("sbomsyfts"))
+	assert.Equal(t, int64(50), q.maxObjectSize.Load())
+	assert.Equal(t, uint64(5), q.maxQueueLen.Load())
+	assert.Equal(t, 3, q.workers.workers)
+	assert.Equal(t, 2, q.workers.maxInFlight)
+}
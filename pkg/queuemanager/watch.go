@@ -0,0 +1,233 @@
+package queuemanager
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kubescape/go-logger"
+	"github.com/kubescape/go-logger/helpers"
+	"github.com/kubescape/storage/pkg/config"
+	"k8s.io/apiserver/pkg/endpoints/request"
+)
+
+// watchShedGrace is how long a shed watch has to send its error event before
+// its request is canceled.
+var watchShedGrace = 5 * time.Second
+
+// WatchStats is a snapshot of the watch admission.
+type WatchStats struct {
+	// Active is the number of watches being served.
+	Active int
+	// Admitted, Rejected and Shed count the watches since the last reset.
+	Admitted int64
+	Rejected int64
+	Shed     int64
+}
+
+type admittedWatch struct {
+	client   string
+	resource string
+	shed     chan struct{}
+	once     sync.Once
+}
+
+func (w *admittedWatch) shedNow() { w.once.Do(func() { close(w.shed) }) }
+
+// WatchAdmission limits the watches served at once, overall
+// (MaxWatches), per client (MaxWatchesPerClient, a client being an authenticated user
+// and its node, see clientKey) and per resource (WatchResourceCaps). A watch over a
+// limit gets a 429. When only the overall limit is reached, the oldest watch
+// of the client holding the most watches is shed instead, provided it holds
+// more than the new client: it receives an error event asking it to retry
+// later, then its request ends.
+type WatchAdmission struct {
+	mu         sync.Mutex
+	cfg        atomic.Pointer[config.Config]
+	total      int
+	byClient   map[string][]*admittedWatch // in admission order
+	byResource map[string]int
+	stats      WatchStats
+}
+
+func NewWatchAdmission(cfg *config.Config) *WatchAdmission {
+	a := &WatchAdmission{
+		byClient:   map[string][]*admittedWatch{},
+		byResource: map[string]int{},
+	}
+	a.cfg.Store(cfg)
+	return a
+}
+
+// Reload applies the limits of cfg to the next watches, the watches already
+// admitted are not shed for them.
+func (a *WatchAdmission) Reload(cfg *config.Config) {
+	a.cfg.Store(cfg)
+}
+
+func isWatch(r *http.Request) bool {
+	if reqInfo, ok := request.RequestInfoFrom(r.Context()); ok {
+		return reqInfo.Verb == "watch"
+	}
+	switch r.URL.Query().Get("watch") {
+	case "true", "1":
+		return true
+	}
+	return false
+}
+
+// admit admits a watch of client on resource, possibly shedding another one,
+// or returns nil when a limit is reached.
+func (a *WatchAdmission) admit(client, resource string) (admitted, shed *admittedWatch) {
+	cfg := a.cfg.Load()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if limit := cfg.MaxWatchesPerClient; limit > 0 && len(a.byClient[client]) >= limit {
+		a.stats.Rejected++
+		return nil, nil
+	}
+	if limit, ok := cfg.WatchResourceCaps[resource]; ok && limit > 0 && a.byResource[resource] >= limit {
+		a.stats.Rejected++
+		return nil, nil
+	}
+	if limit := cfg.MaxWatches; limit > 0 && a.total >= limit {
+		shed = a.victimLocked(client)
+		if shed == nil {
+			a.stats.Rejected++
+			return nil, nil
+		}
+		a.removeLocked(shed)
+		a.stats.Shed++
+	}
+	admitted = &admittedWatch{client: client, resource: resource, shed: make(chan struct{})}
+	a.byClient[client] = append(a.byClient[client], admitted)
+	a.byResource[resource]++
+	a.total++
+	a.stats.Admitted++
+	return admitted, shed
+}
+
+// victimLocked returns the oldest watch of the client holding the most
+// watches, if it holds more than client once admitted.
+func (a *WatchAdmission) victimLocked(client string) *admittedWatch {
+	var victim []*admittedWatch
+	for c, watches := range a.byClient {
+		if c != client && (len(watches) > len(victim) || len(watches) == len(victim) && len(victim) > 0 && c < victim[0].client) {
+			victim = watches
+		}
+	}
+	if len(victim) <= len(a.byClient[client])+1 {
+		return nil
+	}
+	return victim[0]
+}
+
+// removeLocked forgets w, it is a no-op if w was already removed.
+func (a *WatchAdmission) removeLocked(w *admittedWatch) {
+	watches := a.byClient[w.client]
+	for i, o := range watches {
+		if o != w {
+			continue
+		}
+		if len(watches) == 1 {
+			delete(a.byClient, w.client)
+		} else {
+			a.byClient[w.client] = append(watches[:i:i], watches[i+1:]...)
+		}
+		if a.byResource[w.resource]--; a.byResource[w.resource] == 0 {
+			delete(a.byResource, w.resource)
+		}
+		a.total--
+		return
+	}
+}
+
+func (a *WatchAdmission) done(w *admittedWatch) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.removeLocked(w)
+}
+
+// Stats returns a snapshot of the watch admission, the counters are cleared
+// after reading if reset is true.
+func (a *WatchAdmission) Stats(reset bool) WatchStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	stats := a.stats
+	stats.Active = a.total
+	if reset {
+		a.stats = WatchStats{}
+	}
+	return stats
+}
+
+// Handler admits the watch requests, see WatchAdmission.
+func (a *WatchAdmission) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isWatch(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		retryAfter := retryAfterSeconds(a.cfg.Load().WatchRetryAfter)
+		kind, _ := extractKindAndVerb(r)
+		client := clientKey(r)
+		admitted, shed := a.admit(client, kind)
+		if admitted == nil {
+			logger.L().Debug("WatchAdmission - too many watches", helpers.String("path", r.URL.Path),
+				helpers.String("kind", kind), helpers.String("client", client))
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			http.Error(w, "Too Many Requests (too many watches)", http.StatusTooManyRequests)
+			return
+		}
+		if shed != nil {
+			logger.L().Debug("WatchAdmission - shedding watch", helpers.String("kind", shed.resource),
+				helpers.String("client", shed.client), helpers.String("for", client))
+			shed.shedNow()
+		}
+		defer a.done(admitted)
+
+		ctx, cancel := context.WithCancel(WithWatchShed(r.Context(), admitted.shed, retryAfter))
+		defer cancel()
+		go func() {
+			select {
+			case <-admitted.shed:
+			case <-ctx.Done():
+				return
+			}
+			// the watch is expected to end by itself after its error event
+			timer := time.NewTimer(watchShedGrace)
+			defer timer.Stop()
+			select {
+			case <-timer.C:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+type watchShedKey struct{}
+
+type watchShed struct {
+	ch         <-chan struct{}
+	retryAfter int
+}
+
+// WithWatchShed returns a context carrying the shed signal of a watch and the
+// seconds its client should wait before retrying once shed.
+func WithWatchShed(ctx context.Context, shed <-chan struct{}, retryAfter int) context.Context {
+	return context.WithValue(ctx, watchShedKey{}, watchShed{ch: shed, retryAfter: retryAfter})
+}
+
+// WatchShed returns the channel closed when the watch served under ctx is
+// shed, nil when it is never shed, and the seconds its client should wait
+// before retrying. A shed watch should send an error event asking its client
+// to retry later, then end.
+func WatchShed(ctx context.Context) (<-chan struct{}, int) {
+	shed, _ := ctx.Value(watchShedKey{}).(watchShed)
+	return shed.ch, shed.retryAfter
+}
@@ -0,0 +1,136 @@
+package queuemanager
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kubescape/storage/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+)
+
+func TestWatchAdmission_PerClientLimit(t *testing.T) {
+	a := NewWatchAdmission(&config.Config{MaxWatchesPerClient: 2})
+	w1, _ := a.admit("a", "applicationprofiles")
+	w2, _ := a.admit("a", "sbomsyfts")
+	require.NotNil(t, w1)
+	require.NotNil(t, w2)
+	w3, _ := a.admit("a", "sbomsyfts")
+	assert.Nil(t, w3)
+	other, _ := a.admit("b", "sbomsyfts")
+	assert.NotNil(t, other)
+	a.done(w1)
+	w3, _ = a.admit("a", "sbomsyfts")
+	assert.NotNil(t, w3)
+	assert.Equal(t, WatchStats{Active: 3, Admitted: 4, Rejected: 1}, a.Stats(true))
+	assert.Equal(t, WatchStats{Active: 3}, a.Stats(false))
+}
+
+func TestWatchAdmission_ResourceCap(t *testing.T) {
+	a := NewWatchAdmission(&config.Config{WatchResourceCaps: map[string]int{"sbomsyfts": 1}})
+	w1, _ := a.admit("a", "sbomsyfts")
+	require.NotNil(t, w1)
+	w2, _ := a.admit("b", "sbomsyfts")
+	assert.Nil(t, w2)
+	w3, _ := a.admit("b", "applicationprofiles")
+	assert.NotNil(t, w3)
+	a.done(w1)
+	w2, _ = a.admit("b", "sbomsyfts")
+	assert.NotNil(t, w2)
+}
+
+func TestWatchAdmission_ShedsOldestOfBiggestClient(t *testing.T) {
+	a := NewWatchAdmission(&config.Config{MaxWatches: 4})
+	var greedy []*admittedWatch
+	for range 3 {
+		w, shed := a.admit("greedy", "sbomsyfts")
+		require.NotNil(t, w)
+		require.Nil(t, shed)
+		greedy = append(greedy, w)
+	}
+	b1, shed := a.admit("b", "sbomsyfts")
+	require.NotNil(t, b1)
+	require.Nil(t, shed)
+	// greedy holds 3 watches, more than b once admitted
+	b2, shed := a.admit("b", "sbomsyfts")
+	require.NotNil(t, b2)
+	assert.Same(t, greedy[0], shed)
+	// greedy and b now hold 2 watches each, nothing is shed
+	b3, shed := a.admit("b", "sbomsyfts")
+	assert.Nil(t, b3)
+	assert.Nil(t, shed)
+	// done after shed is a no-op
+	a.done(greedy[0])
+	assert.Equal(t, WatchStats{Active: 4, Admitted: 5, Rejected: 1, Shed: 1}, a.Stats(false))
+}
+
+func TestWatchAdmission_Handler(t *testing.T) {
+	a := NewWatchAdmission(&config.Config{MaxWatches: 3, WatchRetryAfter: 30 * time.Second})
+	defer func(grace time.Duration) { watchShedGrace = grace }(watchShedGrace)
+	watchShedGrace = 50 * time.Millisecond
+
+	started := make(chan struct{}, 4)
+	var retryAfter int
+	handler := a.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		shed, seconds := WatchShed(r.Context())
+		if shed == nil {
+			// not a watch
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		retryAfter = seconds
+		started <- struct{}{}
+		// a watch that ignores being shed ends when its request is canceled
+		<-r.Context().Done()
+	}))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	watchRequest := func(node string) *http.Request {
+		r := httptest.NewRequestWithContext(ctx, http.MethodGet, "/apis/spdx.softwarecomposition.kubescape.io/v1beta1/sbomsyfts?watch=true", nil)
+		return r.WithContext(request.WithUser(ctx, &user.DefaultInfo{
+			Name:  "system:serviceaccount:kubescape:node-agent",
+			Extra: map[string][]string{serviceaccount.NodeNameKey: {node}},
+		}))
+	}
+	done := make(chan string, 4)
+	serveWatch := func(node string) {
+		go func() {
+			handler.ServeHTTP(httptest.NewRecorder(), watchRequest(node))
+			done <- node
+		}()
+		<-started
+	}
+
+	serveWatch("greedy")
+	serveWatch("greedy")
+	serveWatch("other")
+	assert.Equal(t, 30, retryAfter)
+
+	// greedy would hold as many watches as other once admitted
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, watchRequest("other"))
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "30", rec.Header().Get("Retry-After"))
+
+	// other requests are not limited
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/apis/spdx.softwarecomposition.kubescape.io/v1beta1/sbomsyfts", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// a new client gets in by shedding a watch of greedy
+	serveWatch("newcomer")
+	select {
+	case node := <-done:
+		// the shed watch ignored its signal, it was canceled after the grace period
+		assert.Equal(t, "greedy", node)
+	case <-time.After(time.Second):
+		t.Fatal("shed watch not canceled")
+	}
+	assert.Equal(t, WatchStats{Active: 3, Admitted: 4, Rejected: 1, Shed: 1}, a.Stats(false))
+}
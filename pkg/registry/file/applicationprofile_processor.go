@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"sync/atomic"
 
 	mapset "github.com/deckarep/golang-set/v2"
 	"github.com/kubescape/go-logger"
@@ -14,6 +15,7 @@ import (
 	"github.com/kubescape/storage/pkg/config"
 	"github.com/kubescape/storage/pkg/registry/file/callstack"
 	"github.com/kubescape/storage/pkg/registry/file/dynamicpathdetector"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -22,21 +24,39 @@ import (
 
 type ApplicationProfileProcessor struct {
 	defaultNamespace          string
-	maxApplicationProfileSize int
-	storageImpl               ContainerProfileStorage
+	maxApplicationProfileSize atomic.Int64
+	// callStackMaxDepth and callStackMaxBreadth bound the unified call stack
+	// trees kept in a profile, see callstack.PruneCallStack.
+	callStackMaxDepth   int
+	callStackMaxBreadth int
+	storageImpl         ContainerProfileStorage
 	// collapseSettings is the lookup hook the deflate path consults for
 	// per-prefix thresholds. Defaults to dynamicpathdetector.DefaultCollapseSettings;
 	// production wiring may override via SetCollapseSettings to a provider that
 	// reads the cluster-scoped CollapseConfiguration "default" CR.
 	collapseSettings dynamicpathdetector.CollapseSettingsProvider
+	// collapseResolver, when set, takes precedence over collapseSettings and
+	// picks the settings of each profile from its namespace and labels.
+	collapseResolver CollapseSettingsResolver
+	// guardrails returns the Guardrail rules profiles are evaluated against,
+	// nil disables the evaluation.
+	guardrails GuardrailsProvider
 }
 
 func NewApplicationProfileProcessor(cfg config.Config) *ApplicationProfileProcessor {
-	return &ApplicationProfileProcessor{
-		defaultNamespace:          cfg.DefaultNamespace,
-		maxApplicationProfileSize: cfg.MaxApplicationProfileSize,
-		collapseSettings:          dynamicpathdetector.DefaultCollapseSettings,
+	a := &ApplicationProfileProcessor{
+		defaultNamespace:    cfg.DefaultNamespace,
+		callStackMaxDepth:   cfg.CallStackMaxDepth,
+		callStackMaxBreadth: cfg.CallStackMaxBreadth,
+		collapseSettings:    dynamicpathdetector.DefaultCollapseSettings,
 	}
+	a.Reload(&cfg)
+	return a
+}
+
+// Reload applies the size limit of cfg to the next profiles saved.
+func (a *ApplicationProfileProcessor) Reload(cfg *config.Config) {
+	a.maxApplicationProfileSize.Store(int64(cfg.MaxApplicationProfileSize))
 }
 
 // SetCollapseSettings overrides the provider the deflate path uses to fetch
@@ -64,6 +84,27 @@ func (a *ApplicationProfileProcessor) effectiveCollapseSettings() dynamicpathdet
 	return a.collapseSettings()
 }
 
+// SetCollapseSettingsResolver sets the resolver picking the collapse settings
+// of each profile, it takes precedence over SetCollapseSettings.
+func (a *ApplicationProfileProcessor) SetCollapseSettingsResolver(r CollapseSettingsResolver) {
+	a.collapseResolver = r
+}
+
+// resolveCollapseSettings returns the settings profile is collapsed with and
+// the name of the CollapseConfiguration they come from.
+func (a *ApplicationProfileProcessor) resolveCollapseSettings(ctx context.Context, profile metav1.Object) (dynamicpathdetector.CollapseSettings, string) {
+	if a.collapseResolver != nil {
+		return a.collapseResolver(ctx, profile.GetNamespace(), profile.GetLabels())
+	}
+	return a.effectiveCollapseSettings(), ""
+}
+
+// SetGuardrails sets the provider of the guardrails every saved profile is
+// evaluated against.
+func (a *ApplicationProfileProcessor) SetGuardrails(p GuardrailsProvider) {
+	a.guardrails = p
+}
+
 var _ Processor = (*ApplicationProfileProcessor)(nil)
 
 func (a *ApplicationProfileProcessor) AfterCreate(_ context.Context, _ runtime.Object) error {
@@ -81,48 +122,67 @@ func (a *ApplicationProfileProcessor) PreSave(ctx context.Context, object runtim
 
 	// size is the sum of all fields in all containers
 	var size int
+	settings, collapseConfiguration := a.resolveCollapseSettings(ctx, profile)
+
+	// deflateContainer deflates container with the collapsing strengthened
+	// for level, see MaxDegradationLevel
+	deflateContainer := func(container softwarecomposition.ApplicationProfileContainer, sbomSet mapset.Set[string], level int) softwarecomposition.ApplicationProfileContainer {
+		container.Execs = degradeExecs(container.Execs, level)
+		deflated := deflateApplicationProfileContainer(container, sbomSet, degradeCollapseSettings(settings, level))
+		var pruned bool
+		if deflated.IdentifiedCallStacks, pruned = callstack.PruneIdentifiedCallStacks(deflated.IdentifiedCallStacks, a.callStackMaxDepth, a.callStackMaxBreadth); pruned {
+			logger.L().Debug("pruned call stacks", loggerhelpers.String("name", profile.Name), loggerhelpers.String("namespace", profile.Namespace), loggerhelpers.String("container", container.Name))
+		}
+		return deflated
+	}
+
+	// containers are deflated in place, their original is kept in case they
+	// have to be degraded
+	type source struct {
+		container softwarecomposition.ApplicationProfileContainer
+		sbomSet   mapset.Set[string]
+	}
+	lists := []*[]softwarecomposition.ApplicationProfileContainer{&profile.Spec.EphemeralContainers, &profile.Spec.InitContainers, &profile.Spec.Containers}
+	sources := make([][]source, len(lists))
+	var count int
+	for l, containers := range lists {
+		for i, container := range *containers {
+			sbomSet := a.sbomSet(ctx, container)
+			sources[l] = append(sources[l], source{container: container, sbomSet: sbomSet})
+			(*containers)[i] = deflateContainer(container, sbomSet, 0)
+			size += applicationProfileContainerSize((*containers)[i])
+			count++
+		}
+	}
 
-	// Define a function to process a slice of containers
-	processContainers := func(containers []softwarecomposition.ApplicationProfileContainer) []softwarecomposition.ApplicationProfileContainer {
-		for i, container := range containers {
-			var sbomSet mapset.Set[string]
-			// get files from corresponding sbom
-			sbomName, err := names.ImageInfoToSlug(container.ImageTag, container.ImageID)
-			if err == nil {
-				key := K8sKeysToPath("", "spdx.softwarecomposition.kubescape.io", "sbomsyft", "", a.defaultNamespace, sbomName)
-				if sbom, err := a.storageImpl.GetSbom(ctx, key); err == nil {
-					// fill sbomSet
-					sbomSet = mapset.NewSet[string]()
-					for _, f := range sbom.Spec.Syft.Files {
-						sbomSet.Add(f.Location.RealPath)
-					}
-				} else {
-					logger.L().Debug("failed to get sbom", loggerhelpers.Error(err), loggerhelpers.String("key", key))
+	// degrade the containers larger than their share of the limit
+	maxSize := int(a.maxApplicationProfileSize.Load())
+	var degradationLevel int
+	if size > maxSize {
+		budget := containerBudget(maxSize, count)
+		size = 0
+		for l, containers := range lists {
+			for i := range *containers {
+				if applicationProfileContainerSize((*containers)[i]) > budget {
+					src := sources[l][i]
+					var level int
+					(*containers)[i], level = degradeUntilFits(func(level int) softwarecomposition.ApplicationProfileContainer {
+						return deflateContainer(src.container, src.sbomSet, level)
+					}, applicationProfileContainerSize, budget)
+					logger.L().Debug("degraded container profile", loggerhelpers.String("name", profile.Name), loggerhelpers.String("namespace", profile.Namespace),
+						loggerhelpers.String("container", src.container.Name), loggerhelpers.Int("level", level))
+					degradationLevel = max(degradationLevel, level)
 				}
-			} else {
-				logger.L().Debug("failed to get sbom name", loggerhelpers.Error(err), loggerhelpers.String("imageTag", container.ImageTag), loggerhelpers.String("imageID", container.ImageID))
+				size += applicationProfileContainerSize((*containers)[i])
 			}
-			containers[i] = deflateApplicationProfileContainer(container, sbomSet, a.effectiveCollapseSettings())
-			size += len(containers[i].Execs)
-			size += len(containers[i].Opens)
-			size += len(containers[i].Syscalls)
-			size += len(containers[i].Capabilities)
-			size += len(containers[i].Endpoints)
-			size += len(containers[i].IdentifiedCallStacks)
 		}
-		return containers
 	}
 
-	// Use the function for InitContainers, EphemeralContainers and Containers
-	profile.Spec.EphemeralContainers = processContainers(profile.Spec.EphemeralContainers)
-	profile.Spec.InitContainers = processContainers(profile.Spec.InitContainers)
-	profile.Spec.Containers = processContainers(profile.Spec.Containers)
-
 	profile.Spec.Architectures = DeflateSortString(profile.Spec.Architectures)
 
 	// check the size of the profile
-	if size > a.maxApplicationProfileSize {
-		return fmt.Errorf("application profile size exceeds the limit of %d: %w", a.maxApplicationProfileSize, ObjectTooLargeError)
+	if size > maxSize {
+		return fmt.Errorf("application profile size exceeds the limit of %d: %w", maxSize, ObjectTooLargeError)
 	}
 
 	// make sure annotations are initialized
@@ -130,9 +190,43 @@ func (a *ApplicationProfileProcessor) PreSave(ctx context.Context, object runtim
 		profile.Annotations = make(map[string]string)
 	}
 	profile.Annotations[helpers.ResourceSizeMetadataKey] = strconv.Itoa(size)
+	recordDegradationLevel(profile, degradationLevel)
+	recordCollapseConfiguration(profile, collapseConfiguration)
+	if a.guardrails != nil {
+		if err := applyGuardrails(a.guardrails(), profile); err != nil {
+			logger.L().Warning("ApplicationProfileProcessor.PreSave - guardrails evaluation failed", loggerhelpers.Error(err))
+		}
+	}
 	return nil
 }
 
+// sbomSet returns the files of the SBOM of the image of container, nil if
+// there is none.
+func (a *ApplicationProfileProcessor) sbomSet(ctx context.Context, container softwarecomposition.ApplicationProfileContainer) mapset.Set[string] {
+	sbomName, err := names.ImageInfoToSlug(container.ImageTag, container.ImageID)
+	if err != nil {
+		logger.L().Debug("failed to get sbom name", loggerhelpers.Error(err), loggerhelpers.String("imageTag", container.ImageTag), loggerhelpers.String("imageID", container.ImageID))
+		return nil
+	}
+	key := K8sKeysToPath("", "spdx.softwarecomposition.kubescape.io", "sbomsyft", "", a.defaultNamespace, sbomName)
+	sbom, err := a.storageImpl.GetSbom(ctx, key)
+	if err != nil {
+		logger.L().Debug("failed to get sbom", loggerhelpers.Error(err), loggerhelpers.String("key", key))
+		return nil
+	}
+	sbomSet := mapset.NewSet[string]()
+	for _, f := range sbom.Spec.Syft.Files {
+		sbomSet.Add(f.Location.RealPath)
+	}
+	return sbomSet
+}
+
+// applicationProfileContainerSize is the number of entries of container
+// counted against the size limit of its profile.
+func applicationProfileContainerSize(container softwarecomposition.ApplicationProfileContainer) int {
+	return len(container.Execs) + len(container.Opens) + len(container.Syscalls) + len(container.Capabilities) + len(container.Endpoints) + len(container.IdentifiedCallStacks)
+}
+
 func (a *ApplicationProfileProcessor) SetStorage(containerProfileStorage ContainerProfileStorage) {
 	a.storageImpl = containerProfileStorage
 }
@@ -143,13 +237,13 @@ func deflateApplicationProfileContainer(container softwarecomposition.Applicatio
 		logger.L().Debug("falling back to DeflateStringer for opens", loggerhelpers.Error(err))
 		opens = DeflateStringer(container.Opens)
 	}
-	endpoints := dynamicpathdetector.AnalyzeEndpoints(&container.Endpoints, dynamicpathdetector.NewPathAnalyzerWithConfigs(settings.EndpointDynamicThreshold, settings.CollapseConfigs))
+	endpoints := dynamicpathdetector.AnalyzeEndpoints(&container.Endpoints, dynamicpathdetector.NewPathAnalyzerWithConfigs(settings.EndpointDynamicThreshold, settings.CollapseConfigs).WithHeaderAllowlist(settings.HeaderAllowlist))
 	identifiedCallStacks := callstack.UnifyIdentifiedCallStacks(container.IdentifiedCallStacks)
 
 	return softwarecomposition.ApplicationProfileContainer{
 		Name:                 container.Name,
 		Capabilities:         DeflateSortString(container.Capabilities),
-		Execs:                DeflateStringer(container.Execs),
+		Execs:                dynamicpathdetector.AnalyzeExecs(container.Execs, settings.ExecArgsCollapseThreshold, settings.EnvCollapseThreshold),
 		Opens:                opens,
 		Syscalls:             DeflateSortString(container.Syscalls),
 		SeccompProfile:       container.SeccompProfile,
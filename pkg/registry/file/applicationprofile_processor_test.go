@@ -299,6 +299,26 @@ func TestDeflateApplicationProfileContainer_CollapsesManyOpens(t *testing.T) {
 	}
 }
 
+func TestDeflateApplicationProfileContainer_CollapsesCronExecs(t *testing.T) {
+	var execs []softwarecomposition.ExecCalls
+	for i := 0; i <= dynamicpathdetector.ExecArgsCollapseThreshold; i++ {
+		execs = append(execs, softwarecomposition.ExecCalls{
+			Path: "/usr/local/bin/report",
+			Args: []string{"--at", fmt.Sprintf("1700000%03d", i)},
+			Envs: []string{"TZ=UTC", fmt.Sprintf("JOB_ID=%d", i)},
+		})
+	}
+	container := softwarecomposition.ApplicationProfileContainer{Name: "cron", Execs: execs}
+
+	result := deflateApplicationProfileContainer(container, nil, dynamicpathdetector.DefaultCollapseSettings())
+
+	assert.Equal(t, []softwarecomposition.ExecCalls{{
+		Path: "/usr/local/bin/report",
+		Args: []string{"--at", dynamicpathdetector.DynamicIdentifier},
+		Envs: []string{"TZ=UTC", "JOB_ID"},
+	}}, result.Execs)
+}
+
 func TestDeflateApplicationProfileContainer_SbomPathsPreserved(t *testing.T) {
 	numOpens := openThreshold() + 1
 	opens := generateSOOpens(numOpens)
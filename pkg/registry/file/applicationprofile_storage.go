@@ -51,14 +51,32 @@ func (a ApplicationProfileStorage) Create(ctx context.Context, key string, obj,
 }
 
 func (a ApplicationProfileStorage) Delete(ctx context.Context, key string, out runtime.Object, preconditions *storage.Preconditions, validateDeletion storage.ValidateObjectFunc, cachedExistingObject runtime.Object, opts storage.DeleteOptions) error {
+	if err := deleteApprovedRevision(ctx, a.realStore, key, &softwarecomposition.ApplicationProfile{}); err != nil {
+		return err
+	}
 	return a.realStore.Delete(ctx, key, out, preconditions, validateDeletion, cachedExistingObject, opts)
 }
 
+// Watch sends the approved revisions of the profiles, see serveApprovedWatch.
 func (a ApplicationProfileStorage) Watch(ctx context.Context, key string, opts storage.ListOptions) (watch.Interface, error) {
-	return a.realStore.Watch(ctx, key, opts)
+	w, err := a.realStore.Watch(ctx, key, opts)
+	if err != nil {
+		return nil, err
+	}
+	return serveApprovedWatch(ctx, a.realStore, key, opts, w), nil
 }
 
+// Get returns the approved revision of the profile, if any, see
+// serveApprovedRevision.
 func (a ApplicationProfileStorage) Get(ctx context.Context, key string, opts storage.GetOptions, objPtr runtime.Object) error {
+	if err := a.get(ctx, key, opts, objPtr); err != nil {
+		return err
+	}
+	return serveApprovedRevision(ctx, a.realStore, key, objPtr)
+}
+
+// get returns the pending revision of the profile, with the containers of its parts.
+func (a ApplicationProfileStorage) get(ctx context.Context, key string, opts storage.GetOptions, objPtr runtime.Object) error {
 	if err := a.realStore.Get(ctx, key, opts, objPtr); err != nil {
 		return err
 	}
@@ -109,15 +127,19 @@ func (a ApplicationProfileStorage) Get(ctx context.Context, key string, opts sto
 	return nil
 }
 
+// GetList lists the approved revisions of the profiles, see serveApprovedList.
 func (a ApplicationProfileStorage) GetList(ctx context.Context, key string, opts storage.ListOptions, listObj runtime.Object) error {
 	if opts.ResourceVersion == softwarecomposition.ResourceVersionFullSpec {
 		return fmt.Errorf("GetList with %s is not allowed for ApplicationProfiles", softwarecomposition.ResourceVersionFullSpec)
 	}
-	return a.realStore.GetList(ctx, key, opts, listObj)
+	if err := a.realStore.GetList(ctx, key, opts, listObj); err != nil {
+		return err
+	}
+	return serveApprovedList(ctx, a.realStore, key, opts, listObj)
 }
 
 func (a ApplicationProfileStorage) GuaranteedUpdate(ctx context.Context, key string, destination runtime.Object, ignoreNotFound bool, preconditions *storage.Preconditions, tryUpdate storage.UpdateFunc, cachedExistingObject runtime.Object) error {
-	return a.realStore.GuaranteedUpdate(ctx, key, destination, ignoreNotFound, preconditions, tryUpdate, cachedExistingObject)
+	return guaranteedUpdateWithApproval(ctx, a.realStore, key, a.Get, destination, ignoreNotFound, preconditions, tryUpdate, cachedExistingObject)
 }
 
 func (a ApplicationProfileStorage) ReadinessCheck() error {
@@ -0,0 +1,366 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kubescape/go-logger"
+	loggerhelpers "github.com/kubescape/go-logger/helpers"
+	helpersv1 "github.com/kubescape/k8s-interface/instanceidhandler/v1/helpers"
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/apiserver/pkg/storage"
+)
+
+// A reviewer approves a revision of an ApplicationProfile or a ContainerProfile
+// by setting ApprovedChecksumAnnotation to its SyncChecksumMetadataKey. Storage
+// then keeps a frozen copy of that revision under ApprovedKeyFor and serves
+// its spec, while newer learned data keeps landing in the pending revision.
+// The served profile carries the checksum of the pending revision in
+// PendingChecksumAnnotation, and ApprovalDriftAnnotation when its spec
+// differs from the approved one. Setting ApprovedChecksumAnnotation to an
+// empty value withdraws the approval.
+const (
+	ApprovedChecksumAnnotation = "kubescape.io/approved-checksum"
+	PendingChecksumAnnotation  = "kubescape.io/pending-checksum"
+	ApprovalDriftAnnotation    = "kubescape.io/approval-drift"
+
+	approvedKindSuffix = "-approved"
+)
+
+// Kinds of the approved revisions, see ApprovedKeyFor.
+const (
+	ApplicationProfileApprovedKind = "applicationprofiles" + approvedKindSuffix
+	ContainerProfileApprovedKind   = ContainerProfileKind + approvedKindSuffix
+)
+
+// ApprovedKeyFor returns the key of the approved revision of the profile at
+// key, the kind segment gets the "-approved" suffix.
+func ApprovedKeyFor(key string) string {
+	_, _, resource, _, _, _ := K8sPathToKeys(key)
+	if resource == "" {
+		return key
+	}
+	return strings.Replace(key, "/"+resource+"/", "/"+resource+approvedKindSuffix+"/", 1)
+}
+
+type pendingRevisionKey struct{}
+
+// WithPendingRevision returns a context reading the pending revision of
+// approved profiles instead of the approved one.
+func WithPendingRevision(ctx context.Context) context.Context {
+	return context.WithValue(ctx, pendingRevisionKey{}, true)
+}
+
+func isPendingRevision(ctx context.Context) bool {
+	pending, _ := ctx.Value(pendingRevisionKey{}).(bool)
+	return pending
+}
+
+// serveApprovedRevision replaces the spec of the pending revision in obj by
+// the one of its approved revision, unless ctx asks for the pending revision.
+// The metadata stays the one of the pending revision, so that updates apply
+// to it, except for the sync checksum which becomes the approved one: a
+// consumer only sees a change once a new revision is approved. A missing or
+// mismatching approved revision is an error, rather than serving the pending
+// one in its place.
+func serveApprovedRevision(ctx context.Context, store storage.Interface, key string, obj runtime.Object) error {
+	if isPendingRevision(ctx) {
+		return nil
+	}
+	pending, ok := obj.(metav1.Object)
+	if !ok {
+		return nil
+	}
+	checksum := pending.GetAnnotations()[ApprovedChecksumAnnotation]
+	if checksum == "" {
+		return nil
+	}
+	approved := obj.DeepCopyObject()
+	if err := runtime.SetZeroValue(approved); err != nil {
+		return fmt.Errorf("reset object before approved read: %w", err)
+	}
+	if err := store.Get(ctx, ApprovedKeyFor(key), storage.GetOptions{}, approved); err != nil {
+		if storage.IsNotFound(err) {
+			return apierrors.NewInternalError(fmt.Errorf("approved revision %s of %s is missing, approve it again or withdraw the approval", checksum, key))
+		}
+		return err
+	}
+	if frozen := approved.(metav1.Object).GetAnnotations()[ApprovedChecksumAnnotation]; frozen != checksum {
+		return apierrors.NewInternalError(fmt.Errorf("approved revision of %s is %s instead of %s", key, frozen, checksum))
+	}
+	drift, err := swapSpec(obj, approved)
+	if err != nil {
+		return err
+	}
+	setApprovalAnnotations(pending, drift)
+	return nil
+}
+
+// setApprovalAnnotations sets the annotations of an approved profile served
+// with its approved revision, drift telling whether the pending spec differs.
+func setApprovalAnnotations(pending metav1.Object, drift bool) {
+	annotations := pending.GetAnnotations()
+	annotations[PendingChecksumAnnotation] = annotations[helpersv1.SyncChecksumMetadataKey]
+	annotations[helpersv1.SyncChecksumMetadataKey] = annotations[ApprovedChecksumAnnotation]
+	if drift {
+		annotations[ApprovalDriftAnnotation] = "true"
+	} else {
+		delete(annotations, ApprovalDriftAnnotation)
+	}
+}
+
+// serveApprovedMetadata sets the annotations of an approved profile listed or
+// watched without its spec, the checksums telling whether the spec drifted.
+func serveApprovedMetadata(obj runtime.Object) {
+	pending, ok := obj.(metav1.Object)
+	if !ok {
+		return
+	}
+	annotations := pending.GetAnnotations()
+	if annotations[ApprovedChecksumAnnotation] == "" {
+		return
+	}
+	setApprovalAnnotations(pending, annotations[ApprovedChecksumAnnotation] != annotations[helpersv1.SyncChecksumMetadataKey])
+}
+
+// serveApprovedList serves the approved revisions of the profiles of listObj,
+// listed under key. Lists only carry the specs with ResourceVersionFullSpec,
+// otherwise only the annotations are served.
+func serveApprovedList(ctx context.Context, store storage.Interface, key string, opts storage.ListOptions, listObj runtime.Object) error {
+	if isPendingRevision(ctx) {
+		return nil
+	}
+	items, err := meta.ExtractList(listObj)
+	if err != nil {
+		return fmt.Errorf("extract list: %w", err)
+	}
+	prefix, root, resource, _, _, _ := K8sPathToKeys(key)
+	for _, item := range items {
+		if opts.ResourceVersion != softwarecomposition.ResourceVersionFullSpec {
+			serveApprovedMetadata(item)
+			continue
+		}
+		accessor, err := meta.Accessor(item)
+		if err != nil {
+			return err
+		}
+		if err := serveApprovedRevision(ctx, store, K8sKeysToPath(prefix, root, resource, "", accessor.GetNamespace(), accessor.GetName()), item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// serveApprovedWatch serves the approved revisions of the profiles sent by w,
+// watching key, like serveApprovedList. An approved revision which cannot be
+// read is logged, the profile being sent with the annotations only.
+func serveApprovedWatch(ctx context.Context, store storage.Interface, key string, opts storage.ListOptions, w watch.Interface) watch.Interface {
+	prefix, root, resource, _, _, _ := K8sPathToKeys(key)
+	return watch.Filter(w, func(ev watch.Event) (watch.Event, bool) {
+		if ev.Type != watch.Added && ev.Type != watch.Modified {
+			return ev, true
+		}
+		accessor, err := meta.Accessor(ev.Object)
+		if err != nil || accessor.GetAnnotations()[ApprovedChecksumAnnotation] == "" {
+			return ev, true
+		}
+		// the object is shared with the other watchers
+		obj := ev.Object.DeepCopyObject()
+		if opts.ResourceVersion != softwarecomposition.ResourceVersionFullSpec {
+			serveApprovedMetadata(obj)
+		} else if err := serveApprovedRevision(ctx, store, K8sKeysToPath(prefix, root, resource, "", accessor.GetNamespace(), accessor.GetName()), obj); err != nil {
+			logger.L().Warning("serveApprovedWatch - serve approved revision failed", loggerhelpers.Error(err), loggerhelpers.String("name", accessor.GetName()), loggerhelpers.String("namespace", accessor.GetNamespace()))
+			obj = ev.Object.DeepCopyObject()
+			serveApprovedMetadata(obj)
+		}
+		ev.Object = obj
+		return ev, true
+	})
+}
+
+// swapSpec sets the spec of approved into obj and reports whether they differed.
+func swapSpec(obj, approved runtime.Object) (bool, error) {
+	switch o := obj.(type) {
+	case *softwarecomposition.ApplicationProfile:
+		a := approved.(*softwarecomposition.ApplicationProfile)
+		drift := !apiequality.Semantic.DeepEqual(o.Spec, a.Spec)
+		o.Spec = a.Spec
+		return drift, nil
+	case *softwarecomposition.ContainerProfile:
+		a := approved.(*softwarecomposition.ContainerProfile)
+		drift := !apiequality.Semantic.DeepEqual(o.Spec, a.Spec)
+		o.Spec = a.Spec
+		return drift, nil
+	default:
+		return false, fmt.Errorf("profiles of type %T cannot be approved", obj)
+	}
+}
+
+// errPendingRevisionNeeded aborts an update approving a revision until the
+// pending revision it is checked against has been read, which cannot be done
+// under the lock of the update.
+var errPendingRevisionNeeded = errors.New("approval needs the pending revision")
+
+// guaranteedUpdateWithApproval runs GuaranteedUpdate on store and handles the
+// changes of ApprovedChecksumAnnotation made by tryUpdate: an update which
+// does not set it keeps the current approval, an empty value withdraws it and
+// a new checksum freezes the pending revision read by getPending, provided
+// the checksum is the one of that revision.
+//
+// The pending revision is frozen before the approval is committed, so that
+// the approval is never served without it, and the previous approved revision
+// is restored when the approval is not committed.
+func guaranteedUpdateWithApproval(ctx context.Context, store StorageQuerier, key string, getPending func(ctx context.Context, key string, opts storage.GetOptions, objPtr runtime.Object) error,
+	destination runtime.Object, ignoreNotFound bool, preconditions *storage.Preconditions, tryUpdate storage.UpdateFunc, cachedExistingObject runtime.Object) error {
+	var pending runtime.Object
+	var approving string
+	var approve, withdraw bool
+	approvalUpdate := func(input runtime.Object, res storage.ResponseMeta) (runtime.Object, *uint64, error) {
+		approve, withdraw = false, false
+		var before string
+		if in, ok := input.(metav1.Object); ok {
+			before = in.GetAnnotations()[ApprovedChecksumAnnotation]
+		}
+		output, ttl, err := tryUpdate(input, res)
+		if err != nil {
+			return output, ttl, err
+		}
+		out, ok := output.(metav1.Object)
+		if !ok {
+			return output, ttl, nil
+		}
+		// served annotations sent back by clients are not stored
+		delete(out.GetAnnotations(), PendingChecksumAnnotation)
+		delete(out.GetAnnotations(), ApprovalDriftAnnotation)
+		after, set := out.GetAnnotations()[ApprovedChecksumAnnotation]
+		switch {
+		case !set:
+			if before != "" {
+				annotations := out.GetAnnotations()
+				if annotations == nil {
+					annotations = map[string]string{}
+				}
+				annotations[ApprovedChecksumAnnotation] = before
+				out.SetAnnotations(annotations)
+			}
+		case after == "":
+			annotations := out.GetAnnotations()
+			delete(annotations, ApprovedChecksumAnnotation)
+			out.SetAnnotations(annotations)
+			withdraw = before != ""
+		case after != before:
+			if pending == nil || after != approving {
+				approving = after
+				return nil, nil, errPendingRevisionNeeded
+			}
+			approve = true
+		}
+		return output, ttl, nil
+	}
+
+	err := store.GuaranteedUpdate(ctx, key, destination, ignoreNotFound, preconditions, approvalUpdate, cachedExistingObject)
+	if !errors.Is(err, errPendingRevisionNeeded) {
+		if err == nil && withdraw {
+			return deleteApprovedRevision(ctx, store, key, destination.DeepCopyObject())
+		}
+		return err
+	}
+
+	pending = destination.DeepCopyObject()
+	if err := runtime.SetZeroValue(pending); err != nil {
+		return fmt.Errorf("reset object before pending read: %w", err)
+	}
+	if err := getPending(WithPendingRevision(ctx), key, storage.GetOptions{}, pending); err != nil {
+		return err
+	}
+	if checksum := pending.(metav1.Object).GetAnnotations()[helpersv1.SyncChecksumMetadataKey]; checksum != approving {
+		_, _, resource, _, _, name := K8sPathToKeys(key)
+		return apierrors.NewConflict(softwarecomposition.Resource(resource), name,
+			fmt.Errorf("checksum %s is not the one of the pending revision, %s", approving, checksum))
+	}
+	previous, err := getApprovedRevision(ctx, store, key, destination)
+	if err != nil {
+		return err
+	}
+	if err := saveApprovedRevision(ctx, store, key, pending); err != nil {
+		return err
+	}
+	err = store.GuaranteedUpdate(ctx, key, destination, ignoreNotFound, preconditions, approvalUpdate, nil)
+	switch {
+	case err == nil && approve:
+		logger.L().Debug("approved profile revision", loggerhelpers.String("key", key), loggerhelpers.String("checksum", approving))
+		return nil
+	case err == nil && withdraw:
+		return deleteApprovedRevision(ctx, store, key, destination.DeepCopyObject())
+	}
+	// the approval was not committed, nor the frozen revision can be kept
+	if restoreErr := restoreApprovedRevision(ctx, store, key, previous, destination); restoreErr != nil {
+		return errors.Join(err, restoreErr)
+	}
+	if errors.Is(err, errPendingRevisionNeeded) {
+		// approving yet another checksum, the pending revision changed meanwhile
+		_, _, resource, _, _, name := K8sPathToKeys(key)
+		return apierrors.NewConflict(softwarecomposition.Resource(resource), name, errors.New("the profile changed during its approval"))
+	}
+	return err
+}
+
+// getApprovedRevision returns the approved revision of key, nil if there is none.
+func getApprovedRevision(ctx context.Context, store storage.Interface, key string, like runtime.Object) (runtime.Object, error) {
+	approved := like.DeepCopyObject()
+	if err := runtime.SetZeroValue(approved); err != nil {
+		return nil, fmt.Errorf("reset object before approved read: %w", err)
+	}
+	if err := store.Get(ctx, ApprovedKeyFor(key), storage.GetOptions{}, approved); err != nil {
+		if storage.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get approved revision: %w", err)
+	}
+	return approved, nil
+}
+
+// saveApprovedRevision stores revision as the approved revision of key. The
+// revision already went through the processor, it is stored as it is.
+func saveApprovedRevision(ctx context.Context, store StorageQuerier, key string, revision runtime.Object) error {
+	frozen := revision.DeepCopyObject()
+	meta := frozen.(metav1.Object)
+	annotations := meta.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[ApprovedChecksumAnnotation] = annotations[helpersv1.SyncChecksumMetadataKey]
+	meta.SetAnnotations(annotations)
+	if err := store.SaveUnprocessed(ctx, ApprovedKeyFor(key), frozen); err != nil {
+		return fmt.Errorf("save approved revision: %w", err)
+	}
+	return nil
+}
+
+// restoreApprovedRevision puts back previous as the approved revision of key,
+// or deletes it if there was none.
+func restoreApprovedRevision(ctx context.Context, store StorageQuerier, key string, previous, like runtime.Object) error {
+	if previous == nil {
+		return deleteApprovedRevision(ctx, store, key, like.DeepCopyObject())
+	}
+	if err := store.SaveUnprocessed(ctx, ApprovedKeyFor(key), previous); err != nil {
+		return fmt.Errorf("restore approved revision: %w", err)
+	}
+	return nil
+}
+
+// deleteApprovedRevision deletes the approved revision of key, if any.
+func deleteApprovedRevision(ctx context.Context, store storage.Interface, key string, out runtime.Object) error {
+	if err := store.Delete(ctx, ApprovedKeyFor(key), out, nil, storage.ValidateAllObjectFunc, nil, storage.DeleteOptions{}); err != nil && !storage.IsNotFound(err) {
+		return fmt.Errorf("delete approved revision: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package file
+
+import (
+	"context"
+	"testing"
+
+	helpersv1 "github.com/kubescape/k8s-interface/instanceidhandler/v1/helpers"
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"github.com/kubescape/storage/pkg/config"
+	"github.com/kubescape/storage/pkg/generated/clientset/versioned/scheme"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/storage"
+)
+
+func TestApprovedKeyFor(t *testing.T) {
+	assert.Equal(t, "/spdx.softwarecomposition.kubescape.io/applicationprofiles-approved/default/nginx",
+		ApprovedKeyFor("/spdx.softwarecomposition.kubescape.io/applicationprofiles/default/nginx"))
+	assert.Equal(t, "/spdx.softwarecomposition.kubescape.io/containerprofile-approved/default/nginx",
+		ApprovedKeyFor("/spdx.softwarecomposition.kubescape.io/containerprofile/default/nginx"))
+}
+
+func TestApprovalWorkflow(t *testing.T) {
+	pool := NewTestPool(t.TempDir())
+	require.NotNil(t, pool)
+	defer func() { _ = pool.Close() }()
+	sch := scheme.Scheme
+	require.NoError(t, softwarecomposition.AddToScheme(sch))
+	fs := afero.NewMemMapFs()
+	ctx := context.TODO()
+
+	realStore := NewStorageImpl(fs, DefaultStorageRoot, pool, nil, sch)
+	processor := NewApplicationProfileProcessor(config.Config{MaxApplicationProfileSize: 100})
+	apStore := NewApplicationProfileStorage(NewStorageImplWithCollector(fs, DefaultStorageRoot, pool, nil, sch, processor))
+	key := "/spdx.softwarecomposition.kubescape.io/applicationprofiles/default/nginx"
+
+	get := func(ctx context.Context) *softwarecomposition.ApplicationProfile {
+		ap := &softwarecomposition.ApplicationProfile{}
+		require.NoError(t, apStore.Get(ctx, key, storage.GetOptions{}, ap))
+		return ap
+	}
+	// update runs an update the way node-agent does, without the annotations it does not know about
+	update := func(syscalls ...string) {
+		require.NoError(t, apStore.GuaranteedUpdate(ctx, key, &softwarecomposition.ApplicationProfile{}, false, nil, func(input runtime.Object, _ storage.ResponseMeta) (runtime.Object, *uint64, error) {
+			ap := input.DeepCopyObject().(*softwarecomposition.ApplicationProfile)
+			ap.Annotations = map[string]string{}
+			ap.Spec.Containers[0].Syscalls = syscalls
+			return ap, nil, nil
+		}, nil))
+	}
+	approve := func(checksum string) error {
+		return apStore.GuaranteedUpdate(ctx, key, &softwarecomposition.ApplicationProfile{}, false, nil, func(input runtime.Object, _ storage.ResponseMeta) (runtime.Object, *uint64, error) {
+			ap := input.DeepCopyObject().(*softwarecomposition.ApplicationProfile)
+			ap.Annotations[ApprovedChecksumAnnotation] = checksum
+			return ap, nil, nil
+		}, nil)
+	}
+	syscalls := func(ap *softwarecomposition.ApplicationProfile) []string {
+		return ap.Spec.Containers[0].Syscalls
+	}
+
+	require.NoError(t, apStore.Create(ctx, key, &softwarecomposition.ApplicationProfile{
+		ObjectMeta: v1.ObjectMeta{Name: "nginx", Namespace: "default"},
+		Spec: softwarecomposition.ApplicationProfileSpec{
+			Containers: []softwarecomposition.ApplicationProfileContainer{{Name: "app", Syscalls: []string{"open"}}},
+		},
+	}, nil, 0))
+	approved := get(ctx).Annotations[helpersv1.SyncChecksumMetadataKey]
+	require.NotEmpty(t, approved)
+
+	// only the checksum of the pending revision can be approved
+	err := approve("unknown")
+	assert.True(t, apierrors.IsConflict(err), err)
+	require.NoError(t, approve(approved))
+	ap := get(ctx)
+	assert.Equal(t, approved, ap.Annotations[ApprovedChecksumAnnotation])
+	assert.Equal(t, approved, ap.Annotations[helpersv1.SyncChecksumMetadataKey])
+	assert.Empty(t, ap.Annotations[ApprovalDriftAnnotation])
+
+	// newer learned data stays pending and keeps the approval
+	update("open", "read")
+	ap = get(ctx)
+	assert.Equal(t, []string{"open"}, syscalls(ap))
+	assert.Equal(t, approved, ap.Annotations[ApprovedChecksumAnnotation])
+	assert.Equal(t, approved, ap.Annotations[helpersv1.SyncChecksumMetadataKey])
+	assert.Equal(t, "true", ap.Annotations[ApprovalDriftAnnotation])
+	pending := get(WithPendingRevision(ctx))
+	assert.Equal(t, []string{"open", "read"}, syscalls(pending))
+	assert.Equal(t, pending.Annotations[helpersv1.SyncChecksumMetadataKey], ap.Annotations[PendingChecksumAnnotation])
+	assert.Empty(t, pending.Annotations[ApprovalDriftAnnotation])
+
+	// approving the pending revision serves it
+	require.NoError(t, approve(pending.Annotations[helpersv1.SyncChecksumMetadataKey]))
+	ap = get(ctx)
+	assert.Equal(t, []string{"open", "read"}, syscalls(ap))
+	assert.Empty(t, ap.Annotations[ApprovalDriftAnnotation])
+
+	// withdrawing the approval serves the pending revision again
+	update("open", "read", "write")
+	require.NoError(t, approve(""))
+	ap = get(ctx)
+	assert.Equal(t, []string{"open", "read", "write"}, syscalls(ap))
+	assert.NotContains(t, ap.Annotations, ApprovedChecksumAnnotation)
+	err = realStore.Get(ctx, ApprovedKeyFor(key), storage.GetOptions{}, &softwarecomposition.ApplicationProfile{})
+	assert.True(t, storage.IsNotFound(err))
+
+	// deleting the profile deletes its approved revision
+	require.NoError(t, approve(get(ctx).Annotations[helpersv1.SyncChecksumMetadataKey]))
+	require.NoError(t, realStore.Get(ctx, ApprovedKeyFor(key), storage.GetOptions{}, &softwarecomposition.ApplicationProfile{}))
+	require.NoError(t, apStore.Delete(ctx, key, &softwarecomposition.ApplicationProfile{}, nil, storage.ValidateAllObjectFunc, nil, storage.DeleteOptions{}))
+	err = realStore.Get(ctx, ApprovedKeyFor(key), storage.GetOptions{}, &softwarecomposition.ApplicationProfile{})
+	assert.True(t, storage.IsNotFound(err))
+}
+
+// countingProcessor counts the objects going through PreSave.
+type countingProcessor struct {
+	DefaultProcessor
+	preSaves int
+}
+
+func (p *countingProcessor) PreSave(_ context.Context, _ runtime.Object) error {
+	p.preSaves++
+	return nil
+}
+
+func TestApprovalServing(t *testing.T) {
+	pool := NewTestPool(t.TempDir())
+	require.NotNil(t, pool)
+	defer func() { _ = pool.Close() }()
+	sch := scheme.Scheme
+	require.NoError(t, softwarecomposition.AddToScheme(sch))
+	fs := afero.NewMemMapFs()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	processor := &countingProcessor{}
+	realStore := NewStorageImplWithCollector(fs, DefaultStorageRoot, pool, NewWatchDispatcher(), sch, processor)
+	apStore := NewApplicationProfileStorage(realStore)
+	listKey := "/spdx.softwarecomposition.kubescape.io/applicationprofiles"
+	key := listKey + "/default/nginx"
+
+	require.NoError(t, apStore.Create(ctx, key, &softwarecomposition.ApplicationProfile{
+		ObjectMeta: v1.ObjectMeta{Name: "nginx", Namespace: "default"},
+		Spec: softwarecomposition.ApplicationProfileSpec{
+			Containers: []softwarecomposition.ApplicationProfileContainer{{Name: "app", Syscalls: []string{"open"}}},
+		},
+	}, nil, 0))
+
+	checksum := func() string {
+		ap := &softwarecomposition.ApplicationProfile{}
+		require.NoError(t, apStore.Get(WithPendingRevision(ctx), key, storage.GetOptions{}, ap))
+		return ap.Annotations[helpersv1.SyncChecksumMetadataKey]
+	}
+	first := checksum()
+
+	// the approved revision is frozen without going through the processor again
+	processor.preSaves = 0
+	require.NoError(t, apStore.GuaranteedUpdate(ctx, key, &softwarecomposition.ApplicationProfile{}, false, nil, func(input runtime.Object, _ storage.ResponseMeta) (runtime.Object, *uint64, error) {
+		ap := input.DeepCopyObject().(*softwarecomposition.ApplicationProfile)
+		ap.Annotations[ApprovedChecksumAnnotation] = first
+		return ap, nil, nil
+	}, nil))
+	// once for the update and once for its no-change check, none for the frozen copy
+	assert.Equal(t, 2, processor.preSaves)
+
+	w, err := apStore.Watch(ctx, listKey, storage.ListOptions{Predicate: storage.Everything})
+	require.NoError(t, err)
+	defer w.Stop()
+	require.NoError(t, apStore.GuaranteedUpdate(ctx, key, &softwarecomposition.ApplicationProfile{}, false, nil, func(input runtime.Object, _ storage.ResponseMeta) (runtime.Object, *uint64, error) {
+		ap := input.DeepCopyObject().(*softwarecomposition.ApplicationProfile)
+		ap.Annotations = map[string]string{}
+		ap.Spec.Containers[0].Syscalls = []string{"open", "read"}
+		return ap, nil, nil
+	}, nil))
+
+	second := checksum()
+	require.NotEqual(t, first, second)
+
+	// lists and watches serve the approved revision too
+	list := &softwarecomposition.ApplicationProfileList{}
+	require.NoError(t, apStore.GetList(ctx, listKey, storage.ListOptions{Predicate: storage.Everything}, list))
+	require.Len(t, list.Items, 1)
+	assert.Equal(t, first, list.Items[0].Annotations[helpersv1.SyncChecksumMetadataKey])
+	assert.Equal(t, second, list.Items[0].Annotations[PendingChecksumAnnotation])
+	assert.Equal(t, "true", list.Items[0].Annotations[ApprovalDriftAnnotation])
+	ev := <-w.ResultChan()
+	ap, ok := ev.Object.(*softwarecomposition.ApplicationProfile)
+	require.True(t, ok)
+	assert.Equal(t, first, ap.Annotations[helpersv1.SyncChecksumMetadataKey])
+	assert.Equal(t, "true", ap.Annotations[ApprovalDriftAnnotation])
+
+	// a missing approved revision is an error, not the pending revision
+	require.NoError(t, realStore.Delete(ctx, ApprovedKeyFor(key), &softwarecomposition.ApplicationProfile{}, nil, storage.ValidateAllObjectFunc, nil, storage.DeleteOptions{}))
+	err = apStore.Get(ctx, key, storage.GetOptions{}, &softwarecomposition.ApplicationProfile{})
+	assert.True(t, apierrors.IsInternalError(err), err)
+}
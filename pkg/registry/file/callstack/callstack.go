@@ -175,5 +175,95 @@ func UnifyIdentifiedCallStacks(stacks []types.IdentifiedCallStack) []types.Ident
 		})
 	}
 
+	// Sort by CallID so that the result does not depend on map iteration order
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CallID < result[j].CallID
+	})
+
 	return result
 }
+
+// PruneCallStack returns a copy of cs limited to maxDepth frames along every
+// path and to the first maxBreadth children of every node, a limit of zero or
+// less disables it. The children of an empty root are at depth 1. It reports
+// whether any frame was removed.
+func PruneCallStack(cs types.CallStack, maxDepth, maxBreadth int) (types.CallStack, bool) {
+	pruned := false
+	var prune func(node types.CallStackNode, depth int) types.CallStackNode
+	prune = func(node types.CallStackNode, depth int) types.CallStackNode {
+		out := types.CallStackNode{Frame: node.Frame, Children: make([]types.CallStackNode, 0, len(node.Children))}
+		children := node.Children
+		if maxDepth > 0 && depth >= maxDepth {
+			pruned = pruned || len(children) > 0
+			return out
+		}
+		if maxBreadth > 0 && len(children) > maxBreadth {
+			children = children[:maxBreadth]
+			pruned = true
+		}
+		for _, child := range children {
+			out.Children = append(out.Children, prune(child, depth+1))
+		}
+		return out
+	}
+
+	rootDepth := 1
+	if isEmptyFrame(cs.Root.Frame) {
+		rootDepth = 0
+	}
+	return types.CallStack{Root: prune(cs.Root, rootDepth)}, pruned
+}
+
+// PruneIdentifiedCallStacks applies PruneCallStack to every stack, see
+// PruneCallStack for the limits. It reports whether any frame was removed.
+func PruneIdentifiedCallStacks(stacks []types.IdentifiedCallStack, maxDepth, maxBreadth int) ([]types.IdentifiedCallStack, bool) {
+	if maxDepth <= 0 && maxBreadth <= 0 {
+		return stacks, false
+	}
+	pruned := false
+	result := make([]types.IdentifiedCallStack, len(stacks))
+	for i, stack := range stacks {
+		cs, p := PruneCallStack(stack.CallStack, maxDepth, maxBreadth)
+		result[i] = types.IdentifiedCallStack{CallID: stack.CallID, CallStack: cs}
+		pruned = pruned || p
+	}
+	return result, pruned
+}
+
+// Frames returns the distinct non-empty frames of cs, in depth-first order.
+func Frames(cs types.CallStack) []types.StackFrame {
+	var frames []types.StackFrame
+	seen := make(map[string]struct{})
+	var walk func(node types.CallStackNode)
+	walk = func(node types.CallStackNode) {
+		if !isEmptyFrame(node.Frame) {
+			if _, ok := seen[frameKey(node.Frame)]; !ok {
+				seen[frameKey(node.Frame)] = struct{}{}
+				frames = append(frames, node.Frame)
+			}
+		}
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	walk(cs.Root)
+	return frames
+}
+
+// ContainsFrame reports whether one of the frames of cs is frame, comparing
+// FileID and Lineno.
+func ContainsFrame(cs types.CallStack, frame types.StackFrame) bool {
+	var walk func(node types.CallStackNode) bool
+	walk = func(node types.CallStackNode) bool {
+		if !isEmptyFrame(node.Frame) && framesEqual(node.Frame, frame) {
+			return true
+		}
+		for _, child := range node.Children {
+			if walk(child) {
+				return true
+			}
+		}
+		return false
+	}
+	return walk(cs.Root)
+}
@@ -1477,3 +1477,65 @@ func TestUnifyCallStacksEdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestPruneCallStack(t *testing.T) {
+	/*
+	   root
+	    |
+	   1,1
+	   / | \
+	 2,1 2,2 2,3
+	  |
+	 3,1
+	  |
+	 4,1
+	*/
+	unified := UnifyIdentifiedCallStacks([]types.IdentifiedCallStack{
+		buildCallStack("test1", []framePath{
+			{{"1", "1", 0}, {"2", "1", 0}, {"3", "1", 0}, {"4", "1", 0}},
+			{{"1", "1", 0}, {"2", "2", 0}},
+			{{"1", "1", 0}, {"2", "3", 0}},
+		}),
+	})
+	if len(unified) != 1 {
+		t.Fatalf("Expected 1 stack, got %d", len(unified))
+	}
+	cs := unified[0].CallStack
+
+	if _, pruned := PruneCallStack(cs, 0, 0); pruned {
+		t.Error("Expected no pruning without limits")
+	}
+	if _, pruned := PruneCallStack(cs, 4, 3); pruned {
+		t.Error("Expected no pruning within the limits")
+	}
+
+	got, pruned := PruneCallStack(cs, 2, 2)
+	if !pruned {
+		t.Error("Expected the stack to be pruned")
+	}
+	first := got.Root.Children[0]
+	if len(first.Children) != 2 {
+		t.Fatalf("Expected 2 children at depth 2, got %d", len(first.Children))
+	}
+	for _, child := range first.Children {
+		if len(child.Children) != 0 {
+			t.Errorf("Expected no frames below depth 2, got %d under %s", len(child.Children), frameKey(child.Frame))
+		}
+	}
+	// the input is left untouched
+	if len(cs.Root.Children[0].Children) != 3 {
+		t.Error("Expected the input stack not to be modified")
+	}
+
+	if frames := Frames(cs); len(frames) != 6 {
+		t.Errorf("Expected 6 frames, got %d", len(frames))
+	}
+	if frames := Frames(got); len(frames) != 3 {
+		t.Errorf("Expected 3 frames after pruning, got %d", len(frames))
+	}
+
+	stacks, pruned := PruneIdentifiedCallStacks(unified, 1, 0)
+	if !pruned || len(stacks) != 1 || stacks[0].CallID != "test1" || len(Frames(stacks[0].CallStack)) != 1 {
+		t.Errorf("Unexpected pruning result %+v", stacks)
+	}
+}
@@ -0,0 +1,149 @@
+package file
+
+import (
+	"context"
+	"fmt"
+
+	helpersv1 "github.com/kubescape/k8s-interface/instanceidhandler/v1/helpers"
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"github.com/kubescape/storage/pkg/registry/file/callstack"
+	"k8s.io/apimachinery/pkg/runtime"
+	"zombiezen.com/go/sqlite"
+	"zombiezen.com/go/sqlite/sqlitex"
+)
+
+// callStackKinds maps the resources whose call stacks are indexed to their kind.
+var callStackKinds = map[string]string{
+	"applicationprofiles": "ApplicationProfile",
+	ContainerProfileKind:  "ContainerProfile",
+}
+
+type containerCallStacks struct {
+	container string
+	stacks    []softwarecomposition.IdentifiedCallStack
+}
+
+// objectCallStacks returns the call stacks of obj per container. The time
+// series pieces of a ContainerProfile are not indexed, only the consolidated
+// profile they are merged into.
+func objectCallStacks(obj runtime.Object) []containerCallStacks {
+	var out []containerCallStacks
+	switch o := obj.(type) {
+	case *softwarecomposition.ApplicationProfile:
+		for _, containers := range [][]softwarecomposition.ApplicationProfileContainer{o.Spec.Containers, o.Spec.InitContainers, o.Spec.EphemeralContainers} {
+			for _, c := range containers {
+				out = append(out, containerCallStacks{container: c.Name, stacks: c.IdentifiedCallStacks})
+			}
+		}
+	case *softwarecomposition.ContainerProfile:
+		if o.Annotations[helpersv1.ReportSeriesIdMetadataKey] == "" {
+			out = append(out, containerCallStacks{container: o.Labels[helpersv1.ContainerNameMetadataKey], stacks: o.Spec.IdentifiedCallStacks})
+		}
+	}
+	return out
+}
+
+// writeCallStackFrames indexes the frames of the call stacks of the object at
+// path, so that the workloads and CallIDs a frame leads to can be listed per
+// namespace. It is a no-op for kinds without call stacks.
+func writeCallStackFrames(conn *sqlite.Conn, path string, obj runtime.Object) (err error) {
+	_, _, resource, _, namespace, name := K8sPathToKeys(path)
+	kind, ok := callStackKinds[resource]
+	if !ok {
+		return nil
+	}
+	defer sqlitex.Save(conn)(&err)
+	if err := deleteCallStackFrames(conn, path); err != nil {
+		return err
+	}
+	for _, c := range objectCallStacks(obj) {
+		for _, stack := range c.stacks {
+			for _, frame := range callstack.Frames(stack.CallStack) {
+				err := sqlitex.Execute(conn,
+					`INSERT OR IGNORE INTO call_stack_frames
+							(kind, namespace, name, container, call_id, file_id, lineno) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+					&sqlitex.ExecOptions{
+						Args: []any{kind, namespace, name, c.container, string(stack.CallID), frame.FileID, frame.Lineno},
+					})
+				if err != nil {
+					return fmt.Errorf("write call stack frame: %w", err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// deleteCallStackFrames removes the indexed frames of the object at path.
+func deleteCallStackFrames(conn *sqlite.Conn, path string) error {
+	_, _, resource, _, namespace, name := K8sPathToKeys(path)
+	kind, ok := callStackKinds[resource]
+	if !ok {
+		return nil
+	}
+	err := sqlitex.Execute(conn,
+		`DELETE FROM call_stack_frames
+				WHERE kind = ?
+				  AND namespace = ?
+				  AND name = ?`,
+		&sqlitex.ExecOptions{
+			Args: []any{kind, namespace, name},
+		})
+	if err != nil {
+		return fmt.Errorf("delete call stack frames: %w", err)
+	}
+	return nil
+}
+
+// listCallStackReferences returns the indexed frames of namespace, restricted
+// to callID and to frame when they are set.
+func listCallStackReferences(conn *sqlite.Conn, namespace string, callID softwarecomposition.CallID, frame *softwarecomposition.StackFrame) ([]softwarecomposition.CallStackReference, error) {
+	query := `SELECT kind, name, container, call_id, file_id, lineno FROM call_stack_frames
+				WHERE namespace = ?`
+	args := []any{namespace}
+	if callID != "" {
+		query += ` AND call_id = ?`
+		args = append(args, string(callID))
+	}
+	if frame != nil {
+		query += ` AND file_id = ? AND lineno = ?`
+		args = append(args, frame.FileID, frame.Lineno)
+	}
+	query += ` ORDER BY kind, name, container, call_id, file_id, lineno`
+	var references []softwarecomposition.CallStackReference
+	err := sqlitex.Execute(conn, query,
+		&sqlitex.ExecOptions{
+			Args: args,
+			ResultFunc: func(stmt *sqlite.Stmt) error {
+				references = append(references, softwarecomposition.CallStackReference{
+					Kind:          stmt.ColumnText(0),
+					Name:          stmt.ColumnText(1),
+					ContainerName: stmt.ColumnText(2),
+					CallID:        softwarecomposition.CallID(stmt.ColumnText(3)),
+					Frame: softwarecomposition.StackFrame{
+						FileID: stmt.ColumnText(4),
+						Lineno: stmt.ColumnText(5),
+					},
+				})
+				return nil
+			},
+		})
+	if err != nil {
+		return nil, fmt.Errorf("list call stack references: %w", err)
+	}
+	return references, nil
+}
+
+// ListCallStackReferences returns the profiles of namespace whose call stacks
+// go through frame, or lead to callID, with the matching CallIDs and frames.
+// At least one of callID and frame should be set.
+func (s *StorageImpl) ListCallStackReferences(ctx context.Context, namespace string, callID softwarecomposition.CallID, frame *softwarecomposition.StackFrame) ([]softwarecomposition.CallStackReference, error) {
+	poolCtx, cancel := poolContext()
+	defer cancel()
+	conn, err := s.pool.Take(poolCtx)
+	if err != nil {
+		return nil, newContentionTimeoutError("list", namespace, err)
+	}
+	defer s.pool.Put(conn)
+	return listCallStackReferences(conn, namespace, callID, frame)
+}
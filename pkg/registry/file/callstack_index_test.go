@@ -0,0 +1,96 @@
+package file
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"github.com/kubescape/storage/pkg/config"
+	"github.com/kubescape/storage/pkg/generated/clientset/versioned/scheme"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/storage"
+)
+
+// newCallStack returns a single path call stack going through frames, the
+// outermost first.
+func newCallStack(callID softwarecomposition.CallID, frames ...softwarecomposition.StackFrame) softwarecomposition.IdentifiedCallStack {
+	var children []softwarecomposition.CallStackNode
+	for i := len(frames) - 1; i >= 0; i-- {
+		children = []softwarecomposition.CallStackNode{{Frame: frames[i], Children: children}}
+	}
+	return softwarecomposition.IdentifiedCallStack{CallID: callID, CallStack: softwarecomposition.CallStack{Root: softwarecomposition.CallStackNode{Children: children}}}
+}
+
+func TestCallStackIndex(t *testing.T) {
+	pool := NewTestPool(t.TempDir())
+	require.NotNil(t, pool)
+	defer func() { _ = pool.Close() }()
+	sch := scheme.Scheme
+	require.NoError(t, softwarecomposition.AddToScheme(sch))
+	fs := afero.NewMemMapFs()
+	ctx := context.TODO()
+
+	realStore := NewStorageImpl(fs, DefaultStorageRoot, pool, nil, sch)
+	processor := NewApplicationProfileProcessor(config.Config{MaxApplicationProfileSize: 100, CallStackMaxDepth: 2})
+	apStore := NewStorageImplWithCollector(fs, DefaultStorageRoot, pool, nil, sch, processor)
+
+	main := softwarecomposition.StackFrame{FileID: "1", Lineno: "10"}
+	handler := softwarecomposition.StackFrame{FileID: "2", Lineno: "20"}
+	deep := softwarecomposition.StackFrame{FileID: "3", Lineno: "30"}
+	newProfile := func(name string, stacks ...softwarecomposition.IdentifiedCallStack) *softwarecomposition.ApplicationProfile {
+		return &softwarecomposition.ApplicationProfile{
+			ObjectMeta: v1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: softwarecomposition.ApplicationProfileSpec{
+				Containers: []softwarecomposition.ApplicationProfileContainer{{Name: "app", IdentifiedCallStacks: stacks}},
+			},
+		}
+	}
+	keyNginx := "/spdx.softwarecomposition.kubescape.io/applicationprofiles/default/nginx"
+	keyRedis := "/spdx.softwarecomposition.kubescape.io/applicationprofiles/default/redis"
+	require.NoError(t, apStore.Create(ctx, keyNginx, newProfile("nginx", newCallStack("open", main, handler, deep)), nil, 0))
+	require.NoError(t, apStore.Create(ctx, keyRedis, newProfile("redis", newCallStack("exec", main)), nil, 0))
+
+	// the stacks are pruned on save
+	ap := &softwarecomposition.ApplicationProfile{}
+	require.NoError(t, apStore.Get(ctx, keyNginx, storage.GetOptions{}, ap))
+	require.Len(t, ap.Spec.Containers[0].IdentifiedCallStacks, 1)
+	assert.Empty(t, ap.Spec.Containers[0].IdentifiedCallStacks[0].CallStack.Root.Children[0].Children[0].Children)
+
+	// a frame leads to every workload and CallID going through it
+	references, err := realStore.ListCallStackReferences(ctx, "default", "", &main)
+	require.NoError(t, err)
+	assert.Equal(t, []softwarecomposition.CallStackReference{
+		{Kind: "ApplicationProfile", Name: "nginx", ContainerName: "app", CallID: "open", Frame: main},
+		{Kind: "ApplicationProfile", Name: "redis", ContainerName: "app", CallID: "exec", Frame: main},
+	}, references)
+	references, err = realStore.ListCallStackReferences(ctx, "default", "", &deep)
+	require.NoError(t, err)
+	assert.Empty(t, references)
+	references, err = realStore.ListCallStackReferences(ctx, "default", "open", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []softwarecomposition.CallStackReference{
+		{Kind: "ApplicationProfile", Name: "nginx", ContainerName: "app", CallID: "open", Frame: main},
+		{Kind: "ApplicationProfile", Name: "nginx", ContainerName: "app", CallID: "open", Frame: handler},
+	}, references)
+	references, err = realStore.ListCallStackReferences(ctx, "other", "open", nil)
+	require.NoError(t, err)
+	assert.Empty(t, references)
+
+	// updates replace the frames of a profile, deletes remove them
+	require.NoError(t, apStore.GuaranteedUpdate(ctx, keyRedis, &softwarecomposition.ApplicationProfile{}, false, nil, func(input runtime.Object, _ storage.ResponseMeta) (runtime.Object, *uint64, error) {
+		return newProfile("redis", newCallStack("exec", handler)), nil, nil
+	}, nil))
+	references, err = realStore.ListCallStackReferences(ctx, "default", "exec", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []softwarecomposition.CallStackReference{
+		{Kind: "ApplicationProfile", Name: "redis", ContainerName: "app", CallID: "exec", Frame: handler},
+	}, references)
+	require.NoError(t, apStore.Delete(ctx, keyNginx, &softwarecomposition.ApplicationProfile{}, nil, nil, nil, storage.DeleteOptions{}))
+	references, err = realStore.ListCallStackReferences(ctx, "default", "open", nil)
+	require.NoError(t, err)
+	assert.Empty(t, references)
+}
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	wlidPkg "github.com/armosec/utils-k8s-go/wlid"
@@ -15,6 +17,7 @@ import (
 	"github.com/kubescape/go-logger/helpers"
 	helpersv1 "github.com/kubescape/k8s-interface/instanceidhandler/v1/helpers"
 	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"github.com/kubescape/storage/pkg/config"
 	"github.com/spf13/afero"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"zombiezen.com/go/sqlite"
@@ -34,6 +37,8 @@ type ResourcesCleanupHandler struct {
 	root                  string // root directory to start the cleanup task
 	pool                  *sqlitemigration.Pool
 	interval              time.Duration // runs the cleanup task every Interval
+	intervalMu            sync.Mutex
+	reloaded              chan struct{} // closed and replaced when interval changes
 	defaultNamespace      string
 	fetcher               ResourcesFetcher
 	deleteFunc            TypeDeleteFunc
@@ -89,28 +94,81 @@ func NewResourcesCleanupHandler(appFs afero.Fs, root string, pool *sqlitemigrati
 		deleteFunc:            deleteFile,
 		resourceToKindHandler: initResourceToKindHandler(relevancyEnabled),
 		watchDispatcher:       watchDispatcher,
+		reloaded:              make(chan struct{}),
+	}
+}
+
+// Reload applies the cleanup interval of cfg, a cleanup task sleeping is
+// woken up if its new interval has already elapsed. A cleanup task that ran
+// once, with a zero interval, is not restarted.
+func (h *ResourcesCleanupHandler) Reload(cfg *config.Config) {
+	h.intervalMu.Lock()
+	defer h.intervalMu.Unlock()
+	if h.interval == cfg.CleanupInterval {
+		return
+	}
+	h.interval = cfg.CleanupInterval
+	if h.reloaded != nil {
+		close(h.reloaded)
+	}
+	h.reloaded = make(chan struct{})
+}
+
+func (h *ResourcesCleanupHandler) getInterval() (time.Duration, <-chan struct{}) {
+	h.intervalMu.Lock()
+	defer h.intervalMu.Unlock()
+	return h.interval, h.reloaded
+}
+
+// sleep waits for the cleanup interval, following its changes. It returns
+// false if ctx is done first.
+func (h *ResourcesCleanupHandler) sleep(ctx context.Context) bool {
+	start := time.Now()
+	for {
+		interval, reloaded := h.getInterval()
+		timer := time.NewTimer(interval - time.Since(start))
+		select {
+		case <-timer.C:
+			return true
+		case <-ctx.Done():
+			timer.Stop()
+			return false
+		case <-reloaded:
+			timer.Stop()
+		}
 	}
 }
 
 func (h *ResourcesCleanupHandler) RunCleanupTask(ctx context.Context) {
 	for {
-		logger.L().Info("starting cleanup task", helpers.String("interval", h.interval.String()))
+		interval, _ := h.getInterval()
+		logger.L().Info("starting cleanup task", helpers.String("interval", interval.String()))
 		err := h.CleanupTask(ctx, h.resourceToKindHandler)
 		if err != nil {
 			logger.L().Error("cleanup task error", helpers.Error(err))
-			time.Sleep(h.interval)
+			if !h.sleep(ctx) {
+				return
+			}
 			continue
 		}
 
-		if h.interval == 0 {
+		if interval, _ := h.getInterval(); interval == 0 {
 			break
 		}
 
 		logger.L().Info("finished cleanup task. sleeping...")
-		time.Sleep(h.interval)
+		if !h.sleep(ctx) {
+			return
+		}
 	}
 }
 
+// EmergencyCleanup runs the cleanup task at once, deleting the deprecated
+// kinds and the stale objects, to free disk space.
+func (h *ResourcesCleanupHandler) EmergencyCleanup(ctx context.Context) error {
+	return h.CleanupTask(ctx, h.resourceToKindHandler)
+}
+
 func (h *ResourcesCleanupHandler) CleanupTask(ctx context.Context, resourceToKindHandler map[string][]TypeCleanupHandlerFunc) error {
 	// take SQLite connection from the pool
 	conn, err := h.pool.Take(context.Background())
@@ -338,12 +396,15 @@ func deleteMissingWlidAnnotation(_, _ string, metadata *metav1.ObjectMeta, _ Res
 	return !ok
 }
 
-// deleteWrongSchemaVersion deletes resources that have missing or unexpected schema version
-func deleteWrongSchemaVersion(_, _ string, metadata *metav1.ObjectMeta, _ ResourceMaps) bool {
+// deleteWrongSchemaVersion deletes resources that have missing or unexpected schema version,
+// those which m can migrate to the current one are kept
+func (m *SchemaMigrations) deleteWrongSchemaVersion(kind, _ string, metadata *metav1.ObjectMeta, _ ResourceMaps) bool {
 	// schema version is transferred via annotations by loadMetadata
 	v, ok := metadata.Annotations["schemaVersion"]
-	if ok && v == fmt.Sprintf("%d", SchemaVersion) {
-		return false
+	if ok {
+		if version, err := strconv.ParseInt(v, 10, 64); err == nil && m.CanMigrate(kind, version) {
+			return false
+		}
 	}
 	logger.L().Debug("deleting resource with wrong schema version", helpers.String("name", metadata.Name), helpers.String("namespace", metadata.Namespace), helpers.String("schemaVersion", v))
 	return true
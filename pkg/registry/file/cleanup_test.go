@@ -11,16 +11,20 @@ import (
 	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	_ "embed"
 
 	mapset "github.com/deckarep/golang-set/v2"
 	"github.com/goradd/maps"
 	helpersv1 "github.com/kubescape/k8s-interface/instanceidhandler/v1/helpers"
+	"github.com/kubescape/storage/pkg/config"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
 	"zombiezen.com/go/sqlite"
 )
 
@@ -282,3 +286,95 @@ func TestIsUserManaged(t *testing.T) {
 		})
 	}
 }
+
+func TestDeleteWrongSchemaVersion(t *testing.T) {
+	migrations, err := NewSchemaMigrations(SchemaVersion+1, SchemaMigration{
+		Name:      "next",
+		Resources: []string{"applicationprofiles"},
+		From:      SchemaVersion,
+		Migrate:   func(runtime.Object) error { return nil },
+	})
+	require.NoError(t, err)
+	tests := []struct {
+		name          string
+		migrations    *SchemaMigrations
+		kind          string
+		schemaVersion *string
+		want          bool
+	}{
+		{
+			name:          "current_kept",
+			migrations:    migrations,
+			kind:          "networkneighborhoods",
+			schemaVersion: ptr.To(fmt.Sprintf("%d", SchemaVersion+1)),
+			want:          false,
+		},
+		{
+			name:          "migratable_kept",
+			migrations:    migrations,
+			kind:          "applicationprofiles",
+			schemaVersion: ptr.To(fmt.Sprintf("%d", SchemaVersion)),
+			want:          false,
+		},
+		{
+			name:          "without_migration_deleted",
+			migrations:    migrations,
+			kind:          "networkneighborhoods",
+			schemaVersion: ptr.To(fmt.Sprintf("%d", SchemaVersion)),
+			want:          true,
+		},
+		{
+			name:          "newer_deleted",
+			migrations:    migrations,
+			kind:          "applicationprofiles",
+			schemaVersion: ptr.To(fmt.Sprintf("%d", SchemaVersion+2)),
+			want:          true,
+		},
+		{
+			name:       "missing_deleted",
+			migrations: migrations,
+			kind:       "applicationprofiles",
+			want:       true,
+		},
+		{
+			name:          "no_migrations_current_kept",
+			kind:          "applicationprofiles",
+			schemaVersion: ptr.To(fmt.Sprintf("%d", SchemaVersion)),
+			want:          false,
+		},
+		{
+			name:          "no_migrations_older_deleted",
+			kind:          "applicationprofiles",
+			schemaVersion: ptr.To(fmt.Sprintf("%d", SchemaVersion-1)),
+			want:          true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata := &metav1.ObjectMeta{Name: "nginx", Annotations: map[string]string{}}
+			if tt.schemaVersion != nil {
+				metadata.Annotations["schemaVersion"] = *tt.schemaVersion
+			}
+			assert.Equal(t, tt.want, tt.migrations.deleteWrongSchemaVersion(tt.kind, "", metadata, ResourceMaps{}))
+		})
+	}
+}
+
+func TestResourcesCleanupHandler_ReloadInterval(t *testing.T) {
+	h := NewResourcesCleanupHandler(afero.NewMemMapFs(), DefaultStorageRoot, nil, nil, time.Hour, "kubescape", nil, false)
+	woken := make(chan bool)
+	go func() { woken <- h.sleep(context.Background()) }()
+	h.Reload(&config.Config{CleanupInterval: 10 * time.Millisecond})
+	select {
+	case ok := <-woken:
+		assert.True(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("cleanup task not woken up by the new interval")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	h.Reload(&config.Config{CleanupInterval: time.Hour})
+	go func() { woken <- h.sleep(ctx) }()
+	cancel()
+	assert.False(t, <-woken)
+}
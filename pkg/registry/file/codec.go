@@ -0,0 +1,385 @@
+package file
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition/v1beta1"
+	"google.golang.org/protobuf/encoding/protowire"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// payloadMagic starts the header of a payload, followed by the PayloadFormat
+// of its encoding. A gob stream never starts with a zero byte, which tells
+// apart the payloads written before headers.
+var payloadMagic = []byte{0x00, 'k', 's', 'p'}
+
+const payloadHeaderSize = 5
+
+// PayloadFormat identifies the encoding of a payload, recorded in its header.
+type PayloadFormat byte
+
+const (
+	// payloadFormatLegacy is gob without header, as written by older versions
+	// and by the gob codec, so that they can read its payloads back.
+	payloadFormatLegacy PayloadFormat = iota
+	// PayloadFormatGob is gob with a header, only read.
+	PayloadFormatGob
+	PayloadFormatProtobuf
+)
+
+func (f PayloadFormat) String() string {
+	switch f {
+	case payloadFormatLegacy:
+		return "legacy"
+	case PayloadFormatGob:
+		return "gob"
+	case PayloadFormatProtobuf:
+		return "protobuf"
+	}
+	return fmt.Sprintf("unknown(%d)", byte(f))
+}
+
+// PayloadCodec encodes the payloads of one PayloadFormat.
+type PayloadCodec interface {
+	Format() PayloadFormat
+	// Supports tells if obj can be encoded, the objects it cannot are
+	// written in gob.
+	Supports(obj runtime.Object) bool
+	Encode(w io.Writer, obj runtime.Object) error
+	Decode(r io.Reader, obj runtime.Object) error
+}
+
+// NewPayloadCodec returns the codec named name, "gob" or "protobuf".
+func NewPayloadCodec(name string, scheme *runtime.Scheme) (PayloadCodec, error) {
+	switch name {
+	case PayloadFormatGob.String():
+		return gobCodec{}, nil
+	case PayloadFormatProtobuf.String():
+		return NewProtobufCodec(scheme, v1beta1.SchemeGroupVersion), nil
+	}
+	return nil, fmt.Errorf("unknown payload codec %q", name)
+}
+
+// gobCodec encodes any object, but breaks on the type changes of its fields.
+// Its payloads have no header, as those of the releases before protobuf.
+type gobCodec struct{}
+
+func (gobCodec) Format() PayloadFormat {
+	return payloadFormatLegacy
+}
+
+func (gobCodec) Supports(runtime.Object) bool {
+	return true
+}
+
+func (gobCodec) Encode(w io.Writer, obj runtime.Object) error {
+	return gob.NewEncoder(w).Encode(obj)
+}
+
+func (gobCodec) Decode(r io.Reader, obj runtime.Object) error {
+	return gob.NewDecoder(r).Decode(obj)
+}
+
+// protoMessage is implemented by the generated protobuf of the served versions.
+type protoMessage interface {
+	runtime.Object
+	Marshal() ([]byte, error)
+	Unmarshal(data []byte) error
+}
+
+// The fields of the envelope of a protobuf payload.
+const (
+	envelopeAPIVersion protowire.Number = iota + 1
+	envelopeKind
+	envelopeObject
+	envelopeStorageOnly
+)
+
+// protobufCodec encodes the objects as the protobuf of a served version, in an
+// envelope recording that version, so that the payloads survive the changes
+// the API versioning allows, converted on read by the scheme. The fields
+// stored but not served, such as the parts of an aggregated profile, are kept
+// in the envelope as JSON.
+type protobufCodec struct {
+	scheme  *runtime.Scheme
+	version schema.GroupVersion
+	// storageOnly caches the names of the fields missing from the versioned
+	// kind, by internal type
+	storageOnly sync.Map
+}
+
+// NewProtobufCodec returns a codec writing the protobuf of version. Without
+// scheme, it only supports the objects of that version.
+func NewProtobufCodec(scheme *runtime.Scheme, version schema.GroupVersion) PayloadCodec {
+	return &protobufCodec{
+		scheme:  scheme,
+		version: version,
+	}
+}
+
+func (c *protobufCodec) Format() PayloadFormat {
+	return PayloadFormatProtobuf
+}
+
+func (c *protobufCodec) Supports(obj runtime.Object) bool {
+	_, _, err := c.versioned(obj)
+	return err == nil
+}
+
+// versioned returns an empty object of the kind of obj in c.version, or obj if
+// it is already versioned, with its kind.
+func (c *protobufCodec) versioned(obj runtime.Object) (protoMessage, schema.GroupVersionKind, error) {
+	if m, ok := obj.(protoMessage); ok {
+		return m, c.version.WithKind(reflect.TypeOf(obj).Elem().Name()), nil
+	}
+	if c.scheme == nil {
+		return nil, schema.GroupVersionKind{}, errors.New("no scheme to convert objects")
+	}
+	gvks, _, err := c.scheme.ObjectKinds(obj)
+	if err != nil {
+		return nil, schema.GroupVersionKind{}, err
+	}
+	gvk := c.version.WithKind(gvks[0].Kind)
+	out, err := c.scheme.New(gvk)
+	if err != nil {
+		return nil, schema.GroupVersionKind{}, err
+	}
+	m, ok := out.(protoMessage)
+	if !ok {
+		return nil, schema.GroupVersionKind{}, fmt.Errorf("no protobuf for %s", gvk)
+	}
+	return m, gvk, nil
+}
+
+func (c *protobufCodec) Encode(w io.Writer, obj runtime.Object) error {
+	m, gvk, err := c.versioned(obj)
+	if err != nil {
+		return err
+	}
+	var storageOnly []byte
+	if runtime.Object(m) != obj {
+		if err := c.scheme.Convert(obj, m, nil); err != nil {
+			return fmt.Errorf("convert to %s: %w", gvk.GroupVersion(), err)
+		}
+		storageOnly, err = c.storageOnlyFields(obj, m)
+		if err != nil {
+			return err
+		}
+	}
+	raw, err := m.Marshal()
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+	var b []byte
+	b = protowire.AppendTag(b, envelopeAPIVersion, protowire.BytesType)
+	b = protowire.AppendString(b, gvk.GroupVersion().String())
+	b = protowire.AppendTag(b, envelopeKind, protowire.BytesType)
+	b = protowire.AppendString(b, gvk.Kind)
+	b = protowire.AppendTag(b, envelopeObject, protowire.BytesType)
+	b = protowire.AppendVarint(b, uint64(len(raw)))
+	if _, err := w.Write(b); err != nil {
+		return err
+	}
+	// the object is written apart, it can be large
+	if _, err := w.Write(raw); err != nil {
+		return err
+	}
+	if storageOnly == nil {
+		return nil
+	}
+	b = protowire.AppendTag(b[:0], envelopeStorageOnly, protowire.BytesType)
+	b = protowire.AppendBytes(b, storageOnly)
+	_, err = w.Write(b)
+	return err
+}
+
+func (c *protobufCodec) Decode(r io.Reader, obj runtime.Object) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	var apiVersion, kind string
+	var raw, storageOnly []byte
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return fmt.Errorf("decode envelope: %w", protowire.ParseError(n))
+		}
+		data = data[n:]
+		if typ != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(num, typ, data)
+		} else {
+			var v []byte
+			v, n = protowire.ConsumeBytes(data)
+			switch num {
+			case envelopeAPIVersion:
+				apiVersion = string(v)
+			case envelopeKind:
+				kind = string(v)
+			case envelopeObject:
+				raw = v
+			case envelopeStorageOnly:
+				storageOnly = v
+			}
+		}
+		if n < 0 {
+			return fmt.Errorf("decode envelope: %w", protowire.ParseError(n))
+		}
+		data = data[n:]
+	}
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return fmt.Errorf("decode envelope: %w", err)
+	}
+	if m, ok := obj.(protoMessage); ok && gv == c.version {
+		return m.Unmarshal(raw)
+	}
+	if c.scheme == nil {
+		return fmt.Errorf("no scheme to convert %s", gv.WithKind(kind))
+	}
+	in, err := c.scheme.New(gv.WithKind(kind))
+	if err != nil {
+		return err
+	}
+	m, ok := in.(protoMessage)
+	if !ok {
+		return fmt.Errorf("no protobuf for %s", gv.WithKind(kind))
+	}
+	if err := m.Unmarshal(raw); err != nil {
+		return err
+	}
+	if err := c.scheme.Convert(m, obj, nil); err != nil {
+		return fmt.Errorf("convert from %s: %w", gv, err)
+	}
+	if storageOnly == nil {
+		return nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(storageOnly, &fields); err != nil {
+		return fmt.Errorf("decode storage-only fields: %w", err)
+	}
+	v := reflect.ValueOf(obj).Elem()
+	for name, value := range fields {
+		if f := v.FieldByName(name); f.IsValid() {
+			if err := json.Unmarshal(value, f.Addr().Interface()); err != nil {
+				return fmt.Errorf("decode %s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// storageOnlyFields returns the JSON of the non-zero fields of obj missing
+// from versioned, nil if there are none.
+func (c *protobufCodec) storageOnlyFields(obj runtime.Object, versioned runtime.Object) ([]byte, error) {
+	t := reflect.TypeOf(obj).Elem()
+	names, ok := c.storageOnly.Load(t)
+	if !ok {
+		vt := reflect.TypeOf(versioned).Elem()
+		var missing []string
+		for i := 0; i < t.NumField(); i++ {
+			if _, found := vt.FieldByName(t.Field(i).Name); !found {
+				missing = append(missing, t.Field(i).Name)
+			}
+		}
+		names, _ = c.storageOnly.LoadOrStore(t, missing)
+	}
+	fields := map[string]any{}
+	v := reflect.ValueOf(obj).Elem()
+	for _, name := range names.([]string) {
+		if f := v.FieldByName(name); !f.IsZero() {
+			fields[name] = f.Interface()
+		}
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(fields)
+}
+
+// encodePayload writes the header and the encoding of obj, with the codec of
+// the writes if it supports obj and with gob otherwise, gob having no header.
+func (s *StorageImpl) encodePayload(w io.Writer, obj runtime.Object) error {
+	codec := s.codecFor(obj)
+	if codec.Format() == payloadFormatLegacy {
+		return codec.Encode(w, obj)
+	}
+	if _, err := w.Write(append(bytes.Clone(payloadMagic), byte(codec.Format()))); err != nil {
+		return err
+	}
+	return codec.Encode(w, obj)
+}
+
+// decodePayload decodes the payload read from r into obj and returns its
+// format, the payloads without header being gob.
+func (s *StorageImpl) decodePayload(r io.Reader, obj runtime.Object) (PayloadFormat, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(payloadHeaderSize)
+	if err != nil || !bytes.HasPrefix(header, payloadMagic) {
+		return payloadFormatLegacy, gob.NewDecoder(br).Decode(obj)
+	}
+	format := PayloadFormat(header[len(payloadMagic)])
+	var codec PayloadCodec = gobCodec{}
+	if format != PayloadFormatGob {
+		var ok bool
+		if codec, ok = s.codecs[format]; !ok {
+			return format, fmt.Errorf("unknown payload format %s", format)
+		}
+	}
+	_, _ = br.Discard(payloadHeaderSize)
+	return format, codec.Decode(br, obj)
+}
+
+// readPayload decodes the payload of key into obj and returns its format.
+func (s *StorageImpl) readPayload(key string, obj runtime.Object) (PayloadFormat, error) {
+	payloadFile, err := s.openPayloadFileWithFallback(makePayloadPath(filepath.Join(s.root, key)), os.O_RDONLY, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = payloadFile.Close()
+	}()
+	return s.decodePayload(NewDirectIOReader(payloadFile), obj)
+}
+
+// isLegacyGobError tells if err comes from a gob payload written before a
+// type change of its fields, which the external migration tool converts.
+func isLegacyGobError(err error) bool {
+	return strings.Contains(err.Error(), "gob: wrong type") || strings.Contains(err.Error(), "extra fields")
+}
+
+// readPayloadFormat returns the format of the payload at path.
+func (s *StorageImpl) readPayloadFormat(path string) (PayloadFormat, error) {
+	f, err := s.appFs.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	header := make([]byte, payloadHeaderSize)
+	if _, err := io.ReadFull(f, header); err != nil || !bytes.HasPrefix(header, payloadMagic) {
+		return payloadFormatLegacy, nil
+	}
+	return PayloadFormat(header[len(payloadMagic)]), nil
+}
+
+// codecFor returns the codec obj is written with.
+func (s *StorageImpl) codecFor(obj runtime.Object) PayloadCodec {
+	if s.codec != nil && s.codec.Supports(obj) {
+		return s.codec
+	}
+	return gobCodec{}
+}
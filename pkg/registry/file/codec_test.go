@@ -0,0 +1,192 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition/v1beta1"
+	"github.com/kubescape/storage/pkg/generated/clientset/versioned/scheme"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/storage"
+)
+
+func TestPayloadCodec(t *testing.T) {
+	sch := scheme.Scheme
+	require.NoError(t, softwarecomposition.AddToScheme(sch))
+	profile := &softwarecomposition.ApplicationProfile{
+		ObjectMeta: v1.ObjectMeta{
+			Name:        "nginx",
+			Namespace:   "default",
+			Annotations: map[string]string{"kubescape.io/status": "ready"},
+		},
+		Parts:         map[string]string{"/containerprofiles/default/nginx": "checksum"},
+		SchemaVersion: 1,
+		Spec: softwarecomposition.ApplicationProfileSpec{
+			Architectures: []string{"amd64"},
+			Containers: []softwarecomposition.ApplicationProfileContainer{{
+				Name:         "nginx",
+				Capabilities: []string{"NET_BIND_SERVICE"},
+				Execs:        []softwarecomposition.ExecCalls{{Path: "/usr/sbin/nginx", Args: []string{"-g", "daemon off;"}}},
+				Opens:        []softwarecomposition.OpenCalls{{Path: "/etc/nginx/nginx.conf", Flags: []string{"O_RDONLY"}}},
+				Syscalls:     []string{"accept4", "read"},
+			}},
+		},
+	}
+	for _, codec := range []PayloadCodec{gobCodec{}, NewProtobufCodec(sch, v1beta1.SchemeGroupVersion)} {
+		t.Run(codec.Format().String(), func(t *testing.T) {
+			// internal objects, with the fields only stored
+			var buf bytes.Buffer
+			require.True(t, codec.Supports(profile))
+			require.NoError(t, codec.Encode(&buf, profile))
+			got := &softwarecomposition.ApplicationProfile{}
+			require.NoError(t, codec.Decode(&buf, got))
+			assert.Equal(t, profile, got)
+			// versioned objects
+			buf.Reset()
+			sbom := &v1beta1.SBOMSyft{ObjectMeta: v1.ObjectMeta{Name: "nginx"}, Spec: v1beta1.SBOMSyftSpec{Metadata: v1beta1.SPDXMeta{Tool: v1beta1.ToolMeta{Name: "syft"}}}}
+			require.NoError(t, codec.Encode(&buf, sbom))
+			gotSBOM := &v1beta1.SBOMSyft{}
+			require.NoError(t, codec.Decode(&buf, gotSBOM))
+			assert.Equal(t, sbom, gotSBOM)
+		})
+	}
+
+	// kinds without protobuf are written in gob
+	protobuf := NewProtobufCodec(sch, v1beta1.SchemeGroupVersion)
+	assert.False(t, protobuf.Supports(&softwarecomposition.Guardrail{}))
+	// internal objects need the scheme
+	assert.False(t, NewProtobufCodec(nil, v1beta1.SchemeGroupVersion).Supports(profile))
+
+	_, err := NewPayloadCodec("json", sch)
+	assert.Error(t, err)
+}
+
+func TestStorageImpl_PayloadFormat(t *testing.T) {
+	pool := NewTestPool(t.TempDir())
+	defer func() { _ = pool.Close() }()
+	sch := scheme.Scheme
+	require.NoError(t, softwarecomposition.AddToScheme(sch))
+	fs := afero.NewMemMapFs()
+	s := NewStorageImpl(fs, DefaultStorageRoot, pool, nil, sch).(*StorageImpl)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	key := "/spdx.softwarecomposition.kubescape.io/sbomsyfts/default/nginx"
+	payloadPath := makePayloadPath(filepath.Join(DefaultStorageRoot, key))
+	obj := &softwarecomposition.SBOMSyft{
+		ObjectMeta: v1.ObjectMeta{Name: "nginx", Namespace: "default"},
+		Spec:       softwarecomposition.SBOMSyftSpec{Metadata: softwarecomposition.SPDXMeta{Tool: softwarecomposition.ToolMeta{Name: "syft"}}},
+	}
+	// written in gob without header by default, as the previous releases
+	require.NoError(t, s.Create(ctx, key, obj, &softwarecomposition.SBOMSyft{}, 0))
+	format, err := s.readPayloadFormat(payloadPath)
+	require.NoError(t, err)
+	assert.Equal(t, payloadFormatLegacy, format)
+	data, err := afero.ReadFile(fs, payloadPath)
+	require.NoError(t, err)
+	previous := &softwarecomposition.SBOMSyft{}
+	require.NoError(t, gob.NewDecoder(bytes.NewReader(data)).Decode(previous))
+	assert.Equal(t, "syft", previous.Spec.Metadata.Tool.Name)
+
+	// and in protobuf once enabled
+	s.SetPayloadCodec(NewProtobufCodec(sch, v1beta1.SchemeGroupVersion))
+	require.NoError(t, s.GuaranteedUpdate(ctx, key, &softwarecomposition.SBOMSyft{}, false, nil, func(input runtime.Object, _ storage.ResponseMeta) (runtime.Object, *uint64, error) {
+		input.(*softwarecomposition.SBOMSyft).Labels = map[string]string{"codec": "protobuf"}
+		return input, nil, nil
+	}, nil))
+	format, err = s.readPayloadFormat(payloadPath)
+	require.NoError(t, err)
+	assert.Equal(t, PayloadFormatProtobuf, format)
+	// the payload is written without padding
+	info, err := fs.Stat(payloadPath)
+	require.NoError(t, err)
+	conn, err := pool.Take(ctx)
+	require.NoError(t, err)
+	usage, err := readNamespaceUsage(conn, "sbomsyfts", "default")
+	pool.Put(conn)
+	require.NoError(t, err)
+	assert.Equal(t, info.Size(), usage.Bytes)
+
+	// a payload in an outdated format is served as is under disk pressure
+	var legacy bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&legacy).Encode(obj))
+	require.NoError(t, afero.WriteFile(fs, payloadPath, legacy.Bytes(), 0644))
+	used := map[string]float64{"/data": 96}
+	m := newTestDiskMonitor(used, nil)
+	m.check(ctx)
+	s.SetDiskMonitor(m)
+	got := &softwarecomposition.SBOMSyft{}
+	require.NoError(t, s.Get(ctx, key, storage.GetOptions{}, got))
+	assert.Equal(t, "syft", got.Spec.Metadata.Tool.Name)
+	format, err = s.readPayloadFormat(payloadPath)
+	require.NoError(t, err)
+	assert.Equal(t, payloadFormatLegacy, format)
+
+	// and left as is by the sweep
+	progress, err := s.SweepMigrations(ctx, SweepOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, progress.Skipped)
+	format, err = s.readPayloadFormat(payloadPath)
+	require.NoError(t, err)
+	assert.Equal(t, payloadFormatLegacy, format)
+
+	// it is upgraded when read otherwise
+	used["/data"] = 10
+	m.check(ctx)
+	got = &softwarecomposition.SBOMSyft{}
+	require.NoError(t, s.Get(ctx, key, storage.GetOptions{}, got))
+	assert.Equal(t, "syft", got.Spec.Metadata.Tool.Name)
+	format, err = s.readPayloadFormat(payloadPath)
+	require.NoError(t, err)
+	assert.Equal(t, PayloadFormatProtobuf, format)
+
+	// or listed
+	require.NoError(t, afero.WriteFile(fs, payloadPath, legacy.Bytes(), 0644))
+	list := &softwarecomposition.SBOMSyftList{}
+	require.NoError(t, s.GetList(ctx, "/spdx.softwarecomposition.kubescape.io/sbomsyfts/default", storage.ListOptions{ResourceVersion: softwarecomposition.ResourceVersionFullSpec, Predicate: storage.Everything}, list))
+	require.Len(t, list.Items, 1)
+	assert.Equal(t, "syft", list.Items[0].Spec.Metadata.Tool.Name)
+	format, err = s.readPayloadFormat(payloadPath)
+	require.NoError(t, err)
+	assert.Equal(t, PayloadFormatProtobuf, format)
+
+	// or swept
+	require.NoError(t, afero.WriteFile(fs, payloadPath, legacy.Bytes(), 0644))
+	progress, err = s.SweepMigrations(ctx, SweepOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, SweepProgress{Total: 1, Checked: 1, Migrations: map[string]int{}, Done: true}, progress)
+	format, err = s.readPayloadFormat(payloadPath)
+	require.NoError(t, err)
+	assert.Equal(t, PayloadFormatProtobuf, format)
+
+	// back to gob, the sweep rewrites the payloads for the previous releases
+	s.SetPayloadCodec(gobCodec{})
+	_, err = s.SweepMigrations(ctx, SweepOptions{})
+	require.NoError(t, err)
+	data, err = afero.ReadFile(fs, payloadPath)
+	require.NoError(t, err)
+	previous = &softwarecomposition.SBOMSyft{}
+	require.NoError(t, gob.NewDecoder(bytes.NewReader(data)).Decode(previous))
+	assert.Equal(t, "syft", previous.Spec.Metadata.Tool.Name)
+
+	// a gob payload with a header is still read
+	var headed bytes.Buffer
+	headed.Write(append(bytes.Clone(payloadMagic), byte(PayloadFormatGob)))
+	require.NoError(t, gob.NewEncoder(&headed).Encode(obj))
+	require.NoError(t, afero.WriteFile(fs, payloadPath, headed.Bytes(), 0644))
+	got = &softwarecomposition.SBOMSyft{}
+	require.NoError(t, s.Get(ctx, key, storage.GetOptions{}, got))
+	assert.Equal(t, "syft", got.Spec.Metadata.Tool.Name)
+
+	// unknown formats are not guessed
+	require.NoError(t, afero.WriteFile(fs, payloadPath, append(bytes.Clone(payloadMagic), 42, 1, 2, 3), 0644))
+	assert.ErrorContains(t, s.Get(ctx, key, storage.GetOptions{}, &softwarecomposition.SBOMSyft{}), "unknown payload format")
+}
@@ -12,12 +12,20 @@ package file
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/kubescape/go-logger"
+	loggerhelpers "github.com/kubescape/go-logger/helpers"
 	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
 	"github.com/kubescape/storage/pkg/registry/file/dynamicpathdetector"
+	"golang.org/x/sync/singleflight"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apiserver/pkg/storage"
 )
 
@@ -38,7 +46,7 @@ type cachedCollapseSettings struct {
 // deflate path reads to learn effective collapse thresholds. Operators
 // (and the bobctl autotune flow) write/edit this CR; if it is absent
 // the provider falls back to dynamicpathdetector.DefaultCollapseSettings.
-const DefaultCollapseConfigurationName = "default"
+const DefaultCollapseConfigurationName = softwarecomposition.DefaultCollapseConfigurationName
 
 // collapseConfigurationKey is the in-storage key for the cluster-scoped
 // CollapseConfiguration/default CR. It must match exactly the key the
@@ -111,3 +119,206 @@ func NewCRDCollapseSettingsProvider(s storage.Interface) dynamicpathdetector.Col
 		return settings
 	}
 }
+
+// CollapseConfigurationAnnotation records on a profile the name of the
+// CollapseConfiguration it was collapsed with. It is absent when the
+// compiled-in defaults were used.
+const CollapseConfigurationAnnotation = "kubescape.io/collapse-configuration"
+
+const collapseConfigurationsResource = "collapseconfigurations"
+
+// NamespaceLabelsFunc returns the labels of a namespace.
+type NamespaceLabelsFunc func(ctx context.Context, namespace string) (map[string]string, error)
+
+// CollapseSettingsResolver returns the collapse settings of a profile of
+// namespace carrying profileLabels, along with the name of the
+// CollapseConfiguration they come from ("" for the compiled-in defaults).
+type CollapseSettingsResolver func(ctx context.Context, namespace string, profileLabels map[string]string) (dynamicpathdetector.CollapseSettings, string)
+
+// scopedCollapseConfiguration is a CollapseConfiguration other than the
+// default one, compiled for matching.
+type scopedCollapseConfiguration struct {
+	name        string
+	namespaces  labels.Selector
+	workloads   labels.Selector // nil without a WorkloadSelector
+	specificity int
+	settings    dynamicpathdetector.CollapseSettings
+}
+
+// collapseConfigurations is the value cached by the resolver: the scoped
+// configurations, most specific first, and the default one if applied.
+type collapseConfigurations struct {
+	scoped    []scopedCollapseConfiguration
+	def       *dynamicpathdetector.CollapseSettings
+	expiresAt time.Time
+}
+
+type cachedNamespaceLabels struct {
+	labels    labels.Set
+	expiresAt time.Time
+}
+
+// NewCRDCollapseSettingsResolver returns a CollapseSettingsResolver reading
+// every CollapseConfiguration in s. A profile is collapsed with the most
+// specific scoped configuration selecting it: one with a WorkloadSelector
+// (matched against the profile labels) over one without, then the one with
+// the most selector requirements, then the first by name. A profile no
+// scoped configuration selects gets the "default" configuration, or the
+// compiled-in defaults when it is absent.
+//
+// NamespaceSelectors are matched against the labels namespaceLabels returns,
+// which always include kubernetes.io/metadata.name, so that a nil
+// namespaceLabels or a failing lookup still allows selecting by name.
+// Configurations and namespace labels are cached for collapseSettingsTTL,
+// like NewCRDCollapseSettingsProvider.
+func NewCRDCollapseSettingsResolver(s storage.Interface, namespaceLabels NamespaceLabelsFunc) CollapseSettingsResolver {
+	var (
+		cache      atomic.Pointer[collapseConfigurations]
+		mu         sync.Mutex // serializes refreshes only; reads are lock-free
+		nsMu       sync.Mutex // guards namespaces only
+		namespaces = map[string]cachedNamespaceLabels{}
+		lookups    singleflight.Group
+	)
+	load := func() *collapseConfigurations {
+		if c := cache.Load(); c != nil && time.Now().Before(c.expiresAt) {
+			return c
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if c := cache.Load(); c != nil && time.Now().Before(c.expiresAt) { // re-check under lock
+			return c
+		}
+		c := loadCollapseConfigurations(s)
+		c.expiresAt = time.Now().Add(collapseSettingsTTL)
+		cache.Store(c)
+		return c
+	}
+	// namespaces are looked up outside nsMu, so that a slow lookup only holds
+	// back the writes of its own namespace, and once per namespace at a time
+	labelsOf := func(ctx context.Context, namespace string) labels.Set {
+		nsMu.Lock()
+		c, ok := namespaces[namespace]
+		nsMu.Unlock()
+		if ok && time.Now().Before(c.expiresAt) {
+			return c.labels
+		}
+		set, _, _ := lookups.Do(namespace, func() (any, error) {
+			set := labels.Set{}
+			if namespaceLabels != nil {
+				nsLabels, err := namespaceLabels(ctx, namespace)
+				if err != nil {
+					logger.L().Debug("CollapseSettingsResolver - get namespace labels failed", loggerhelpers.Error(err), loggerhelpers.String("namespace", namespace))
+				}
+				for k, v := range nsLabels {
+					set[k] = v
+				}
+			}
+			set[corev1.LabelMetadataName] = namespace
+			nsMu.Lock()
+			namespaces[namespace] = cachedNamespaceLabels{labels: set, expiresAt: time.Now().Add(collapseSettingsTTL)}
+			nsMu.Unlock()
+			return set, nil
+		})
+		return set.(labels.Set)
+	}
+	return func(ctx context.Context, namespace string, profileLabels map[string]string) (dynamicpathdetector.CollapseSettings, string) {
+		c := load()
+		if len(c.scoped) > 0 {
+			nsLabels := labelsOf(ctx, namespace)
+			for _, scoped := range c.scoped {
+				if scoped.namespaces.Matches(nsLabels) && (scoped.workloads == nil || scoped.workloads.Matches(labels.Set(profileLabels))) {
+					return scoped.settings, scoped.name
+				}
+			}
+		}
+		if c.def != nil {
+			return *c.def, DefaultCollapseConfigurationName
+		}
+		return dynamicpathdetector.DefaultCollapseSettings(), ""
+	}
+}
+
+// loadCollapseConfigurations reads and compiles the CollapseConfigurations of
+// s, an unreadable storage yields the compiled-in defaults.
+func loadCollapseConfigurations(s storage.Interface) *collapseConfigurations {
+	c := &collapseConfigurations{}
+	if s == nil {
+		return c
+	}
+	ctx := context.Background()
+	// the metadata list gives the names, each configuration is then read with its spec
+	list := &softwarecomposition.CollapseConfigurationList{}
+	if err := s.GetList(ctx, "/"+softwarecomposition.GroupName+"/"+collapseConfigurationsResource, storage.ListOptions{}, list); err != nil {
+		logger.L().Warning("CollapseSettingsResolver - list collapse configurations failed", loggerhelpers.Error(err))
+		return c
+	}
+	for _, item := range list.Items {
+		crd := &softwarecomposition.CollapseConfiguration{}
+		key := collapseConfigurationKey(item.Name)
+		if err := s.Get(ctx, key, storage.GetOptions{}, crd); err != nil {
+			logger.L().Warning("CollapseSettingsResolver - get collapse configuration failed", loggerhelpers.Error(err), loggerhelpers.String("key", key))
+			continue
+		}
+		if crd.Name == DefaultCollapseConfigurationName {
+			settings := dynamicpathdetector.CollapseSettingsFromCRD(crd)
+			c.def = &settings
+			continue
+		}
+		scoped, err := compileScopedCollapseConfiguration(crd)
+		if err != nil {
+			logger.L().Warning("CollapseSettingsResolver - invalid collapse configuration selector", loggerhelpers.Error(err), loggerhelpers.String("name", crd.Name))
+			continue
+		}
+		c.scoped = append(c.scoped, scoped)
+	}
+	sort.SliceStable(c.scoped, func(i, j int) bool {
+		if c.scoped[i].specificity != c.scoped[j].specificity {
+			return c.scoped[i].specificity > c.scoped[j].specificity
+		}
+		return c.scoped[i].name < c.scoped[j].name
+	})
+	return c
+}
+
+func compileScopedCollapseConfiguration(crd *softwarecomposition.CollapseConfiguration) (scopedCollapseConfiguration, error) {
+	scoped := scopedCollapseConfiguration{
+		name:     crd.Name,
+		settings: dynamicpathdetector.CollapseSettingsFromCRD(crd),
+	}
+	if crd.Spec.NamespaceSelector == nil {
+		// rejected by validation, never select anything
+		return scoped, fmt.Errorf("missing namespaceSelector")
+	}
+	var err error
+	if scoped.namespaces, err = metav1.LabelSelectorAsSelector(crd.Spec.NamespaceSelector); err != nil {
+		return scoped, fmt.Errorf("namespaceSelector: %w", err)
+	}
+	scoped.specificity = selectorRequirements(crd.Spec.NamespaceSelector)
+	if crd.Spec.WorkloadSelector != nil {
+		if scoped.workloads, err = metav1.LabelSelectorAsSelector(crd.Spec.WorkloadSelector); err != nil {
+			return scoped, fmt.Errorf("workloadSelector: %w", err)
+		}
+		// a workload selector always outweighs namespace requirements
+		scoped.specificity += 1<<16 + selectorRequirements(crd.Spec.WorkloadSelector)
+	}
+	return scoped, nil
+}
+
+func selectorRequirements(s *metav1.LabelSelector) int {
+	return len(s.MatchLabels) + len(s.MatchExpressions)
+}
+
+// recordCollapseConfiguration sets the CollapseConfigurationAnnotation of a
+// profile to name, removing it for the compiled-in defaults.
+func recordCollapseConfiguration(meta metav1.Object, name string) {
+	annotations := meta.GetAnnotations()
+	if name == "" {
+		delete(annotations, CollapseConfigurationAnnotation)
+		return
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[CollapseConfigurationAnnotation] = name
+	meta.SetAnnotations(annotations)
+}
@@ -13,11 +13,13 @@ package file
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"github.com/kubescape/storage/pkg/config"
 	"github.com/kubescape/storage/pkg/registry/file/dynamicpathdetector"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -63,6 +65,23 @@ func (f *fakeCollapseStorage) Get(_ context.Context, key string, opts storage.Ge
 	return nil
 }
 
+// GetList lists the stored CollapseConfigurations, for NewCRDCollapseSettingsResolver.
+func (f *fakeCollapseStorage) GetList(_ context.Context, _ string, _ storage.ListOptions, out runtime.Object) error {
+	list, ok := out.(*softwarecomposition.CollapseConfigurationList)
+	if !ok {
+		return fmt.Errorf("fakeCollapseStorage: unhandled list type %T", out)
+	}
+	keys := make([]string, 0, len(f.stored))
+	for k := range f.stored {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		list.Items = append(list.Items, *f.stored[k].(*softwarecomposition.CollapseConfiguration))
+	}
+	return nil
+}
+
 // Watch is required by storage.Interface but not exercised here.
 func (f *fakeCollapseStorage) Watch(_ context.Context, _ string, _ storage.ListOptions) (watch.Interface, error) {
 	return nil, fmt.Errorf("fakeCollapseStorage: Watch not implemented")
@@ -261,3 +280,119 @@ func TestNewCRDCollapseSettingsProvider_RefreshesAfterTTLExpiry(t *testing.T) {
 	assert.Equal(t, 1, provider().OpenDynamicThreshold)
 	assert.EqualValues(t, 2, s.getCalls.Load(), "a call after TTL expiry must trigger a fresh Get")
 }
+
+func collapseConfiguration(name string, threshold int32, ns, wl *metav1.LabelSelector) *softwarecomposition.CollapseConfiguration {
+	return &softwarecomposition.CollapseConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: softwarecomposition.CollapseConfigurationSpec{
+			OpenDynamicThreshold: threshold,
+			NamespaceSelector:    ns,
+			WorkloadSelector:     wl,
+		},
+	}
+}
+
+func TestNewCRDCollapseSettingsResolver(t *testing.T) {
+	nginx := &metav1.LabelSelector{MatchLabels: map[string]string{"kubescape.io/workload-name": "nginx"}}
+	stored := map[string]runtime.Object{}
+	for _, cc := range []*softwarecomposition.CollapseConfiguration{
+		collapseConfiguration(DefaultCollapseConfigurationName, 10, nil, nil),
+		collapseConfiguration("data", 20, &metav1.LabelSelector{MatchLabels: map[string]string{"team": "data"}}, nil),
+		collapseConfiguration("nginx", 30, &metav1.LabelSelector{}, nginx),
+		collapseConfiguration("nginx-prod", 40, &metav1.LabelSelector{MatchLabels: map[string]string{"kubernetes.io/metadata.name": "prod"}}, nginx),
+	} {
+		stored[collapseConfigurationKey(cc.Name)] = cc
+	}
+	s := &fakeCollapseStorage{stored: stored}
+	namespaceLabels := func(_ context.Context, namespace string) (map[string]string, error) {
+		if namespace == "analytics" {
+			return map[string]string{"team": "data"}, nil
+		}
+		return nil, fmt.Errorf("namespace %s not found", namespace)
+	}
+	resolve := NewCRDCollapseSettingsResolver(s, namespaceLabels)
+	nginxLabels := map[string]string{"kubescape.io/workload-name": "nginx"}
+	tests := []struct {
+		namespace     string
+		profileLabels map[string]string
+		wantName      string
+		wantThreshold int
+	}{
+		{"other", nil, DefaultCollapseConfigurationName, 10},
+		{"analytics", nil, "data", 20},
+		{"analytics", nginxLabels, "nginx", 30},
+		{"prod", nginxLabels, "nginx-prod", 40},
+		{"prod", map[string]string{"kubescape.io/workload-name": "redis"}, DefaultCollapseConfigurationName, 10},
+	}
+	for _, tt := range tests {
+		settings, name := resolve(context.TODO(), tt.namespace, tt.profileLabels)
+		assert.Equal(t, tt.wantName, name, "%s %v", tt.namespace, tt.profileLabels)
+		assert.Equal(t, tt.wantThreshold, settings.OpenDynamicThreshold, "%s %v", tt.namespace, tt.profileLabels)
+	}
+
+	// namespace selectors still match by name without a namespace lookup
+	_, name := NewCRDCollapseSettingsResolver(s, nil)(context.TODO(), "prod", nginxLabels)
+	assert.Equal(t, "nginx-prod", name)
+
+	// without any configuration the compiled-in defaults apply
+	settings, name := NewCRDCollapseSettingsResolver(&fakeCollapseStorage{stored: map[string]runtime.Object{}}, nil)(context.TODO(), "prod", nil)
+	assert.Empty(t, name)
+	assert.Equal(t, dynamicpathdetector.DefaultCollapseSettings(), settings)
+}
+
+func TestNewCRDCollapseSettingsResolver_NamespaceLookups(t *testing.T) {
+	s := &fakeCollapseStorage{stored: map[string]runtime.Object{
+		collapseConfigurationKey("data"): collapseConfiguration("data", 20, &metav1.LabelSelector{MatchLabels: map[string]string{"team": "data"}}, nil),
+	}}
+	release := make(chan struct{})
+	var lookups atomic.Int32
+	namespaceLabels := func(_ context.Context, namespace string) (map[string]string, error) {
+		if namespace == "slow" {
+			lookups.Add(1)
+			<-release
+		}
+		return map[string]string{"team": "data"}, nil
+	}
+	resolve := NewCRDCollapseSettingsResolver(s, namespaceLabels)
+
+	// concurrent writes of a namespace share its lookup
+	done := make(chan string, 2)
+	for range 2 {
+		go func() {
+			_, name := resolve(context.TODO(), "slow", nil)
+			done <- name
+		}()
+	}
+	// while a slow lookup does not hold back the other namespaces
+	_, name := resolve(context.TODO(), "fast", nil)
+	assert.Equal(t, "data", name)
+	require.Eventually(t, func() bool { return lookups.Load() == 1 }, 5*time.Second, time.Millisecond)
+	close(release)
+	assert.Equal(t, "data", <-done)
+	assert.Equal(t, "data", <-done)
+	assert.Equal(t, int32(1), lookups.Load())
+}
+
+func TestNetworkNeighborhoodProcessor_RecordsCollapseConfiguration(t *testing.T) {
+	a := NewNetworkNeighborhoodProcessor(config.Config{MaxNetworkNeighborhoodSize: 100})
+	var gotNamespace string
+	var gotLabels map[string]string
+	name := "nginx"
+	a.SetCollapseSettingsResolver(func(_ context.Context, namespace string, profileLabels map[string]string) (dynamicpathdetector.CollapseSettings, string) {
+		gotNamespace, gotLabels = namespace, profileLabels
+		return dynamicpathdetector.DefaultCollapseSettings(), name
+	})
+	profile := &softwarecomposition.NetworkNeighborhood{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "default",
+		Labels:    map[string]string{"kubescape.io/workload-name": "nginx"},
+	}}
+	require.NoError(t, a.PreSave(context.TODO(), profile))
+	assert.Equal(t, "default", gotNamespace)
+	assert.Equal(t, profile.Labels, gotLabels)
+	assert.Equal(t, "nginx", profile.Annotations[CollapseConfigurationAnnotation])
+
+	// the annotation goes away once the compiled-in defaults apply
+	name = ""
+	require.NoError(t, a.PreSave(context.TODO(), profile))
+	assert.NotContains(t, profile.Annotations, CollapseConfigurationAnnotation)
+}
@@ -0,0 +1,258 @@
+package file
+
+import (
+	"cmp"
+	"context"
+	"maps"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/kubescape/go-logger"
+	loggerhelpers "github.com/kubescape/go-logger/helpers"
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"github.com/kubescape/storage/pkg/compiledprofile"
+	"github.com/kubescape/storage/pkg/registry/file/networkmatch"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/storage"
+)
+
+// CompileContainerProfile returns the compiled form of a ContainerProfile,
+// encoded with compiledprofile.Encode, as served on the
+// containerprofiles/compiled subresource. It carries the labels and
+// annotations of the profile, which cleanup relies on.
+func CompileContainerProfile(profile *softwarecomposition.ContainerProfile) *softwarecomposition.CompiledContainerProfile {
+	return &softwarecomposition.CompiledContainerProfile{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        profile.Name,
+			Namespace:   profile.Namespace,
+			Labels:      maps.Clone(profile.Labels),
+			Annotations: maps.Clone(profile.Annotations),
+		},
+		Spec: softwarecomposition.CompiledContainerProfileSpec{
+			FormatVersion:         compiledprofile.FormatVersion,
+			SourceResourceVersion: profile.ResourceVersion,
+			Data:                  compiledprofile.Encode(CompileContainerProfileSpec(&profile.Spec)),
+		},
+	}
+}
+
+// NewCompiledContainerProfileGetter returns the function serving the
+// compiled subresource: it returns the compiled form of profile, the
+// effective ContainerProfile named name, reusing the one stored during
+// consolidation when it was compiled from the same version and compiling it
+// afresh otherwise, or when profile is an approved revision drifting from the
+// stored one. keyFunc is the key function of the containerprofiles resource.
+func NewCompiledContainerProfileGetter(s storage.Interface, keyFunc func(ctx context.Context, name string) (string, error)) func(ctx context.Context, name string, profile *softwarecomposition.ContainerProfile) (*softwarecomposition.CompiledContainerProfile, error) {
+	return func(ctx context.Context, name string, profile *softwarecomposition.ContainerProfile) (*softwarecomposition.CompiledContainerProfile, error) {
+		key, err := keyFunc(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		stored := &softwarecomposition.CompiledContainerProfile{}
+		err = s.Get(ctx, CompiledKeyFor(key), storage.GetOptions{}, stored)
+		switch {
+		case err == nil && stored.Spec.FormatVersion == compiledprofile.FormatVersion && stored.Spec.SourceResourceVersion == profile.ResourceVersion &&
+			profile.Annotations[ApprovalDriftAnnotation] != "true":
+			return stored, nil
+		case err != nil && !storage.IsNotFound(err):
+			logger.L().Debug("CompiledContainerProfile - get stored compiled profile", loggerhelpers.Error(err), loggerhelpers.String("key", key))
+		}
+		return CompileContainerProfile(profile), nil
+	}
+}
+
+// CompileContainerProfileSpec builds the compiled form of a profile spec, see
+// compiledprofile.Profile for the ordering guarantees. Malformed IP entries
+// are dropped, like networkmatch.CompileIP does.
+func CompileContainerProfileSpec(spec *softwarecomposition.ContainerProfileSpec) *compiledprofile.Profile {
+	p := &compiledprofile.Profile{
+		ImageID:       spec.ImageID,
+		ImageTag:      spec.ImageTag,
+		Architectures: DeflateSortString(spec.Architectures),
+		Capabilities:  DeflateSortString(spec.Capabilities),
+		Syscalls:      DeflateSortString(spec.Syscalls),
+	}
+
+	for _, exec := range spec.Execs {
+		p.Execs = append(p.Execs, compiledprofile.Exec{
+			Path:         exec.Path,
+			Args:         slices.Clone(exec.Args),
+			ArgsRequired: exec.ArgsRequired,
+			Envs:         DeflateSortString(exec.Envs),
+		})
+	}
+	slices.SortFunc(p.Execs, func(a, b compiledprofile.Exec) int {
+		return cmp.Or(
+			cmp.Compare(a.Path, b.Path),
+			slices.Compare(a.Args, b.Args),
+			compareBool(a.ArgsRequired, b.ArgsRequired),
+			slices.Compare(a.Envs, b.Envs))
+	})
+	p.Execs = slices.CompactFunc(p.Execs, func(a, b compiledprofile.Exec) bool {
+		return a.Path == b.Path && slices.Equal(a.Args, b.Args) && a.ArgsRequired == b.ArgsRequired && slices.Equal(a.Envs, b.Envs)
+	})
+
+	opens := make(map[string][]string, len(spec.Opens))
+	for _, open := range spec.Opens {
+		opens[open.Path] = append(opens[open.Path], open.Flags...)
+	}
+	for path, flags := range opens {
+		p.Opens = append(p.Opens, compiledprofile.Open{
+			Path:     path,
+			Segments: strings.Split(path, "/"),
+			Flags:    DeflateSortString(flags),
+		})
+	}
+	slices.SortFunc(p.Opens, func(a, b compiledprofile.Open) int {
+		return cmp.Compare(a.Path, b.Path)
+	})
+
+	type endpointKey struct{ direction, endpoint string }
+	endpoints := make(map[endpointKey]*compiledprofile.Endpoint, len(spec.Endpoints))
+	for _, endpoint := range spec.Endpoints {
+		key := endpointKey{direction: string(endpoint.Direction), endpoint: endpoint.Endpoint}
+		e, ok := endpoints[key]
+		if !ok {
+			e = &compiledprofile.Endpoint{Endpoint: endpoint.Endpoint, Direction: string(endpoint.Direction)}
+			endpoints[key] = e
+		}
+		e.Internal = e.Internal || endpoint.Internal
+		e.Methods = append(e.Methods, endpoint.Methods...)
+	}
+	for _, e := range endpoints {
+		e.Methods = DeflateSortString(e.Methods)
+		p.Endpoints = append(p.Endpoints, *e)
+	}
+	slices.SortFunc(p.Endpoints, func(a, b compiledprofile.Endpoint) int {
+		return cmp.Or(cmp.Compare(a.Direction, b.Direction), cmp.Compare(a.Endpoint, b.Endpoint))
+	})
+
+	for ruleID, policy := range spec.PolicyByRuleId {
+		p.Policies = append(p.Policies, compiledprofile.RulePolicy{
+			RuleID:           ruleID,
+			AllowedContainer: policy.AllowedContainer,
+			AllowedProcesses: DeflateSortString(policy.AllowedProcesses),
+		})
+	}
+	slices.SortFunc(p.Policies, func(a, b compiledprofile.RulePolicy) int {
+		return cmp.Compare(a.RuleID, b.RuleID)
+	})
+
+	p.Ingress = compileNeighbors(spec.Ingress)
+	p.Egress = compileNeighbors(spec.Egress)
+	return p
+}
+
+func compileNeighbors(neighbors []softwarecomposition.NetworkNeighbor) []compiledprofile.Neighbor {
+	var out []compiledprofile.Neighbor
+	for _, neighbor := range neighbors {
+		n := compiledprofile.Neighbor{
+			Identifier:        neighbor.Identifier,
+			Type:              string(neighbor.Type),
+			PodSelector:       compileSelector(neighbor.PodSelector),
+			NamespaceSelector: compileSelector(neighbor.NamespaceSelector),
+		}
+		for _, entry := range neighborIPs(&neighbor) {
+			if entry == networkmatch.AnyIPSentinel {
+				n.AnyIP = true
+				continue
+			}
+			if prefix, ok := parseIPEntry(entry); ok {
+				n.Prefixes = append(n.Prefixes, prefix)
+			}
+		}
+		slices.SortFunc(n.Prefixes, func(a, b netip.Prefix) int {
+			return cmp.Or(a.Addr().Compare(b.Addr()), cmp.Compare(a.Bits(), b.Bits()))
+		})
+		n.Prefixes = slices.Compact(n.Prefixes)
+
+		names := make([]string, 0, len(neighbor.DNSNames)+1)
+		for _, name := range neighborNames(&neighbor) {
+			if name = strings.ToLower(strings.TrimSuffix(name, ".")); name != "" {
+				names = append(names, name)
+			}
+		}
+		for _, name := range DeflateSortString(names) {
+			n.DNSNames = append(n.DNSNames, strings.Split(name, "."))
+		}
+
+		for _, port := range neighbor.Ports {
+			n.Ports = append(n.Ports, compiledprofile.Port{
+				Name:     port.Name,
+				Protocol: string(port.Protocol),
+				Port:     ptrValue(port.Port),
+			})
+		}
+		slices.SortFunc(n.Ports, func(a, b compiledprofile.Port) int {
+			return cmp.Or(cmp.Compare(a.Protocol, b.Protocol), cmp.Compare(a.Port, b.Port), cmp.Compare(a.Name, b.Name))
+		})
+		n.Ports = slices.Compact(n.Ports)
+		out = append(out, n)
+	}
+	slices.SortStableFunc(out, func(a, b compiledprofile.Neighbor) int {
+		return cmp.Compare(a.Identifier, b.Identifier)
+	})
+	return out
+}
+
+// parseIPEntry parses a literal address or a CIDR entry into a prefix, a
+// literal address being a single-address prefix.
+func parseIPEntry(entry string) (netip.Prefix, bool) {
+	if strings.Contains(entry, "/") {
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return netip.Prefix{}, false
+		}
+		if prefix.Addr().Is4In6() {
+			prefix = netip.PrefixFrom(prefix.Addr().Unmap(), max(0, prefix.Bits()-96))
+		}
+		return prefix.Masked(), true
+	}
+	addr, err := netip.ParseAddr(entry)
+	if err != nil {
+		return netip.Prefix{}, false
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), true
+}
+
+func compileSelector(selector *metav1.LabelSelector) *compiledprofile.Selector {
+	if selector == nil {
+		return nil
+	}
+	s := &compiledprofile.Selector{}
+	for key, value := range selector.MatchLabels {
+		s.MatchLabels = append(s.MatchLabels, compiledprofile.Label{Key: key, Value: value})
+	}
+	slices.SortFunc(s.MatchLabels, func(a, b compiledprofile.Label) int {
+		return cmp.Compare(a.Key, b.Key)
+	})
+	for _, req := range selector.MatchExpressions {
+		s.MatchExpressions = append(s.MatchExpressions, compiledprofile.Requirement{
+			Key:      req.Key,
+			Operator: string(req.Operator),
+			Values:   DeflateSortString(req.Values),
+		})
+	}
+	return s
+}
+
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	default:
+		return -1
+	}
+}
+
+func ptrValue[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+	return *p
+}
@@ -0,0 +1,177 @@
+package file
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+
+	helpersv1 "github.com/kubescape/k8s-interface/instanceidhandler/v1/helpers"
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"github.com/kubescape/storage/pkg/compiledprofile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/storage"
+	"k8s.io/utils/ptr"
+)
+
+func TestCompileContainerProfileSpec(t *testing.T) {
+	spec := &softwarecomposition.ContainerProfileSpec{
+		Capabilities: []string{"CAP_SETUID", "CAP_CHOWN", "CAP_SETUID"},
+		Syscalls:     []string{"read", "openat", "read"},
+		Execs: []softwarecomposition.ExecCalls{
+			{Path: "/bin/sh", Args: []string{"-c", "⋯"}},
+			{Path: "/bin/ls", Envs: []string{"TERM=xterm", "HOME=/root"}},
+			{Path: "/bin/sh", Args: []string{"-c", "⋯"}},
+		},
+		Opens: []softwarecomposition.OpenCalls{
+			{Path: "/etc/hosts", Flags: []string{"O_RDONLY"}},
+			{Path: "/etc/⋯/config", Flags: []string{"O_WRONLY"}},
+			{Path: "/etc/hosts", Flags: []string{"O_CLOEXEC", "O_RDONLY"}},
+		},
+		Endpoints: []softwarecomposition.HTTPEndpoint{
+			{Endpoint: ":80/health", Direction: "inbound", Methods: []string{"HEAD"}},
+			{Endpoint: ":80/health", Direction: "inbound", Methods: []string{"GET"}, Internal: true},
+		},
+		PolicyByRuleId: map[string]softwarecomposition.RulePolicy{
+			"R0002": {AllowedContainer: true},
+			"R0001": {AllowedProcesses: []string{"sh", "nginx"}},
+		},
+		Egress: []softwarecomposition.NetworkNeighbor{
+			{
+				Identifier:  "b",
+				IPAddresses: []string{"10.1.2.3/8", "not-an-ip", "::ffff:1.2.3.4"},
+				IPAddress:   "1.2.3.4",
+				DNSNames:    []string{"API.Example.com.", "*.example.com"},
+				DNS:         "api.example.com",
+				Ports: []softwarecomposition.NetworkPort{
+					{Name: "TCP-443", Protocol: "TCP", Port: ptr.To[int32](443)},
+					{Name: "UDP-53", Protocol: "UDP", Port: ptr.To[int32](53)},
+					{Name: "TCP-80", Protocol: "TCP", Port: ptr.To[int32](80)},
+				},
+				PodSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"tier": "db", "app": "postgres"},
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{Key: "zone", Operator: metav1.LabelSelectorOpIn, Values: []string{"b", "a"}},
+					},
+				},
+			},
+			{Identifier: "a", IPAddresses: []string{"*"}},
+		},
+	}
+
+	got := CompileContainerProfileSpec(spec)
+	assert.Equal(t, &compiledprofile.Profile{
+		Capabilities: []string{"CAP_CHOWN", "CAP_SETUID"},
+		Syscalls:     []string{"openat", "read"},
+		Execs: []compiledprofile.Exec{
+			{Path: "/bin/ls", Envs: []string{"HOME=/root", "TERM=xterm"}},
+			{Path: "/bin/sh", Args: []string{"-c", "⋯"}},
+		},
+		Opens: []compiledprofile.Open{
+			{Path: "/etc/hosts", Segments: []string{"", "etc", "hosts"}, Flags: []string{"O_CLOEXEC", "O_RDONLY"}},
+			{Path: "/etc/⋯/config", Segments: []string{"", "etc", "⋯", "config"}, Flags: []string{"O_WRONLY"}},
+		},
+		Endpoints: []compiledprofile.Endpoint{
+			{Endpoint: ":80/health", Direction: "inbound", Internal: true, Methods: []string{"GET", "HEAD"}},
+		},
+		Policies: []compiledprofile.RulePolicy{
+			{RuleID: "R0001", AllowedProcesses: []string{"nginx", "sh"}},
+			{RuleID: "R0002", AllowedContainer: true},
+		},
+		Egress: []compiledprofile.Neighbor{
+			{Identifier: "a", AnyIP: true},
+			{
+				Identifier: "b",
+				Prefixes: []netip.Prefix{
+					netip.MustParsePrefix("1.2.3.4/32"),
+					netip.MustParsePrefix("10.0.0.0/8"),
+				},
+				DNSNames: [][]string{{"*", "example", "com"}, {"api", "example", "com"}},
+				Ports: []compiledprofile.Port{
+					{Name: "TCP-80", Protocol: "TCP", Port: 80},
+					{Name: "TCP-443", Protocol: "TCP", Port: 443},
+					{Name: "UDP-53", Protocol: "UDP", Port: 53},
+				},
+				PodSelector: &compiledprofile.Selector{
+					MatchLabels:      []compiledprofile.Label{{Key: "app", Value: "postgres"}, {Key: "tier", Value: "db"}},
+					MatchExpressions: []compiledprofile.Requirement{{Key: "zone", Operator: "In", Values: []string{"a", "b"}}},
+				},
+			},
+		},
+	}, got)
+
+	// maps are iterated in random order, the encoding must not depend on it
+	data := compiledprofile.Encode(got)
+	for range 10 {
+		assert.Equal(t, data, compiledprofile.Encode(CompileContainerProfileSpec(spec)))
+	}
+}
+
+func TestConsolidateStoresCompiledProfile(t *testing.T) {
+	h := newE2EHarness(t)
+	defer h.close()
+
+	loadCompiled := func() softwarecomposition.CompiledContainerProfile {
+		var compiled softwarecomposition.CompiledContainerProfile
+		require.NoError(t, h.s.GetWithConn(h.ctx, h.conn, CompiledKeyFor(e2eCPKey()), storage.GetOptions{}, &compiled))
+		return compiled
+	}
+	decode := func(compiled softwarecomposition.CompiledContainerProfile) *compiledprofile.Profile {
+		p, err := compiledprofile.Decode(compiled.Spec.Data)
+		require.NoError(t, err)
+		return p
+	}
+
+	h.createCP("testdata/p1.json")
+	h.consolidate()
+	observed := h.loadConsolidated()
+	compiled := loadCompiled()
+	assert.Equal(t, observed.ResourceVersion, compiled.Spec.SourceResourceVersion)
+	assert.Equal(t, int32(compiledprofile.FormatVersion), compiled.Spec.FormatVersion)
+	assert.Equal(t, observed.Annotations[helpersv1.WlidMetadataKey], compiled.Annotations[helpersv1.WlidMetadataKey])
+	assert.Equal(t, DeflateSortString(observed.Spec.Syscalls), decode(compiled).Syscalls)
+
+	// the compiled profile follows the merged profile once there is one
+	h.seedNonCP(e2eUgAPKey(), &softwarecomposition.ApplicationProfile{
+		ObjectMeta: metav1.ObjectMeta{Namespace: e2eNS, Name: e2eWorkloadUg},
+		Spec: softwarecomposition.ApplicationProfileSpec{
+			Containers: []softwarecomposition.ApplicationProfileContainer{
+				{Name: "coredns", Capabilities: []string{"COMPILED_CAP"}},
+			},
+		},
+	})
+	h.createCP("testdata/p2.json")
+	h.consolidate()
+	merged := h.requireMerged()
+	compiled = loadCompiled()
+	assert.Equal(t, merged.ResourceVersion, compiled.Spec.SourceResourceVersion)
+	assert.True(t, decode(compiled).HasCapability("COMPILED_CAP"))
+
+	// an unchanged effective profile is not recompiled
+	cps := NewContainerProfileStorageImpl(h.s, h.pool)
+	require.NoError(t, cps.SaveCompiledContainerProfile(context.WithValue(h.ctx, connKey, h.conn), e2eCPKey(), &merged))
+	assert.Equal(t, compiled.ResourceVersion, loadCompiled().ResourceVersion)
+
+	// the getter serves the stored artifact while it is current, and compiles
+	// afresh otherwise
+	get := NewCompiledContainerProfileGetter(h.s, func(context.Context, string) (string, error) { return e2eCPKey(), nil })
+	current := h.requireMerged()
+	got, err := get(h.ctx, current.Name, &current)
+	require.NoError(t, err)
+	assert.Equal(t, loadCompiled().ResourceVersion, got.ResourceVersion)
+
+	stale := current.DeepCopy()
+	stale.ResourceVersion = "0"
+	stale.Spec.Capabilities = []string{"FRESH_CAP"}
+	got, err = get(h.ctx, stale.Name, stale)
+	require.NoError(t, err)
+	assert.Empty(t, got.ResourceVersion)
+	assert.Equal(t, "0", got.Spec.SourceResourceVersion)
+	assert.Equal(t, []string{"FRESH_CAP"}, decode(*got).Capabilities)
+
+	// deleting the profile deletes the compiled sibling
+	require.NoError(t, NewContainerProfileRESTStorage(h.s).Delete(h.ctx, e2eCPKey(), &softwarecomposition.ContainerProfile{}, nil, storage.ValidateAllObjectFunc, nil, storage.DeleteOptions{}))
+	err = h.s.GetWithConn(h.ctx, h.conn, CompiledKeyFor(e2eCPKey()), storage.GetOptions{}, &softwarecomposition.CompiledContainerProfile{})
+	assert.True(t, storage.IsNotFound(err))
+}
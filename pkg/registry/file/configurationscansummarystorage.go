@@ -3,16 +3,17 @@ package file
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/kubescape/go-logger"
 	"github.com/kubescape/go-logger/helpers"
 	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
-	"github.com/kubescape/storage/pkg/apis/softwarecomposition/v1beta1"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/apiserver/pkg/storage"
 )
 
@@ -21,7 +22,7 @@ const (
 	workloadConfigurationScanSummariesResource = "workloadconfigurationscansummaries"
 )
 
-// ConfigurationScanSummaryStorage offers a storage solution for ConfigurationScanSummary objects, implementing custom business logic for these objects and using the underlying default storage implementation.
+// ConfigurationScanSummaryStorage offers a storage solution for ConfigurationScanSummary objects, serving the namespace rollups materialized by the underlying default storage implementation.
 type ConfigurationScanSummaryStorage struct {
 	immutableStorage
 	realStore StorageQuerier
@@ -47,37 +48,24 @@ func NewConfigurationScanSummaryStorage(realStore StorageQuerier) storage.Interf
 	return &ConfigurationScanSummaryStorage{realStore: realStore}
 }
 
-func (s *ConfigurationScanSummaryStorage) GetCurrentResourceVersion(_ context.Context) (uint64, error) {
-	return 0, nil
+// GetCurrentResourceVersion returns the revision of the storage the summaries are maintained in.
+func (s *ConfigurationScanSummaryStorage) GetCurrentResourceVersion(ctx context.Context) (uint64, error) {
+	return s.realStore.GetCurrentResourceVersion(ctx)
 }
 
-// Get generates and returns a single ConfigurationScanSummary object for a namespace
+// Get returns the ConfigurationScanSummary object of a namespace
 func (s *ConfigurationScanSummaryStorage) Get(ctx context.Context, key string, _ storage.GetOptions, objPtr runtime.Object) error {
 	ctx, span := otel.Tracer("").Start(ctx, "ConfigurationScanSummaryStorage.Get")
 	span.SetAttributes(attribute.String("key", key))
 	defer span.End()
 
-	workloadScanSummaryListObjPtr := &softwarecomposition.WorkloadConfigurationScanSummaryList{}
-
 	namespace := getNamespaceFromKey(key)
 
-	if err := s.realStore.GetByNamespace(ctx, v1beta1.GroupName, workloadConfigurationScanSummariesResource, namespace, workloadScanSummaryListObjPtr); err != nil {
-		return err
-	}
-
-	if &workloadScanSummaryListObjPtr == nil {
-		return storage.NewInternalError(fmt.Errorf("workload scan summary list is nil"))
-	}
-
-	if len(workloadScanSummaryListObjPtr.Items) == 0 {
+	data, err := s.realStore.GetSummary(ctx, configurationScanSummariesVirtualResource, namespace)
+	if errors.Is(err, ErrSummaryNotFound) {
 		return storage.NewKeyNotFoundError(key, 0)
 	}
-
-	configurationScanSummaryObj := buildConfigurationScanSummary(*workloadScanSummaryListObjPtr, namespace)
-
-	data, err := json.Marshal(configurationScanSummaryObj)
 	if err != nil {
-		logger.L().Ctx(ctx).Error("json marshal failed", helpers.Error(err), helpers.String("key", key))
 		return err
 	}
 
@@ -89,37 +77,54 @@ func (s *ConfigurationScanSummaryStorage) Get(ctx context.Context, key string, _
 	return nil
 }
 
-// GetList generates and returns a list of ConfigurationScanSummary objects for the cluster
+// GetList returns the list of ConfigurationScanSummary objects for the cluster
 func (s *ConfigurationScanSummaryStorage) GetList(ctx context.Context, key string, opts storage.ListOptions, listObj runtime.Object) error {
 	ctx, span := otel.Tracer("").Start(ctx, "ConfigurationScanSummaryStorage.GetList")
 	span.SetAttributes(attribute.String("key", key))
 	defer span.End()
 
-	workloadScanSummaryListObjPtr := &softwarecomposition.WorkloadConfigurationScanSummaryList{}
-
-	// ask for all workloadconfigurationscansummaries in the cluster.
-	// The underlying store caps each page at a default limit and returns a
-	// continuation token, so we must page through every token to make sure the
-	// aggregation covers all objects and not just the first page (issue #337).
-	listOpts := opts
-	listOpts.Predicate.Continue = ""
-	for {
-		page := &softwarecomposition.WorkloadConfigurationScanSummaryList{}
-		if err := s.realStore.GetList(ctx, "/spdx.softwarecomposition.kubescape.io/"+workloadConfigurationScanSummariesResource, listOpts, page); err != nil {
+	// the namespace summaries are maintained incrementally on every write of their
+	// workloadconfigurationscansummaries, so listing them does not touch the payloads
+	summaries, err := s.realStore.ListSummaries(ctx, configurationScanSummariesVirtualResource)
+	if err != nil {
+		return err
+	}
+
+	nsSummaries := softwarecomposition.ConfigurationScanSummaryList{
+		TypeMeta: v1.TypeMeta{
+			Kind:       configurationScanSummaryKind,
+			APIVersion: StorageV1Beta1ApiVersion,
+		},
+	}
+	for _, data := range summaries {
+		var summary softwarecomposition.ConfigurationScanSummary
+		if err := json.Unmarshal(data, &summary); err != nil {
+			logger.L().Ctx(ctx).Error("json unmarshal failed", helpers.Error(err), helpers.String("key", key))
 			return err
 		}
-		workloadScanSummaryListObjPtr.Items = append(workloadScanSummaryListObjPtr.Items, page.Items...)
-
-		// the store only sets a continuation token when the page was truncated;
-		// an empty token means we have read the last page.
-		listOpts.Predicate.Continue = page.Continue
-		if listOpts.Predicate.Continue == "" {
-			break
+		// the selectors apply to the summaries, before they are paged
+		if !hasSelectors(opts.Predicate) || matches(opts.Predicate, &summary) {
+			nsSummaries.Items = append(nsSummaries.Items, summary)
 		}
 	}
 
-	// generate a single configurationScanSummary for the cluster, with a configuration scan summary for each namespace
-	nsSummaries := buildConfigurationScanSummaryForCluster(*workloadScanSummaryListObjPtr)
+	// the summaries are few, one per namespace, so they are paged in memory
+	page, token, last, remaining, err := paginate(key, opts, nsSummaries.Items, func(summary softwarecomposition.ConfigurationScanSummary) listPosition {
+		return listPosition{Name: summary.Name}
+	})
+	if err != nil {
+		return err
+	}
+	nsSummaries.Items = page
+	resourceVersion := token.ResourceVersion
+	if resourceVersion == 0 {
+		if resourceVersion, err = s.realStore.GetCurrentResourceVersion(ctx); err != nil {
+			return err
+		}
+	}
+	if err := setListMeta(&nsSummaries, key, opts, resourceVersion, last, remaining); err != nil {
+		return err
+	}
 
 	data, err := json.Marshal(nsSummaries)
 	if err != nil {
@@ -135,62 +140,45 @@ func (s *ConfigurationScanSummaryStorage) GetList(ctx context.Context, key strin
 	return nil
 }
 
-// buildConfigurationScanSummaryForCluster generates a configuration scan summary list for the cluster, where each item is a configuration scan summary for a namespace
-func buildConfigurationScanSummaryForCluster(list softwarecomposition.WorkloadConfigurationScanSummaryList) softwarecomposition.ConfigurationScanSummaryList {
-
-	// build a map of namespace to workload configuration scan summaries
-	perNS := map[string][]softwarecomposition.WorkloadConfigurationScanSummary{}
-	for _, s := range list.Items {
-		perNS[s.Namespace] = append(perNS[s.Namespace], s)
+// Watch emits the namespace ConfigurationScanSummary matching opts whenever one of the WorkloadConfigurationScanSummary
+// objects of that namespace changes, and a Deleted one when the last of them is removed.
+func (s *ConfigurationScanSummaryStorage) Watch(ctx context.Context, key string, opts storage.ListOptions) (watch.Interface, error) {
+	source, err := s.realStore.Watch(ctx, "/"+softwarecomposition.GroupName+"/"+workloadConfigurationScanSummariesResource, derivedWatchSourceOptions(opts))
+	if err != nil {
+		return nil, err
 	}
+	return newDerivedWatch(ctx, source, key, opts, s, derivedWatchCoalesceWindow), nil
+}
 
-	ret := softwarecomposition.ConfigurationScanSummaryList{
-		TypeMeta: v1.TypeMeta{
-			Kind:       configurationScanSummaryKind,
-			APIVersion: StorageV1Beta1ApiVersion,
-		},
-	}
+func (s *ConfigurationScanSummaryStorage) derivedKey(ev watch.Event) (string, bool) {
+	return namespaceSummaryKey(ev, configurationScanSummariesVirtualResource)
+}
 
-	type wList = softwarecomposition.WorkloadConfigurationScanSummaryList
-	// 1 - build a workload configuration scan summary list for each namespace
-	// 2 - generate a single configuration scan summary for the namespace
-	// 3 - add the configuration scan summary to the cluster summary list object
-	for ns, sums := range perNS {
-		// for each namespace, create a single workload configuration scan summary object
-		ret.Items = append(ret.Items, buildConfigurationScanSummary(wList{Items: sums}, ns))
-	}
+func (s *ConfigurationScanSummaryStorage) resolve(ctx context.Context, key string) (runtime.Object, error) {
+	obj := &softwarecomposition.ConfigurationScanSummary{}
+	return obj, s.Get(ctx, key, storage.GetOptions{}, obj)
+}
 
-	return ret
+func (s *ConfigurationScanSummaryStorage) list(ctx context.Context, key string) (map[string]runtime.Object, error) {
+	list := &softwarecomposition.ConfigurationScanSummaryList{}
+	if err := s.GetList(ctx, key, storage.ListOptions{Recursive: true}, list); err != nil {
+		return nil, err
+	}
+	objs := make(map[string]runtime.Object, len(list.Items))
+	for i := range list.Items {
+		objs[K8sClusterScopedKeysToPath("", softwarecomposition.GroupName, configurationScanSummariesVirtualResource, list.Items[i].Name)] = &list.Items[i]
+	}
+	return objs, nil
 }
 
-// buildConfigurationScanSummary generates a single configuration scan summary for the given namespace
-func buildConfigurationScanSummary(list softwarecomposition.WorkloadConfigurationScanSummaryList, namespace string) softwarecomposition.ConfigurationScanSummary {
-	summary := softwarecomposition.ConfigurationScanSummary{
+func (s *ConfigurationScanSummaryStorage) tombstone(key string) runtime.Object {
+	return &softwarecomposition.ConfigurationScanSummary{
 		TypeMeta: v1.TypeMeta{
 			Kind:       configurationScanSummaryKind,
 			APIVersion: StorageV1Beta1ApiVersion,
 		},
 		ObjectMeta: v1.ObjectMeta{
-			Name: namespace,
+			Name: getNamespaceFromKey(key),
 		},
 	}
-
-	for i := range list.Items {
-		summary.Spec.Severities.Critical += list.Items[i].Spec.Severities.Critical
-		summary.Spec.Severities.High += list.Items[i].Spec.Severities.High
-		summary.Spec.Severities.Medium += list.Items[i].Spec.Severities.Medium
-		summary.Spec.Severities.Low += list.Items[i].Spec.Severities.Low
-		summary.Spec.Severities.Unknown += list.Items[i].Spec.Severities.Unknown
-
-		id := softwarecomposition.WorkloadConfigurationScanSummaryIdentifier{
-			Namespace: list.Items[i].Namespace,
-			Kind:      "WorkloadConfigurationScanSummary",
-			Name:      list.Items[i].Name,
-		}
-
-		summary.Spec.WorkloadConfigurationScanSummaryIdentifiers = append(summary.Spec.WorkloadConfigurationScanSummaryIdentifiers, id)
-
-	}
-
-	return summary
 }
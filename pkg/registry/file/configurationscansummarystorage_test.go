@@ -41,10 +41,14 @@ func TestConfigurationScanSummaryStorage_Delete(t *testing.T) {
 }
 
 func TestConfigurationScanSummaryStorage_Watch(t *testing.T) {
-	storageImpl := NewStorageImpl(afero.NewMemMapFs(), "", nil, nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pool := NewTestPool(t.TempDir())
+	defer func() { _ = pool.Close() }()
+	storageImpl := NewStorageImpl(afero.NewMemMapFs(), "", pool, nil, nil)
 	configScanSummaryStorage := NewConfigurationScanSummaryStorage(storageImpl)
 
-	_, err := configScanSummaryStorage.Watch(context.TODO(), "", storage.ListOptions{})
+	_, err := configScanSummaryStorage.Watch(ctx, "/spdx.softwarecomposition.kubescape.io/configurationscansummaries", storage.ListOptions{})
 	assert.NoError(t, err)
 }
 
@@ -810,3 +814,63 @@ func TestGetNamespaceFromKey(t *testing.T) {
 	}
 
 }
+
+// buildConfigurationScanSummaryForCluster generates a configuration scan summary list for the cluster, where each item is a configuration scan summary for a namespace
+func buildConfigurationScanSummaryForCluster(list softwarecomposition.WorkloadConfigurationScanSummaryList) softwarecomposition.ConfigurationScanSummaryList {
+
+	// build a map of namespace to workload configuration scan summaries
+	perNS := map[string][]softwarecomposition.WorkloadConfigurationScanSummary{}
+	for _, s := range list.Items {
+		perNS[s.Namespace] = append(perNS[s.Namespace], s)
+	}
+
+	ret := softwarecomposition.ConfigurationScanSummaryList{
+		TypeMeta: v1.TypeMeta{
+			Kind:       configurationScanSummaryKind,
+			APIVersion: StorageV1Beta1ApiVersion,
+		},
+	}
+
+	type wList = softwarecomposition.WorkloadConfigurationScanSummaryList
+	// 1 - build a workload configuration scan summary list for each namespace
+	// 2 - generate a single configuration scan summary for the namespace
+	// 3 - add the configuration scan summary to the cluster summary list object
+	for ns, sums := range perNS {
+		// for each namespace, create a single workload configuration scan summary object
+		ret.Items = append(ret.Items, buildConfigurationScanSummary(wList{Items: sums}, ns))
+	}
+
+	return ret
+}
+
+// buildConfigurationScanSummary generates a single configuration scan summary for the given namespace
+func buildConfigurationScanSummary(list softwarecomposition.WorkloadConfigurationScanSummaryList, namespace string) softwarecomposition.ConfigurationScanSummary {
+	summary := softwarecomposition.ConfigurationScanSummary{
+		TypeMeta: v1.TypeMeta{
+			Kind:       configurationScanSummaryKind,
+			APIVersion: StorageV1Beta1ApiVersion,
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name: namespace,
+		},
+	}
+
+	for i := range list.Items {
+		summary.Spec.Severities.Critical += list.Items[i].Spec.Severities.Critical
+		summary.Spec.Severities.High += list.Items[i].Spec.Severities.High
+		summary.Spec.Severities.Medium += list.Items[i].Spec.Severities.Medium
+		summary.Spec.Severities.Low += list.Items[i].Spec.Severities.Low
+		summary.Spec.Severities.Unknown += list.Items[i].Spec.Severities.Unknown
+
+		id := softwarecomposition.WorkloadConfigurationScanSummaryIdentifier{
+			Namespace: list.Items[i].Namespace,
+			Kind:      "WorkloadConfigurationScanSummary",
+			Name:      list.Items[i].Name,
+		}
+
+		summary.Spec.WorkloadConfigurationScanSummaryIdentifiers = append(summary.Spec.WorkloadConfigurationScanSummaryIdentifiers, id)
+
+	}
+
+	return summary
+}
@@ -77,6 +77,9 @@ func (f *fakeStorage) GetMergedContainerProfile(ctx context.Context, observedKey
 func (f *fakeStorage) DeleteMergedContainerProfile(ctx context.Context, observedKey string) error {
 	return nil
 }
+func (f *fakeStorage) SaveCompiledContainerProfile(ctx context.Context, observedKey string, profile *softwarecomposition.ContainerProfile) error {
+	return nil
+}
 func (f *fakeStorage) UpdateApplicationProfile(ctx context.Context, key, prefix, root string, id armotypes.ProfileIdentifier, slug, wlid string, instanceID interface{ GetStringNoContainer() string }, profile *softwarecomposition.ContainerProfile, creationTimestamp metav1.Time) error {
 	return nil
 }
@@ -9,6 +9,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/armosec/armoapi-go/armotypes"
@@ -44,6 +45,10 @@ type ContainerProfileProcessor struct {
 	Interval                time.Duration
 	LastCleanup             time.Time
 	MaxContainerProfileSize int
+	// CallStackMaxDepth and CallStackMaxBreadth bound the unified call stack
+	// trees kept in a profile, see callstack.PruneCallStack.
+	CallStackMaxDepth       int
+	CallStackMaxBreadth     int
 	ContainerProfileStorage ContainerProfileStorage
 	ConsolidatedSlugChannel chan ConsolidatedSlugData
 	// CollapseSettings is the lookup hook the deflate path consults for
@@ -51,6 +56,16 @@ type ContainerProfileProcessor struct {
 	// production wiring may swap to a provider that reads the cluster-scoped
 	// CollapseConfiguration "default" CR.
 	CollapseSettings dynamicpathdetector.CollapseSettingsProvider
+	// CollapseSettingsResolver, when set, takes precedence over
+	// CollapseSettings and picks the settings of each profile from its
+	// namespace and labels.
+	CollapseSettingsResolver CollapseSettingsResolver
+	// Guardrails returns the Guardrail rules consolidated profiles are
+	// evaluated against, nil disables the evaluation.
+	Guardrails GuardrailsProvider
+	// SchemaMigrations tells the cleanup which schema versions the profiles
+	// can be migrated from, nil keeping only SchemaVersion.
+	SchemaMigrations *SchemaMigrations
 	// Workers bounds how many keys ConsolidateTimeSeries processes concurrently,
 	// each on its own pool connection. Kept a fraction of the pool size so the
 	// background consolidation never starves REST traffic of connections.
@@ -59,6 +74,9 @@ type ContainerProfileProcessor struct {
 	// ConsolidateTimeSeries. nil means use consolidateKeyTimeSeries; tests
 	// override it to count invocations or inject per-key failures.
 	consolidateKey func(ctx context.Context, key string, expired bool) error
+	// limitsMu guards CleanupInterval, DeleteThreshold and
+	// MaxContainerProfileSize once the processor is in use, see Reload.
+	limitsMu sync.RWMutex
 }
 
 func NewContainerProfileProcessor(cfg config.Config, cleanupHandler *ResourcesCleanupHandler) *ContainerProfileProcessor {
@@ -74,6 +92,8 @@ func NewContainerProfileProcessor(cfg config.Config, cleanupHandler *ResourcesCl
 		HostType:                hostType,
 		Interval:                30 * time.Second,
 		MaxContainerProfileSize: cfg.MaxApplicationProfileSize,
+		CallStackMaxDepth:       cfg.CallStackMaxDepth,
+		CallStackMaxBreadth:     cfg.CallStackMaxBreadth,
 		CollapseSettings:        dynamicpathdetector.DefaultCollapseSettings,
 		Workers:                 max(1, DefaultPoolSize/4),
 	}
@@ -81,6 +101,24 @@ func NewContainerProfileProcessor(cfg config.Config, cleanupHandler *ResourcesCl
 
 var _ Processor = (*ContainerProfileProcessor)(nil)
 
+// Reload applies the cleanup interval, sniffing time and size limit of cfg
+// to the next cleanup, consolidation and profiles saved.
+func (a *ContainerProfileProcessor) Reload(cfg *config.Config) {
+	a.limitsMu.Lock()
+	defer a.limitsMu.Unlock()
+	a.CleanupInterval = cfg.CleanupInterval
+	a.DeleteThreshold = 2 * cfg.MaxSniffingTime
+	a.MaxContainerProfileSize = cfg.MaxApplicationProfileSize
+}
+
+// limits returns the CleanupInterval, DeleteThreshold and
+// MaxContainerProfileSize in effect.
+func (a *ContainerProfileProcessor) limits() (cleanupInterval, deleteThreshold time.Duration, maxSize int) {
+	a.limitsMu.RLock()
+	defer a.limitsMu.RUnlock()
+	return a.CleanupInterval, a.DeleteThreshold, a.MaxContainerProfileSize
+}
+
 // AfterCreate is called after a TS ContainerProfile is created to store metadata.
 func (a *ContainerProfileProcessor) AfterCreate(ctx context.Context, object runtime.Object) error {
 	profile, ok := object.(*softwarecomposition.ContainerProfile)
@@ -194,21 +232,37 @@ func (a *ContainerProfileProcessor) PreSave(ctx context.Context, object runtime.
 	} else {
 		logger.L().Debug("ContainerProfileProcessor.PreSave - failed to get sbom name", loggerhelpers.Error(err), loggerhelpers.String("imageTag", profile.Spec.ImageTag), loggerhelpers.String("imageID", profile.Spec.ImageID))
 	}
-	settings := dynamicpathdetector.DefaultCollapseSettings()
-	if a.CollapseSettings != nil {
+	settings, collapseConfiguration := dynamicpathdetector.DefaultCollapseSettings(), ""
+	if a.CollapseSettingsResolver != nil {
+		settings, collapseConfiguration = a.CollapseSettingsResolver(ctx, profile.Namespace, profile.Labels)
+	} else if a.CollapseSettings != nil {
 		settings = a.CollapseSettings()
 	}
-	profile.Spec = DeflateContainerProfileSpec(profile.Spec, sbomSet, settings)
-	size += len(profile.Spec.Execs)
-	size += len(profile.Spec.Opens)
-	size += len(profile.Spec.Syscalls)
-	size += len(profile.Spec.Capabilities)
-	size += len(profile.Spec.Endpoints)
-	size += len(profile.Spec.IdentifiedCallStacks)
-	size += len(profile.Spec.Ingress)
-	size += len(profile.Spec.Egress)
-
-	if size > a.MaxContainerProfileSize {
+	// deflate deflates the spec with the collapsing strengthened for level,
+	// see MaxDegradationLevel
+	original := profile.Spec
+	deflate := func(level int) softwarecomposition.ContainerProfileSpec {
+		spec := original
+		spec.Execs = degradeExecs(spec.Execs, level)
+		spec = DeflateContainerProfileSpec(spec, sbomSet, degradeCollapseSettings(settings, level))
+		var pruned bool
+		if spec.IdentifiedCallStacks, pruned = callstack.PruneIdentifiedCallStacks(spec.IdentifiedCallStacks, a.CallStackMaxDepth, a.CallStackMaxBreadth); pruned {
+			logger.L().Debug("ContainerProfileProcessor.PreSave - pruned call stacks", loggerhelpers.String("name", profile.Name), loggerhelpers.String("namespace", profile.Namespace))
+		}
+		return spec
+	}
+	profile.Spec = deflate(0)
+	size = containerProfileSpecSize(profile.Spec)
+	_, _, maxSize := a.limits()
+	var degradationLevel int
+	if size > maxSize {
+		profile.Spec, degradationLevel = degradeUntilFits(deflate, containerProfileSpecSize, maxSize)
+		size = containerProfileSpecSize(profile.Spec)
+		logger.L().Debug("ContainerProfileProcessor.PreSave - degraded profile", loggerhelpers.String("name", profile.Name), loggerhelpers.String("namespace", profile.Namespace),
+			loggerhelpers.Int("level", degradationLevel))
+	}
+
+	if size > maxSize {
 		// set annotation but don't return an error as we want to save the profile anyway
 		profile.Annotations[helpers.StatusMetadataKey] = helpers.TooLarge
 	}
@@ -218,6 +272,13 @@ func (a *ContainerProfileProcessor) PreSave(ctx context.Context, object runtime.
 		profile.Annotations = make(map[string]string)
 	}
 	profile.Annotations[helpers.ResourceSizeMetadataKey] = strconv.Itoa(size)
+	recordDegradationLevel(profile, degradationLevel)
+	recordCollapseConfiguration(profile, collapseConfiguration)
+	if a.Guardrails != nil {
+		if err := applyGuardrails(a.Guardrails(), profile); err != nil {
+			logger.L().Warning("ContainerProfileProcessor.PreSave - guardrails evaluation failed", loggerhelpers.Error(err))
+		}
+	}
 
 	return nil
 }
@@ -253,24 +314,30 @@ func (a *ContainerProfileProcessor) runMaintenanceTasks() {
 }
 
 func (a *ContainerProfileProcessor) cleanup() error {
-	if a.CleanupInterval == 0 && !a.LastCleanup.IsZero() {
+	cleanupInterval, _, _ := a.limits()
+	if cleanupInterval == 0 && !a.LastCleanup.IsZero() {
 		// no cleanup interval set, we run cleanup only once
 		return nil
 	}
-	if time.Since(a.LastCleanup) < a.CleanupInterval {
+	if time.Since(a.LastCleanup) < cleanupInterval {
 		// cleanup interval not reached yet
 		return nil
 	}
 	a.LastCleanup = time.Now()
 	resourceToKindHandler := map[string][]TypeCleanupHandlerFunc{
-		"applicationprofiles": {deleteWrongSchemaVersion, deleteByTemplateHashOrWlid},
+		"applicationprofiles": {a.SchemaMigrations.deleteWrongSchemaVersion, deleteByTemplateHashOrWlid},
 		"containerprofiles":   {deleteByTemplateHashOrWlid},
 		// The merged (effective) CP carries the same templateHash/wlid metadata
 		// as its observed sibling, so the same predicate retires orphans. This
 		// covers workloads that get age-cleaned without going through the REST
 		// Delete path (which already cascades to the merged sibling).
 		ContainerProfileMergedKind: {deleteByTemplateHashOrWlid},
-		"networkneighborhoods":     {deleteWrongSchemaVersion, deleteByTemplateHashOrWlid},
+		// So does the compiled CP.
+		ContainerProfileCompiledKind: {deleteByTemplateHashOrWlid},
+		// And the approved revisions.
+		ApplicationProfileApprovedKind: {deleteByTemplateHashOrWlid},
+		ContainerProfileApprovedKind:   {deleteByTemplateHashOrWlid},
+		"networkneighborhoods":         {a.SchemaMigrations.deleteWrongSchemaVersion, deleteByTemplateHashOrWlid},
 	}
 	return a.CleanupHandler.CleanupTask(context.TODO(), resourceToKindHandler)
 }
@@ -291,7 +358,8 @@ func (a *ContainerProfileProcessor) ConsolidateTimeSeries(ctx context.Context) e
 		return fmt.Errorf("failed to take connection for listing: %w", err)
 	}
 	// Phase 1: expired time series (past deleteThreshold), marked Completed/Partial.
-	expired, err := a.ContainerProfileStorage.ListTimeSeriesExpired(listCtx, a.DeleteThreshold)
+	_, deleteThreshold, _ := a.limits()
+	expired, err := a.ContainerProfileStorage.ListTimeSeriesExpired(listCtx, deleteThreshold)
 	if err != nil {
 		cleanup()
 		return fmt.Errorf("failed to list expired time series: %w", err)
@@ -570,6 +638,12 @@ func (a *ContainerProfileProcessor) updateProfile(ctx context.Context, timeSerie
 		return nil, err
 	}
 
+	// The compiled form follows the effective CP, it is only recompiled when
+	// the effective CP changed.
+	if err := a.ContainerProfileStorage.SaveCompiledContainerProfile(ctx, key, effective); err != nil {
+		return nil, fmt.Errorf("failed to save compiled container profile: %w", err)
+	}
+
 	// Aggregated AP/NN derive from the effective CP so all downstream outputs
 	// stay aligned with what node-agent actually reads (step 6 of the review).
 	// Still gated on newData to preserve the existing 30s aggregation cadence —
@@ -886,19 +960,26 @@ func (a *ContainerProfileProcessor) getAggregatedData(ctx context.Context, key s
 	return status, completion, hash
 }
 
+// containerProfileSpecSize is the number of entries of spec counted against
+// the size limit of its profile.
+func containerProfileSpecSize(spec softwarecomposition.ContainerProfileSpec) int {
+	return len(spec.Execs) + len(spec.Opens) + len(spec.Syscalls) + len(spec.Capabilities) + len(spec.Endpoints) +
+		len(spec.IdentifiedCallStacks) + len(spec.Ingress) + len(spec.Egress)
+}
+
 func DeflateContainerProfileSpec(container softwarecomposition.ContainerProfileSpec, sbomSet mapset.Set[string], settings dynamicpathdetector.CollapseSettings) softwarecomposition.ContainerProfileSpec {
 	opens, err := dynamicpathdetector.AnalyzeOpens(container.Opens, dynamicpathdetector.NewPathAnalyzerWithConfigs(settings.OpenDynamicThreshold, settings.CollapseConfigs), sbomSet)
 	if err != nil {
 		logger.L().Debug("ContainerProfileProcessor.deflateContainerProfileSpec - falling back to DeflateStringer for opens", loggerhelpers.Error(err))
 		opens = DeflateStringer(container.Opens)
 	}
-	endpoints := dynamicpathdetector.AnalyzeEndpoints(&container.Endpoints, dynamicpathdetector.NewPathAnalyzerWithConfigs(settings.EndpointDynamicThreshold, settings.CollapseConfigs))
+	endpoints := dynamicpathdetector.AnalyzeEndpoints(&container.Endpoints, dynamicpathdetector.NewPathAnalyzerWithConfigs(settings.EndpointDynamicThreshold, settings.CollapseConfigs).WithHeaderAllowlist(settings.HeaderAllowlist))
 	identifiedCallStacks := callstack.UnifyIdentifiedCallStacks(container.IdentifiedCallStacks)
 
 	return softwarecomposition.ContainerProfileSpec{
 		Architectures:        DeflateSortString(container.Architectures),
 		Capabilities:         DeflateSortString(container.Capabilities),
-		Execs:                DeflateStringer(container.Execs),
+		Execs:                dynamicpathdetector.AnalyzeExecs(container.Execs, settings.ExecArgsCollapseThreshold, settings.EnvCollapseThreshold),
 		Opens:                opens,
 		Syscalls:             DeflateSortString(container.Syscalls),
 		SeccompProfile:       container.SeccompProfile,
@@ -911,8 +992,8 @@ func DeflateContainerProfileSpec(container softwarecomposition.ContainerProfileS
 			MatchLabels:      container.MatchLabels,
 			MatchExpressions: DeflateLabelSelectorRequirement(container.MatchExpressions),
 		},
-		Ingress: deflateNetworkNeighbors(container.Ingress),
-		Egress:  deflateNetworkNeighbors(container.Egress),
+		Ingress: deflateNetworkNeighbors(container.Ingress, settings),
+		Egress:  deflateNetworkNeighbors(container.Egress, settings),
 	}
 }
 
@@ -26,6 +26,10 @@ import (
 // Writes / List / Watch / Delete / GuaranteedUpdate pass straight through to
 // the canonical key. The merged artifact is exclusively maintained by the
 // consolidator (refreshMergedProfile), never by REST clients.
+//
+// Once a revision is approved, Get, List and Watch serve its frozen spec
+// instead, see serveApprovedRevision, and GuaranteedUpdate maintains the
+// approved sibling.
 type ContainerProfileRESTStorage struct {
 	realStore StorageQuerier
 }
@@ -57,19 +61,28 @@ func (c ContainerProfileRESTStorage) Delete(ctx context.Context, key string, out
 	// other failure is surfaced as a hard error: swallowing it would orphan the
 	// merged artifact and let the merged-first read path keep serving a profile
 	// whose observed sibling is gone. The caller (apiserver) retries the delete.
+	// The compiled sibling goes the same way.
 	if _, ok := out.(*softwarecomposition.ContainerProfile); ok {
 		mergedKey := MergedKeyFor(key)
 		if mergedKey != key {
 			if err := c.realStore.Delete(ctx, mergedKey, &softwarecomposition.ContainerProfile{}, nil, storage.ValidateAllObjectFunc, nil, storage.DeleteOptions{}); err != nil && !storage.IsNotFound(err) {
 				return fmt.Errorf("delete merged container profile sibling: %w", err)
 			}
+			if err := c.realStore.Delete(ctx, CompiledKeyFor(key), &softwarecomposition.CompiledContainerProfile{}, nil, storage.ValidateAllObjectFunc, nil, storage.DeleteOptions{}); err != nil && !storage.IsNotFound(err) {
+				return fmt.Errorf("delete compiled container profile sibling: %w", err)
+			}
 		}
 	}
 	return c.realStore.Delete(ctx, key, out, preconditions, validateDeletion, cachedExistingObject, opts)
 }
 
+// Watch sends the approved revisions of the profiles, see serveApprovedWatch.
 func (c ContainerProfileRESTStorage) Watch(ctx context.Context, key string, opts storage.ListOptions) (watch.Interface, error) {
-	return c.realStore.Watch(ctx, key, opts)
+	w, err := c.realStore.Watch(ctx, key, opts)
+	if err != nil {
+		return nil, err
+	}
+	return serveApprovedWatch(ctx, c.realStore, key, opts, w), nil
 }
 
 func (c ContainerProfileRESTStorage) Get(ctx context.Context, key string, opts storage.GetOptions, objPtr runtime.Object) error {
@@ -78,6 +91,14 @@ func (c ContainerProfileRESTStorage) Get(ctx context.Context, key string, opts s
 		// don't surprise an unexpected caller with a kind-segment rewrite.
 		return c.realStore.Get(ctx, key, opts, objPtr)
 	}
+	if err := c.get(ctx, key, opts, objPtr); err != nil {
+		return err
+	}
+	return serveApprovedRevision(ctx, c.realStore, key, objPtr)
+}
+
+// get returns the pending revision of the profile, merged-first.
+func (c ContainerProfileRESTStorage) get(ctx context.Context, key string, opts storage.GetOptions, objPtr runtime.Object) error {
 	mergedKey := MergedKeyFor(key)
 	if mergedKey != key {
 		err := c.realStore.Get(ctx, mergedKey, opts, objPtr)
@@ -104,12 +125,16 @@ func (c ContainerProfileRESTStorage) GetList(ctx context.Context, key string, op
 	// need the merged view will call Get per-item, which this wrapper handles.
 	// Scoping List to merged-first would require interleaving two kinds and
 	// reconciling per-item; the maintainer's review explicitly asked for the
-	// read-path fallback (step 4), not list rewriting.
-	return c.realStore.GetList(ctx, key, opts, listObj)
+	// read-path fallback (step 4), not list rewriting. Approved profiles are
+	// listed with their approved revision, see serveApprovedList.
+	if err := c.realStore.GetList(ctx, key, opts, listObj); err != nil {
+		return err
+	}
+	return serveApprovedList(ctx, c.realStore, key, opts, listObj)
 }
 
 func (c ContainerProfileRESTStorage) GuaranteedUpdate(ctx context.Context, key string, destination runtime.Object, ignoreNotFound bool, preconditions *storage.Preconditions, tryUpdate storage.UpdateFunc, cachedExistingObject runtime.Object) error {
-	return c.realStore.GuaranteedUpdate(ctx, key, destination, ignoreNotFound, preconditions, tryUpdate, cachedExistingObject)
+	return guaranteedUpdateWithApproval(ctx, c.realStore, key, c.Get, destination, ignoreNotFound, preconditions, tryUpdate, cachedExistingObject)
 }
 
 func (c ContainerProfileRESTStorage) ReadinessCheck() error {
@@ -2,6 +2,7 @@ package file
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -10,6 +11,7 @@ import (
 	"github.com/armosec/armoapi-go/armotypes"
 	"github.com/kubescape/k8s-interface/instanceidhandler/v1/helpers"
 	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"github.com/kubescape/storage/pkg/compiledprofile"
 	"github.com/kubescape/storage/pkg/utils"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -25,11 +27,14 @@ import (
 // overlay — written under a parallel key so consumers can prefer it without
 // the consolidator ever reading it back. The split exists to preserve a
 // canonical observed CP that retracts cleanly when a user edits or deletes
-// a ug- CRD (kubescape/storage#315 review).
+// a ug- CRD (kubescape/storage#315 review). CompiledKind is the
+// CompiledContainerProfile of the effective CP, served on the compiled
+// subresource.
 const (
-	ContainerProfileKind       = "containerprofile"
-	ContainerProfileKindPlural = "containerprofiles"
-	ContainerProfileMergedKind = "containerprofile-merged"
+	ContainerProfileKind         = "containerprofile"
+	ContainerProfileKindPlural   = "containerprofiles"
+	ContainerProfileMergedKind   = "containerprofile-merged"
+	ContainerProfileCompiledKind = "containerprofile-compiled"
 )
 
 // MergedKeyFor returns the merged-CP storage key corresponding to an
@@ -41,6 +46,12 @@ func MergedKeyFor(observedKey string) string {
 	return strings.Replace(observedKey, "/"+ContainerProfileKind+"/", "/"+ContainerProfileMergedKind+"/", 1)
 }
 
+// CompiledKeyFor returns the compiled-CP storage key corresponding to an
+// observed-CP key, see MergedKeyFor.
+func CompiledKeyFor(observedKey string) string {
+	return strings.Replace(observedKey, "/"+ContainerProfileKind+"/", "/"+ContainerProfileCompiledKind+"/", 1)
+}
+
 // ContainerProfileStorageImpl implements ContainerProfileStorage using SQLite as the backend.
 type ContainerProfileStorageImpl struct {
 	storageImpl *StorageImpl
@@ -230,6 +241,62 @@ func (c *ContainerProfileStorageImpl) DeleteMergedContainerProfile(ctx context.C
 	return c.storageImpl.DeleteWithConn(ctx, conn, mergedKey, &softwarecomposition.ContainerProfile{}, nil, nil, nil, storage.DeleteOptions{})
 }
 
+func (c *ContainerProfileStorageImpl) SaveCompiledContainerProfile(ctx context.Context, observedKey string, profile *softwarecomposition.ContainerProfile) error {
+	conn := ctx.Value(connKey).(*sqlite.Conn)
+
+	// profile does not carry the resourceVersion its save produced, read it
+	// back from the key the REST Get serves: merged first, then observed.
+	sourceVersion, err := effectiveResourceVersion(conn, observedKey)
+	if err != nil {
+		return fmt.Errorf("read effective container profile version: %w", err)
+	}
+
+	tryUpdate := func(input runtime.Object, res storage.ResponseMeta) (runtime.Object, *uint64, error) {
+		// Skip the compilation, and the write, when the stored artifact is
+		// already compiled from this version of the profile.
+		if existing, ok := input.(*softwarecomposition.CompiledContainerProfile); ok && existing.ResourceVersion != "" &&
+			existing.Spec.SourceResourceVersion == sourceVersion && existing.Spec.FormatVersion == compiledprofile.FormatVersion {
+			return existing, nil, nil
+		}
+		source := *profile
+		source.ResourceVersion = sourceVersion
+		out := CompileContainerProfile(&source)
+		if existing, ok := input.(*softwarecomposition.CompiledContainerProfile); ok {
+			out.ResourceVersion = existing.ResourceVersion
+			out.UID = existing.UID
+			out.CreationTimestamp = existing.CreationTimestamp
+		}
+		return out, nil, nil
+	}
+
+	cpCtx, cpCancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cpCancel()
+
+	if err := c.storageImpl.GuaranteedUpdateWithConn(cpCtx, conn, CompiledKeyFor(observedKey), &softwarecomposition.CompiledContainerProfile{},
+		true, nil, tryUpdate, nil, ""); err != nil {
+		return fmt.Errorf("failed to update compiled container profile: %w", err)
+	}
+	return nil
+}
+
+// effectiveResourceVersion returns the resourceVersion of the merged CP of
+// observedKey, or of the observed CP when there is no merged one.
+func effectiveResourceVersion(conn *sqlite.Conn, observedKey string) (string, error) {
+	metadataJSON, err := ReadMetadata(conn, MergedKeyFor(observedKey))
+	if errors.Is(err, ErrMetadataNotFound) {
+		metadataJSON, err = ReadMetadata(conn, observedKey)
+	}
+	if err != nil {
+		return "", err
+	}
+	// the metadata of internal objects is stored inline
+	var metadata metav1.ObjectMeta
+	if err := json.Unmarshal(metadataJSON, &metadata); err != nil {
+		return "", fmt.Errorf("unmarshal metadata: %w", err)
+	}
+	return metadata.ResourceVersion, nil
+}
+
 func (c *ContainerProfileStorageImpl) UpdateApplicationProfile(ctx context.Context, key, prefix, root string, id armotypes.ProfileIdentifier, slug, wlid string, instanceID interface{ GetStringNoContainer() string }, profile *softwarecomposition.ContainerProfile, creationTimestamp metav1.Time) error {
 	conn := ctx.Value(connKey).(*sqlite.Conn)
 
@@ -76,6 +76,12 @@ type ContainerProfileStorage interface {
 	// corresponds to observedKey. Idempotent: not-found is not an error.
 	DeleteMergedContainerProfile(ctx context.Context, observedKey string) error
 
+	// SaveCompiledContainerProfile compiles profile, the effective container
+	// profile of observedKey, and stores it under a parallel key (kind:
+	// containerprofile-compiled) unless the stored one is already compiled
+	// from the same version.
+	SaveCompiledContainerProfile(ctx context.Context, observedKey string, profile *softwarecomposition.ContainerProfile) error
+
 	// UpdateApplicationProfile updates the application profile associated with a container profile.
 	UpdateApplicationProfile(ctx context.Context, key, prefix, root string, id armotypes.ProfileIdentifier, slug, wlid string, instanceID interface{ GetStringNoContainer() string }, profile *softwarecomposition.ContainerProfile, creationTimestamp metav1.Time) error
 
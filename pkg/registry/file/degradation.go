@@ -0,0 +1,102 @@
+package file
+
+import (
+	"strconv"
+
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"github.com/kubescape/storage/pkg/registry/file/dynamicpathdetector"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// A profile exceeding its size limit is not rejected right away: each of its
+// containers larger than its share of the limit is deflated again with a
+// stronger collapsing, level after level, until it fits its share:
+//  1. the PathAnalyzer, exec arguments, IP and DNS thresholds are halved,
+//  2. they are quartered and the Envs of execs are dropped,
+//  3. they are set to 1, which also enables the IP and DNS collapsing.
+//
+// The highest level applied is recorded in DegradationLevelAnnotation, so a
+// runaway container degrades its own profile instead of losing the whole
+// object. A profile still too large after the last level is rejected with
+// ObjectTooLargeError.
+const (
+	DegradationLevelAnnotation = "kubescape.io/degradation-level"
+	MaxDegradationLevel        = 3
+)
+
+// degradeCollapseSettings returns settings with the collapsing strengthened
+// for level, see MaxDegradationLevel.
+func degradeCollapseSettings(settings dynamicpathdetector.CollapseSettings, level int) dynamicpathdetector.CollapseSettings {
+	if level <= 0 {
+		return settings
+	}
+	degrade := func(threshold int) int {
+		if level >= MaxDegradationLevel {
+			return 1
+		}
+		if threshold <= 0 {
+			// disabled
+			return threshold
+		}
+		return max(threshold>>level, 1)
+	}
+	degraded := settings
+	degraded.OpenDynamicThreshold = degrade(settings.OpenDynamicThreshold)
+	degraded.EndpointDynamicThreshold = degrade(settings.EndpointDynamicThreshold)
+	degraded.IPCollapseThreshold = degrade(settings.IPCollapseThreshold)
+	degraded.DNSCollapseThreshold = degrade(settings.DNSCollapseThreshold)
+	degraded.ExecArgsCollapseThreshold = degrade(settings.ExecArgsCollapseThreshold)
+	degraded.CollapseConfigs = make([]dynamicpathdetector.CollapseConfig, len(settings.CollapseConfigs))
+	for i, c := range settings.CollapseConfigs {
+		degraded.CollapseConfigs[i] = dynamicpathdetector.CollapseConfig{Prefix: c.Prefix, Threshold: degrade(c.Threshold)}
+	}
+	return degraded
+}
+
+// degradeExecs returns execs without their Envs from the second level on.
+func degradeExecs(execs []softwarecomposition.ExecCalls, level int) []softwarecomposition.ExecCalls {
+	if level < 2 || len(execs) == 0 {
+		return execs
+	}
+	out := make([]softwarecomposition.ExecCalls, len(execs))
+	for i, e := range execs {
+		e.Envs = nil
+		out[i] = e
+	}
+	return out
+}
+
+// degradeUntilFits deflates at increasing levels, from the first one, until
+// the size of the result fits budget or the last level is reached. It
+// returns the result and the level applied.
+func degradeUntilFits[T any](deflate func(level int) T, size func(T) int, budget int) (T, int) {
+	for level := 1; ; level++ {
+		out := deflate(level)
+		if size(out) <= budget || level == MaxDegradationLevel {
+			return out, level
+		}
+	}
+}
+
+// containerBudget returns the share of maxSize of each of count containers.
+func containerBudget(maxSize, count int) int {
+	if count <= 1 {
+		return maxSize
+	}
+	return max(maxSize/count, 1)
+}
+
+// recordDegradationLevel sets DegradationLevelAnnotation on profile, or
+// removes it when the profile was not degraded.
+func recordDegradationLevel(profile metav1.Object, level int) {
+	annotations := profile.GetAnnotations()
+	if level == 0 {
+		delete(annotations, DegradationLevelAnnotation)
+		return
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[DegradationLevelAnnotation] = strconv.Itoa(level)
+	profile.SetAnnotations(annotations)
+}
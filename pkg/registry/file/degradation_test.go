@@ -0,0 +1,119 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/kubescape/k8s-interface/instanceidhandler/v1/helpers"
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"github.com/kubescape/storage/pkg/config"
+	"github.com/kubescape/storage/pkg/registry/file/dynamicpathdetector"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestDegradeCollapseSettings(t *testing.T) {
+	settings := dynamicpathdetector.CollapseSettings{
+		OpenDynamicThreshold:      50,
+		EndpointDynamicThreshold:  100,
+		CollapseConfigs:           []dynamicpathdetector.CollapseConfig{{Prefix: "/etc", Threshold: 20}},
+		IPCollapseThreshold:       0,
+		DNSCollapseThreshold:      16,
+		ExecArgsCollapseThreshold: 16,
+		EnvCollapseThreshold:      1,
+	}
+	assert.Equal(t, settings, degradeCollapseSettings(settings, 0))
+	assert.Equal(t, dynamicpathdetector.CollapseSettings{
+		OpenDynamicThreshold:      25,
+		EndpointDynamicThreshold:  50,
+		CollapseConfigs:           []dynamicpathdetector.CollapseConfig{{Prefix: "/etc", Threshold: 10}},
+		IPCollapseThreshold:       0,
+		DNSCollapseThreshold:      8,
+		ExecArgsCollapseThreshold: 8,
+		EnvCollapseThreshold:      1,
+	}, degradeCollapseSettings(settings, 1))
+	assert.Equal(t, 12, degradeCollapseSettings(settings, 2).OpenDynamicThreshold)
+	assert.Equal(t, dynamicpathdetector.CollapseSettings{
+		OpenDynamicThreshold:      1,
+		EndpointDynamicThreshold:  1,
+		CollapseConfigs:           []dynamicpathdetector.CollapseConfig{{Prefix: "/etc", Threshold: 1}},
+		IPCollapseThreshold:       1,
+		DNSCollapseThreshold:      1,
+		ExecArgsCollapseThreshold: 1,
+		EnvCollapseThreshold:      1,
+	}, degradeCollapseSettings(settings, MaxDegradationLevel))
+	// the configured settings are left alone
+	assert.Equal(t, 20, settings.CollapseConfigs[0].Threshold)
+}
+
+func TestDegradeExecs(t *testing.T) {
+	execs := []softwarecomposition.ExecCalls{{Path: "/bin/sh", Args: []string{"-c"}, Envs: []string{"HOME=/root"}, ArgsRequired: true}}
+	assert.Equal(t, execs, degradeExecs(execs, 1))
+	assert.Equal(t, []softwarecomposition.ExecCalls{{Path: "/bin/sh", Args: []string{"-c"}, ArgsRequired: true}}, degradeExecs(execs, 2))
+	assert.Equal(t, []string{"HOME=/root"}, execs[0].Envs)
+}
+
+func TestApplicationProfileProcessor_PreSaveDegrades(t *testing.T) {
+	newProfile := func() *softwarecomposition.ApplicationProfile {
+		var opens []softwarecomposition.OpenCalls
+		for i := range 40 {
+			opens = append(opens, softwarecomposition.OpenCalls{Path: fmt.Sprintf("/data/file%d", i), Flags: []string{"O_RDONLY"}})
+		}
+		return &softwarecomposition.ApplicationProfile{
+			ObjectMeta: v1.ObjectMeta{Annotations: map[string]string{}},
+			Spec: softwarecomposition.ApplicationProfileSpec{
+				Containers: []softwarecomposition.ApplicationProfileContainer{
+					{Name: "runaway", Opens: opens},
+					{Name: "quiet", Syscalls: []string{"read", "open"}},
+				},
+			},
+		}
+	}
+
+	// the profile fits, nothing is degraded
+	profile := newProfile()
+	a := NewApplicationProfileProcessor(config.Config{DefaultNamespace: "kubescape", MaxApplicationProfileSize: 100})
+	require.NoError(t, a.PreSave(context.TODO(), profile))
+	assert.Len(t, profile.Spec.Containers[0].Opens, 40)
+	assert.NotContains(t, profile.Annotations, DegradationLevelAnnotation)
+
+	// only the container larger than its share is degraded
+	profile = newProfile()
+	a = NewApplicationProfileProcessor(config.Config{DefaultNamespace: "kubescape", MaxApplicationProfileSize: 30})
+	require.NoError(t, a.PreSave(context.TODO(), profile))
+	assert.Equal(t, []softwarecomposition.OpenCalls{{Path: "/data/" + dynamicpathdetector.DynamicIdentifier, Flags: []string{"O_RDONLY"}}}, profile.Spec.Containers[0].Opens)
+	assert.Equal(t, []string{"open", "read"}, profile.Spec.Containers[1].Syscalls)
+	assert.Equal(t, "1", profile.Annotations[DegradationLevelAnnotation])
+	assert.Equal(t, "3", profile.Annotations[helpers.ResourceSizeMetadataKey])
+}
+
+func TestNetworkNeighborhoodProcessor_PreSaveDegrades(t *testing.T) {
+	var egress []softwarecomposition.NetworkNeighbor
+	for i := range 10 {
+		egress = append(egress, softwarecomposition.NetworkNeighbor{
+			Identifier:  fmt.Sprintf("neighbor%d", i),
+			Type:        "external",
+			IPAddresses: []string{fmt.Sprintf("10.0.0.%d", i)},
+			Ports:       []softwarecomposition.NetworkPort{{Name: "TCP-443", Protocol: "TCP", Port: ptr.To[int32](443)}},
+		})
+	}
+	profile := &softwarecomposition.NetworkNeighborhood{
+		ObjectMeta: v1.ObjectMeta{Annotations: map[string]string{}},
+		Spec: softwarecomposition.NetworkNeighborhoodSpec{
+			Containers: []softwarecomposition.NetworkNeighborhoodContainer{{Name: "runaway", Egress: egress}},
+		},
+	}
+	a := NewNetworkNeighborhoodProcessor(config.Config{MaxNetworkNeighborhoodSize: 5})
+	require.NoError(t, a.PreSave(context.TODO(), profile))
+	require.Len(t, profile.Spec.Containers[0].Egress, 1)
+	assert.Equal(t, []string{"10.0.0.0/24"}, profile.Spec.Containers[0].Egress[0].IPAddresses)
+	assert.Equal(t, "1", profile.Annotations[DegradationLevelAnnotation])
+
+	// a profile too large at every level is still rejected
+	profile.Spec.Containers[0].Egress = egress
+	a = NewNetworkNeighborhoodProcessor(config.Config{MaxNetworkNeighborhoodSize: 0})
+	assert.ErrorIs(t, a.PreSave(context.TODO(), profile), ObjectTooLargeError)
+}
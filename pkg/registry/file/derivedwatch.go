@@ -0,0 +1,233 @@
+package file
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kubescape/go-logger"
+	"github.com/kubescape/go-logger/helpers"
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/apiserver/pkg/storage"
+)
+
+// derivedWatchCoalesceWindow is how long source events are accumulated before the affected
+// virtual objects are regenerated, so that a burst of writes produces a single event per object.
+const derivedWatchCoalesceWindow = time.Second
+
+// deriver maps the events of a source kind to the virtual objects generated from it.
+type deriver interface {
+	// derivedKey returns the key of the virtual object affected by a source event.
+	derivedKey(ev watch.Event) (string, bool)
+	// resolve returns the current virtual object at key, or a not found error if it no longer exists.
+	resolve(ctx context.Context, key string) (runtime.Object, error)
+	// tombstone returns the object sent along with the Deleted event of key.
+	tombstone(key string) runtime.Object
+	// list returns the current virtual objects under key, by their key, as they are listed.
+	list(ctx context.Context, key string) (map[string]runtime.Object, error)
+	// GetCurrentResourceVersion returns the resourceVersion the virtual objects are sent with.
+	GetCurrentResourceVersion(ctx context.Context) (uint64, error)
+}
+
+// newDerivedWatch creates a watcher emitting the events of the virtual objects under key
+// matching opts, derived from the events of source. Source events are coalesced during
+// window, after which every affected virtual object is regenerated and sent once: Added
+// the first time it matches, Modified afterwards, and Deleted when it no longer exists or
+// no longer matches. The objects are sent with the current resourceVersion of the storage.
+//
+// Like a watch served from the watch cache, it starts with an Added event for every
+// existing object when opts.ResourceVersion is unset or "0", or when opts.SendInitialEvents
+// asks for them, the latter ending them with a bookmark if bookmarks are allowed.
+func newDerivedWatch(ctx context.Context, source watch.Interface, key string, opts storage.ListOptions, d deriver, window time.Duration) *watcher {
+	if opts.Predicate.Label == nil {
+		opts.Predicate.Label = labels.Everything()
+	}
+	if opts.Predicate.Field == nil {
+		opts.Predicate.Field = fields.Everything()
+	}
+	w := newWatcher(ctx, true)
+	go w.derive(source, key, opts, d, window)
+	return w
+}
+
+// derivedWatchSourceOptions returns the options of the source watch of a derived watch,
+// which only carry over the resourceVersion the derived watch starts at.
+func derivedWatchSourceOptions(opts storage.ListOptions) storage.ListOptions {
+	if opts.ResourceVersion == softwarecomposition.ResourceVersionFullSpec {
+		// the virtual objects are always sent in full
+		return storage.ListOptions{}
+	}
+	return storage.ListOptions{ResourceVersion: opts.ResourceVersion}
+}
+
+// sendsInitialEvents returns true if a watch starting with opts begins with an Added event
+// for every existing object.
+func sendsInitialEvents(opts storage.ListOptions) bool {
+	if opts.SendInitialEvents != nil {
+		return *opts.SendInitialEvents
+	}
+	switch opts.ResourceVersion {
+	case "", "0", softwarecomposition.ResourceVersionFullSpec:
+		return true
+	}
+	return false
+}
+
+// derive is the only goroutine feeding a derived watcher, it returns when the watcher is
+// stopped or the source watch is closed.
+func (w *watcher) derive(source watch.Interface, key string, opts storage.ListOptions, d deriver, window time.Duration) {
+	defer source.Stop()
+	// sent holds the keys of the objects the client knows about, so that the first event
+	// of an object is Added and only those are Deleted
+	sent, err := w.initialEvents(key, opts, d)
+	if err != nil {
+		logger.L().Warning("derive - failed to list objects", helpers.Error(err), helpers.String("key", key))
+		// closing the watcher makes the client re-establish the watch
+		w.Stop()
+		return
+	}
+	pending := map[string]struct{}{}
+	var flush <-chan time.Time
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case ev, ok := <-source.ResultChan():
+			if !ok {
+				// closing the watcher makes the client re-establish the watch
+				w.Stop()
+				return
+			}
+			derivedKey, ok := d.derivedKey(ev)
+			if !ok || !isKeyUnder(derivedKey, key) {
+				continue
+			}
+			pending[derivedKey] = struct{}{}
+			if flush == nil {
+				flush = time.After(window)
+			}
+		case <-flush:
+			flush = nil
+			keys := slices.Sorted(maps.Keys(pending))
+			clear(pending)
+			resourceVersion, err := d.GetCurrentResourceVersion(w.ctx)
+			if err != nil {
+				logger.L().Warning("derive - failed to get resourceVersion", helpers.Error(err), helpers.String("key", key))
+			}
+			for _, k := range keys {
+				obj, err := d.resolve(w.ctx, k)
+				switch {
+				case storage.IsNotFound(err):
+					obj = nil
+				case err != nil:
+					logger.L().Warning("derive - failed to regenerate object", helpers.Error(err), helpers.String("key", k))
+					continue
+				case !matches(opts.Predicate, obj):
+					// an object no longer matching is deleted from the point of view of the client
+					obj = nil
+				}
+				_, known := sent[k]
+				switch {
+				case obj == nil && known:
+					delete(sent, k)
+					w.send(watch.Event{Type: watch.Deleted, Object: withResourceVersion(d.tombstone(k), resourceVersion)})
+				case obj != nil && known:
+					w.send(watch.Event{Type: watch.Modified, Object: withResourceVersion(obj, resourceVersion)})
+				case obj != nil:
+					sent[k] = struct{}{}
+					w.send(watch.Event{Type: watch.Added, Object: withResourceVersion(obj, resourceVersion)})
+				}
+			}
+		}
+	}
+}
+
+// initialEvents lists the objects under key matching opts, sending their Added events if
+// the watch starts with them, and returns their keys.
+func (w *watcher) initialEvents(key string, opts storage.ListOptions, d deriver) (map[string]struct{}, error) {
+	objs, err := d.list(w.ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	resourceVersion, err := d.GetCurrentResourceVersion(w.ctx)
+	if err != nil {
+		return nil, err
+	}
+	initial := sendsInitialEvents(opts)
+	sent := map[string]struct{}{}
+	for _, k := range slices.Sorted(maps.Keys(objs)) {
+		if !isKeyUnder(k, key) || !matches(opts.Predicate, objs[k]) {
+			continue
+		}
+		sent[k] = struct{}{}
+		if !initial {
+			continue
+		}
+		// the listed objects may be abridged, the events carry the full ones
+		obj, err := d.resolve(w.ctx, k)
+		switch {
+		case storage.IsNotFound(err):
+			delete(sent, k)
+			continue
+		case err != nil:
+			logger.L().Warning("derive - failed to generate object", helpers.Error(err), helpers.String("key", k))
+			continue
+		}
+		w.send(watch.Event{Type: watch.Added, Object: withResourceVersion(obj, resourceVersion)})
+	}
+	if initial && opts.SendInitialEvents != nil && opts.Predicate.AllowWatchBookmarks {
+		bookmark := d.tombstone(key)
+		if accessor, err := meta.Accessor(bookmark); err == nil {
+			accessor.SetName("")
+			accessor.SetNamespace("")
+			accessor.SetAnnotations(map[string]string{metav1.InitialEventsAnnotationKey: "true"})
+		}
+		w.send(watch.Event{Type: watch.Bookmark, Object: withResourceVersion(bookmark, resourceVersion)})
+	}
+	return sent, nil
+}
+
+// matches returns true if obj is selected by p, objects failing to be matched are not.
+func matches(p storage.SelectionPredicate, obj runtime.Object) bool {
+	ok, err := p.Matches(obj)
+	if err != nil {
+		logger.L().Debug("derive - failed to match object", helpers.Error(err))
+	}
+	return ok
+}
+
+// withResourceVersion sets the resourceVersion of obj, unless it is zero, and returns it.
+func withResourceVersion(obj runtime.Object, resourceVersion uint64) runtime.Object {
+	if resourceVersion == 0 {
+		return obj
+	}
+	if accessor, err := meta.Accessor(obj); err == nil {
+		accessor.SetResourceVersion(strconv.FormatUint(resourceVersion, 10))
+	}
+	return obj
+}
+
+// isKeyUnder returns true if key is watchKey or one of its descendants.
+func isKeyUnder(key, watchKey string) bool {
+	watchKey = strings.TrimSuffix(watchKey, "/")
+	return key == watchKey || strings.HasPrefix(key, watchKey+"/")
+}
+
+// namespaceSummaryKey returns the key of the namespace summary of the virtual kind affected
+// by a source event, summaries being named after the namespace they cover.
+func namespaceSummaryKey(ev watch.Event, kind string) (string, bool) {
+	obj, err := meta.Accessor(ev.Object)
+	if err != nil || obj.GetNamespace() == "" {
+		return "", false
+	}
+	return "/" + softwarecomposition.GroupName + "/" + kind + "/" + obj.GetNamespace(), true
+}
@@ -0,0 +1,279 @@
+package file
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/apiserver/pkg/storage"
+)
+
+// testDeriver derives one object per namespace, resolving to a NetworkNeighborhood which is
+// missing when its namespace is listed in deleted, and labelled with the labels of its namespace.
+type testDeriver struct {
+	resolved chan string
+	deleted  map[string]bool
+	labels   map[string]map[string]string
+	listed   []string
+}
+
+func (d *testDeriver) derivedKey(ev watch.Event) (string, bool) {
+	return namespaceSummaryKey(ev, "tests")
+}
+
+func (d *testDeriver) resolve(_ context.Context, key string) (runtime.Object, error) {
+	d.resolved <- key
+	if d.deleted[key] {
+		return nil, storage.NewKeyNotFoundError(key, 0)
+	}
+	return &softwarecomposition.NetworkNeighborhood{ObjectMeta: v1.ObjectMeta{Name: getNamespaceFromKey(key), Labels: d.labels[key]}}, nil
+}
+
+func (d *testDeriver) tombstone(key string) runtime.Object {
+	return &softwarecomposition.NetworkNeighborhood{ObjectMeta: v1.ObjectMeta{Name: getNamespaceFromKey(key)}}
+}
+
+func (d *testDeriver) list(_ context.Context, _ string) (map[string]runtime.Object, error) {
+	objs := map[string]runtime.Object{}
+	for _, key := range d.listed {
+		objs[key] = &softwarecomposition.NetworkNeighborhood{ObjectMeta: v1.ObjectMeta{Name: getNamespaceFromKey(key), Labels: d.labels[key]}}
+	}
+	return objs, nil
+}
+
+func (d *testDeriver) GetCurrentResourceVersion(_ context.Context) (uint64, error) {
+	return 42, nil
+}
+
+func nnInNamespace(namespace string) *softwarecomposition.NetworkNeighborhood {
+	return &softwarecomposition.NetworkNeighborhood{ObjectMeta: v1.ObjectMeta{Name: "nn", Namespace: namespace}}
+}
+
+func TestDerivedWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	source := watch.NewFake()
+	d := &testDeriver{
+		resolved: make(chan string, 10),
+		deleted:  map[string]bool{"/spdx.softwarecomposition.kubescape.io/tests/gone": true},
+		listed:   []string{"/spdx.softwarecomposition.kubescape.io/tests/gone"},
+	}
+	// watching from a resourceVersion, the listed objects are known to the client
+	w := newDerivedWatch(ctx, source, "/spdx.softwarecomposition.kubescape.io/tests", storage.ListOptions{ResourceVersion: "10"}, d, 50*time.Millisecond)
+
+	// a burst of events is coalesced into a single event per derived object
+	source.Add(nnInNamespace("default"))
+	source.Modify(nnInNamespace("default"))
+	source.Modify(nnInNamespace("default"))
+	source.Delete(nnInNamespace("gone"))
+	// objects without a derived key are ignored
+	source.Add(&softwarecomposition.NetworkNeighborhood{})
+
+	var events []watch.Event
+	for range 2 {
+		select {
+		case ev := <-w.ResultChan():
+			events = append(events, ev)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for derived events")
+		}
+	}
+	require.Len(t, events, 2)
+	assert.Equal(t, watch.Added, events[0].Type)
+	obj, err := meta.Accessor(events[0].Object)
+	require.NoError(t, err)
+	assert.Equal(t, "default", obj.GetName())
+	assert.Equal(t, "42", obj.GetResourceVersion())
+	assert.Equal(t, watch.Deleted, events[1].Type)
+	obj, err = meta.Accessor(events[1].Object)
+	require.NoError(t, err)
+	assert.Equal(t, "gone", obj.GetName())
+	assert.Len(t, d.resolved, 2)
+
+	// the next event of an object already sent is a Modified one
+	<-d.resolved
+	<-d.resolved
+	source.Modify(nnInNamespace("default"))
+	select {
+	case ev := <-w.ResultChan():
+		assert.Equal(t, watch.Modified, ev.Type)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for derived events")
+	}
+
+	// closing the source closes the derived watch
+	source.Stop()
+	select {
+	case _, ok := <-w.ResultChan():
+		assert.False(t, ok)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the derived watch to close")
+	}
+}
+
+func TestDerivedWatch_Filter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	source := watch.NewFake()
+	d := &testDeriver{resolved: make(chan string, 10)}
+	w := newDerivedWatch(ctx, source, "/spdx.softwarecomposition.kubescape.io/tests/kubescape", storage.ListOptions{ResourceVersion: "10"}, d, 10*time.Millisecond)
+
+	source.Modify(nnInNamespace("default"))
+	source.Modify(nnInNamespace("kubescape"))
+
+	select {
+	case ev := <-w.ResultChan():
+		obj, err := meta.Accessor(ev.Object)
+		require.NoError(t, err)
+		assert.Equal(t, "kubescape", obj.GetName())
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for derived events")
+	}
+	assert.Equal(t, "/spdx.softwarecomposition.kubescape.io/tests/kubescape", <-d.resolved)
+	assert.Empty(t, d.resolved)
+
+	// stopping the derived watch stops the source
+	w.Stop()
+	select {
+	case _, ok := <-source.ResultChan():
+		assert.False(t, ok)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the source watch to stop")
+	}
+}
+
+func TestDerivedWatch_InitialEvents(t *testing.T) {
+	sendInitialEvents := true
+	tests := []struct {
+		name string
+		opts storage.ListOptions
+		want []watch.EventType
+	}{
+		{
+			name: "from a resourceVersion",
+			opts: storage.ListOptions{ResourceVersion: "10"},
+		},
+		{
+			name: "from any resourceVersion",
+			opts: storage.ListOptions{ResourceVersion: "0"},
+			want: []watch.EventType{watch.Added, watch.Added},
+		},
+		{
+			name: "send initial events",
+			opts: storage.ListOptions{
+				SendInitialEvents: &sendInitialEvents,
+				Predicate:         storage.SelectionPredicate{AllowWatchBookmarks: true},
+			},
+			want: []watch.EventType{watch.Added, watch.Added, watch.Bookmark},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			source := watch.NewFake()
+			d := &testDeriver{
+				resolved: make(chan string, 10),
+				listed: []string{
+					"/spdx.softwarecomposition.kubescape.io/tests/default",
+					"/spdx.softwarecomposition.kubescape.io/tests/kubescape",
+				},
+			}
+			w := newDerivedWatch(ctx, source, "/spdx.softwarecomposition.kubescape.io/tests", tt.opts, d, 10*time.Millisecond)
+			// an event following the initial ones
+			source.Modify(nnInNamespace("default"))
+			var got []watch.EventType
+			for {
+				ev := <-w.ResultChan()
+				if ev.Type == watch.Modified {
+					break
+				}
+				got = append(got, ev.Type)
+				obj, err := meta.Accessor(ev.Object)
+				require.NoError(t, err)
+				assert.Equal(t, "42", obj.GetResourceVersion())
+				if ev.Type == watch.Bookmark {
+					assert.Equal(t, "true", obj.GetAnnotations()[v1.InitialEventsAnnotationKey])
+				}
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDerivedWatch_Selector(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	source := watch.NewFake()
+	d := &testDeriver{
+		resolved: make(chan string, 10),
+		labels: map[string]map[string]string{
+			"/spdx.softwarecomposition.kubescape.io/tests/default": {"team": "a"},
+		},
+		listed: []string{
+			"/spdx.softwarecomposition.kubescape.io/tests/default",
+			"/spdx.softwarecomposition.kubescape.io/tests/kubescape",
+		},
+	}
+	opts := storage.ListOptions{
+		ResourceVersion: "0",
+		Predicate: storage.SelectionPredicate{
+			Label:    labels.SelectorFromSet(labels.Set{"team": "a"}),
+			GetAttrs: storage.DefaultClusterScopedAttr,
+		},
+	}
+	w := newDerivedWatch(ctx, source, "/spdx.softwarecomposition.kubescape.io/tests", opts, d, 10*time.Millisecond)
+	next := func() watch.Event {
+		select {
+		case ev := <-w.ResultChan():
+			return ev
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for derived events")
+		}
+		return watch.Event{}
+	}
+
+	// only the matching object is sent
+	ev := next()
+	assert.Equal(t, watch.Added, ev.Type)
+	obj, err := meta.Accessor(ev.Object)
+	require.NoError(t, err)
+	assert.Equal(t, "default", obj.GetName())
+
+	// an object starting to match is added
+	d.labels["/spdx.softwarecomposition.kubescape.io/tests/kubescape"] = map[string]string{"team": "a"}
+	source.Modify(nnInNamespace("kubescape"))
+	ev = next()
+	assert.Equal(t, watch.Added, ev.Type)
+	obj, err = meta.Accessor(ev.Object)
+	require.NoError(t, err)
+	assert.Equal(t, "kubescape", obj.GetName())
+
+	// and an object no longer matching is deleted
+	d.labels["/spdx.softwarecomposition.kubescape.io/tests/default"] = nil
+	source.Modify(nnInNamespace("default"))
+	ev = next()
+	assert.Equal(t, watch.Deleted, ev.Type)
+	obj, err = meta.Accessor(ev.Object)
+	require.NoError(t, err)
+	assert.Equal(t, "default", obj.GetName())
+}
+
+func TestGeneratedNetworkPolicyStorage_derivedKey(t *testing.T) {
+	s := &GeneratedNetworkPolicyStorage{}
+	key, ok := s.derivedKey(watch.Event{Type: watch.Modified, Object: nnInNamespace("default")})
+	assert.True(t, ok)
+	assert.Equal(t, "/spdx.softwarecomposition.kubescape.io/generatednetworkpolicies/default/nn", key)
+	tombstone, err := meta.Accessor(s.tombstone(key))
+	require.NoError(t, err)
+	assert.Equal(t, "default", tombstone.GetNamespace())
+	assert.Equal(t, "nn", tombstone.GetName())
+}
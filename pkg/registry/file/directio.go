@@ -37,8 +37,8 @@ func (d *DirectIOReader) Read(p []byte) (int, error) {
 		}
 		d.off = 0
 	}
-	// copy data to the buffer
-	n := copy(p, d.buf[d.off:])
+	// copy data to the buffer, the last block being short
+	n := copy(p, d.buf[d.off:d.bufSize])
 	d.off += n
 	return n, nil
 }
@@ -74,7 +74,12 @@ func (d *DirectIOWriter) Close() error {
 	if err != nil {
 		return err
 	}
-	return d.wr.Truncate(d.fileSize + int64(d.off))
+	return d.wr.Truncate(d.fileSize)
+}
+
+// Size returns the number of bytes written so far.
+func (d *DirectIOWriter) Size() int64 {
+	return d.fileSize
 }
 
 func (d *DirectIOWriter) Write(p []byte) (int, error) {
@@ -0,0 +1,43 @@
+package file
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/ncw/directio"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirectIO(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	for _, size := range []int{0, 10, directio.BlockSize, directio.BlockSize + 10, 3*directio.BlockSize - 1} {
+		data := bytes.Repeat([]byte("0123456789"), size/10+1)[:size]
+		// a previous, longer content must not leak through
+		require.NoError(t, afero.WriteFile(fs, "payload", bytes.Repeat([]byte{'x'}, 4*directio.BlockSize), 0644))
+		f, err := fs.OpenFile("payload", os.O_WRONLY|os.O_TRUNC, 0644)
+		require.NoError(t, err)
+		w := NewDirectIOWriter(f)
+		// written in pieces not aligned on blocks
+		for off := 0; off < size; off += 7 {
+			_, err := w.Write(data[off:min(off+7, size)])
+			require.NoError(t, err)
+		}
+		require.NoError(t, w.Close())
+		require.NoError(t, f.Close())
+		assert.Equal(t, int64(size), w.Size())
+
+		info, err := fs.Stat("payload")
+		require.NoError(t, err)
+		assert.Equal(t, int64(size), info.Size(), "size %d", size)
+		f, err = fs.Open("payload")
+		require.NoError(t, err)
+		got, err := io.ReadAll(NewDirectIOReader(f))
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+		assert.Equal(t, data, got, "size %d", size)
+	}
+}
@@ -7,8 +7,11 @@ import (
 	wlidPkg "github.com/armosec/utils-k8s-go/wlid"
 	"github.com/kubescape/k8s-interface/instanceidhandler/v1"
 	"github.com/kubescape/k8s-interface/k8sinterface"
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition/networkpolicy/v2"
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition/v1beta1"
 	"github.com/kubescape/storage/pkg/config"
 	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -51,6 +54,101 @@ func NewKubernetesAPI(cfg config.Config, client *kubernetes.Clientset) *Kubernet
 
 var _ ResourcesFetcher = (*KubernetesAPI)(nil)
 
+var _ networkpolicy.ClusterLister = (*KubernetesAPI)(nil)
+
+// NamespaceLabels returns the labels of a namespace, it is a NamespaceLabelsFunc.
+func (h *KubernetesAPI) NamespaceLabels(ctx context.Context, namespace string) (map[string]string, error) {
+	if h.client == nil {
+		return nil, fmt.Errorf("no kubernetes client")
+	}
+	ns, err := h.client.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return ns.Labels, nil
+}
+
+// ListServices lists the Services of every namespace, page by page.
+func (h *KubernetesAPI) ListServices(ctx context.Context) ([]corev1.Service, error) {
+	if h.client == nil {
+		return nil, fmt.Errorf("no kubernetes client")
+	}
+	var services []corev1.Service
+	err := pager.New(func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		return h.client.CoreV1().Services(metav1.NamespaceAll).List(ctx, opts)
+	}).EachListItem(ctx, metav1.ListOptions{}, func(obj runtime.Object) error {
+		services = append(services, *obj.(*corev1.Service))
+		return nil
+	})
+	return services, err
+}
+
+// ListEndpointSlices lists the EndpointSlices of every namespace, page by page.
+func (h *KubernetesAPI) ListEndpointSlices(ctx context.Context) ([]discoveryv1.EndpointSlice, error) {
+	if h.client == nil {
+		return nil, fmt.Errorf("no kubernetes client")
+	}
+	var endpointSlices []discoveryv1.EndpointSlice
+	err := pager.New(func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		return h.client.DiscoveryV1().EndpointSlices(metav1.NamespaceAll).List(ctx, opts)
+	}).EachListItem(ctx, metav1.ListOptions{}, func(obj runtime.Object) error {
+		endpointSlices = append(endpointSlices, *obj.(*discoveryv1.EndpointSlice))
+		return nil
+	})
+	return endpointSlices, err
+}
+
+// ListNodes lists the Nodes of the cluster, page by page.
+func (h *KubernetesAPI) ListNodes(ctx context.Context) ([]corev1.Node, error) {
+	if h.client == nil {
+		return nil, fmt.Errorf("no kubernetes client")
+	}
+	var nodes []corev1.Node
+	err := pager.New(func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		return h.client.CoreV1().Nodes().List(ctx, opts)
+	}).EachListItem(ctx, metav1.ListOptions{}, func(obj runtime.Object) error {
+		nodes = append(nodes, *obj.(*corev1.Node))
+		return nil
+	})
+	return nodes, err
+}
+
+// QuarantineEvent records a Warning event on the object at key, of kind,
+// quarantined for reason, in its namespace or the default one.
+func (h *KubernetesAPI) QuarantineEvent(ctx context.Context, key, kind, reason string) error {
+	if h.client == nil {
+		return fmt.Errorf("no kubernetes client")
+	}
+	_, _, _, _, namespace, name := K8sPathToKeys(key)
+	if name == "" {
+		// cluster-scoped
+		namespace, name = "", namespace
+	}
+	eventNamespace := namespace
+	if eventNamespace == "" {
+		eventNamespace = h.cfg.DefaultNamespace
+	}
+	now := metav1.Now()
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{GenerateName: name + ".", Namespace: eventNamespace},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion: v1beta1.SchemeGroupVersion.String(),
+			Kind:       kind,
+			Namespace:  namespace,
+			Name:       name,
+		},
+		Reason:         "Quarantined",
+		Message:        fmt.Sprintf("payload failed its %s check and was quarantined, the object must be sent again", reason),
+		Type:           corev1.EventTypeWarning,
+		Source:         corev1.EventSource{Component: "storage"},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+	_, err := h.client.CoreV1().Events(eventNamespace).Create(ctx, event, metav1.CreateOptions{})
+	return err
+}
+
 // ResourceMaps is a map of running resources in the cluster, based on these maps we can decide which files to delete
 type ResourceMaps struct {
 	// CLUSTER level
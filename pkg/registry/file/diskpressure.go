@@ -0,0 +1,196 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/kubescape/go-logger"
+	"github.com/kubescape/go-logger/helpers"
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"github.com/kubescape/storage/pkg/config"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// emergencyCleanupInterval is the least time between two emergency cleanups,
+// they scan every object and query the cluster.
+var emergencyCleanupInterval = 5 * time.Minute
+
+// DiskPressureLevel is how close to full the volumes of the storage are.
+type DiskPressureLevel int32
+
+const (
+	DiskPressureNone DiskPressureLevel = iota
+	// DiskPressureCleanup triggers an emergency cleanup.
+	DiskPressureCleanup
+	// DiskPressureLargeKinds rejects the writes of the large kinds.
+	DiskPressureLargeKinds
+	// DiskPressureReadOnly rejects every write and fails readiness.
+	DiskPressureReadOnly
+)
+
+func (l DiskPressureLevel) String() string {
+	switch l {
+	case DiskPressureCleanup:
+		return "cleanup"
+	case DiskPressureLargeKinds:
+		return "large-kinds"
+	case DiskPressureReadOnly:
+		return "read-only"
+	default:
+		return "none"
+	}
+}
+
+// DiskMonitor watches the used space of the volumes holding the payloads and
+// the metadata database against the watermarks of config.Config. The level
+// follows the fullest volume and is checked every DiskCheckInterval, writes
+// are accepted again as soon as the space is freed.
+type DiskMonitor struct {
+	paths   []string
+	cfg     atomic.Pointer[config.Config]
+	level   atomic.Int32
+	used    atomic.Uint64 // percent, of the fullest volume
+	cleanup func(ctx context.Context) error
+	// cleaning is set while an emergency cleanup runs.
+	cleaning    atomic.Bool
+	lastCleanup time.Time
+	// usage returns the percentage of the volume of path in use.
+	usage func(path string) (float64, error)
+}
+
+// NewDiskMonitor returns a DiskMonitor of the volumes of paths. cleanup, if
+// not nil, is the emergency cleanup run under pressure.
+func NewDiskMonitor(cfg *config.Config, cleanup func(ctx context.Context) error, paths ...string) *DiskMonitor {
+	m := &DiskMonitor{
+		paths:   slices.Compact(slices.Sorted(slices.Values(paths))),
+		cleanup: cleanup,
+		usage:   diskUsage,
+	}
+	m.Reload(cfg)
+	return m
+}
+
+// Reload applies the watermarks and interval of cfg from the next check.
+func (m *DiskMonitor) Reload(cfg *config.Config) {
+	m.cfg.Store(cfg)
+}
+
+// Level returns the level of the last check.
+func (m *DiskMonitor) Level() DiskPressureLevel {
+	if m == nil {
+		return DiskPressureNone
+	}
+	return DiskPressureLevel(m.level.Load())
+}
+
+// Run checks the volumes until ctx is done.
+func (m *DiskMonitor) Run(ctx context.Context) {
+	for {
+		m.check(ctx)
+		interval := m.cfg.Load().DiskCheckInterval
+		if interval <= 0 {
+			interval = 30 * time.Second
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// check updates the level from the usage of the volumes and starts an
+// emergency cleanup if needed.
+func (m *DiskMonitor) check(ctx context.Context) {
+	var used float64
+	for _, path := range m.paths {
+		u, err := m.usage(path)
+		if err != nil {
+			logger.L().Debug("disk usage unavailable", helpers.String("path", path), helpers.Error(err))
+			continue
+		}
+		used = max(used, u)
+	}
+	cfg := m.cfg.Load()
+	level := DiskPressureNone
+	switch {
+	case reached(used, cfg.DiskReadOnlyWatermark):
+		level = DiskPressureReadOnly
+	case reached(used, cfg.DiskLargeKindsWatermark):
+		level = DiskPressureLargeKinds
+	case reached(used, cfg.DiskCleanupWatermark):
+		level = DiskPressureCleanup
+	}
+	m.used.Store(uint64(used))
+	if prev := DiskPressureLevel(m.level.Swap(int32(level))); prev != level {
+		if level > prev {
+			logger.L().Warning("disk pressure increased", helpers.String("level", level.String()), helpers.Int("usedPercent", int(used)))
+		} else {
+			logger.L().Info("disk pressure decreased", helpers.String("level", level.String()), helpers.Int("usedPercent", int(used)))
+		}
+	}
+	if level < DiskPressureCleanup || m.cleanup == nil || time.Since(m.lastCleanup) < emergencyCleanupInterval {
+		return
+	}
+	if !m.cleaning.CompareAndSwap(false, true) {
+		return
+	}
+	m.lastCleanup = time.Now()
+	go func() {
+		defer m.cleaning.Store(false)
+		logger.L().Warning("starting emergency cleanup", helpers.Int("usedPercent", int(used)))
+		if err := m.cleanup(ctx); err != nil {
+			logger.L().Error("emergency cleanup failed", helpers.Error(err))
+			return
+		}
+		logger.L().Info("emergency cleanup done")
+	}()
+}
+
+// reached tells if used is at or above watermark, 0 disabling it.
+func reached(used float64, watermark int) bool {
+	return watermark > 0 && used >= float64(watermark)
+}
+
+// ReadinessCheck fails in read-only mode.
+func (m *DiskMonitor) ReadinessCheck() error {
+	if m.Level() == DiskPressureReadOnly {
+		return fmt.Errorf("disk pressure: %d%% used, storage is read-only", m.used.Load())
+	}
+	return nil
+}
+
+// checkWrite returns an InsufficientStorage error if writing the object at key
+// is not allowed at the current level.
+func (m *DiskMonitor) checkWrite(key string) error {
+	level := m.Level()
+	if level < DiskPressureLargeKinds {
+		return nil
+	}
+	_, _, kind, _, _, name := K8sPathToKeys(key)
+	if level == DiskPressureLargeKinds && !slices.Contains(m.cfg.Load().DiskLargeKinds, kind) {
+		return nil
+	}
+	return newInsufficientStorageError(kind, name, fmt.Sprintf("disk pressure: %d%% used, %s writes are rejected", m.used.Load(), level))
+}
+
+// newInsufficientStorageError returns a 507 error, for which there is no
+// helper in apierrors.
+func newInsufficientStorageError(kind, name, message string) *apierrors.StatusError {
+	return &apierrors.StatusError{ErrStatus: metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    http.StatusInsufficientStorage,
+		Reason:  "InsufficientStorage",
+		Message: message,
+		Details: &metav1.StatusDetails{
+			Group: softwarecomposition.GroupName,
+			Kind:  kind,
+			Name:  name,
+		},
+	}}
+}
@@ -0,0 +1,120 @@
+package file
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition/v1beta1"
+	"github.com/kubescape/storage/pkg/config"
+	"github.com/kubescape/storage/pkg/generated/clientset/versioned/scheme"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestDiskMonitor(used map[string]float64, cleanup func(ctx context.Context) error) *DiskMonitor {
+	m := NewDiskMonitor(&config.Config{
+		DiskCleanupWatermark:    85,
+		DiskLargeKindsWatermark: 90,
+		DiskReadOnlyWatermark:   95,
+		DiskLargeKinds:          []string{"sbomsyfts"},
+	}, cleanup, "/data", "/metadata")
+	m.usage = func(path string) (float64, error) {
+		return used[path], nil
+	}
+	return m
+}
+
+func TestDiskMonitor_Level(t *testing.T) {
+	tests := []struct {
+		name string
+		used map[string]float64
+		want DiskPressureLevel
+	}{
+		{"empty", nil, DiskPressureNone},
+		{"below", map[string]float64{"/data": 84.9}, DiskPressureNone},
+		{"cleanup", map[string]float64{"/data": 85}, DiskPressureCleanup},
+		{"large kinds", map[string]float64{"/data": 60, "/metadata": 92}, DiskPressureLargeKinds},
+		{"read-only", map[string]float64{"/data": 99}, DiskPressureReadOnly},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestDiskMonitor(tt.used, nil)
+			m.check(context.Background())
+			assert.Equal(t, tt.want, m.Level())
+		})
+	}
+}
+
+func TestDiskMonitor_DisabledWatermark(t *testing.T) {
+	m := newTestDiskMonitor(map[string]float64{"/data": 99}, nil)
+	m.Reload(&config.Config{DiskCleanupWatermark: 85})
+	m.check(context.Background())
+	assert.Equal(t, DiskPressureCleanup, m.Level())
+	assert.NoError(t, m.ReadinessCheck())
+}
+
+func TestDiskMonitor_EmergencyCleanup(t *testing.T) {
+	var runs atomic.Int32
+	done := make(chan struct{}, 2)
+	m := newTestDiskMonitor(map[string]float64{"/data": 86}, func(context.Context) error {
+		runs.Add(1)
+		done <- struct{}{}
+		return nil
+	})
+	m.check(context.Background())
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("emergency cleanup not started")
+	}
+	// not started again before emergencyCleanupInterval
+	m.check(context.Background())
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, int32(1), runs.Load())
+}
+
+func TestStorageImpl_DiskPressure(t *testing.T) {
+	pool := NewTestPool(t.TempDir())
+	defer func() { _ = pool.Close() }()
+	sch := scheme.Scheme
+	require.NoError(t, softwarecomposition.AddToScheme(sch))
+	s := NewStorageImpl(afero.NewMemMapFs(), DefaultStorageRoot, pool, nil, sch)
+	used := map[string]float64{}
+	m := newTestDiskMonitor(used, nil)
+	s.SetDiskMonitor(m)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	create := func(kind, name string) error {
+		obj := &v1beta1.SBOMSyft{ObjectMeta: v1.ObjectMeta{Name: name, Namespace: "default"}}
+		return s.Create(ctx, "/spdx.softwarecomposition.kubescape.io/"+kind+"/default/"+name, obj, &v1beta1.SBOMSyft{}, 0)
+	}
+	isInsufficientStorage := func(err error) bool {
+		return apierrors.ReasonForError(err) == "InsufficientStorage" && err.(apierrors.APIStatus).Status().Code == http.StatusInsufficientStorage
+	}
+
+	used["/data"] = 91
+	m.check(ctx)
+	err := create("sbomsyfts", "one")
+	assert.True(t, isInsufficientStorage(err), "got %v", err)
+	require.NoError(t, create("applicationprofiles", "one"))
+	require.NoError(t, s.ReadinessCheck())
+
+	used["/data"] = 96
+	m.check(ctx)
+	err = create("applicationprofiles", "two")
+	assert.True(t, isInsufficientStorage(err), "got %v", err)
+	assert.Error(t, s.ReadinessCheck())
+
+	// recovers once space is freed
+	used["/data"] = 50
+	m.check(ctx)
+	require.NoError(t, create("sbomsyfts", "one"))
+	require.NoError(t, s.ReadinessCheck())
+}
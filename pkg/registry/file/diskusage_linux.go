@@ -0,0 +1,20 @@
+//go:build linux
+// +build linux
+
+package file
+
+import "syscall"
+
+// diskUsage returns the percentage of the volume of path in use, counting the
+// blocks reserved to root as unavailable, as df does.
+func diskUsage(path string) (float64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, err
+	}
+	used := st.Blocks - st.Bfree
+	if used+st.Bavail == 0 {
+		return 0, nil
+	}
+	return float64(used) * 100 / float64(used+st.Bavail), nil
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+// +build !linux
+
+package file
+
+import "errors"
+
+// diskUsage is not implemented on non-Linux platforms, the DiskMonitor then
+// never reports pressure.
+func diskUsage(_ string) (float64, error) {
+	return 0, errors.New("disk usage is only available on linux")
+}
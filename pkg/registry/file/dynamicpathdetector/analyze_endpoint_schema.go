@@ -0,0 +1,165 @@
+package dynamicpathdetector
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	types "github.com/kubescape/storage/pkg/apis/softwarecomposition"
+)
+
+// defaultHeaderAllowlist carries the names of the headers kept in the
+// profile of an HTTP endpoint, the others are dropped. The
+// CollapseConfiguration HeaderAllowlist replaces it.
+var defaultHeaderAllowlist = []string{
+	"Accept",
+	"Authorization",
+	"Content-Type",
+	"Host",
+	"Origin",
+	"User-Agent",
+	"X-Api-Key",
+	"X-Requested-With",
+}
+
+// DefaultHeaderAllowlist returns a defensive copy of the canonical names of
+// the headers kept in the profile of an HTTP endpoint.
+func DefaultHeaderAllowlist() []string {
+	return append([]string(nil), defaultHeaderAllowlist...)
+}
+
+// credentialHeaders carries the canonical names of the headers whose values
+// are credentials: when allowlisted, only their names are kept, their values
+// being stored as a single DynamicIdentifier.
+var credentialHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Proxy-Authorization",
+	"Set-Cookie",
+	"X-Api-Key",
+	"X-Auth-Token",
+}
+
+// QueryParamNames returns the sorted names of the query parameters of
+// endpoint, their values are dropped.
+func QueryParamNames(endpoint string) []string {
+	_, query, found := strings.Cut(endpoint, "?")
+	if !found {
+		return nil
+	}
+	query, _, _ = strings.Cut(query, "#")
+	var names []string
+	for _, pair := range strings.Split(query, "&") {
+		name, _, _ := strings.Cut(pair, "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// StatusClass returns the class of a response status, given as a code like
+// "404" or already as a class like "4xx", or "" when status is neither.
+func StatusClass(status string) string {
+	status = strings.ToLower(strings.TrimSpace(status))
+	if len(status) != 3 || status[0] < '1' || status[0] > '5' {
+		return ""
+	}
+	if status[1:] == "xx" {
+		return status
+	}
+	if status[1] < '0' || status[1] > '9' || status[2] < '0' || status[2] > '9' {
+		return ""
+	}
+	return status[:1] + "xx"
+}
+
+// ContentType returns the lowercased media type of a Content-Type value,
+// without its parameters.
+func ContentType(value string) string {
+	if mediaType, _, err := mime.ParseMediaType(value); err == nil {
+		return mediaType
+	}
+	mediaType, _, _ := strings.Cut(value, ";")
+	return strings.ToLower(strings.TrimSpace(mediaType))
+}
+
+// mergeEndpointSchema merges the query parameters, status classes and
+// content types of new into existing.
+func mergeEndpointSchema(existing, new *types.HTTPEndpoint) {
+	existing.QueryParams = MergeStrings(existing.QueryParams, new.QueryParams)
+	existing.StatusClasses = MergeStrings(existing.StatusClasses, new.StatusClasses)
+	existing.ContentTypes = MergeStrings(existing.ContentTypes, new.ContentTypes)
+}
+
+// normalizeEndpointSchema sorts the query parameters, status classes and
+// content types of endpoint, the content types gaining the ones of its
+// Content-Type header. Its headers are restricted to allowlist, given as
+// canonical names, and the values of a credential header or of a header
+// taking more than HeaderValueCollapseThreshold of them are collapsed into a
+// single DynamicIdentifier.
+func normalizeEndpointSchema(endpoint *types.HTTPEndpoint, allowlist []string) {
+	endpoint.QueryParams = sortedSet(endpoint.QueryParams, func(name string) string { return name })
+	endpoint.StatusClasses = sortedSet(endpoint.StatusClasses, StatusClass)
+	contentTypes := endpoint.ContentTypes
+	if len(endpoint.Headers) == 0 {
+		endpoint.ContentTypes = sortedSet(contentTypes, ContentType)
+		return
+	}
+	headers, err := endpoint.GetHeaders()
+	if err != nil {
+		endpoint.ContentTypes = sortedSet(contentTypes, ContentType)
+		return
+	}
+	// headers are matched by their canonical name but keep their spelling
+	kept := make(map[string][]string, len(headers))
+	for name, values := range headers {
+		if slices.Contains(allowlist, http.CanonicalHeaderKey(name)) {
+			kept[name] = values
+		}
+	}
+	for name, values := range kept {
+		if http.CanonicalHeaderKey(name) == "Content-Type" {
+			contentTypes = append(slices.Clip(contentTypes), values...)
+		}
+		values = sortedSet(values, func(value string) string { return value })
+		if len(values) > HeaderValueCollapseThreshold || slices.Contains(values, DynamicIdentifier) ||
+			slices.Contains(credentialHeaders, http.CanonicalHeaderKey(name)) {
+			values = []string{DynamicIdentifier}
+		}
+		kept[name] = values
+	}
+	endpoint.ContentTypes = sortedSet(contentTypes, ContentType)
+	if rawJSON, err := json.Marshal(kept); err == nil {
+		endpoint.Headers = rawJSON
+	}
+}
+
+// canonicalHeaderNames returns the canonical form of the header names of
+// allowlist, DefaultHeaderAllowlist when it is empty.
+func canonicalHeaderNames(allowlist []string) []string {
+	if len(allowlist) == 0 {
+		return defaultHeaderAllowlist
+	}
+	return sortedSet(allowlist, func(name string) string { return http.CanonicalHeaderKey(strings.TrimSpace(name)) })
+}
+
+// sortedSet returns the sorted distinct non-empty normalized values, nil if
+// there is none.
+func sortedSet(values []string, normalize func(string) string) []string {
+	var out []string
+	for _, v := range values {
+		if v = normalize(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	slices.Sort(out)
+	return slices.Compact(out)
+}
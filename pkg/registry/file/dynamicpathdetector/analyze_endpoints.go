@@ -17,6 +17,9 @@ func isWildcardPort(port string) bool {
 	return port == "0"
 }
 
+// AnalyzeEndpoints collapses the paths of endpoints with analyzer, merging the
+// endpoints left identical, and normalizes their schema, keeping the headers
+// of the allowlist of analyzer, see WithHeaderAllowlist.
 func AnalyzeEndpoints(endpoints *[]types.HTTPEndpoint, analyzer *PathAnalyzer) []types.HTTPEndpoint {
 	if len(*endpoints) == 0 {
 		return nil
@@ -44,11 +47,17 @@ func AnalyzeEndpoints(endpoints *[]types.HTTPEndpoint, analyzer *PathAnalyzer) [
 	// Cross-port folding happens here: only same-(path, direction) siblings
 	// of an explicit :0 wildcard get absorbed into it.
 	newEndpoints = MergeDuplicateEndpoints(newEndpoints)
+	for _, endpoint := range newEndpoints {
+		normalizeEndpointSchema(endpoint, analyzer.headerAllowlist)
+	}
 
 	return convertPointerToValueSlice(newEndpoints)
 }
 
 func ProcessEndpoint(endpoint *types.HTTPEndpoint, analyzer *PathAnalyzer, newEndpoints []*types.HTTPEndpoint) (*types.HTTPEndpoint, error) {
+	// the query is dropped from the endpoint, only the names of its
+	// parameters are kept
+	endpoint.QueryParams = MergeStrings(endpoint.QueryParams, QueryParamNames(endpoint.Endpoint))
 	analyzeURL, err := AnalyzeURL(endpoint.Endpoint, analyzer)
 	if err != nil {
 		return nil, err
@@ -61,6 +70,7 @@ func ProcessEndpoint(endpoint *types.HTTPEndpoint, analyzer *PathAnalyzer, newEn
 			if getEndpointKey(e) == getEndpointKey(endpoint) {
 				newEndpoints[i].Methods = MergeStrings(e.Methods, endpoint.Methods)
 				mergeHeaders(e, endpoint)
+				mergeEndpointSchema(e, endpoint)
 				return nil, nil
 			}
 		}
@@ -71,6 +81,10 @@ func ProcessEndpoint(endpoint *types.HTTPEndpoint, analyzer *PathAnalyzer, newEn
 			Internal:  endpoint.Internal,
 			Direction: endpoint.Direction,
 			Headers:   endpoint.Headers,
+
+			QueryParams:   endpoint.QueryParams,
+			StatusClasses: endpoint.StatusClasses,
+			ContentTypes:  endpoint.ContentTypes,
 		}
 
 		return &dynamicEndpoint, nil
@@ -155,6 +169,7 @@ func MergeDuplicateEndpoints(endpoints []*types.HTTPEndpoint) []*types.HTTPEndpo
 		if existing, found := seen[key]; found {
 			existing.Methods = MergeStrings(existing.Methods, endpoint.Methods)
 			mergeHeaders(existing, endpoint)
+			mergeEndpointSchema(existing, endpoint)
 			continue
 		}
 
@@ -173,6 +188,7 @@ func MergeDuplicateEndpoints(endpoints []*types.HTTPEndpoint) []*types.HTTPEndpo
 				}
 				endpoint.Methods = MergeStrings(endpoint.Methods, e.Methods)
 				mergeHeaders(endpoint, e)
+				mergeEndpointSchema(endpoint, e)
 				delete(seen, k)
 				newEndpoints = removeEndpoint(newEndpoints, e)
 			}
@@ -191,6 +207,7 @@ func MergeDuplicateEndpoints(endpoints []*types.HTTPEndpoint) []*types.HTTPEndpo
 		if existing, found := seen[wildcardKey]; found {
 			existing.Methods = MergeStrings(existing.Methods, endpoint.Methods)
 			mergeHeaders(existing, endpoint)
+			mergeEndpointSchema(existing, endpoint)
 			continue
 		}
 
@@ -0,0 +1,300 @@
+package dynamicpathdetector
+
+import (
+	"strings"
+
+	types "github.com/kubescape/storage/pkg/apis/softwarecomposition"
+)
+
+// AnalyzeExecs deduplicates exec calls and collapses the parts of them that
+// vary from one invocation to the next, so that workloads passing
+// timestamps or UUIDs to their processes do not grow a profile without
+// bound:
+//
+//   - An environment variable taking more than envThreshold distinct values
+//     across the execs of one path is kept by name only ("NAME" instead of
+//     "NAME=value").
+//   - Among the execs of one path with the same ArgsRequired and Envs, an
+//     argv position taking more than argsThreshold distinct values collapses
+//     to DynamicIdentifier ("⋯"). When all the values are paths with the same
+//     number of segments, only the varying segments collapse
+//     ("/tmp/⋯/out").
+//   - If more than argsThreshold distinct argv remain, e.g. because their
+//     length varies, they collapse to their common prefix followed by
+//     ExecArgsWildcard ("⋯⋯").
+//
+// Every collapsed argv still matches the argv it replaces under
+// MatchExecArgs, and literal execs already matched by a collapsed sibling
+// are dropped. A threshold <= 0 disables the corresponding collapsing. The
+// input order is kept, a collapsed exec takes the position of its first
+// member.
+func AnalyzeExecs(execs []types.ExecCalls, argsThreshold, envThreshold int) []types.ExecCalls {
+	out := make([]types.ExecCalls, len(execs))
+	copy(out, execs)
+	if envThreshold > 0 {
+		collapseExecEnvs(out, envThreshold)
+	}
+	out = dedupeExecs(out)
+	if argsThreshold <= 0 {
+		return out
+	}
+
+	groups := make(map[string][]int)
+	var order []string
+	for i, exec := range out {
+		key := execGroupKey(exec)
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], i)
+	}
+	changed := false
+	for _, key := range order {
+		if collapseExecArgs(out, groups[key], argsThreshold) {
+			changed = true
+		}
+	}
+	if changed {
+		out = dedupeExecs(out)
+	}
+	return consolidateExecs(out)
+}
+
+// collapseExecEnvs rewrites in place the Envs of the execs whose variables
+// vary, per path, past threshold. A bare name, without a value, marks the
+// variable as already collapsed.
+func collapseExecEnvs(execs []types.ExecCalls, threshold int) {
+	values := make(map[string]map[string]map[string]struct{})
+	for _, exec := range execs {
+		byName, ok := values[exec.Path]
+		if !ok {
+			byName = make(map[string]map[string]struct{})
+			values[exec.Path] = byName
+		}
+		for _, env := range exec.Envs {
+			name, value, _ := strings.Cut(env, "=")
+			if _, ok := byName[name]; !ok {
+				byName[name] = make(map[string]struct{})
+			}
+			byName[name][value] = struct{}{}
+		}
+	}
+	collapsed := make(map[string]map[string]bool, len(values))
+	for path, byName := range values {
+		for name, distinct := range byName {
+			if len(distinct) > threshold {
+				if collapsed[path] == nil {
+					collapsed[path] = make(map[string]bool)
+				}
+				collapsed[path][name] = true
+			}
+		}
+	}
+	for i := range execs {
+		names := collapsed[execs[i].Path]
+		if len(names) == 0 {
+			continue
+		}
+		envs := make([]string, 0, len(execs[i].Envs))
+		seen := make(map[string]struct{}, len(execs[i].Envs))
+		for _, env := range execs[i].Envs {
+			if name, _, _ := strings.Cut(env, "="); names[name] {
+				env = name
+			}
+			if _, ok := seen[env]; ok {
+				continue
+			}
+			seen[env] = struct{}{}
+			envs = append(envs, env)
+		}
+		execs[i].Envs = envs
+	}
+}
+
+func execGroupKey(exec types.ExecCalls) string {
+	var s strings.Builder
+	s.WriteString(exec.Path)
+	if exec.ArgsRequired {
+		s.WriteString("\x00required")
+	}
+	for _, env := range exec.Envs {
+		s.WriteString("\x00")
+		s.WriteString(env)
+	}
+	return s.String()
+}
+
+// collapseExecArgs collapses in place the Args of the execs at members,
+// which share a path, and reports whether any of them changed.
+func collapseExecArgs(execs []types.ExecCalls, members []int, threshold int) bool {
+	if distinctArgs(execs, members) <= threshold {
+		return false
+	}
+	changed := false
+
+	// per-position variability, among the argv of the same length
+	byLength := make(map[int][]int)
+	for _, i := range members {
+		byLength[len(execs[i].Args)] = append(byLength[len(execs[i].Args)], i)
+	}
+	for length, same := range byLength {
+		if len(same) <= threshold {
+			continue
+		}
+		for pos := 0; pos < length; pos++ {
+			values := make([]string, 0, len(same))
+			distinct := make(map[string]struct{})
+			for _, i := range same {
+				values = append(values, execs[i].Args[pos])
+				distinct[execs[i].Args[pos]] = struct{}{}
+			}
+			if len(distinct) <= threshold {
+				continue
+			}
+			collapsed := collapseArgValues(values, threshold)
+			for k, i := range same {
+				if execs[i].Args[pos] == collapsed[k] {
+					continue
+				}
+				if !changed {
+					// the first change copies the Args, the input may alias them
+					for _, j := range members {
+						execs[j].Args = append([]string(nil), execs[j].Args...)
+					}
+					changed = true
+				}
+				execs[i].Args[pos] = collapsed[k]
+			}
+		}
+	}
+	if distinctArgs(execs, members) <= threshold {
+		return changed
+	}
+
+	// still too many: the argv vary in length or in too many combinations,
+	// keep what they all start with
+	prefix := execs[members[0]].Args
+	for _, i := range members[1:] {
+		n := 0
+		for n < len(prefix) && n < len(execs[i].Args) && prefix[n] == execs[i].Args[n] {
+			n++
+		}
+		prefix = prefix[:n]
+	}
+	if n := len(prefix); n > 0 && prefix[n-1] == ExecArgsWildcard {
+		prefix = prefix[:n-1]
+	}
+	args := append(append(make([]string, 0, len(prefix)+1), prefix...), ExecArgsWildcard)
+	for _, i := range members {
+		execs[i].Args = args
+	}
+	return true
+}
+
+// collapseArgValues returns values with DynamicIdentifier in place of the
+// parts that vary past threshold: the varying segments when all values are
+// paths of the same depth and few enough patterns remain, the whole value
+// otherwise.
+func collapseArgValues(values []string, threshold int) []string {
+	out := make([]string, len(values))
+	segments := make([][]string, len(values))
+	depth := -1
+	for k, value := range values {
+		segments[k] = strings.Split(value, "/")
+		if depth == -1 {
+			depth = len(segments[k])
+		}
+		if len(segments[k]) != depth || depth < 2 {
+			depth = 0
+		}
+	}
+	if depth > 0 {
+		for seg := 0; seg < depth; seg++ {
+			distinct := make(map[string]struct{})
+			for k := range segments {
+				distinct[segments[k][seg]] = struct{}{}
+			}
+			if len(distinct) <= threshold {
+				continue
+			}
+			for k := range segments {
+				segments[k][seg] = DynamicIdentifier
+			}
+		}
+		distinct := make(map[string]struct{})
+		for k := range segments {
+			out[k] = strings.Join(segments[k], "/")
+			distinct[out[k]] = struct{}{}
+		}
+		if len(distinct) <= threshold {
+			return out
+		}
+	}
+	for k := range out {
+		out[k] = DynamicIdentifier
+	}
+	return out
+}
+
+func distinctArgs(execs []types.ExecCalls, members []int) int {
+	distinct := make(map[string]struct{}, len(members))
+	for _, i := range members {
+		distinct[strings.Join(execs[i].Args, "\x00")] = struct{}{}
+	}
+	return len(distinct)
+}
+
+// consolidateExecs drops the literal execs matched by a collapsed sibling
+// of the same group, see AnalyzeExecs.
+func consolidateExecs(execs []types.ExecCalls) []types.ExecCalls {
+	patterns := make(map[string][][]string)
+	for _, exec := range execs {
+		if isArgsPattern(exec.Args) {
+			key := execGroupKey(exec)
+			patterns[key] = append(patterns[key], exec.Args)
+		}
+	}
+	if len(patterns) == 0 {
+		return execs
+	}
+	out := execs[:0]
+	for _, exec := range execs {
+		if !isArgsPattern(exec.Args) && coveredArgs(patterns[execGroupKey(exec)], exec.Args) {
+			continue
+		}
+		out = append(out, exec)
+	}
+	return out
+}
+
+func isArgsPattern(args []string) bool {
+	for _, arg := range args {
+		if strings.Contains(arg, DynamicIdentifier) {
+			return true
+		}
+	}
+	return false
+}
+
+func coveredArgs(patterns [][]string, args []string) bool {
+	for _, pattern := range patterns {
+		if matchExecArgsStrict(pattern, args) {
+			return true
+		}
+	}
+	return false
+}
+
+func dedupeExecs(execs []types.ExecCalls) []types.ExecCalls {
+	out := make([]types.ExecCalls, 0, len(execs))
+	seen := make(map[string]struct{}, len(execs))
+	for _, exec := range execs {
+		key := exec.String()
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, exec)
+	}
+	return out
+}
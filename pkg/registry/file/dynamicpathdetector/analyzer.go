@@ -37,13 +37,22 @@ func NewPathAnalyzerWithConfigs(defaultThreshold int, configs []CollapseConfig)
 	copied := make([]CollapseConfig, len(configs))
 	copy(copied, configs)
 	return &PathAnalyzer{
-		RootNodes:  make(map[string]*SegmentNode),
-		threshold:  defaultThreshold,
-		configs:    copied,
-		defaultCfg: CollapseConfig{Prefix: "/", Threshold: defaultThreshold},
+		RootNodes:       make(map[string]*SegmentNode),
+		threshold:       defaultThreshold,
+		configs:         copied,
+		defaultCfg:      CollapseConfig{Prefix: "/", Threshold: defaultThreshold},
+		headerAllowlist: defaultHeaderAllowlist,
 	}
 }
 
+// WithHeaderAllowlist sets the names of the headers AnalyzeEndpoints keeps,
+// matched case-insensitively, DefaultHeaderAllowlist when names is empty.
+// It returns the analyzer, for chaining after its constructor.
+func (ua *PathAnalyzer) WithHeaderAllowlist(names []string) *PathAnalyzer {
+	ua.headerAllowlist = canonicalHeaderNames(names)
+	return ua
+}
+
 // effectiveThreshold returns the collapse threshold applicable to the given
 // path prefix, picking the longest matching CollapseConfig or falling back
 // to the analyzer's default. Loop is O(len(configs)) and configs is small
@@ -18,6 +18,7 @@ package dynamicpathdetector
 
 import (
 	"math"
+	"slices"
 
 	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -44,9 +45,14 @@ func clampInt32(v int) int32 {
 // CRDFromCollapseSettings to round-trip back when tooling (e.g. bobctl
 // autotune) needs to write the CRD.
 type CollapseSettings struct {
-	OpenDynamicThreshold     int
-	EndpointDynamicThreshold int
-	CollapseConfigs          []CollapseConfig
+	OpenDynamicThreshold      int
+	EndpointDynamicThreshold  int
+	CollapseConfigs           []CollapseConfig
+	IPCollapseThreshold       int
+	DNSCollapseThreshold      int
+	ExecArgsCollapseThreshold int
+	EnvCollapseThreshold      int
+	HeaderAllowlist           []string
 }
 
 // DefaultCollapseSettings returns the built-in baseline. The returned
@@ -56,9 +62,14 @@ type CollapseSettings struct {
 // accessor already enforces.
 func DefaultCollapseSettings() CollapseSettings {
 	return CollapseSettings{
-		OpenDynamicThreshold:     OpenDynamicThreshold,
-		EndpointDynamicThreshold: EndpointDynamicThreshold,
-		CollapseConfigs:          DefaultCollapseConfigs(),
+		OpenDynamicThreshold:      OpenDynamicThreshold,
+		EndpointDynamicThreshold:  EndpointDynamicThreshold,
+		CollapseConfigs:           DefaultCollapseConfigs(),
+		IPCollapseThreshold:       IPCollapseThreshold,
+		DNSCollapseThreshold:      DNSCollapseThreshold,
+		ExecArgsCollapseThreshold: ExecArgsCollapseThreshold,
+		EnvCollapseThreshold:      EnvCollapseThreshold,
+		HeaderAllowlist:           DefaultHeaderAllowlist(),
 	}
 }
 
@@ -87,6 +98,26 @@ func CollapseSettingsFromCRD(crd *softwarecomposition.CollapseConfiguration) Col
 	if endpoint <= 0 {
 		endpoint = EndpointDynamicThreshold
 	}
+	ip := int(crd.Spec.IPCollapseThreshold)
+	if ip <= 0 {
+		ip = IPCollapseThreshold
+	}
+	dns := int(crd.Spec.DNSCollapseThreshold)
+	if dns <= 0 {
+		dns = DNSCollapseThreshold
+	}
+	execArgs := int(crd.Spec.ExecArgsCollapseThreshold)
+	if execArgs <= 0 {
+		execArgs = ExecArgsCollapseThreshold
+	}
+	env := int(crd.Spec.EnvCollapseThreshold)
+	if env <= 0 {
+		env = EnvCollapseThreshold
+	}
+	headerAllowlist := DefaultHeaderAllowlist()
+	if len(crd.Spec.HeaderAllowlist) > 0 {
+		headerAllowlist = append([]string(nil), crd.Spec.HeaderAllowlist...)
+	}
 	configs := make([]CollapseConfig, len(crd.Spec.CollapseConfigs))
 	for i, entry := range crd.Spec.CollapseConfigs {
 		configs[i] = CollapseConfig{
@@ -95,9 +126,14 @@ func CollapseSettingsFromCRD(crd *softwarecomposition.CollapseConfiguration) Col
 		}
 	}
 	return CollapseSettings{
-		OpenDynamicThreshold:     open,
-		EndpointDynamicThreshold: endpoint,
-		CollapseConfigs:          configs,
+		OpenDynamicThreshold:      open,
+		EndpointDynamicThreshold:  endpoint,
+		CollapseConfigs:           configs,
+		IPCollapseThreshold:       ip,
+		DNSCollapseThreshold:      dns,
+		ExecArgsCollapseThreshold: execArgs,
+		EnvCollapseThreshold:      env,
+		HeaderAllowlist:           headerAllowlist,
 	}
 }
 
@@ -106,6 +142,11 @@ func CollapseSettingsFromCRD(crd *softwarecomposition.CollapseConfiguration) Col
 // Update calls. Tooling (notably bobctl autotune) uses it to push tuned
 // thresholds back into a running cluster.
 func CRDFromCollapseSettings(name string, settings CollapseSettings) *softwarecomposition.CollapseConfiguration {
+	var headerAllowlist []string
+	if !slices.Equal(settings.HeaderAllowlist, defaultHeaderAllowlist) {
+		// the compiled-in allowlist is left out, as in a CRD omitting it
+		headerAllowlist = append([]string(nil), settings.HeaderAllowlist...)
+	}
 	entries := make([]softwarecomposition.CollapseConfigEntry, len(settings.CollapseConfigs))
 	for i, cfg := range settings.CollapseConfigs {
 		entries[i] = softwarecomposition.CollapseConfigEntry{
@@ -118,9 +159,14 @@ func CRDFromCollapseSettings(name string, settings CollapseSettings) *softwareco
 			Name: name,
 		},
 		Spec: softwarecomposition.CollapseConfigurationSpec{
-			OpenDynamicThreshold:     clampInt32(settings.OpenDynamicThreshold),
-			EndpointDynamicThreshold: clampInt32(settings.EndpointDynamicThreshold),
-			CollapseConfigs:          entries,
+			OpenDynamicThreshold:      clampInt32(settings.OpenDynamicThreshold),
+			EndpointDynamicThreshold:  clampInt32(settings.EndpointDynamicThreshold),
+			CollapseConfigs:           entries,
+			IPCollapseThreshold:       clampInt32(settings.IPCollapseThreshold),
+			DNSCollapseThreshold:      clampInt32(settings.DNSCollapseThreshold),
+			ExecArgsCollapseThreshold: clampInt32(settings.ExecArgsCollapseThreshold),
+			EnvCollapseThreshold:      clampInt32(settings.EnvCollapseThreshold),
+			HeaderAllowlist:           headerAllowlist,
 		},
 	}
 }
@@ -0,0 +1,100 @@
+package dynamicpathdetectortests
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	types "github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"github.com/kubescape/storage/pkg/registry/file/dynamicpathdetector"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryParamNames(t *testing.T) {
+	assert.Nil(t, dynamicpathdetector.QueryParamNames(":80/users"))
+	assert.Equal(t, []string{"page", "q", "sort by"},
+		dynamicpathdetector.QueryParamNames(":80/users?q=alice&sort+by=name&page=2&q=bob&=x#top"))
+}
+
+func TestStatusClass(t *testing.T) {
+	for status, want := range map[string]string{
+		"200": "2xx",
+		"404": "4xx",
+		"5XX": "5xx",
+		"600": "",
+		"20":  "",
+		"2a0": "",
+	} {
+		assert.Equal(t, want, dynamicpathdetector.StatusClass(status), status)
+	}
+}
+
+func TestContentType(t *testing.T) {
+	assert.Equal(t, "application/json", dynamicpathdetector.ContentType("Application/JSON; charset=utf-8"))
+	assert.Equal(t, "text/plain", dynamicpathdetector.ContentType("text/plain"))
+}
+
+func TestAnalyzeEndpointsSchema(t *testing.T) {
+	headers := func(h map[string][]string) []byte {
+		raw, _ := json.Marshal(h)
+		return raw
+	}
+	var endpoints []types.HTTPEndpoint
+	for i := 0; i < 6; i++ {
+		endpoints = append(endpoints, types.HTTPEndpoint{
+			Endpoint:      fmt.Sprintf(":80/search?q=%d&page=%d", i, i),
+			Methods:       []string{"GET"},
+			StatusClasses: []string{fmt.Sprintf("%d00", 2+i%3)},
+			Headers: headers(map[string][]string{
+				"user-agent":    {fmt.Sprintf("client/%d", i)},
+				"Content-Type":  {"application/json; charset=utf-8"},
+				"X-Request-Id":  {fmt.Sprintf("%d", i)},
+				"Authorization": {"Bearer token"},
+			}),
+		})
+	}
+	endpoints = append(endpoints, types.HTTPEndpoint{
+		Endpoint:     ":80/search?lang=en",
+		Methods:      []string{"POST"},
+		ContentTypes: []string{"text/plain"},
+	})
+
+	analyzer := dynamicpathdetector.NewPathAnalyzer(100)
+	result := dynamicpathdetector.AnalyzeEndpoints(&endpoints, analyzer)
+
+	assert.Len(t, result, 1)
+	got := result[0]
+	assert.Equal(t, ":80/search", got.Endpoint)
+	assert.ElementsMatch(t, []string{"GET", "POST"}, got.Methods)
+	assert.Equal(t, []string{"lang", "page", "q"}, got.QueryParams)
+	assert.Equal(t, []string{"2xx", "3xx", "4xx"}, got.StatusClasses)
+	assert.Equal(t, []string{"application/json", "text/plain"}, got.ContentTypes)
+
+	gotHeaders, err := got.GetHeaders()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"user-agent":    {dynamicpathdetector.DynamicIdentifier},
+		"Content-Type":  {"application/json; charset=utf-8"},
+		"Authorization": {dynamicpathdetector.DynamicIdentifier},
+	}, gotHeaders)
+}
+
+func TestAnalyzeEndpointsHeaderAllowlist(t *testing.T) {
+	raw, _ := json.Marshal(map[string][]string{
+		"X-Tenant":     {"acme"},
+		"X-Api-Key":    {"secret"},
+		"Content-Type": {"application/json"},
+	})
+	endpoints := []types.HTTPEndpoint{{Endpoint: ":80/users", Methods: []string{"GET"}, Headers: raw}}
+
+	analyzer := dynamicpathdetector.NewPathAnalyzer(100).WithHeaderAllowlist([]string{"x-tenant", "x-api-key"})
+	result := dynamicpathdetector.AnalyzeEndpoints(&endpoints, analyzer)
+
+	assert.Len(t, result, 1)
+	gotHeaders, err := result[0].GetHeaders()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"X-Tenant":  {"acme"},
+		"X-Api-Key": {dynamicpathdetector.DynamicIdentifier},
+	}, gotHeaders)
+}
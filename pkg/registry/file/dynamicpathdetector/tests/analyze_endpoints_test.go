@@ -161,7 +161,7 @@ func TestAnalyzeEndpoints(t *testing.T) {
 				{
 					Endpoint: ":80/x/\u22ef/posts/\u22ef",
 					Methods:  []string{"GET", "POST"},
-					Headers:  json.RawMessage(`{"Authorization":["Bearer token"],"Content-Type":["<<UNORDERED>>","application/json","application/xml"],"X-API-Key":["key1"]}`),
+					Headers:  json.RawMessage(`{"Authorization":["⋯"],"Content-Type":["<<UNORDERED>>","application/json","application/xml"],"X-API-Key":["⋯"]}`),
 				},
 			},
 		},
@@ -0,0 +1,158 @@
+package dynamicpathdetectortests
+
+import (
+	"fmt"
+	"testing"
+
+	types "github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"github.com/kubescape/storage/pkg/registry/file/dynamicpathdetector"
+	"github.com/stretchr/testify/assert"
+)
+
+func cronExecs(n int, args func(i int) []string) []types.ExecCalls {
+	execs := make([]types.ExecCalls, 0, n)
+	for i := 0; i < n; i++ {
+		execs = append(execs, types.ExecCalls{Path: "/usr/bin/backup", Args: args(i)})
+	}
+	return execs
+}
+
+func TestAnalyzeExecs(t *testing.T) {
+	tests := []struct {
+		name  string
+		execs []types.ExecCalls
+		want  []types.ExecCalls
+	}{
+		{
+			name: "below threshold is only deduplicated",
+			execs: []types.ExecCalls{
+				{Path: "/bin/sh", Args: []string{"-c", "true"}},
+				{Path: "/bin/ls", Args: []string{"-l"}},
+				{Path: "/bin/sh", Args: []string{"-c", "true"}},
+			},
+			want: []types.ExecCalls{
+				{Path: "/bin/sh", Args: []string{"-c", "true"}},
+				{Path: "/bin/ls", Args: []string{"-l"}},
+			},
+		},
+		{
+			name: "varying position collapses",
+			execs: append(cronExecs(4, func(i int) []string {
+				return []string{"--since", fmt.Sprintf("2024-01-0%dT00:00:00Z", i+1), "--verbose"}
+			}), types.ExecCalls{Path: "/bin/ls"}),
+			want: []types.ExecCalls{
+				{Path: "/usr/bin/backup", Args: []string{"--since", dynamicpathdetector.DynamicIdentifier, "--verbose"}},
+				{Path: "/bin/ls"},
+			},
+		},
+		{
+			name: "steady positions keep their values",
+			execs: cronExecs(6, func(i int) []string {
+				return []string{[]string{"full", "incremental"}[i%2], fmt.Sprintf("job-%d", i)}
+			}),
+			want: []types.ExecCalls{
+				{Path: "/usr/bin/backup", Args: []string{"full", dynamicpathdetector.DynamicIdentifier}},
+				{Path: "/usr/bin/backup", Args: []string{"incremental", dynamicpathdetector.DynamicIdentifier}},
+			},
+		},
+		{
+			name: "path argument collapses segment-wise",
+			execs: cronExecs(4, func(i int) []string {
+				return []string{"--out", fmt.Sprintf("/var/backups/%d/dump.sql", i)}
+			}),
+			want: []types.ExecCalls{
+				{Path: "/usr/bin/backup", Args: []string{"--out", "/var/backups/⋯/dump.sql"}},
+			},
+		},
+		{
+			name: "varying length collapses to common prefix",
+			execs: cronExecs(4, func(i int) []string {
+				args := []string{"sync"}
+				for j := 0; j <= i; j++ {
+					args = append(args, fmt.Sprintf("file-%d", j))
+				}
+				return args
+			}),
+			want: []types.ExecCalls{
+				{Path: "/usr/bin/backup", Args: []string{"sync", "file-0", dynamicpathdetector.ExecArgsWildcard}},
+			},
+		},
+		{
+			name: "literal covered by an existing pattern is dropped",
+			execs: []types.ExecCalls{
+				{Path: "/usr/bin/backup", Args: []string{"--since", dynamicpathdetector.DynamicIdentifier}},
+				{Path: "/usr/bin/backup", Args: []string{"--since", "yesterday"}},
+				{Path: "/usr/bin/backup", Args: []string{"--until", "yesterday"}},
+			},
+			want: []types.ExecCalls{
+				{Path: "/usr/bin/backup", Args: []string{"--since", dynamicpathdetector.DynamicIdentifier}},
+				{Path: "/usr/bin/backup", Args: []string{"--until", "yesterday"}},
+			},
+		},
+		{
+			name: "ArgsRequired execs are analyzed apart",
+			execs: []types.ExecCalls{
+				{Path: "/usr/bin/backup", Args: []string{dynamicpathdetector.ExecArgsWildcard}},
+				{Path: "/usr/bin/backup", Args: []string{"--since", "yesterday"}, ArgsRequired: true},
+			},
+			want: []types.ExecCalls{
+				{Path: "/usr/bin/backup", Args: []string{dynamicpathdetector.ExecArgsWildcard}},
+				{Path: "/usr/bin/backup", Args: []string{"--since", "yesterday"}, ArgsRequired: true},
+			},
+		},
+		{
+			name: "varying env is kept by name",
+			execs: []types.ExecCalls{
+				{Path: "/usr/bin/backup", Envs: []string{"HOME=/root", "RUN_ID=1"}},
+				{Path: "/usr/bin/backup", Envs: []string{"HOME=/root", "RUN_ID=2"}},
+				{Path: "/bin/ls", Envs: []string{"RUN_ID=1"}},
+			},
+			want: []types.ExecCalls{
+				{Path: "/usr/bin/backup", Envs: []string{"HOME=/root", "RUN_ID"}},
+				{Path: "/bin/ls", Envs: []string{"RUN_ID=1"}},
+			},
+		},
+		{
+			name:  "nil",
+			execs: nil,
+			want:  []types.ExecCalls{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dynamicpathdetector.AnalyzeExecs(tt.execs, 3, 1)
+			assert.Equal(t, tt.want, got)
+			// every input argv is still admitted by the result
+			for _, exec := range tt.execs {
+				assert.True(t, admitted(got, exec), "%v is no longer admitted by %v", exec, got)
+			}
+			// analysis is idempotent
+			assert.Equal(t, got, dynamicpathdetector.AnalyzeExecs(got, 3, 1))
+		})
+	}
+}
+
+func TestAnalyzeExecs_Disabled(t *testing.T) {
+	execs := cronExecs(20, func(i int) []string { return []string{fmt.Sprint(i)} })
+	execs[0].Envs = []string{"RUN_ID=0"}
+	execs[1].Envs = []string{"RUN_ID=1"}
+	assert.Equal(t, execs, dynamicpathdetector.AnalyzeExecs(execs, 0, 0))
+}
+
+func TestAnalyzeExecs_DoesNotMutateInput(t *testing.T) {
+	execs := cronExecs(4, func(i int) []string { return []string{"--id", fmt.Sprint(i)} })
+	_ = dynamicpathdetector.AnalyzeExecs(execs, 3, 1)
+	for i, exec := range execs {
+		assert.Equal(t, []string{"--id", fmt.Sprint(i)}, exec.Args)
+	}
+}
+
+func admitted(profile []types.ExecCalls, exec types.ExecCalls) bool {
+	for _, p := range profile {
+		if p.Path == exec.Path && p.ArgsRequired == exec.ArgsRequired &&
+			dynamicpathdetector.MatchExecArgs(p.Args, true, exec.Args) {
+			return true
+		}
+	}
+	return false
+}
@@ -87,12 +87,20 @@ func TestCollapseSettingsFromCRD_RoundTrip(t *testing.T) {
 				{Prefix: "/var/log", Threshold: 50},
 				{Prefix: "/opt", Threshold: 25},
 			},
+			IPCollapseThreshold:       8,
+			DNSCollapseThreshold:      4,
+			ExecArgsCollapseThreshold: 6,
+			EnvCollapseThreshold:      2,
 		},
 	}
 
 	settings := dynamicpathdetector.CollapseSettingsFromCRD(crd)
 	assert.Equal(t, 42, settings.OpenDynamicThreshold)
 	assert.Equal(t, 84, settings.EndpointDynamicThreshold)
+	assert.Equal(t, 8, settings.IPCollapseThreshold)
+	assert.Equal(t, 4, settings.DNSCollapseThreshold)
+	assert.Equal(t, 6, settings.ExecArgsCollapseThreshold)
+	assert.Equal(t, 2, settings.EnvCollapseThreshold)
 	require.Len(t, settings.CollapseConfigs, 3)
 	assert.Equal(t, "/etc", settings.CollapseConfigs[0].Prefix)
 	assert.Equal(t, 100, settings.CollapseConfigs[0].Threshold)
@@ -131,6 +139,14 @@ func TestCollapseSettingsFromCRD_ZeroGlobalThresholdsUseDefaults(t *testing.T) {
 		"omitted endpointDynamicThreshold must use the compiled default, not 0")
 	assert.Positive(t, got.OpenDynamicThreshold, "must never be the over-collapsing 0")
 	assert.Positive(t, got.EndpointDynamicThreshold, "must never be the over-collapsing 0")
+	assert.Equal(t, want.IPCollapseThreshold, got.IPCollapseThreshold,
+		"omitted ipCollapseThreshold must use the compiled default, not 0")
+	assert.Equal(t, want.DNSCollapseThreshold, got.DNSCollapseThreshold,
+		"omitted dnsCollapseThreshold must use the compiled default, not 0")
+	assert.Equal(t, want.ExecArgsCollapseThreshold, got.ExecArgsCollapseThreshold,
+		"omitted execArgsCollapseThreshold must use the compiled default, not 0")
+	assert.Equal(t, want.EnvCollapseThreshold, got.EnvCollapseThreshold,
+		"omitted envCollapseThreshold must use the compiled default, not 0")
 }
 
 // TestCollapseSettingsFromCRD_OnlyCollapseConfigsKeepsGlobalDefaults pins the
@@ -202,6 +218,10 @@ func TestCollapseSettings_FullRoundTrip(t *testing.T) {
 				{Prefix: "/etc", Threshold: 100},
 				{Prefix: "/var/run", Threshold: 50},
 			},
+			IPCollapseThreshold:       dynamicpathdetector.IPCollapseThreshold,
+			DNSCollapseThreshold:      dynamicpathdetector.DNSCollapseThreshold,
+			ExecArgsCollapseThreshold: dynamicpathdetector.ExecArgsCollapseThreshold,
+			EnvCollapseThreshold:      dynamicpathdetector.EnvCollapseThreshold,
 		},
 	}
 
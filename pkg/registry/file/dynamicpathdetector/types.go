@@ -21,9 +21,22 @@ const (
 // OpenDynamicThreshold is the fallback threshold used by AnalyzeOpens when
 // no more-specific CollapseConfig matches the walked path prefix.
 // EndpointDynamicThreshold is the counterpart for AnalyzeEndpoints.
+// IPCollapseThreshold and DNSCollapseThreshold are the counterparts for the
+// addresses and names of network neighbors, see networkmatch.CollapseIPs and
+// networkmatch.CollapseDNSNames. ExecArgsCollapseThreshold and
+// EnvCollapseThreshold are the counterparts for the argv and environment of
+// exec calls, see AnalyzeExecs; an environment variable is kept by name only
+// as soon as it takes a second value. HeaderValueCollapseThreshold is the
+// counterpart for the values of the headers of HTTP endpoints, see
+// AnalyzeEndpoints.
 const (
-	OpenDynamicThreshold     = 50
-	EndpointDynamicThreshold = 100
+	OpenDynamicThreshold         = 50
+	EndpointDynamicThreshold     = 100
+	IPCollapseThreshold          = 16
+	DNSCollapseThreshold         = 16
+	ExecArgsCollapseThreshold    = 16
+	EnvCollapseThreshold         = 1
+	HeaderValueCollapseThreshold = 4
 )
 
 // --- Collapse configuration ---
@@ -86,6 +99,8 @@ type PathAnalyzer struct {
 	threshold  int              // fallback threshold when no config matches
 	configs    []CollapseConfig // per-prefix overrides; longest prefix wins
 	defaultCfg CollapseConfig   // explicit fallback; equivalent to {Prefix:"/", Threshold: threshold}
+	// headerAllowlist carries the canonical names of the headers AnalyzeEndpoints keeps
+	headerAllowlist []string
 }
 
 func (sn *SegmentNode) IsNextDynamic() bool {
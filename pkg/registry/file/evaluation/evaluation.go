@@ -0,0 +1,291 @@
+// Package evaluation tells whether a stored profile covers runtime
+// observations, i.e. whether the node-agent would let them through.
+//
+// It matches with the same functions the node-agent uses: CompareDynamic for
+// exec, open and endpoint paths, MatchExecArgs for argv, and the networkmatch
+// matchers for IP addresses and DNS names, so that a wildcard or a collapsed
+// entry covers exactly what it covers at runtime.
+package evaluation
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"github.com/kubescape/storage/pkg/registry/file/dynamicpathdetector"
+	"github.com/kubescape/storage/pkg/registry/file/networkmatch"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// profile is the part of a profile an evaluation reads, flattened from the
+// selected container. field is the path of the container, or of the spec for
+// a ContainerProfile, and kind names what the profile records.
+type profile struct {
+	kind         string
+	field        *field.Path
+	execs        []softwarecomposition.ExecCalls
+	opens        []softwarecomposition.OpenCalls
+	syscalls     []string
+	capabilities []string
+	endpoints    []softwarecomposition.HTTPEndpoint
+	ingress      []softwarecomposition.NetworkNeighbor
+	egress       []softwarecomposition.NetworkNeighbor
+	hasProcesses bool
+	hasNetwork   bool
+}
+
+// Evaluate returns one result per observation of spec, in order. obj must be
+// an ApplicationProfile, a NetworkNeighborhood or a ContainerProfile; an
+// error is returned when it is not, or when the container of spec is not
+// found in it.
+func Evaluate(obj runtime.Object, spec *softwarecomposition.ProfileEvaluationSpec) (softwarecomposition.ProfileEvaluationStatus, error) {
+	p, err := profileOf(obj, spec.ContainerName)
+	if err != nil {
+		return softwarecomposition.ProfileEvaluationStatus{}, err
+	}
+	status := softwarecomposition.ProfileEvaluationStatus{
+		Results: make([]softwarecomposition.ProfileObservationResult, 0, len(spec.Observations)),
+	}
+	for i := range spec.Observations {
+		status.Results = append(status.Results, p.evaluate(&spec.Observations[i]))
+	}
+	return status, nil
+}
+
+// Validate checks that every observation carries the fields its type needs.
+func Validate(spec *softwarecomposition.ProfileEvaluationSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	fp := field.NewPath("spec", "observations")
+	for i, o := range spec.Observations {
+		idx := fp.Index(i)
+		switch o.Type {
+		case softwarecomposition.ObservationTypeExec, softwarecomposition.ObservationTypeOpen:
+			if o.Path == "" {
+				allErrs = append(allErrs, field.Required(idx.Child("path"), ""))
+			}
+		case softwarecomposition.ObservationTypeSyscall, softwarecomposition.ObservationTypeCapability:
+			if o.Name == "" {
+				allErrs = append(allErrs, field.Required(idx.Child("name"), ""))
+			}
+		case softwarecomposition.ObservationTypeEgress, softwarecomposition.ObservationTypeIngress:
+			if o.IPAddress == "" && o.DNSName == "" {
+				allErrs = append(allErrs, field.Required(idx, "one of ipAddress and dnsName is required"))
+			}
+		case softwarecomposition.ObservationTypeHTTPEndpoint:
+			if o.Endpoint == "" {
+				allErrs = append(allErrs, field.Required(idx.Child("endpoint"), ""))
+			}
+		default:
+			allErrs = append(allErrs, field.NotSupported(idx.Child("type"), o.Type, []softwarecomposition.ObservationType{
+				softwarecomposition.ObservationTypeExec,
+				softwarecomposition.ObservationTypeOpen,
+				softwarecomposition.ObservationTypeSyscall,
+				softwarecomposition.ObservationTypeCapability,
+				softwarecomposition.ObservationTypeEgress,
+				softwarecomposition.ObservationTypeIngress,
+				softwarecomposition.ObservationTypeHTTPEndpoint,
+			}))
+		}
+	}
+	return allErrs
+}
+
+func profileOf(obj runtime.Object, containerName string) (*profile, error) {
+	switch o := obj.(type) {
+	case *softwarecomposition.ApplicationProfile:
+		fp, c, err := selectContainer(containerName, o.Spec.Containers, o.Spec.InitContainers, o.Spec.EphemeralContainers,
+			func(c softwarecomposition.ApplicationProfileContainer) string { return c.Name })
+		if err != nil {
+			return nil, err
+		}
+		return &profile{
+			kind:         "ApplicationProfile",
+			field:        fp,
+			execs:        c.Execs,
+			opens:        c.Opens,
+			syscalls:     c.Syscalls,
+			capabilities: c.Capabilities,
+			endpoints:    c.Endpoints,
+			hasProcesses: true,
+		}, nil
+	case *softwarecomposition.NetworkNeighborhood:
+		fp, c, err := selectContainer(containerName, o.Spec.Containers, o.Spec.InitContainers, o.Spec.EphemeralContainers,
+			func(c softwarecomposition.NetworkNeighborhoodContainer) string { return c.Name })
+		if err != nil {
+			return nil, err
+		}
+		return &profile{
+			kind:       "NetworkNeighborhood",
+			field:      fp,
+			ingress:    c.Ingress,
+			egress:     c.Egress,
+			hasNetwork: true,
+		}, nil
+	case *softwarecomposition.ContainerProfile:
+		return &profile{
+			kind:         "ContainerProfile",
+			field:        field.NewPath("spec"),
+			execs:        o.Spec.Execs,
+			opens:        o.Spec.Opens,
+			syscalls:     o.Spec.Syscalls,
+			capabilities: o.Spec.Capabilities,
+			endpoints:    o.Spec.Endpoints,
+			ingress:      o.Spec.Ingress,
+			egress:       o.Spec.Egress,
+			hasProcesses: true,
+			hasNetwork:   true,
+		}, nil
+	default:
+		return nil, fmt.Errorf("cannot evaluate a %T", obj)
+	}
+}
+
+// selectContainer finds the container named name in the three container lists
+// of a profile. An empty name selects the only container of a profile which
+// has exactly one.
+func selectContainer[T any](name string, containers, initContainers, ephemeralContainers []T, nameOf func(T) string) (*field.Path, T, error) {
+	var zero T
+	lists := []struct {
+		field string
+		items []T
+	}{
+		{"containers", containers},
+		{"initContainers", initContainers},
+		{"ephemeralContainers", ephemeralContainers},
+	}
+	if name == "" {
+		if len(containers)+len(initContainers)+len(ephemeralContainers) != 1 {
+			return nil, zero, fmt.Errorf("containerName is required when the profile does not have exactly one container")
+		}
+		for _, l := range lists {
+			if len(l.items) == 1 {
+				return field.NewPath("spec", l.field).Index(0), l.items[0], nil
+			}
+		}
+	}
+	for _, l := range lists {
+		for i, c := range l.items {
+			if nameOf(c) == name {
+				return field.NewPath("spec", l.field).Index(i), c, nil
+			}
+		}
+	}
+	return nil, zero, fmt.Errorf("container %q not found in the profile", name)
+}
+
+func (p *profile) evaluate(o *softwarecomposition.ProfileObservation) softwarecomposition.ProfileObservationResult {
+	switch o.Type {
+	case softwarecomposition.ObservationTypeExec:
+		if !p.hasProcesses {
+			return p.notRecorded(o)
+		}
+		for i, e := range p.execs {
+			if dynamicpathdetector.CompareDynamic(e.Path, o.Path) && dynamicpathdetector.MatchExecArgs(e.Args, e.ArgsRequired, o.Args) {
+				return p.matched("execs", i, strings.Join(append([]string{e.Path}, e.Args...), " "))
+			}
+		}
+	case softwarecomposition.ObservationTypeOpen:
+		if !p.hasProcesses {
+			return p.notRecorded(o)
+		}
+		for i, e := range p.opens {
+			if dynamicpathdetector.CompareDynamic(e.Path, o.Path) && containsAll(e.Flags, o.Flags) {
+				return p.matched("opens", i, strings.TrimSpace(e.Path+" "+strings.Join(e.Flags, ",")))
+			}
+		}
+	case softwarecomposition.ObservationTypeSyscall:
+		if !p.hasProcesses {
+			return p.notRecorded(o)
+		}
+		if i := slices.Index(p.syscalls, o.Name); i >= 0 {
+			return p.matched("syscalls", i, p.syscalls[i])
+		}
+	case softwarecomposition.ObservationTypeCapability:
+		if !p.hasProcesses {
+			return p.notRecorded(o)
+		}
+		if i := slices.Index(p.capabilities, o.Name); i >= 0 {
+			return p.matched("capabilities", i, p.capabilities[i])
+		}
+	case softwarecomposition.ObservationTypeHTTPEndpoint:
+		if !p.hasProcesses {
+			return p.notRecorded(o)
+		}
+		for i, e := range p.endpoints {
+			if dynamicpathdetector.CompareDynamic(e.Endpoint, o.Endpoint) &&
+				(o.Method == "" || slices.Contains(e.Methods, o.Method)) &&
+				(o.Direction == "" || e.Direction == o.Direction) {
+				return p.matched("endpoints", i, e.Endpoint)
+			}
+		}
+	case softwarecomposition.ObservationTypeEgress, softwarecomposition.ObservationTypeIngress:
+		if !p.hasNetwork {
+			return p.notRecorded(o)
+		}
+		name, neighbors := "egress", p.egress
+		if o.Type == softwarecomposition.ObservationTypeIngress {
+			name, neighbors = "ingress", p.ingress
+		}
+		for i, n := range neighbors {
+			if matchNeighbor(&n, o) {
+				return p.matched(name, i, n.Identifier)
+			}
+		}
+	default:
+		return softwarecomposition.ProfileObservationResult{Reason: fmt.Sprintf("unsupported observation type %q", o.Type)}
+	}
+	return softwarecomposition.ProfileObservationResult{Reason: "no entry of the profile covers the observation"}
+}
+
+func (p *profile) matched(list string, i int, entry string) softwarecomposition.ProfileObservationResult {
+	return softwarecomposition.ProfileObservationResult{
+		Allowed:      true,
+		MatchedField: p.field.Child(list).Index(i).String(),
+		MatchedEntry: entry,
+	}
+}
+
+func (p *profile) notRecorded(o *softwarecomposition.ProfileObservation) softwarecomposition.ProfileObservationResult {
+	return softwarecomposition.ProfileObservationResult{Reason: fmt.Sprintf("%s does not record %s observations", p.kind, o.Type)}
+}
+
+// matchNeighbor reports whether n covers the peer of o. The peer address and
+// name must both match when both are observed, and the port must be listed
+// when it is observed.
+func matchNeighbor(n *softwarecomposition.NetworkNeighbor, o *softwarecomposition.ProfileObservation) bool {
+	if o.IPAddress != "" {
+		ips := n.IPAddresses
+		if n.IPAddress != "" {
+			ips = append(slices.Clip(ips), n.IPAddress)
+		}
+		if !networkmatch.MatchIP(ips, o.IPAddress) {
+			return false
+		}
+	}
+	if o.DNSName != "" {
+		names := n.DNSNames
+		if n.DNS != "" {
+			names = append(slices.Clip(names), n.DNS)
+		}
+		if !networkmatch.MatchDNS(names, o.DNSName) {
+			return false
+		}
+	}
+	if o.Port == nil {
+		return true
+	}
+	return slices.ContainsFunc(n.Ports, func(port softwarecomposition.NetworkPort) bool {
+		return port.Port != nil && *port.Port == *o.Port && (o.Protocol == "" || port.Protocol == o.Protocol)
+	})
+}
+
+func containsAll(set, items []string) bool {
+	for _, item := range items {
+		if !slices.Contains(set, item) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,131 @@
+package evaluation
+
+import (
+	"testing"
+
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition/consts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestEvaluate_ApplicationProfile(t *testing.T) {
+	ap := &softwarecomposition.ApplicationProfile{
+		ObjectMeta: v1.ObjectMeta{Name: "nginx", Namespace: "default"},
+		Spec: softwarecomposition.ApplicationProfileSpec{
+			InitContainers: []softwarecomposition.ApplicationProfileContainer{{Name: "init"}},
+			Containers: []softwarecomposition.ApplicationProfileContainer{
+				{Name: "sidecar"},
+				{
+					Name:         "nginx",
+					Capabilities: []string{"NET_BIND_SERVICE"},
+					Syscalls:     []string{"open", "read"},
+					Execs: []softwarecomposition.ExecCalls{
+						{Path: "/bin/ls", Args: []string{"-l"}, ArgsRequired: true},
+						{Path: "/bin/sh", Args: []string{"-c", "⋯⋯"}, ArgsRequired: true},
+					},
+					Opens: []softwarecomposition.OpenCalls{
+						{Path: "/etc/nginx/⋯", Flags: []string{"O_RDONLY", "O_CLOEXEC"}},
+					},
+					Endpoints: []softwarecomposition.HTTPEndpoint{
+						{Endpoint: ":80/users/⋯", Methods: []string{"GET"}, Direction: consts.Inbound},
+					},
+				},
+			},
+		},
+	}
+	observations := []softwarecomposition.ProfileObservation{
+		{Type: softwarecomposition.ObservationTypeExec, Path: "/bin/sh", Args: []string{"-c", "echo", "hello"}},
+		{Type: softwarecomposition.ObservationTypeExec, Path: "/bin/ls", Args: []string{"-la"}},
+		{Type: softwarecomposition.ObservationTypeOpen, Path: "/etc/nginx/nginx.conf", Flags: []string{"O_RDONLY"}},
+		{Type: softwarecomposition.ObservationTypeOpen, Path: "/etc/nginx/nginx.conf", Flags: []string{"O_WRONLY"}},
+		{Type: softwarecomposition.ObservationTypeSyscall, Name: "read"},
+		{Type: softwarecomposition.ObservationTypeCapability, Name: "SYS_ADMIN"},
+		{Type: softwarecomposition.ObservationTypeHTTPEndpoint, Endpoint: ":80/users/42", Method: "GET"},
+		{Type: softwarecomposition.ObservationTypeHTTPEndpoint, Endpoint: ":80/users/42", Method: "DELETE"},
+		{Type: softwarecomposition.ObservationTypeEgress, DNSName: "example.com"},
+	}
+	status, err := Evaluate(ap, &softwarecomposition.ProfileEvaluationSpec{ContainerName: "nginx", Observations: observations})
+	require.NoError(t, err)
+	assert.Equal(t, []softwarecomposition.ProfileObservationResult{
+		{Allowed: true, MatchedField: "spec.containers[1].execs[1]", MatchedEntry: "/bin/sh -c ⋯⋯"},
+		{Reason: "no entry of the profile covers the observation"},
+		{Allowed: true, MatchedField: "spec.containers[1].opens[0]", MatchedEntry: "/etc/nginx/⋯ O_RDONLY,O_CLOEXEC"},
+		{Reason: "no entry of the profile covers the observation"},
+		{Allowed: true, MatchedField: "spec.containers[1].syscalls[1]", MatchedEntry: "read"},
+		{Reason: "no entry of the profile covers the observation"},
+		{Allowed: true, MatchedField: "spec.containers[1].endpoints[0]", MatchedEntry: ":80/users/⋯"},
+		{Reason: "no entry of the profile covers the observation"},
+		{Reason: "ApplicationProfile does not record Egress observations"},
+	}, status.Results)
+
+	// the container must be named when there are several
+	_, err = Evaluate(ap, &softwarecomposition.ProfileEvaluationSpec{Observations: observations})
+	assert.Error(t, err)
+	_, err = Evaluate(ap, &softwarecomposition.ProfileEvaluationSpec{ContainerName: "missing", Observations: observations})
+	assert.Error(t, err)
+	status, err = Evaluate(ap, &softwarecomposition.ProfileEvaluationSpec{ContainerName: "init", Observations: observations[4:5]})
+	require.NoError(t, err)
+	assert.False(t, status.Results[0].Allowed)
+}
+
+func TestEvaluate_Network(t *testing.T) {
+	neighbors := []softwarecomposition.NetworkNeighbor{
+		{Identifier: "dns", DNSNames: []string{"*.example.com."}, Ports: []softwarecomposition.NetworkPort{{Name: "TCP-443", Protocol: "TCP", Port: ptr.To[int32](443)}}},
+		{Identifier: "cidr", IPAddresses: []string{"10.0.0.0/8"}},
+		{Identifier: "legacy", IPAddress: "192.168.1.1", DNS: "legacy.local."},
+	}
+	nn := &softwarecomposition.NetworkNeighborhood{
+		Spec: softwarecomposition.NetworkNeighborhoodSpec{
+			Containers: []softwarecomposition.NetworkNeighborhoodContainer{{Name: "nginx", Egress: neighbors}},
+		},
+	}
+	observations := []softwarecomposition.ProfileObservation{
+		{Type: softwarecomposition.ObservationTypeEgress, DNSName: "api.example.com", Protocol: "TCP", Port: ptr.To[int32](443)},
+		{Type: softwarecomposition.ObservationTypeEgress, DNSName: "api.example.com", Protocol: "TCP", Port: ptr.To[int32](80)},
+		{Type: softwarecomposition.ObservationTypeEgress, IPAddress: "10.1.2.3"},
+		{Type: softwarecomposition.ObservationTypeEgress, IPAddress: "192.168.1.1", DNSName: "legacy.local"},
+		{Type: softwarecomposition.ObservationTypeIngress, IPAddress: "10.1.2.3"},
+		{Type: softwarecomposition.ObservationTypeExec, Path: "/bin/sh"},
+	}
+	// the only container is selected without a name
+	status, err := Evaluate(nn, &softwarecomposition.ProfileEvaluationSpec{Observations: observations})
+	require.NoError(t, err)
+	assert.Equal(t, []softwarecomposition.ProfileObservationResult{
+		{Allowed: true, MatchedField: "spec.containers[0].egress[0]", MatchedEntry: "dns"},
+		{Reason: "no entry of the profile covers the observation"},
+		{Allowed: true, MatchedField: "spec.containers[0].egress[1]", MatchedEntry: "cidr"},
+		{Allowed: true, MatchedField: "spec.containers[0].egress[2]", MatchedEntry: "legacy"},
+		{Reason: "no entry of the profile covers the observation"},
+		{Reason: "NetworkNeighborhood does not record Exec observations"},
+	}, status.Results)
+
+	// a container profile records both
+	cp := &softwarecomposition.ContainerProfile{
+		Spec: softwarecomposition.ContainerProfileSpec{
+			Execs:   []softwarecomposition.ExecCalls{{Path: "/bin/sh"}},
+			Ingress: neighbors,
+		},
+	}
+	status, err = Evaluate(cp, &softwarecomposition.ProfileEvaluationSpec{ContainerName: "ignored", Observations: observations[4:]})
+	require.NoError(t, err)
+	assert.Equal(t, []softwarecomposition.ProfileObservationResult{
+		{Allowed: true, MatchedField: "spec.ingress[1]", MatchedEntry: "cidr"},
+		{Allowed: true, MatchedField: "spec.execs[0]", MatchedEntry: "/bin/sh"},
+	}, status.Results)
+}
+
+func TestValidate(t *testing.T) {
+	errs := Validate(&softwarecomposition.ProfileEvaluationSpec{Observations: []softwarecomposition.ProfileObservation{
+		{Type: softwarecomposition.ObservationTypeExec, Path: "/bin/sh"},
+		{Type: softwarecomposition.ObservationTypeOpen},
+		{Type: softwarecomposition.ObservationTypeEgress, Port: ptr.To[int32](80)},
+		{Type: "Fork"},
+	}})
+	require.Len(t, errs, 3)
+	assert.Equal(t, "spec.observations[1].path", errs[0].Field)
+	assert.Equal(t, "spec.observations[2]", errs[1].Field)
+	assert.Equal(t, "spec.observations[3].type", errs[2].Field)
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync/atomic"
+	"time"
 
 	"github.com/kubescape/go-logger"
 	"github.com/kubescape/go-logger/helpers"
@@ -11,21 +13,40 @@ import (
 	"github.com/kubescape/storage/pkg/apis/softwarecomposition/networkpolicy/v2"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/sync/singleflight"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/apiserver/pkg/storage"
 )
 
 const (
-	networkNeighborhoodResource = "networkneighborhoods"
-	knownServersResource        = "knownservers"
+	networkNeighborhoodResource    = "networkneighborhoods"
+	knownServersResource           = "knownservers"
+	generatedNetworkPolicyResource = "generatednetworkpolicies"
+	generatedNetworkPolicyKind     = "GeneratedNetworkPolicy"
 )
 
+// clusterSnapshotTTL is how long the snapshot of the cluster objects ingress
+// IPs are resolved against is reused.
+var clusterSnapshotTTL = 30 * time.Second
+
+type cachedClusterSnapshot struct {
+	snapshot  *networkpolicy.ClusterSnapshot
+	expiresAt time.Time
+}
+
 // GeneratedNetworkPolicyStorage offers a storage solution for GeneratedNetworkPolicy objects, implementing custom business logic for these objects and using the underlying default storage implementation.
 type GeneratedNetworkPolicyStorage struct {
 	immutableStorage
-	realStore StorageQuerier
-	nnStore   storage.Interface
+	realStore     StorageQuerier
+	nnStore       storage.Interface
+	clusterLister networkpolicy.ClusterLister
+	snapshot      atomic.Pointer[cachedClusterSnapshot]
+	// snapshots lists the cluster objects once for the concurrent Gets
+	// finding the snapshot expired
+	snapshots singleflight.Group
 }
 
 func (s *GeneratedNetworkPolicyStorage) EnableResourceSizeEstimation(keysFunc storage.KeysFunc) error {
@@ -44,15 +65,51 @@ func (s *GeneratedNetworkPolicyStorage) CompactRevision() int64 {
 
 var _ storage.Interface = (*GeneratedNetworkPolicyStorage)(nil)
 
-func NewGeneratedNetworkPolicyStorage(realStore StorageQuerier, nnStore storage.Interface) storage.Interface {
+// NewGeneratedNetworkPolicyStorage returns the storage of GeneratedNetworkPolicy
+// objects. Learned ingress IPs are resolved against the Services,
+// EndpointSlices and Nodes clusterLister lists, a nil clusterLister leaves
+// them as IP blocks.
+func NewGeneratedNetworkPolicyStorage(realStore StorageQuerier, nnStore storage.Interface, clusterLister networkpolicy.ClusterLister) storage.Interface {
 	return &GeneratedNetworkPolicyStorage{
-		nnStore:   nnStore,
-		realStore: realStore,
+		nnStore:       nnStore,
+		realStore:     realStore,
+		clusterLister: clusterLister,
+	}
+}
+
+// ingressResolver returns the cached snapshot of the cluster objects, listing
+// them again once it expired, once for all the concurrent callers. It returns
+// nil without a cluster lister or when listing fails, so that policies are
+// still generated from the learned IPs.
+func (s *GeneratedNetworkPolicyStorage) ingressResolver(ctx context.Context) networkpolicy.IngressResolver {
+	if s.clusterLister == nil {
+		return nil
+	}
+	if cached := s.snapshot.Load(); cached != nil && time.Now().Before(cached.expiresAt) {
+		return cached.snapshot
+	}
+	snapshot, err, _ := s.snapshots.Do("", func() (any, error) {
+		if cached := s.snapshot.Load(); cached != nil && time.Now().Before(cached.expiresAt) {
+			return cached.snapshot, nil
+		}
+		// shared by the callers, it must not end with the first of them
+		snapshot, err := networkpolicy.NewClusterSnapshot(context.WithoutCancel(ctx), s.clusterLister)
+		if err != nil {
+			return nil, err
+		}
+		s.snapshot.Store(&cachedClusterSnapshot{snapshot: snapshot, expiresAt: time.Now().Add(clusterSnapshotTTL)})
+		return snapshot, nil
+	})
+	if err != nil {
+		logger.L().Ctx(ctx).Warning("failed to list cluster objects, ingress IPs are not resolved", helpers.Error(err))
+		return nil
 	}
+	return snapshot.(*networkpolicy.ClusterSnapshot)
 }
 
-func (s *GeneratedNetworkPolicyStorage) GetCurrentResourceVersion(_ context.Context) (uint64, error) {
-	return 0, nil
+// GetCurrentResourceVersion returns the revision of the storage the network neighborhoods are stored in.
+func (s *GeneratedNetworkPolicyStorage) GetCurrentResourceVersion(ctx context.Context) (uint64, error) {
+	return s.realStore.GetCurrentResourceVersion(ctx)
 }
 
 // Get generates and returns a single GeneratedNetworkPolicy object
@@ -78,7 +135,7 @@ func (s *GeneratedNetworkPolicyStorage) Get(ctx context.Context, key string, opt
 		return err
 	}
 
-	generatedNetworkPolicy, err := networkpolicy.GenerateNetworkPolicy(networkNeighborhoodObjPtr, softwarecomposition.NewKnownServersFinderImpl(knownServersListObjPtr.Items), metav1.Now())
+	generatedNetworkPolicy, err := networkpolicy.GenerateNetworkPolicyWithIngressResolver(networkNeighborhoodObjPtr, softwarecomposition.NewKnownServersFinderImpl(knownServersListObjPtr.Items), s.ingressResolver(ctx), metav1.Now())
 	if err != nil {
 		return fmt.Errorf("error generating network policy: %w", err)
 	}
@@ -105,29 +162,54 @@ func (s *GeneratedNetworkPolicyStorage) GetList(ctx context.Context, key string,
 		},
 	}
 
-	// get all network neighborhood on namespace
-	networkNeighborhoodObjListPtr := &softwarecomposition.NetworkNeighborhoodList{}
-	if err := s.realStore.GetList(ctx, replaceKeyForKind(key, networkNeighborhoodResource), opts, networkNeighborhoodObjListPtr); err != nil {
+	// page through the network neighborhoods of the namespace, a policy is only
+	// generated for the available ones so a page of them may yield fewer policies
+	nnKey := replaceKeyForKind(key, networkNeighborhoodResource)
+	nnOpts := opts
+	cont, err := reencodeContinue(key, nnKey, opts.Predicate.Continue)
+	if err != nil {
 		return err
 	}
+	nnOpts.Predicate.Continue = cont
+	for {
+		if opts.Predicate.Limit > 0 {
+			// never ask for more than the policies still missing, so that the last
+			// network neighborhood of the page is also the last one returned
+			nnOpts.Predicate.Limit = opts.Predicate.Limit - int64(len(generatedNetworkPolicyList.Items))
+		}
+		networkNeighborhoodObjListPtr := &softwarecomposition.NetworkNeighborhoodList{}
+		if err := s.realStore.GetList(ctx, nnKey, nnOpts, networkNeighborhoodObjListPtr); err != nil {
+			return err
+		}
+
+		for _, nn := range networkNeighborhoodObjListPtr.Items {
+			if !networkpolicy.IsAvailable(&nn) {
+				continue
+			}
+			generatedNetworkPolicyList.Items = append(generatedNetworkPolicyList.Items, softwarecomposition.GeneratedNetworkPolicy{
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "GeneratedNetworkPolicy",
+					APIVersion: "spdx.softwarecomposition.kubescape.io/v1beta1",
+				},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              nn.Name,
+					Namespace:         nn.Namespace,
+					Labels:            nn.Labels,
+					CreationTimestamp: metav1.Now(),
+				},
+				PoliciesRef: []softwarecomposition.PolicyRef{},
+			})
+		}
 
-	for _, nn := range networkNeighborhoodObjListPtr.Items {
-		if !networkpolicy.IsAvailable(&nn) {
-			continue
+		generatedNetworkPolicyList.ResourceVersion = networkNeighborhoodObjListPtr.ResourceVersion
+		nnOpts.Predicate.Continue = networkNeighborhoodObjListPtr.Continue
+		if nnOpts.Predicate.Continue == "" || (opts.Predicate.Limit > 0 && int64(len(generatedNetworkPolicyList.Items)) >= opts.Predicate.Limit) {
+			break
 		}
-		generatedNetworkPolicyList.Items = append(generatedNetworkPolicyList.Items, softwarecomposition.GeneratedNetworkPolicy{
-			TypeMeta: metav1.TypeMeta{
-				Kind:       "GeneratedNetworkPolicy",
-				APIVersion: "spdx.softwarecomposition.kubescape.io/v1beta1",
-			},
-			ObjectMeta: metav1.ObjectMeta{
-				Name:              nn.Name,
-				Namespace:         nn.Namespace,
-				Labels:            nn.Labels,
-				CreationTimestamp: metav1.Now(),
-			},
-			PoliciesRef: []softwarecomposition.PolicyRef{},
-		})
+	}
+	// the number of remaining policies is unknown, only the continue value is reported
+	if generatedNetworkPolicyList.Continue, err = reencodeContinue(nnKey, key, nnOpts.Predicate.Continue); err != nil {
+		return err
 	}
 
 	data, err := json.Marshal(generatedNetworkPolicyList)
@@ -143,3 +225,53 @@ func (s *GeneratedNetworkPolicyStorage) GetList(ctx context.Context, key string,
 
 	return nil
 }
+
+// Watch emits the GeneratedNetworkPolicy matching opts whenever its NetworkNeighborhood changes, and a Deleted one
+// when the NetworkNeighborhood is removed.
+func (s *GeneratedNetworkPolicyStorage) Watch(ctx context.Context, key string, opts storage.ListOptions) (watch.Interface, error) {
+	source, err := s.nnStore.Watch(ctx, "/"+softwarecomposition.GroupName+"/"+networkNeighborhoodResource, derivedWatchSourceOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	return newDerivedWatch(ctx, source, key, opts, s, derivedWatchCoalesceWindow), nil
+}
+
+func (s *GeneratedNetworkPolicyStorage) derivedKey(ev watch.Event) (string, bool) {
+	obj, err := meta.Accessor(ev.Object)
+	if err != nil {
+		return "", false
+	}
+	return K8sKeysToPath("", softwarecomposition.GroupName, generatedNetworkPolicyResource, "", obj.GetNamespace(), obj.GetName()), true
+}
+
+func (s *GeneratedNetworkPolicyStorage) resolve(ctx context.Context, key string) (runtime.Object, error) {
+	obj := &softwarecomposition.GeneratedNetworkPolicy{}
+	return obj, s.Get(ctx, key, storage.GetOptions{}, obj)
+}
+
+// list returns the policies as listed, without generating them.
+func (s *GeneratedNetworkPolicyStorage) list(ctx context.Context, key string) (map[string]runtime.Object, error) {
+	list := &softwarecomposition.GeneratedNetworkPolicyList{}
+	if err := s.GetList(ctx, key, storage.ListOptions{Recursive: true}, list); err != nil {
+		return nil, err
+	}
+	objs := make(map[string]runtime.Object, len(list.Items))
+	for i := range list.Items {
+		objs[K8sKeysToPath("", softwarecomposition.GroupName, generatedNetworkPolicyResource, "", list.Items[i].Namespace, list.Items[i].Name)] = &list.Items[i]
+	}
+	return objs, nil
+}
+
+func (s *GeneratedNetworkPolicyStorage) tombstone(key string) runtime.Object {
+	_, _, _, _, namespace, name := K8sPathToKeys(key)
+	return &softwarecomposition.GeneratedNetworkPolicy{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       generatedNetworkPolicyKind,
+			APIVersion: StorageV1Beta1ApiVersion,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+	}
+}
@@ -2,6 +2,8 @@ package file
 
 import (
 	"context"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -13,6 +15,8 @@ import (
 	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apiserver/pkg/storage"
@@ -146,7 +150,7 @@ func TestGeneratedNetworkPolicyStorage_Get(t *testing.T) {
 			sch := scheme.Scheme
 			require.NoError(t, softwarecomposition.AddToScheme(sch))
 			realStorage := NewStorageImpl(afero.NewMemMapFs(), "/", pool, nil, sch)
-			generatedNetworkPolicyStorage := NewGeneratedNetworkPolicyStorage(realStorage, realStorage)
+			generatedNetworkPolicyStorage := NewGeneratedNetworkPolicyStorage(realStorage, realStorage, nil)
 			ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
 			defer cancel()
 			if tt.create {
@@ -190,7 +194,7 @@ func TestGeneratedNetworkPolicyStorage_Get(t *testing.T) {
 
 func TestGeneratedNetworkPolicyStorage_Create(t *testing.T) {
 	storageImpl := NewStorageImpl(afero.NewMemMapFs(), "", nil, nil, nil)
-	generatedNetworkPolicyStorage := NewGeneratedNetworkPolicyStorage(storageImpl, storageImpl)
+	generatedNetworkPolicyStorage := NewGeneratedNetworkPolicyStorage(storageImpl, storageImpl, nil)
 
 	err := generatedNetworkPolicyStorage.Create(context.TODO(), "", nil, nil, 0)
 
@@ -201,7 +205,7 @@ func TestGeneratedNetworkPolicyStorage_Create(t *testing.T) {
 
 func TestGeneratedNetworkPolicyStorage_Delete(t *testing.T) {
 	storageImpl := NewStorageImpl(afero.NewMemMapFs(), "", nil, nil, nil)
-	generatedNetworkPolicyStorage := NewGeneratedNetworkPolicyStorage(storageImpl, storageImpl)
+	generatedNetworkPolicyStorage := NewGeneratedNetworkPolicyStorage(storageImpl, storageImpl, nil)
 
 	err := generatedNetworkPolicyStorage.Delete(context.TODO(), "", nil, nil, nil, nil, storage.DeleteOptions{})
 
@@ -211,16 +215,20 @@ func TestGeneratedNetworkPolicyStorage_Delete(t *testing.T) {
 }
 
 func TestGeneratedNetworkPolicyStorage_Watch(t *testing.T) {
-	storageImpl := NewStorageImpl(afero.NewMemMapFs(), "", nil, nil, nil)
-	generatedNetworkPolicyStorage := NewGeneratedNetworkPolicyStorage(storageImpl, storageImpl)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pool := NewTestPool(t.TempDir())
+	defer func() { _ = pool.Close() }()
+	storageImpl := NewStorageImpl(afero.NewMemMapFs(), "", pool, nil, nil)
+	generatedNetworkPolicyStorage := NewGeneratedNetworkPolicyStorage(storageImpl, storageImpl, nil)
 
-	_, err := generatedNetworkPolicyStorage.Watch(context.TODO(), "", storage.ListOptions{})
+	_, err := generatedNetworkPolicyStorage.Watch(ctx, "/spdx.softwarecomposition.kubescape.io/generatednetworkpolicies", storage.ListOptions{})
 	assert.NoError(t, err)
 }
 
 func TestGeneratedNetworkPolicyStorage_GuaranteedUpdate(t *testing.T) {
 	storageImpl := NewStorageImpl(afero.NewMemMapFs(), "", nil, nil, nil)
-	generatedNetworkPolicyStorage := NewGeneratedNetworkPolicyStorage(storageImpl, storageImpl)
+	generatedNetworkPolicyStorage := NewGeneratedNetworkPolicyStorage(storageImpl, storageImpl, nil)
 
 	err := generatedNetworkPolicyStorage.GuaranteedUpdate(context.TODO(), "", nil, false, nil, nil, nil)
 
@@ -228,3 +236,44 @@ func TestGeneratedNetworkPolicyStorage_GuaranteedUpdate(t *testing.T) {
 
 	assert.EqualError(t, err, expectedError.Error())
 }
+
+// slowClusterLister counts the times the Services are listed, each list
+// taking a while.
+type slowClusterLister struct {
+	lists atomic.Int32
+}
+
+func (l *slowClusterLister) ListServices(context.Context) ([]corev1.Service, error) {
+	l.lists.Add(1)
+	time.Sleep(50 * time.Millisecond)
+	return nil, nil
+}
+
+func (l *slowClusterLister) ListEndpointSlices(context.Context) ([]discoveryv1.EndpointSlice, error) {
+	return nil, nil
+}
+
+func (l *slowClusterLister) ListNodes(context.Context) ([]corev1.Node, error) {
+	return nil, nil
+}
+
+func TestGeneratedNetworkPolicyStorage_IngressResolver(t *testing.T) {
+	lister := &slowClusterLister{}
+	s := NewGeneratedNetworkPolicyStorage(nil, nil, lister).(*GeneratedNetworkPolicyStorage)
+
+	// the concurrent Gets of an expired snapshot list the cluster once
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NotNil(t, s.ingressResolver(context.Background()))
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), lister.lists.Load())
+
+	// and the snapshot is reused until it expires
+	assert.NotNil(t, s.ingressResolver(context.Background()))
+	assert.Equal(t, int32(1), lister.lists.Load())
+}
@@ -0,0 +1,151 @@
+// Package guardrail compiles the CEL rules of Guardrail objects and evaluates
+// profiles against them.
+//
+// Every rule sees two variables:
+//   - object: the profile, in its v1beta1 JSON form (as served by the API)
+//   - kind:   the profile kind, e.g. "ApplicationProfile"
+//
+// namespace is a reserved word in CEL, rules read object.metadata.namespace.
+//
+// A rule must evaluate to a bool, true meaning the profile complies.
+package guardrail
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/google/cel-go/cel"
+	"github.com/kubescape/go-logger"
+	"github.com/kubescape/go-logger/helpers"
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+)
+
+// ViolationsAnnotation lists, as JSON, the guardrail rules a profile violates.
+// It is absent when the profile complies with every guardrail.
+const ViolationsAnnotation = "kubescape.io/guardrail-violations"
+
+// costLimit bounds the runtime cost of a single rule, so that a careless
+// expression over a large profile cannot stall a write.
+const costLimit = 1_000_000
+
+// Violation is a rule a profile does not comply with.
+type Violation struct {
+	Guardrail string `json:"guardrail"`
+	Rule      string `json:"rule"`
+	Message   string `json:"message,omitempty"`
+}
+
+type rule struct {
+	name    string
+	message string
+	program cel.Program
+}
+
+type compiled struct {
+	name       string
+	matchKinds []string
+	rules      []rule
+}
+
+// Set is a compiled list of guardrails, safe for concurrent use.
+// The zero value and nil are empty sets.
+type Set struct {
+	guardrails []compiled
+}
+
+var env *cel.Env
+
+func init() {
+	var err error
+	env, err = cel.NewEnv(
+		cel.Variable("object", cel.DynType),
+		cel.Variable("kind", cel.StringType),
+	)
+	if err != nil {
+		panic(fmt.Errorf("guardrail CEL environment: %w", err))
+	}
+}
+
+// CompileExpression checks that expression is a valid rule.
+func CompileExpression(expression string) (cel.Program, error) {
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+		return nil, fmt.Errorf("expression must evaluate to a bool, not %s", ast.OutputType())
+	}
+	return env.Program(ast, cel.CostLimit(costLimit))
+}
+
+// Compile compiles the rules of guardrails, skipping (and logging) the ones
+// which do not compile. Those are rejected by the API validation, so they can
+// only come from objects stored before a rule became invalid.
+func Compile(guardrails []softwarecomposition.Guardrail) *Set {
+	set := &Set{}
+	for _, g := range guardrails {
+		c := compiled{
+			name:       g.Name,
+			matchKinds: g.Spec.MatchKinds,
+		}
+		for _, r := range g.Spec.Rules {
+			program, err := CompileExpression(r.Expression)
+			if err != nil {
+				logger.L().Warning("guardrail - skipping invalid rule", helpers.Error(err),
+					helpers.String("guardrail", g.Name), helpers.String("rule", r.Name))
+				continue
+			}
+			c.rules = append(c.rules, rule{name: r.Name, message: r.Message, program: program})
+		}
+		set.guardrails = append(set.guardrails, c)
+	}
+	sort.Slice(set.guardrails, func(i, j int) bool {
+		return set.guardrails[i].name < set.guardrails[j].name
+	})
+	return set
+}
+
+// Empty returns true if the set has no rule to evaluate.
+func (s *Set) Empty() bool {
+	if s == nil {
+		return true
+	}
+	for _, g := range s.guardrails {
+		if len(g.rules) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Evaluate returns the violations of a profile, ordered by guardrail and rule.
+// object is the unstructured v1beta1 form of the profile. A rule failing to
+// evaluate (e.g. accessing a missing field) is logged and not reported.
+func (s *Set) Evaluate(kind string, object map[string]any) []Violation {
+	if s == nil {
+		return nil
+	}
+	vars := map[string]any{
+		"object": object,
+		"kind":   kind,
+	}
+	var violations []Violation
+	for _, g := range s.guardrails {
+		if len(g.matchKinds) > 0 && !slices.Contains(g.matchKinds, kind) {
+			continue
+		}
+		for _, r := range g.rules {
+			out, _, err := r.program.Eval(vars)
+			if err != nil {
+				logger.L().Debug("guardrail - rule evaluation failed", helpers.Error(err),
+					helpers.String("guardrail", g.name), helpers.String("rule", r.name))
+				continue
+			}
+			if ok, isBool := out.Value().(bool); isBool && !ok {
+				violations = append(violations, Violation{Guardrail: g.name, Rule: r.name, Message: r.message})
+			}
+		}
+	}
+	return violations
+}
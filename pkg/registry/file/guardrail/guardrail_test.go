@@ -0,0 +1,93 @@
+package guardrail
+
+import (
+	"testing"
+
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCompileExpression(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		wantErr    bool
+	}{
+		{
+			name:       "bool expression",
+			expression: "object.spec.containers.all(c, c.execs.all(e, e.path != '/bin/sh'))",
+		},
+		{
+			name:       "syntax error",
+			expression: "object.spec.containers.all(c,",
+			wantErr:    true,
+		},
+		{
+			name:       "not a bool",
+			expression: "kind + 'suffix'",
+			wantErr:    true,
+		},
+		{
+			name:       "undeclared variable",
+			expression: "profile.spec == null",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CompileExpression(tt.expression)
+			assert.Equal(t, tt.wantErr, err != nil, "error: %v", err)
+		})
+	}
+}
+
+func TestSet_Evaluate(t *testing.T) {
+	set := Compile([]softwarecomposition.Guardrail{
+		{
+			ObjectMeta: v1.ObjectMeta{Name: "no-shell"},
+			Spec: softwarecomposition.GuardrailSpec{
+				MatchKinds: []string{"ApplicationProfile"},
+				Rules: []softwarecomposition.GuardrailRule{
+					{Name: "sh", Expression: "object.spec.containers.all(c, c.execs.all(e, e.path != '/bin/sh'))", Message: "no shell"},
+					{Name: "broken", Expression: "object.spec.missing.size() == 0"},
+					{Name: "invalid", Expression: "object.spec.containers.all(c,"},
+				},
+			},
+		},
+		{
+			ObjectMeta: v1.ObjectMeta{Name: "egress"},
+			Spec: softwarecomposition.GuardrailSpec{
+				Rules: []softwarecomposition.GuardrailRule{
+					{Name: "any", Expression: "object.metadata.namespace == 'kube-system' || object.spec.containers.all(c, !has(c.egress) || c.egress.all(e, e.ipAddress != '0.0.0.0/0'))"},
+				},
+			},
+		},
+	})
+	assert.False(t, set.Empty())
+
+	shell := map[string]any{"metadata": map[string]any{"namespace": "default"}, "spec": map[string]any{"containers": []any{
+		map[string]any{"execs": []any{map[string]any{"path": "/bin/sh"}}},
+	}}}
+	egress := func(namespace string) map[string]any {
+		return map[string]any{"metadata": map[string]any{"namespace": namespace}, "spec": map[string]any{"containers": []any{
+			map[string]any{"egress": []any{map[string]any{"ipAddress": "0.0.0.0/0"}}},
+		}}}
+	}
+
+	// rules are ordered by guardrail name, evaluation errors are not violations
+	assert.Equal(t, []Violation{{Guardrail: "no-shell", Rule: "sh", Message: "no shell"}},
+		set.Evaluate("ApplicationProfile", shell))
+	// matchKinds restricts the guardrail
+	assert.Empty(t, set.Evaluate("NetworkNeighborhood", shell))
+	assert.Equal(t, []Violation{{Guardrail: "egress", Rule: "any"}},
+		set.Evaluate("NetworkNeighborhood", egress("default")))
+	assert.Empty(t, set.Evaluate("NetworkNeighborhood", egress("kube-system")))
+}
+
+func TestSet_Empty(t *testing.T) {
+	var set *Set
+	assert.True(t, set.Empty())
+	assert.Nil(t, set.Evaluate("ApplicationProfile", nil))
+	assert.True(t, Compile(nil).Empty())
+}
@@ -0,0 +1,135 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/kubescape/go-logger"
+	"github.com/kubescape/go-logger/helpers"
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/storage"
+)
+
+// GuardrailStorage implements the storage of Guardrail objects.
+//
+// The status of a guardrail lists the profiles violating it, read from the index
+// maintained on every profile write. Every change to a guardrail triggers a
+// background re-evaluation of all the stored profiles, so that the violations
+// reflect the new rules without waiting for the profiles to be updated.
+type GuardrailStorage struct {
+	StorageQuerier
+	guardrails    *GuardrailsCache
+	profileStores map[string]StorageQuerier
+	pending       chan struct{}
+	startWorker   sync.Once
+}
+
+var _ storage.Interface = (*GuardrailStorage)(nil)
+
+// NewGuardrailStorage returns the storage of Guardrail objects, backed by realStore.
+// profileStores maps the evaluated resources to the storage saving them.
+func NewGuardrailStorage(realStore StorageQuerier, guardrails *GuardrailsCache, profileStores map[string]StorageQuerier) *GuardrailStorage {
+	return &GuardrailStorage{
+		StorageQuerier: realStore,
+		guardrails:     guardrails,
+		profileStores:  profileStores,
+		pending:        make(chan struct{}, 1),
+	}
+}
+
+func (s *GuardrailStorage) Create(ctx context.Context, key string, obj, out runtime.Object, ttl uint64) error {
+	if err := s.StorageQuerier.Create(ctx, key, obj, out, ttl); err != nil {
+		return err
+	}
+	s.Reevaluate()
+	return nil
+}
+
+func (s *GuardrailStorage) Delete(ctx context.Context, key string, out runtime.Object, preconditions *storage.Preconditions, validateDeletion storage.ValidateObjectFunc, cachedExistingObject runtime.Object, opts storage.DeleteOptions) error {
+	if err := s.StorageQuerier.Delete(ctx, key, out, preconditions, validateDeletion, cachedExistingObject, opts); err != nil {
+		return err
+	}
+	s.Reevaluate()
+	return nil
+}
+
+func (s *GuardrailStorage) GuaranteedUpdate(ctx context.Context, key string, destination runtime.Object, ignoreNotFound bool, preconditions *storage.Preconditions, tryUpdate storage.UpdateFunc, cachedExistingObject runtime.Object) error {
+	if err := s.StorageQuerier.GuaranteedUpdate(ctx, key, destination, ignoreNotFound, preconditions, tryUpdate, cachedExistingObject); err != nil {
+		return err
+	}
+	s.Reevaluate()
+	return nil
+}
+
+func (s *GuardrailStorage) Get(ctx context.Context, key string, opts storage.GetOptions, objPtr runtime.Object) error {
+	if err := s.StorageQuerier.Get(ctx, key, opts, objPtr); err != nil {
+		return err
+	}
+	g, ok := objPtr.(*softwarecomposition.Guardrail)
+	if !ok {
+		return fmt.Errorf("object is not a Guardrail")
+	}
+	if g.Name == "" {
+		// IgnoreNotFound returned the zero value
+		return nil
+	}
+	return s.fillStatus(ctx, g)
+}
+
+func (s *GuardrailStorage) GetList(ctx context.Context, key string, opts storage.ListOptions, listObj runtime.Object) error {
+	if err := s.StorageQuerier.GetList(ctx, key, opts, listObj); err != nil {
+		return err
+	}
+	list, ok := listObj.(*softwarecomposition.GuardrailList)
+	if !ok {
+		return fmt.Errorf("object is not a GuardrailList")
+	}
+	for i := range list.Items {
+		if err := s.fillStatus(ctx, &list.Items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *GuardrailStorage) fillStatus(ctx context.Context, g *softwarecomposition.Guardrail) error {
+	violations, err := s.ListGuardrailViolations(ctx, g.Name)
+	if err != nil {
+		return err
+	}
+	g.Status.Violations = violations
+	return nil
+}
+
+// Reevaluate schedules a refresh of the guardrails and a re-evaluation of all
+// the stored profiles against them. Requests made while one is pending are
+// coalesced.
+func (s *GuardrailStorage) Reevaluate() {
+	s.startWorker.Do(func() {
+		go s.reevaluateWorker()
+	})
+	select {
+	case s.pending <- struct{}{}:
+	default:
+	}
+}
+
+func (s *GuardrailStorage) reevaluateWorker() {
+	ctx := context.Background()
+	for range s.pending {
+		if err := s.guardrails.Refresh(ctx); err != nil {
+			logger.L().Error("GuardrailStorage - refresh guardrails failed", helpers.Error(err))
+			continue
+		}
+		for _, resource := range slices.Sorted(maps.Keys(s.profileStores)) {
+			if err := s.profileStores[resource].ReevaluateGuardrails(ctx, resource, s.guardrails.Get); err != nil {
+				logger.L().Error("GuardrailStorage - reevaluate guardrails failed", helpers.Error(err), helpers.String("resource", resource))
+			}
+		}
+		logger.L().Debug("GuardrailStorage - guardrails reevaluated")
+	}
+}
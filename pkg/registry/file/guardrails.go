@@ -0,0 +1,362 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kubescape/go-logger"
+	"github.com/kubescape/go-logger/helpers"
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition/v1beta1"
+	"github.com/kubescape/storage/pkg/registry/file/guardrail"
+	"github.com/spf13/afero"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/storage"
+	"zombiezen.com/go/sqlite"
+	"zombiezen.com/go/sqlite/sqlitex"
+)
+
+// GuardrailViolationsAnnotation lists, as JSON, the guardrail rules a profile violates.
+const GuardrailViolationsAnnotation = guardrail.ViolationsAnnotation
+
+const guardrailsResource = "guardrails"
+
+// guardrailKinds maps the resources evaluated against guardrails to their kind.
+var guardrailKinds = map[string]string{
+	"applicationprofiles":       "ApplicationProfile",
+	ContainerProfileKindPlural:  "ContainerProfile",
+	networkNeighborhoodResource: "NetworkNeighborhood",
+}
+
+// guardrailsTTL is how often the cached guardrails are re-read from storage.
+// Writes through GuardrailStorage refresh the cache immediately, the TTL only
+// covers other writers.
+var guardrailsTTL = 10 * time.Second
+
+// GuardrailsProvider returns the compiled guardrails profiles are evaluated
+// against, nil when they are not known yet.
+type GuardrailsProvider func() *guardrail.Set
+
+// GuardrailsCache serves the compiled Guardrail objects of a storage. Get only
+// reads the cache, so that it can be called while a storage connection is
+// held, the cache being refreshed by Run and Refresh.
+type GuardrailsCache struct {
+	set atomic.Pointer[guardrail.Set]
+	mu  sync.Mutex // serializes refreshes only; reads are lock-free
+	s   storage.Interface
+}
+
+func NewGuardrailsCache(s storage.Interface) *GuardrailsCache {
+	return &GuardrailsCache{s: s}
+}
+
+// Get returns the guardrails last read from storage, nil if they were never read.
+func (c *GuardrailsCache) Get() *guardrail.Set {
+	return c.set.Load()
+}
+
+// Run refreshes the cache every guardrailsTTL until ctx is done.
+func (c *GuardrailsCache) Run(ctx context.Context) {
+	ticker := time.NewTicker(guardrailsTTL)
+	defer ticker.Stop()
+	for {
+		if err := c.Refresh(ctx); err != nil {
+			logger.L().Warning("GuardrailsCache - refresh failed, keeping the previous guardrails", helpers.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh re-reads the guardrails from storage. The previous guardrails are
+// kept when they cannot be read, so that a transient failure does not clear
+// the violations of the profiles written meanwhile.
+func (c *GuardrailsCache) Refresh(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	guardrails, err := c.load(ctx)
+	if err != nil {
+		return err
+	}
+	c.set.Store(guardrail.Compile(guardrails))
+	return nil
+}
+
+func (c *GuardrailsCache) load(ctx context.Context) ([]softwarecomposition.Guardrail, error) {
+	if c.s == nil {
+		return nil, nil
+	}
+	// the metadata list gives the names, each guardrail is then read with its spec
+	list := &softwarecomposition.GuardrailList{}
+	if err := c.s.GetList(ctx, "/"+softwarecomposition.GroupName+"/"+guardrailsResource, storage.ListOptions{}, list); err != nil {
+		return nil, fmt.Errorf("list guardrails: %w", err)
+	}
+	guardrails := make([]softwarecomposition.Guardrail, 0, len(list.Items))
+	for _, item := range list.Items {
+		g := softwarecomposition.Guardrail{}
+		key := K8sClusterScopedKeysToPath("", softwarecomposition.GroupName, guardrailsResource, item.Name)
+		if err := c.s.Get(ctx, key, storage.GetOptions{}, &g); err != nil {
+			if storage.IsNotFound(err) {
+				// deleted since listed
+				continue
+			}
+			return nil, fmt.Errorf("get guardrail %s: %w", item.Name, err)
+		}
+		guardrails = append(guardrails, g)
+	}
+	return guardrails, nil
+}
+
+// applyGuardrails evaluates a profile against set and records the violations
+// in the GuardrailViolationsAnnotation, removing it when the profile complies.
+// The recorded violations are left as they are while set is unknown.
+func applyGuardrails(set *guardrail.Set, obj runtime.Object) error {
+	meta, ok := obj.(metav1.Object)
+	if !ok {
+		return fmt.Errorf("given object has no metadata")
+	}
+	if set == nil {
+		return nil
+	}
+	var violations []guardrail.Violation
+	if !set.Empty() {
+		kind, object, err := guardrailObject(obj)
+		if err != nil {
+			return err
+		}
+		violations = set.Evaluate(kind, object)
+	}
+	annotations := meta.GetAnnotations()
+	if len(violations) == 0 {
+		delete(annotations, GuardrailViolationsAnnotation)
+		return nil
+	}
+	b, err := json.Marshal(violations)
+	if err != nil {
+		return fmt.Errorf("marshal guardrail violations: %w", err)
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[GuardrailViolationsAnnotation] = string(b)
+	meta.SetAnnotations(annotations)
+	return nil
+}
+
+// guardrailObject returns the kind of a profile and its unstructured v1beta1 form,
+// which is what rules are written against.
+func guardrailObject(obj runtime.Object) (string, map[string]any, error) {
+	var (
+		kind string
+		out  runtime.Object
+		err  error
+	)
+	switch in := obj.(type) {
+	case *softwarecomposition.ApplicationProfile:
+		converted := &v1beta1.ApplicationProfile{}
+		kind, out, err = "ApplicationProfile", converted, v1beta1.Convert_softwarecomposition_ApplicationProfile_To_v1beta1_ApplicationProfile(in, converted, nil)
+	case *softwarecomposition.ContainerProfile:
+		converted := &v1beta1.ContainerProfile{}
+		kind, out, err = "ContainerProfile", converted, v1beta1.Convert_softwarecomposition_ContainerProfile_To_v1beta1_ContainerProfile(in, converted, nil)
+	case *softwarecomposition.NetworkNeighborhood:
+		converted := &v1beta1.NetworkNeighborhood{}
+		kind, out, err = "NetworkNeighborhood", converted, v1beta1.Convert_softwarecomposition_NetworkNeighborhood_To_v1beta1_NetworkNeighborhood(in, converted, nil)
+	default:
+		return "", nil, fmt.Errorf("guardrails do not apply to %T", obj)
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("convert to v1beta1: %w", err)
+	}
+	object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(out)
+	if err != nil {
+		return "", nil, fmt.Errorf("convert to unstructured: %w", err)
+	}
+	return kind, object, nil
+}
+
+// writeGuardrailViolations indexes the violations recorded on the object at path,
+// so that they can be listed per guardrail. It is a no-op for kinds which are not
+// evaluated against guardrails.
+func writeGuardrailViolations(conn *sqlite.Conn, path string, obj runtime.Object) (err error) {
+	_, _, resource, _, namespace, name := K8sPathToKeys(path)
+	kind, ok := guardrailKinds[resource]
+	if !ok {
+		return nil
+	}
+	var violations []guardrail.Violation
+	if anno := obj.(metav1.Object).GetAnnotations()[GuardrailViolationsAnnotation]; anno != "" {
+		if err := json.Unmarshal([]byte(anno), &violations); err != nil {
+			return fmt.Errorf("unmarshal guardrail violations: %w", err)
+		}
+	}
+	defer sqlitex.Save(conn)(&err)
+	if err := deleteGuardrailViolations(conn, path); err != nil {
+		return err
+	}
+	for _, v := range violations {
+		err := sqlitex.Execute(conn,
+			`INSERT OR REPLACE INTO guardrail_violations
+					(kind, namespace, name, guardrail, rule, message) VALUES (?, ?, ?, ?, ?, ?)`,
+			&sqlitex.ExecOptions{
+				Args: []any{kind, namespace, name, v.Guardrail, v.Rule, v.Message},
+			})
+		if err != nil {
+			return fmt.Errorf("write guardrail violation: %w", err)
+		}
+	}
+	return nil
+}
+
+// deleteGuardrailViolations removes the indexed violations of the object at path.
+func deleteGuardrailViolations(conn *sqlite.Conn, path string) error {
+	_, _, resource, _, namespace, name := K8sPathToKeys(path)
+	kind, ok := guardrailKinds[resource]
+	if !ok {
+		return nil
+	}
+	err := sqlitex.Execute(conn,
+		`DELETE FROM guardrail_violations
+				WHERE kind = ?
+				  AND namespace = ?
+				  AND name = ?`,
+		&sqlitex.ExecOptions{
+			Args: []any{kind, namespace, name},
+		})
+	if err != nil {
+		return fmt.Errorf("delete guardrail violations: %w", err)
+	}
+	return nil
+}
+
+func listGuardrailViolations(conn *sqlite.Conn, guardrailName string) ([]softwarecomposition.GuardrailViolation, error) {
+	var violations []softwarecomposition.GuardrailViolation
+	err := sqlitex.Execute(conn,
+		`SELECT kind, namespace, name, rule, message FROM guardrail_violations
+				WHERE guardrail = ?
+				ORDER BY kind, namespace, name, rule`,
+		&sqlitex.ExecOptions{
+			Args: []any{guardrailName},
+			ResultFunc: func(stmt *sqlite.Stmt) error {
+				violations = append(violations, softwarecomposition.GuardrailViolation{
+					Kind:      stmt.ColumnText(0),
+					Namespace: stmt.ColumnText(1),
+					Name:      stmt.ColumnText(2),
+					Rule:      stmt.ColumnText(3),
+					Message:   stmt.ColumnText(4),
+				})
+				return nil
+			},
+		})
+	if err != nil {
+		return nil, fmt.Errorf("list guardrail violations: %w", err)
+	}
+	return violations, nil
+}
+
+// ListGuardrailViolations returns the profiles currently violating a guardrail.
+func (s *StorageImpl) ListGuardrailViolations(ctx context.Context, guardrailName string) ([]softwarecomposition.GuardrailViolation, error) {
+	poolCtx, cancel := poolContext()
+	defer cancel()
+	conn, err := s.pool.Take(poolCtx)
+	if err != nil {
+		return nil, newContentionTimeoutError("list", guardrailName, err)
+	}
+	defer s.pool.Put(conn)
+	return listGuardrailViolations(conn, guardrailName)
+}
+
+// ReevaluateGuardrails evaluates every stored profile of resource against the
+// guardrails of provider and saves the profiles whose violations changed. This is
+// the on-demand counterpart of the evaluation the processors run on every write,
+// it must be called on the storage owning resource so that it shares its key locks.
+func (s *StorageImpl) ReevaluateGuardrails(ctx context.Context, resource string, provider GuardrailsProvider) error {
+	kind, ok := guardrailKinds[resource]
+	if !ok {
+		return fmt.Errorf("guardrails do not apply to %s", resource)
+	}
+	var keys []string
+	root := filepath.Join(s.root, softwarecomposition.GroupName, resource)
+	err := afero.Walk(s.appFs, root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && IsPayloadFile(path) {
+			keys = append(keys, s.keyFromPath(path))
+		}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("walk %s: %w", resource, err)
+	}
+	set := provider()
+	if set == nil {
+		return fmt.Errorf("guardrails are not loaded")
+	}
+	for _, key := range keys {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := s.reevaluateGuardrails(ctx, key, kind, set); err != nil {
+			logger.L().Warning("ReevaluateGuardrails - evaluation failed", helpers.Error(err), helpers.String("key", key))
+		}
+	}
+	return nil
+}
+
+// reevaluateGuardrails updates the violations of the profile at key. It cannot go
+// through GuaranteedUpdate, which runs the processor on both the original and the
+// updated object and would therefore see no change.
+func (s *StorageImpl) reevaluateGuardrails(ctx context.Context, key, kind string, set *guardrail.Set) error {
+	lockCtx, lockCancel := context.WithTimeout(ctx, lockTimeout)
+	defer lockCancel()
+	if err := s.locks.Lock(lockCtx, key); err != nil {
+		return newContentionTimeoutError("update", key, err)
+	}
+	defer s.locks.Unlock(key)
+	poolCtx, cancel := poolContext()
+	defer cancel()
+	conn, err := s.pool.Take(poolCtx)
+	if err != nil {
+		return newContentionTimeoutError("update", key, err)
+	}
+	defer s.pool.Put(conn)
+	obj, err := s.scheme.New(softwarecomposition.SchemeGroupVersion.WithKind(kind))
+	if err != nil {
+		return fmt.Errorf("new %s: %w", kind, err)
+	}
+	if err := s.get(ctx, conn, key, storage.GetOptions{}, obj, hasWriteLock); err != nil {
+		if storage.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	before := obj.(metav1.Object).GetAnnotations()[GuardrailViolationsAnnotation]
+	if before == "" && set.Empty() {
+		return nil
+	}
+	if err := applyGuardrails(set, obj); err != nil {
+		return err
+	}
+	if obj.(metav1.Object).GetAnnotations()[GuardrailViolationsAnnotation] == before {
+		return nil
+	}
+	metaOut, err := s.scheme.New(softwarecomposition.SchemeGroupVersion.WithKind(kind))
+	if err != nil {
+		return fmt.Errorf("new %s: %w", kind, err)
+	}
+	if err := s.saveObject(conn, key, obj, metaOut, ""); err != nil {
+		return fmt.Errorf("save object: %w", err)
+	}
+	s.watchDispatcher.Modified(key, metaOut, obj)
+	return nil
+}
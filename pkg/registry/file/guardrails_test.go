@@ -0,0 +1,135 @@
+package file
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"github.com/kubescape/storage/pkg/config"
+	"github.com/kubescape/storage/pkg/generated/clientset/versioned/scheme"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/storage"
+)
+
+func newOpenEgressNetworkNeighborhood(namespace, name string) *softwarecomposition.NetworkNeighborhood {
+	return &softwarecomposition.NetworkNeighborhood{
+		ObjectMeta: v1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: softwarecomposition.NetworkNeighborhoodSpec{
+			Containers: []softwarecomposition.NetworkNeighborhoodContainer{{
+				Name:   "nginx",
+				Egress: []softwarecomposition.NetworkNeighbor{{Identifier: "any", IPAddress: "0.0.0.0/0"}},
+			}},
+		},
+	}
+}
+
+func TestGuardrails(t *testing.T) {
+	pool := NewTestPool(t.TempDir())
+	require.NotNil(t, pool)
+	defer func() { _ = pool.Close() }()
+	sch := scheme.Scheme
+	require.NoError(t, softwarecomposition.AddToScheme(sch))
+	fs := afero.NewMemMapFs()
+	ctx := context.TODO()
+
+	realStore := NewStorageImpl(fs, DefaultStorageRoot, pool, nil, sch)
+	cache := NewGuardrailsCache(realStore)
+	processor := NewNetworkNeighborhoodProcessor(config.Config{MaxNetworkNeighborhoodSize: 100})
+	processor.SetGuardrails(cache.Get)
+	nnStore := NewStorageImplWithCollector(fs, DefaultStorageRoot, pool, nil, sch, processor)
+	guardrails := NewGuardrailStorage(realStore, cache, map[string]StorageQuerier{networkNeighborhoodResource: nnStore})
+
+	guardrailKey := K8sClusterScopedKeysToPath("", softwarecomposition.GroupName, guardrailsResource, "no-open-egress")
+	require.NoError(t, realStore.Create(ctx, guardrailKey, &softwarecomposition.Guardrail{
+		ObjectMeta: v1.ObjectMeta{Name: "no-open-egress"},
+		Spec: softwarecomposition.GuardrailSpec{
+			MatchKinds: []string{"NetworkNeighborhood"},
+			Rules: []softwarecomposition.GuardrailRule{{
+				Name:       "any",
+				Expression: "object.metadata.namespace == 'kube-system' || object.spec.containers.all(c, c.egress.all(e, e.ipAddress != '0.0.0.0/0'))",
+				Message:    "no egress to 0.0.0.0/0",
+			}},
+		},
+	}, nil, 0))
+	// unknown guardrails leave the violations as they are
+	assert.Nil(t, cache.Get())
+	require.NoError(t, cache.Refresh(ctx))
+
+	// writes are evaluated by the processor
+	keyDefault := "/spdx.softwarecomposition.kubescape.io/networkneighborhoods/default/nginx"
+	keySystem := "/spdx.softwarecomposition.kubescape.io/networkneighborhoods/kube-system/nginx"
+	require.NoError(t, nnStore.Create(ctx, keyDefault, newOpenEgressNetworkNeighborhood("default", "nginx"), nil, 0))
+	require.NoError(t, nnStore.Create(ctx, keySystem, newOpenEgressNetworkNeighborhood("kube-system", "nginx"), nil, 0))
+	nn := &softwarecomposition.NetworkNeighborhood{}
+	require.NoError(t, nnStore.Get(ctx, keyDefault, storage.GetOptions{}, nn))
+	assert.JSONEq(t, `[{"guardrail":"no-open-egress","rule":"any","message":"no egress to 0.0.0.0/0"}]`, nn.Annotations[GuardrailViolationsAnnotation])
+	nn = &softwarecomposition.NetworkNeighborhood{}
+	require.NoError(t, nnStore.Get(ctx, keySystem, storage.GetOptions{}, nn))
+	assert.NotContains(t, nn.Annotations, GuardrailViolationsAnnotation)
+
+	// the guardrail status lists the violations
+	g := &softwarecomposition.Guardrail{}
+	require.NoError(t, guardrails.Get(ctx, guardrailKey, storage.GetOptions{}, g))
+	assert.Equal(t, []softwarecomposition.GuardrailViolation{{
+		Kind:      "NetworkNeighborhood",
+		Namespace: "default",
+		Name:      "nginx",
+		Rule:      "any",
+		Message:   "no egress to 0.0.0.0/0",
+	}}, g.Status.Violations)
+
+	// removing the guardrail and re-evaluating clears the violations
+	require.NoError(t, realStore.Delete(ctx, guardrailKey, &softwarecomposition.Guardrail{}, nil, nil, nil, storage.DeleteOptions{}))
+	require.NoError(t, cache.Refresh(ctx))
+	require.NoError(t, nnStore.ReevaluateGuardrails(ctx, networkNeighborhoodResource, cache.Get))
+	nn = &softwarecomposition.NetworkNeighborhood{}
+	require.NoError(t, nnStore.Get(ctx, keyDefault, storage.GetOptions{}, nn))
+	assert.NotContains(t, nn.Annotations, GuardrailViolationsAnnotation)
+	violations, err := realStore.ListGuardrailViolations(ctx, "no-open-egress")
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+
+	// deleting a profile removes its violations from the index
+	require.NoError(t, realStore.Create(ctx, guardrailKey, &softwarecomposition.Guardrail{
+		ObjectMeta: v1.ObjectMeta{Name: "no-open-egress"},
+		Spec: softwarecomposition.GuardrailSpec{
+			Rules: []softwarecomposition.GuardrailRule{{Name: "never", Expression: "false"}},
+		},
+	}, nil, 0))
+	require.NoError(t, cache.Refresh(ctx))
+	require.NoError(t, nnStore.ReevaluateGuardrails(ctx, networkNeighborhoodResource, cache.Get))
+	violations, err = realStore.ListGuardrailViolations(ctx, "no-open-egress")
+	require.NoError(t, err)
+	assert.Len(t, violations, 2)
+	require.NoError(t, nnStore.Delete(ctx, keySystem, &softwarecomposition.NetworkNeighborhood{}, nil, nil, nil, storage.DeleteOptions{}))
+	violations, err = realStore.ListGuardrailViolations(ctx, "no-open-egress")
+	require.NoError(t, err)
+	assert.Equal(t, []softwarecomposition.GuardrailViolation{{Kind: "NetworkNeighborhood", Namespace: "default", Name: "nginx", Rule: "never"}}, violations)
+}
+
+func TestGuardrailsCache_RefreshFailure(t *testing.T) {
+	pool := NewTestPool(t.TempDir())
+	require.NotNil(t, pool)
+	sch := scheme.Scheme
+	require.NoError(t, softwarecomposition.AddToScheme(sch))
+	ctx := context.TODO()
+	realStore := NewStorageImpl(afero.NewMemMapFs(), DefaultStorageRoot, pool, nil, sch)
+	cache := NewGuardrailsCache(realStore)
+	require.NoError(t, realStore.Create(ctx, K8sClusterScopedKeysToPath("", softwarecomposition.GroupName, guardrailsResource, "never"), &softwarecomposition.Guardrail{
+		ObjectMeta: v1.ObjectMeta{Name: "never"},
+		Spec: softwarecomposition.GuardrailSpec{
+			Rules: []softwarecomposition.GuardrailRule{{Name: "never", Expression: "false"}},
+		},
+	}, nil, 0))
+	require.NoError(t, cache.Refresh(ctx))
+	set := cache.Get()
+	require.False(t, set.Empty())
+
+	// an unreadable storage keeps the previous guardrails
+	require.NoError(t, pool.Close())
+	assert.Error(t, cache.Refresh(ctx))
+	assert.Same(t, set, cache.Get())
+}
@@ -1,19 +1,18 @@
-package main
-
-// migration is a standalone utility to decode legacy Gob data into JSON.
-// It is used to handle breaking changes in the Gob format (e.g., transitioning 
-// fields from uint64 to int64) that cannot be handled by the main service 
-// due to Go's global Gob type registration constraints.
+// Package legacygob decodes the gob payloads written before fields of the
+// profiles changed from uint64 to int64, which gob refuses to decode into the
+// current types. They are decoded into copies of the types as they were, then
+// converted to the current ones through JSON.
+package legacygob
 
 import (
+	"bytes"
 	"encoding/gob"
 	"encoding/json"
-	"flag"
 	"fmt"
-	"os"
 
 	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // Legacy types with uint64 fields exactly as they were in the old binary format
@@ -53,7 +52,7 @@ type LegacyApplicationProfileContainer struct {
 	ImageID              string                                    `json:"ImageID"`
 	ImageTag             string                                    `json:"ImageTag"`
 	PolicyByRuleId       map[string]softwarecomposition.RulePolicy `json:"PolicyByRuleId"`
-	IdentifiedCallStacks []softwarecomposition.IdentifiedCallStack  `json:"IdentifiedCallStacks"`
+	IdentifiedCallStacks []softwarecomposition.IdentifiedCallStack `json:"IdentifiedCallStacks"`
 }
 
 type LegacyApplicationProfileSpec struct {
@@ -100,34 +99,34 @@ type LegacyContainerProfileSpec struct {
 	ImageID              string                                    `json:"ImageID"`
 	ImageTag             string                                    `json:"ImageTag"`
 	PolicyByRuleId       map[string]softwarecomposition.RulePolicy `json:"PolicyByRuleId"`
-	IdentifiedCallStacks []softwarecomposition.IdentifiedCallStack  `json:"IdentifiedCallStacks"`
+	IdentifiedCallStacks []softwarecomposition.IdentifiedCallStack `json:"IdentifiedCallStacks"`
 	metav1.LabelSelector `json:"LabelSelector"`
-	Ingress              []LegacyNetworkNeighbor                   `json:"Ingress"`
-	Egress               []LegacyNetworkNeighbor                   `json:"Egress"`
+	Ingress              []LegacyNetworkNeighbor `json:"Ingress"`
+	Egress               []LegacyNetworkNeighbor `json:"Egress"`
 }
 
 type LegacyContainerProfile struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:",inline"`
-	Spec              LegacyContainerProfileSpec `json:"Spec,omitempty"`
+	Spec              LegacyContainerProfileSpec                 `json:"Spec,omitempty"`
 	Status            softwarecomposition.ContainerProfileStatus `json:"Status,omitempty"`
 }
 
 type LegacyNetworkPort struct {
 	Name     string `json:"Name"`
 	Protocol string `json:"Protocol"`
-	Port     *int32  `json:"Port"`
+	Port     *int32 `json:"Port"`
 }
 
 type LegacyNetworkNeighbor struct {
-	Identifier        string                      `json:"Identifier"`
-	Type              string                      `json:"Type"`
-	DNS               string                      `json:"DNS"`
-	DNSNames          []string                    `json:"DNSNames"`
-	Ports             []LegacyNetworkPort         `json:"Ports"`
-	PodSelector       *metav1.LabelSelector       `json:"PodSelector"`
-	NamespaceSelector *metav1.LabelSelector       `json:"NamespaceSelector"`
-	IPAddress         string                      `json:"IPAddress"`
+	Identifier        string                `json:"Identifier"`
+	Type              string                `json:"Type"`
+	DNS               string                `json:"DNS"`
+	DNSNames          []string              `json:"DNSNames"`
+	Ports             []LegacyNetworkPort   `json:"Ports"`
+	PodSelector       *metav1.LabelSelector `json:"PodSelector"`
+	NamespaceSelector *metav1.LabelSelector `json:"NamespaceSelector"`
+	IPAddress         string                `json:"IPAddress"`
 }
 
 type LegacySeccompProfileSpec struct {
@@ -139,62 +138,37 @@ type LegacySeccompProfileSpec struct {
 type LegacySeccompProfile struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:",inline"`
-	Spec              LegacySeccompProfileSpec            `json:"Spec,omitempty"`
+	Spec              LegacySeccompProfileSpec                 `json:"Spec,omitempty"`
 	Status            softwarecomposition.SeccompProfileStatus `json:"Status,omitempty"`
 }
 
-func main() {
-	filePath := flag.String("file", "", "Path to the gob file to decode")
-	typeName := flag.String("type", "ApplicationProfile", "Type to decode (ApplicationProfile, ContainerProfile, or SeccompProfile)")
-	flag.Parse()
-
-	if *filePath == "" {
-		fmt.Fprintf(os.Stderr, "Usage: migration -file <path> [-type <ApplicationProfile|ContainerProfile|SeccompProfile>]\n")
-		os.Exit(1)
-	}
-
-	f, err := os.Open(*filePath)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "failed to open file: %v\n", err)
-		os.Exit(1)
-	}
-	defer f.Close()
-
-	var result interface{}
-	switch *typeName {
-	case "ApplicationProfile":
-		result = &LegacyApplicationProfile{}
-	case "ContainerProfile":
-		result = &LegacyContainerProfile{}
-	case "SeccompProfile":
-		result = &LegacySeccompProfile{}
-	default:
-		fmt.Fprintf(os.Stderr, "unsupported type: %s\n", *typeName)
-		os.Exit(1)
-	}
-
-	// Important: We need to register types that might be in the gob stream
-	// but are defined locally in this 'main' package to avoid name mismatches
-	// although gob name matching is usually package-scoped.
-	// Since this is a separate binary, its 'main.LegacyApplicationProfile'
-	// registration is isolated from the storage binary's registration.
-
-	// Register common types that might be inside interface{} fields or nested structs
+func init() {
+	// types that might be inside interface{} fields or nested structs
 	gob.Register(map[string]interface{}{})
 	gob.Register([]interface{}{})
 	gob.Register(metav1.Time{})
+}
 
-	decoder := gob.NewDecoder(f)
-	if err := decoder.Decode(result); err != nil {
-		fmt.Fprintf(os.Stderr, "decode failed: %v\n", err)
-		os.Exit(1)
+// Decode decodes the legacy gob payload data into obj, an ApplicationProfile,
+// a ContainerProfile or a SeccompProfile.
+func Decode(data []byte, obj runtime.Object) error {
+	var legacy interface{}
+	switch obj.(type) {
+	case *softwarecomposition.ApplicationProfile:
+		legacy = &LegacyApplicationProfile{}
+	case *softwarecomposition.ContainerProfile:
+		legacy = &LegacyContainerProfile{}
+	case *softwarecomposition.SeccompProfile:
+		legacy = &LegacySeccompProfile{}
+	default:
+		return fmt.Errorf("unsupported type %T", obj)
 	}
-
-	out, err := json.Marshal(result)
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(legacy); err != nil {
+		return fmt.Errorf("decode: %w", err)
+	}
+	out, err := json.Marshal(legacy)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "json marshal failed: %v\n", err)
-		os.Exit(1)
+		return fmt.Errorf("json marshal: %w", err)
 	}
-
-	fmt.Printf("%s", out)
+	return json.Unmarshal(out, obj)
 }
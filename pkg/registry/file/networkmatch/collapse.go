@@ -0,0 +1,247 @@
+package networkmatch
+
+import (
+	"net"
+	"sort"
+	"strings"
+)
+
+// IPv4CollapsePrefix and IPv6CollapsePrefix are the networks literal
+// addresses are bucketed into by CollapseIPs.
+const (
+	IPv4CollapsePrefix = 24
+	IPv6CollapsePrefix = 64
+)
+
+// CollapseIPs folds the literal addresses of an IPAddresses[] list into CIDR
+// entries: once more than threshold distinct literals fall into the same /24
+// (IPv4) or /64 (IPv6), they are replaced by that network. Literals already
+// covered by a CIDR entry, or by the AnyIPSentinel, are dropped too.
+//
+// Malformed entries are kept as they are, validation is the admission
+// layer's job. A threshold <= 0 disables the folding. The result is sorted
+// and deduplicated; entries is returned unchanged when nothing is folded or
+// dropped, so that an already collapsed list keeps its order.
+func CollapseIPs(entries []string, threshold int) []string {
+	if threshold <= 0 || len(entries) == 0 {
+		return entries
+	}
+	var (
+		anyIP     bool
+		changed   bool
+		cidrs     []*net.IPNet
+		malformed []string
+		literals  = map[string]net.IP{}
+		buckets   = map[string]*ipBucket{}
+		seen      = map[string]struct{}{}
+	)
+	for _, entry := range entries {
+		if _, ok := seen[entry]; ok {
+			changed = true
+			continue
+		}
+		seen[entry] = struct{}{}
+		switch {
+		case entry == AnyIPSentinel:
+			anyIP = true
+		case strings.Contains(entry, "/"):
+			if _, cidr, err := net.ParseCIDR(entry); err == nil {
+				cidrs = append(cidrs, cidr)
+				changed = changed || cidr.String() != entry
+			} else {
+				malformed = append(malformed, entry)
+			}
+		default:
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				malformed = append(malformed, entry)
+				continue
+			}
+			literals[entry] = ip
+			network := bucketOf(ip)
+			b, ok := buckets[network.String()]
+			if !ok {
+				b = &ipBucket{network: network, members: map[string]struct{}{}}
+				buckets[network.String()] = b
+			}
+			b.members[ip.String()] = struct{}{}
+		}
+	}
+
+	out := malformed
+	if anyIP {
+		changed = changed || len(cidrs) > 0 || len(literals) > 0
+		if !changed {
+			return entries
+		}
+		return sortedUnique(append(out, AnyIPSentinel))
+	}
+	for _, b := range buckets {
+		if len(b.members) > threshold {
+			cidrs = append(cidrs, b.network)
+			changed = true
+		}
+	}
+	for i := range cidrs {
+		if coveredByCIDR(cidrs, i) {
+			changed = true
+			continue
+		}
+		out = append(out, cidrs[i].String())
+	}
+	for entry, ip := range literals {
+		if containsIP(cidrs, ip) {
+			changed = true
+			continue
+		}
+		out = append(out, entry)
+	}
+	if !changed {
+		return entries
+	}
+	return sortedUnique(out)
+}
+
+type ipBucket struct {
+	network *net.IPNet
+	members map[string]struct{}
+}
+
+func bucketOf(ip net.IP) *net.IPNet {
+	if v4 := ip.To4(); v4 != nil {
+		mask := net.CIDRMask(IPv4CollapsePrefix, 8*net.IPv4len)
+		return &net.IPNet{IP: v4.Mask(mask), Mask: mask}
+	}
+	mask := net.CIDRMask(IPv6CollapsePrefix, 8*net.IPv6len)
+	return &net.IPNet{IP: ip.Mask(mask), Mask: mask}
+}
+
+// coveredByCIDR reports whether cidrs[i] is contained in a wider network of
+// cidrs, or in an equal network listed before it.
+func coveredByCIDR(cidrs []*net.IPNet, i int) bool {
+	ones, bits := cidrs[i].Mask.Size()
+	for j, c := range cidrs {
+		if j == i {
+			continue
+		}
+		cOnes, cBits := c.Mask.Size()
+		if cBits == bits && c.Contains(cidrs[i].IP) && (cOnes < ones || cOnes == ones && j < i) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsIP(cidrs []*net.IPNet, ip net.IP) bool {
+	for _, c := range cidrs {
+		if c.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// CollapseDNSNames folds the literal names of a DNSNames[] list into leading
+// wildcard entries: once more than threshold distinct labels appear directly
+// under the same suffix, they are replaced by "*.<suffix>", which matches
+// exactly one label (RFC 4592). Only suffixes of at least two labels are
+// considered, so that a public suffix like "com" is never wildcarded.
+// Literals already covered by a wildcard entry are dropped too.
+//
+// Names are compared lowercased and without their trailing dot, the
+// wildcard keeps the trailing dot style of the names it replaces. Pattern and
+// malformed entries are kept as they are. A threshold <= 0 disables the
+// folding. The result is sorted and deduplicated; entries is returned
+// unchanged when nothing is folded or dropped.
+func CollapseDNSNames(entries []string, threshold int) []string {
+	if threshold <= 0 || len(entries) == 0 {
+		return entries
+	}
+	var (
+		changed  bool
+		patterns []string
+		literals []string
+		groups   = map[string]*dnsGroup{}
+		order    []string
+		seen     = map[string]struct{}{}
+	)
+	for _, entry := range entries {
+		if _, ok := seen[entry]; ok {
+			changed = true
+			continue
+		}
+		seen[entry] = struct{}{}
+		labels, ok := splitDNS(entry)
+		if !ok || isDNSPattern(labels) {
+			patterns = append(patterns, entry)
+			continue
+		}
+		literals = append(literals, entry)
+		if len(labels) < 3 {
+			continue
+		}
+		suffix := strings.Join(labels[1:], ".")
+		g, ok := groups[suffix]
+		if !ok {
+			g = &dnsGroup{members: map[string]struct{}{}, trailingDot: strings.HasSuffix(entry, ".")}
+			groups[suffix] = g
+			order = append(order, suffix)
+		}
+		g.members[labels[0]] = struct{}{}
+	}
+	for _, suffix := range order {
+		g := groups[suffix]
+		if len(g.members) <= threshold {
+			continue
+		}
+		wildcard := DNSWildcardLabel + "." + suffix
+		if g.trailingDot {
+			wildcard += "."
+		}
+		if _, ok := seen[wildcard]; !ok {
+			seen[wildcard] = struct{}{}
+			patterns = append(patterns, wildcard)
+			changed = true
+		}
+	}
+
+	out := patterns
+	matcher := CompileDNS(patterns)
+	for _, entry := range literals {
+		if matcher.Match(entry) {
+			changed = true
+			continue
+		}
+		out = append(out, entry)
+	}
+	if !changed {
+		return entries
+	}
+	return sortedUnique(out)
+}
+
+type dnsGroup struct {
+	members     map[string]struct{}
+	trailingDot bool
+}
+
+func isDNSPattern(labels []string) bool {
+	for _, l := range labels {
+		if l == DNSWildcardLabel || l == DNSDynamicLabel || l == "**" {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedUnique(in []string) []string {
+	sort.Strings(in)
+	out := in[:0]
+	for i, s := range in {
+		if i > 0 && s == in[i-1] {
+			continue
+		}
+		out = append(out, s)
+	}
+	return out
+}
@@ -0,0 +1,104 @@
+package networkmatch
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func ipRange(prefix string, from, to int) []string {
+	var out []string
+	for i := from; i <= to; i++ {
+		out = append(out, fmt.Sprintf("%s%d", prefix, i))
+	}
+	return out
+}
+
+func TestCollapseIPs(t *testing.T) {
+	cases := []struct {
+		name      string
+		entries   []string
+		threshold int
+		want      []string
+	}{
+		{"below-threshold-unchanged", []string{"10.0.0.2", "10.0.0.1"}, 2, []string{"10.0.0.2", "10.0.0.1"}},
+		{"v4-folds-to-24", ipRange("10.0.0.", 1, 3), 2, []string{"10.0.0.0/24"}},
+		{"only-crowded-bucket-folds", append(ipRange("10.0.0.", 1, 3), "10.0.1.1"), 2, []string{"10.0.0.0/24", "10.0.1.1"}},
+		{"v6-folds-to-64", []string{"2001:db8::1", "2001:db8::2", "2001:db8::3"}, 2, []string{"2001:db8::/64"}},
+		{"existing-cidr-absorbs-literals", []string{"10.1.2.3", "10.0.0.0/8", "192.168.1.1"}, 16, []string{"10.0.0.0/8", "192.168.1.1"}},
+		{"wider-cidr-absorbs-narrower", []string{"10.0.0.0/24", "10.0.0.0/8"}, 16, []string{"10.0.0.0/8"}},
+		{"folded-cidr-inside-existing", append(ipRange("10.0.0.", 1, 3), "10.0.0.0/16"), 2, []string{"10.0.0.0/16"}},
+		{"any-sentinel-wins", []string{"10.0.0.1", "*", "2001:db8::/32"}, 16, []string{"*"}},
+		{"duplicates-dropped", []string{"10.0.0.1", "10.0.0.1"}, 16, []string{"10.0.0.1"}},
+		{"malformed-kept", append(ipRange("10.0.0.", 1, 3), "not-an-ip"), 2, []string{"10.0.0.0/24", "not-an-ip"}},
+		{"threshold-zero-disabled", ipRange("10.0.0.", 1, 3), 0, ipRange("10.0.0.", 1, 3)},
+		{"nil", nil, 2, nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := CollapseIPs(tc.entries, tc.threshold)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("CollapseIPs(%v, %d) = %v, want %v", tc.entries, tc.threshold, got, tc.want)
+			}
+			for _, entry := range got {
+				if entry == "not-an-ip" {
+					continue
+				}
+				if err := ValidateIPEntry(entry); err != nil {
+					t.Errorf("CollapseIPs produced an invalid entry: %v", err)
+				}
+			}
+			// collapsing is idempotent and keeps every input address admitted
+			if again := CollapseIPs(got, tc.threshold); !reflect.DeepEqual(again, got) {
+				t.Errorf("CollapseIPs is not idempotent: %v then %v", got, again)
+			}
+			for _, entry := range tc.entries {
+				if ValidateIPEntry(entry) == nil && entry != AnyIPSentinel && !strings.Contains(entry, "/") && !MatchIP(got, entry) {
+					t.Errorf("%q is no longer admitted by %v", entry, got)
+				}
+			}
+		})
+	}
+}
+
+func TestCollapseDNSNames(t *testing.T) {
+	cases := []struct {
+		name      string
+		entries   []string
+		threshold int
+		want      []string
+	}{
+		{"below-threshold-unchanged", []string{"b.example.com.", "a.example.com."}, 2, []string{"b.example.com.", "a.example.com."}},
+		{"siblings-fold", []string{"a.example.com.", "b.example.com.", "c.example.com."}, 2, []string{"*.example.com."}},
+		{"case-and-dot-insensitive", []string{"a.example.com", "A.example.com.", "b.example.com", "c.example.com"}, 2, []string{"*.example.com"}},
+		{"deeper-names-kept", []string{"a.example.com.", "b.example.com.", "c.example.com.", "x.a.example.com."}, 2, []string{"*.example.com.", "x.a.example.com."}},
+		{"public-suffix-never-folds", []string{"a.com.", "b.com.", "c.com."}, 2, []string{"a.com.", "b.com.", "c.com."}},
+		{"existing-wildcard-absorbs", []string{"api.example.com.", "*.example.com.", "other.org."}, 16, []string{"*.example.com.", "other.org."}},
+		{"mid-dynamic-absorbs", []string{"kubernetes.⋯.svc.cluster.local.", "kubernetes.default.svc.cluster.local."}, 16, []string{"kubernetes.⋯.svc.cluster.local."}},
+		{"duplicates-dropped", []string{"a.example.com.", "a.example.com."}, 16, []string{"a.example.com."}},
+		{"threshold-zero-disabled", []string{"a.example.com.", "b.example.com."}, 0, []string{"a.example.com.", "b.example.com."}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := CollapseDNSNames(tc.entries, tc.threshold)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("CollapseDNSNames(%v, %d) = %v, want %v", tc.entries, tc.threshold, got, tc.want)
+			}
+			for _, entry := range got {
+				if err := ValidateDNSEntry(entry); err != nil {
+					t.Errorf("CollapseDNSNames produced an invalid entry: %v", err)
+				}
+			}
+			if again := CollapseDNSNames(got, tc.threshold); !reflect.DeepEqual(again, got) {
+				t.Errorf("CollapseDNSNames is not idempotent: %v then %v", got, again)
+			}
+			for _, entry := range tc.entries {
+				labels, _ := splitDNS(entry)
+				if !isDNSPattern(labels) && !MatchDNS(got, entry) {
+					t.Errorf("%q is no longer admitted by %v", entry, got)
+				}
+			}
+		})
+	}
+}
@@ -2,33 +2,95 @@ package file
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"slices"
 	"strconv"
+	"strings"
+	"sync/atomic"
 
 	mapset "github.com/deckarep/golang-set/v2"
+	"github.com/kubescape/go-logger"
+	loggerhelpers "github.com/kubescape/go-logger/helpers"
 	"github.com/kubescape/k8s-interface/instanceidhandler/v1/helpers"
 	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
 	"github.com/kubescape/storage/pkg/config"
+	"github.com/kubescape/storage/pkg/registry/file/dynamicpathdetector"
+	"github.com/kubescape/storage/pkg/registry/file/networkmatch"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
 type NetworkNeighborhoodProcessor struct {
-	maxNetworkNeighborhoodSize int
+	maxNetworkNeighborhoodSize atomic.Int64
+	// collapseSettings is the lookup hook the deflate path consults for the
+	// IP and DNS collapse thresholds. Defaults to
+	// dynamicpathdetector.DefaultCollapseSettings.
+	collapseSettings dynamicpathdetector.CollapseSettingsProvider
+	// collapseResolver, when set, takes precedence over collapseSettings and
+	// picks the settings of each profile from its namespace and labels.
+	collapseResolver CollapseSettingsResolver
+	// guardrails returns the Guardrail rules profiles are evaluated against,
+	// nil disables the evaluation.
+	guardrails GuardrailsProvider
 }
 
 func NewNetworkNeighborhoodProcessor(cfg config.Config) *NetworkNeighborhoodProcessor {
-	return &NetworkNeighborhoodProcessor{
-		maxNetworkNeighborhoodSize: cfg.MaxNetworkNeighborhoodSize,
+	a := &NetworkNeighborhoodProcessor{
+		collapseSettings: dynamicpathdetector.DefaultCollapseSettings,
 	}
+	a.Reload(&cfg)
+	return a
+}
+
+// Reload applies the size limit of cfg to the next network neighborhoods
+// saved.
+func (a *NetworkNeighborhoodProcessor) Reload(cfg *config.Config) {
+	a.maxNetworkNeighborhoodSize.Store(int64(cfg.MaxNetworkNeighborhoodSize))
+}
+
+// SetCollapseSettings overrides the provider the deflate path uses to fetch
+// the IP and DNS collapse thresholds. A nil provider restores the defaults.
+func (a *NetworkNeighborhoodProcessor) SetCollapseSettings(p dynamicpathdetector.CollapseSettingsProvider) {
+	if p == nil {
+		a.collapseSettings = dynamicpathdetector.DefaultCollapseSettings
+		return
+	}
+	a.collapseSettings = p
+}
+
+// SetCollapseSettingsResolver sets the resolver picking the collapse settings
+// of each profile, it takes precedence over SetCollapseSettings.
+func (a *NetworkNeighborhoodProcessor) SetCollapseSettingsResolver(r CollapseSettingsResolver) {
+	a.collapseResolver = r
+}
+
+// resolveCollapseSettings returns the settings profile is collapsed with and
+// the name of the CollapseConfiguration they come from.
+func (a *NetworkNeighborhoodProcessor) resolveCollapseSettings(ctx context.Context, profile metav1.Object) (dynamicpathdetector.CollapseSettings, string) {
+	if a.collapseResolver != nil {
+		return a.collapseResolver(ctx, profile.GetNamespace(), profile.GetLabels())
+	}
+	if a.collapseSettings == nil {
+		return dynamicpathdetector.DefaultCollapseSettings(), ""
+	}
+	return a.collapseSettings(), ""
+}
+
+// SetGuardrails sets the provider of the guardrails every saved profile is
+// evaluated against.
+func (a *NetworkNeighborhoodProcessor) SetGuardrails(p GuardrailsProvider) {
+	a.guardrails = p
 }
 
 var _ Processor = (*NetworkNeighborhoodProcessor)(nil)
 
-func (a NetworkNeighborhoodProcessor) AfterCreate(_ context.Context, _ runtime.Object) error {
+func (a *NetworkNeighborhoodProcessor) AfterCreate(_ context.Context, _ runtime.Object) error {
 	return nil
 }
 
-func (a NetworkNeighborhoodProcessor) PreSave(_ context.Context, object runtime.Object) error {
+func (a *NetworkNeighborhoodProcessor) PreSave(ctx context.Context, object runtime.Object) error {
 	profile, ok := object.(*softwarecomposition.NetworkNeighborhood)
 	if !ok {
 		return fmt.Errorf("given object is not an NetworkNeighborhood")
@@ -39,25 +101,48 @@ func (a NetworkNeighborhoodProcessor) PreSave(_ context.Context, object runtime.
 
 	// size is the sum of all ingress/egress in all containers
 	var size int
+	settings, collapseConfiguration := a.resolveCollapseSettings(ctx, profile)
 
-	// Define a function to process a slice of containers
-	processContainers := func(containers []softwarecomposition.NetworkNeighborhoodContainer) []softwarecomposition.NetworkNeighborhoodContainer {
-		for i, container := range containers {
-			containers[i] = deflateNetworkNeighborhoodContainer(container)
-			size += len(containers[i].Ingress)
-			size += len(containers[i].Egress)
+	// containers are deflated in place, their original is kept in case they
+	// have to be degraded, see MaxDegradationLevel
+	lists := []*[]softwarecomposition.NetworkNeighborhoodContainer{&profile.Spec.EphemeralContainers, &profile.Spec.InitContainers, &profile.Spec.Containers}
+	originals := make([][]softwarecomposition.NetworkNeighborhoodContainer, len(lists))
+	var count int
+	for l, containers := range lists {
+		for i, container := range *containers {
+			originals[l] = append(originals[l], container)
+			(*containers)[i] = deflateNetworkNeighborhoodContainer(container, settings)
+			size += networkNeighborhoodContainerSize((*containers)[i])
+			count++
 		}
-		return containers
 	}
 
-	// Use the function for InitContainers, EphemeralContainers and Containers
-	profile.Spec.EphemeralContainers = processContainers(profile.Spec.EphemeralContainers)
-	profile.Spec.InitContainers = processContainers(profile.Spec.InitContainers)
-	profile.Spec.Containers = processContainers(profile.Spec.Containers)
+	// degrade the containers larger than their share of the limit
+	maxSize := int(a.maxNetworkNeighborhoodSize.Load())
+	var degradationLevel int
+	if size > maxSize {
+		budget := containerBudget(maxSize, count)
+		size = 0
+		for l, containers := range lists {
+			for i := range *containers {
+				if networkNeighborhoodContainerSize((*containers)[i]) > budget {
+					original := originals[l][i]
+					var level int
+					(*containers)[i], level = degradeUntilFits(func(level int) softwarecomposition.NetworkNeighborhoodContainer {
+						return deflateNetworkNeighborhoodContainer(original, degradeCollapseSettings(settings, level))
+					}, networkNeighborhoodContainerSize, budget)
+					logger.L().Debug("NetworkNeighborhoodProcessor.PreSave - degraded container", loggerhelpers.String("name", profile.Name), loggerhelpers.String("namespace", profile.Namespace),
+						loggerhelpers.String("container", original.Name), loggerhelpers.Int("level", level))
+					degradationLevel = max(degradationLevel, level)
+				}
+				size += networkNeighborhoodContainerSize((*containers)[i])
+			}
+		}
+	}
 
 	// check the size of the profile
-	if size > a.maxNetworkNeighborhoodSize {
-		return fmt.Errorf("application profile size exceeds the limit of %d: %w", a.maxNetworkNeighborhoodSize, ObjectTooLargeError)
+	if size > maxSize {
+		return fmt.Errorf("application profile size exceeds the limit of %d: %w", maxSize, ObjectTooLargeError)
 	}
 
 	// make sure annotations are initialized
@@ -65,23 +150,39 @@ func (a NetworkNeighborhoodProcessor) PreSave(_ context.Context, object runtime.
 		profile.Annotations = make(map[string]string)
 	}
 	profile.Annotations[helpers.ResourceSizeMetadataKey] = strconv.Itoa(size)
+	recordDegradationLevel(profile, degradationLevel)
+	recordCollapseConfiguration(profile, collapseConfiguration)
+	if a.guardrails != nil {
+		if err := applyGuardrails(a.guardrails(), profile); err != nil {
+			logger.L().Warning("NetworkNeighborhoodProcessor.PreSave - guardrails evaluation failed", loggerhelpers.Error(err))
+		}
+	}
 	return nil
 }
 
-func (a NetworkNeighborhoodProcessor) SetStorage(_ ContainerProfileStorage) {}
+func (a *NetworkNeighborhoodProcessor) SetStorage(_ ContainerProfileStorage) {}
 
-func deflateNetworkNeighborhoodContainer(container softwarecomposition.NetworkNeighborhoodContainer) softwarecomposition.NetworkNeighborhoodContainer {
+// networkNeighborhoodContainerSize is the number of entries of container
+// counted against the size limit of its profile.
+func networkNeighborhoodContainerSize(container softwarecomposition.NetworkNeighborhoodContainer) int {
+	return len(container.Ingress) + len(container.Egress)
+}
+
+func deflateNetworkNeighborhoodContainer(container softwarecomposition.NetworkNeighborhoodContainer, settings dynamicpathdetector.CollapseSettings) softwarecomposition.NetworkNeighborhoodContainer {
 	return softwarecomposition.NetworkNeighborhoodContainer{
 		Name:    container.Name,
-		Ingress: deflateNetworkNeighbors(container.Ingress),
-		Egress:  deflateNetworkNeighbors(container.Egress),
+		Ingress: deflateNetworkNeighbors(container.Ingress, settings),
+		Egress:  deflateNetworkNeighbors(container.Egress, settings),
 	}
 }
 
 // NetworkNeighbors are merged on Identifier
-// DNSNames are deduplicated
+// DNSNames and IPAddresses are deduplicated
 // Ports are merged on Name
-func deflateNetworkNeighbors(in []softwarecomposition.NetworkNeighbor) []softwarecomposition.NetworkNeighbor {
+// IPAddresses and DNSNames are then collapsed into CIDRs and DNS wildcards,
+// and the external neighbors they make redundant are folded together,
+// see foldExternalNeighbors.
+func deflateNetworkNeighbors(in []softwarecomposition.NetworkNeighbor, settings dynamicpathdetector.CollapseSettings) []softwarecomposition.NetworkNeighbor {
 	if in == nil {
 		return nil
 	}
@@ -91,6 +192,7 @@ func deflateNetworkNeighbors(in []softwarecomposition.NetworkNeighbor) []softwar
 	for _, item := range in {
 		if index, ok := seen[item.Identifier]; ok {
 			out[index].DNSNames = append(out[index].DNSNames, item.DNSNames...)
+			out[index].IPAddresses = append(out[index].IPAddresses, item.IPAddresses...)
 			out[index].Ports = append(out[index].Ports, item.Ports...)
 			toDeflate.Add(index)
 		} else {
@@ -100,7 +202,176 @@ func deflateNetworkNeighbors(in []softwarecomposition.NetworkNeighbor) []softwar
 	}
 	for _, i := range mapset.Sorted(toDeflate) {
 		out[i].DNSNames = DeflateSortString(out[i].DNSNames)
+		out[i].IPAddresses = DeflateSortString(out[i].IPAddresses)
 		out[i].Ports = DeflateStringer(out[i].Ports)
 	}
+	for i := range out {
+		out[i].IPAddresses = networkmatch.CollapseIPs(out[i].IPAddresses, settings.IPCollapseThreshold)
+		out[i].DNSNames = networkmatch.CollapseDNSNames(out[i].DNSNames, settings.DNSCollapseThreshold)
+	}
+	return foldExternalNeighbors(out, settings)
+}
+
+// foldExternalNeighbors collapses the peers of the external neighbors (no pod
+// or namespace selector) sharing a Type and a set of ports. Each of them
+// usually carries a single address or name, so it is only across neighbors
+// that a /24 or a DNS suffix gets crowded. When the collapsed peers cover all
+// the entries of at least two neighbors, these are replaced, at the position
+// of the first one, by a single neighbor carrying the covering CIDRs and
+// wildcards. Its Identifier is derived from its content, so that folding a
+// profile again is stable.
+func foldExternalNeighbors(in []softwarecomposition.NetworkNeighbor, settings dynamicpathdetector.CollapseSettings) []softwarecomposition.NetworkNeighbor {
+	if settings.IPCollapseThreshold <= 0 && settings.DNSCollapseThreshold <= 0 {
+		return in
+	}
+	groups := map[string][]int{}
+	var keys []string
+	for i, n := range in {
+		if n.PodSelector != nil || n.NamespaceSelector != nil {
+			continue
+		}
+		key := string(n.Type) + "|" + portsKey(n.Ports)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], i)
+	}
+	replaced := map[int]softwarecomposition.NetworkNeighbor{}
+	dropped := mapset.NewThreadUnsafeSet[int]()
+	for _, key := range keys {
+		members := groups[key]
+		if len(members) < 2 {
+			continue
+		}
+		var ips, names []string
+		for _, i := range members {
+			ips = append(ips, neighborIPs(&in[i])...)
+			names = append(names, neighborNames(&in[i])...)
+		}
+		wildIPs := wildcardIPs(networkmatch.CollapseIPs(ips, settings.IPCollapseThreshold))
+		wildNames := wildcardNames(networkmatch.CollapseDNSNames(names, settings.DNSCollapseThreshold))
+		if len(wildIPs) == 0 && len(wildNames) == 0 {
+			continue
+		}
+		var foldable []int
+		usedIPs := mapset.NewThreadUnsafeSet[string]()
+		usedNames := mapset.NewThreadUnsafeSet[string]()
+		for _, i := range members {
+			nIPs, nNames := neighborIPs(&in[i]), neighborNames(&in[i])
+			if len(nIPs)+len(nNames) == 0 || !coveredEntries(nIPs, wildIPs, networkmatch.MatchIP) || !coveredEntries(nNames, wildNames, networkmatch.MatchDNS) {
+				continue
+			}
+			foldable = append(foldable, i)
+			usedIPs.Append(coveringEntries(nIPs, wildIPs, networkmatch.MatchIP)...)
+			usedNames.Append(coveringEntries(nNames, wildNames, networkmatch.MatchDNS)...)
+		}
+		if len(foldable) < 2 {
+			continue
+		}
+		first := in[foldable[0]]
+		folded := softwarecomposition.NetworkNeighbor{
+			Type:        first.Type,
+			Ports:       first.Ports,
+			IPAddresses: mapset.Sorted(usedIPs),
+			DNSNames:    mapset.Sorted(usedNames),
+		}
+		if len(folded.IPAddresses) == 0 {
+			folded.IPAddresses = nil
+		}
+		if len(folded.DNSNames) == 0 {
+			folded.DNSNames = nil
+		}
+		folded.Identifier = foldedIdentifier(&folded)
+		replaced[foldable[0]] = folded
+		dropped.Append(foldable[1:]...)
+	}
+	if len(replaced) == 0 {
+		return in
+	}
+	out := make([]softwarecomposition.NetworkNeighbor, 0, len(in)-dropped.Cardinality())
+	for i, n := range in {
+		if dropped.Contains(i) {
+			continue
+		}
+		if folded, ok := replaced[i]; ok {
+			n = folded
+		}
+		out = append(out, n)
+	}
+	return out
+}
+
+func portsKey(ports []softwarecomposition.NetworkPort) string {
+	names := make([]string, 0, len(ports))
+	for _, p := range ports {
+		names = append(names, p.String())
+	}
+	return strings.Join(DeflateSortString(names), ",")
+}
+
+// neighborIPs returns the addresses of n, including the deprecated IPAddress.
+func neighborIPs(n *softwarecomposition.NetworkNeighbor) []string {
+	if n.IPAddress == "" {
+		return n.IPAddresses
+	}
+	return append(slices.Clip(n.IPAddresses), n.IPAddress)
+}
+
+// neighborNames returns the names of n, including the deprecated DNS.
+func neighborNames(n *softwarecomposition.NetworkNeighbor) []string {
+	if n.DNS == "" {
+		return n.DNSNames
+	}
+	return append(slices.Clip(n.DNSNames), n.DNS)
+}
+
+func wildcardIPs(entries []string) []string {
+	var out []string
+	for _, e := range entries {
+		if e == networkmatch.AnyIPSentinel || strings.Contains(e, "/") {
+			out = append(out, e)
+		}
+	}
 	return out
 }
+
+func wildcardNames(entries []string) []string {
+	var out []string
+	for _, e := range entries {
+		if strings.Contains(e, networkmatch.DNSWildcardLabel) || strings.Contains(e, networkmatch.DNSDynamicLabel) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// coveredEntries reports whether every entry is one of patterns or matched
+// by them.
+func coveredEntries(entries, patterns []string, match func([]string, string) bool) bool {
+	for _, e := range entries {
+		if !slices.Contains(patterns, e) && !match(patterns, e) {
+			return false
+		}
+	}
+	return true
+}
+
+// coveringEntries returns the patterns which are, or match, one of entries.
+func coveringEntries(entries, patterns []string, match func([]string, string) bool) []string {
+	var out []string
+	for _, p := range patterns {
+		for _, e := range entries {
+			if e == p || match([]string{p}, e) {
+				out = append(out, p)
+				break
+			}
+		}
+	}
+	return out
+}
+
+func foldedIdentifier(n *softwarecomposition.NetworkNeighbor) string {
+	hasher := sha256.New()
+	hasher.Write([]byte(string(n.Type) + "|" + portsKey(n.Ports) + "|" + strings.Join(n.IPAddresses, ",") + "|" + strings.Join(n.DNSNames, ",")))
+	return hex.EncodeToString(hasher.Sum(nil))
+}
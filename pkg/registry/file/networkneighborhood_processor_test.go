@@ -3,14 +3,18 @@ package file
 import (
 	"context"
 	"fmt"
+	"slices"
 	"testing"
 
 	"github.com/kubescape/k8s-interface/instanceidhandler/v1/helpers"
 	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
 	"github.com/kubescape/storage/pkg/config"
+	"github.com/kubescape/storage/pkg/registry/file/dynamicpathdetector"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
 )
 
 var nn = softwarecomposition.NetworkNeighborhood{
@@ -130,3 +134,37 @@ func TestNetworkNeighborhoodProcessor_PreSave(t *testing.T) {
 		})
 	}
 }
+
+func TestDeflateNetworkNeighbors_Collapse(t *testing.T) {
+	settings := dynamicpathdetector.CollapseSettings{IPCollapseThreshold: 2, DNSCollapseThreshold: 2}
+	https := []softwarecomposition.NetworkPort{{Name: "TCP-443", Protocol: "TCP", Port: ptr.To[int32](443)}}
+	in := []softwarecomposition.NetworkNeighbor{
+		{Identifier: "in-cluster", Type: "internal", PodSelector: &v1.LabelSelector{MatchLabels: map[string]string{"app": "db"}}, Ports: https},
+		{Identifier: "1", Type: "external", IPAddresses: []string{"10.0.0.1"}, Ports: https},
+		{Identifier: "2", Type: "external", IPAddress: "10.0.0.2", Ports: https},
+		{Identifier: "3", Type: "external", IPAddresses: []string{"10.0.0.3"}, Ports: https},
+		{Identifier: "other-port", Type: "external", IPAddresses: []string{"10.0.0.4"}},
+		{Identifier: "a", Type: "external", DNSNames: []string{"a.example.com."}, Ports: https},
+		{Identifier: "b", Type: "external", DNSNames: []string{"b.example.com."}, Ports: https},
+		{Identifier: "c", Type: "external", DNSNames: []string{"c.example.com."}, Ports: https},
+		{Identifier: "many", Type: "external", IPAddresses: []string{"192.168.1.1", "192.168.1.2", "192.168.1.3", "192.168.2.1"}},
+	}
+	out := deflateNetworkNeighbors(in, settings)
+	require.Len(t, out, 4)
+	assert.Equal(t, "in-cluster", out[0].Identifier)
+	assert.Equal(t, []string{"10.0.0.0/24"}, out[1].IPAddresses)
+	assert.Equal(t, https, out[1].Ports)
+	assert.Equal(t, []string{"*.example.com."}, out[1].DNSNames)
+	assert.Len(t, out[1].Identifier, 64)
+	assert.Equal(t, "other-port", out[2].Identifier)
+	assert.Equal(t, []string{"192.168.1.0/24", "192.168.2.1"}, out[3].IPAddresses)
+
+	// folding again is stable, and absorbs a new neighbor the wildcards cover
+	again := deflateNetworkNeighbors(append(slices.Clone(out),
+		softwarecomposition.NetworkNeighbor{Identifier: "d", Type: "external", DNSNames: []string{"d.example.com."}, Ports: https}), settings)
+	assert.Equal(t, out, again)
+
+	// the default thresholds leave small profiles alone
+	small := deflateNetworkNeighbors(in[:4], dynamicpathdetector.DefaultCollapseSettings())
+	assert.Equal(t, in[:4], small)
+}
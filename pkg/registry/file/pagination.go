@@ -0,0 +1,159 @@
+package file
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/storage"
+)
+
+// continueTokenVersion identifies the layout of continueToken, tokens of any
+// other version are rejected instead of being misread.
+const continueTokenVersion = "kubescape.io/v1"
+
+// defaultListLimit is the page size of the metadata lists when the client sets no limit.
+const defaultListLimit = 500
+
+// listPosition locates an object in a list. Lists are ordered by namespace then
+// name: unlike the SQLite rowid, this order does not change when an object is
+// updated, so an object is never returned twice while paging.
+type listPosition struct {
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+}
+
+func (p listPosition) after(other listPosition) bool {
+	if p.Namespace != other.Namespace {
+		return p.Namespace > other.Namespace
+	}
+	return p.Name > other.Name
+}
+
+// continueToken is the decoded form of the opaque continue value of a list.
+// It records what was listed, so that a token cannot be replayed against
+// another list, and the resourceVersion of the first page, which every
+// following page reports.
+type continueToken struct {
+	Version         string       `json:"v"`
+	ResourceVersion uint64       `json:"rv,omitempty"`
+	Kind            string       `json:"kind"`
+	Namespace       string       `json:"ns,omitempty"`
+	After           listPosition `json:"after"`
+}
+
+func encodeContinue(key string, resourceVersion uint64, after listPosition) (string, error) {
+	_, _, kind, _, namespace, _ := K8sPathToKeys(key)
+	b, err := json.Marshal(continueToken{
+		Version:         continueTokenVersion,
+		ResourceVersion: resourceVersion,
+		Kind:            kind,
+		Namespace:       namespace,
+		After:           after,
+	})
+	if err != nil {
+		return "", fmt.Errorf("encode continue token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// decodeContinue decodes the continue value of a list of key. An empty value
+// yields the zero token, which starts the list from the beginning.
+func decodeContinue(key, value string) (continueToken, error) {
+	var token continueToken
+	if value == "" {
+		return token, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return token, invalidContinueError(err)
+	}
+	if err := json.Unmarshal(b, &token); err != nil {
+		return token, invalidContinueError(err)
+	}
+	if token.Version != continueTokenVersion {
+		return token, invalidContinueError(fmt.Errorf("unsupported version %q", token.Version))
+	}
+	_, _, kind, _, namespace, _ := K8sPathToKeys(key)
+	if token.Kind != kind || token.Namespace != namespace {
+		return token, invalidContinueError(fmt.Errorf("token was issued for another list"))
+	}
+	return token, nil
+}
+
+// reencodeContinue translates a continue value issued for a list of from into one for a list of to,
+// which lets a derived list hand out tokens of its own while paging through its source.
+func reencodeContinue(from, to, value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	token, err := decodeContinue(from, value)
+	if err != nil {
+		return "", err
+	}
+	return encodeContinue(to, token.ResourceVersion, token.After)
+}
+
+func invalidContinueError(err error) error {
+	return apierrors.NewBadRequest(fmt.Sprintf("invalid continue token: %v", err))
+}
+
+// setListMeta fills the list metadata of a page of key ending at last. remaining
+// is the number of objects after the page; it is only reported when the list is
+// not filtered by selectors, as the count would otherwise be wrong.
+func setListMeta(listObj runtime.Object, key string, opts storage.ListOptions, resourceVersion uint64, last listPosition, remaining int64) error {
+	listAccessor, err := meta.ListAccessor(listObj)
+	if err != nil {
+		return fmt.Errorf("list accessor: %w", err)
+	}
+	listAccessor.SetResourceVersion(strconv.FormatUint(resourceVersion, 10))
+	if remaining <= 0 {
+		listAccessor.SetContinue("")
+		listAccessor.SetRemainingItemCount(nil)
+		return nil
+	}
+	cont, err := encodeContinue(key, resourceVersion, last)
+	if err != nil {
+		return err
+	}
+	listAccessor.SetContinue(cont)
+	if !hasSelectors(opts.Predicate) {
+		listAccessor.SetRemainingItemCount(&remaining)
+	} else {
+		listAccessor.SetRemainingItemCount(nil)
+	}
+	return nil
+}
+
+func hasSelectors(p storage.SelectionPredicate) bool {
+	return (p.Label != nil && !p.Label.Empty()) || (p.Field != nil && !p.Field.Empty())
+}
+
+// paginate applies the limit and continue value of opts to items, which must be
+// sorted by position. It is used by the lists computed in memory, where a zero
+// limit returns every remaining item.
+func paginate[T any](key string, opts storage.ListOptions, items []T, position func(T) listPosition) ([]T, continueToken, listPosition, int64, error) {
+	token, err := decodeContinue(key, opts.Predicate.Continue)
+	if err != nil {
+		return nil, token, listPosition{}, 0, err
+	}
+	start := 0
+	if opts.Predicate.Continue != "" {
+		for start < len(items) && !position(items[start]).after(token.After) {
+			start++
+		}
+	}
+	end := len(items)
+	if opts.Predicate.Limit > 0 && int64(end-start) > opts.Predicate.Limit {
+		end = start + int(opts.Predicate.Limit)
+	}
+	var last listPosition
+	if end > start {
+		last = position(items[end-1])
+	}
+	return items[start:end], token, last, int64(len(items) - end), nil
+}
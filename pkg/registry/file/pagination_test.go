@@ -0,0 +1,110 @@
+package file
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"github.com/kubescape/storage/pkg/generated/clientset/versioned/scheme"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/storage"
+	"k8s.io/utils/ptr"
+)
+
+func TestStorageImpl_GetListPagination(t *testing.T) {
+	pool := NewTestPool(t.TempDir())
+	require.NotNil(t, pool)
+	defer func() { _ = pool.Close() }()
+	sch := scheme.Scheme
+	require.NoError(t, softwarecomposition.AddToScheme(sch))
+	s := NewStorageImpl(afero.NewMemMapFs(), DefaultStorageRoot, pool, nil, sch)
+	ctx := context.TODO()
+
+	var want []string
+	for _, ns := range []string{"a", "b"} {
+		for i := range 3 {
+			name := fmt.Sprintf("sbom%d", i)
+			key := "/spdx.softwarecomposition.kubescape.io/sbomsyfts/" + ns + "/" + name
+			require.NoError(t, s.Create(ctx, key, &softwarecomposition.SBOMSyft{ObjectMeta: v1.ObjectMeta{Name: name, Namespace: ns}}, nil, 0))
+			want = append(want, ns+"/"+name)
+		}
+	}
+	key := "/spdx.softwarecomposition.kubescape.io/sbomsyfts"
+
+	var got []string
+	var resourceVersion string
+	opts := storage.ListOptions{Predicate: storage.SelectionPredicate{Limit: 4}}
+	for page := 0; ; page++ {
+		list := &softwarecomposition.SBOMSyftList{}
+		require.NoError(t, s.GetList(ctx, key, opts, list))
+		for _, item := range list.Items {
+			got = append(got, item.Namespace+"/"+item.Name)
+		}
+		if page == 0 {
+			resourceVersion = list.ResourceVersion
+			assert.NotEmpty(t, resourceVersion)
+			assert.Equal(t, ptr.To[int64](2), list.RemainingItemCount)
+			// an update moves the rowid of the object, it must not be listed twice
+			obj := &softwarecomposition.SBOMSyft{}
+			require.NoError(t, s.GuaranteedUpdate(ctx, key+"/a/sbom0", obj, false, nil, func(input runtime.Object, _ storage.ResponseMeta) (runtime.Object, *uint64, error) {
+				input.(*softwarecomposition.SBOMSyft).Labels = map[string]string{"updated": "true"}
+				return input, nil, nil
+			}, nil))
+		} else {
+			// later pages report the resourceVersion of the first one
+			assert.Equal(t, resourceVersion, list.ResourceVersion)
+		}
+		if list.Continue == "" {
+			assert.Nil(t, list.RemainingItemCount)
+			break
+		}
+		opts.Predicate.Continue = list.Continue
+	}
+	assert.Equal(t, want, got)
+
+	// a namespaced list continues within its namespace
+	list := &softwarecomposition.SBOMSyftList{}
+	require.NoError(t, s.GetList(ctx, key+"/b", storage.ListOptions{Predicate: storage.SelectionPredicate{Limit: 2}}, list))
+	assert.Len(t, list.Items, 2)
+	assert.Equal(t, ptr.To[int64](1), list.RemainingItemCount)
+
+	// tokens are validated
+	for _, cont := range []string{"garbage", list.Continue} {
+		err := s.GetList(ctx, key+"/a", storage.ListOptions{Predicate: storage.SelectionPredicate{Limit: 2, Continue: cont}}, &softwarecomposition.SBOMSyftList{})
+		assert.True(t, apierrors.IsBadRequest(err), "continue %q: %v", cont, err)
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	items := []string{"a", "b", "c", "d", "e"}
+	position := func(item string) listPosition { return listPosition{Name: item} }
+	key := "/spdx.softwarecomposition.kubescape.io/vulnerabilitysummaries"
+
+	page, token, last, remaining, err := paginate(key, storage.ListOptions{}, items, position)
+	require.NoError(t, err)
+	assert.Equal(t, items, page)
+	assert.Zero(t, token.ResourceVersion)
+	assert.Equal(t, int64(0), remaining)
+
+	page, _, last, remaining, err = paginate(key, storage.ListOptions{Predicate: storage.SelectionPredicate{Limit: 2}}, items, position)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, page)
+	assert.Equal(t, int64(3), remaining)
+
+	cont, err := encodeContinue(key, 7, last)
+	require.NoError(t, err)
+	page, token, _, remaining, err = paginate(key, storage.ListOptions{Predicate: storage.SelectionPredicate{Limit: 2, Continue: cont}}, items, position)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c", "d"}, page)
+	assert.Equal(t, int64(1), remaining)
+	assert.Equal(t, uint64(7), token.ResourceVersion)
+
+	_, _, _, _, err = paginate("/spdx.softwarecomposition.kubescape.io/configurationscansummaries", storage.ListOptions{Predicate: storage.SelectionPredicate{Continue: cont}}, items, position)
+	assert.True(t, apierrors.IsBadRequest(err))
+}
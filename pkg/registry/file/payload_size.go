@@ -0,0 +1,31 @@
+package file
+
+import (
+	"zombiezen.com/go/sqlite/sqlitemigration"
+)
+
+// NewPayloadSizer returns a function giving the payload sizes recorded in the
+// metadata of kind: the one of name in namespace, or the sum of the limit
+// largest ones of namespace (of every namespace when empty, of all of them
+// when limit is not positive) when name is empty. It returns 0 for missing
+// objects or when the metadata cannot be read, the size is only an estimate
+// of the memory a read of them takes.
+func NewPayloadSizer(pool *sqlitemigration.Pool) func(kind, namespace, name string, limit int64) int64 {
+	return func(kind, namespace, name string, limit int64) int64 {
+		if kind == "" || pool == nil {
+			return 0
+		}
+		ctx, cancel := poolContext()
+		defer cancel()
+		conn, err := pool.Take(ctx)
+		if err != nil {
+			return 0
+		}
+		defer pool.Put(conn)
+		size, err := readPayloadSize(conn, kind, namespace, name, limit)
+		if err != nil {
+			return 0
+		}
+		return size
+	}
+}
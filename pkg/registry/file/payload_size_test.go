@@ -0,0 +1,38 @@
+package file
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestNewPayloadSizer(t *testing.T) {
+	pool := NewTestPool(t.TempDir())
+	require.NotNil(t, pool)
+	defer func() { _ = pool.Close() }()
+	conn, err := pool.Take(context.Background())
+	require.NoError(t, err)
+	for path, size := range map[string]int64{
+		"/spdx.softwarecomposition.kubescape.io/sbomsyfts/kubescape/a":       10,
+		"/spdx.softwarecomposition.kubescape.io/sbomsyfts/kubescape/b":       20,
+		"/spdx.softwarecomposition.kubescape.io/sbomsyfts/default/c":         40,
+		"/spdx.softwarecomposition.kubescape.io/vulnerabilities/kubescape/a": 80,
+	} {
+		require.NoError(t, writeMetadata(conn, path, &unstructured.Unstructured{Object: map[string]any{}}))
+		require.NoError(t, writePayloadSize(conn, path, size, ""))
+	}
+	pool.Put(conn)
+	payloadSize := NewPayloadSizer(pool)
+
+	assert.Equal(t, int64(10), payloadSize("sbomsyfts", "kubescape", "a", 0))
+	assert.Equal(t, int64(0), payloadSize("sbomsyfts", "kubescape", "missing", 0))
+	assert.Equal(t, int64(30), payloadSize("sbomsyfts", "kubescape", "", 0))
+	assert.Equal(t, int64(70), payloadSize("sbomsyfts", "", "", 0))
+	// a page is charged its largest payloads
+	assert.Equal(t, int64(60), payloadSize("sbomsyfts", "", "", 2))
+	assert.Equal(t, int64(0), payloadSize("applicationprofiles", "", "", 0))
+	assert.Equal(t, int64(0), payloadSize("", "", "", 0))
+}
@@ -0,0 +1,161 @@
+package file
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync/atomic"
+
+	"github.com/kubescape/go-logger"
+	"github.com/kubescape/go-logger/helpers"
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"github.com/kubescape/storage/pkg/config"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"zombiezen.com/go/sqlite"
+	"zombiezen.com/go/sqlite/sqlitemigration"
+)
+
+// QuotaWildcard stands for any namespace or kind without a quota of its own.
+const QuotaWildcard = "*"
+
+// QuotaUsage is the usage of a kind in a namespace against its quota.
+type QuotaUsage struct {
+	Namespace  string `json:"namespace"`
+	Kind       string `json:"kind"`
+	Objects    int64  `json:"objects"`
+	MaxObjects int64  `json:"maxObjects,omitempty"`
+	Bytes      int64  `json:"bytes"`
+	MaxBytes   int64  `json:"maxBytes,omitempty"`
+}
+
+// Quotas caps the objects and payload bytes per namespace and kind, as
+// configured by config.Config.Quotas. A create is rejected with a 403 once the
+// namespace holds MaxObjects objects of the kind, or MaxBytes bytes of their
+// payloads: the last object admitted may take the usage over MaxBytes. The
+// usage is read from the metadata table, payloads written before it recorded
+// their sizes count for 0 bytes until updated.
+type Quotas struct {
+	quotas atomic.Pointer[map[string]map[string]config.QuotaConfig]
+}
+
+func NewQuotas(cfg *config.Config) *Quotas {
+	q := &Quotas{}
+	q.Reload(cfg)
+	return q
+}
+
+// Reload applies the quotas of cfg to the next creates.
+func (q *Quotas) Reload(cfg *config.Config) {
+	q.quotas.Store(&cfg.Quotas)
+}
+
+// limit returns the quota of kind in namespace, the most specific entry
+// winning.
+func (q *Quotas) limit(namespace, kind string) (config.QuotaConfig, bool) {
+	quotas := *q.quotas.Load()
+	for _, ns := range []string{namespace, QuotaWildcard} {
+		byKind, ok := quotas[ns]
+		if !ok {
+			continue
+		}
+		for _, k := range []string{kind, QuotaWildcard} {
+			if quota, ok := byKind[k]; ok {
+				return quota, true
+			}
+		}
+	}
+	return config.QuotaConfig{}, false
+}
+
+// check returns a Forbidden error if creating the object at key would exceed
+// the quota of its namespace.
+func (q *Quotas) check(conn *sqlite.Conn, key string) error {
+	if q == nil {
+		return nil
+	}
+	_, _, kind, _, namespace, name := K8sPathToKeys(key)
+	if namespace == "" {
+		return nil
+	}
+	quota, ok := q.limit(namespace, kind)
+	if !ok || quota.MaxObjects <= 0 && quota.MaxBytes <= 0 {
+		return nil
+	}
+	usage, err := readNamespaceUsage(conn, kind, namespace)
+	if err != nil {
+		return err
+	}
+	var reason string
+	switch {
+	case quota.MaxObjects > 0 && usage.Objects >= quota.MaxObjects:
+		reason = fmt.Sprintf("exceeded quota: namespace %s holds %d of %d %s allowed", namespace, usage.Objects, quota.MaxObjects, kind)
+	case quota.MaxBytes > 0 && usage.Bytes >= quota.MaxBytes:
+		reason = fmt.Sprintf("exceeded quota: %s of namespace %s use %d of %d bytes allowed", kind, namespace, usage.Bytes, quota.MaxBytes)
+	default:
+		return nil
+	}
+	logger.L().Debug("quota exceeded", helpers.String("key", key), helpers.String("reason", reason))
+	return apierrors.NewForbidden(softwarecomposition.Resource(kind), name, fmt.Errorf("%s", reason))
+}
+
+// Usage returns the usage against their quota of the kinds in the namespaces
+// having one: every pair holding objects and every pair named by the quotas.
+func (q *Quotas) Usage(conn *sqlite.Conn) ([]QuotaUsage, error) {
+	stored, err := listNamespaceUsage(conn)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[[2]string]bool{}
+	var usages []QuotaUsage
+	add := func(u namespaceUsage) {
+		quota, ok := q.limit(u.Namespace, u.Kind)
+		if !ok {
+			return
+		}
+		seen[[2]string{u.Namespace, u.Kind}] = true
+		usages = append(usages, QuotaUsage{
+			Namespace:  u.Namespace,
+			Kind:       u.Kind,
+			Objects:    u.Objects,
+			MaxObjects: quota.MaxObjects,
+			Bytes:      u.Bytes,
+			MaxBytes:   quota.MaxBytes,
+		})
+	}
+	for _, u := range stored {
+		add(u)
+	}
+	for namespace, byKind := range *q.quotas.Load() {
+		for kind := range byKind {
+			if namespace == QuotaWildcard || kind == QuotaWildcard || seen[[2]string{namespace, kind}] {
+				continue
+			}
+			add(namespaceUsage{Namespace: namespace, Kind: kind})
+		}
+	}
+	slices.SortFunc(usages, func(a, b QuotaUsage) int {
+		return cmp.Or(cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Kind, b.Kind))
+	})
+	return usages, nil
+}
+
+// Handler serves the Usage of the quotas as JSON.
+func (q *Quotas) Handler(pool *sqlitemigration.Pool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := pool.Take(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		defer pool.Put(conn)
+		usages, err := q.Usage(conn)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(usages)
+	})
+}
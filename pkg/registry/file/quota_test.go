@@ -0,0 +1,93 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition/v1beta1"
+	"github.com/kubescape/storage/pkg/config"
+	"github.com/kubescape/storage/pkg/generated/clientset/versioned/scheme"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestQuotas_Limit(t *testing.T) {
+	q := NewQuotas(&config.Config{Quotas: map[string]map[string]config.QuotaConfig{
+		"ci":          {"sbomsyfts": {MaxObjects: 1}, QuotaWildcard: {MaxObjects: 2}},
+		QuotaWildcard: {"sbomsyfts": {MaxObjects: 3}},
+	}})
+	tests := []struct {
+		namespace, kind string
+		want            int64
+		ok              bool
+	}{
+		{"ci", "sbomsyfts", 1, true},
+		{"ci", "vulnerabilitymanifests", 2, true},
+		{"prod", "sbomsyfts", 3, true},
+		{"prod", "vulnerabilitymanifests", 0, false},
+	}
+	for _, tt := range tests {
+		quota, ok := q.limit(tt.namespace, tt.kind)
+		assert.Equal(t, tt.ok, ok, tt.namespace+"/"+tt.kind)
+		assert.Equal(t, tt.want, quota.MaxObjects, tt.namespace+"/"+tt.kind)
+	}
+}
+
+func TestStorageImpl_CreateQuota(t *testing.T) {
+	pool := NewTestPool(t.TempDir())
+	defer func() { _ = pool.Close() }()
+	sch := scheme.Scheme
+	require.NoError(t, softwarecomposition.AddToScheme(sch))
+	s := NewStorageImpl(afero.NewMemMapFs(), DefaultStorageRoot, pool, nil, sch)
+	q := NewQuotas(&config.Config{Quotas: map[string]map[string]config.QuotaConfig{
+		"ci": {"sbomsyfts": {MaxObjects: 2}},
+	}})
+	s.SetQuotas(q)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	create := func(namespace, name string) error {
+		obj := &v1beta1.SBOMSyft{ObjectMeta: v1.ObjectMeta{Name: name, Namespace: namespace}}
+		return s.Create(ctx, "/spdx.softwarecomposition.kubescape.io/sbomsyfts/"+namespace+"/"+name, obj, &v1beta1.SBOMSyft{}, 0)
+	}
+
+	require.NoError(t, create("ci", "one"))
+	require.NoError(t, create("ci", "two"))
+	err := create("ci", "three")
+	assert.True(t, apierrors.IsForbidden(err), "got %v", err)
+	assert.Contains(t, err.Error(), "exceeded quota: namespace ci holds 2 of 2 sbomsyfts allowed")
+	// other namespaces are not limited
+	require.NoError(t, create("prod", "three"))
+
+	// tighten the quota on bytes
+	conn, err := pool.Take(ctx)
+	require.NoError(t, err)
+	usage, err := readNamespaceUsage(conn, "sbomsyfts", "ci")
+	pool.Put(conn)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), usage.Objects)
+	assert.Positive(t, usage.Bytes)
+	q.Reload(&config.Config{Quotas: map[string]map[string]config.QuotaConfig{
+		QuotaWildcard: {QuotaWildcard: {MaxBytes: usage.Bytes}},
+	}})
+	err = create("ci", "three")
+	assert.True(t, apierrors.IsForbidden(err), "got %v", err)
+
+	// usage view
+	rec := httptest.NewRecorder()
+	q.Handler(pool).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/quotas", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var usages []QuotaUsage
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &usages))
+	require.Len(t, usages, 2)
+	assert.Equal(t, QuotaUsage{Namespace: "ci", Kind: "sbomsyfts", Objects: 2, Bytes: usage.Bytes, MaxBytes: usage.Bytes}, usages[0])
+	assert.Equal(t, "prod", usages[1].Namespace)
+	assert.Equal(t, int64(1), usages[1].Objects)
+}
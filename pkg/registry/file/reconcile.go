@@ -0,0 +1,325 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/kubescape/go-logger"
+	"github.com/kubescape/go-logger/helpers"
+	helpersv1 "github.com/kubescape/k8s-interface/instanceidhandler/v1/helpers"
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"zombiezen.com/go/sqlite"
+)
+
+// QuarantineDir is the directory, under the storage root, where the payloads
+// that fail to decode are moved to, at their key.
+const QuarantineDir = ".quarantine"
+
+// ReconcileReport counts the repairs of a Reconcile pass.
+type ReconcileReport struct {
+	// TemporaryRemoved counts the leftovers of interrupted writes.
+	TemporaryRemoved int
+	// MetadataRestored counts the payloads without metadata, or whose
+	// metadata has another checksum, rewritten from the payload.
+	MetadataRestored int
+	// MetadataDeleted counts the metadata without payload.
+	MetadataDeleted int
+	// Quarantined counts the payloads failing to decode.
+	Quarantined int
+}
+
+// ReconcileOptions restricts a Reconcile pass to Resources, by default to all
+// of them but Exclude.
+type ReconcileOptions struct {
+	Resources []string
+	Exclude   []string
+}
+
+// Reconcile repairs the storage after a crash: a payload is written before
+// its metadata, so the payload wins any disagreement. Temporary files are
+// removed, the metadata without payload is deleted and only the payloads
+// modified after their metadata was written, or without metadata, are
+// decoded: their metadata is restored if missing or differing, and they are
+// moved to QuarantineDir if they fail to decode. Decoding the other payloads
+// is left to the Scrubber. Each object is checked holding its lock, on a
+// connection of its own, so it can run while serving, if the pass only checks
+// the resources its StorageImpl writes, see ReconcileBackends.
+func (s *StorageImpl) Reconcile(ctx context.Context, opts ReconcileOptions) (ReconcileReport, error) {
+	var report ReconcileReport
+	reconciled := func(resource string) bool {
+		if opts.Resources != nil {
+			return slices.Contains(opts.Resources, resource)
+		}
+		return !slices.Contains(opts.Exclude, resource)
+	}
+	// list the metadata before walking the payloads, an object created in
+	// between is then seen as a payload without metadata, checked under lock
+	var updated map[[3]string]int64
+	if err := s.withConn(func(conn *sqlite.Conn) (err error) {
+		updated, err = listMetadataUpdated(conn)
+		return err
+	}); err != nil {
+		return report, err
+	}
+	maps.DeleteFunc(updated, func(identity [3]string, _ int64) bool {
+		return !reconciled(identity[0])
+	})
+	dirs, err := s.resourceDirs(opts.Resources, opts.Exclude)
+	if err != nil {
+		return report, err
+	}
+	var payloadKeys, tmpKeys []string
+	payloads := map[[3]string]bool{}
+	for _, dir := range dirs {
+		err := afero.Walk(s.appFs, dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				// missing root or vanishing file
+				return nil
+			}
+			switch {
+			case info.IsDir():
+			case strings.HasSuffix(path, GobExt+TmpExt):
+				tmpKeys = append(tmpKeys, strings.TrimPrefix(strings.TrimSuffix(path, GobExt+TmpExt), s.root))
+			case IsPayloadFile(path):
+				key := s.keyFromPath(path)
+				identity := metadataIdentity(key)
+				payloads[identity] = true
+				if written, ok := updated[identity]; !ok || info.ModTime().UnixNano() > written {
+					payloadKeys = append(payloadKeys, key)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return report, fmt.Errorf("walk payloads: %w", err)
+		}
+	}
+	for _, key := range tmpKeys {
+		if ctx.Err() != nil {
+			return report, ctx.Err()
+		}
+		if removed, err := s.removeTemporary(ctx, key); err != nil {
+			logger.L().Warning("Reconcile - remove temporary file failed", helpers.Error(err), helpers.String("key", key))
+		} else if removed {
+			report.TemporaryRemoved++
+		}
+	}
+	for _, key := range payloadKeys {
+		if ctx.Err() != nil {
+			return report, ctx.Err()
+		}
+		if err := s.withConn(func(conn *sqlite.Conn) error {
+			return s.reconcilePayload(ctx, conn, key, &report)
+		}); err != nil {
+			logger.L().Warning("Reconcile - check payload failed", helpers.Error(err), helpers.String("key", key))
+		}
+	}
+	for identity := range updated {
+		if ctx.Err() != nil {
+			return report, ctx.Err()
+		}
+		if payloads[identity] {
+			continue
+		}
+		key := K8sKeysToPath("", softwarecomposition.GroupName, identity[0], "", identity[1], identity[2])
+		var deleted bool
+		if err := s.withConn(func(conn *sqlite.Conn) (err error) {
+			deleted, err = s.deleteOrphanMetadata(ctx, conn, key)
+			return err
+		}); err != nil {
+			logger.L().Warning("Reconcile - delete metadata failed", helpers.Error(err), helpers.String("key", key))
+		} else if deleted {
+			report.MetadataDeleted++
+		}
+	}
+	return report, nil
+}
+
+// ReconcileBackends runs a Reconcile pass on storage, the resources in
+// backends being reconciled by theirs, which lock their objects. The report
+// adds up the passes.
+func ReconcileBackends(ctx context.Context, storage StorageQuerier, backends map[string]StorageQuerier) (ReconcileReport, error) {
+	var done ReconcileReport
+	passes := []ReconcileOptions{{Exclude: slices.Sorted(maps.Keys(backends))}}
+	for _, resource := range slices.Sorted(maps.Keys(backends)) {
+		passes = append(passes, ReconcileOptions{Resources: []string{resource}})
+	}
+	for _, opts := range passes {
+		owner := storage
+		if opts.Resources != nil {
+			owner = backends[opts.Resources[0]]
+		}
+		report, err := owner.Reconcile(ctx, opts)
+		done.TemporaryRemoved += report.TemporaryRemoved
+		done.MetadataRestored += report.MetadataRestored
+		done.MetadataDeleted += report.MetadataDeleted
+		done.Quarantined += report.Quarantined
+		if err != nil {
+			return done, err
+		}
+	}
+	return done, nil
+}
+
+// metadataIdentity returns the columns identifying the metadata of key.
+func metadataIdentity(key string) [3]string {
+	_, _, kind, _, namespace, name := K8sPathToKeys(key)
+	return [3]string{kind, namespace, name}
+}
+
+func (s *StorageImpl) lockForReconcile(ctx context.Context, key string) error {
+	lockCtx, lockCancel := context.WithTimeout(ctx, lockTimeout)
+	defer lockCancel()
+	if err := s.locks.Lock(lockCtx, key); err != nil {
+		return newContentionTimeoutError("reconcile", key, err)
+	}
+	return nil
+}
+
+// removeTemporary removes the temporary payload of key, left by a write
+// interrupted since writes hold the lock until it is renamed.
+func (s *StorageImpl) removeTemporary(ctx context.Context, key string) (bool, error) {
+	if err := s.lockForReconcile(ctx, key); err != nil {
+		return false, err
+	}
+	defer s.locks.Unlock(key)
+	err := s.appFs.Remove(makePayloadPath(filepath.Join(s.root, key)) + TmpExt)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// reconcilePayload checks the payload of key decodes and agrees with its
+// metadata.
+func (s *StorageImpl) reconcilePayload(ctx context.Context, conn *sqlite.Conn, key string, report *ReconcileReport) error {
+	_, _, resource, _, _, _ := K8sPathToKeys(key)
+	obj, err := s.newObjectForResource(resource)
+	if err != nil {
+		// kinds no longer served are left to the cleanup
+		return nil
+	}
+	if err := s.lockForReconcile(ctx, key); err != nil {
+		return err
+	}
+	defer s.locks.Unlock(key)
+	metadataJSON, err := ReadMetadata(conn, key)
+	if err != nil && !errors.Is(err, ErrMetadataNotFound) {
+		return err
+	}
+	if _, err := s.readPayload(key, obj); err != nil {
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			return nil
+		case isLegacyGobError(err):
+			// migrated when read
+			return nil
+		}
+		logger.L().Warning("Reconcile - payload failed to decode", helpers.Error(err), helpers.String("key", key))
+		if err := s.quarantine(conn, key); err != nil {
+			return err
+		}
+		report.Quarantined++
+		return nil
+	}
+	checksum := obj.(metav1.Object).GetAnnotations()[helpersv1.SyncChecksumMetadataKey]
+	if metadataJSON != nil {
+		stored, err := s.newObjectForResource(resource)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(metadataJSON, stored); err == nil && stored.(metav1.Object).GetAnnotations()[helpersv1.SyncChecksumMetadataKey] == checksum {
+			// skip the payload next time
+			return touchMetadata(conn, key)
+		}
+	}
+	info, err := s.appFs.Stat(makePayloadPath(filepath.Join(s.root, key)))
+	if err != nil {
+		return err
+	}
+	digest, err := s.payloadDigest(key)
+	if err != nil {
+		return err
+	}
+	metadata := extractFields(obj, []string{"ObjectMeta", "SchemaVersion"})
+	if err := s.writeIndexes(conn, key, obj, metadata, info.Size(), digest); err != nil {
+		return err
+	}
+	if _, err := bumpRevision(conn); err != nil {
+		return err
+	}
+	logger.L().Info("Reconcile - metadata restored from payload", helpers.String("key", key), helpers.String("checksum", checksum))
+	if metadataJSON == nil {
+		s.watchDispatcher.Added(key, metadata, obj)
+	} else {
+		s.watchDispatcher.Modified(key, metadata, obj)
+	}
+	report.MetadataRestored++
+	return nil
+}
+
+// deleteOrphanMetadata deletes the metadata of key if it still has no payload.
+func (s *StorageImpl) deleteOrphanMetadata(ctx context.Context, conn *sqlite.Conn, key string) (bool, error) {
+	if err := s.lockForReconcile(ctx, key); err != nil {
+		return false, err
+	}
+	defer s.locks.Unlock(key)
+	if exists, _ := afero.Exists(s.appFs, makePayloadPath(filepath.Join(s.root, key))); exists {
+		return false, nil
+	}
+	if err := DeleteMetadata(conn, key, nil); err != nil {
+		return false, err
+	}
+	logger.L().Info("Reconcile - metadata without payload deleted", helpers.String("key", key))
+	return true, nil
+}
+
+// quarantine moves the payload of key to QuarantineDir and deletes its
+// metadata, the caller holding its lock. Watchers see the object deleted.
+func (s *StorageImpl) quarantine(conn *sqlite.Conn, key string) error {
+	p := filepath.Join(s.root, QuarantineDir, key)
+	if err := s.appFs.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return fmt.Errorf("mkdir: %w", err)
+	}
+	if err := s.appFs.Rename(makePayloadPath(filepath.Join(s.root, key)), makePayloadPath(p)); err != nil {
+		return fmt.Errorf("move payload: %w", err)
+	}
+	_, _, resource, _, _, _ := K8sPathToKeys(key)
+	metaOut, _ := s.newObjectForResource(resource)
+	if err := DeleteMetadata(conn, key, metaOut); err != nil {
+		return err
+	}
+	if IsContainerProfileKind(resource) {
+		if err := DeleteTimeSeriesContainerEntries(conn, key); err != nil {
+			return fmt.Errorf("delete time series entries: %w", err)
+		}
+	}
+	if metaOut != nil && metaOut.(metav1.Object).GetName() != "" {
+		s.watchDispatcher.Deleted(key, metaOut)
+	}
+	logger.L().Warning("payload quarantined", helpers.String("key", key), helpers.String("path", makePayloadPath(p)))
+	return nil
+}
+
+// newObjectForResource returns an empty object of the kind served as resource,
+// in the internal version payloads and metadata are stored in.
+func (s *StorageImpl) newObjectForResource(resource string) (runtime.Object, error) {
+	for kind := range s.scheme.KnownTypes(softwarecomposition.SchemeGroupVersion) {
+		gvk := softwarecomposition.SchemeGroupVersion.WithKind(kind)
+		if plural, _ := meta.UnsafeGuessKindToResource(gvk); plural.Resource == resource {
+			return s.scheme.New(gvk)
+		}
+	}
+	return nil, fmt.Errorf("unknown resource %s", resource)
+}
@@ -0,0 +1,136 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	helpersv1 "github.com/kubescape/k8s-interface/instanceidhandler/v1/helpers"
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"github.com/kubescape/storage/pkg/generated/clientset/versioned/scheme"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/storage"
+	"zombiezen.com/go/sqlite/sqlitex"
+)
+
+func TestStorageImpl_Reconcile(t *testing.T) {
+	pool := NewTestPool(t.TempDir())
+	defer func() { _ = pool.Close() }()
+	sch := scheme.Scheme
+	require.NoError(t, softwarecomposition.AddToScheme(sch))
+	fs := afero.NewMemMapFs()
+	s := NewStorageImpl(fs, DefaultStorageRoot, pool, nil, sch).(*StorageImpl)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	key := func(name string) string {
+		return "/spdx.softwarecomposition.kubescape.io/sbomsyfts/default/" + name
+	}
+	payload := func(name string) string {
+		return makePayloadPath(filepath.Join(DefaultStorageRoot, key(name)))
+	}
+	for _, name := range []string{"ok", "orphan", "stale", "missing", "corrupt", "rotten"} {
+		obj := &softwarecomposition.SBOMSyft{ObjectMeta: v1.ObjectMeta{Name: name, Namespace: "default"}}
+		require.NoError(t, s.Create(ctx, key(name), obj, &softwarecomposition.SBOMSyft{}, 0))
+		// writes leave no temporary file behind
+		exists, err := afero.Exists(fs, payload(name)+TmpExt)
+		require.NoError(t, err)
+		require.False(t, exists)
+	}
+
+	conn, err := pool.Take(ctx)
+	require.NoError(t, err)
+	// interrupted write
+	require.NoError(t, afero.WriteFile(fs, payload("ok")+TmpExt, []byte("partial"), 0644))
+	// payload without metadata
+	require.NoError(t, DeleteMetadata(conn, key("orphan"), nil))
+	// metadata of another revision, written before the payload
+	stale := &softwarecomposition.SBOMSyft{ObjectMeta: v1.ObjectMeta{Name: "stale", Namespace: "default", Annotations: map[string]string{helpersv1.SyncChecksumMetadataKey: "old"}}}
+	require.NoError(t, writeMetadata(conn, key("stale"), stale))
+	require.NoError(t, sqlitex.Execute(conn, `UPDATE metadata SET updated = 0 WHERE name = 'stale'`, nil))
+	// metadata without payload
+	require.NoError(t, fs.Remove(payload("missing")))
+	// payload failing to decode
+	require.NoError(t, afero.WriteFile(fs, payload("corrupt"), []byte("\x07garbage"), 0644))
+	// payload older than its metadata, left to the scrubber
+	require.NoError(t, afero.WriteFile(fs, payload("rotten"), []byte("garbage"), 0644))
+	require.NoError(t, touchMetadata(conn, key("rotten")))
+	pool.Put(conn)
+
+	report, err := s.Reconcile(ctx, ReconcileOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, ReconcileReport{TemporaryRemoved: 1, MetadataRestored: 2, MetadataDeleted: 1, Quarantined: 1}, report)
+
+	conn, err = pool.Take(ctx)
+	require.NoError(t, err)
+	defer pool.Put(conn)
+	keys, err := listAllMetadataKeys(conn)
+	require.NoError(t, err)
+	assert.Equal(t, []string{key("ok"), key("orphan"), key("rotten"), key("stale")}, keys)
+	metadataJSON, err := ReadMetadata(conn, key("stale"))
+	require.NoError(t, err)
+	var metadata softwarecomposition.SBOMSyft
+	require.NoError(t, json.Unmarshal(metadataJSON, &metadata))
+	assert.NotEqual(t, "old", metadata.Annotations[helpersv1.SyncChecksumMetadataKey])
+	exists, err := afero.Exists(fs, payload("ok")+TmpExt)
+	require.NoError(t, err)
+	assert.False(t, exists)
+	exists, err = afero.Exists(fs, makePayloadPath(filepath.Join(DefaultStorageRoot, QuarantineDir, key("corrupt"))))
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	// a second pass finds nothing to repair
+	report, err = s.Reconcile(ctx, ReconcileOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, ReconcileReport{}, report)
+}
+
+func TestReconcileBackends(t *testing.T) {
+	pool := NewTestPool(t.TempDir())
+	defer func() { _ = pool.Close() }()
+	sch := scheme.Scheme
+	require.NoError(t, softwarecomposition.AddToScheme(sch))
+	fs := afero.NewMemMapFs()
+	s := NewStorageImpl(fs, DefaultStorageRoot, pool, nil, sch).(*StorageImpl)
+	backend := NewStorageImpl(fs, DefaultStorageRoot, pool, nil, sch).(*StorageImpl)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	key := "/spdx.softwarecomposition.kubescape.io/applicationprofiles/default/nginx"
+	payload := makePayloadPath(filepath.Join(DefaultStorageRoot, key))
+	profile := &softwarecomposition.ApplicationProfile{ObjectMeta: v1.ObjectMeta{Name: "nginx", Namespace: "default"}}
+	require.NoError(t, backend.Create(ctx, key, profile, &softwarecomposition.ApplicationProfile{}, 0))
+	// an interrupted write of another resource
+	sbomKey := "/spdx.softwarecomposition.kubescape.io/sbomsyfts/default/nginx"
+	require.NoError(t, s.Create(ctx, sbomKey, &softwarecomposition.SBOMSyft{ObjectMeta: v1.ObjectMeta{Name: "nginx", Namespace: "default"}}, &softwarecomposition.SBOMSyft{}, 0))
+	require.NoError(t, afero.WriteFile(fs, makePayloadPath(filepath.Join(DefaultStorageRoot, sbomKey))+TmpExt, []byte("partial"), 0644))
+
+	// the backend writes the profile during the pass, its temporary payload
+	// is not yet renamed
+	require.NoError(t, backend.locks.Lock(ctx, key))
+	profile.Spec.Architectures = []string{"amd64"}
+	size, digest, err := backend.writePayload(payload, profile)
+	require.NoError(t, err)
+	require.NoError(t, fs.Rename(payload, payload+TmpExt))
+	reconciled := make(chan ReconcileReport)
+	go func() {
+		report, err := ReconcileBackends(ctx, s, map[string]StorageQuerier{"applicationprofiles": backend})
+		assert.NoError(t, err)
+		reconciled <- report
+	}()
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, fs.Rename(payload+TmpExt, payload))
+	conn, err := pool.Take(ctx)
+	require.NoError(t, err)
+	require.NoError(t, backend.writeIndexes(conn, key, profile, extractFields(profile, []string{"ObjectMeta", "SchemaVersion"}), size, digest))
+	pool.Put(conn)
+	backend.locks.Unlock(key)
+
+	assert.Equal(t, ReconcileReport{TemporaryRemoved: 1}, <-reconciled)
+	got := &softwarecomposition.ApplicationProfile{}
+	require.NoError(t, backend.Get(ctx, key, storage.GetOptions{}, got))
+	assert.Equal(t, []string{"amd64"}, got.Spec.Architectures)
+}
@@ -0,0 +1,668 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kubescape/go-logger"
+	"github.com/kubescape/go-logger/helpers"
+	helpersv1 "github.com/kubescape/k8s-interface/instanceidhandler/v1/helpers"
+	"github.com/kubescape/storage/pkg/config"
+	"github.com/kubescape/storage/pkg/registry/file/legacygob"
+	"github.com/spf13/afero"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+	"zombiezen.com/go/sqlite"
+)
+
+// sweepLockTimeout is how long the migration sweeper waits for the lock of an
+// object, a busy object being left to the next pass.
+var sweepLockTimeout = 100 * time.Millisecond
+
+// sweepLogInterval is how often the migration sweeper logs its progress.
+var sweepLogInterval = time.Minute
+
+var (
+	schemaMigrationsTotal = metrics.NewCounterVec(&metrics.CounterOpts{
+		Subsystem:      "storage_schema",
+		Name:           "migrations_total",
+		Help:           "Number of schema migrations applied to stored objects, by resource and migration.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"resource", "migration"})
+	schemaMigrationFailuresTotal = metrics.NewCounterVec(&metrics.CounterOpts{
+		Subsystem:      "storage_schema",
+		Name:           "migration_failures_total",
+		Help:           "Number of stored objects failing to migrate, by resource.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"resource"})
+	registerSchemaMigrationMetrics sync.Once
+)
+
+// SchemaMigration migrates the stored objects of Resources. Migrate moves a
+// decoded object from schema version From to From+1, Decode instead decodes a
+// payload the current types fail to decode, whatever its schema version.
+// Exactly one of them is set.
+type SchemaMigration struct {
+	Name      string
+	Resources []string
+	From      int64
+	Migrate   func(obj runtime.Object) error
+	Decode    func(data []byte, obj runtime.Object) error
+}
+
+// SchemaMigrations is a registry of SchemaMigration, by resource, bringing the
+// stored objects to the current schema version. A nil SchemaMigrations
+// migrates nothing.
+type SchemaMigrations struct {
+	current  int64
+	steps    map[string]map[int64]SchemaMigration
+	decoders map[string][]SchemaMigration
+}
+
+// NewSchemaMigrations returns the registry of migrations to schema version
+// current, the migrations of a resource having to chain without gaps.
+func NewSchemaMigrations(current int64, migrations ...SchemaMigration) (*SchemaMigrations, error) {
+	m := &SchemaMigrations{
+		current:  current,
+		steps:    map[string]map[int64]SchemaMigration{},
+		decoders: map[string][]SchemaMigration{},
+	}
+	for _, migration := range migrations {
+		if migration.Name == "" {
+			return nil, errors.New("schema migration without a name")
+		}
+		if len(migration.Resources) == 0 {
+			return nil, fmt.Errorf("schema migration %s has no resources", migration.Name)
+		}
+		if (migration.Migrate == nil) == (migration.Decode == nil) {
+			return nil, fmt.Errorf("schema migration %s needs exactly one of Migrate and Decode", migration.Name)
+		}
+		for _, resource := range migration.Resources {
+			if migration.Decode != nil {
+				m.decoders[resource] = append(m.decoders[resource], migration)
+				continue
+			}
+			if migration.From < 0 || migration.From >= current {
+				return nil, fmt.Errorf("schema migration %s from version %d, the current one being %d", migration.Name, migration.From, current)
+			}
+			if m.steps[resource] == nil {
+				m.steps[resource] = map[int64]SchemaMigration{}
+			}
+			if other, ok := m.steps[resource][migration.From]; ok {
+				return nil, fmt.Errorf("schema migrations %s and %s both migrate %s from version %d", other.Name, migration.Name, resource, migration.From)
+			}
+			m.steps[resource][migration.From] = migration
+		}
+	}
+	for resource, steps := range m.steps {
+		for from := range steps {
+			for v := from + 1; v < current; v++ {
+				if _, ok := steps[v]; !ok {
+					return nil, fmt.Errorf("no schema migration of %s from version %d", resource, v)
+				}
+			}
+		}
+	}
+	return m, nil
+}
+
+// DefaultSchemaMigrations returns the migrations of the objects stored by the
+// previous releases to SchemaVersion.
+func DefaultSchemaMigrations() *SchemaMigrations {
+	m, err := NewSchemaMigrations(SchemaVersion,
+		// uint64 fields became int64, which gob refuses to decode
+		SchemaMigration{
+			Name:      "legacy-gob-uint64",
+			Resources: []string{"applicationprofiles", "containerprofiles", "seccompprofiles"},
+			Decode:    legacygob.Decode,
+		},
+	)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// migrates tells if resource has migrations.
+func (m *SchemaMigrations) migrates(resource string) bool {
+	return m != nil && (len(m.steps[resource]) > 0 || len(m.decoders[resource]) > 0)
+}
+
+// Resources returns the resources having migrations, sorted.
+func (m *SchemaMigrations) Resources() []string {
+	if m == nil {
+		return nil
+	}
+	resources := slices.Collect(maps.Keys(m.steps))
+	for resource := range m.decoders {
+		if !slices.Contains(resources, resource) {
+			resources = append(resources, resource)
+		}
+	}
+	slices.Sort(resources)
+	return resources
+}
+
+// CanMigrate tells if the objects of resource stored with schema version are
+// current or can be migrated to it.
+func (m *SchemaMigrations) CanMigrate(resource string, version int64) bool {
+	current := SchemaVersion
+	if m != nil {
+		current = m.current
+	}
+	if version == current {
+		return true
+	}
+	if m == nil || version > current {
+		return false
+	}
+	_, ok := m.steps[resource][version]
+	return ok
+}
+
+// Pending tells if obj, of resource, has migrations to apply.
+func (m *SchemaMigrations) Pending(resource string, obj runtime.Object) bool {
+	if m == nil {
+		return false
+	}
+	version, ok := schemaVersion(obj)
+	if !ok || version >= m.current {
+		return false
+	}
+	_, ok = m.steps[resource][version]
+	return ok
+}
+
+// Migrate applies the pending migrations of obj, of resource, returning their
+// names. obj is left at the schema version of the last one applied if one
+// fails.
+func (m *SchemaMigrations) Migrate(resource string, obj runtime.Object) ([]string, error) {
+	var applied []string
+	for m.Pending(resource, obj) {
+		version, _ := schemaVersion(obj)
+		step := m.steps[resource][version]
+		if err := step.Migrate(obj); err != nil {
+			return applied, fmt.Errorf("schema migration %s: %w", step.Name, err)
+		}
+		setSchemaVersion(obj, version+1)
+		applied = append(applied, step.Name)
+	}
+	return applied, nil
+}
+
+// Decode decodes data, the payload of an object of resource the current types
+// failed to decode, into obj, returning the name of the migration which did.
+func (m *SchemaMigrations) Decode(resource string, data []byte, obj runtime.Object) (string, error) {
+	if m == nil || len(m.decoders[resource]) == 0 {
+		return "", fmt.Errorf("no schema migration decodes %s", resource)
+	}
+	var errs []error
+	for _, decoder := range m.decoders[resource] {
+		err := decoder.Decode(data, obj)
+		if err == nil {
+			return decoder.Name, nil
+		}
+		errs = append(errs, fmt.Errorf("schema migration %s: %w", decoder.Name, err))
+	}
+	return "", errors.Join(errs...)
+}
+
+// schemaVersion returns the schema version of obj, if its kind has one.
+func schemaVersion(obj runtime.Object) (int64, bool) {
+	field := reflect.ValueOf(obj).Elem().FieldByName("SchemaVersion")
+	if !field.IsValid() || field.Kind() != reflect.Int64 {
+		return 0, false
+	}
+	return field.Int(), true
+}
+
+func setSchemaVersion(obj runtime.Object, version int64) {
+	field := reflect.ValueOf(obj).Elem().FieldByName("SchemaVersion")
+	if field.IsValid() && field.Kind() == reflect.Int64 {
+		field.SetInt(version)
+	}
+}
+
+// hasParts tells if obj is aggregated from parts, its checksum being theirs.
+func hasParts(obj runtime.Object) bool {
+	parts := reflect.ValueOf(obj).Elem().FieldByName("Parts")
+	return parts.IsValid() && parts.Kind() == reflect.Map && parts.Len() > 0
+}
+
+// SetSchemaMigrations migrates the objects read with migrations, nil disables
+// them. In dryRun the migrations are only reported by SweepMigrations, nothing
+// they change is written. It must be called before the storage serves any
+// request.
+func (s *StorageImpl) SetSchemaMigrations(migrations *SchemaMigrations, dryRun bool) {
+	s.migrations = migrations
+	s.migrationDryRun = dryRun
+}
+
+// rewritePayload rewrites the payload of key, obj decoded from format, in the
+// format of its codec. In dry-run nothing is written. The caller must hold the
+// write lock for key.
+func (s *StorageImpl) rewritePayload(conn *sqlite.Conn, key string, obj runtime.Object, format PayloadFormat) error {
+	if s.migrationDryRun {
+		return nil
+	}
+	size, digest, err := s.writePayload(makePayloadPath(filepath.Join(s.root, key)), obj)
+	if err != nil {
+		return err
+	}
+	if err := writePayloadSize(conn, key, size, digest); err != nil {
+		return err
+	}
+	logger.L().Debug("payload format upgraded", helpers.String("key", key), helpers.String("from", format.String()), helpers.String("to", s.codecFor(obj).Format().String()))
+	return nil
+}
+
+// decodeLegacyPayload decodes the payload of key, which the current types
+// failed to decode, into obj through the schema migrations, returning the
+// name of the one which did.
+func (s *StorageImpl) decodeLegacyPayload(key string, obj runtime.Object) (string, error) {
+	payloadFile, err := s.openPayloadFileWithFallback(makePayloadPath(filepath.Join(s.root, key)), os.O_RDONLY, 0)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = payloadFile.Close()
+	}()
+	data, err := io.ReadAll(NewDirectIOReader(payloadFile))
+	if err != nil {
+		return "", err
+	}
+	_, _, resource, _, _, _ := K8sPathToKeys(key)
+	return s.migrations.Decode(resource, data, obj)
+}
+
+// migrateSchema applies the pending schema migrations to obj, decoded from
+// the payload of key, and saves it if any was, returning their names. In
+// dry-run nothing is migrated. The caller must hold the write lock for key.
+func (s *StorageImpl) migrateSchema(conn *sqlite.Conn, key string, obj runtime.Object) ([]string, error) {
+	_, _, resource, _, _, _ := K8sPathToKeys(key)
+	if s.migrationDryRun || !s.migrations.Pending(resource, obj) {
+		return nil, nil
+	}
+	from, _ := schemaVersion(obj)
+	applied, err := s.migrations.Migrate(resource, obj)
+	if err != nil {
+		schemaMigrationFailuresTotal.WithLabelValues(resource).Inc()
+		return nil, err
+	}
+	// an aggregated checksum is the one of the parts
+	checksum := ""
+	if hasParts(obj) {
+		checksum = obj.(metav1.Object).GetAnnotations()[helpersv1.SyncChecksumMetadataKey]
+	}
+	metaOut := reflect.New(reflect.TypeOf(obj).Elem()).Interface().(runtime.Object)
+	if err := s.saveObject(conn, key, obj, metaOut, checksum); err != nil {
+		return nil, err
+	}
+	s.watchDispatcher.Modified(key, metaOut, obj)
+	for _, name := range applied {
+		schemaMigrationsTotal.WithLabelValues(resource, name).Inc()
+	}
+	to, _ := schemaVersion(obj)
+	logger.L().Info("schema migrated", helpers.String("key", key), helpers.Interface("migrations", applied), helpers.Int("from", int(from)), helpers.Int("to", int(to)))
+	return applied, nil
+}
+
+// migrateInMemory applies the pending schema migrations to obj, decoded from
+// the payload of key, without writing anything. In dry-run nothing is
+// migrated.
+func (s *StorageImpl) migrateInMemory(ctx context.Context, key string, obj runtime.Object) {
+	if s.migrationDryRun {
+		return
+	}
+	_, _, resource, _, _, _ := K8sPathToKeys(key)
+	if _, err := s.migrations.Migrate(resource, obj); err != nil {
+		logger.L().Ctx(ctx).Warning("schema migration failed", helpers.Error(err), helpers.String("key", key))
+	}
+}
+
+// upgradePayload migrates the object of key, obj decoded from it, see
+// migrateSchema, or rewrites its payload if only its format is outdated, see
+// rewritePayload. The payload is decoded again, it may have been written
+// meanwhile, and obj is set to the object upgraded. A failure leaves it to
+// the next read or to the MigrationSweeper. The caller must hold the write
+// lock for key.
+func (s *StorageImpl) upgradePayload(ctx context.Context, conn *sqlite.Conn, key string, obj runtime.Object) {
+	current := reflect.New(reflect.TypeOf(obj).Elem()).Interface().(runtime.Object)
+	format, err := s.readPayload(key, current)
+	if err != nil {
+		logger.L().Ctx(ctx).Debug("payload upgrade skipped", helpers.Error(err), helpers.String("key", key))
+		return
+	}
+	applied, err := s.migrateSchema(conn, key, current)
+	if err == nil && len(applied) == 0 && format != s.codecFor(current).Format() {
+		err = s.rewritePayload(conn, key, current, format)
+	}
+	if err != nil {
+		logger.L().Ctx(ctx).Warning("payload upgrade failed", helpers.Error(err), helpers.String("key", key))
+	}
+	reflect.ValueOf(obj).Elem().Set(reflect.ValueOf(current).Elem())
+}
+
+// decodeLegacyObject decodes the legacy payload of key into obj through the
+// decoding schema migrations and migrates it, without writing anything.
+func (s *StorageImpl) decodeLegacyObject(ctx context.Context, key string, obj runtime.Object) error {
+	if _, err := s.decodeLegacyPayload(key, obj); err != nil {
+		return err
+	}
+	s.migrateInMemory(ctx, key, obj)
+	return nil
+}
+
+// migrateLegacyPayload decodes the legacy payload of key into obj, migrates
+// it, see migrateSchema, and otherwise rewrites it, see rewritePayload. The
+// names of the migrations applied are returned, starting with the one which
+// decoded it, empty if none did. The caller must hold the write lock for key.
+func (s *StorageImpl) migrateLegacyPayload(conn *sqlite.Conn, key string, obj runtime.Object) ([]string, error) {
+	_, _, resource, _, _, _ := K8sPathToKeys(key)
+	name, err := s.decodeLegacyPayload(key, obj)
+	if err != nil {
+		schemaMigrationFailuresTotal.WithLabelValues(resource).Inc()
+		return nil, err
+	}
+	applied, err := s.migrateSchema(conn, key, obj)
+	if err == nil && len(applied) == 0 {
+		err = s.rewritePayload(conn, key, obj, payloadFormatLegacy)
+	}
+	applied = append([]string{name}, applied...)
+	if err != nil {
+		return applied, err
+	}
+	if !s.migrationDryRun {
+		schemaMigrationsTotal.WithLabelValues(resource, name).Inc()
+		logger.L().Info("legacy payload migrated", helpers.String("key", key), helpers.String("migration", name))
+	}
+	return applied, nil
+}
+
+// SweepOptions tunes a SweepMigrations pass.
+type SweepOptions struct {
+	// Rate caps the objects checked per second, 0 for no limit.
+	Rate int
+	// Resources restricts the pass to these resources, by default all of
+	// them but Exclude.
+	Resources []string
+	Exclude   []string
+	// Progress, if not nil, is called after every object checked.
+	Progress func(SweepProgress)
+}
+
+// SweepProgress counts the objects of a SweepMigrations pass, or of the
+// passes of a MigrationSweeper so far. Migrated counts the objects migrated
+// and Migrations the migrations applied, in DryRun those which would have
+// been.
+type SweepProgress struct {
+	Total      int            `json:"total"`
+	Checked    int            `json:"checked"`
+	Migrated   int            `json:"migrated"`
+	Failed     int            `json:"failed"`
+	Skipped    int            `json:"skipped"`
+	Migrations map[string]int `json:"migrations,omitempty"`
+	DryRun     bool           `json:"dryRun"`
+	Done       bool           `json:"done"`
+}
+
+// SweepMigrations migrates every stored object having schema migrations and
+// rewrites the payloads in an outdated format, as reads do for the objects
+// they serve. The payloads of the resources without migrations are only
+// decoded if their format is outdated. An object whose lock is busy is
+// skipped, as are all of them under disk pressure, for the next pass. In
+// dry-run the migrations are applied to the decoded objects only and nothing
+// is written.
+func (s *StorageImpl) SweepMigrations(ctx context.Context, opts SweepOptions) (SweepProgress, error) {
+	progress := SweepProgress{DryRun: s.migrationDryRun, Migrations: map[string]int{}}
+	dirs, err := s.resourceDirs(opts.Resources, opts.Exclude)
+	if err != nil {
+		return progress, err
+	}
+	var keys []string
+	for _, dir := range dirs {
+		err := afero.Walk(s.appFs, dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				// missing root or vanishing file
+				return nil
+			}
+			if !info.IsDir() && IsPayloadFile(path) {
+				keys = append(keys, s.keyFromPath(path))
+			}
+			return nil
+		})
+		if err != nil {
+			return progress, fmt.Errorf("walk payloads: %w", err)
+		}
+	}
+	progress.Total = len(keys)
+	var tick <-chan time.Time
+	if opts.Rate > 0 {
+		ticker := time.NewTicker(time.Second / time.Duration(opts.Rate))
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for _, key := range keys {
+		if tick != nil {
+			select {
+			case <-ctx.Done():
+				return progress, ctx.Err()
+			case <-tick:
+			}
+		} else if ctx.Err() != nil {
+			return progress, ctx.Err()
+		}
+		applied, skipped, err := s.sweepPayload(ctx, key)
+		switch {
+		case skipped:
+			logger.L().Debug("SweepMigrations - object skipped", helpers.Error(err), helpers.String("key", key))
+			progress.Skipped++
+		case err != nil:
+			logger.L().Warning("SweepMigrations - object failed to migrate", helpers.Error(err), helpers.String("key", key))
+			progress.Checked++
+			progress.Failed++
+		default:
+			progress.Checked++
+			if len(applied) > 0 {
+				progress.Migrated++
+			}
+			for _, name := range applied {
+				progress.Migrations[name]++
+			}
+		}
+		if opts.Progress != nil {
+			opts.Progress(progress)
+		}
+	}
+	progress.Done = true
+	return progress, nil
+}
+
+// sweepPayload migrates the object of key and rewrites its payload if
+// outdated, returning the names of the migrations applied, or whether it was
+// skipped.
+func (s *StorageImpl) sweepPayload(ctx context.Context, key string) ([]string, bool, error) {
+	_, _, resource, _, _, _ := K8sPathToKeys(key)
+	obj, err := s.newObjectForResource(resource)
+	if err != nil {
+		return nil, true, err
+	}
+	if !s.migrations.migrates(resource) {
+		// the header tells if the payload is current, without decoding it
+		format, err := s.readPayloadFormat(makePayloadPath(filepath.Join(s.root, key)))
+		if err != nil {
+			return nil, true, err
+		}
+		if format == s.codecFor(obj).Format() || s.migrationDryRun {
+			return nil, false, nil
+		}
+	}
+	lockCtx, lockCancel := context.WithTimeout(ctx, sweepLockTimeout)
+	defer lockCancel()
+	if err := s.locks.Lock(lockCtx, key); err != nil {
+		return nil, true, fmt.Errorf("object busy: %w", err)
+	}
+	defer s.locks.Unlock(key)
+	format, err := s.readPayload(key, obj)
+	legacy := err != nil && isLegacyGobError(err)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		// deleted meanwhile
+		return nil, true, err
+	case legacy && s.migrationDryRun:
+		name, err := s.decodeLegacyPayload(key, obj)
+		if err != nil {
+			return nil, false, err
+		}
+		applied, err := s.migrations.Migrate(resource, obj)
+		return append([]string{name}, applied...), false, err
+	case legacy:
+	case err != nil:
+		// left to the scrubber
+		return nil, true, err
+	case s.migrationDryRun:
+		applied, err := s.migrations.Migrate(resource, obj)
+		return applied, false, err
+	case format == s.codecFor(obj).Format() && !s.migrations.Pending(resource, obj):
+		return nil, false, nil
+	}
+	if err := s.diskMonitor.checkWrite(key); err != nil {
+		return nil, true, err
+	}
+	poolCtx, cancel := poolContext()
+	defer cancel()
+	conn, err := s.pool.Take(poolCtx)
+	if err != nil {
+		return nil, true, newContentionTimeoutError("sweep migrations", key, err)
+	}
+	defer s.pool.Put(conn)
+	if legacy {
+		applied, err := s.migrateLegacyPayload(conn, key, obj)
+		return applied, false, err
+	}
+	applied, err := s.migrateSchema(conn, key, obj)
+	if err == nil && len(applied) == 0 {
+		err = s.rewritePayload(conn, key, obj, format)
+	}
+	return applied, false, err
+}
+
+// add returns the counts of p and other summed.
+func (p SweepProgress) add(other SweepProgress) SweepProgress {
+	migrations := maps.Clone(p.Migrations)
+	if migrations == nil {
+		migrations = map[string]int{}
+	}
+	for name, count := range other.Migrations {
+		migrations[name] += count
+	}
+	return SweepProgress{
+		Total:      p.Total + other.Total,
+		Checked:    p.Checked + other.Checked,
+		Migrated:   p.Migrated + other.Migrated,
+		Failed:     p.Failed + other.Failed,
+		Skipped:    p.Skipped + other.Skipped,
+		Migrations: migrations,
+		DryRun:     p.DryRun || other.DryRun,
+	}
+}
+
+// MigrationSweeper runs a SweepMigrations pass over the stored objects at
+// startup, at MigrationSweepRate, logging and serving its progress.
+type MigrationSweeper struct {
+	storage  StorageQuerier
+	backends map[string]StorageQuerier
+	rate     int
+	progress atomic.Pointer[SweepProgress]
+}
+
+// NewMigrationSweeper returns a MigrationSweeper of storage, the resources in
+// backends being swept by theirs, which lock their objects.
+func NewMigrationSweeper(storage StorageQuerier, backends map[string]StorageQuerier, cfg *config.Config) *MigrationSweeper {
+	registerSchemaMigrationMetrics.Do(func() {
+		legacyregistry.MustRegister(schemaMigrationsTotal, schemaMigrationFailuresTotal)
+	})
+	ms := &MigrationSweeper{
+		storage:  storage,
+		backends: backends,
+		rate:     cfg.MigrationSweepRate,
+	}
+	ms.progress.Store(&SweepProgress{DryRun: cfg.MigrationDryRun})
+	return ms
+}
+
+// Run sweeps the stored objects once, or until ctx is done.
+func (ms *MigrationSweeper) Run(ctx context.Context) {
+	start := time.Now()
+	lastLog := start
+	done := *ms.progress.Load()
+	// the resources having a backend are swept by it, the others at once
+	passes := []SweepOptions{{Exclude: slices.Sorted(maps.Keys(ms.backends))}}
+	for _, resource := range slices.Sorted(maps.Keys(ms.backends)) {
+		passes = append(passes, SweepOptions{Resources: []string{resource}})
+	}
+	for _, opts := range passes {
+		storage := ms.storage
+		if opts.Resources != nil {
+			storage = ms.backends[opts.Resources[0]]
+		}
+		opts.Rate = ms.rate
+		opts.Progress = func(progress SweepProgress) {
+			total := done.add(progress)
+			ms.progress.Store(&total)
+			if time.Since(lastLog) < sweepLogInterval {
+				return
+			}
+			lastLog = time.Now()
+			logger.L().Info("migration sweep in progress",
+				helpers.Int("total", total.Total),
+				helpers.Int("checked", total.Checked),
+				helpers.Int("migrated", total.Migrated),
+				helpers.Int("failed", total.Failed))
+		}
+		progress, err := storage.SweepMigrations(ctx, opts)
+		done = done.add(progress)
+		ms.progress.Store(&done)
+		if err != nil {
+			logger.L().Warning("migration sweep interrupted", helpers.Error(err), helpers.Interface("resources", opts.Resources), helpers.Int("checked", done.Checked))
+			return
+		}
+	}
+	done.Done = true
+	ms.progress.Store(&done)
+	logger.L().Info("migration sweep done",
+		helpers.Int("checked", done.Checked),
+		helpers.Int("migrated", done.Migrated),
+		helpers.Int("failed", done.Failed),
+		helpers.Int("skipped", done.Skipped),
+		helpers.Interface("migrations", done.Migrations),
+		helpers.String("dryRun", fmt.Sprintf("%t", done.DryRun)),
+		helpers.String("duration", time.Since(start).String()))
+}
+
+// Progress returns the progress of the sweep.
+func (ms *MigrationSweeper) Progress() SweepProgress {
+	return *ms.progress.Load()
+}
+
+// Handler serves the progress of the sweep as JSON.
+func (ms *MigrationSweeper) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(ms.Progress())
+	})
+}
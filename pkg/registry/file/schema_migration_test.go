@@ -0,0 +1,370 @@
+package file
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition/v1beta1"
+	"github.com/kubescape/storage/pkg/config"
+	"github.com/kubescape/storage/pkg/generated/clientset/versioned/scheme"
+	"github.com/kubescape/storage/pkg/registry/file/legacygob"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/storage"
+)
+
+func TestNewSchemaMigrations(t *testing.T) {
+	migrate := func(runtime.Object) error { return nil }
+	tests := []struct {
+		name       string
+		migrations []SchemaMigration
+		wantErr    string
+	}{
+		{
+			name: "chained",
+			migrations: []SchemaMigration{
+				{Name: "one", Resources: []string{"applicationprofiles"}, From: 1, Migrate: migrate},
+				{Name: "two", Resources: []string{"applicationprofiles"}, From: 2, Migrate: migrate},
+				{Name: "legacy", Resources: []string{"applicationprofiles"}, Decode: legacygob.Decode},
+			},
+		},
+		{
+			name:       "no name",
+			migrations: []SchemaMigration{{Resources: []string{"applicationprofiles"}, From: 1, Migrate: migrate}},
+			wantErr:    "without a name",
+		},
+		{
+			name:       "no resources",
+			migrations: []SchemaMigration{{Name: "one", From: 1, Migrate: migrate}},
+			wantErr:    "has no resources",
+		},
+		{
+			name:       "both migrate and decode",
+			migrations: []SchemaMigration{{Name: "one", Resources: []string{"applicationprofiles"}, From: 1, Migrate: migrate, Decode: legacygob.Decode}},
+			wantErr:    "exactly one of Migrate and Decode",
+		},
+		{
+			name:       "from the current version",
+			migrations: []SchemaMigration{{Name: "one", Resources: []string{"applicationprofiles"}, From: 3, Migrate: migrate}},
+			wantErr:    "the current one being 3",
+		},
+		{
+			name: "duplicate",
+			migrations: []SchemaMigration{
+				{Name: "one", Resources: []string{"applicationprofiles"}, From: 2, Migrate: migrate},
+				{Name: "other", Resources: []string{"applicationprofiles"}, From: 2, Migrate: migrate},
+			},
+			wantErr: "both migrate applicationprofiles from version 2",
+		},
+		{
+			name:       "gap",
+			migrations: []SchemaMigration{{Name: "one", Resources: []string{"applicationprofiles"}, From: 1, Migrate: migrate}},
+			wantErr:    "no schema migration of applicationprofiles from version 2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewSchemaMigrations(3, tt.migrations...)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, []string{"applicationprofiles"}, m.Resources())
+			assert.True(t, m.CanMigrate("applicationprofiles", 1))
+			assert.True(t, m.CanMigrate("networkneighborhoods", 3))
+			assert.False(t, m.CanMigrate("networkneighborhoods", 1))
+			assert.False(t, m.CanMigrate("applicationprofiles", 4))
+		})
+	}
+}
+
+// writeLegacyProfile stores an application profile whose payload was written
+// before the seccomp fields became int64.
+func writeLegacyProfile(t *testing.T, s *StorageImpl, key string) {
+	ctx := context.Background()
+	_, _, _, _, namespace, name := K8sPathToKeys(key)
+	conn, err := s.pool.Take(ctx)
+	require.NoError(t, err)
+	require.NoError(t, s.saveObject(conn, key, &softwarecomposition.ApplicationProfile{
+		ObjectMeta:    v1.ObjectMeta{Name: name, Namespace: namespace},
+		SchemaVersion: SchemaVersion,
+	}, nil, ""))
+	s.pool.Put(conn)
+	var seccomp legacygob.LegacySingleSeccompProfile
+	seccomp.Spec.Syscalls = []*legacygob.LegacySyscall{{Names: []string{"read"}, ErrnoRet: 1, Args: []*legacygob.LegacyArg{{Index: 1, Value: 2}}}}
+	legacy := &legacygob.LegacyApplicationProfile{
+		ObjectMeta:    v1.ObjectMeta{Name: name, Namespace: namespace},
+		SchemaVersion: SchemaVersion,
+		Spec: legacygob.LegacyApplicationProfileSpec{
+			Containers: []legacygob.LegacyApplicationProfileContainer{{Name: "nginx", SeccompProfile: seccomp}},
+		},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(legacy))
+	require.NoError(t, afero.WriteFile(s.appFs, makePayloadPath(filepath.Join(s.root, key)), buf.Bytes(), 0644))
+}
+
+func TestStorageImpl_SchemaMigrations(t *testing.T) {
+	pool := NewTestPool(t.TempDir())
+	defer func() { _ = pool.Close() }()
+	sch := scheme.Scheme
+	require.NoError(t, softwarecomposition.AddToScheme(sch))
+	fs := afero.NewMemMapFs()
+	s := NewStorageImpl(fs, DefaultStorageRoot, pool, nil, sch).(*StorageImpl)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	migrations, err := NewSchemaMigrations(2, SchemaMigration{
+		Name:      "architectures",
+		Resources: []string{"applicationprofiles"},
+		From:      1,
+		Migrate: func(obj runtime.Object) error {
+			profile := obj.(*softwarecomposition.ApplicationProfile)
+			if profile.Name == "broken" {
+				return errors.New("broken")
+			}
+			profile.Spec.Architectures = append(profile.Spec.Architectures, "arm64")
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	prefix := "/spdx.softwarecomposition.kubescape.io/applicationprofiles"
+	key := func(name string) string {
+		return prefix + "/default/" + name
+	}
+	save := func(key string) {
+		conn, err := pool.Take(ctx)
+		require.NoError(t, err)
+		defer pool.Put(conn)
+		_, _, _, _, namespace, name := K8sPathToKeys(key)
+		profile := &softwarecomposition.ApplicationProfile{
+			ObjectMeta:    v1.ObjectMeta{Name: name, Namespace: namespace},
+			SchemaVersion: 1,
+			Spec:          softwarecomposition.ApplicationProfileSpec{Architectures: []string{"amd64"}},
+		}
+		require.NoError(t, s.saveObject(conn, key, profile, nil, ""))
+	}
+	stored := func(key string) *softwarecomposition.ApplicationProfile {
+		profile := &softwarecomposition.ApplicationProfile{}
+		_, err := s.readPayload(key, profile)
+		require.NoError(t, err)
+		return profile
+	}
+	// as seen by the cleanup
+	storedVersion := func(key string) string {
+		conn, err := pool.Take(ctx)
+		require.NoError(t, err)
+		defer pool.Put(conn)
+		metadataJSON, err := ReadMetadata(conn, key)
+		require.NoError(t, err)
+		metadata, err := loadMetadata(metadataJSON)
+		require.NoError(t, err)
+		return metadata.Annotations["schemaVersion"]
+	}
+	for _, name := range []string{"lazy", "pressured", "swept", "broken"} {
+		save(key(name))
+	}
+	save(prefix + "/listed/nginx")
+
+	// dry-run only reports
+	s.SetSchemaMigrations(migrations, true)
+	got := &softwarecomposition.ApplicationProfile{}
+	require.NoError(t, s.Get(ctx, key("lazy"), storage.GetOptions{}, got))
+	assert.Equal(t, int64(1), got.SchemaVersion)
+	progress, err := s.SweepMigrations(ctx, SweepOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, SweepProgress{
+		Total:      5,
+		Checked:    5,
+		Migrated:   4,
+		Failed:     1,
+		Migrations: map[string]int{"architectures": 4},
+		DryRun:     true,
+		Done:       true,
+	}, progress)
+	assert.Equal(t, []string{"amd64"}, stored(key("swept")).Spec.Architectures)
+	assert.Equal(t, "1", storedVersion(key("swept")))
+
+	// migrated when read, without writing under disk pressure
+	s.SetSchemaMigrations(migrations, false)
+	used := map[string]float64{"/data": 96}
+	m := newTestDiskMonitor(used, nil)
+	m.check(ctx)
+	s.SetDiskMonitor(m)
+	got = &softwarecomposition.ApplicationProfile{}
+	require.NoError(t, s.Get(ctx, key("pressured"), storage.GetOptions{}, got))
+	assert.Equal(t, int64(2), got.SchemaVersion)
+	assert.Equal(t, []string{"amd64", "arm64"}, got.Spec.Architectures)
+	assert.Equal(t, int64(1), stored(key("pressured")).SchemaVersion)
+	assert.Equal(t, "1", storedVersion(key("pressured")))
+	// and stored otherwise
+	used["/data"] = 10
+	m.check(ctx)
+	got = &softwarecomposition.ApplicationProfile{}
+	require.NoError(t, s.Get(ctx, key("lazy"), storage.GetOptions{}, got))
+	assert.Equal(t, int64(2), got.SchemaVersion)
+	assert.Equal(t, []string{"amd64", "arm64"}, got.Spec.Architectures)
+	assert.Equal(t, int64(2), stored(key("lazy")).SchemaVersion)
+	assert.Equal(t, []string{"amd64", "arm64"}, stored(key("lazy")).Spec.Architectures)
+	assert.Equal(t, "2", storedVersion(key("lazy")))
+	list := &softwarecomposition.ApplicationProfileList{}
+	require.NoError(t, s.GetList(ctx, prefix+"/listed", storage.ListOptions{ResourceVersion: softwarecomposition.ResourceVersionFullSpec, Predicate: storage.Everything}, list))
+	require.Len(t, list.Items, 1)
+	assert.Equal(t, int64(2), list.Items[0].SchemaVersion)
+	assert.Equal(t, []string{"amd64", "arm64"}, list.Items[0].Spec.Architectures)
+	assert.Equal(t, "2", storedVersion(prefix+"/listed/nginx"))
+	// left as stored when failing
+	got = &softwarecomposition.ApplicationProfile{}
+	require.NoError(t, s.Get(ctx, key("broken"), storage.GetOptions{}, got))
+	assert.Equal(t, int64(1), got.SchemaVersion)
+	assert.Equal(t, "1", storedVersion(key("broken")))
+
+	// the rest rewritten by the sweeper
+	ms := NewMigrationSweeper(s, nil, &config.Config{})
+	ms.Run(ctx)
+	assert.Equal(t, SweepProgress{
+		Total:      5,
+		Checked:    5,
+		Migrated:   2,
+		Failed:     1,
+		Migrations: map[string]int{"architectures": 2},
+		Done:       true,
+	}, ms.Progress())
+	for _, name := range []string{"swept", "pressured"} {
+		assert.Equal(t, []string{"amd64", "arm64"}, stored(key(name)).Spec.Architectures)
+		assert.Equal(t, "2", storedVersion(key(name)))
+	}
+	assert.Equal(t, "1", storedVersion(key("broken")))
+}
+
+func TestMigrationSweeper(t *testing.T) {
+	pool := NewTestPool(t.TempDir())
+	defer func() { _ = pool.Close() }()
+	sch := scheme.Scheme
+	require.NoError(t, softwarecomposition.AddToScheme(sch))
+	fs := afero.NewMemMapFs()
+	s := NewStorageImpl(fs, DefaultStorageRoot, pool, nil, sch).(*StorageImpl)
+	s.SetPayloadCodec(NewProtobufCodec(sch, v1beta1.SchemeGroupVersion))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	prefix := "/spdx.softwarecomposition.kubescape.io/applicationprofiles"
+	format := func(key string) PayloadFormat {
+		format, err := s.readPayloadFormat(makePayloadPath(filepath.Join(DefaultStorageRoot, prefix, key)))
+		require.NoError(t, err)
+		return format
+	}
+	writeLegacyProfile(t, s, prefix+"/listed/nginx")
+	writeLegacyProfile(t, s, prefix+"/default/swept")
+	conn, err := pool.Take(ctx)
+	require.NoError(t, err)
+	require.NoError(t, s.saveObject(conn, prefix+"/default/current", &softwarecomposition.ApplicationProfile{
+		ObjectMeta:    v1.ObjectMeta{Name: "current", Namespace: "default"},
+		SchemaVersion: SchemaVersion,
+	}, nil, ""))
+	pool.Put(conn)
+
+	// dry-run only reports
+	s.SetSchemaMigrations(DefaultSchemaMigrations(), true)
+	progress, err := s.SweepMigrations(ctx, SweepOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, SweepProgress{
+		Total:      3,
+		Checked:    3,
+		Migrated:   2,
+		Migrations: map[string]int{"legacy-gob-uint64": 2},
+		DryRun:     true,
+		Done:       true,
+	}, progress)
+	assert.Equal(t, payloadFormatLegacy, format("default/swept"))
+
+	// listed without writing under disk pressure
+	s.SetSchemaMigrations(DefaultSchemaMigrations(), false)
+	used := map[string]float64{"/data": 96}
+	m := newTestDiskMonitor(used, nil)
+	m.check(ctx)
+	s.SetDiskMonitor(m)
+	list := &softwarecomposition.ApplicationProfileList{}
+	require.NoError(t, s.GetList(ctx, prefix+"/listed", storage.ListOptions{ResourceVersion: softwarecomposition.ResourceVersionFullSpec, Predicate: storage.Everything}, list))
+	require.Len(t, list.Items, 1)
+	assert.Len(t, list.Items[0].Spec.Containers, 1)
+	assert.Equal(t, payloadFormatLegacy, format("listed/nginx"))
+	// and rewritten otherwise
+	used["/data"] = 10
+	m.check(ctx)
+	list = &softwarecomposition.ApplicationProfileList{}
+	require.NoError(t, s.GetList(ctx, prefix+"/listed", storage.ListOptions{ResourceVersion: softwarecomposition.ResourceVersionFullSpec, Predicate: storage.Everything}, list))
+	require.Len(t, list.Items, 1)
+	assert.Len(t, list.Items[0].Spec.Containers, 1)
+	assert.Equal(t, PayloadFormatProtobuf, format("listed/nginx"))
+
+	// rewritten by the sweeper
+	ms := NewMigrationSweeper(s, nil, &config.Config{})
+	ms.Run(ctx)
+	assert.Equal(t, SweepProgress{
+		Total:      3,
+		Checked:    3,
+		Migrated:   1,
+		Migrations: map[string]int{"legacy-gob-uint64": 1},
+		Done:       true,
+	}, ms.Progress())
+	assert.Equal(t, PayloadFormatProtobuf, format("default/swept"))
+	rec := httptest.NewRecorder()
+	ms.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/migrations", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var served SweepProgress
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &served))
+	assert.Equal(t, ms.Progress(), served)
+}
+
+func TestStorageImpl_LegacyGobMigration(t *testing.T) {
+	pool := NewTestPool(t.TempDir())
+	defer func() { _ = pool.Close() }()
+	sch := scheme.Scheme
+	require.NoError(t, softwarecomposition.AddToScheme(sch))
+	fs := afero.NewMemMapFs()
+	s := NewStorageImpl(fs, DefaultStorageRoot, pool, nil, sch).(*StorageImpl)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	key := "/spdx.softwarecomposition.kubescape.io/applicationprofiles/default/legacy"
+	payloadPath := makePayloadPath(filepath.Join(DefaultStorageRoot, key))
+	writeLegacyProfile(t, s, key)
+	_, err := s.readPayload(key, &softwarecomposition.ApplicationProfile{})
+	require.True(t, isLegacyGobError(err), err)
+
+	// only decoded under disk pressure
+	used := map[string]float64{"/data": 96}
+	m := newTestDiskMonitor(used, nil)
+	m.check(ctx)
+	s.SetDiskMonitor(m)
+	got := &softwarecomposition.ApplicationProfile{}
+	require.NoError(t, s.Get(ctx, key, storage.GetOptions{}, got))
+	require.Len(t, got.Spec.Containers, 1)
+	format, err := s.readPayloadFormat(payloadPath)
+	require.NoError(t, err)
+	assert.Equal(t, payloadFormatLegacy, format)
+
+	used["/data"] = 10
+	m.check(ctx)
+	got = &softwarecomposition.ApplicationProfile{}
+	require.NoError(t, s.Get(ctx, key, storage.GetOptions{}, got))
+	require.Len(t, got.Spec.Containers, 1)
+	syscalls := got.Spec.Containers[0].SeccompProfile.Spec.Syscalls
+	require.Len(t, syscalls, 1)
+	assert.Equal(t, int64(1), syscalls[0].ErrnoRet)
+	assert.Equal(t, int64(2), syscalls[0].Args[0].Value)
+	// rewritten in gob the current types decode
+	_, err = s.readPayload(key, &softwarecomposition.ApplicationProfile{})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,375 @@
+package file
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kubescape/go-logger"
+	"github.com/kubescape/go-logger/helpers"
+	"github.com/kubescape/storage/pkg/config"
+	"github.com/spf13/afero"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+// The reasons a payload is quarantined for.
+const (
+	QuarantineReasonDecode   = "decode"
+	QuarantineReasonChecksum = "checksum"
+)
+
+// scrubLockTimeout is how long the scrubber waits for the lock of an object,
+// a busy object is checked on the next pass.
+var scrubLockTimeout = 100 * time.Millisecond
+
+// scrubDisabledPoll is how often a disabled Scrubber checks whether it was
+// enabled by a reload.
+var scrubDisabledPoll = time.Minute
+
+var (
+	scrubPassesTotal = metrics.NewCounter(&metrics.CounterOpts{
+		Subsystem:      "storage_scrubber",
+		Name:           "passes_total",
+		Help:           "Number of passes of the integrity scrubber over the payloads.",
+		StabilityLevel: metrics.ALPHA,
+	})
+	scrubCheckedTotal = metrics.NewCounter(&metrics.CounterOpts{
+		Subsystem:      "storage_scrubber",
+		Name:           "checked_payloads_total",
+		Help:           "Number of payloads checked by the integrity scrubber.",
+		StabilityLevel: metrics.ALPHA,
+	})
+	scrubQuarantinedTotal = metrics.NewCounterVec(&metrics.CounterOpts{
+		Subsystem:      "storage_scrubber",
+		Name:           "quarantined_payloads_total",
+		Help:           "Number of payloads quarantined by the integrity scrubber, by resource and reason.",
+		StabilityLevel: metrics.ALPHA,
+	}, []string{"resource", "reason"})
+	registerScrubberMetrics sync.Once
+)
+
+// ScrubOptions tunes a Scrub pass.
+type ScrubOptions struct {
+	// Rate caps the payloads checked per second, 0 for no limit.
+	Rate int
+	// Resources restricts the pass to these resources, by default all of
+	// them but Exclude.
+	Resources []string
+	Exclude   []string
+	// Quarantined, if not nil, is called with the key, kind and reason of
+	// every payload quarantined.
+	Quarantined func(key, kind, reason string)
+}
+
+// ScrubReport counts the payloads of a Scrub pass.
+type ScrubReport struct {
+	Checked     int
+	Skipped     int
+	Quarantined int
+}
+
+// QuarantinedPayload is a payload moved to QuarantineDir, its object is gone
+// until its producer sends it again.
+type QuarantinedPayload struct {
+	Key       string `json:"key"`
+	Resource  string `json:"resource"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Size      int64  `json:"size"`
+}
+
+// Scrub checks every payload decodes and matches the digest recorded when it
+// was written, quarantining the ones failing. The digest is the one of the
+// encoded payload rather than the checksum in the annotations: the codecs
+// decode empty slices and maps as nil, so the checksum of a decoded object is
+// not the one of the object written. An object whose lock is busy is skipped
+// rather than waited for, as are the payloads in a legacy format, migrated
+// when read. The payloads written before digests were recorded are only
+// decoded. Objects are locked by the backend writing them, so a pass must
+// only check the resources of its StorageImpl.
+func (s *StorageImpl) Scrub(ctx context.Context, opts ScrubOptions) (ScrubReport, error) {
+	var report ScrubReport
+	dirs, err := s.resourceDirs(opts.Resources, opts.Exclude)
+	if err != nil {
+		return report, err
+	}
+	var keys []string
+	for _, dir := range dirs {
+		err := afero.Walk(s.appFs, dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				// missing root or vanishing file
+				return nil
+			}
+			if !info.IsDir() && IsPayloadFile(path) {
+				keys = append(keys, s.keyFromPath(path))
+			}
+			return nil
+		})
+		if err != nil {
+			return report, fmt.Errorf("walk payloads: %w", err)
+		}
+	}
+	var tick <-chan time.Time
+	if opts.Rate > 0 {
+		ticker := time.NewTicker(time.Second / time.Duration(opts.Rate))
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for _, key := range keys {
+		if tick != nil {
+			select {
+			case <-ctx.Done():
+				return report, ctx.Err()
+			case <-tick:
+			}
+		} else if ctx.Err() != nil {
+			return report, ctx.Err()
+		}
+		kind, reason, err := s.scrubPayload(ctx, key)
+		switch {
+		case err != nil:
+			logger.L().Debug("Scrub - payload skipped", helpers.Error(err), helpers.String("key", key))
+			report.Skipped++
+		case reason != "":
+			report.Checked++
+			report.Quarantined++
+			if opts.Quarantined != nil {
+				opts.Quarantined(key, kind, reason)
+			}
+		default:
+			report.Checked++
+		}
+	}
+	return report, nil
+}
+
+// scrubPayload checks the payload of key, returning the kind of its object
+// and the reason it was quarantined for, if it was.
+func (s *StorageImpl) scrubPayload(ctx context.Context, key string) (string, string, error) {
+	_, _, resource, _, _, _ := K8sPathToKeys(key)
+	obj, err := s.newObjectForResource(resource)
+	if err != nil {
+		return "", "", err
+	}
+	kind := reflect.TypeOf(obj).Elem().Name()
+	lockCtx, lockCancel := context.WithTimeout(ctx, scrubLockTimeout)
+	defer lockCancel()
+	if err := s.locks.Lock(lockCtx, key); err != nil {
+		return kind, "", fmt.Errorf("object busy: %w", err)
+	}
+	defer s.locks.Unlock(key)
+	poolCtx, cancel := poolContext()
+	defer cancel()
+	conn, err := s.pool.Take(poolCtx)
+	if err != nil {
+		return kind, "", newContentionTimeoutError("scrub", key, err)
+	}
+	defer s.pool.Put(conn)
+	_, err = s.readPayload(key, obj)
+	var reason string
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		// deleted meanwhile
+		return kind, "", err
+	case err != nil && isLegacyGobError(err):
+		return kind, "", fmt.Errorf("legacy payload: %w", err)
+	case err != nil:
+		logger.L().Warning("Scrub - payload failed to decode", helpers.Error(err), helpers.String("key", key))
+		reason = QuarantineReasonDecode
+	default:
+		recorded, err := readPayloadDigest(conn, key)
+		if err != nil || recorded == "" {
+			return kind, "", err
+		}
+		computed, err := s.payloadDigest(key)
+		if err != nil {
+			return kind, "", err
+		}
+		if computed == recorded {
+			return kind, "", nil
+		}
+		logger.L().Warning("Scrub - payload digest mismatch", helpers.String("key", key), helpers.String("recorded", recorded), helpers.String("computed", computed))
+		reason = QuarantineReasonChecksum
+	}
+	if err := s.quarantine(conn, key); err != nil {
+		return kind, "", err
+	}
+	return kind, reason, nil
+}
+
+// payloadDigest returns the SHA-256 of the encoded payload of key, as
+// returned by writePayload.
+func (s *StorageImpl) payloadDigest(key string) (string, error) {
+	payloadFile, err := s.openPayloadFileWithFallback(makePayloadPath(filepath.Join(s.root, key)), os.O_RDONLY, 0)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = payloadFile.Close()
+	}()
+	digest := sha256.New()
+	if _, err := io.Copy(digest, NewDirectIOReader(payloadFile)); err != nil {
+		return "", fmt.Errorf("read payload: %w", err)
+	}
+	return hex.EncodeToString(digest.Sum(nil)), nil
+}
+
+// ListQuarantined returns the payloads in QuarantineDir.
+func (s *StorageImpl) ListQuarantined() ([]QuarantinedPayload, error) {
+	root := filepath.Join(s.root, QuarantineDir)
+	var payloads []QuarantinedPayload
+	err := afero.Walk(s.appFs, root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() || !IsPayloadFile(path) {
+			return nil
+		}
+		key := strings.TrimPrefix(strings.TrimSuffix(path, GobExt), root)
+		_, _, resource, _, namespace, name := K8sPathToKeys(key)
+		if name == "" {
+			// cluster-scoped
+			namespace, name = "", namespace
+		}
+		payloads = append(payloads, QuarantinedPayload{
+			Key:       key,
+			Resource:  resource,
+			Namespace: namespace,
+			Name:      name,
+			Size:      info.Size(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walk quarantine: %w", err)
+	}
+	return payloads, nil
+}
+
+// QuarantineEventFunc reports the object at key, of kind, quarantined for
+// reason.
+type QuarantineEventFunc func(ctx context.Context, key, kind, reason string) error
+
+// Scrubber runs a Scrub pass every ScrubInterval, at ScrubRate. The payloads
+// quarantined are counted in metrics and reported through its
+// QuarantineEventFunc, so that their producers can send them again.
+type Scrubber struct {
+	storage  StorageQuerier
+	backends map[string]StorageQuerier
+	cfg      atomic.Pointer[config.Config]
+	event    QuarantineEventFunc
+}
+
+// NewScrubber returns a Scrubber of storage, the resources in backends being
+// scrubbed by theirs, which lock their objects. event may be nil.
+func NewScrubber(storage StorageQuerier, backends map[string]StorageQuerier, cfg *config.Config, event QuarantineEventFunc) *Scrubber {
+	registerScrubberMetrics.Do(func() {
+		legacyregistry.MustRegister(scrubPassesTotal, scrubCheckedTotal, scrubQuarantinedTotal)
+	})
+	sc := &Scrubber{
+		storage:  storage,
+		backends: backends,
+		event:    event,
+	}
+	sc.Reload(cfg)
+	return sc
+}
+
+// Reload applies the interval and rate of cfg from the next pass.
+func (sc *Scrubber) Reload(cfg *config.Config) {
+	sc.cfg.Store(cfg)
+}
+
+// Run scrubs the payloads until ctx is done, waiting an interval before the
+// first pass as Reconcile checked them at startup.
+func (sc *Scrubber) Run(ctx context.Context) {
+	for {
+		interval := sc.cfg.Load().ScrubInterval
+		wait := interval
+		if interval <= 0 {
+			wait = scrubDisabledPoll
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+		if interval > 0 {
+			sc.pass(ctx)
+		}
+	}
+}
+
+func (sc *Scrubber) pass(ctx context.Context) {
+	start := time.Now()
+	var done ScrubReport
+	// the resources having a backend are scrubbed by it, the others at once
+	passes := []ScrubOptions{{Exclude: slices.Sorted(maps.Keys(sc.backends))}}
+	for _, resource := range slices.Sorted(maps.Keys(sc.backends)) {
+		passes = append(passes, ScrubOptions{Resources: []string{resource}})
+	}
+	for _, opts := range passes {
+		storage := sc.storage
+		if opts.Resources != nil {
+			storage = sc.backends[opts.Resources[0]]
+		}
+		opts.Rate = sc.cfg.Load().ScrubRate
+		opts.Quarantined = func(key, kind, reason string) {
+			_, _, resource, _, _, _ := K8sPathToKeys(key)
+			scrubQuarantinedTotal.WithLabelValues(resource, reason).Inc()
+			if sc.event == nil {
+				return
+			}
+			if err := sc.event(ctx, key, kind, reason); err != nil {
+				logger.L().Warning("quarantine event failed", helpers.Error(err), helpers.String("key", key))
+			}
+		}
+		report, err := storage.Scrub(ctx, opts)
+		done.Checked += report.Checked
+		done.Skipped += report.Skipped
+		done.Quarantined += report.Quarantined
+		scrubCheckedTotal.Add(float64(report.Checked))
+		if err != nil {
+			scrubPassesTotal.Inc()
+			logger.L().Warning("scrub pass interrupted", helpers.Error(err), helpers.Interface("resources", opts.Resources), helpers.Int("checked", done.Checked))
+			return
+		}
+	}
+	scrubPassesTotal.Inc()
+	logger.L().Info("scrub pass done",
+		helpers.Int("checked", done.Checked),
+		helpers.Int("skipped", done.Skipped),
+		helpers.Int("quarantined", done.Quarantined),
+		helpers.String("duration", time.Since(start).String()))
+}
+
+// Handler serves the quarantined payloads as JSON.
+func (sc *Scrubber) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		payloads, err := sc.storage.ListQuarantined()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(payloads)
+	})
+}
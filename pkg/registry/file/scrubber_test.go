@@ -0,0 +1,177 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"github.com/kubescape/storage/pkg/config"
+	"github.com/kubescape/storage/pkg/generated/clientset/versioned/scheme"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/storage"
+)
+
+func TestScrubber(t *testing.T) {
+	pool := NewTestPool(t.TempDir())
+	defer func() { _ = pool.Close() }()
+	sch := scheme.Scheme
+	require.NoError(t, softwarecomposition.AddToScheme(sch))
+	fs := afero.NewMemMapFs()
+	s := NewStorageImpl(fs, DefaultStorageRoot, pool, nil, sch).(*StorageImpl)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	key := func(name string) string {
+		return "/spdx.softwarecomposition.kubescape.io/sbomsyfts/default/" + name
+	}
+	payload := func(key string) string {
+		return makePayloadPath(filepath.Join(DefaultStorageRoot, key))
+	}
+	create := func(name string) {
+		obj := &softwarecomposition.SBOMSyft{ObjectMeta: v1.ObjectMeta{Name: name, Namespace: "default"}}
+		require.NoError(t, s.Create(ctx, key(name), obj, &softwarecomposition.SBOMSyft{}, 0))
+	}
+	for _, name := range []string{"ok", "corrupt", "tampered"} {
+		create(name)
+	}
+	// payload failing to decode
+	require.NoError(t, afero.WriteFile(fs, payload(key("corrupt")), []byte("garbage"), 0644))
+	// payload changed behind its checksum
+	tampered := &softwarecomposition.SBOMSyft{}
+	require.NoError(t, s.Get(ctx, key("tampered"), storage.GetOptions{}, tampered))
+	tampered.Spec.Metadata.Tool.Name = "tampered"
+	_, _, err := s.writePayload(payload(key("tampered")), tampered)
+	require.NoError(t, err)
+	// profile whose checksum is aggregated from its parts
+	apKey := "/spdx.softwarecomposition.kubescape.io/applicationprofiles/default/aggregated"
+	conn, err := pool.Take(ctx)
+	require.NoError(t, err)
+	profile := &softwarecomposition.ApplicationProfile{
+		ObjectMeta: v1.ObjectMeta{Name: "aggregated", Namespace: "default"},
+		Parts:      map[string]string{"part": "checksum"},
+	}
+	require.NoError(t, s.saveObject(conn, apKey, profile, nil, "aggregated"))
+	pool.Put(conn)
+	// empty slices, decoded as nil
+	nnKey := "/spdx.softwarecomposition.kubescape.io/networkneighborhoods/default/empty"
+	nn := &softwarecomposition.NetworkNeighborhood{
+		ObjectMeta: v1.ObjectMeta{Name: "empty", Namespace: "default"},
+		Spec: softwarecomposition.NetworkNeighborhoodSpec{
+			Containers: []softwarecomposition.NetworkNeighborhoodContainer{{
+				Name:    "nginx",
+				Ingress: []softwarecomposition.NetworkNeighbor{},
+				Egress:  []softwarecomposition.NetworkNeighbor{},
+			}},
+		},
+	}
+	require.NoError(t, s.Create(ctx, nnKey, nn, &softwarecomposition.NetworkNeighborhood{}, 0))
+
+	type event struct{ key, kind, reason string }
+	var events []event
+	sc := NewScrubber(s, nil, &config.Config{}, func(_ context.Context, key, kind, reason string) error {
+		events = append(events, event{key, kind, reason})
+		return nil
+	})
+	report, err := s.Scrub(ctx, ScrubOptions{Quarantined: func(key, kind, reason string) {
+		require.NoError(t, sc.event(ctx, key, kind, reason))
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, ScrubReport{Checked: 5, Quarantined: 2}, report)
+	assert.ElementsMatch(t, []event{
+		{key("corrupt"), "SBOMSyft", QuarantineReasonDecode},
+		{key("tampered"), "SBOMSyft", QuarantineReasonChecksum},
+	}, events)
+	conn, err = pool.Take(ctx)
+	require.NoError(t, err)
+	_, err = ReadMetadata(conn, key("tampered"))
+	pool.Put(conn)
+	assert.ErrorIs(t, err, ErrMetadataNotFound)
+
+	// quarantined payloads are listed
+	rec := httptest.NewRecorder()
+	sc.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/quarantine", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var quarantined []QuarantinedPayload
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &quarantined))
+	require.Len(t, quarantined, 2)
+	assert.Equal(t, QuarantinedPayload{Key: key("corrupt"), Resource: "sbomsyfts", Namespace: "default", Name: "corrupt", Size: 7}, quarantined[0])
+	assert.Equal(t, key("tampered"), quarantined[1].Key)
+
+	// until sent again
+	create("corrupt")
+	quarantined, err = s.ListQuarantined()
+	require.NoError(t, err)
+	require.Len(t, quarantined, 1)
+	assert.Equal(t, key("tampered"), quarantined[0].Key)
+}
+
+func TestScrubber_Backends(t *testing.T) {
+	pool := NewTestPool(t.TempDir())
+	defer func() { _ = pool.Close() }()
+	sch := scheme.Scheme
+	require.NoError(t, softwarecomposition.AddToScheme(sch))
+	fs := afero.NewMemMapFs()
+	s := NewStorageImpl(fs, DefaultStorageRoot, pool, nil, sch).(*StorageImpl)
+	backend := NewStorageImpl(fs, DefaultStorageRoot, pool, nil, sch).(*StorageImpl)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	key := "/spdx.softwarecomposition.kubescape.io/applicationprofiles/default/nginx"
+	profile := &softwarecomposition.ApplicationProfile{ObjectMeta: v1.ObjectMeta{Name: "nginx", Namespace: "default"}}
+	require.NoError(t, backend.Create(ctx, key, profile, &softwarecomposition.ApplicationProfile{}, 0))
+	var events []string
+	sc := NewScrubber(s, map[string]StorageQuerier{"applicationprofiles": backend}, &config.Config{}, func(_ context.Context, key, _, _ string) error {
+		events = append(events, key)
+		return nil
+	})
+
+	// the backend writes the profile during the pass: its new payload is in
+	// place, its digest not yet recorded
+	written := make(chan struct{})
+	scrubbed := make(chan struct{})
+	writeDone := make(chan error)
+	go func() {
+		writeDone <- func() error {
+			if err := backend.locks.Lock(ctx, key); err != nil {
+				return err
+			}
+			defer backend.locks.Unlock(key)
+			profile.Spec.Architectures = []string{"amd64"}
+			size, digest, err := backend.writePayload(makePayloadPath(filepath.Join(DefaultStorageRoot, key)), profile)
+			if err != nil {
+				return err
+			}
+			close(written)
+			<-scrubbed
+			conn, err := pool.Take(ctx)
+			if err != nil {
+				return err
+			}
+			defer pool.Put(conn)
+			return backend.writeIndexes(conn, key, profile, extractFields(profile, []string{"ObjectMeta", "SchemaVersion"}), size, digest)
+		}()
+	}()
+	<-written
+	sc.pass(ctx)
+	close(scrubbed)
+	require.NoError(t, <-writeDone)
+	// and the profile is checked once written
+	sc.pass(ctx)
+	assert.Empty(t, events)
+	quarantined, err := s.ListQuarantined()
+	require.NoError(t, err)
+	assert.Empty(t, quarantined)
+	report, err := backend.Scrub(ctx, ScrubOptions{Resources: []string{"applicationprofiles"}})
+	require.NoError(t, err)
+	assert.Equal(t, ScrubReport{Checked: 1}, report)
+	// the other resources are not scrubbed by the default storage
+	report, err = s.Scrub(ctx, ScrubOptions{Exclude: []string{"applicationprofiles"}})
+	require.NoError(t, err)
+	assert.Equal(t, ScrubReport{}, report)
+}
@@ -57,6 +57,46 @@ func NewPool(path string, size int) *sqlitemigration.Pool {
 					hasData INTEGER DEFAULT 0,
 					PRIMARY KEY (kind, namespace, name, seriesID, tsSuffix)
 				);`,
+				`CREATE TABLE IF NOT EXISTS summary_contributions (
+					kind TEXT,
+					namespace TEXT,
+					name TEXT,
+					contribution JSON,
+					PRIMARY KEY (kind, namespace, name)
+				);`,
+				`CREATE TABLE IF NOT EXISTS summaries (
+					kind TEXT,
+					namespace TEXT,
+					summary JSON,
+					PRIMARY KEY (kind, namespace)
+				);`,
+				`CREATE TABLE IF NOT EXISTS guardrail_violations (
+					kind TEXT,
+					namespace TEXT,
+					name TEXT,
+					guardrail TEXT,
+					rule TEXT,
+					message TEXT,
+					PRIMARY KEY (kind, namespace, name, guardrail, rule)
+				);`,
+				`CREATE TABLE IF NOT EXISTS revision (
+					id INTEGER PRIMARY KEY CHECK (id = 0),
+					value INTEGER NOT NULL
+				);`,
+				`CREATE TABLE IF NOT EXISTS call_stack_frames (
+					kind TEXT,
+					namespace TEXT,
+					name TEXT,
+					container TEXT,
+					call_id TEXT,
+					file_id TEXT,
+					lineno TEXT,
+					PRIMARY KEY (kind, namespace, name, container, call_id, file_id, lineno)
+				);`,
+				`CREATE INDEX IF NOT EXISTS call_stack_frames_by_frame ON call_stack_frames (namespace, file_id, lineno);`,
+				`ALTER TABLE metadata ADD COLUMN size INTEGER NOT NULL DEFAULT 0;`,
+				`ALTER TABLE metadata ADD COLUMN updated INTEGER NOT NULL DEFAULT 0;`,
+				`ALTER TABLE metadata ADD COLUMN digest TEXT NOT NULL DEFAULT '';`,
 			},
 		},
 		sqlitemigration.Options{
@@ -201,9 +241,10 @@ func countMetadata(conn *sqlite.Conn, path string) (int64, error) {
 }
 
 // DeleteMetadata deletes metadata for the given path and unmarshals the deleted metadata into the provided runtime.Object.
-func DeleteMetadata(conn *sqlite.Conn, path string, metadata runtime.Object) error {
+func DeleteMetadata(conn *sqlite.Conn, path string, metadata runtime.Object) (err error) {
+	defer sqlitex.Save(conn)(&err)
 	_, _, kind, _, namespace, name := K8sPathToKeys(path)
-	err := sqlitex.Execute(conn,
+	err = sqlitex.Execute(conn,
 		`DELETE FROM metadata
 				WHERE kind = :kind
 				  AND namespace = :namespace
@@ -222,68 +263,170 @@ func DeleteMetadata(conn *sqlite.Conn, path string, metadata runtime.Object) err
 	if err != nil {
 		return fmt.Errorf("delete metadata: %w", err)
 	}
-	return nil
+	if err := deleteSummaryContribution(conn, path); err != nil {
+		return fmt.Errorf("delete summary contribution: %w", err)
+	}
+	if err := deleteGuardrailViolations(conn, path); err != nil {
+		return err
+	}
+	if err := deleteCallStackFrames(conn, path); err != nil {
+		return err
+	}
+	_, err = bumpRevision(conn)
+	return err
 }
 
-func listMetadataKeys(conn *sqlite.Conn, path, cont string, limit int64) ([]string, string, error) {
+// listMetadataKeys returns the keys of at most limit objects under path, ordered by
+// namespace and name, starting after the given position.
+func listMetadataKeys(conn *sqlite.Conn, path string, after listPosition, limit int64) ([]string, listPosition, error) {
 	prefix, root, kind, _, namespace, _ := K8sPathToKeys(path)
-	if cont == "" {
-		cont = "0"
-	}
-	var last string
+	var last listPosition
 	var names []string
 	err := sqlitex.Execute(conn,
-		`SELECT rowid, namespace, name FROM metadata
+		`SELECT namespace, name FROM metadata
                 WHERE kind = :kind
                     AND (:namespace = '' OR namespace = :namespace)
-                	AND rowid > :cont
-				ORDER BY rowid
+                	AND (namespace, name) > (:afterNamespace, :afterName)
+				ORDER BY namespace, name
 				LIMIT :limit`,
 		&sqlitex.ExecOptions{
-			Named: map[string]any{":kind": kind, ":namespace": namespace, ":cont": cont, ":limit": limit},
+			Named: map[string]any{":kind": kind, ":namespace": namespace, ":afterNamespace": after.Namespace, ":afterName": after.Name, ":limit": limit},
 			ResultFunc: func(stmt *sqlite.Stmt) error {
-				last = stmt.ColumnText(0)
-				ns := stmt.ColumnText(1)
-				name := stmt.ColumnText(2)
-				names = append(names, K8sKeysToPath(prefix, root, kind, "", ns, name))
+				last = listPosition{Namespace: stmt.ColumnText(0), Name: stmt.ColumnText(1)}
+				names = append(names, K8sKeysToPath(prefix, root, kind, "", last.Namespace, last.Name))
 				return nil
 			},
 		})
 	if err != nil {
-		return nil, "", fmt.Errorf("list names: %w", err)
+		return nil, listPosition{}, fmt.Errorf("list names: %w", err)
 	}
 	return names, last, nil
 }
 
-func listMetadata(conn *sqlite.Conn, path, cont string, limit int64) ([]string, string, error) {
+// listMetadata returns the metadata of at most limit objects under path, ordered by
+// namespace and name, starting after the given position.
+func listMetadata(conn *sqlite.Conn, path string, after listPosition, limit int64) ([]string, listPosition, error) {
 	_, _, kind, _, namespace, _ := K8sPathToKeys(path)
-	if cont == "" {
-		cont = "0"
-	}
-	var last string
+	var last listPosition
 	var metadataJSONs []string
 	err := sqlitex.Execute(conn,
-		`SELECT rowid, metadata FROM metadata
+		`SELECT namespace, name, metadata FROM metadata
                 WHERE kind = :kind
                     AND (:namespace = '' OR namespace = :namespace)
-                	AND rowid > :cont
-				ORDER BY rowid
+                	AND (namespace, name) > (:afterNamespace, :afterName)
+				ORDER BY namespace, name
 				LIMIT :limit`,
 		&sqlitex.ExecOptions{
-			Named: map[string]any{":kind": kind, ":namespace": namespace, ":cont": cont, ":limit": limit},
+			Named: map[string]any{":kind": kind, ":namespace": namespace, ":afterNamespace": after.Namespace, ":afterName": after.Name, ":limit": limit},
 			ResultFunc: func(stmt *sqlite.Stmt) error {
-				last = stmt.ColumnText(0)
-				metadataJSON := stmt.ColumnText(1)
+				last = listPosition{Namespace: stmt.ColumnText(0), Name: stmt.ColumnText(1)}
+				metadataJSON := stmt.ColumnText(2)
 				metadataJSONs = append(metadataJSONs, metadataJSON)
 				return nil
 			},
 		})
 	if err != nil {
-		return nil, "", fmt.Errorf("list metadata: %w", err)
+		return nil, listPosition{}, fmt.Errorf("list metadata: %w", err)
 	}
 	return metadataJSONs, last, nil
 }
 
+// listAllMetadataKeys returns the keys of every object having metadata. The
+// cluster segment of a key is not stored, the keys having one come back
+// without it.
+func listAllMetadataKeys(conn *sqlite.Conn) ([]string, error) {
+	var keys []string
+	err := sqlitex.Execute(conn,
+		`SELECT kind, namespace, name FROM metadata ORDER BY kind, namespace, name`,
+		&sqlitex.ExecOptions{
+			ResultFunc: func(stmt *sqlite.Stmt) error {
+				keys = append(keys, K8sKeysToPath("", softwarecomposition.GroupName, stmt.ColumnText(0), "", stmt.ColumnText(1), stmt.ColumnText(2)))
+				return nil
+			},
+		})
+	if err != nil {
+		return nil, fmt.Errorf("list metadata keys: %w", err)
+	}
+	return keys, nil
+}
+
+// listMetadataUpdated returns when the metadata of each object was written,
+// in Unix nanoseconds, zero when unknown.
+func listMetadataUpdated(conn *sqlite.Conn) (map[[3]string]int64, error) {
+	updated := map[[3]string]int64{}
+	err := sqlitex.Execute(conn,
+		`SELECT kind, namespace, name, updated FROM metadata`,
+		&sqlitex.ExecOptions{
+			ResultFunc: func(stmt *sqlite.Stmt) error {
+				updated[[3]string{stmt.ColumnText(0), stmt.ColumnText(1), stmt.ColumnText(2)}] = stmt.ColumnInt64(3)
+				return nil
+			},
+		})
+	if err != nil {
+		return nil, fmt.Errorf("list metadata: %w", err)
+	}
+	return updated, nil
+}
+
+// countMetadataAfter returns the number of objects under path listed after the given position.
+func countMetadataAfter(conn *sqlite.Conn, path string, after listPosition) (int64, error) {
+	_, _, kind, _, namespace, _ := K8sPathToKeys(path)
+	var count int64
+	err := sqlitex.Execute(conn,
+		`SELECT COUNT(*) FROM metadata
+                WHERE kind = :kind
+                  AND (:namespace = '' OR namespace = :namespace)
+                  AND (namespace, name) > (:afterNamespace, :afterName)`,
+		&sqlitex.ExecOptions{
+			Named: map[string]any{":kind": kind, ":namespace": namespace, ":afterNamespace": after.Namespace, ":afterName": after.Name},
+			ResultFunc: func(stmt *sqlite.Stmt) error {
+				count = stmt.ColumnInt64(0)
+				return nil
+			},
+		})
+	if err != nil {
+		return 0, fmt.Errorf("count metadata: %w", err)
+	}
+	return count, nil
+}
+
+// bumpRevision increments the revision of the storage, which changes on every metadata write or delete,
+// and returns it.
+func bumpRevision(conn *sqlite.Conn) (uint64, error) {
+	var revision uint64
+	err := sqlitex.Execute(conn,
+		`INSERT INTO revision (id, value) VALUES (0, 1)
+				ON CONFLICT (id) DO UPDATE SET value = value + 1
+				RETURNING value`,
+		&sqlitex.ExecOptions{
+			ResultFunc: func(stmt *sqlite.Stmt) error {
+				revision = uint64(stmt.ColumnInt64(0))
+				return nil
+			},
+		})
+	if err != nil {
+		return 0, fmt.Errorf("bump revision: %w", err)
+	}
+	return revision, nil
+}
+
+// readRevision returns the current revision of the storage.
+func readRevision(conn *sqlite.Conn) (uint64, error) {
+	var revision uint64
+	err := sqlitex.Execute(conn,
+		`SELECT value FROM revision WHERE id = 0`,
+		&sqlitex.ExecOptions{
+			ResultFunc: func(stmt *sqlite.Stmt) error {
+				revision = uint64(stmt.ColumnInt64(0))
+				return nil
+			},
+		})
+	if err != nil {
+		return 0, fmt.Errorf("read revision: %w", err)
+	}
+	return revision, nil
+}
+
 func listNamespaces(conn *sqlite.Conn) ([]string, error) {
 	var namespaces []string
 	err := sqlitex.Execute(conn,
@@ -447,10 +590,21 @@ func writeMetadata(conn *sqlite.Conn, path string, metadata runtime.Object) erro
 
 // WriteJSON writes the given JSON metadata to the database for the specified path.
 func WriteJSON(conn *sqlite.Conn, path string, metadataJSON []byte) error {
+	if err := upsertMetadata(conn, path, metadataJSON); err != nil {
+		return err
+	}
+	_, err := bumpRevision(conn)
+	return err
+}
+
+// upsertMetadata writes the given JSON metadata for path, leaving the revision
+// of the storage to the caller.
+func upsertMetadata(conn *sqlite.Conn, path string, metadataJSON []byte) error {
 	_, _, kind, _, namespace, name := K8sPathToKeys(path)
 	err := sqlitex.Execute(conn,
-		`INSERT OR REPLACE INTO metadata
-				(kind, namespace, name, metadata) VALUES (?, ?, ?, ?)`,
+		`INSERT INTO metadata
+				(kind, namespace, name, metadata) VALUES (?, ?, ?, ?)
+				ON CONFLICT (kind, namespace, name) DO UPDATE SET metadata = excluded.metadata`,
 		&sqlitex.ExecOptions{
 			Args: []any{kind, namespace, name, metadataJSON},
 		})
@@ -460,6 +614,139 @@ func WriteJSON(conn *sqlite.Conn, path string, metadataJSON []byte) error {
 	return nil
 }
 
+// writePayloadSize records the size and the digest of the payload of the
+// object at path, whose metadata must already be written, and the time it was
+// written at: a payload modified later was written by an interrupted write,
+// see Reconcile.
+func writePayloadSize(conn *sqlite.Conn, path string, size int64, digest string) error {
+	_, _, kind, _, namespace, name := K8sPathToKeys(path)
+	err := sqlitex.Execute(conn,
+		`UPDATE metadata SET size = ?, digest = ?, updated = ?
+				WHERE kind = ? AND namespace = ? AND name = ?`,
+		&sqlitex.ExecOptions{
+			Args: []any{size, digest, time.Now().UnixNano(), kind, namespace, name},
+		})
+	if err != nil {
+		return fmt.Errorf("write payload size: %w", err)
+	}
+	return nil
+}
+
+// readPayloadDigest returns the digest recorded for the payload of the object
+// at path, empty if none was.
+func readPayloadDigest(conn *sqlite.Conn, path string) (string, error) {
+	_, _, kind, _, namespace, name := K8sPathToKeys(path)
+	var digest string
+	err := sqlitex.Execute(conn,
+		`SELECT digest FROM metadata
+				WHERE kind = ? AND namespace = ? AND name = ?`,
+		&sqlitex.ExecOptions{
+			Args: []any{kind, namespace, name},
+			ResultFunc: func(stmt *sqlite.Stmt) error {
+				digest = stmt.ColumnText(0)
+				return nil
+			},
+		})
+	if err != nil {
+		return "", fmt.Errorf("read payload digest: %w", err)
+	}
+	return digest, nil
+}
+
+// touchMetadata records the metadata of the object at path as up to date
+// with its payload.
+func touchMetadata(conn *sqlite.Conn, path string) error {
+	_, _, kind, _, namespace, name := K8sPathToKeys(path)
+	err := sqlitex.Execute(conn,
+		`UPDATE metadata SET updated = ?
+				WHERE kind = ? AND namespace = ? AND name = ?`,
+		&sqlitex.ExecOptions{
+			Args: []any{time.Now().UnixNano(), kind, namespace, name},
+		})
+	if err != nil {
+		return fmt.Errorf("touch metadata: %w", err)
+	}
+	return nil
+}
+
+// readPayloadSize returns the payload sizes recorded in the metadata, see
+// NewPayloadSizer.
+func readPayloadSize(conn *sqlite.Conn, kind, namespace, name string, limit int64) (int64, error) {
+	query := `SELECT COALESCE(SUM(size), 0) FROM (SELECT size FROM metadata
+				WHERE kind = ? AND (? = '' OR namespace = ?) AND (? = '' OR name = ?)
+				ORDER BY size DESC LIMIT ?)`
+	if limit <= 0 {
+		limit = -1 // no limit
+	}
+	var size int64
+	err := sqlitex.Execute(conn, query, &sqlitex.ExecOptions{
+		Args: []any{kind, namespace, namespace, name, name, limit},
+		ResultFunc: func(stmt *sqlite.Stmt) error {
+			size = stmt.ColumnInt64(0)
+			return nil
+		},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("read payload size: %w", err)
+	}
+	return size, nil
+}
+
+// namespaceUsage is the number of objects of a kind in a namespace and the
+// size of their payloads.
+type namespaceUsage struct {
+	Kind      string
+	Namespace string
+	Objects   int64
+	Bytes     int64
+}
+
+// readNamespaceUsage returns the usage of kind in namespace.
+func readNamespaceUsage(conn *sqlite.Conn, kind, namespace string) (namespaceUsage, error) {
+	usage := namespaceUsage{Kind: kind, Namespace: namespace}
+	err := sqlitex.Execute(conn,
+		`SELECT COUNT(*), COALESCE(SUM(size), 0) FROM metadata
+				WHERE kind = ? AND namespace = ?`,
+		&sqlitex.ExecOptions{
+			Args: []any{kind, namespace},
+			ResultFunc: func(stmt *sqlite.Stmt) error {
+				usage.Objects = stmt.ColumnInt64(0)
+				usage.Bytes = stmt.ColumnInt64(1)
+				return nil
+			},
+		})
+	if err != nil {
+		return usage, fmt.Errorf("read namespace usage: %w", err)
+	}
+	return usage, nil
+}
+
+// listNamespaceUsage returns the usage of every kind in every namespace
+// holding objects of it.
+func listNamespaceUsage(conn *sqlite.Conn) ([]namespaceUsage, error) {
+	var usages []namespaceUsage
+	err := sqlitex.Execute(conn,
+		`SELECT kind, namespace, COUNT(*), COALESCE(SUM(size), 0) FROM metadata
+				WHERE namespace != ''
+				GROUP BY kind, namespace
+				ORDER BY namespace, kind`,
+		&sqlitex.ExecOptions{
+			ResultFunc: func(stmt *sqlite.Stmt) error {
+				usages = append(usages, namespaceUsage{
+					Kind:      stmt.ColumnText(0),
+					Namespace: stmt.ColumnText(1),
+					Objects:   stmt.ColumnInt64(2),
+					Bytes:     stmt.ColumnInt64(3),
+				})
+				return nil
+			},
+		})
+	if err != nil {
+		return nil, fmt.Errorf("list namespace usage: %w", err)
+	}
+	return usages, nil
+}
+
 // WriteTimeSeriesEntry writes a time series entry to the database.
 func WriteTimeSeriesEntry(conn *sqlite.Conn, kind, namespace, name, seriesID, tsSuffix, reportTimestamp, status, completion, previousReportTimestamp string, hasData bool) error {
 	err := sqlitex.Execute(conn,
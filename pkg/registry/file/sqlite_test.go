@@ -38,7 +38,7 @@ func TestMemoryConn(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, int64(2), count)
 	// Test list
-	list, last, err := listMetadata(conn, "/v1/pods", "", int64(500))
+	list, last, err := listMetadata(conn, "/v1/pods", listPosition{}, int64(500))
 	assert.NoError(t, err)
 	assert.Len(t, list, 3)
 	expected := []string{
@@ -47,17 +47,25 @@ func TestMemoryConn(t *testing.T) {
 		"{\"metadata\":{\"name\":\"name\"},\"spec\":{\"containers\":null},\"status\":{}}",
 	}
 	assert.Equal(t, expected, list)
-	assert.Equal(t, "3", last)
+	assert.Equal(t, listPosition{Namespace: "default2", Name: "pod1"}, last)
 	// Test list with limit
-	list, last, err = listMetadata(conn, "/v1/pods/default1", "", int64(1))
+	list, last, err = listMetadata(conn, "/v1/pods/default1", listPosition{}, int64(1))
 	assert.NoError(t, err)
 	assert.Len(t, list, 1)
-	assert.Equal(t, "1", last)
+	assert.Equal(t, listPosition{Namespace: "default1", Name: "pod1"}, last)
+	// Test count after last
+	count, err = countMetadataAfter(conn, "/v1/pods/default1", last)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
 	// Test list with last
 	list, last, err = listMetadata(conn, "/v1/pods/default1", last, int64(500))
 	assert.NoError(t, err)
 	assert.Len(t, list, 1)
-	assert.Equal(t, "2", last)
+	assert.Equal(t, listPosition{Namespace: "default1", Name: "pod2"}, last)
+	// Test revision
+	revision, err := readRevision(conn)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), revision)
 	// Test read
 	b, err := ReadMetadata(conn, "/v1/pods/default2/pod1")
 	assert.NoError(t, err)
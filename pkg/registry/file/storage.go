@@ -1,17 +1,17 @@
 package file
 
 import (
-	"bytes"
 	"context"
-	"encoding/gob"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"strings"
 	"time"
 
@@ -34,11 +34,14 @@ import (
 	"k8s.io/apiserver/pkg/storage"
 	"zombiezen.com/go/sqlite"
 	"zombiezen.com/go/sqlite/sqlitemigration"
+	"zombiezen.com/go/sqlite/sqlitex"
 )
 
 const (
 	DefaultStorageRoot       = "/data"
+	DefaultMetadataPath      = DefaultStorageRoot + "/metadata.sq3"
 	GobExt                   = ".g"
+	TmpExt                   = ".tmp"
 	MetadataExt              = ".m"
 	SchemaVersion            = int64(1)
 	StorageV1Beta1ApiVersion = "spdx.softwarecomposition.kubescape.io/v1beta1"
@@ -125,9 +128,16 @@ type objState struct {
 // hides all the storage-related operations behind it.
 type StorageImpl struct {
 	appFs           afero.Fs
+	codec           PayloadCodec
+	codecs          map[PayloadFormat]PayloadCodec
+	diskMonitor     *DiskMonitor
 	pool            *sqlitemigration.Pool
 	locks           utils.MapMutex[string]
+	managedFields   map[string]bool
+	migrations      *SchemaMigrations
+	migrationDryRun bool
 	processor       Processor
+	quotas          *Quotas
 	root            string
 	scheme          *runtime.Scheme
 	versioner       storage.Versioner
@@ -169,6 +179,22 @@ type StorageQuerier interface {
 	CalculateChecksum(in runtime.Object) (string, error)
 	GetByNamespace(ctx context.Context, apiVersion, kind, namespace string, listObj runtime.Object) error
 	GetByCluster(ctx context.Context, apiVersion, kind string, listObj runtime.Object) error
+	GetSummary(ctx context.Context, kind, namespace string) ([]byte, error)
+	ListSummaries(ctx context.Context, kind string) ([][]byte, error)
+	RebuildSummaries(ctx context.Context) error
+	Reconcile(ctx context.Context, opts ReconcileOptions) (ReconcileReport, error)
+	Scrub(ctx context.Context, opts ScrubOptions) (ScrubReport, error)
+	ListQuarantined() ([]QuarantinedPayload, error)
+	ListGuardrailViolations(ctx context.Context, guardrailName string) ([]softwarecomposition.GuardrailViolation, error)
+	ReevaluateGuardrails(ctx context.Context, resource string, provider GuardrailsProvider) error
+	ListCallStackReferences(ctx context.Context, namespace string, callID softwarecomposition.CallID, frame *softwarecomposition.StackFrame) ([]softwarecomposition.CallStackReference, error)
+	TrackManagedFields(resources ...string)
+	SetQuotas(quotas *Quotas)
+	SetDiskMonitor(monitor *DiskMonitor)
+	SetPayloadCodec(codec PayloadCodec)
+	SetSchemaMigrations(migrations *SchemaMigrations, dryRun bool)
+	SweepMigrations(ctx context.Context, opts SweepOptions) (SweepProgress, error)
+	SaveUnprocessed(ctx context.Context, key string, obj runtime.Object) error
 }
 
 var _ storage.Interface = (*StorageImpl)(nil)
@@ -184,9 +210,14 @@ func NewStorageImplWithCollector(appFs afero.Fs, root string, conn *sqlitemigrat
 		watchDispatcher = NewWatchDispatcher()
 	}
 	storageImpl := &StorageImpl{
-		appFs:           appFs,
+		appFs: appFs,
+		// the payloads are written in gob unless protobuf is set, the
+		// previous releases reading them
+		codec:           gobCodec{},
+		codecs:          map[PayloadFormat]PayloadCodec{PayloadFormatProtobuf: NewProtobufCodec(scheme, v1beta1.SchemeGroupVersion)},
 		pool:            conn,
 		locks:           utils.NewMapMutex[string](),
+		migrations:      DefaultSchemaMigrations(),
 		processor:       processor,
 		root:            root,
 		scheme:          scheme,
@@ -197,12 +228,54 @@ func NewStorageImplWithCollector(appFs afero.Fs, root string, conn *sqlitemigrat
 	return storageImpl
 }
 
+// TrackManagedFields keeps the managed fields of the objects of the given resources,
+// which server-side apply needs to tell field managers apart. They are dropped for
+// every other resource, as they can be as large as the object itself.
+// It must be called before the storage serves any request.
+func (s *StorageImpl) TrackManagedFields(resources ...string) {
+	s.managedFields = make(map[string]bool, len(resources))
+	for _, resource := range resources {
+		s.managedFields[resource] = true
+	}
+}
+
+// SetQuotas enforces quotas on the objects created, nil disables them.
+// It must be called before the storage serves any request.
+func (s *StorageImpl) SetQuotas(quotas *Quotas) {
+	s.quotas = quotas
+}
+
+// SetDiskMonitor rejects writes and fails readiness under disk pressure, nil
+// disables it. It must be called before the storage serves any request.
+func (s *StorageImpl) SetDiskMonitor(monitor *DiskMonitor) {
+	s.diskMonitor = monitor
+}
+
+// SetPayloadCodec writes the payloads with codec, gob by default, the payloads
+// of other formats being rewritten with it when read and by SweepMigrations. It
+// must be called before the storage serves any request.
+func (s *StorageImpl) SetPayloadCodec(codec PayloadCodec) {
+	if s.codecs == nil {
+		s.codecs = map[PayloadFormat]PayloadCodec{}
+	}
+	s.codecs[codec.Format()] = codec
+	s.codec = codec
+}
+
+// GetCurrentResourceVersion returns the revision of the storage, which is also the resourceVersion of lists.
 func (s *StorageImpl) GetCurrentResourceVersion(_ context.Context) (uint64, error) {
-	return 0, nil
+	poolCtx, cancel := poolContext()
+	defer cancel()
+	conn, err := s.pool.Take(poolCtx)
+	if err != nil {
+		return 0, newContentionTimeoutError("get revision", "", err)
+	}
+	defer s.pool.Put(conn)
+	return readRevision(conn)
 }
 
 func (s *StorageImpl) ReadinessCheck() error {
-	return nil
+	return s.diskMonitor.ReadinessCheck()
 }
 
 // Versioner Returns Versioner associated with this interface.
@@ -222,6 +295,85 @@ func extractFields(obj runtime.Object, fields []string) runtime.Object {
 	return ret
 }
 
+// writePayload atomically replaces the payload at payloadPath with the
+// encoding of obj and returns its size and digest, see payloadDigest.
+func (s *StorageImpl) writePayload(payloadPath string, obj runtime.Object) (int64, string, error) {
+	tmpPath := payloadPath + TmpExt
+	payloadFile, err := s.openPayloadFileWithFallback(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return 0, "", fmt.Errorf("open payload file: %w", err)
+	}
+	directIOWriter := NewDirectIOWriter(payloadFile)
+	fail := func(err error) (int64, string, error) {
+		_ = payloadFile.Close()
+		_ = s.appFs.Remove(tmpPath)
+		return 0, "", err
+	}
+	// write payload
+	digest := sha256.New()
+	if err := s.encodePayload(io.MultiWriter(directIOWriter, digest), obj); err != nil {
+		_ = directIOWriter.Close()
+		return fail(fmt.Errorf("encode payload: %w", err))
+	}
+	if err := directIOWriter.Close(); err != nil {
+		return fail(fmt.Errorf("close directIOWriter: %w", err))
+	}
+	if err := payloadFile.Sync(); err != nil {
+		return fail(fmt.Errorf("sync payload file: %w", err))
+	}
+	if err := payloadFile.Close(); err != nil {
+		_ = s.appFs.Remove(tmpPath)
+		return 0, "", fmt.Errorf("close payload file: %w", err)
+	}
+	if err := s.appFs.Rename(tmpPath, payloadPath); err != nil {
+		_ = s.appFs.Remove(tmpPath)
+		return 0, "", fmt.Errorf("rename payload file: %w", err)
+	}
+	// persist the rename, some filesystems cannot sync a directory
+	if dir, err := s.appFs.Open(filepath.Dir(payloadPath)); err == nil {
+		if err := dir.Sync(); err != nil {
+			logger.L().Debug("saveObject - sync directory failed", helpers.Error(err), helpers.String("path", payloadPath))
+		}
+		_ = dir.Close()
+	}
+	return directIOWriter.Size(), hex.EncodeToString(digest.Sum(nil)), nil
+}
+
+// writeIndexes writes metadata, extracted from obj stored at key with a
+// payload of size bytes and digest, and updates the tables derived from obj. The
+// metadata and the summary contribution are written in one savepoint, so that
+// the summaries cannot drift from the objects. The revision of the storage is
+// bumped by the caller.
+func (s *StorageImpl) writeIndexes(conn *sqlite.Conn, key string, obj, metadata runtime.Object, size int64, digest string) (err error) {
+	defer sqlitex.Save(conn)(&err)
+	// store metadata in SQLite
+	metadataJSON, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("marshal metadata: %w", err)
+	}
+	if err := upsertMetadata(conn, key, metadataJSON); err != nil {
+		return fmt.Errorf("write metadata: %w", err)
+	}
+	// record the payload size the quotas are checked against, and its digest
+	// the Scrubber checks
+	if err := writePayloadSize(conn, key, size, digest); err != nil {
+		return err
+	}
+	// update materialized summaries
+	if err := writeSummaryContribution(conn, key, obj); err != nil {
+		return fmt.Errorf("write summary contribution: %w", err)
+	}
+	// index guardrail violations, a failure only leaves them stale until the next write
+	if err := writeGuardrailViolations(conn, key, obj); err != nil {
+		logger.L().Error("saveObject - index guardrail violations failed", helpers.Error(err), helpers.String("key", key))
+	}
+	// index call stack frames, a failure only leaves them stale until the next write
+	if err := writeCallStackFrames(conn, key, obj); err != nil {
+		logger.L().Error("saveObject - index call stack frames failed", helpers.Error(err), helpers.String("key", key))
+	}
+	return nil
+}
+
 // makePayloadPath returns a path for the payload file
 func makePayloadPath(path string) string {
 	return path + GobExt
@@ -241,21 +393,47 @@ func (s *StorageImpl) keyFromPath(path string) string {
 	return strings.TrimPrefix(strings.TrimSuffix(path, extension), s.root)
 }
 
-func (s *StorageImpl) saveObject(conn *sqlite.Conn, key string, obj runtime.Object, metaOut runtime.Object, checksum string) error {
-	// increment resourceVersion
-	if version, err := s.versioner.ObjectResourceVersion(obj); err == nil {
-		if err := s.versioner.UpdateObject(obj, version+1); err != nil {
-			return fmt.Errorf("set resourceVersion: %w", err)
+// resourceDirs returns the directories of the payloads of resources, by
+// default of every resource stored but exclude.
+func (s *StorageImpl) resourceDirs(resources, exclude []string) ([]string, error) {
+	root := filepath.Join(s.root, softwarecomposition.GroupName)
+	if resources == nil {
+		entries, err := afero.ReadDir(s.appFs, root)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("list resources: %w", err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() && !slices.Contains(exclude, entry.Name()) {
+				resources = append(resources, entry.Name())
+			}
 		}
 	}
-	// remove managed fields
-	managedFields := reflect.ValueOf(obj).Elem().FieldByName("ObjectMeta").FieldByName("ManagedFields")
-	if managedFields.IsValid() {
-		managedFields.Set(reflect.Zero(managedFields.Type()))
+	dirs := make([]string, 0, len(resources))
+	for _, resource := range resources {
+		dirs = append(dirs, filepath.Join(root, resource))
+	}
+	return dirs, nil
+}
+
+func (s *StorageImpl) saveObject(conn *sqlite.Conn, key string, obj runtime.Object, metaOut runtime.Object, checksum string) error {
+	// the resourceVersion is the revision of the storage the write makes, so
+	// that the watch events and the lists share one sequence
+	revision, err := bumpRevision(conn)
+	if err != nil {
+		return err
+	}
+	if err := s.versioner.UpdateObject(obj, revision); err != nil {
+		return fmt.Errorf("set resourceVersion: %w", err)
+	}
+	// remove managed fields, unless tracked for the resource
+	if _, _, resource, _, _, _ := K8sPathToKeys(key); !s.managedFields[resource] {
+		managedFields := reflect.ValueOf(obj).Elem().FieldByName("ObjectMeta").FieldByName("ManagedFields")
+		if managedFields.IsValid() {
+			managedFields.Set(reflect.Zero(managedFields.Type()))
+		}
 	}
 	// calculate checksum
 	if checksum == "" {
-		var err error
 		checksum, err = s.CalculateChecksum(obj)
 		if err != nil {
 			return fmt.Errorf("calculate checksum: %w", err)
@@ -272,34 +450,19 @@ func (s *StorageImpl) saveObject(conn *sqlite.Conn, key string, obj runtime.Obje
 	if err := s.appFs.MkdirAll(filepath.Dir(p), 0755); err != nil {
 		return fmt.Errorf("mkdir: %w", err)
 	}
-	// prepare payload file
-	payloadFile, err := s.openPayloadFileWithFallback(makePayloadPath(p), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	// write the payload to a temporary file renamed over the previous one once
+	// synced, a crash leaves either of them whole
+	payloadPath := makePayloadPath(p)
+	size, digest, err := s.writePayload(payloadPath, obj)
 	if err != nil {
-		return fmt.Errorf("open payload file: %w", err)
-	}
-	directIOWriter := NewDirectIOWriter(payloadFile)
-
-	// write payload
-	payloadEncoder := gob.NewEncoder(directIOWriter)
-	if err := payloadEncoder.Encode(obj); err != nil {
-		_ = directIOWriter.Close()
-		_ = payloadFile.Close()
-		return fmt.Errorf("encode payload: %w", err)
-	}
-	if err := directIOWriter.Close(); err != nil {
-		_ = payloadFile.Close()
-		return fmt.Errorf("close directIOWriter: %w", err)
-	}
-	if err := payloadFile.Close(); err != nil {
-		return fmt.Errorf("close payload file: %w", err)
+		return err
 	}
 
-	// extract metadata
+	// the metadata is written once the payload is durable, a crash in between
+	// is repaired from the payload by Reconcile
 	metadata := extractFields(obj, []string{"ObjectMeta", "SchemaVersion"})
-	// store metadata in SQLite
-	err = writeMetadata(conn, key, metadata)
-	if err != nil {
-		return fmt.Errorf("write metadata: %w", err)
+	if err := s.writeIndexes(conn, key, obj, metadata, size, digest); err != nil {
+		return err
 	}
 	// eventually fill metaOut
 	if metaOut != nil {
@@ -332,6 +495,9 @@ func (s *StorageImpl) CreateWithConn(ctx context.Context, conn *sqlite.Conn, key
 	ctx, span := otel.Tracer("").Start(ctx, "StorageImpl.Create")
 	span.SetAttributes(attribute.String("key", key))
 	defer span.End()
+	if err := s.diskMonitor.checkWrite(key); err != nil {
+		return err
+	}
 	_, spanLock := otel.Tracer("").Start(ctx, "waiting for lock")
 	beforeLock := time.Now()
 	lockCtx, lockCancel := context.WithTimeout(ctx, lockTimeout)
@@ -350,6 +516,10 @@ func (s *StorageImpl) CreateWithConn(ctx context.Context, conn *sqlite.Conn, key
 	if _, err := s.appFs.Stat(makePayloadPath(filepath.Join(s.root, key))); err == nil {
 		return storage.NewKeyExistsError(key, 0)
 	}
+	// check the quota of the namespace
+	if err := s.quotas.check(conn, key); err != nil {
+		return err
+	}
 	// resourceVersion should not be set on create
 	if version, err := s.versioner.ObjectResourceVersion(obj); err == nil && version != 0 {
 		msg := "resourceVersion should not be set on objects to be created"
@@ -382,6 +552,8 @@ func (s *StorageImpl) CreateWithConn(ctx context.Context, conn *sqlite.Conn, key
 		logger.L().Ctx(ctx).Error("Create - save object failed", helpers.Error(err), helpers.String("key", key))
 		return err
 	}
+	// a resent object leaves the quarantine
+	_ = s.appFs.Remove(makePayloadPath(filepath.Join(s.root, QuarantineDir, key)))
 	// call processor on saved object
 	if err := s.processor.AfterCreate(ctx, obj); err != nil {
 		return fmt.Errorf("processor.AfterCreate: %w", err)
@@ -391,6 +563,54 @@ func (s *StorageImpl) CreateWithConn(ctx context.Context, conn *sqlite.Conn, key
 	return nil
 }
 
+// SaveUnprocessed stores obj at key as it is, creating or replacing the object,
+// without running the processor. It is meant for copies of objects the processor
+// already ran on, such as the approved revisions of profiles.
+func (s *StorageImpl) SaveUnprocessed(ctx context.Context, key string, obj runtime.Object) error {
+	if err := s.diskMonitor.checkWrite(key); err != nil {
+		return err
+	}
+	lockCtx, lockCancel := context.WithTimeout(ctx, lockTimeout)
+	defer lockCancel()
+	if err := s.locks.Lock(lockCtx, key); err != nil {
+		return newContentionTimeoutError("update", key, err)
+	}
+	defer s.locks.Unlock(key)
+	poolCtx, cancel := poolContext()
+	defer cancel()
+	conn, err := s.pool.Take(poolCtx)
+	if err != nil {
+		return newContentionTimeoutError("update", key, err)
+	}
+	defer s.pool.Put(conn)
+	// the replaced object, if any, tells whether the object is created
+	existing := obj.DeepCopyObject()
+	if err := runtime.SetZeroValue(existing); err != nil {
+		return fmt.Errorf("reset object before read: %w", err)
+	}
+	err = s.get(ctx, conn, key, storage.GetOptions{}, existing, hasWriteLock)
+	created := storage.IsNotFound(err)
+	switch {
+	case created:
+		if err := s.quotas.check(conn, key); err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	}
+	metaOut := existing
+	if err := s.saveObject(conn, key, obj, metaOut, ""); err != nil {
+		logger.L().Ctx(ctx).Error("SaveUnprocessed - save object failed", helpers.Error(err), helpers.String("key", key))
+		return err
+	}
+	if created {
+		s.watchDispatcher.Added(key, metaOut, obj)
+	} else {
+		s.watchDispatcher.Modified(key, metaOut, obj)
+	}
+	return nil
+}
+
 // Delete removes the specified key and returns the value that existed at that spot.
 // If key didn't exist, it will return NotFound storage error.
 // If 'cachedExistingObject' is non-nil, it can be used as a suggestion about the
@@ -454,11 +674,11 @@ func (s *StorageImpl) delete(ctx context.Context, conn *sqlite.Conn, key string,
 
 // Watch begins watching the specified key. Events are decoded into API objects,
 // and any items selected by 'p' are sent down to returned watch.Interface.
-// resourceVersion may be used to specify what version to begin watching,
-// which should be the current resourceVersion, and no longer rv+1
-// (e.g. reconnecting without missing any updates).
-// If resource version is "0", this interface will get current object at given key
-// and send it in an "ADDED" event, before watch starts.
+// Past events are not kept, so a watch always starts at the current revision
+// of the storage without initial events: an unset resourceVersion, "0",
+// ResourceVersionFullSpec or any revision up to the current one, like the
+// resourceVersion of a list or of an event a client resumes from, start it
+// now, and a newer one fails with a too large resourceVersion error.
 func (s *StorageImpl) Watch(ctx context.Context, key string, opts storage.ListOptions) (watch.Interface, error) {
 	_, span := otel.Tracer("").Start(ctx, "StorageImpl.Watch")
 	span.SetAttributes(attribute.String("key", key))
@@ -474,9 +694,34 @@ func (s *StorageImpl) Watch(ctx context.Context, key string, opts storage.ListOp
 	// TODO(ttimonen) Should we do ctx.WithoutCancel; or does the parent ctx lifetime match with expectations?
 	nw := newWatcher(ctx, opts.ResourceVersion == softwarecomposition.ResourceVersionFullSpec)
 	s.watchDispatcher.Register(key, nw)
+	// registered before the revision is read, so that no write after it is missed
+	if err := s.checkWatchResourceVersion(opts.ResourceVersion); err != nil {
+		nw.Stop()
+		return nil, err
+	}
 	return nw, nil
 }
 
+// checkWatchResourceVersion returns an error if a watch cannot start at resourceVersion.
+func (s *StorageImpl) checkWatchResourceVersion(resourceVersion string) error {
+	switch resourceVersion {
+	case "", "0", softwarecomposition.ResourceVersionFullSpec:
+		return nil
+	}
+	requested, err := s.versioner.ParseResourceVersion(resourceVersion)
+	if err != nil {
+		return err
+	}
+	current, err := s.GetCurrentResourceVersion(context.Background())
+	if err != nil {
+		return err
+	}
+	if requested > current {
+		return storage.NewTooLargeResourceVersionError(requested, current, 1)
+	}
+	return nil
+}
+
 // Get unmarshals object found at key into objPtr. On a not found error, will either
 // return a zero object of the requested type, or an error, depending on 'opts.ignoreNotFound'.
 // Treats empty responses and nil response nodes exactly like a not found error.
@@ -583,61 +828,41 @@ func (s *StorageImpl) get(ctx context.Context, conn *sqlite.Conn, key string, op
 	}()
 
 	// Try normal decode first
-	decoder := gob.NewDecoder(NewDirectIOReader(payloadFile))
-	err = decoder.Decode(objPtr)
+	format, err := s.decodePayload(NewDirectIOReader(payloadFile), objPtr)
 	if err == nil {
-		return nil
+		_, _, resource, _, _, _ := K8sPathToKeys(key)
+		pending := !s.migrationDryRun && s.migrations.Pending(resource, objPtr)
+		if !pending && format == s.codecFor(objPtr).Format() {
+			return nil
+		}
+		if s.diskMonitor.checkWrite(key) != nil {
+			// no writes under disk pressure, the object served is migrated and
+			// its payload left to the MigrationSweeper
+			s.migrateInMemory(ctx, key, objPtr)
+			return nil
+		}
+		// the object at an older schema version or in an outdated format is
+		// rewritten as it is served
+		return s.withWriteLock(ctx, key, lockState, &ownedRLock, func() error {
+			s.upgradePayload(ctx, conn, key, objPtr)
+			return nil
+		})
 	}
 
-	// If it fails with type error, or any other gob error, try external migration tool
-	if strings.Contains(err.Error(), "gob: wrong type") || strings.Contains(err.Error(), "extra fields") {
-		logger.L().Ctx(ctx).Info("Get - detected gob type mismatch, attempting external migration", helpers.Error(err), helpers.String("key", key))
-
-		// Acquire a write lock before running the migration tool to prevent
-		// concurrent migration attempts.  All lock transitions are explicit
-		// (no deferred lock calls) so that errors can be checked and the lock
-		// state is always well-defined on every return path.
-		var migErr error
-		switch lockState {
-		case hasReadLock:
-			// Drop the caller's read lock and upgrade to write lock.
-			// After migration, restore the read lock so the caller's deferred
-			// RUnlock (in GetWithConn) has a matching acquire.
-			s.locks.RUnlock(key)
-			if lockErr := s.locks.Lock(ctx, key); lockErr != nil {
-				logger.L().Ctx(ctx).Error("Get - failed to acquire write lock for migration", helpers.Error(lockErr), helpers.String("key", key))
-				// Best-effort: restore the read lock so the caller's deferred
-				// RUnlock is not left unmatched.
-				if rlockErr := s.locks.RLock(ctx, key); rlockErr != nil {
-					logger.L().Ctx(ctx).Error("Get - failed to restore read lock after write lock failure", helpers.Error(rlockErr), helpers.String("key", key))
-				}
-				return fmt.Errorf("failed to acquire write lock for migration: %w", lockErr)
-			}
-			migErr = s.migrateObject(ctx, conn, p, key, opts, objPtr)
-			s.locks.Unlock(key)
-			if rlockErr := s.locks.RLock(ctx, key); rlockErr != nil {
-				logger.L().Ctx(ctx).Error("Get - failed to restore read lock after migration", helpers.Error(rlockErr), helpers.String("key", key))
-				return fmt.Errorf("failed to restore read lock after migration: %w", rlockErr)
-			}
-
-		case noLock:
-			// We hold a temporary read lock (ownedRLock=true); release it and
-			// upgrade to write lock.  Do not re-acquire afterwards — there is
-			// no outer caller expecting a read lock to be held.
-			ownedRLock = false
-			s.locks.RUnlock(key)
-			if lockErr := s.locks.Lock(ctx, key); lockErr != nil {
-				logger.L().Ctx(ctx).Error("Get - failed to acquire write lock for migration", helpers.Error(lockErr), helpers.String("key", key))
-				return fmt.Errorf("failed to acquire write lock for migration: %w", lockErr)
-			}
-			migErr = s.migrateObject(ctx, conn, p, key, opts, objPtr)
-			s.locks.Unlock(key)
-
-		case hasWriteLock:
-			// Already holding the write lock — just migrate.
-			migErr = s.migrateObject(ctx, conn, p, key, opts, objPtr)
+	// If it fails with type error, or any other gob error, try the decoding
+	// schema migrations
+	if isLegacyGobError(err) {
+		if err := s.diskMonitor.checkWrite(key); err != nil {
+			// no writes under disk pressure, the object is only decoded
+			return s.decodeLegacyObject(ctx, key, objPtr)
 		}
-		return migErr
+		logger.L().Ctx(ctx).Info("Get - detected gob type mismatch, attempting migration", helpers.Error(err), helpers.String("key", key))
+
+		// Acquire a write lock before migrating to prevent concurrent
+		// migration attempts.
+		return s.withWriteLock(ctx, key, lockState, &ownedRLock, func() error {
+			return s.migrateObject(ctx, conn, p, key, opts, objPtr)
+		})
 	}
 
 	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
@@ -655,16 +880,67 @@ func (s *StorageImpl) get(ctx context.Context, conn *sqlite.Conn, key string, op
 	return err
 }
 
-// migrateObject runs the external migration tool and unmarshals the output into objPtr.
-// It is used by get() to migrate objects that need external migration.
+// withWriteLock runs fn holding the write lock of key, get() having been
+// called with lockState, and leaves the locks as they were.  All lock
+// transitions are explicit (no deferred lock calls) so that errors can be
+// checked and the lock state is always well-defined on every return path.
+// ownedRLock is cleared when the temporary read lock of a noLock call is
+// released.
+func (s *StorageImpl) withWriteLock(ctx context.Context, key string, lockState getLockState, ownedRLock *bool, fn func() error) error {
+	var err error
+	switch lockState {
+	case hasReadLock:
+		// Drop the caller's read lock and upgrade to write lock.
+		// After the rewrite, restore the read lock so the caller's deferred
+		// RUnlock (in GetWithConn) has a matching acquire.
+		s.locks.RUnlock(key)
+		if lockErr := s.locks.Lock(ctx, key); lockErr != nil {
+			logger.L().Ctx(ctx).Error("Get - failed to acquire write lock for rewrite", helpers.Error(lockErr), helpers.String("key", key))
+			// Best-effort: restore the read lock so the caller's deferred
+			// RUnlock is not left unmatched.
+			if rlockErr := s.locks.RLock(ctx, key); rlockErr != nil {
+				logger.L().Ctx(ctx).Error("Get - failed to restore read lock after write lock failure", helpers.Error(rlockErr), helpers.String("key", key))
+			}
+			return fmt.Errorf("failed to acquire write lock for rewrite: %w", lockErr)
+		}
+		err = fn()
+		s.locks.Unlock(key)
+		if rlockErr := s.locks.RLock(ctx, key); rlockErr != nil {
+			logger.L().Ctx(ctx).Error("Get - failed to restore read lock after rewrite", helpers.Error(rlockErr), helpers.String("key", key))
+			return fmt.Errorf("failed to restore read lock after rewrite: %w", rlockErr)
+		}
+
+	case noLock:
+		// We hold a temporary read lock (ownedRLock=true); release it and
+		// upgrade to write lock.  Do not re-acquire afterwards — there is
+		// no outer caller expecting a read lock to be held.
+		*ownedRLock = false
+		s.locks.RUnlock(key)
+		if lockErr := s.locks.Lock(ctx, key); lockErr != nil {
+			logger.L().Ctx(ctx).Error("Get - failed to acquire write lock for rewrite", helpers.Error(lockErr), helpers.String("key", key))
+			return fmt.Errorf("failed to acquire write lock for rewrite: %w", lockErr)
+		}
+		err = fn()
+		s.locks.Unlock(key)
+
+	case hasWriteLock:
+		// Already holding the write lock — just run fn.
+		err = fn()
+	}
+	return err
+}
+
+// migrateObject decodes the legacy payload of key into objPtr through the
+// schema migrations and migrates it, see migrateLegacyPayload. An object no
+// migration decodes is deleted. It is used by get() to migrate objects the
+// current types fail to decode.
 // The caller must hold the write lock for key before calling this.
 func (s *StorageImpl) migrateObject(ctx context.Context, conn *sqlite.Conn, path, key string, opts storage.GetOptions, objPtr runtime.Object) error {
 	// Re-check if the file still needs migration — another goroutine may have
 	// already migrated it while we were waiting for the write lock.
 	payloadFileRetry, err := s.openPayloadFileWithFallback(makePayloadPath(path), os.O_RDONLY, 0)
 	if err == nil {
-		decoderRetry := gob.NewDecoder(NewDirectIOReader(payloadFileRetry))
-		errRetry := decoderRetry.Decode(objPtr)
+		_, errRetry := s.decodePayload(NewDirectIOReader(payloadFileRetry), objPtr)
 		_ = payloadFileRetry.Close()
 		if errRetry == nil {
 			logger.L().Ctx(ctx).Info("Get - migration already completed by another thread", helpers.String("key", key))
@@ -672,28 +948,11 @@ func (s *StorageImpl) migrateObject(ctx context.Context, conn *sqlite.Conn, path
 		}
 	}
 
-	typeName := "ApplicationProfile"
-	if _, ok := objPtr.(*softwarecomposition.ContainerProfile); ok {
-		typeName = "ContainerProfile"
-	} else if _, ok := objPtr.(*softwarecomposition.SeccompProfile); ok {
-		typeName = "SeccompProfile"
-	}
-
-	migrationCtx, migrationCancel := context.WithTimeout(ctx, 30*time.Second)
-	defer migrationCancel()
-
-	cmd := exec.CommandContext(migrationCtx, "/usr/bin/migration", "-file", makePayloadPath(path), "-type", typeName)
-	var out bytes.Buffer
-	var stderr bytes.Buffer
-	cmd.Stdout = &out
-	cmd.Stderr = &stderr
-	if runErr := cmd.Run(); runErr != nil {
-		if errors.Is(migrationCtx.Err(), context.DeadlineExceeded) {
-			logger.L().Ctx(ctx).Error("Get - migration tool timed out", helpers.String("key", key))
-			return fmt.Errorf("migration tool timed out: %w", migrationCtx.Err())
-		}
-		logger.L().Ctx(ctx).Error("Get - migration tool failed", helpers.Error(runErr), helpers.String("stderr", stderr.String()), helpers.String("key", key))
-		// If migration tool fails, treat as corrupted and delete
+	applied, err := s.migrateLegacyPayload(conn, key, objPtr)
+	switch {
+	case err != nil && len(applied) == 0:
+		logger.L().Ctx(ctx).Error("Get - migration failed", helpers.Error(err), helpers.String("key", key))
+		// If no migration decodes it, treat as corrupted and delete
 		_ = DeleteMetadata(conn, key, nil)
 		_ = s.appFs.Remove(makePayloadPath(path))
 		if opts.IgnoreNotFound {
@@ -701,20 +960,10 @@ func (s *StorageImpl) migrateObject(ctx context.Context, conn *sqlite.Conn, path
 		} else {
 			return storage.NewKeyNotFoundError(key, 0)
 		}
-	}
-
-	// Migration tool outputted JSON, unmarshal it into objPtr
-	if unmarshalErr := json.Unmarshal(out.Bytes(), objPtr); unmarshalErr != nil {
-		logger.L().Ctx(ctx).Error("Get - unmarshal migrated JSON failed", helpers.Error(unmarshalErr), helpers.String("key", key))
-		return unmarshalErr
-	}
-
-	logger.L().Ctx(ctx).Info("Get - external migration successful", helpers.String("key", key))
-
-	if saveErr := s.saveObject(conn, key, objPtr, nil, ""); saveErr != nil {
-		logger.L().Ctx(ctx).Error("Get - failed to rewrite migrated object", helpers.Error(saveErr), helpers.String("key", key))
-	} else {
-		logger.L().Ctx(ctx).Info("Get - successfully migrated object to modern format", helpers.String("key", key))
+	case err != nil:
+		logger.L().Ctx(ctx).Error("Get - failed to rewrite migrated object", helpers.Error(err), helpers.String("key", key))
+	default:
+		logger.L().Ctx(ctx).Info("Get - successfully migrated object", helpers.String("key", key), helpers.Interface("migrations", applied))
 	}
 
 	return nil
@@ -752,16 +1001,20 @@ func (s *StorageImpl) GetListWithConn(ctx context.Context, conn *sqlite.Conn, ke
 		logger.L().Ctx(ctx).Error("GetList - need ptr to slice", helpers.Error(err), helpers.String("key", key))
 		return fmt.Errorf("need ptr to slice: %v", err)
 	}
+	token, err := decodeContinue(key, opts.Predicate.Continue)
+	if err != nil {
+		return err
+	}
 	// set default limit
 	if opts.Predicate.Limit == 0 {
-		opts.Predicate.Limit = 500
+		opts.Predicate.Limit = defaultListLimit
 	}
 	// prepare SQLite connection
 	var list []string
-	var last string
+	var last listPosition
 	if opts.ResourceVersion == softwarecomposition.ResourceVersionFullSpec {
 		// get names from SQLite
-		list, last, err = listMetadataKeys(conn, key, opts.Predicate.Continue, opts.Predicate.Limit)
+		list, last, err = listMetadataKeys(conn, key, token.After, opts.Predicate.Limit)
 		if err != nil {
 			logger.L().Ctx(ctx).Error("GetList - list keys failed", helpers.Error(err), helpers.String("key", key))
 		}
@@ -777,7 +1030,7 @@ func (s *StorageImpl) GetListWithConn(ctx context.Context, conn *sqlite.Conn, ke
 		}
 	} else {
 		// get metadata from SQLite
-		list, last, err = listMetadata(conn, key, opts.Predicate.Continue, opts.Predicate.Limit)
+		list, last, err = listMetadata(conn, key, token.After, opts.Predicate.Limit)
 		if err != nil {
 			logger.L().Ctx(ctx).Error("GetList - list metadata failed", helpers.Error(err), helpers.String("key", key))
 		}
@@ -792,28 +1045,33 @@ func (s *StorageImpl) GetListWithConn(ctx context.Context, conn *sqlite.Conn, ke
 			v.Set(reflect.Append(v, reflect.ValueOf(obj).Elem()))
 		}
 	}
-	// eventually set list accessor fields
+	// the first page sets the resourceVersion of the list, the following ones report it as well
+	resourceVersion := token.ResourceVersion
+	if resourceVersion == 0 {
+		if resourceVersion, err = readRevision(conn); err != nil {
+			return err
+		}
+	}
+	var remaining int64
 	if len(list) == int(opts.Predicate.Limit) {
-		listAccessor, err := meta.ListAccessor(listObj)
-		if err != nil {
-			return fmt.Errorf("list accessor: %w", err)
+		if remaining, err = countMetadataAfter(conn, key, last); err != nil {
+			return err
 		}
-		listAccessor.SetContinue(last)
-		//if rsp.RemainingItemCount > 0 {
-		//listAccessor.SetRemainingItemCount(&rsp.RemainingItemCount)
-		//}
-		//if rsp.ResourceVersion > 0 {
-		//listAccessor.SetResourceVersion(strconv.FormatInt(rsp.ResourceVersion, 10))
-		//}
 	}
-	return nil
+	return setListMeta(listObj, key, opts, resourceVersion, last, remaining)
 }
 
 // getListWithSpec is the same as GetList, but it returns the full objects instead of just the metadata.
-func (s *StorageImpl) getListWithSpec(ctx context.Context, key string, _ storage.ListOptions, listObj runtime.Object) error {
+// Without a limit nor a continue value it reads every payload under key, otherwise the page is
+// selected from SQLite and only its payloads are read.
+func (s *StorageImpl) getListWithSpec(ctx context.Context, key string, opts storage.ListOptions, listObj runtime.Object) error {
 	ctx, span := otel.Tracer("").Start(ctx, "StorageImpl.getListWithSpec")
 	span.SetAttributes(attribute.String("key", key))
 	defer span.End()
+	if opts.Predicate.Limit > 0 || opts.Predicate.Continue != "" {
+		opts.ResourceVersion = softwarecomposition.ResourceVersionFullSpec
+		return s.GetList(ctx, "/"+strings.TrimPrefix(key, "/"), opts, listObj)
+	}
 	listPtr, err := meta.GetItemsPtr(listObj)
 	if err != nil {
 		logger.L().Ctx(ctx).Error("getListWithSpec - get items ptr failed", helpers.Error(err), helpers.String("key", key))
@@ -929,6 +1187,9 @@ func (s *StorageImpl) GuaranteedUpdateWithConn(
 	ctx, span := otel.Tracer("").Start(ctx, "StorageImpl.GuaranteedUpdate")
 	span.SetAttributes(attribute.String("key", key))
 	defer span.End()
+	if err := s.diskMonitor.checkWrite(key); err != nil {
+		return err
+	}
 	_, spanLock := otel.Tracer("").Start(ctx, "waiting for lock")
 	beforeLock := time.Now()
 	lockCtx, lockCancel := context.WithTimeout(ctx, lockTimeout)
@@ -1012,7 +1273,7 @@ func (s *StorageImpl) GuaranteedUpdateWithConn(
 		if err != nil {
 			// If our data is already up-to-date, return the error
 			if origStateIsCurrent {
-				if !apierrors.IsNotFound(err) && !apierrors.IsInvalid(err) {
+				if !apierrors.IsNotFound(err) && !apierrors.IsInvalid(err) && !errors.Is(err, errPendingRevisionNeeded) {
 					logger.L().Ctx(ctx).Error("GuaranteedUpdate - tryUpdate func failed", helpers.Error(err), helpers.String("key", key))
 				}
 				logger.L().Debug("GuaranteedUpdate - tryUpdate func failed", helpers.Error(err), helpers.String("key", key))
@@ -1132,6 +1393,8 @@ func (s *StorageImpl) GetByCluster(ctx context.Context, apiVersion, kind string,
 }
 
 // appendGobObjectFromFile unmarshalls a Gob file into a runtime.Object and appends it to the underlying list object.
+// Like get(), a legacy object, one at an older schema version or in an outdated format is rewritten, and only
+// migrated in memory under disk pressure.
 func (s *StorageImpl) appendGobObjectFromFile(ctx context.Context, path string, v reflect.Value) error {
 	key := s.keyFromPath(path)
 	lockCtx, lockCancel := context.WithTimeout(ctx, lockTimeout)
@@ -1140,7 +1403,13 @@ func (s *StorageImpl) appendGobObjectFromFile(ctx context.Context, path string,
 	if err != nil {
 		return newContentionTimeoutError("list", key, err)
 	}
-	defer s.locks.RUnlock(key)
+	// the read lock is released before an upgrade of the payload
+	readLocked := true
+	defer func() {
+		if readLocked {
+			s.locks.RUnlock(key)
+		}
+	}()
 	payloadFile, err := s.openPayloadFileWithFallback(path, os.O_RDONLY, 0)
 	if err != nil {
 		// skip if file is not readable, maybe it was deleted
@@ -1153,15 +1422,43 @@ func (s *StorageImpl) appendGobObjectFromFile(ctx context.Context, path string,
 	obj := reflect.New(v.Type().Elem()).Interface().(runtime.Object)
 
 	// Try normal decode first
-	decoder := gob.NewDecoder(NewDirectIOReader(payloadFile))
-	err = decoder.Decode(obj)
+	format, err := s.decodePayload(NewDirectIOReader(payloadFile), obj)
+	_, _, resource, _, _, _ := K8sPathToKeys(key)
+	outdated := err == nil && (format != s.codecFor(obj).Format() || (!s.migrationDryRun && s.migrations.Pending(resource, obj)))
+	if outdated && s.diskMonitor.checkWrite(key) != nil {
+		// no writes under disk pressure, the object listed is migrated
+		s.migrateInMemory(ctx, key, obj)
+	} else if outdated {
+		// the object is rewritten as it is listed
+		_ = payloadFile.Close()
+		readLocked = false
+		s.locks.RUnlock(key)
+		upgradeErr := s.locks.Lock(lockCtx, key)
+		if upgradeErr == nil {
+			upgradeErr = s.withConn(func(conn *sqlite.Conn) error {
+				s.upgradePayload(ctx, conn, key, obj)
+				return nil
+			})
+			s.locks.Unlock(key)
+		}
+		if upgradeErr != nil {
+			logger.L().Ctx(ctx).Debug("appendGobObjectFromFile - payload upgrade skipped", helpers.Error(upgradeErr), helpers.String("path", path))
+			s.migrateInMemory(ctx, key, obj)
+		}
+	}
 	if err != nil {
-		// If it fails with type error, try legacy decoding via external tool
-		if strings.Contains(err.Error(), "gob: wrong type") || strings.Contains(err.Error(), "extra fields") {
-			logger.L().Ctx(ctx).Info("appendGobObjectFromFile - detected gob type mismatch, attempting external migration", helpers.Error(err), helpers.String("path", path))
+		// If it fails with type error, try the decoding schema migrations
+		if isLegacyGobError(err) && s.diskMonitor.checkWrite(key) != nil {
+			// no writes under disk pressure, the object is only decoded
+			if err := s.decodeLegacyObject(ctx, key, obj); err != nil {
+				logger.L().Ctx(ctx).Error("appendGobObjectFromFile - migration failed", helpers.Error(err), helpers.String("path", path))
+				return nil
+			}
+		} else if isLegacyGobError(err) {
+			logger.L().Ctx(ctx).Info("appendGobObjectFromFile - detected gob type mismatch, attempting migration", helpers.Error(err), helpers.String("path", path))
 
 			// Rewrite the object in the modern format to complete the migration
-			// We upgrade to a write lock BEFORE running the migration tool to prevent concurrent migration attempts
+			// We upgrade to a write lock BEFORE migrating to prevent concurrent migration attempts
 			s.locks.RUnlock(key)
 			// re-acquire read lock if anything fails or when we are done
 			defer s.locks.RLock(ctx, key)
@@ -1176,8 +1473,7 @@ func (s *StorageImpl) appendGobObjectFromFile(ctx context.Context, path string,
 			// Another thread might have finished the migration while we were waiting for the lock
 			payloadFileRetry, err := s.openPayloadFileWithFallback(path, os.O_RDONLY, 0)
 			if err == nil {
-				decoderRetry := gob.NewDecoder(NewDirectIOReader(payloadFileRetry))
-				errRetry := decoderRetry.Decode(obj)
+				_, errRetry := s.decodePayload(NewDirectIOReader(payloadFileRetry), obj)
 				_ = payloadFileRetry.Close()
 				if errRetry == nil {
 					logger.L().Ctx(ctx).Info("appendGobObjectFromFile - migration already completed by another thread", helpers.String("path", path))
@@ -1186,53 +1482,24 @@ func (s *StorageImpl) appendGobObjectFromFile(ctx context.Context, path string,
 				}
 			}
 
-			typeName := "ApplicationProfile"
-			if _, ok := obj.(*softwarecomposition.ContainerProfile); ok {
-				typeName = "ContainerProfile"
-			} else if _, ok := obj.(*softwarecomposition.SeccompProfile); ok {
-				typeName = "SeccompProfile"
-			}
-
-			// Run migration tool: /usr/bin/migration -file <path> -type <typeName>
-			migrationCtx, migrationCancel := context.WithTimeout(ctx, 30*time.Second)
-			defer migrationCancel()
-
-			cmd := exec.CommandContext(migrationCtx, "/usr/bin/migration", "-file", path, "-type", typeName)
-			var out bytes.Buffer
-			var stderr bytes.Buffer
-			cmd.Stdout = &out
-			cmd.Stderr = &stderr
-			if runErr := cmd.Run(); runErr != nil {
-				if errors.Is(migrationCtx.Err(), context.DeadlineExceeded) {
-					logger.L().Ctx(ctx).Error("appendGobObjectFromFile - migration tool timed out", helpers.String("path", path))
-					return nil // treat as skip/corrupted in list operations
-				}
-				logger.L().Ctx(ctx).Error("appendGobObjectFromFile - migration tool failed", helpers.Error(runErr), helpers.String("stderr", stderr.String()), helpers.String("path", path))
-				// If migration tool fails, treat as corrupted and skip
-				return nil
-			}
-
-			// Migration tool outputted JSON, unmarshal it into obj
-			if unmarshalErr := json.Unmarshal(out.Bytes(), obj); unmarshalErr != nil {
-				logger.L().Ctx(ctx).Error("appendGobObjectFromFile - unmarshal migrated JSON failed", helpers.Error(unmarshalErr), helpers.String("path", path))
-				return unmarshalErr
-			}
-
-			logger.L().Ctx(ctx).Info("appendGobObjectFromFile - external migration successful", helpers.String("path", path))
-
-			// Write modern format back to disk to finish migration
 			poolCtx, cancel := poolContext()
 			defer cancel()
 			conn, err := s.pool.Take(poolCtx)
 			if err != nil {
-				logger.L().Ctx(ctx).Error("appendGobObjectFromFile - failed to take connection for migration save", helpers.Error(err), helpers.String("path", path))
-			} else {
-				defer s.pool.Put(conn)
-				if saveErr := s.saveObject(conn, key, obj, nil, ""); saveErr != nil {
-					logger.L().Ctx(ctx).Error("appendGobObjectFromFile - failed to rewrite migrated object", helpers.Error(saveErr), helpers.String("path", path))
-				} else {
-					logger.L().Ctx(ctx).Info("appendGobObjectFromFile - successfully migrated object to modern format", helpers.String("path", path))
-				}
+				logger.L().Ctx(ctx).Error("appendGobObjectFromFile - failed to take connection for migration", helpers.Error(err), helpers.String("path", path))
+				return nil // skipped, migrated by a later read
+			}
+			defer s.pool.Put(conn)
+			applied, err := s.migrateLegacyPayload(conn, key, obj)
+			switch {
+			case err != nil && len(applied) == 0:
+				logger.L().Ctx(ctx).Error("appendGobObjectFromFile - migration failed", helpers.Error(err), helpers.String("path", path))
+				// If no migration decodes it, treat as corrupted and skip
+				return nil
+			case err != nil:
+				logger.L().Ctx(ctx).Error("appendGobObjectFromFile - failed to rewrite migrated object", helpers.Error(err), helpers.String("path", path))
+			default:
+				logger.L().Ctx(ctx).Info("appendGobObjectFromFile - successfully migrated object", helpers.String("path", path), helpers.Interface("migrations", applied))
 			}
 		} else {
 			return err
@@ -119,7 +119,7 @@ func TestStorageImpl_Create(t *testing.T) {
 
 			conn, err := pool.Take(context.TODO())
 			require.NoError(t, err)
-			l, _, err := listMetadata(conn, tt.args.key, "", int64(500))
+			l, _, err := listMetadata(conn, tt.args.key, listPosition{}, int64(500))
 			assert.NoError(t, err)
 			assert.Len(t, l, 1)
 			pool.Put(conn)
@@ -439,12 +439,14 @@ func TestStorageImpl_GuaranteedUpdate(t *testing.T) {
 			},
 		},
 	}
+	// the resourceVersion is the revision of the storage, which the read of
+	// the missing object already bumped
 	totov1 := &v1beta1.SBOMSyft{
 		ObjectMeta: v1.ObjectMeta{
 			Name:            "toto",
-			ResourceVersion: "1",
+			ResourceVersion: "2",
 			Annotations: map[string]string{
-				helpers.SyncChecksumMetadataKey: "f34e9780b1cab33db857768ff07da40a09ddf003ee31e66cc70acaeb1bf44f40",
+				helpers.SyncChecksumMetadataKey: "138c2c3fc0289745a1dd7fdbec62036589a21a3d2bea0b16b9d39896f04030cb",
 			},
 		},
 		Spec: v1beta1.SBOMSyftSpec{
@@ -458,9 +460,9 @@ func TestStorageImpl_GuaranteedUpdate(t *testing.T) {
 	totov3 := &v1beta1.SBOMSyft{
 		ObjectMeta: v1.ObjectMeta{
 			Name:            "toto",
-			ResourceVersion: "3",
+			ResourceVersion: "2",
 			Annotations: map[string]string{
-				helpers.SyncChecksumMetadataKey: "d378bc779bc63bf3877d24fec9a62b7739aec21cf7489202c461756c07169ae7",
+				helpers.SyncChecksumMetadataKey: "ae4db70f36c4a80f70b9946a8ab98fbea495589da4d1e6e85ae1c791782fdeb5",
 			},
 		},
 		Spec: v1beta1.SBOMSyftSpec{
@@ -791,3 +793,31 @@ func TestStorageImpl_PoolContentionReturnsServerTimeout(t *testing.T) {
 	require.NotNil(t, status.Details)
 	assert.EqualValues(t, 1, status.Details.RetryAfterSeconds)
 }
+
+func TestStorageImpl_TrackManagedFields(t *testing.T) {
+	pool := NewTestPool(t.TempDir())
+	require.NotNil(t, pool)
+	defer func() { _ = pool.Close() }()
+	sch := scheme.Scheme
+	require.NoError(t, softwarecomposition.AddToScheme(sch))
+	s := NewStorageImpl(afero.NewMemMapFs(), DefaultStorageRoot, pool, nil, sch)
+	s.TrackManagedFields("applicationprofiles")
+	ctx := context.TODO()
+	managedFields := []v1.ManagedFieldsEntry{{Manager: "node-agent", Operation: v1.ManagedFieldsOperationApply}}
+
+	apKey := "/spdx.softwarecomposition.kubescape.io/applicationprofiles/default/nginx"
+	require.NoError(t, s.Create(ctx, apKey, &softwarecomposition.ApplicationProfile{
+		ObjectMeta: v1.ObjectMeta{Name: "nginx", Namespace: "default", ManagedFields: managedFields},
+	}, nil, 0))
+	ap := &softwarecomposition.ApplicationProfile{}
+	require.NoError(t, s.Get(ctx, apKey, storage.GetOptions{}, ap))
+	assert.Equal(t, managedFields, ap.ManagedFields)
+
+	sbomKey := "/spdx.softwarecomposition.kubescape.io/sbomsyfts/default/nginx"
+	require.NoError(t, s.Create(ctx, sbomKey, &softwarecomposition.SBOMSyft{
+		ObjectMeta: v1.ObjectMeta{Name: "nginx", Namespace: "default", ManagedFields: managedFields},
+	}, nil, 0))
+	sbom := &softwarecomposition.SBOMSyft{}
+	require.NoError(t, s.Get(ctx, sbomKey, storage.GetOptions{}, sbom))
+	assert.Empty(t, sbom.ManagedFields)
+}
@@ -0,0 +1,525 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/kubescape/go-logger"
+	"github.com/kubescape/go-logger/helpers"
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/storage"
+	"zombiezen.com/go/sqlite"
+	"zombiezen.com/go/sqlite/sqlitex"
+)
+
+const (
+	vulnerabilitySummariesVirtualResource     = "vulnerabilitysummaries"
+	configurationScanSummariesVirtualResource = "configurationscansummaries"
+)
+
+var (
+	ErrSummaryNotFound = errors.New("summary not found")
+)
+
+// summaryRollup maintains the namespace rollups of a virtual summary kind from the
+// objects of its source kind.
+//
+// Every source object has a contribution (its severities and its identifier in the
+// rollup), which is persisted next to the rollup itself. On every write the previous
+// contribution is subtracted from the namespace rollup and the new one added, so
+// serving a summary never needs to decode the source payloads.
+type summaryRollup interface {
+	// virtualKind returns the resource name of the summary kind.
+	virtualKind() string
+	// contribution returns the JSON contribution of a source object.
+	contribution(obj runtime.Object) ([]byte, error)
+	// apply removes the old contribution from rollup and adds the new one, either of them
+	// can be nil. It returns nil when the resulting rollup has no contributions left.
+	apply(rollup []byte, namespace string, old, new []byte) ([]byte, error)
+}
+
+// summaryRollups maps source kinds to the rollups they feed.
+var summaryRollups = map[string]summaryRollup{
+	vulnerabilitySummariesResource:             vulnerabilitySummaryRollup{},
+	workloadConfigurationScanSummariesResource: configurationScanSummaryRollup{},
+}
+
+type vulnerabilitySummaryContribution struct {
+	Severities softwarecomposition.SeveritySummary
+	Scope      softwarecomposition.VulnerabilitiesObjScope
+}
+
+type vulnerabilitySummaryRollup struct{}
+
+func (vulnerabilitySummaryRollup) virtualKind() string {
+	return vulnerabilitySummariesVirtualResource
+}
+
+func (vulnerabilitySummaryRollup) contribution(obj runtime.Object) ([]byte, error) {
+	summary, ok := obj.(*softwarecomposition.VulnerabilityManifestSummary)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object type %T", obj)
+	}
+	return json.Marshal(vulnerabilitySummaryContribution{
+		Severities: summary.Spec.Severities,
+		Scope: softwarecomposition.VulnerabilitiesObjScope{
+			Name:      summary.Name,
+			Namespace: summary.Namespace,
+			Kind:      "vulnerabilitymanifestsummary",
+		},
+	})
+}
+
+func (vulnerabilitySummaryRollup) apply(rollup []byte, namespace string, old, new []byte) ([]byte, error) {
+	summary := softwarecomposition.VulnerabilitySummary{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       vulnerabilitySummaryKind,
+			APIVersion: StorageV1Beta1ApiVersion,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              namespace,
+			CreationTimestamp: metav1.Now(),
+		},
+	}
+	if rollup != nil {
+		if err := json.Unmarshal(rollup, &summary); err != nil {
+			return nil, fmt.Errorf("unmarshal rollup: %w", err)
+		}
+	}
+	// scopes are matched by value, objects sharing the same scope are interchangeable
+	pos := -1
+	if old != nil {
+		var c vulnerabilitySummaryContribution
+		if err := json.Unmarshal(old, &c); err != nil {
+			return nil, fmt.Errorf("unmarshal old contribution: %w", err)
+		}
+		summary.Spec.Severities.Sub(&c.Severities)
+		pos = slices.Index(summary.Spec.WorkloadVulnerabilitiesObj, c.Scope)
+	}
+	if new != nil {
+		var c vulnerabilitySummaryContribution
+		if err := json.Unmarshal(new, &c); err != nil {
+			return nil, fmt.Errorf("unmarshal new contribution: %w", err)
+		}
+		summary.Spec.Severities.Add(&c.Severities)
+		if pos >= 0 {
+			summary.Spec.WorkloadVulnerabilitiesObj[pos] = c.Scope
+		} else {
+			summary.Spec.WorkloadVulnerabilitiesObj = append(summary.Spec.WorkloadVulnerabilitiesObj, c.Scope)
+		}
+	} else if pos >= 0 {
+		summary.Spec.WorkloadVulnerabilitiesObj = slices.Delete(summary.Spec.WorkloadVulnerabilitiesObj, pos, pos+1)
+	}
+	if len(summary.Spec.WorkloadVulnerabilitiesObj) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(summary)
+}
+
+type configurationScanSummaryContribution struct {
+	Severities softwarecomposition.WorkloadConfigurationScanSeveritiesSummary
+	Identifier softwarecomposition.WorkloadConfigurationScanSummaryIdentifier
+}
+
+type configurationScanSummaryRollup struct{}
+
+func (configurationScanSummaryRollup) virtualKind() string {
+	return configurationScanSummariesVirtualResource
+}
+
+func (configurationScanSummaryRollup) contribution(obj runtime.Object) ([]byte, error) {
+	summary, ok := obj.(*softwarecomposition.WorkloadConfigurationScanSummary)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object type %T", obj)
+	}
+	return json.Marshal(configurationScanSummaryContribution{
+		Severities: summary.Spec.Severities,
+		Identifier: softwarecomposition.WorkloadConfigurationScanSummaryIdentifier{
+			Namespace: summary.Namespace,
+			Kind:      "WorkloadConfigurationScanSummary",
+			Name:      summary.Name,
+		},
+	})
+}
+
+func (configurationScanSummaryRollup) apply(rollup []byte, namespace string, old, new []byte) ([]byte, error) {
+	summary := softwarecomposition.ConfigurationScanSummary{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       configurationScanSummaryKind,
+			APIVersion: StorageV1Beta1ApiVersion,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: namespace,
+		},
+	}
+	if rollup != nil {
+		if err := json.Unmarshal(rollup, &summary); err != nil {
+			return nil, fmt.Errorf("unmarshal rollup: %w", err)
+		}
+	}
+	// identifiers are matched by value, objects sharing the same identifier are interchangeable
+	pos := -1
+	if old != nil {
+		var c configurationScanSummaryContribution
+		if err := json.Unmarshal(old, &c); err != nil {
+			return nil, fmt.Errorf("unmarshal old contribution: %w", err)
+		}
+		summary.Spec.Severities.Sub(&c.Severities)
+		pos = slices.Index(summary.Spec.WorkloadConfigurationScanSummaryIdentifiers, c.Identifier)
+	}
+	if new != nil {
+		var c configurationScanSummaryContribution
+		if err := json.Unmarshal(new, &c); err != nil {
+			return nil, fmt.Errorf("unmarshal new contribution: %w", err)
+		}
+		summary.Spec.Severities.Add(&c.Severities)
+		if pos >= 0 {
+			summary.Spec.WorkloadConfigurationScanSummaryIdentifiers[pos] = c.Identifier
+		} else {
+			summary.Spec.WorkloadConfigurationScanSummaryIdentifiers = append(summary.Spec.WorkloadConfigurationScanSummaryIdentifiers, c.Identifier)
+		}
+	} else if pos >= 0 {
+		summary.Spec.WorkloadConfigurationScanSummaryIdentifiers = slices.Delete(summary.Spec.WorkloadConfigurationScanSummaryIdentifiers, pos, pos+1)
+	}
+	if len(summary.Spec.WorkloadConfigurationScanSummaryIdentifiers) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(summary)
+}
+
+// writeSummaryContribution replaces the contribution of the object at path in the rollup
+// of its namespace. It is a no-op for kinds which do not feed any summary.
+func writeSummaryContribution(conn *sqlite.Conn, path string, obj runtime.Object) error {
+	_, _, kind, _, _, _ := K8sPathToKeys(path)
+	r, ok := summaryRollups[kind]
+	if !ok {
+		return nil
+	}
+	c, err := r.contribution(obj)
+	if err != nil {
+		return fmt.Errorf("summary contribution: %w", err)
+	}
+	return replaceSummaryContribution(conn, r, path, c)
+}
+
+// deleteSummaryContribution removes the contribution of the object at path from the rollup
+// of its namespace. It is a no-op for kinds which do not feed any summary.
+func deleteSummaryContribution(conn *sqlite.Conn, path string) error {
+	_, _, kind, _, _, _ := K8sPathToKeys(path)
+	r, ok := summaryRollups[kind]
+	if !ok {
+		return nil
+	}
+	return replaceSummaryContribution(conn, r, path, nil)
+}
+
+func replaceSummaryContribution(conn *sqlite.Conn, r summaryRollup, path string, contribution []byte) (err error) {
+	_, _, kind, _, namespace, name := K8sPathToKeys(path)
+	defer sqlitex.Save(conn)(&err)
+	old, err := readSummaryContribution(conn, kind, namespace, name)
+	if err != nil {
+		return err
+	}
+	if old == nil && contribution == nil {
+		return nil
+	}
+	rollup, err := readSummary(conn, r.virtualKind(), namespace)
+	if err != nil && !errors.Is(err, ErrSummaryNotFound) {
+		return err
+	}
+	rollup, err = r.apply(rollup, namespace, old, contribution)
+	if err != nil {
+		return fmt.Errorf("apply summary contribution: %w", err)
+	}
+	if contribution == nil {
+		err = sqlitex.Execute(conn,
+			`DELETE FROM summary_contributions
+					WHERE kind = ?
+					  AND namespace = ?
+					  AND name = ?`,
+			&sqlitex.ExecOptions{
+				Args: []any{kind, namespace, name},
+			})
+	} else {
+		err = sqlitex.Execute(conn,
+			`INSERT INTO summary_contributions
+					(kind, namespace, name, contribution) VALUES (?, ?, ?, ?)
+					ON CONFLICT (kind, namespace, name) DO UPDATE SET contribution = excluded.contribution`,
+			&sqlitex.ExecOptions{
+				Args: []any{kind, namespace, name, contribution},
+			})
+	}
+	if err != nil {
+		return fmt.Errorf("write summary contribution: %w", err)
+	}
+	return writeSummary(conn, r.virtualKind(), namespace, rollup)
+}
+
+func readSummaryContribution(conn *sqlite.Conn, kind, namespace, name string) ([]byte, error) {
+	var contribution []byte
+	err := sqlitex.Execute(conn,
+		`SELECT contribution FROM summary_contributions
+				WHERE kind = ?
+				  AND namespace = ?
+				  AND name = ?`,
+		&sqlitex.ExecOptions{
+			Args: []any{kind, namespace, name},
+			ResultFunc: func(stmt *sqlite.Stmt) error {
+				contribution = []byte(stmt.ColumnText(0))
+				return nil
+			},
+		})
+	if err != nil {
+		return nil, fmt.Errorf("read summary contribution: %w", err)
+	}
+	return contribution, nil
+}
+
+func readSummary(conn *sqlite.Conn, kind, namespace string) ([]byte, error) {
+	var summary []byte
+	err := sqlitex.Execute(conn,
+		`SELECT summary FROM summaries
+				WHERE kind = ?
+				  AND namespace = ?`,
+		&sqlitex.ExecOptions{
+			Args: []any{kind, namespace},
+			ResultFunc: func(stmt *sqlite.Stmt) error {
+				summary = []byte(stmt.ColumnText(0))
+				return nil
+			},
+		})
+	if err != nil {
+		return nil, fmt.Errorf("read summary: %w", err)
+	}
+	if len(summary) == 0 {
+		return nil, ErrSummaryNotFound
+	}
+	return summary, nil
+}
+
+// writeSummary stores the rollup of a namespace, a nil rollup deletes it.
+func writeSummary(conn *sqlite.Conn, kind, namespace string, rollup []byte) error {
+	var err error
+	if rollup == nil {
+		err = sqlitex.Execute(conn,
+			`DELETE FROM summaries
+					WHERE kind = ?
+					  AND namespace = ?`,
+			&sqlitex.ExecOptions{
+				Args: []any{kind, namespace},
+			})
+	} else {
+		err = sqlitex.Execute(conn,
+			`INSERT OR REPLACE INTO summaries
+					(kind, namespace, summary) VALUES (?, ?, ?)`,
+			&sqlitex.ExecOptions{
+				Args: []any{kind, namespace, rollup},
+			})
+	}
+	if err != nil {
+		return fmt.Errorf("write summary: %w", err)
+	}
+	return nil
+}
+
+func listSummaries(conn *sqlite.Conn, kind string) ([][]byte, error) {
+	var summaries [][]byte
+	err := sqlitex.Execute(conn,
+		`SELECT summary FROM summaries
+				WHERE kind = ?
+				ORDER BY namespace`,
+		&sqlitex.ExecOptions{
+			Args: []any{kind},
+			ResultFunc: func(stmt *sqlite.Stmt) error {
+				summaries = append(summaries, []byte(stmt.ColumnText(0)))
+				return nil
+			},
+		})
+	if err != nil {
+		return nil, fmt.Errorf("list summaries: %w", err)
+	}
+	return summaries, nil
+}
+
+// rebuildSummaries recomputes every namespace rollup of r from the persisted contributions of kind,
+// dropping the contributions whose object no longer exists.
+func rebuildSummaries(conn *sqlite.Conn, kind string, r summaryRollup) (err error) {
+	defer sqlitex.Save(conn)(&err)
+	err = sqlitex.Execute(conn,
+		`DELETE FROM summary_contributions
+				WHERE kind = :kind
+				  AND NOT EXISTS (SELECT 1 FROM metadata m
+						WHERE m.kind = :kind
+						  AND m.namespace = summary_contributions.namespace
+						  AND m.name = summary_contributions.name)`,
+		&sqlitex.ExecOptions{
+			Named: map[string]any{":kind": kind},
+		})
+	if err != nil {
+		return fmt.Errorf("delete orphan summary contributions: %w", err)
+	}
+	rollups := map[string][]byte{}
+	err = sqlitex.Execute(conn,
+		`SELECT namespace, contribution FROM summary_contributions
+				WHERE kind = ?
+				ORDER BY rowid`,
+		&sqlitex.ExecOptions{
+			Args: []any{kind},
+			ResultFunc: func(stmt *sqlite.Stmt) error {
+				namespace := stmt.ColumnText(0)
+				rollup, err := r.apply(rollups[namespace], namespace, nil, []byte(stmt.ColumnText(1)))
+				if err != nil {
+					return err
+				}
+				rollups[namespace] = rollup
+				return nil
+			},
+		})
+	if err != nil {
+		return fmt.Errorf("fold summary contributions: %w", err)
+	}
+	err = sqlitex.Execute(conn, `DELETE FROM summaries WHERE kind = ?`, &sqlitex.ExecOptions{
+		Args: []any{r.virtualKind()},
+	})
+	if err != nil {
+		return fmt.Errorf("delete summaries: %w", err)
+	}
+	for namespace, rollup := range rollups {
+		if err := writeSummary(conn, r.virtualKind(), namespace, rollup); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetSummary returns the materialized rollup of a virtual summary kind for a namespace.
+func (s *StorageImpl) GetSummary(ctx context.Context, kind, namespace string) ([]byte, error) {
+	poolCtx, cancel := poolContext()
+	defer cancel()
+	conn, err := s.pool.Take(poolCtx)
+	if err != nil {
+		return nil, newContentionTimeoutError("get", kind, err)
+	}
+	defer s.pool.Put(conn)
+	return readSummary(conn, kind, namespace)
+}
+
+// ListSummaries returns the materialized rollups of a virtual summary kind for every namespace.
+func (s *StorageImpl) ListSummaries(ctx context.Context, kind string) ([][]byte, error) {
+	poolCtx, cancel := poolContext()
+	defer cancel()
+	conn, err := s.pool.Take(poolCtx)
+	if err != nil {
+		return nil, newContentionTimeoutError("list", kind, err)
+	}
+	defer s.pool.Put(conn)
+	return listSummaries(conn, kind)
+}
+
+// RebuildSummaries is the consistency check of the materialized summaries. The
+// contributions are written along the metadata of their object, only the
+// objects missing theirs, stored before the summaries were maintained, are
+// decoded to backfill them. All rollups are then recomputed from the
+// contributions.
+func (s *StorageImpl) RebuildSummaries(ctx context.Context) error {
+	for kind, r := range summaryRollups {
+		var after listPosition
+		for {
+			keys, last, err := s.listMissingSummaryContributions(kind, after)
+			if err != nil {
+				return err
+			}
+			for _, key := range keys {
+				if err := s.refreshSummaryContribution(ctx, key); err != nil {
+					logger.L().Ctx(ctx).Warning("RebuildSummaries - refresh contribution failed", helpers.Error(err), helpers.String("key", key))
+				}
+			}
+			if len(keys) < defaultListLimit {
+				break
+			}
+			after = last
+		}
+		if err := s.withConn(func(conn *sqlite.Conn) error {
+			return rebuildSummaries(conn, kind, r)
+		}); err != nil {
+			return fmt.Errorf("rebuild %s: %w", r.virtualKind(), err)
+		}
+	}
+	return nil
+}
+
+// listMissingSummaryContributions returns the keys of at most defaultListLimit
+// objects of kind without a summary contribution, starting after the given
+// position.
+func (s *StorageImpl) listMissingSummaryContributions(kind string, after listPosition) ([]string, listPosition, error) {
+	var keys []string
+	var last listPosition
+	err := s.withConn(func(conn *sqlite.Conn) error {
+		return sqlitex.Execute(conn,
+			`SELECT namespace, name FROM metadata m
+					WHERE kind = :kind
+					  AND (namespace, name) > (:afterNamespace, :afterName)
+					  AND NOT EXISTS (SELECT 1 FROM summary_contributions c
+							WHERE c.kind = m.kind
+							  AND c.namespace = m.namespace
+							  AND c.name = m.name)
+					ORDER BY namespace, name
+					LIMIT :limit`,
+			&sqlitex.ExecOptions{
+				Named: map[string]any{":kind": kind, ":afterNamespace": after.Namespace, ":afterName": after.Name, ":limit": defaultListLimit},
+				ResultFunc: func(stmt *sqlite.Stmt) error {
+					last = listPosition{Namespace: stmt.ColumnText(0), Name: stmt.ColumnText(1)}
+					keys = append(keys, K8sKeysToPath("", softwarecomposition.GroupName, kind, "", last.Namespace, last.Name))
+					return nil
+				},
+			})
+	})
+	if err != nil {
+		return nil, listPosition{}, fmt.Errorf("list missing summary contributions: %w", err)
+	}
+	return keys, last, nil
+}
+
+// withConn runs fn with a connection of the pool.
+func (s *StorageImpl) withConn(fn func(conn *sqlite.Conn) error) error {
+	poolCtx, cancel := poolContext()
+	defer cancel()
+	conn, err := s.pool.Take(poolCtx)
+	if err != nil {
+		return fmt.Errorf("take connection: %w", err)
+	}
+	defer s.pool.Put(conn)
+	return fn(conn)
+}
+
+// refreshSummaryContribution rewrites the contribution of the object at key, holding its lock
+// so that it cannot race with a concurrent write of the same object.
+func (s *StorageImpl) refreshSummaryContribution(ctx context.Context, key string) error {
+	lockCtx, lockCancel := context.WithTimeout(ctx, lockTimeout)
+	defer lockCancel()
+	if err := s.locks.Lock(lockCtx, key); err != nil {
+		return newContentionTimeoutError("get", key, err)
+	}
+	defer s.locks.Unlock(key)
+	_, _, kind, _, _, _ := K8sPathToKeys(key)
+	var obj runtime.Object
+	switch kind {
+	case vulnerabilitySummariesResource:
+		obj = &softwarecomposition.VulnerabilityManifestSummary{}
+	case workloadConfigurationScanSummariesResource:
+		obj = &softwarecomposition.WorkloadConfigurationScanSummary{}
+	default:
+		return nil
+	}
+	return s.withConn(func(conn *sqlite.Conn) error {
+		if err := s.get(ctx, conn, key, storage.GetOptions{}, obj, hasWriteLock); err != nil {
+			return err
+		}
+		return writeSummaryContribution(conn, key, obj)
+	})
+}
@@ -0,0 +1,194 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"github.com/kubescape/storage/pkg/generated/clientset/versioned/scheme"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/storage"
+	"zombiezen.com/go/sqlite/sqlitex"
+)
+
+func newVulnerabilityManifestSummary(namespace, name string, critical int64) *softwarecomposition.VulnerabilityManifestSummary {
+	return &softwarecomposition.VulnerabilityManifestSummary{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: softwarecomposition.VulnerabilityManifestSummarySpec{
+			Severities: softwarecomposition.SeveritySummary{
+				Critical: softwarecomposition.VulnerabilityCounters{All: critical},
+			},
+		},
+	}
+}
+
+func getVulnerabilitySummary(t *testing.T, s *StorageImpl, namespace string) *softwarecomposition.VulnerabilitySummary {
+	data, err := s.GetSummary(context.TODO(), vulnerabilitySummariesVirtualResource, namespace)
+	if err != nil {
+		assert.ErrorIs(t, err, ErrSummaryNotFound)
+		return nil
+	}
+	summary := &softwarecomposition.VulnerabilitySummary{}
+	require.NoError(t, json.Unmarshal(data, summary))
+	return summary
+}
+
+func TestStorageImpl_Summaries(t *testing.T) {
+	pool := NewTestPool(t.TempDir())
+	require.NotNil(t, pool)
+	defer func() { _ = pool.Close() }()
+	sch := scheme.Scheme
+	require.NoError(t, softwarecomposition.AddToScheme(sch))
+	s := NewStorageImpl(afero.NewMemMapFs(), "/", pool, nil, sch).(*StorageImpl)
+	ctx := context.TODO()
+	keyA := "/spdx.softwarecomposition.kubescape.io/vulnerabilitymanifestsummaries/default/a"
+	keyB := "/spdx.softwarecomposition.kubescape.io/vulnerabilitymanifestsummaries/default/b"
+
+	// create two objects in the same namespace
+	require.NoError(t, s.Create(ctx, keyA, newVulnerabilityManifestSummary("default", "a", 1), nil, 0))
+	require.NoError(t, s.Create(ctx, keyB, newVulnerabilityManifestSummary("default", "b", 2), nil, 0))
+	summary := getVulnerabilitySummary(t, s, "default")
+	require.NotNil(t, summary)
+	assert.Equal(t, "default", summary.Name)
+	assert.Equal(t, int64(3), summary.Spec.Severities.Critical.All)
+	assert.Len(t, summary.Spec.WorkloadVulnerabilitiesObj, 2)
+
+	// updating an object replaces its contribution
+	err := s.GuaranteedUpdate(ctx, keyA, &softwarecomposition.VulnerabilityManifestSummary{}, true, nil, func(_ runtime.Object, _ storage.ResponseMeta) (runtime.Object, *uint64, error) {
+		return newVulnerabilityManifestSummary("default", "a", 5), nil, nil
+	}, nil)
+	require.NoError(t, err)
+	summary = getVulnerabilitySummary(t, s, "default")
+	require.NotNil(t, summary)
+	assert.Equal(t, int64(7), summary.Spec.Severities.Critical.All)
+	assert.Len(t, summary.Spec.WorkloadVulnerabilitiesObj, 2)
+
+	// deleting an object removes its contribution
+	require.NoError(t, s.Delete(ctx, keyA, &softwarecomposition.VulnerabilityManifestSummary{}, nil, nil, nil, storage.DeleteOptions{}))
+	summary = getVulnerabilitySummary(t, s, "default")
+	require.NotNil(t, summary)
+	assert.Equal(t, int64(2), summary.Spec.Severities.Critical.All)
+	assert.Equal(t, []softwarecomposition.VulnerabilitiesObjScope{{Namespace: "default", Name: "b", Kind: "vulnerabilitymanifestsummary"}}, summary.Spec.WorkloadVulnerabilitiesObj)
+
+	// the rebuild restores a lost rollup from the payloads
+	conn, err := pool.Take(ctx)
+	require.NoError(t, err)
+	require.NoError(t, sqlitex.Execute(conn, "DELETE FROM summaries", nil))
+	pool.Put(conn)
+	assert.Nil(t, getVulnerabilitySummary(t, s, "default"))
+	require.NoError(t, s.RebuildSummaries(ctx))
+	summary = getVulnerabilitySummary(t, s, "default")
+	require.NotNil(t, summary)
+	assert.Equal(t, int64(2), summary.Spec.Severities.Critical.All)
+	assert.Len(t, summary.Spec.WorkloadVulnerabilitiesObj, 1)
+
+	// only the objects missing their contribution are decoded again
+	_, _, err = s.writePayload(makePayloadPath(keyB), newVulnerabilityManifestSummary("default", "b", 9))
+	require.NoError(t, err)
+	require.NoError(t, s.RebuildSummaries(ctx))
+	assert.Equal(t, int64(2), getVulnerabilitySummary(t, s, "default").Spec.Severities.Critical.All)
+	conn, err = pool.Take(ctx)
+	require.NoError(t, err)
+	require.NoError(t, sqlitex.Execute(conn, "DELETE FROM summary_contributions", nil))
+	pool.Put(conn)
+	require.NoError(t, s.RebuildSummaries(ctx))
+	assert.Equal(t, int64(9), getVulnerabilitySummary(t, s, "default").Spec.Severities.Critical.All)
+
+	// deleting the last object removes the rollup
+	require.NoError(t, s.Delete(ctx, keyB, &softwarecomposition.VulnerabilityManifestSummary{}, nil, nil, nil, storage.DeleteOptions{}))
+	assert.Nil(t, getVulnerabilitySummary(t, s, "default"))
+	summaries, err := s.ListSummaries(ctx, vulnerabilitySummariesVirtualResource)
+	require.NoError(t, err)
+	assert.Empty(t, summaries)
+}
+
+func TestStorageImpl_SummariesMatchBuilders(t *testing.T) {
+	pool := NewTestPool(t.TempDir())
+	require.NotNil(t, pool)
+	defer func() { _ = pool.Close() }()
+	sch := scheme.Scheme
+	require.NoError(t, softwarecomposition.AddToScheme(sch))
+	s := NewStorageImpl(afero.NewMemMapFs(), "/", pool, nil, sch).(*StorageImpl)
+	ctx := context.TODO()
+
+	vulnerabilities := map[string]*softwarecomposition.VulnerabilityManifestSummary{}
+	configurations := map[string]*softwarecomposition.WorkloadConfigurationScanSummary{}
+	for i, namespace := range []string{"alpha", "alpha", "alpha", "beta", "beta", "gamma"} {
+		name := fmt.Sprintf("workload-%d", i)
+		vulnerability := newVulnerabilityManifestSummary(namespace, name, int64(i+1))
+		key := "/spdx.softwarecomposition.kubescape.io/vulnerabilitymanifestsummaries/" + namespace + "/" + name
+		require.NoError(t, s.Create(ctx, key, vulnerability, nil, 0))
+		vulnerabilities[key] = vulnerability
+		configuration := &softwarecomposition.WorkloadConfigurationScanSummary{
+			ObjectMeta: v1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: softwarecomposition.WorkloadConfigurationScanSummarySpec{
+				Severities: softwarecomposition.WorkloadConfigurationScanSeveritiesSummary{High: int64(i), Low: 1},
+			},
+		}
+		key = "/spdx.softwarecomposition.kubescape.io/workloadconfigurationscansummaries/" + namespace + "/" + name
+		require.NoError(t, s.Create(ctx, key, configuration, nil, 0))
+		configurations[key] = configuration
+	}
+	// update and delete some of them, emptying a namespace
+	for _, key := range []string{
+		"/spdx.softwarecomposition.kubescape.io/vulnerabilitymanifestsummaries/alpha/workload-0",
+		"/spdx.softwarecomposition.kubescape.io/vulnerabilitymanifestsummaries/beta/workload-3",
+	} {
+		updated := newVulnerabilityManifestSummary(vulnerabilities[key].Namespace, vulnerabilities[key].Name, 10)
+		require.NoError(t, s.GuaranteedUpdate(ctx, key, &softwarecomposition.VulnerabilityManifestSummary{}, true, nil, func(_ runtime.Object, _ storage.ResponseMeta) (runtime.Object, *uint64, error) {
+			return updated, nil, nil
+		}, nil))
+		vulnerabilities[key] = updated
+	}
+	for _, key := range []string{
+		"/spdx.softwarecomposition.kubescape.io/vulnerabilitymanifestsummaries/gamma/workload-5",
+		"/spdx.softwarecomposition.kubescape.io/vulnerabilitymanifestsummaries/alpha/workload-1",
+	} {
+		require.NoError(t, s.Delete(ctx, key, &softwarecomposition.VulnerabilityManifestSummary{}, nil, nil, nil, storage.DeleteOptions{}))
+		delete(vulnerabilities, key)
+	}
+	key := "/spdx.softwarecomposition.kubescape.io/workloadconfigurationscansummaries/beta/workload-4"
+	require.NoError(t, s.Delete(ctx, key, &softwarecomposition.WorkloadConfigurationScanSummary{}, nil, nil, nil, storage.DeleteOptions{}))
+	delete(configurations, key)
+
+	// the rollups equal the summaries recomputed from scratch
+	var vulnerabilityList softwarecomposition.VulnerabilityManifestSummaryList
+	for _, vulnerability := range vulnerabilities {
+		vulnerabilityList.Items = append(vulnerabilityList.Items, *vulnerability)
+	}
+	want := buildVulnerabilitySummaryForCluster(vulnerabilityList)
+	rollups, err := s.ListSummaries(ctx, vulnerabilitySummariesVirtualResource)
+	require.NoError(t, err)
+	require.Len(t, rollups, len(want.Items))
+	for _, expected := range want.Items {
+		got := getVulnerabilitySummary(t, s, expected.Name)
+		require.NotNil(t, got, expected.Name)
+		assert.Equal(t, expected.Spec.Severities, got.Spec.Severities, expected.Name)
+		assert.ElementsMatch(t, expected.Spec.WorkloadVulnerabilitiesObj, got.Spec.WorkloadVulnerabilitiesObj, expected.Name)
+	}
+	var configurationList softwarecomposition.WorkloadConfigurationScanSummaryList
+	for _, configuration := range configurations {
+		configurationList.Items = append(configurationList.Items, *configuration)
+	}
+	wantConfigurations := buildConfigurationScanSummaryForCluster(configurationList)
+	rollups, err = s.ListSummaries(ctx, configurationScanSummariesVirtualResource)
+	require.NoError(t, err)
+	require.Len(t, rollups, len(wantConfigurations.Items))
+	for _, expected := range wantConfigurations.Items {
+		data, err := s.GetSummary(ctx, configurationScanSummariesVirtualResource, expected.Name)
+		require.NoError(t, err, expected.Name)
+		var got softwarecomposition.ConfigurationScanSummary
+		require.NoError(t, json.Unmarshal(data, &got))
+		assert.Equal(t, expected.Spec.Severities, got.Spec.Severities, expected.Name)
+		assert.ElementsMatch(t, expected.Spec.WorkloadConfigurationScanSummaryIdentifiers, got.Spec.WorkloadConfigurationScanSummaryIdentifiers, expected.Name)
+	}
+}
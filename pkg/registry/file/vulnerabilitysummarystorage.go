@@ -3,16 +3,17 @@ package file
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/kubescape/go-logger"
 	"github.com/kubescape/go-logger/helpers"
 	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
-	"github.com/kubescape/storage/pkg/apis/softwarecomposition/v1beta1"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/apiserver/pkg/storage"
 )
 
@@ -23,7 +24,7 @@ const (
 
 // VulnerabilitySummaryStorage implements a storage for vulnerability summaries.
 //
-// It provides vulnerability summaries for scopes like namespace and cluster. To get these summaries, the storage fetches existing stored VulnerabilitySummary objects and aggregates them on the fly.
+// It provides vulnerability summaries for scopes like namespace and cluster. These summaries are materialized rollups of the stored VulnerabilityManifestSummary objects, maintained by the underlying storage on every write.
 type VulnerabilitySummaryStorage struct {
 	immutableStorage
 	realStore StorageQuerier
@@ -49,66 +50,9 @@ func NewVulnerabilitySummaryStorage(realStore StorageQuerier) storage.Interface
 	return &VulnerabilitySummaryStorage{realStore: realStore}
 }
 
-func buildVulnerabilityScanSummary(vulnerabilityManifestSummaryList softwarecomposition.VulnerabilityManifestSummaryList, namespace string) softwarecomposition.VulnerabilitySummary {
-	vulnerabilityScanSummaryObj := softwarecomposition.VulnerabilitySummary{
-		TypeMeta: v1.TypeMeta{
-			Kind:       vulnerabilitySummaryKind,
-			APIVersion: StorageV1Beta1ApiVersion,
-		},
-		ObjectMeta: v1.ObjectMeta{
-			Name:              namespace,
-			CreationTimestamp: v1.Now(),
-		},
-	}
-
-	for i := range vulnerabilityManifestSummaryList.Items {
-		vulnerabilityScanSummaryObj.Merge(&vulnerabilityManifestSummaryList.Items[i])
-	}
-
-	return vulnerabilityScanSummaryObj
-}
-
-// buildConfigurationScanSummaryForCluster generates a vulnerability summary list for the cluster, where each item is a vulnerability summary for a namespace
-func buildVulnerabilitySummaryForCluster(vulnerabilityManifestSummaryList softwarecomposition.VulnerabilityManifestSummaryList) softwarecomposition.VulnerabilitySummaryList {
-
-	// build a map of namespace to workload vulnerability summaries
-	mapNamespaceToSummaries := make(map[string][]softwarecomposition.VulnerabilityManifestSummary)
-
-	for _, vlSummary := range vulnerabilityManifestSummaryList.Items {
-		if _, ok := mapNamespaceToSummaries[vlSummary.Namespace]; !ok {
-			mapNamespaceToSummaries[vlSummary.Namespace] = make([]softwarecomposition.VulnerabilityManifestSummary, 0)
-		}
-		mapNamespaceToSummaries[vlSummary.Namespace] = append(mapNamespaceToSummaries[vlSummary.Namespace], vlSummary)
-	}
-
-	vulnerabilitySummaryList := softwarecomposition.VulnerabilitySummaryList{
-		TypeMeta: v1.TypeMeta{
-			Kind:       vulnerabilitySummaryKind,
-			APIVersion: StorageV1Beta1ApiVersion,
-		},
-	}
-
-	// 1 - build a workload vulnerability summary list for each namespace
-	// 2 - generate a single vulnerability summary for the namespace
-	// 3 - add the vulnerability summary to the cluster summary list object
-	for namespace, vlSummaries := range mapNamespaceToSummaries {
-		// for each namespace, create a single workload vulnerability summary object
-		nsListObj := softwarecomposition.VulnerabilityManifestSummaryList{
-			TypeMeta: v1.TypeMeta{
-				Kind:       vulnerabilitySummaryKind,
-				APIVersion: StorageV1Beta1ApiVersion,
-			},
-			Items: vlSummaries,
-		}
-
-		vulnerabilitySummaryList.Items = append(vulnerabilitySummaryList.Items, buildVulnerabilityScanSummary(nsListObj, namespace))
-	}
-
-	return vulnerabilitySummaryList
-}
-
-func (s *VulnerabilitySummaryStorage) GetCurrentResourceVersion(_ context.Context) (uint64, error) {
-	return 0, nil
+// GetCurrentResourceVersion returns the revision of the storage the summaries are maintained in.
+func (s *VulnerabilitySummaryStorage) GetCurrentResourceVersion(ctx context.Context) (uint64, error) {
+	return s.realStore.GetCurrentResourceVersion(ctx)
 }
 
 func (s *VulnerabilitySummaryStorage) Get(ctx context.Context, key string, _ storage.GetOptions, objPtr runtime.Object) error {
@@ -116,27 +60,13 @@ func (s *VulnerabilitySummaryStorage) Get(ctx context.Context, key string, _ sto
 	span.SetAttributes(attribute.String("key", key))
 	defer span.End()
 
-	vulnerabilityManifestSummaryListObjPtr := &softwarecomposition.VulnerabilityManifestSummaryList{}
-
 	namespace := getNamespaceFromKey(key)
 
-	if err := s.realStore.GetByNamespace(ctx, v1beta1.GroupName, vulnerabilitySummariesResource, namespace, vulnerabilityManifestSummaryListObjPtr); err != nil {
-		return err
-	}
-
-	if &vulnerabilityManifestSummaryListObjPtr == nil {
-		return storage.NewInternalError(fmt.Errorf("workload scan summary list is nil"))
-	}
-
-	if len(vulnerabilityManifestSummaryListObjPtr.Items) == 0 {
+	data, err := s.realStore.GetSummary(ctx, vulnerabilitySummariesVirtualResource, namespace)
+	if errors.Is(err, ErrSummaryNotFound) {
 		return storage.NewKeyNotFoundError(key, 0)
 	}
-
-	vulnerabilitySummaryObj := buildVulnerabilityScanSummary(*vulnerabilityManifestSummaryListObjPtr, namespace)
-
-	data, err := json.Marshal(vulnerabilitySummaryObj)
 	if err != nil {
-		logger.L().Ctx(ctx).Error("json marshal failed", helpers.Error(err), helpers.String("key", key))
 		return err
 	}
 
@@ -153,31 +83,48 @@ func (s *VulnerabilitySummaryStorage) GetList(ctx context.Context, key string, o
 	span.SetAttributes(attribute.String("key", key))
 	defer span.End()
 
-	vulnerabilityManifestSummaryListObjPtr := &softwarecomposition.VulnerabilityManifestSummaryList{}
-
-	// ask for all vulnerabilitySummaries in the cluster.
-	// The underlying store caps each page at a default limit and returns a
-	// continuation token, so we must page through every token to make sure the
-	// aggregation covers all objects and not just the first page (issue #337).
-	listOpts := opts
-	listOpts.Predicate.Continue = ""
-	for {
-		page := &softwarecomposition.VulnerabilityManifestSummaryList{}
-		if err := s.realStore.GetList(ctx, "/spdx.softwarecomposition.kubescape.io/"+vulnerabilitySummariesResource, listOpts, page); err != nil {
+	// the namespace summaries are maintained incrementally on every write of their
+	// vulnerabilitymanifestsummaries, so listing them does not touch the payloads
+	summaries, err := s.realStore.ListSummaries(ctx, vulnerabilitySummariesVirtualResource)
+	if err != nil {
+		return err
+	}
+
+	nsSummaries := softwarecomposition.VulnerabilitySummaryList{
+		TypeMeta: v1.TypeMeta{
+			Kind:       vulnerabilitySummaryKind,
+			APIVersion: StorageV1Beta1ApiVersion,
+		},
+	}
+	for _, data := range summaries {
+		var summary softwarecomposition.VulnerabilitySummary
+		if err := json.Unmarshal(data, &summary); err != nil {
+			logger.L().Ctx(ctx).Error("json unmarshal failed", helpers.Error(err), helpers.String("key", key))
 			return err
 		}
-		vulnerabilityManifestSummaryListObjPtr.Items = append(vulnerabilityManifestSummaryListObjPtr.Items, page.Items...)
-
-		// the store only sets a continuation token when the page was truncated;
-		// an empty token means we have read the last page.
-		listOpts.Predicate.Continue = page.Continue
-		if listOpts.Predicate.Continue == "" {
-			break
+		// the selectors apply to the summaries, before they are paged
+		if !hasSelectors(opts.Predicate) || matches(opts.Predicate, &summary) {
+			nsSummaries.Items = append(nsSummaries.Items, summary)
 		}
 	}
 
-	// generate a single vulnerabilitySummary for the cluster, with a vulnerability summary for each namespace
-	nsSummaries := buildVulnerabilitySummaryForCluster(*vulnerabilityManifestSummaryListObjPtr)
+	// the summaries are few, one per namespace, so they are paged in memory
+	page, token, last, remaining, err := paginate(key, opts, nsSummaries.Items, func(summary softwarecomposition.VulnerabilitySummary) listPosition {
+		return listPosition{Name: summary.Name}
+	})
+	if err != nil {
+		return err
+	}
+	nsSummaries.Items = page
+	resourceVersion := token.ResourceVersion
+	if resourceVersion == 0 {
+		if resourceVersion, err = s.realStore.GetCurrentResourceVersion(ctx); err != nil {
+			return err
+		}
+	}
+	if err := setListMeta(&nsSummaries, key, opts, resourceVersion, last, remaining); err != nil {
+		return err
+	}
 
 	data, err := json.Marshal(nsSummaries)
 	if err != nil {
@@ -192,3 +139,46 @@ func (s *VulnerabilitySummaryStorage) GetList(ctx context.Context, key string, o
 
 	return nil
 }
+
+// Watch emits the namespace VulnerabilitySummary matching opts whenever one of the VulnerabilityManifestSummary
+// objects of that namespace changes, and a Deleted one when the last of them is removed.
+func (s *VulnerabilitySummaryStorage) Watch(ctx context.Context, key string, opts storage.ListOptions) (watch.Interface, error) {
+	source, err := s.realStore.Watch(ctx, "/"+softwarecomposition.GroupName+"/"+vulnerabilitySummariesResource, derivedWatchSourceOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	return newDerivedWatch(ctx, source, key, opts, s, derivedWatchCoalesceWindow), nil
+}
+
+func (s *VulnerabilitySummaryStorage) derivedKey(ev watch.Event) (string, bool) {
+	return namespaceSummaryKey(ev, vulnerabilitySummariesVirtualResource)
+}
+
+func (s *VulnerabilitySummaryStorage) resolve(ctx context.Context, key string) (runtime.Object, error) {
+	obj := &softwarecomposition.VulnerabilitySummary{}
+	return obj, s.Get(ctx, key, storage.GetOptions{}, obj)
+}
+
+func (s *VulnerabilitySummaryStorage) list(ctx context.Context, key string) (map[string]runtime.Object, error) {
+	list := &softwarecomposition.VulnerabilitySummaryList{}
+	if err := s.GetList(ctx, key, storage.ListOptions{Recursive: true}, list); err != nil {
+		return nil, err
+	}
+	objs := make(map[string]runtime.Object, len(list.Items))
+	for i := range list.Items {
+		objs[K8sClusterScopedKeysToPath("", softwarecomposition.GroupName, vulnerabilitySummariesVirtualResource, list.Items[i].Name)] = &list.Items[i]
+	}
+	return objs, nil
+}
+
+func (s *VulnerabilitySummaryStorage) tombstone(key string) runtime.Object {
+	return &softwarecomposition.VulnerabilitySummary{
+		TypeMeta: v1.TypeMeta{
+			Kind:       vulnerabilitySummaryKind,
+			APIVersion: StorageV1Beta1ApiVersion,
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name: getNamespaceFromKey(key),
+		},
+	}
+}
@@ -13,6 +13,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apiserver/pkg/storage"
 	"zombiezen.com/go/sqlite/sqlitemigration"
@@ -95,10 +97,14 @@ func TestVulnSummaryStorageImpl_Delete(t *testing.T) {
 }
 
 func TestVulnSummaryStorageImpl_Watch(t *testing.T) {
-	storageImpl := NewStorageImpl(afero.NewMemMapFs(), "", nil, nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pool := NewTestPool(t.TempDir())
+	defer func() { _ = pool.Close() }()
+	storageImpl := NewStorageImpl(afero.NewMemMapFs(), "", pool, nil, nil)
 	vulnerabilitySummaryStorage := NewVulnerabilitySummaryStorage(storageImpl)
 
-	_, err := vulnerabilitySummaryStorage.Watch(context.TODO(), "", storage.ListOptions{})
+	_, err := vulnerabilitySummaryStorage.Watch(ctx, "/spdx.softwarecomposition.kubescape.io/vulnerabilitysummaries", storage.ListOptions{})
 	assert.NoError(t, err)
 }
 
@@ -130,6 +136,10 @@ func TestVulnSummaryStorageImpl_GetList(t *testing.T) {
 								Kind:       "VulnerabilitySummary",
 								APIVersion: "spdx.softwarecomposition.kubescape.io/v1beta1",
 							},
+							// summaries are grouped by the namespace of the key
+							ObjectMeta: v1.ObjectMeta{
+								Name: "any",
+							},
 							Spec: softwarecomposition.VulnerabilitySummarySpec{
 								WorkloadVulnerabilitiesObj: []softwarecomposition.VulnerabilitiesObjScope{
 									{
@@ -165,6 +175,11 @@ func TestVulnSummaryStorageImpl_GetList(t *testing.T) {
 								Name: "any",
 							},
 							Spec: softwarecomposition.VulnerabilitySummarySpec{
+								Severities: softwarecomposition.SeveritySummary{
+									Negligible: softwarecomposition.VulnerabilityCounters{
+										All: 1,
+									},
+								},
 								WorkloadVulnerabilitiesObj: []softwarecomposition.VulnerabilitiesObjScope{
 									{
 										Name:      "any",
@@ -183,6 +198,11 @@ func TestVulnSummaryStorageImpl_GetList(t *testing.T) {
 								Name: "many",
 							},
 							Spec: softwarecomposition.VulnerabilitySummarySpec{
+								Severities: softwarecomposition.SeveritySummary{
+									Critical: softwarecomposition.VulnerabilityCounters{
+										All: 1,
+									},
+								},
 								WorkloadVulnerabilitiesObj: []softwarecomposition.VulnerabilitiesObjScope{
 									{
 										Kind:      "vulnerabilitymanifestsummary",
@@ -262,6 +282,9 @@ func TestVulnSummaryStorageImpl_GetList(t *testing.T) {
 					o.Items[i].CreationTimestamp = tt.args.createdObj[i].CreationTimestamp
 				}
 				assert.Equal(t, tt.args.expectedObj.TypeMeta, o.TypeMeta)
+				// the list resourceVersion is the revision of the storage
+				assert.NotEmpty(t, o.ResourceVersion)
+				o.ResourceVersion = ""
 				assert.Equal(t, tt.args.expectedObj.ListMeta, o.ListMeta)
 				assert.ElementsMatch(t, tt.args.expectedObj.Items, o.Items)
 			}
@@ -329,6 +352,47 @@ func TestVulnSummaryStorageImpl_GetList_Pagination(t *testing.T) {
 	assert.Equal(t, betaCount, scopesByNamespace["beta"], "beta must not be truncated/dropped by the page limit")
 }
 
+func TestVulnSummaryStorageImpl_GetList_Selectors(t *testing.T) {
+	pool := NewTestPool(t.TempDir())
+	require.NotNil(t, pool)
+	defer func(pool *sqlitemigration.Pool) {
+		_ = pool.Close()
+	}(pool)
+	sch := scheme.Scheme
+	require.NoError(t, softwarecomposition.AddToScheme(sch))
+	realStorage := NewStorageImpl(afero.NewMemMapFs(), "/", pool, nil, sch)
+	ctx := context.TODO()
+	for _, namespace := range []string{"alpha", "beta", "gamma"} {
+		key := fmt.Sprintf("/spdx.softwarecomposition.kubescape.io/vulnerabilitymanifestsummaries/%s/workload", namespace)
+		require.NoError(t, realStorage.Create(ctx, key, newVulnerabilityManifestSummary(namespace, "workload", 1), nil, 0))
+	}
+	s := NewVulnerabilitySummaryStorage(realStorage)
+	getAttrs := func(obj runtime.Object) (labels.Set, fields.Set, error) {
+		summary := obj.(*softwarecomposition.VulnerabilitySummary)
+		return summary.Labels, fields.Set{"metadata.name": summary.Name}, nil
+	}
+
+	// the selectors apply before the limit
+	o := &softwarecomposition.VulnerabilitySummaryList{}
+	require.NoError(t, s.GetList(ctx, "/spdx.softwarecomposition.kubescape.io/vulnerabilitysummaries", storage.ListOptions{Predicate: storage.SelectionPredicate{
+		Label:    labels.Everything(),
+		Field:    fields.OneTermNotEqualSelector("metadata.name", "alpha"),
+		GetAttrs: getAttrs,
+		Limit:    1,
+	}}, o))
+	require.Len(t, o.Items, 1)
+	assert.Equal(t, "beta", o.Items[0].Name)
+	assert.NotEmpty(t, o.Continue)
+
+	o = &softwarecomposition.VulnerabilitySummaryList{}
+	require.NoError(t, s.GetList(ctx, "/spdx.softwarecomposition.kubescape.io/vulnerabilitysummaries", storage.ListOptions{Predicate: storage.SelectionPredicate{
+		Label:    labels.SelectorFromSet(labels.Set{"team": "a"}),
+		Field:    fields.Everything(),
+		GetAttrs: getAttrs,
+	}}, o))
+	assert.Empty(t, o.Items)
+}
+
 func TestVulnSummaryStorageImpl_GuaranteedUpdate(t *testing.T) {
 	type args struct {
 		key                  string
@@ -468,3 +532,63 @@ func TestVulnSummaryStorageImpl_Get(t *testing.T) {
 		})
 	}
 }
+
+// buildVulnerabilityScanSummary merges the vulnerability manifest summaries of a namespace from scratch, the
+// oracle the materialized rollups are checked against
+func buildVulnerabilityScanSummary(vulnerabilityManifestSummaryList softwarecomposition.VulnerabilityManifestSummaryList, namespace string) softwarecomposition.VulnerabilitySummary {
+	vulnerabilityScanSummaryObj := softwarecomposition.VulnerabilitySummary{
+		TypeMeta: v1.TypeMeta{
+			Kind:       vulnerabilitySummaryKind,
+			APIVersion: StorageV1Beta1ApiVersion,
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name:              namespace,
+			CreationTimestamp: v1.Now(),
+		},
+	}
+
+	for i := range vulnerabilityManifestSummaryList.Items {
+		vulnerabilityScanSummaryObj.Merge(&vulnerabilityManifestSummaryList.Items[i])
+	}
+
+	return vulnerabilityScanSummaryObj
+}
+
+// buildVulnerabilitySummaryForCluster generates a vulnerability summary list for the cluster, where each item is a vulnerability summary for a namespace
+func buildVulnerabilitySummaryForCluster(vulnerabilityManifestSummaryList softwarecomposition.VulnerabilityManifestSummaryList) softwarecomposition.VulnerabilitySummaryList {
+
+	// build a map of namespace to workload vulnerability summaries
+	mapNamespaceToSummaries := make(map[string][]softwarecomposition.VulnerabilityManifestSummary)
+
+	for _, vlSummary := range vulnerabilityManifestSummaryList.Items {
+		if _, ok := mapNamespaceToSummaries[vlSummary.Namespace]; !ok {
+			mapNamespaceToSummaries[vlSummary.Namespace] = make([]softwarecomposition.VulnerabilityManifestSummary, 0)
+		}
+		mapNamespaceToSummaries[vlSummary.Namespace] = append(mapNamespaceToSummaries[vlSummary.Namespace], vlSummary)
+	}
+
+	vulnerabilitySummaryList := softwarecomposition.VulnerabilitySummaryList{
+		TypeMeta: v1.TypeMeta{
+			Kind:       vulnerabilitySummaryKind,
+			APIVersion: StorageV1Beta1ApiVersion,
+		},
+	}
+
+	// 1 - build a workload vulnerability summary list for each namespace
+	// 2 - generate a single vulnerability summary for the namespace
+	// 3 - add the vulnerability summary to the cluster summary list object
+	for namespace, vlSummaries := range mapNamespaceToSummaries {
+		// for each namespace, create a single workload vulnerability summary object
+		nsListObj := softwarecomposition.VulnerabilityManifestSummaryList{
+			TypeMeta: v1.TypeMeta{
+				Kind:       vulnerabilitySummaryKind,
+				APIVersion: StorageV1Beta1ApiVersion,
+			},
+			Items: vlSummaries,
+		}
+
+		vulnerabilitySummaryList.Items = append(vulnerabilitySummaryList.Items, buildVulnerabilityScanSummary(nsListObj, namespace))
+	}
+
+	return vulnerabilitySummaryList
+}
@@ -6,7 +6,9 @@ import (
 	"slices"
 	"sync"
 
+	"github.com/kubescape/storage/pkg/queuemanager"
 	"github.com/puzpuzpuz/xsync/v2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
 )
@@ -69,10 +71,16 @@ type watcher struct {
 	stop           context.CancelFunc
 	outCh, inCh    chan watch.Event
 	sendFullObject bool
+	// shed is closed when the watch admission sheds the watch, which then
+	// ends with an error event asking the client to retry after retryAfter
+	// seconds, see queuemanager.WatchAdmission.
+	shed       <-chan struct{}
+	retryAfter int
 }
 
 // newWatcher creates a new watcher
 func newWatcher(ctx context.Context, sendFullObject bool) *watcher {
+	shed, retryAfter := queuemanager.WatchShed(ctx)
 	ctx, cn := context.WithCancel(ctx)
 	w := &watcher{
 		ctx:            ctx,
@@ -80,6 +88,8 @@ func newWatcher(ctx context.Context, sendFullObject bool) *watcher {
 		outCh:          make(chan watch.Event, 100),
 		inCh:           make(chan watch.Event),
 		sendFullObject: sendFullObject,
+		shed:           shed,
+		retryAfter:     retryAfter,
 	}
 	go w.shipIt()
 	return w
@@ -98,16 +108,32 @@ func (w *watcher) shipIt() {
 		select { // we want both reads and writes to be interruptable, hence complexity here.
 		case <-w.ctx.Done():
 			return
+		case <-w.shed:
+			w.shedIt()
+			return
 		case ev = <-w.inCh:
 		}
 		select {
 		case <-w.ctx.Done():
 			return
+		case <-w.shed:
+			w.shedIt()
+			return
 		case w.outCh <- ev:
 		}
 	}
 }
 
+// shedIt sends the error event of a shed watch and stops it.
+func (w *watcher) shedIt() {
+	status := apierrors.NewTooManyRequests("too many watches, retry later", w.retryAfter).Status()
+	select {
+	case <-w.ctx.Done():
+	case w.outCh <- watch.Event{Type: watch.Error, Object: &status}:
+	}
+	w.stop()
+}
+
 func (w *watcher) notify(eventFull, eventMeta watch.Event) {
 	if w.sendFullObject {
 		w.send(eventFull)
@@ -2,6 +2,7 @@ package file
 
 import (
 	"context"
+	"strconv"
 	"testing"
 	"time"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
 	"github.com/kubescape/storage/pkg/apis/softwarecomposition/v1beta1"
 	"github.com/kubescape/storage/pkg/generated/clientset/versioned/scheme"
+	"github.com/kubescape/storage/pkg/queuemanager"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -98,6 +100,89 @@ func TestFileSystemStorageWatchReturnsDistinctWatchers(t *testing.T) {
 	}
 }
 
+func TestStorageImpl_WatchResourceVersion(t *testing.T) {
+	pool := NewTestPool(t.TempDir())
+	defer func() { _ = pool.Close() }()
+	sch := scheme.Scheme
+	require.NoError(t, softwarecomposition.AddToScheme(sch))
+	s := NewStorageImpl(afero.NewMemMapFs(), DefaultStorageRoot, pool, nil, sch)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	key := "/spdx.softwarecomposition.kubescape.io/applicationprofiles"
+	for _, name := range []string{"nginx", "redis"} {
+		require.NoError(t, s.Create(ctx, key+"/default/"+name, &softwarecomposition.ApplicationProfile{
+			ObjectMeta: v1.ObjectMeta{Name: name, Namespace: "default"},
+		}, nil, 0))
+	}
+	current, err := s.GetCurrentResourceVersion(ctx)
+	require.NoError(t, err)
+	require.Greater(t, current, uint64(1))
+	watchFrom := func(resourceVersion string) error {
+		w, err := s.Watch(ctx, key, storage.ListOptions{ResourceVersion: resourceVersion})
+		if err == nil {
+			w.Stop()
+		}
+		return err
+	}
+
+	// past events are not replayed, an older revision starts the watch now
+	for _, resourceVersion := range []string{"", "0", softwarecomposition.ResourceVersionFullSpec, strconv.FormatUint(current, 10), strconv.FormatUint(current-1, 10)} {
+		assert.NoError(t, watchFrom(resourceVersion), resourceVersion)
+	}
+	assert.True(t, storage.IsTooLargeResourceVersion(watchFrom(strconv.FormatUint(current+1, 10))))
+	assert.Error(t, watchFrom("invalid"))
+}
+
+func TestStorageImpl_WatchResume(t *testing.T) {
+	pool := NewTestPool(t.TempDir())
+	defer func() { _ = pool.Close() }()
+	sch := scheme.Scheme
+	require.NoError(t, softwarecomposition.AddToScheme(sch))
+	s := NewStorageImpl(afero.NewMemMapFs(), DefaultStorageRoot, pool, nil, sch)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	key := "/spdx.softwarecomposition.kubescape.io/applicationprofiles"
+	create := func(name string) {
+		require.NoError(t, s.Create(ctx, key+"/default/"+name, &softwarecomposition.ApplicationProfile{
+			ObjectMeta: v1.ObjectMeta{Name: name, Namespace: "default"},
+		}, &softwarecomposition.ApplicationProfile{}, 0))
+	}
+	next := func(w watch.Interface) watch.Event {
+		select {
+		case ev := <-w.ResultChan():
+			return ev
+		case <-time.After(time.Second):
+			require.FailNow(t, "no event")
+			return watch.Event{}
+		}
+	}
+
+	w, err := s.Watch(ctx, key, storage.ListOptions{})
+	require.NoError(t, err)
+	create("nginx")
+	ev := next(w)
+	w.Stop()
+	resourceVersion := ev.Object.(*softwarecomposition.ApplicationProfile).ResourceVersion
+	// the event carries the revision of the storage its write made
+	current, err := s.GetCurrentResourceVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, strconv.FormatUint(current, 10), resourceVersion)
+
+	// other keys are written before the client resumes from its last event
+	create("redis")
+	create("postgres")
+	w, err = s.Watch(ctx, key, storage.ListOptions{ResourceVersion: resourceVersion})
+	require.NoError(t, err)
+	defer w.Stop()
+	create("mysql")
+	ev = next(w)
+	assert.Equal(t, watch.Added, ev.Type)
+	assert.Equal(t, "mysql", ev.Object.(*softwarecomposition.ApplicationProfile).Name)
+	got, err := strconv.ParseUint(ev.Object.(*softwarecomposition.ApplicationProfile).ResourceVersion, 10, 64)
+	require.NoError(t, err)
+	assert.Greater(t, got, current+1)
+}
+
 func TestFilesystemStorageWatchPublishing(t *testing.T) {
 	var (
 		keyN = "/spdx.softwarecomposition.kubescape.io/sbomsyfts"
@@ -344,3 +429,25 @@ func TestWatchGuaranteedUpdateProducesMatchingEvents(t *testing.T) {
 		})
 	}
 }
+
+func TestWatcherShed(t *testing.T) {
+	shed := make(chan struct{})
+	w := newWatcher(queuemanager.WithWatchShed(context.Background(), shed, 10), true)
+	obj := &v1beta1.SBOMSyft{ObjectMeta: v1.ObjectMeta{Name: "some-sbom"}}
+	w.notify(watch.Event{Type: watch.Added, Object: obj}, watch.Event{})
+	ev, ok := <-w.ResultChan()
+	require.True(t, ok)
+	assert.Equal(t, watch.Added, ev.Type)
+
+	close(shed)
+	ev, ok = <-w.ResultChan()
+	require.True(t, ok)
+	assert.Equal(t, watch.Error, ev.Type)
+	status, ok := ev.Object.(*v1.Status)
+	require.True(t, ok)
+	assert.Equal(t, v1.StatusReasonTooManyRequests, status.Reason)
+	assert.Equal(t, int32(429), status.Code)
+	assert.Equal(t, int32(10), status.Details.RetryAfterSeconds)
+	_, ok = <-w.ResultChan()
+	assert.False(t, ok)
+}
@@ -12,6 +12,7 @@ import (
 	"k8s.io/apiserver/pkg/registry/generic"
 	"k8s.io/apiserver/pkg/storage"
 	"k8s.io/apiserver/pkg/storage/names"
+	"sigs.k8s.io/structured-merge-diff/v6/fieldpath"
 
 	"github.com/kubescape/go-logger"
 	"github.com/kubescape/k8s-interface/instanceidhandler/v1/helpers"
@@ -143,3 +144,8 @@ func (ApplicationProfileStrategy) ValidateUpdate(_ context.Context, obj, _ runti
 func (ApplicationProfileStrategy) WarningsOnUpdate(_ context.Context, _, _ runtime.Object) []string {
 	return nil
 }
+
+// GetResetFields returns the fields set by the storage, which server-side apply ignores.
+func (ApplicationProfileStrategy) GetResetFields() map[fieldpath.APIVersion]*fieldpath.Set {
+	return common.ProfileResetFields()
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2024 The Kubescape Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package callstackquery
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"github.com/kubescape/storage/pkg/registry/file/callstack"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apiserver/pkg/registry/rest"
+)
+
+// ReferencesFunc lists the indexed call stack frames of the profiles of
+// namespace, restricted to callID and to frame when they are set.
+type ReferencesFunc func(ctx context.Context, namespace string, callID softwarecomposition.CallID, frame *softwarecomposition.StackFrame) ([]softwarecomposition.CallStackReference, error)
+
+// REST serves the callstacks subresource of ApplicationProfiles and
+// ContainerProfiles: a CallStackQuery posted to it is answered with the
+// unified call stacks of the profile, as read through the parent resource,
+// and with the frames matching the query across the namespace. Nothing is
+// stored.
+type REST struct {
+	profiles   rest.Getter
+	references ReferencesFunc
+}
+
+var _ rest.NamedCreater = &REST{}
+
+// NewREST returns the callstacks subresource of the resource served by
+// profiles.
+func NewREST(profiles rest.Getter, references ReferencesFunc) *REST {
+	return &REST{profiles: profiles, references: references}
+}
+
+func (r *REST) New() runtime.Object {
+	return &softwarecomposition.CallStackQuery{}
+}
+
+func (r *REST) Destroy() {}
+
+func (r *REST) Create(ctx context.Context, name string, obj runtime.Object, createValidation rest.ValidateObjectFunc, _ *metav1.CreateOptions) (runtime.Object, error) {
+	q, ok := obj.(*softwarecomposition.CallStackQuery)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("not a CallStackQuery: %T", obj))
+	}
+	if q.Spec.CallID == "" && q.Spec.Frame == nil {
+		return nil, apierrors.NewInvalid(softwarecomposition.Kind("CallStackQuery"), name, field.ErrorList{
+			field.Required(field.NewPath("spec"), "one of callID and frame is required"),
+		})
+	}
+	if createValidation != nil {
+		if err := createValidation(ctx, obj.DeepCopyObject()); err != nil {
+			return nil, err
+		}
+	}
+	profile, err := r.profiles.Get(ctx, name, &metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	stacks, err := profileCallStacks(profile, q.Spec.ContainerName)
+	if err != nil {
+		return nil, apierrors.NewBadRequest(err.Error())
+	}
+	namespace := profile.(metav1.Object).GetNamespace()
+	references, err := r.references(ctx, namespace, q.Spec.CallID, q.Spec.Frame)
+	if err != nil {
+		return nil, err
+	}
+	out := q.DeepCopy()
+	out.Name = name
+	out.Namespace = namespace
+	out.Status = softwarecomposition.CallStackQueryStatus{
+		CallStacks: matchingCallStacks(stacks, &q.Spec),
+		References: references,
+	}
+	return out, nil
+}
+
+// profileCallStacks returns the call stacks of the container of an
+// ApplicationProfile named containerName, of all its containers when it is
+// empty, or of a ContainerProfile.
+func profileCallStacks(obj runtime.Object, containerName string) ([]softwarecomposition.IdentifiedCallStack, error) {
+	switch o := obj.(type) {
+	case *softwarecomposition.ApplicationProfile:
+		var stacks []softwarecomposition.IdentifiedCallStack
+		found := false
+		for _, containers := range [][]softwarecomposition.ApplicationProfileContainer{o.Spec.Containers, o.Spec.InitContainers, o.Spec.EphemeralContainers} {
+			for _, c := range containers {
+				if containerName == "" || c.Name == containerName {
+					stacks = append(stacks, c.IdentifiedCallStacks...)
+					found = true
+				}
+			}
+		}
+		if containerName != "" && !found {
+			return nil, fmt.Errorf("container %q not found in the profile", containerName)
+		}
+		return stacks, nil
+	case *softwarecomposition.ContainerProfile:
+		return o.Spec.IdentifiedCallStacks, nil
+	default:
+		return nil, fmt.Errorf("call stacks are not recorded in %T", obj)
+	}
+}
+
+// matchingCallStacks unifies the stacks leading to the CallID of spec and
+// going through its frame, the stacks of several containers included.
+func matchingCallStacks(stacks []softwarecomposition.IdentifiedCallStack, spec *softwarecomposition.CallStackQuerySpec) []softwarecomposition.IdentifiedCallStack {
+	var matching []softwarecomposition.IdentifiedCallStack
+	for _, stack := range stacks {
+		if spec.CallID != "" && stack.CallID != spec.CallID {
+			continue
+		}
+		if spec.Frame != nil && !callstack.ContainsFrame(stack.CallStack, *spec.Frame) {
+			continue
+		}
+		matching = append(matching, stack)
+	}
+	return callstack.UnifyIdentifiedCallStacks(matching)
+}
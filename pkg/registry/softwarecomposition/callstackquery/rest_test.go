@@ -0,0 +1,74 @@
+package callstackquery
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type getterFunc func(ctx context.Context, name string, options *metav1.GetOptions) (runtime.Object, error)
+
+func (f getterFunc) Get(ctx context.Context, name string, options *metav1.GetOptions) (runtime.Object, error) {
+	return f(ctx, name, options)
+}
+
+func stack(callID softwarecomposition.CallID, frames ...softwarecomposition.StackFrame) softwarecomposition.IdentifiedCallStack {
+	var children []softwarecomposition.CallStackNode
+	for i := len(frames) - 1; i >= 0; i-- {
+		children = []softwarecomposition.CallStackNode{{Frame: frames[i], Children: children}}
+	}
+	return softwarecomposition.IdentifiedCallStack{CallID: callID, CallStack: softwarecomposition.CallStack{Root: softwarecomposition.CallStackNode{Children: children}}}
+}
+
+func TestREST_Create(t *testing.T) {
+	main := softwarecomposition.StackFrame{FileID: "1", Lineno: "10"}
+	read := softwarecomposition.StackFrame{FileID: "2", Lineno: "20"}
+	write := softwarecomposition.StackFrame{FileID: "3", Lineno: "30"}
+	r := NewREST(getterFunc(func(_ context.Context, name string, _ *metav1.GetOptions) (runtime.Object, error) {
+		if name != "nginx" {
+			return nil, apierrors.NewNotFound(softwarecomposition.Resource("applicationprofiles"), name)
+		}
+		return &softwarecomposition.ApplicationProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: softwarecomposition.ApplicationProfileSpec{
+				Containers: []softwarecomposition.ApplicationProfileContainer{
+					{Name: "nginx", IdentifiedCallStacks: []softwarecomposition.IdentifiedCallStack{stack("open", main, read), stack("exec", write)}},
+					{Name: "sidecar", IdentifiedCallStacks: []softwarecomposition.IdentifiedCallStack{stack("open", main, write)}},
+				},
+			},
+		}, nil
+	}), func(_ context.Context, namespace string, callID softwarecomposition.CallID, frame *softwarecomposition.StackFrame) ([]softwarecomposition.CallStackReference, error) {
+		return []softwarecomposition.CallStackReference{{Kind: "ApplicationProfile", Name: namespace, CallID: callID, Frame: *frame}}, nil
+	})
+	ctx := context.TODO()
+
+	// the stacks of every container leading to the CallID are unified
+	obj, err := r.Create(ctx, "nginx", &softwarecomposition.CallStackQuery{Spec: softwarecomposition.CallStackQuerySpec{CallID: "open", Frame: &main}}, nil, &metav1.CreateOptions{})
+	require.NoError(t, err)
+	out := obj.(*softwarecomposition.CallStackQuery)
+	assert.Equal(t, "default", out.Namespace)
+	require.Len(t, out.Status.CallStacks, 1)
+	assert.Equal(t, softwarecomposition.CallID("open"), out.Status.CallStacks[0].CallID)
+	assert.Len(t, out.Status.CallStacks[0].CallStack.Root.Children[0].Children, 2)
+	assert.Equal(t, []softwarecomposition.CallStackReference{{Kind: "ApplicationProfile", Name: "default", CallID: "open", Frame: main}}, out.Status.References)
+
+	// a frame selects the stacks going through it, in the selected container
+	obj, err = r.Create(ctx, "nginx", &softwarecomposition.CallStackQuery{Spec: softwarecomposition.CallStackQuerySpec{ContainerName: "nginx", Frame: &write}}, nil, &metav1.CreateOptions{})
+	require.NoError(t, err)
+	out = obj.(*softwarecomposition.CallStackQuery)
+	require.Len(t, out.Status.CallStacks, 1)
+	assert.Equal(t, softwarecomposition.CallID("exec"), out.Status.CallStacks[0].CallID)
+
+	_, err = r.Create(ctx, "nginx", &softwarecomposition.CallStackQuery{Spec: softwarecomposition.CallStackQuerySpec{ContainerName: "missing", CallID: "open"}}, nil, &metav1.CreateOptions{})
+	assert.True(t, apierrors.IsBadRequest(err))
+	_, err = r.Create(ctx, "missing", &softwarecomposition.CallStackQuery{Spec: softwarecomposition.CallStackQuerySpec{CallID: "open"}}, nil, &metav1.CreateOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+	_, err = r.Create(ctx, "nginx", &softwarecomposition.CallStackQuery{}, nil, &metav1.CreateOptions{})
+	assert.True(t, apierrors.IsInvalid(err))
+}
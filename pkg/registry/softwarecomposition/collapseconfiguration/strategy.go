@@ -22,9 +22,11 @@ import (
 	"strings"
 
 	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/apiserver/pkg/registry/generic"
 	"k8s.io/apiserver/pkg/storage"
@@ -91,7 +93,7 @@ func (CollapseConfigurationStrategy) Validate(_ context.Context, obj runtime.Obj
 	if !ok {
 		return field.ErrorList{field.InternalError(field.NewPath(""), fmt.Errorf("expected *CollapseConfiguration"))}
 	}
-	return validateCollapseConfigurationSpec(&cc.Spec, field.NewPath("spec"))
+	return validateCollapseConfiguration(cc)
 }
 
 func (CollapseConfigurationStrategy) WarningsOnCreate(_ context.Context, _ runtime.Object) []string {
@@ -116,13 +118,40 @@ func (CollapseConfigurationStrategy) ValidateUpdate(_ context.Context, obj, _ ru
 	if !ok {
 		return field.ErrorList{field.InternalError(field.NewPath(""), fmt.Errorf("expected *CollapseConfiguration"))}
 	}
-	return validateCollapseConfigurationSpec(&cc.Spec, field.NewPath("spec"))
+	return validateCollapseConfiguration(cc)
 }
 
 func (CollapseConfigurationStrategy) WarningsOnUpdate(_ context.Context, _, _ runtime.Object) []string {
 	return nil
 }
 
+// validateCollapseConfiguration checks the spec and the selectors: the
+// cluster-wide "default" must not carry any, every other configuration needs a
+// NamespaceSelector to be scoped by.
+func validateCollapseConfiguration(cc *softwarecomposition.CollapseConfiguration) field.ErrorList {
+	fp := field.NewPath("spec")
+	errs := validateCollapseConfigurationSpec(&cc.Spec, fp)
+	opts := metav1validation.LabelSelectorValidationOptions{}
+	if cc.Name == softwarecomposition.DefaultCollapseConfigurationName {
+		if cc.Spec.NamespaceSelector != nil {
+			errs = append(errs, field.Forbidden(fp.Child("namespaceSelector"), "the default configuration applies cluster-wide"))
+		}
+		if cc.Spec.WorkloadSelector != nil {
+			errs = append(errs, field.Forbidden(fp.Child("workloadSelector"), "the default configuration applies cluster-wide"))
+		}
+		return errs
+	}
+	if cc.Spec.NamespaceSelector == nil {
+		errs = append(errs, field.Required(fp.Child("namespaceSelector"), "configurations other than default must be scoped, use {} to select all namespaces"))
+	} else {
+		errs = append(errs, metav1validation.ValidateLabelSelector(cc.Spec.NamespaceSelector, opts, fp.Child("namespaceSelector"))...)
+	}
+	if cc.Spec.WorkloadSelector != nil {
+		errs = append(errs, metav1validation.ValidateLabelSelector(cc.Spec.WorkloadSelector, opts, fp.Child("workloadSelector"))...)
+	}
+	return errs
+}
+
 // validateCollapseConfigurationSpec enforces the per-entry invariants and
 // rejects duplicate prefixes (which would silently produce a non-deterministic
 // longest-prefix-wins outcome at runtime).
@@ -142,6 +171,18 @@ func validateCollapseConfigurationSpec(spec *softwarecomposition.CollapseConfigu
 	if spec.EndpointDynamicThreshold < 0 {
 		errs = append(errs, field.Invalid(fp.Child("endpointDynamicThreshold"), spec.EndpointDynamicThreshold, "must be >= 0 (0 means use the compiled-in default)"))
 	}
+	if spec.IPCollapseThreshold < 0 {
+		errs = append(errs, field.Invalid(fp.Child("ipCollapseThreshold"), spec.IPCollapseThreshold, "must be >= 0 (0 means use the compiled-in default)"))
+	}
+	if spec.DNSCollapseThreshold < 0 {
+		errs = append(errs, field.Invalid(fp.Child("dnsCollapseThreshold"), spec.DNSCollapseThreshold, "must be >= 0 (0 means use the compiled-in default)"))
+	}
+	if spec.ExecArgsCollapseThreshold < 0 {
+		errs = append(errs, field.Invalid(fp.Child("execArgsCollapseThreshold"), spec.ExecArgsCollapseThreshold, "must be >= 0 (0 means use the compiled-in default)"))
+	}
+	if spec.EnvCollapseThreshold < 0 {
+		errs = append(errs, field.Invalid(fp.Child("envCollapseThreshold"), spec.EnvCollapseThreshold, "must be >= 0 (0 means use the compiled-in default)"))
+	}
 	seen := make(map[string]int, len(spec.CollapseConfigs))
 	cfgsPath := fp.Child("collapseConfigs")
 	for i, e := range spec.CollapseConfigs {
@@ -160,5 +201,11 @@ func validateCollapseConfigurationSpec(spec *softwarecomposition.CollapseConfigu
 			seen[e.Prefix] = i
 		}
 	}
+	headersPath := fp.Child("headerAllowlist")
+	for i, name := range spec.HeaderAllowlist {
+		for _, msg := range validation.IsHTTPHeaderName(name) {
+			errs = append(errs, field.Invalid(headersPath.Index(i), name, msg))
+		}
+	}
 	return errs
 }
@@ -60,14 +60,19 @@ func TestValidate_Valid(t *testing.T) {
 func TestValidate_NegativeThresholds(t *testing.T) {
 	s := NewStrategy(newScheme())
 	cc := &softwarecomposition.CollapseConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
 		Spec: softwarecomposition.CollapseConfigurationSpec{
-			OpenDynamicThreshold:     -1,
-			EndpointDynamicThreshold: -1,
+			OpenDynamicThreshold:      -1,
+			EndpointDynamicThreshold:  -1,
+			IPCollapseThreshold:       -1,
+			DNSCollapseThreshold:      -1,
+			ExecArgsCollapseThreshold: -1,
+			EnvCollapseThreshold:      -1,
 		},
 	}
 	errs := s.Validate(context.Background(), cc)
-	if len(errs) != 2 {
-		t.Fatalf("expected 2 errors for the two negative defaults, got %d: %v", len(errs), errs)
+	if len(errs) != 6 {
+		t.Fatalf("expected 6 errors for the six negative defaults, got %d: %v", len(errs), errs)
 	}
 }
 
@@ -94,6 +99,35 @@ func TestValidate_EntryRules(t *testing.T) {
 	}
 }
 
+func TestValidate_Selectors(t *testing.T) {
+	s := NewStrategy(newScheme())
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{"team": "data"}}
+	tests := []struct {
+		name     string
+		ccName   string
+		ns, wl   *metav1.LabelSelector
+		wantErrs int
+	}{
+		{name: "default without selectors", ccName: "default"},
+		{name: "default with selectors", ccName: "default", ns: selector, wl: selector, wantErrs: 2},
+		{name: "scoped", ccName: "data", ns: selector, wl: selector},
+		{name: "scoped to all namespaces", ccName: "nginx", ns: &metav1.LabelSelector{}, wl: selector},
+		{name: "scoped without namespace selector", ccName: "nginx", wl: selector, wantErrs: 1},
+		{name: "invalid selector", ccName: "data", ns: &metav1.LabelSelector{MatchLabels: map[string]string{"bad key!": "x"}}, wantErrs: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cc := &softwarecomposition.CollapseConfiguration{
+				ObjectMeta: metav1.ObjectMeta{Name: tt.ccName},
+				Spec:       softwarecomposition.CollapseConfigurationSpec{NamespaceSelector: tt.ns, WorkloadSelector: tt.wl},
+			}
+			if errs := s.Validate(context.Background(), cc); len(errs) != tt.wantErrs {
+				t.Fatalf("expected %d errors, got %d: %v", tt.wantErrs, len(errs), errs)
+			}
+		})
+	}
+}
+
 func TestValidate_RejectsNonCC(t *testing.T) {
 	s := NewStrategy(newScheme())
 	// Pass a different type to confirm the type assertion fails cleanly.
@@ -146,3 +180,17 @@ func TestGetAttrs_RejectsNonCC(t *testing.T) {
 		t.Fatalf("GetAttrs should reject non-CollapseConfiguration objects")
 	}
 }
+
+func TestValidate_HeaderAllowlist(t *testing.T) {
+	s := NewStrategy(newScheme())
+	cc := &softwarecomposition.CollapseConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "default"},
+		Spec: softwarecomposition.CollapseConfigurationSpec{
+			HeaderAllowlist: []string{"X-Tenant", "", "Bad Header"},
+		},
+	}
+	errs := s.Validate(context.Background(), cc)
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 header name errors, got %d: %v", len(errs), errs)
+	}
+}
@@ -2,6 +2,9 @@ package common
 
 import (
 	"github.com/kubescape/k8s-interface/instanceidhandler/v1/helpers"
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition/v1beta1"
+	"github.com/kubescape/storage/pkg/registry/file/guardrail"
+	"sigs.k8s.io/structured-merge-diff/v6/fieldpath"
 )
 
 func IsComplete(oldAnnotations map[string]string, newAnnotations map[string]string) bool {
@@ -13,3 +16,16 @@ func IsComplete(oldAnnotations map[string]string, newAnnotations map[string]stri
 	}
 	return false
 }
+
+// ProfileResetFields returns the fields of the profiles which only the storage sets:
+// the annotations it computes on every write. Server-side apply ignores them, so
+// that no field manager owns them nor conflicts on them.
+func ProfileResetFields() map[fieldpath.APIVersion]*fieldpath.Set {
+	return map[fieldpath.APIVersion]*fieldpath.Set{
+		fieldpath.APIVersion(v1beta1.SchemeGroupVersion.String()): fieldpath.NewSet(
+			fieldpath.MakePathOrDie("metadata", "annotations", helpers.ResourceSizeMetadataKey),
+			fieldpath.MakePathOrDie("metadata", "annotations", helpers.SyncChecksumMetadataKey),
+			fieldpath.MakePathOrDie("metadata", "annotations", guardrail.ViolationsAnnotation),
+		),
+	}
+}
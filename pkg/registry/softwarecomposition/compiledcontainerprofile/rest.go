@@ -0,0 +1,66 @@
+/*
+Copyright 2024 The Kubescape Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compiledcontainerprofile
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/rest"
+)
+
+// CompileFunc returns the compiled form of profile, the ContainerProfile
+// named name.
+type CompileFunc func(ctx context.Context, name string, profile *softwarecomposition.ContainerProfile) (*softwarecomposition.CompiledContainerProfile, error)
+
+// REST serves the compiled subresource of ContainerProfiles: the profile, as
+// read through the parent resource, in the pre-indexed form of the
+// compiledprofile package.
+type REST struct {
+	profiles rest.Getter
+	compile  CompileFunc
+}
+
+var _ rest.Getter = &REST{}
+
+// NewREST returns the compiled subresource of the ContainerProfiles served by
+// profiles.
+func NewREST(profiles rest.Getter, compile CompileFunc) *REST {
+	return &REST{profiles: profiles, compile: compile}
+}
+
+func (r *REST) New() runtime.Object {
+	return &softwarecomposition.CompiledContainerProfile{}
+}
+
+func (r *REST) Destroy() {}
+
+func (r *REST) Get(ctx context.Context, name string, _ *metav1.GetOptions) (runtime.Object, error) {
+	obj, err := r.profiles.Get(ctx, name, &metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	profile, ok := obj.(*softwarecomposition.ContainerProfile)
+	if !ok {
+		return nil, apierrors.NewInternalError(fmt.Errorf("not a ContainerProfile: %T", obj))
+	}
+	return r.compile(ctx, name, profile)
+}
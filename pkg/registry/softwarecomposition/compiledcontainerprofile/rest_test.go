@@ -0,0 +1,45 @@
+package compiledcontainerprofile
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type getterFunc func(ctx context.Context, name string, options *metav1.GetOptions) (runtime.Object, error)
+
+func (f getterFunc) Get(ctx context.Context, name string, options *metav1.GetOptions) (runtime.Object, error) {
+	return f(ctx, name, options)
+}
+
+func TestREST_Get(t *testing.T) {
+	r := NewREST(getterFunc(func(_ context.Context, name string, _ *metav1.GetOptions) (runtime.Object, error) {
+		if name != "nginx" {
+			return nil, apierrors.NewNotFound(softwarecomposition.Resource("containerprofiles"), name)
+		}
+		return &softwarecomposition.ContainerProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", ResourceVersion: "7"},
+		}, nil
+	}), func(_ context.Context, name string, profile *softwarecomposition.ContainerProfile) (*softwarecomposition.CompiledContainerProfile, error) {
+		return &softwarecomposition.CompiledContainerProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: profile.Namespace},
+			Spec:       softwarecomposition.CompiledContainerProfileSpec{SourceResourceVersion: profile.ResourceVersion},
+		}, nil
+	})
+	ctx := context.TODO()
+
+	obj, err := r.Get(ctx, "nginx", &metav1.GetOptions{})
+	require.NoError(t, err)
+	out := obj.(*softwarecomposition.CompiledContainerProfile)
+	assert.Equal(t, "default", out.Namespace)
+	assert.Equal(t, "7", out.Spec.SourceResourceVersion)
+
+	_, err = r.Get(ctx, "missing", &metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+}
@@ -45,6 +45,8 @@ func NewREST(scheme *runtime.Scheme, storageImpl storage.Interface, optsGetter g
 		UpdateStrategy: strategy,
 		DeleteStrategy: strategy,
 
+		ResetFieldsStrategy: strategy,
+
 		// TODO: define table converter that exposes more than name/creation timestamp
 		TableConvertor: rest.NewDefaultTableConvertor(softwarecomposition.Resource("containerprofiles")),
 	}
@@ -11,8 +11,10 @@ import (
 	"k8s.io/apiserver/pkg/registry/generic"
 	"k8s.io/apiserver/pkg/storage"
 	"k8s.io/apiserver/pkg/storage/names"
+	"sigs.k8s.io/structured-merge-diff/v6/fieldpath"
 
 	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"github.com/kubescape/storage/pkg/registry/softwarecomposition/common"
 )
 
 // NewStrategy creates and returns a sbomSyftStrategy instance
@@ -87,3 +89,8 @@ func (SbomSyftStrategy) ValidateUpdate(_ context.Context, _, _ runtime.Object) f
 func (SbomSyftStrategy) WarningsOnUpdate(_ context.Context, _, _ runtime.Object) []string {
 	return nil
 }
+
+// GetResetFields returns the fields set by the storage, which server-side apply ignores.
+func (SbomSyftStrategy) GetResetFields() map[fieldpath.APIVersion]*fieldpath.Set {
+	return common.ProfileResetFields()
+}
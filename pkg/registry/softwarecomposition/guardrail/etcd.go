@@ -0,0 +1,59 @@
+/*
+Copyright 2024 The Kubescape Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package guardrail
+
+import (
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"github.com/kubescape/storage/pkg/registry"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/generic"
+	genericregistry "k8s.io/apiserver/pkg/registry/generic/registry"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/apiserver/pkg/storage"
+)
+
+// NewREST returns a RESTStorage object that exposes Guardrail resources.
+// Guardrails are cluster-scoped and read by the profile processors, which
+// evaluate every ApplicationProfile, NetworkNeighborhood and ContainerProfile
+// write against their rules.
+func NewREST(scheme *runtime.Scheme, storageImpl storage.Interface, optsGetter generic.RESTOptionsGetter) (*registry.REST, error) {
+	strategy := NewStrategy(scheme)
+
+	dryRunnableStorage := genericregistry.DryRunnableStorage{Codec: nil, Storage: storageImpl}
+
+	store := &genericregistry.Store{
+		NewFunc:                   func() runtime.Object { return &softwarecomposition.Guardrail{} },
+		NewListFunc:               func() runtime.Object { return &softwarecomposition.GuardrailList{} },
+		PredicateFunc:             MatchGuardrail,
+		DefaultQualifiedResource:  softwarecomposition.Resource("guardrails"),
+		SingularQualifiedResource: softwarecomposition.Resource("guardrail"),
+
+		Storage: dryRunnableStorage,
+
+		CreateStrategy: strategy,
+		UpdateStrategy: strategy,
+		DeleteStrategy: strategy,
+
+		TableConvertor: rest.NewDefaultTableConvertor(softwarecomposition.Resource("guardrails")),
+	}
+	options := &generic.StoreOptions{RESTOptions: optsGetter, AttrFunc: GetAttrs}
+	if err := store.CompleteWithOptions(options); err != nil {
+		return nil, err
+	}
+
+	return &registry.REST{Store: store}, nil
+}
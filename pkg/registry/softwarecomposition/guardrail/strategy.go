@@ -0,0 +1,163 @@
+/*
+Copyright 2024 The Kubescape Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package guardrail
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"github.com/kubescape/storage/pkg/registry/file/guardrail"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apiserver/pkg/registry/generic"
+	"k8s.io/apiserver/pkg/storage"
+	"k8s.io/apiserver/pkg/storage/names"
+)
+
+// profileKinds are the kinds a guardrail can match.
+var profileKinds = []string{"ApplicationProfile", "ContainerProfile", "NetworkNeighborhood"}
+
+// NewStrategy creates and returns a GuardrailStrategy instance.
+func NewStrategy(typer runtime.ObjectTyper) GuardrailStrategy {
+	return GuardrailStrategy{typer, names.SimpleNameGenerator}
+}
+
+// GetAttrs returns labels.Set, fields.Set, and error in case the given
+// runtime.Object is not a Guardrail.
+func GetAttrs(obj runtime.Object) (labels.Set, fields.Set, error) {
+	g, ok := obj.(*softwarecomposition.Guardrail)
+	if !ok {
+		return nil, nil, fmt.Errorf("given object is not a Guardrail")
+	}
+	return g.Labels, SelectableFields(g), nil
+}
+
+// MatchGuardrail returns a generic SelectionPredicate that pairs the supplied
+// label/field selectors with the type's GetAttrs.
+func MatchGuardrail(label labels.Selector, field fields.Selector) storage.SelectionPredicate {
+	return storage.SelectionPredicate{
+		Label:    label,
+		Field:    field,
+		GetAttrs: GetAttrs,
+	}
+}
+
+// SelectableFields returns a field set that represents the object.
+func SelectableFields(obj *softwarecomposition.Guardrail) fields.Set {
+	return generic.ObjectMetaFieldsSet(&obj.ObjectMeta, false)
+}
+
+// GuardrailStrategy carries the per-object lifecycle hooks the generic
+// registry calls during Create/Update/Delete. Guardrail is cluster-scoped,
+// its status is computed by the storage and every rule must compile.
+type GuardrailStrategy struct {
+	runtime.ObjectTyper
+	names.NameGenerator
+}
+
+// NamespaceScoped declares the resource as cluster-scoped.
+func (GuardrailStrategy) NamespaceScoped() bool {
+	return false
+}
+
+// PrepareForCreate drops the status, violations are only ever reported by the storage.
+func (GuardrailStrategy) PrepareForCreate(_ context.Context, obj runtime.Object) {
+	if g, ok := obj.(*softwarecomposition.Guardrail); ok {
+		g.Status = softwarecomposition.GuardrailStatus{}
+	}
+}
+
+// PrepareForUpdate drops the status, violations are only ever reported by the storage.
+func (GuardrailStrategy) PrepareForUpdate(_ context.Context, obj, _ runtime.Object) {
+	if g, ok := obj.(*softwarecomposition.Guardrail); ok {
+		g.Status = softwarecomposition.GuardrailStatus{}
+	}
+}
+
+// Validate runs spec-level checks on a Create.
+func (GuardrailStrategy) Validate(_ context.Context, obj runtime.Object) field.ErrorList {
+	g, ok := obj.(*softwarecomposition.Guardrail)
+	if !ok {
+		return field.ErrorList{field.InternalError(field.NewPath(""), fmt.Errorf("expected *Guardrail"))}
+	}
+	return validateGuardrailSpec(&g.Spec, field.NewPath("spec"))
+}
+
+func (GuardrailStrategy) WarningsOnCreate(_ context.Context, _ runtime.Object) []string {
+	return nil
+}
+
+func (GuardrailStrategy) AllowCreateOnUpdate() bool {
+	return false
+}
+
+func (GuardrailStrategy) AllowUnconditionalUpdate() bool {
+	return false
+}
+
+func (GuardrailStrategy) Canonicalize(_ runtime.Object) {
+}
+
+// ValidateUpdate runs the same spec-level checks as Validate.
+func (GuardrailStrategy) ValidateUpdate(_ context.Context, obj, _ runtime.Object) field.ErrorList {
+	g, ok := obj.(*softwarecomposition.Guardrail)
+	if !ok {
+		return field.ErrorList{field.InternalError(field.NewPath(""), fmt.Errorf("expected *Guardrail"))}
+	}
+	return validateGuardrailSpec(&g.Spec, field.NewPath("spec"))
+}
+
+func (GuardrailStrategy) WarningsOnUpdate(_ context.Context, _, _ runtime.Object) []string {
+	return nil
+}
+
+// validateGuardrailSpec rejects unknown kinds, unnamed or duplicate rules and
+// expressions which do not compile to a bool, so that a bad rule is reported
+// to its author instead of being silently skipped at evaluation time.
+func validateGuardrailSpec(spec *softwarecomposition.GuardrailSpec, fp *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	for i, kind := range spec.MatchKinds {
+		if !slices.Contains(profileKinds, kind) {
+			errs = append(errs, field.NotSupported(fp.Child("matchKinds").Index(i), kind, profileKinds))
+		}
+	}
+	rulesPath := fp.Child("rules")
+	if len(spec.Rules) == 0 {
+		errs = append(errs, field.Required(rulesPath, "at least one rule is required"))
+	}
+	seen := make(map[string]int, len(spec.Rules))
+	for i, r := range spec.Rules {
+		ip := rulesPath.Index(i)
+		if r.Name == "" {
+			errs = append(errs, field.Required(ip.Child("name"), "name must not be empty"))
+		} else if dup, ok := seen[r.Name]; ok {
+			errs = append(errs, field.Duplicate(ip.Child("name"), fmt.Sprintf("%s (also at index %d)", r.Name, dup)))
+		} else {
+			seen[r.Name] = i
+		}
+		if r.Expression == "" {
+			errs = append(errs, field.Required(ip.Child("expression"), "expression must not be empty"))
+		} else if _, err := guardrail.CompileExpression(r.Expression); err != nil {
+			errs = append(errs, field.Invalid(ip.Child("expression"), r.Expression, err.Error()))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2024 The Kubescape Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package guardrail
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestNamespaceScoped(t *testing.T) {
+	s := NewStrategy(runtime.NewScheme())
+	if s.NamespaceScoped() {
+		t.Fatalf("Guardrail must be cluster-scoped")
+	}
+}
+
+func TestValidate_Valid(t *testing.T) {
+	s := NewStrategy(runtime.NewScheme())
+	g := &softwarecomposition.Guardrail{
+		ObjectMeta: metav1.ObjectMeta{Name: "no-shell"},
+		Spec: softwarecomposition.GuardrailSpec{
+			MatchKinds: []string{"ApplicationProfile", "ContainerProfile"},
+			Rules: []softwarecomposition.GuardrailRule{
+				{Name: "sh", Expression: "object.spec.containers.all(c, c.execs.all(e, e.path != '/bin/sh'))"},
+				{Name: "bash", Expression: "object.spec.containers.all(c, c.execs.all(e, e.path != '/bin/bash'))"},
+			},
+		},
+	}
+	if errs := s.Validate(context.Background(), g); len(errs) != 0 {
+		t.Fatalf("expected no validation errors, got: %v", errs)
+	}
+}
+
+func TestValidate_RuleErrors(t *testing.T) {
+	s := NewStrategy(runtime.NewScheme())
+	g := &softwarecomposition.Guardrail{
+		Spec: softwarecomposition.GuardrailSpec{
+			MatchKinds: []string{"Pod"}, // unknown kind
+			Rules: []softwarecomposition.GuardrailRule{
+				{Name: "", Expression: "true"},             // empty name
+				{Name: "a", Expression: ""},                // empty expression
+				{Name: "b", Expression: "object.spec +"},   // syntax error
+				{Name: "c", Expression: "kind + 'suffix'"}, // not a bool
+				{Name: "c", Expression: "true"},            // duplicate name
+			},
+		},
+	}
+	errs := s.Validate(context.Background(), g)
+	if len(errs) != 6 {
+		t.Fatalf("expected 6 errors, got %d: %v", len(errs), errs)
+	}
+}
+
+func TestValidate_NoRules(t *testing.T) {
+	s := NewStrategy(runtime.NewScheme())
+	errs := s.ValidateUpdate(context.Background(), &softwarecomposition.Guardrail{}, &softwarecomposition.Guardrail{})
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error for missing rules, got: %v", errs)
+	}
+}
+
+func TestPrepareForCreate_DropsStatus(t *testing.T) {
+	s := NewStrategy(runtime.NewScheme())
+	g := &softwarecomposition.Guardrail{
+		Status: softwarecomposition.GuardrailStatus{
+			Violations: []softwarecomposition.GuardrailViolation{{Kind: "ApplicationProfile", Namespace: "default", Name: "nginx", Rule: "sh"}},
+		},
+	}
+	s.PrepareForCreate(context.Background(), g)
+	if len(g.Status.Violations) != 0 {
+		t.Fatalf("status must be dropped, got: %v", g.Status)
+	}
+}
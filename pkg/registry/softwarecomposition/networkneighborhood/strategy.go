@@ -12,6 +12,7 @@ import (
 	"k8s.io/apiserver/pkg/registry/generic"
 	"k8s.io/apiserver/pkg/storage"
 	"k8s.io/apiserver/pkg/storage/names"
+	"sigs.k8s.io/structured-merge-diff/v6/fieldpath"
 
 	"github.com/kubescape/go-logger"
 	"github.com/kubescape/k8s-interface/instanceidhandler/v1/helpers"
@@ -215,3 +216,8 @@ func (NetworkNeighborhoodStrategy) ValidateUpdate(_ context.Context, obj, _ runt
 func (NetworkNeighborhoodStrategy) WarningsOnUpdate(_ context.Context, _, _ runtime.Object) []string {
 	return nil
 }
+
+// GetResetFields returns the fields set by the storage, which server-side apply ignores.
+func (NetworkNeighborhoodStrategy) GetResetFields() map[fieldpath.APIVersion]*fieldpath.Set {
+	return common.ProfileResetFields()
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2024 The Kubescape Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pendingprofile
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/rest"
+)
+
+// Profiles is the parent resource of the pending subresource.
+type Profiles interface {
+	rest.Getter
+	New() runtime.Object
+}
+
+// REST serves the pending subresource of approved profiles: the latest
+// learned revision, which the parent resource only serves once approved.
+type REST struct {
+	profiles    Profiles
+	withPending func(ctx context.Context) context.Context
+}
+
+var _ rest.Getter = &REST{}
+
+// NewREST returns the pending subresource of the profiles served by profiles,
+// withPending marks a context as reading the pending revision.
+func NewREST(profiles Profiles, withPending func(ctx context.Context) context.Context) *REST {
+	return &REST{profiles: profiles, withPending: withPending}
+}
+
+func (r *REST) New() runtime.Object {
+	return r.profiles.New()
+}
+
+func (r *REST) Destroy() {}
+
+func (r *REST) Get(ctx context.Context, name string, _ *metav1.GetOptions) (runtime.Object, error) {
+	return r.profiles.Get(r.withPending(ctx), name, &metav1.GetOptions{})
+}
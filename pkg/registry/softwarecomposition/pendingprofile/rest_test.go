@@ -0,0 +1,43 @@
+package pendingprofile
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type pendingKey struct{}
+
+type profiles struct{}
+
+func (profiles) New() runtime.Object {
+	return &softwarecomposition.ApplicationProfile{}
+}
+
+func (profiles) Get(ctx context.Context, name string, _ *metav1.GetOptions) (runtime.Object, error) {
+	revision := "approved"
+	if ctx.Value(pendingKey{}) != nil {
+		revision = "pending"
+	}
+	return &softwarecomposition.ApplicationProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: map[string]string{"revision": revision}},
+	}, nil
+}
+
+func TestREST_Get(t *testing.T) {
+	r := NewREST(profiles{}, func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, pendingKey{}, true)
+	})
+	assert.IsType(t, &softwarecomposition.ApplicationProfile{}, r.New())
+
+	obj, err := r.Get(context.TODO(), "nginx", &metav1.GetOptions{})
+	require.NoError(t, err)
+	out := obj.(*softwarecomposition.ApplicationProfile)
+	assert.Equal(t, "nginx", out.Name)
+	assert.Equal(t, "pending", out.Annotations["revision"])
+}
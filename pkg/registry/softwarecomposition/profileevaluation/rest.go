@@ -0,0 +1,85 @@
+/*
+Copyright 2024 The Kubescape Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package profileevaluation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"github.com/kubescape/storage/pkg/registry/file/evaluation"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/rest"
+)
+
+// REST serves the evaluate subresource of ApplicationProfiles,
+// NetworkNeighborhoods and ContainerProfiles: a ProfileEvaluation posted to
+// it is answered with the evaluation of its observations against the profile,
+// as read through the parent resource. Nothing is stored.
+type REST struct {
+	profiles rest.Getter
+}
+
+var _ rest.NamedCreater = &REST{}
+
+// NewREST returns the evaluate subresource of the resource served by profiles.
+func NewREST(profiles rest.Getter) *REST {
+	return &REST{profiles: profiles}
+}
+
+func (r *REST) New() runtime.Object {
+	return &softwarecomposition.ProfileEvaluation{}
+}
+
+func (r *REST) Destroy() {}
+
+func (r *REST) Create(ctx context.Context, name string, obj runtime.Object, createValidation rest.ValidateObjectFunc, _ *metav1.CreateOptions) (runtime.Object, error) {
+	pe, ok := obj.(*softwarecomposition.ProfileEvaluation)
+	if !ok {
+		return nil, apierrors.NewBadRequest(fmt.Sprintf("not a ProfileEvaluation: %T", obj))
+	}
+	if errs := evaluation.Validate(&pe.Spec); len(errs) > 0 {
+		return nil, apierrors.NewInvalid(softwarecomposition.Kind("ProfileEvaluation"), name, errs)
+	}
+	if createValidation != nil {
+		if err := createValidation(ctx, obj.DeepCopyObject()); err != nil {
+			return nil, err
+		}
+	}
+	profile, err := r.profiles.Get(ctx, name, &metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	status, err := evaluation.Evaluate(profile, &pe.Spec)
+	if err != nil {
+		return nil, apierrors.NewBadRequest(err.Error())
+	}
+	out := pe.DeepCopy()
+	out.Name = name
+	out.Namespace = profileNamespace(profile)
+	out.Status = status
+	return out, nil
+}
+
+func profileNamespace(obj runtime.Object) string {
+	if o, ok := obj.(metav1.Object); ok {
+		return o.GetNamespace()
+	}
+	return ""
+}
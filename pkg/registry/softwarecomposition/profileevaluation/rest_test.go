@@ -0,0 +1,50 @@
+package profileevaluation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kubescape/storage/pkg/apis/softwarecomposition"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type getterFunc func(ctx context.Context, name string, options *metav1.GetOptions) (runtime.Object, error)
+
+func (f getterFunc) Get(ctx context.Context, name string, options *metav1.GetOptions) (runtime.Object, error) {
+	return f(ctx, name, options)
+}
+
+func TestREST_Create(t *testing.T) {
+	r := NewREST(getterFunc(func(_ context.Context, name string, _ *metav1.GetOptions) (runtime.Object, error) {
+		if name != "nginx" {
+			return nil, apierrors.NewNotFound(softwarecomposition.Resource("containerprofiles"), name)
+		}
+		return &softwarecomposition.ContainerProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       softwarecomposition.ContainerProfileSpec{Syscalls: []string{"read"}},
+		}, nil
+	}))
+	ctx := context.TODO()
+	pe := &softwarecomposition.ProfileEvaluation{Spec: softwarecomposition.ProfileEvaluationSpec{
+		Observations: []softwarecomposition.ProfileObservation{{Type: softwarecomposition.ObservationTypeSyscall, Name: "read"}},
+	}}
+
+	obj, err := r.Create(ctx, "nginx", pe, nil, &metav1.CreateOptions{})
+	require.NoError(t, err)
+	out := obj.(*softwarecomposition.ProfileEvaluation)
+	assert.Equal(t, "default", out.Namespace)
+	assert.Equal(t, []softwarecomposition.ProfileObservationResult{{Allowed: true, MatchedField: "spec.syscalls[0]", MatchedEntry: "read"}}, out.Status.Results)
+
+	_, err = r.Create(ctx, "missing", pe, nil, &metav1.CreateOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+
+	invalid := &softwarecomposition.ProfileEvaluation{Spec: softwarecomposition.ProfileEvaluationSpec{
+		Observations: []softwarecomposition.ProfileObservation{{Type: softwarecomposition.ObservationTypeSyscall}},
+	}}
+	_, err = r.Create(ctx, "nginx", invalid, nil, &metav1.CreateOptions{})
+	assert.True(t, apierrors.IsInvalid(err))
+}